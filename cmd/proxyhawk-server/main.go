@@ -44,7 +44,8 @@ func main() {
 		httpAddr  = flag.String("http", ":8080", "HTTP proxy address")
 		
 		// Geographic testing API
-		apiAddr = flag.String("api", ":8888", "API/WebSocket address")
+		apiAddr  = flag.String("api", "127.0.0.1:8888", "API/WebSocket address")
+		apiToken = flag.String("api-token", "", "Bearer token required by POST /api/check (default: loopback clients only)")
 		
 		// Configuration
 		configFile = flag.String("config", "", "Configuration file (default: ~/.config/proxyhawk/server.yaml)")
@@ -121,6 +122,16 @@ func main() {
 		logger.Info("No config file found", "default_path", configPath, "suggestion", "Create config file or use -config flag")
 	}
 	
+	if *apiToken != "" {
+		config.APIToken = *apiToken
+	}
+	
+	// An access policy that cannot be parsed would leave denied ranges open
+	if err := server.ValidateAccessControl(config.AccessControl); err != nil {
+		logger.Error("Invalid access_control configuration", "error", err)
+		os.Exit(1)
+	}
+	
 	// Initialize the unified server
 	srv := server.NewProxyHawkServer(config, logger)
 	
@@ -169,7 +180,7 @@ func createDefaultConfig(mode server.ServerMode, metricsEnabled bool, metricsAdd
 		Mode:       mode,
 		SOCKS5Addr: ":1080",
 		HTTPAddr:   ":8080",
-		APIAddr:    ":8888",
+		APIAddr:    "127.0.0.1:8888",
 		
 		// Sample regions configuration
 		Regions: map[string]*server.RegionConfig{
@@ -220,6 +231,7 @@ func createDefaultConfig(mode server.ServerMode, metricsEnabled bool, metricsAdd
 			Timeout:          10 * time.Second,
 			FailureThreshold: 3,
 			SuccessThreshold: 2,
+			MaxBackoff:       server.DefaultHealthCheckMaxBackoff,
 		},
 		
 		CacheConfig: server.CacheConfig{
@@ -228,10 +240,11 @@ func createDefaultConfig(mode server.ServerMode, metricsEnabled bool, metricsAdd
 			MaxEntries: 10000,
 		},
 		
-		MetricsEnabled: metricsEnabled,
-		MetricsAddr:    metricsAddr,
-		LogLevel:       "info",
-		LogFormat:      "text",
+		MetricsEnabled:      metricsEnabled,
+		MetricsAddr:         metricsAddr,
+		ShutdownGracePeriod: server.DefaultShutdownGracePeriod,
+		LogLevel:            "info",
+		LogFormat:           "text",
 	}
 }
 
@@ -263,7 +276,11 @@ FLAGS:
         HTTP proxy address (default ":8080")
     
     -api string
-        API/WebSocket address (default ":8888")
+        API/WebSocket address (default "127.0.0.1:8888")
+    
+    -api-token string
+        Bearer token required by POST /api/check; without one only
+        loopback clients may run checks
     
     -config string
         Configuration file (default "~/.config/proxyhawk/server.yaml")
@@ -296,7 +313,7 @@ EXAMPLES:
     proxyhawk-server -mode proxy -socks :1080 -http :8080
     
     # Start only as geographic agent
-    proxyhawk-server -mode agent -api :8888
+    proxyhawk-server -mode agent -api :8888 -api-token s3cret
     
     # Start with custom addresses and metrics
     proxyhawk-server -socks :2080 -http :3080 -api :4080 -metrics
@@ -315,6 +332,10 @@ INTEGRATION:
     # Health check endpoint  
     http://localhost:8888/api/health
 
+    # Check a proxy on demand (returns the result as JSON)
+    curl -X POST http://localhost:8888/api/check -H 'Authorization: Bearer s3cret' \
+         -d '{"proxy":"socks5://1.2.3.4:1080","test_url":"https://example.com","timeout":"5s"}'
+
 For more information, see the documentation.
 `)
 }
@@ -372,6 +393,9 @@ func mergeConfigs(defaultConfig, loadedConfig *server.Config) *server.Config {
 	if loadedConfig.APIAddr != "" {
 		merged.APIAddr = loadedConfig.APIAddr
 	}
+	if loadedConfig.APIToken != "" {
+		merged.APIToken = loadedConfig.APIToken
+	}
 	
 	// Merge regions if provided
 	if len(loadedConfig.Regions) > 0 {
@@ -382,6 +406,12 @@ func mergeConfigs(defaultConfig, loadedConfig *server.Config) *server.Config {
 	if loadedConfig.SelectionStrategy != "" {
 		merged.SelectionStrategy = loadedConfig.SelectionStrategy
 	}
+	if loadedConfig.HashKey != "" {
+		merged.HashKey = loadedConfig.HashKey
+	}
+	if len(loadedConfig.AccessControl.AllowCIDRs) > 0 || len(loadedConfig.AccessControl.DenyCIDRs) > 0 {
+		merged.AccessControl = loadedConfig.AccessControl
+	}
 	
 	// Override boolean and numeric values
 	if loadedConfig.RoundRobinDetection.Enabled {
@@ -398,6 +428,9 @@ func mergeConfigs(defaultConfig, loadedConfig *server.Config) *server.Config {
 		merged.MetricsEnabled = loadedConfig.MetricsEnabled
 		merged.MetricsAddr = loadedConfig.MetricsAddr
 	}
+	if loadedConfig.ShutdownGracePeriod > 0 {
+		merged.ShutdownGracePeriod = loadedConfig.ShutdownGracePeriod
+	}
 	
 	if loadedConfig.LogLevel != "" {
 		merged.LogLevel = loadedConfig.LogLevel
@@ -415,9 +448,13 @@ type YAMLConfig struct {
 	SOCKS5Addr   string                   `yaml:"socks5_addr"`
 	HTTPAddr     string                   `yaml:"http_addr"`
 	APIAddr      string                   `yaml:"api_addr"`
+	APIToken     string                   `yaml:"api_token"`
 	
 	Regions  map[string]YAMLRegion    `yaml:"regions"`
 	Strategy string                   `yaml:"selection_strategy"`
+	HashKey  string                   `yaml:"hash_key"`
+	
+	AccessControl YAMLAccessControlConfig `yaml:"access_control"`
 	
 	RoundRobinDetection YAMLRoundRobinConfig `yaml:"round_robin_detection"`
 	HealthCheck        YAMLHealthCheckConfig `yaml:"health_check"`
@@ -425,6 +462,8 @@ type YAMLConfig struct {
 	
 	Metrics YAMLMetricsConfig `yaml:"metrics"`
 	
+	ShutdownGracePeriod time.Duration `yaml:"shutdown_grace_period"`
+	
 	LogLevel  string `yaml:"log_level"`
 	LogFormat string `yaml:"log_format"`
 }
@@ -433,6 +472,9 @@ type YAMLConfig struct {
 type YAMLRegion struct {
 	Name    string        `yaml:"name"`
 	Proxies []YAMLProxy   `yaml:"proxies"`
+	
+	MaxConcurrent int           `yaml:"max_concurrent"`
+	QueueTimeout  time.Duration `yaml:"queue_timeout"`
 }
 
 // YAMLProxy represents a proxy configuration in YAML
@@ -442,6 +484,12 @@ type YAMLProxy struct {
 	HealthCheckURL string `yaml:"health_check_url"`
 }
 
+// YAMLAccessControlConfig represents destination access control in YAML
+type YAMLAccessControlConfig struct {
+	AllowCIDRs []string `yaml:"allow_cidrs"`
+	DenyCIDRs  []string `yaml:"deny_cidrs"`
+}
+
 // YAMLRoundRobinConfig represents round robin configuration in YAML
 type YAMLRoundRobinConfig struct {
 	Enabled             bool          `yaml:"enabled"`
@@ -457,6 +505,7 @@ type YAMLHealthCheckConfig struct {
 	Timeout          time.Duration `yaml:"timeout"`
 	FailureThreshold int           `yaml:"failure_threshold"`
 	SuccessThreshold int           `yaml:"success_threshold"`
+	MaxBackoff       time.Duration `yaml:"max_backoff"`
 }
 
 // YAMLCacheConfig represents cache configuration in YAML
@@ -479,6 +528,7 @@ func convertYAMLToConfig(yamlConfig *YAMLConfig) *server.Config {
 		SOCKS5Addr: yamlConfig.SOCKS5Addr,
 		HTTPAddr:   yamlConfig.HTTPAddr,
 		APIAddr:    yamlConfig.APIAddr,
+		APIToken:   yamlConfig.APIToken,
 		LogLevel:   yamlConfig.LogLevel,
 		LogFormat:  yamlConfig.LogFormat,
 	}
@@ -493,14 +543,25 @@ func convertYAMLToConfig(yamlConfig *YAMLConfig) *server.Config {
 		config.SelectionStrategy = server.StrategySmart
 	case "weighted":
 		config.SelectionStrategy = server.StrategyWeighted
+	case "least_conn":
+		config.SelectionStrategy = server.StrategyLeastConn
+	case "consistent_hash":
+		config.SelectionStrategy = server.StrategyConsistentHash
+	}
+	config.HashKey = yamlConfig.HashKey
+	config.AccessControl = server.AccessControlConfig{
+		AllowCIDRs: yamlConfig.AccessControl.AllowCIDRs,
+		DenyCIDRs:  yamlConfig.AccessControl.DenyCIDRs,
 	}
 	
 	// Convert regions
 	config.Regions = make(map[string]*server.RegionConfig)
 	for regionName, yamlRegion := range yamlConfig.Regions {
 		region := &server.RegionConfig{
-			Name:    yamlRegion.Name,
-			Proxies: make([]server.ProxyConfig, 0, len(yamlRegion.Proxies)),
+			Name:          yamlRegion.Name,
+			Proxies:       make([]server.ProxyConfig, 0, len(yamlRegion.Proxies)),
+			MaxConcurrent: yamlRegion.MaxConcurrent,
+			QueueTimeout:  yamlRegion.QueueTimeout,
 		}
 		
 		for _, yamlProxy := range yamlRegion.Proxies {
@@ -530,6 +591,7 @@ func convertYAMLToConfig(yamlConfig *YAMLConfig) *server.Config {
 		Timeout:          yamlConfig.HealthCheck.Timeout,
 		FailureThreshold: yamlConfig.HealthCheck.FailureThreshold,
 		SuccessThreshold: yamlConfig.HealthCheck.SuccessThreshold,
+		MaxBackoff:       yamlConfig.HealthCheck.MaxBackoff,
 	}
 	
 	// Convert cache config
@@ -543,5 +605,7 @@ func convertYAMLToConfig(yamlConfig *YAMLConfig) *server.Config {
 	config.MetricsEnabled = yamlConfig.Metrics.Enabled
 	config.MetricsAddr = yamlConfig.Metrics.Addr
 	
+	config.ShutdownGracePeriod = yamlConfig.ShutdownGracePeriod
+	
 	return config
 }
\ No newline at end of file
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/logging"
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/proxy"
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/ui"
+)
+
+// startFlakyProxies listens on every loopback address and closes each
+// connection after a random delay, so concurrent checks of the returned
+// proxies finish in an unpredictable order
+func startFlakyProxies(t *testing.T, count int) []string {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				time.Sleep(time.Duration(rand.Intn(20)) * time.Millisecond)
+				conn.Close()
+			}(conn)
+		}
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	proxies := make([]string, count)
+	for i := range proxies {
+		proxies[i] = fmt.Sprintf("http://127.0.0.%d:%d", i+1, port)
+	}
+	return proxies
+}
+
+// TestConcurrentResultsMatchProxies runs many checks concurrently and
+// verifies every completion message carries the result of the proxy it
+// reports, with each proxy reported exactly once
+func TestConcurrentResultsMatchProxies(t *testing.T) {
+	proxies := startFlakyProxies(t, 60)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	s := &AppState{
+		view:        &ui.View{ActiveChecks: make(map[string]*ui.CheckStatus)},
+		checker:     proxy.NewChecker(proxy.Config{Timeout: 2 * time.Second, ValidationURL: "http://example.com"}, false, nil),
+		proxies:     proxies,
+		concurrency: 16,
+		updateChan:  make(chan tea.Msg, 100),
+		ctx:         ctx,
+	}
+
+	go s.startChecking()
+
+	reported := make(map[string]int)
+	timeout := time.After(60 * time.Second)
+	for done := false; !done; {
+		select {
+		case msg, ok := <-s.updateChan:
+			if !ok {
+				done = true
+				break
+			}
+			if complete, isComplete := msg.(proxyCheckCompleteMsg); isComplete {
+				if complete.result.ProxyURL != complete.proxy {
+					t.Errorf("Completion for %s carries the result of %s", complete.proxy, complete.result.ProxyURL)
+				}
+				reported[complete.proxy]++
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for the checks to finish")
+		}
+	}
+
+	for _, proxyURL := range proxies {
+		if reported[proxyURL] != 1 {
+			t.Errorf("Expected %s to be reported once, got %d", proxyURL, reported[proxyURL])
+		}
+	}
+	if len(reported) != len(proxies) {
+		t.Errorf("Expected %d proxies reported, got %d", len(proxies), len(reported))
+	}
+}
+
+// TestConcurrentResultsMatchProxiesNoUI is TestConcurrentResultsMatchProxies
+// for the non-TUI worker loop, which collects results directly
+func TestConcurrentResultsMatchProxiesNoUI(t *testing.T) {
+	proxies := startFlakyProxies(t, 60)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	s := &AppState{
+		checker:     proxy.NewChecker(proxy.Config{Timeout: 2 * time.Second, ValidationURL: "http://example.com"}, false, nil),
+		proxies:     proxies,
+		concurrency: 16,
+		logger:      logging.NewLogger(logging.Config{Level: logging.LevelError, Output: io.Discard}),
+		ctx:         ctx,
+	}
+
+	s.startCheckingNoUI()
+
+	reported := make(map[string]int)
+	for _, result := range s.results {
+		reported[result.ProxyURL]++
+	}
+	for _, proxyURL := range proxies {
+		if reported[proxyURL] != 1 {
+			t.Errorf("Expected one result for %s, got %d", proxyURL, reported[proxyURL])
+		}
+	}
+	if len(s.results) != len(proxies) {
+		t.Errorf("Expected %d results, got %d", len(proxies), len(s.results))
+	}
+}
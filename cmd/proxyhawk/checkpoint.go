@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// checkpointInterval is how many completed checks are batched between checkpoint writes
+const checkpointInterval = 100
+
+// checkpointFile is the on-disk layout of a checkpoint
+type checkpointFile struct {
+	Checked []string `json:"checked"`
+}
+
+// checkpoint records which proxies have already been checked so an interrupted
+// scan can resume where it stopped. A nil checkpoint does nothing.
+type checkpoint struct {
+	path    string
+	mutex   sync.Mutex
+	checked []string
+	seen    map[string]bool
+	pending int
+}
+
+// loadCheckpoint opens the checkpoint at path, starting empty if it does not exist yet
+func loadCheckpoint(path string) (*checkpoint, error) {
+	c := &checkpoint{path: path, seen: make(map[string]bool)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file checkpointFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	for _, proxy := range file.Checked {
+		if !c.seen[proxy] {
+			c.seen[proxy] = true
+			c.checked = append(c.checked, proxy)
+		}
+	}
+	return c, nil
+}
+
+// remaining returns the proxies not yet recorded in the checkpoint
+func (c *checkpoint) remaining(proxies []string) []string {
+	if c == nil || len(c.seen) == 0 {
+		return proxies
+	}
+
+	var remaining []string
+	for _, proxy := range proxies {
+		if !c.seen[proxy] {
+			remaining = append(remaining, proxy)
+		}
+	}
+	return remaining
+}
+
+// markChecked records a completed proxy, writing the checkpoint every
+// checkpointInterval results
+func (c *checkpoint) markChecked(proxy string) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.seen[proxy] {
+		return nil
+	}
+	c.seen[proxy] = true
+	c.checked = append(c.checked, proxy)
+	c.pending++
+	if c.pending < checkpointInterval {
+		return nil
+	}
+	return c.writeLocked()
+}
+
+// flush writes any results recorded since the last checkpoint write
+func (c *checkpoint) flush() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.pending == 0 {
+		return nil
+	}
+	return c.writeLocked()
+}
+
+// writeLocked atomically replaces the checkpoint file by writing a temporary
+// file next to it and renaming it into place. The caller must hold c.mutex.
+func (c *checkpoint) writeLocked() error {
+	data, err := json.Marshal(checkpointFile{Checked: c.checked})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return err
+	}
+
+	c.pending = 0
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	cp, err := loadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("loadCheckpoint() on a missing file error = %v", err)
+	}
+	if err := cp.markChecked("http://1.2.3.4:8080"); err != nil {
+		t.Fatalf("markChecked() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no checkpoint write before the interval, got %v", err)
+	}
+	if err := cp.flush(); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+
+	resumed, err := loadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("loadCheckpoint() error = %v", err)
+	}
+	remaining := resumed.remaining([]string{"http://1.2.3.4:8080", "socks5://5.6.7.8:1080"})
+	if len(remaining) != 1 || remaining[0] != "socks5://5.6.7.8:1080" {
+		t.Errorf("remaining() = %v, want only the unchecked proxy", remaining)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected only the checkpoint file to be left behind, got %d entries", len(entries))
+	}
+}
+
+func TestCheckpointWritesEveryInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	cp, err := loadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("loadCheckpoint() error = %v", err)
+	}
+
+	for i := 0; i < checkpointInterval; i++ {
+		if err := cp.markChecked(fmt.Sprintf("http://10.0.0.%d:8080", i)); err != nil {
+			t.Fatalf("markChecked() error = %v", err)
+		}
+	}
+
+	resumed, err := loadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("Expected the checkpoint to be written after %d results: %v", checkpointInterval, err)
+	}
+	if len(resumed.checked) != checkpointInterval {
+		t.Errorf("Expected %d checked proxies, got %d", checkpointInterval, len(resumed.checked))
+	}
+}
+
+func TestNilCheckpoint(t *testing.T) {
+	var cp *checkpoint
+	proxies := []string{"http://1.2.3.4:8080"}
+
+	if got := cp.remaining(proxies); len(got) != 1 {
+		t.Errorf("remaining() on a nil checkpoint = %v, want all proxies", got)
+	}
+	if err := cp.markChecked(proxies[0]); err != nil {
+		t.Errorf("markChecked() on a nil checkpoint error = %v", err)
+	}
+	if err := cp.flush(); err != nil {
+		t.Errorf("flush() on a nil checkpoint error = %v", err)
+	}
+}
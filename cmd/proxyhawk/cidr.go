@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/loader"
+)
+
+// CIDR ranges are limited to maxCIDRHosts addresses unless -force is given,
+// and never expanded beyond maxForcedCIDRHosts
+const (
+	maxCIDRHosts       = 1 << 16
+	maxForcedCIDRHosts = 1 << 24
+)
+
+// cidrOptions controls how a CIDR range is expanded into candidate proxies
+type cidrOptions struct {
+	port            string // -port; overrides a :port suffix on the range
+	scheme          string // -scheme; candidates become scheme://ip:port
+	force           bool   // allow ranges larger than maxCIDRHosts
+	includeReserved bool   // keep loopback, link-local, multicast and other reserved addresses
+}
+
+// expandCIDR expands a CIDR notation into candidate proxies, one per address.
+// A port can be given as a suffix (e.g. "192.168.1.0/24:8080") or with
+// opts.port, and opts.scheme turns each candidate into a proxy URL. It also
+// returns how many reserved addresses were skipped.
+func expandCIDR(cidr string, opts cidrOptions) ([]string, int, error) {
+	// A port suffix follows the prefix length, so IPv6 ranges keep their colons
+	var port string
+	if slash := strings.Index(cidr, "/"); slash >= 0 {
+		if prefixLen, suffix, found := strings.Cut(cidr[slash+1:], ":"); found {
+			cidr = cidr[:slash+1] + prefixLen
+			port = suffix
+		}
+	}
+	if opts.port != "" {
+		port = opts.port
+	}
+	if port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return nil, 0, fmt.Errorf("invalid port: %s", port)
+		}
+	}
+
+	scheme := strings.ToLower(opts.scheme)
+	switch scheme {
+	case "", "http", "https", "socks4", "socks5":
+	default:
+		return nil, 0, fmt.Errorf("unsupported scheme: %s (use http, https, socks4 or socks5)", opts.scheme)
+	}
+
+	// Parse CIDR
+	ip, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid CIDR notation: %w", err)
+	}
+
+	ones, bits := ipNet.Mask.Size()
+	hostBits := bits - ones
+	limit := maxCIDRHosts
+	if opts.force {
+		limit = maxForcedCIDRHosts
+	}
+	if hostBits >= 31 || 1<<hostBits > limit {
+		if !opts.force {
+			return nil, 0, fmt.Errorf("CIDR range %s is larger than %d addresses; use -force to scan it", cidr, maxCIDRHosts)
+		}
+		return nil, 0, fmt.Errorf("CIDR range %s is larger than %d addresses", cidr, maxForcedCIDRHosts)
+	}
+
+	var ips []net.IP
+	for ip := ip.Mask(ipNet.Mask); ipNet.Contains(ip); inc(ip) {
+		ips = append(ips, append(net.IP(nil), ip...))
+	}
+
+	// Remove network and broadcast addresses for IPv4
+	if len(ips) > 2 && ip.To4() != nil {
+		ips = ips[1 : len(ips)-1]
+	}
+
+	var candidates []string
+	skipped := 0
+	for _, ip := range ips {
+		if !opts.includeReserved && loader.IsReservedIP(ip) {
+			skipped++
+			continue
+		}
+		candidate := ip.String()
+		if port != "" {
+			candidate = net.JoinHostPort(candidate, port)
+		} else if ip.To4() == nil && scheme != "" {
+			candidate = "[" + candidate + "]"
+		}
+		if scheme != "" {
+			candidate = scheme + "://" + candidate
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, skipped, nil
+}
+
+// inc increments an IP address
+func inc(ip net.IP) {
+	for j := len(ip) - 1; j >= 0; j-- {
+		ip[j]++
+		if ip[j] > 0 {
+			break
+		}
+	}
+}
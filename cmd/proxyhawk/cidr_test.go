@@ -0,0 +1,113 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandCIDR(t *testing.T) {
+	tests := []struct {
+		name    string
+		cidr    string
+		opts    cidrOptions
+		want    []string
+		skipped int
+		wantErr string
+	}{
+		{
+			name: "bare addresses without network and broadcast",
+			cidr: "192.0.2.0/30",
+			want: []string{"192.0.2.1", "192.0.2.2"},
+		},
+		{
+			name: "port suffix",
+			cidr: "192.0.2.0/30:3128",
+			want: []string{"192.0.2.1:3128", "192.0.2.2:3128"},
+		},
+		{
+			name: "port and scheme flags",
+			cidr: "192.0.2.0/30:3128",
+			opts: cidrOptions{port: "8080", scheme: "SOCKS5"},
+			want: []string{"socks5://192.0.2.1:8080", "socks5://192.0.2.2:8080"},
+		},
+		{
+			name: "IPv6 range",
+			cidr: "2001:db8::/127:8080",
+			opts: cidrOptions{scheme: "http"},
+			want: []string{"http://[2001:db8::]:8080", "http://[2001:db8::1]:8080"},
+		},
+		{
+			name:    "reserved addresses skipped",
+			cidr:    "127.0.0.0/30",
+			opts:    cidrOptions{port: "8080"},
+			skipped: 2,
+		},
+		{
+			name: "reserved addresses included",
+			cidr: "127.0.0.0/30",
+			opts: cidrOptions{port: "8080", includeReserved: true},
+			want: []string{"127.0.0.1:8080", "127.0.0.2:8080"},
+		},
+		{
+			name: "private addresses are not reserved",
+			cidr: "10.0.0.0/31",
+			want: []string{"10.0.0.0", "10.0.0.1"},
+		},
+		{
+			name:    "huge range refused",
+			cidr:    "10.0.0.0/15",
+			wantErr: "use -force",
+		},
+		{
+			name:    "forced range still capped",
+			cidr:    "10.0.0.0/7",
+			opts:    cidrOptions{force: true},
+			wantErr: "larger than",
+		},
+		{
+			name:    "huge IPv6 range refused",
+			cidr:    "2001:db8::/64",
+			opts:    cidrOptions{force: true},
+			wantErr: "larger than",
+		},
+		{
+			name:    "invalid port",
+			cidr:    "192.0.2.0/30",
+			opts:    cidrOptions{port: "70000"},
+			wantErr: "invalid port",
+		},
+		{
+			name:    "unsupported scheme",
+			cidr:    "192.0.2.0/30",
+			opts:    cidrOptions{scheme: "ftp"},
+			wantErr: "unsupported scheme",
+		},
+		{
+			name:    "invalid CIDR",
+			cidr:    "192.0.2.0",
+			wantErr: "invalid CIDR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, skipped, err := expandCIDR(tt.cidr, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+			if skipped != tt.skipped {
+				t.Errorf("Expected %d reserved addresses skipped, got %d", tt.skipped, skipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/errors"
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/proxy"
+)
+
+// errRunStalled is the cancel cause when the idle watchdog stops a stalled run
+var errRunStalled = stderrors.New("no progress within max idle")
+
+// runStoppedMsg tells the TUI that -max-runtime expired or the run stalled,
+// and it should quit so completed results are written
+type runStoppedMsg struct{}
+
+// stoppedEarly reports whether the run was cut short by -max-runtime or the
+// idle watchdog, as opposed to finishing or being interrupted by the user
+func (s *AppState) stoppedEarly() bool {
+	cause := context.Cause(s.ctx)
+	return cause == context.DeadlineExceeded || cause == errRunStalled
+}
+
+// stopReason describes why the run was cut short
+func (s *AppState) stopReason() string {
+	if context.Cause(s.ctx) == errRunStalled {
+		return "stalled"
+	}
+	return "deadline"
+}
+
+// waitForWorkers waits for wg unless the run is cancelled first, in which case
+// checks still stuck on slow proxies are abandoned. It reports whether every
+// worker finished.
+func (s *AppState) waitForWorkers(wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// markNotChecked adds a failed result for every proxy that had not finished
+// when the run was cut short and stops late checks from adding their results,
+// so output files list each proxy exactly once
+func (s *AppState) markNotChecked() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.resultsClosed {
+		return
+	}
+	s.resultsClosed = true
+
+	checked := make(map[string]bool, len(s.results))
+	for _, result := range s.results {
+		checked[result.ProxyURL] = true
+	}
+	message := "not checked (" + s.stopReason() + ")"
+	for _, proxyURL := range s.proxies {
+		if checked[proxyURL] {
+			continue
+		}
+		s.results = append(s.results, &proxy.ProxyResult{
+			ProxyURL: proxyURL,
+			Input:    s.inputs[proxyURL],
+			Type:     proxy.ProxyTypeUnknown,
+			Error:    errors.NewProxyError(errors.ErrorSystemTimeout, message, proxyURL, context.Cause(s.ctx)),
+		})
+	}
+}
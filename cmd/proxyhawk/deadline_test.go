@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/proxy"
+)
+
+func TestMarkNotCheckedAfterDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	s := &AppState{
+		ctx:     ctx,
+		cancel:  cancel,
+		proxies: []string{"http://1.2.3.4:8080", "http://5.6.7.8:3128"},
+		inputs:  map[string]string{"http://5.6.7.8:3128": "5.6.7.8:3128"},
+		results: []*proxy.ProxyResult{{ProxyURL: "http://1.2.3.4:8080", Working: true}},
+	}
+	if !s.stoppedEarly() {
+		t.Fatal("Expected the deadline to be reported as exceeded")
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	if s.waitForWorkers(&wg) {
+		t.Error("Expected waitForWorkers() to give up on a stuck worker after the deadline")
+	}
+
+	s.markNotChecked()
+	s.markNotChecked()
+	if len(s.results) != 2 {
+		t.Fatalf("Expected one added result per unfinished proxy, got %d results", len(s.results))
+	}
+	unchecked := s.results[1]
+	if unchecked.ProxyURL != "http://5.6.7.8:3128" || unchecked.Input != "5.6.7.8:3128" {
+		t.Errorf("Unexpected result for the unfinished proxy: %+v", unchecked)
+	}
+	if unchecked.Error == nil || !strings.Contains(unchecked.Error.Error(), "not checked (deadline)") {
+		t.Errorf("Expected a deadline error, got %v", unchecked.Error)
+	}
+	if !s.resultsClosed {
+		t.Error("Expected late results to be dropped after marking")
+	}
+}
+
+func TestDeadlineNotExceededOnCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	cancel()
+
+	s := &AppState{ctx: ctx, cancel: cancel}
+	if s.stoppedEarly() {
+		t.Error("Expected a user cancel not to count as the max runtime expiring")
+	}
+}
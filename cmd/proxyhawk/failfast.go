@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/proxy"
+)
+
+// failedProxy is an input proxy that did not produce a working result
+type failedProxy struct {
+	proxyURL string
+	reason   string
+}
+
+// failedProxies returns the proxies, in input order, without a working
+// result. A proxy with no result at all (e.g. the run was interrupted)
+// counts as failed.
+func failedProxies(proxies []string, results []*proxy.ProxyResult) []failedProxy {
+	byURL := make(map[string]*proxy.ProxyResult, len(results))
+	for _, result := range results {
+		if existing, ok := byURL[result.ProxyURL]; !ok || !existing.Working {
+			byURL[result.ProxyURL] = result
+		}
+	}
+
+	var failed []failedProxy
+	for _, proxyURL := range proxies {
+		result, ok := byURL[proxyURL]
+		switch {
+		case !ok:
+			failed = append(failed, failedProxy{proxyURL: proxyURL, reason: "not checked"})
+		case !result.Working:
+			reason := "not working"
+			if result.Error != nil {
+				reason = result.Error.Error()
+			}
+			failed = append(failed, failedProxy{proxyURL: proxyURL, reason: reason})
+		}
+	}
+	return failed
+}
+
+// reportFailedProxies writes the proxies that did not work to w for
+// -fail-fast and reports whether every proxy worked
+func (s *AppState) reportFailedProxies(w io.Writer) bool {
+	s.mutex.RLock()
+	failed := failedProxies(s.proxies, s.results)
+	s.mutex.RUnlock()
+
+	if len(failed) == 0 {
+		return true
+	}
+	fmt.Fprintf(w, "%d of %d proxies failed:\n", len(failed), len(s.proxies))
+	for _, f := range failed {
+		name := f.proxyURL
+		if s.preserveInput && s.inputs[f.proxyURL] != "" {
+			name = s.inputs[f.proxyURL]
+		}
+		fmt.Fprintf(w, "  %s: %s\n", proxy.RedactProxyURL(name), f.reason)
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// goroutineSampleInterval is how often the goroutine guard counts goroutines
+const goroutineSampleInterval = time.Second
+
+// goroutineGuard watches the number of goroutines in the process, which
+// leaks in long runs (large lists, streamed output, vuln scans with
+// interactsh polling) can drive into the hundreds of thousands. Above the limit it
+// reports once per excursion and, when throttling, lets new checks start only
+// one at a time until the count is back under the limit. A nil guard does
+// nothing.
+type goroutineGuard struct {
+	limit    int
+	throttle bool
+	count    int64         // Last sampled goroutine count, updated atomically
+	peak     int64         // Highest sampled count, updated atomically
+	over     int32         // 1 while the last sample exceeded limit, updated atomically
+	slot     chan struct{} // Admits one new check at a time while throttled
+}
+
+// newGoroutineGuard returns a guard for limit goroutines, or nil if limit is
+// not positive
+func newGoroutineGuard(limit int, throttle bool) *goroutineGuard {
+	if limit <= 0 {
+		return nil
+	}
+	return &goroutineGuard{limit: limit, throttle: throttle, slot: make(chan struct{}, 1)}
+}
+
+// sample records count and reports whether it just went over the limit
+func (g *goroutineGuard) sample(count int) bool {
+	atomic.StoreInt64(&g.count, int64(count))
+	for {
+		peak := atomic.LoadInt64(&g.peak)
+		if int64(count) <= peak || atomic.CompareAndSwapInt64(&g.peak, peak, int64(count)) {
+			break
+		}
+	}
+
+	if count > g.limit {
+		return atomic.SwapInt32(&g.over, 1) == 0
+	}
+	atomic.StoreInt32(&g.over, 0)
+	return false
+}
+
+// run samples the goroutine count until ctx is done, calling onSample with
+// every count and onExceeded each time the count goes over the limit
+func (g *goroutineGuard) run(ctx context.Context, onSample func(count int), onExceeded func(count int)) {
+	if g == nil {
+		return
+	}
+
+	ticker := time.NewTicker(goroutineSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count := runtime.NumGoroutine()
+			if g.sample(count) {
+				onExceeded(count)
+			}
+			onSample(count)
+		}
+	}
+}
+
+// admit waits until a worker may start another check and returns the
+// function to call when the check is done. Checks start freely unless the
+// guard throttles and the goroutine count is over the limit.
+func (g *goroutineGuard) admit(ctx context.Context) func() {
+	if g == nil || !g.throttle || atomic.LoadInt32(&g.over) == 0 {
+		return func() {}
+	}
+	select {
+	case g.slot <- struct{}{}:
+		return func() { <-g.slot }
+	case <-ctx.Done():
+		return func() {}
+	}
+}
+
+// Peak returns the highest goroutine count sampled so far
+func (g *goroutineGuard) Peak() int {
+	if g == nil {
+		return 0
+	}
+	return int(atomic.LoadInt64(&g.peak))
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGoroutineGuardSample(t *testing.T) {
+	g := newGoroutineGuard(100, false)
+
+	if g.sample(50) {
+		t.Error("Expected no report under the limit")
+	}
+	if !g.sample(150) {
+		t.Error("Expected a report when the count goes over the limit")
+	}
+	if g.sample(200) {
+		t.Error("Expected one report per excursion over the limit")
+	}
+	if g.sample(80) {
+		t.Error("Expected no report when the count drops back")
+	}
+	if !g.sample(120) {
+		t.Error("Expected a report for a new excursion over the limit")
+	}
+	if peak := g.Peak(); peak != 200 {
+		t.Errorf("Peak() = %d, want 200", peak)
+	}
+}
+
+func TestGoroutineGuardThrottle(t *testing.T) {
+	g := newGoroutineGuard(100, true)
+	ctx := context.Background()
+
+	// Under the limit, checks start freely
+	first, second := g.admit(ctx), g.admit(ctx)
+	first()
+	second()
+
+	// Over the limit, a second check waits until the first is done
+	g.sample(150)
+	release := g.admit(ctx)
+	admitted := make(chan func())
+	go func() { admitted <- g.admit(ctx) }()
+	select {
+	case <-admitted:
+		t.Fatal("Expected the second check to wait while over the limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	select {
+	case next := <-admitted:
+		next()
+	case <-time.After(time.Second):
+		t.Fatal("Expected the second check to start once the first was done")
+	}
+
+	// A cancelled run is not held up
+	g.sample(150)
+	release = g.admit(ctx)
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	g.admit(cancelled)()
+	release()
+}
+
+func TestGoroutineGuardDisabled(t *testing.T) {
+	g := newGoroutineGuard(0, true)
+	if g != nil {
+		t.Fatal("Expected no guard when max goroutines is 0")
+	}
+
+	// A nil guard is safe to use and returns immediately
+	g.admit(context.Background())()
+	g.run(context.Background(), nil, nil)
+	if g.Peak() != 0 {
+		t.Error("Expected no peak from a nil guard")
+	}
+
+	// Without throttling, checks are never held back
+	g = newGoroutineGuard(100, false)
+	g.sample(150)
+	first, second := g.admit(context.Background()), g.admit(context.Background())
+	first()
+	second()
+}
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"runtime"
 	"strings"
 	"sync"
 	"syscall"
@@ -28,7 +31,9 @@ import (
 	"github.com/ResistanceIsUseless/ProxyHawk/internal/pool"
 	progresspkg "github.com/ResistanceIsUseless/ProxyHawk/internal/progress"
 	"github.com/ResistanceIsUseless/ProxyHawk/internal/proxy"
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/sshtunnel"
 	"github.com/ResistanceIsUseless/ProxyHawk/internal/ui"
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/worker"
 )
 
 // AppState represents the application state
@@ -36,14 +41,23 @@ type AppState struct {
 	view        *ui.View
 	checker     *proxy.Checker
 	proxies     []string
+	timeouts    map[string]time.Duration // Per-proxy timeout overrides from the proxy list
+	countries   map[string]string        // Per-proxy expected exit countries from the proxy list
+	inputs      map[string]string        // Proxies as written in the proxy list, keyed by normalized URL
 	results     []*proxy.ProxyResult
 	concurrency int
+	startLimit  *worker.StartLimiter // Global cap on how fast new checks are started (nil = unlimited)
 	verbose     bool
 	debug       bool
 	logger      *logging.Logger
 	mutex       sync.RWMutex // RWMutex to protect shared state (allows concurrent reads)
 	updateChan  chan tea.Msg // Channel for sending updates to the UI
 
+	// Per-type worker pool sizes; when either is set HTTP and SOCKS proxies get
+	// separate pools (0 = use concurrency)
+	concurrencyHTTP  int
+	concurrencySOCKS int
+
 	// Terminal dimensions
 	width  int
 	height int
@@ -56,12 +70,32 @@ type AppState struct {
 	cancel       context.CancelFunc
 	shutdownChan chan os.Signal
 
+	// Overall run deadline from -max-runtime (0 = none) and the stall watchdog from max_idle
+	maxRuntime    time.Duration
+	watchdog      *idleWatchdog
+	goroutines    *goroutineGuard            // Goroutine count guardrail from max_goroutines (nil = disabled)
+	pause         *pauseGate                 // Pauses proxy feeding from the TUI (nil in -no-ui mode)
+	throughput    *progresspkg.RateEstimator // Recent checks per second behind the TUI ETA
+	resultsClosed bool                       // Set once unfinished proxies are marked, late results are dropped
+
 	// Output options
 	outputFile    string
 	jsonFile      string
+	jsonSorted    bool
+	sortBy        string             // Order of results in output files ("" = completion order, "score", "connect")
+	classFilter   []proxy.ProxyClass // Only output proxies of these classes (empty = all)
 	workingFile   string
 	anonymousFile string
+	csvFile       string
+	csvColumns    []string
+	jsonlWriter   *output.JSONLWriter
+	syslog        *logging.Logger // Per-proxy results and the summary sent to syslog (nil = disabled)
+	syslogCloser  io.Closer
+	htmlFile      string
+	binaryFile    string
+	preserveInput bool
 	noUI          bool
+	failFast      bool // Exit non-zero unless every proxy worked
 
 	// Progress indicator for non-TUI mode
 	progressIndicator progresspkg.ProgressIndicator
@@ -71,8 +105,21 @@ type AppState struct {
 
 	// Config watcher for hot-reloading
 	configWatcher *config.ConfigWatcher
+
+	// Post-run keep-warm settings
+	keepWarm       time.Duration
+	connectionPool *pool.ConnectionPool
+	validationURL  string
+	timeout        time.Duration
+
+	// Resume support: proxies already checked, persisted as results complete
+	checkpoint *checkpoint
 }
 
+// keepWarmInterval is how often working proxies are pinged while keeping them warm.
+// It stays below the default idle connection timeout so pooled connections are reused.
+const keepWarmInterval = 15 * time.Second
+
 // Define custom message types
 type progressUpdateMsg struct{}
 
@@ -93,36 +140,81 @@ type allChecksCompleteMsg struct {
 }
 
 func main() {
+	// Subcommands come before any flags
+	if len(os.Args) > 1 && os.Args[1] == "validate-list" {
+		os.Exit(runValidateList(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	// Parse command line flags
 	proxyList := flag.String("l", "", "File containing list of proxies")
 	proxyHost := flag.String("host", "", "Single proxy host (IP, hostname, or IP:PORT) to test")
 	proxyCIDR := flag.String("cidr", "", "CIDR range to test (e.g., 192.168.1.0/24, or 192.168.1.0/24:8080 to specify port)")
+	cidrPort := flag.String("port", "", "Port to check on every address of the -cidr range")
+	cidrScheme := flag.String("scheme", "", "Scheme for candidates from the -cidr range (http, https, socks4, socks5)")
+	cidrForce := flag.Bool("force", false, "Allow -cidr ranges larger than 65536 addresses")
+	cidrIncludeReserved := flag.Bool("include-reserved", false, "Keep loopback, link-local, multicast and other reserved addresses of the -cidr range")
 	configFile := flag.String("config", "config/default.yaml", "Path to config file")
 	verbose := flag.Bool("v", false, "Enable verbose output")
 	debug := flag.Bool("d", false, "Enable debug mode")
 	concurrency := flag.Int("c", 0, "Number of concurrent checks (overrides config)")
+	concurrencyHTTP := flag.Int("concurrency-http", 0, "Run HTTP(S) proxies in their own worker pool of this size")
+	concurrencySOCKS := flag.Int("concurrency-socks", 0, "Run SOCKS proxies in their own worker pool of this size")
+	vulnConcurrency := flag.Int("vuln-concurrency", 0, "Maximum number of proxies in the vuln scan phase at once (overrides config, 0 = no separate limit)")
 	useRDNS := flag.Bool("r", false, "Use rDNS lookup for host headers")
 	timeout := flag.Int("t", 0, "Timeout in seconds (overrides config)")
 	hotReload := flag.Bool("hot-reload", false, "Enable configuration hot-reloading")
 
+	// SSH tunnel flags
+	sshTunnel := flag.String("ssh-tunnel", "", "Route proxy checks through an SSH tunnel to this bastion (user@host[:port])")
+	sshKey := flag.String("ssh-key", "", "Private key for the SSH tunnel (default: ~/.ssh/id_ed25519, id_ecdsa, id_rsa and ssh-agent)")
+	sshKnownHosts := flag.String("ssh-known-hosts", "", "Known hosts file used to verify the bastion (default: ~/.ssh/known_hosts)")
+	proxyChain := flag.String("proxy-chain", "", "Reach every proxy through this jump proxy, e.g. socks5://jump:1080, to test proxies only reachable via another proxy (overrides config)")
+
 	// Rate limiting flags
 	rateLimitEnabled := flag.Bool("rate-limit", false, "Enable rate limiting")
 	rateLimitDelay := flag.Duration("rate-delay", 1*time.Second, "Delay between requests (e.g. 500ms, 1s, 2s)")
 	rateLimitPerHost := flag.Bool("rate-per-host", true, "Apply rate limiting per host instead of globally")
 	rateLimitPerProxy := flag.Bool("rate-per-proxy", false, "Apply rate limiting per individual proxy (takes precedence over per-host)")
+	proxiesPerSecond := flag.Float64("pps", 0, "Maximum number of proxy checks started per second across all workers (0 = unlimited)")
 
 	// Output flags
 	outputFile := flag.String("o", "", "Output results to text file")
 	jsonFile := flag.String("j", "", "Output results to JSON file")
+	jsonSorted := flag.Bool("json-sorted", false, "Sort JSON results by proxy URL so runs diff cleanly regardless of completion order")
+	sortBy := flag.String("sort", "", "Sort results in the output files: score (highest quality score first) or connect (fastest proxy connect first)")
 	workingFile := flag.String("wp", "", "Output working proxies to file")
 	anonymousFile := flag.String("wpa", "", "Output working anonymous proxies to file")
+	warningsJSON := flag.String("warnings-json", "", "Output loader and config warnings to a JSON file")
+	csvFile := flag.String("csv", "", "Output results to CSV file")
+	htmlFile := flag.String("html", "", "Output a self-contained HTML report with a sortable results table")
+	binaryFile := flag.String("binary-out", "", "Output results to a compact binary (gob) file for fast re-loading with -binary-in")
+	binaryIn := flag.String("binary-in", "", "Load results saved with -binary-out and write them to the output files instead of checking proxies")
+	preserveInput := flag.Bool("preserve-input", false, "Write proxies to output files exactly as written in the proxy list instead of normalized URLs")
+	jsonlFile := flag.String("jsonl", "", "Stream results to a JSON-lines file as each check completes")
+	csvColumnsSpec := flag.String("csv-columns", "", "Comma-separated CSV columns (e.g. proxy,type,speed,anon); default: "+strings.Join(output.DefaultCSVColumns, ","))
+	includeTimingInCSV := flag.Bool("include-timing-in-csv", false, "Add timing breakdown columns (speed_ms, check_times_ms, checked_at) to CSV output")
 	noUI := flag.Bool("no-ui", false, "Disable terminal UI (for automation/scripting)")
+	checkpointPath := flag.String("checkpoint", "", "Record checked proxies in this file and skip them on the next run, so an interrupted scan can resume")
+	maxRuntime := flag.Duration("max-runtime", 0, "Stop the run after this long (e.g. 10m), abandoning unfinished checks but still writing output for completed ones")
+	flushInterval := flag.Duration("flush-interval", 0, "Write streamed results out this often instead of after every result (e.g. 5s, overrides config)")
+	fsyncOutput := flag.Bool("fsync", false, "fsync streamed output on every write so results survive an OS crash")
+	debugLines := flag.Int("debug-lines", 0, "Debug log lines kept for scrolling in the TUI debug view (default 1000, overrides config)")
+	maxGoroutines := flag.Int("max-goroutines", 0, "Warn when the process runs more goroutines than this, and with throttle_goroutines start new checks one at a time until it drops (overrides config)")
+	maxIdle := flag.Duration("max-idle", 0, "Stop the run if no check completes for this long (e.g. 2m), writing partial results (overrides config)")
+	failFast := flag.Bool("fail-fast", false, "Exit with status 1 if any proxy in the list is not working, listing the failures on stderr (for CI/deploy gates)")
+	keepWarm := flag.Duration("keep-warm", 0, "After the run, keep pooled connections to working proxies alive for this long (e.g. 5m); stops early on SIGINT/SIGTERM")
 
 	// Progress indicator flags
-	progressType := flag.String("progress", "bar", "Progress indicator type for non-TUI mode (none, basic, bar, spinner, dots, percent)")
+	progressType := flag.String("progress", "bar", "Progress indicator type for non-TUI mode (none, basic, bar, spinner, dots, percent, line)")
 	progressWidth := flag.Int("progress-width", 50, "Width of progress bar")
 	progressNoColor := flag.Bool("progress-no-color", false, "Disable colored progress output")
 
+	// Syslog flags
+	syslogEnabled := flag.Bool("syslog", false, "Send per-proxy results and the summary to syslog as RFC 5424 messages")
+	syslogAddr := flag.String("syslog-addr", "", "Syslog daemon address: udp://host:514, tcp://host:514, unix:///path or host:port (default: local syslog socket)")
+	syslogFacility := flag.String("syslog-facility", "", "Syslog facility, e.g. user, daemon, local0 (overrides config)")
+	syslogSeverity := flag.String("syslog-severity", "", "Syslog severity of result and summary messages, e.g. info, notice (overrides config)")
+
 	// Metrics flags
 	enableMetrics := flag.Bool("metrics", false, "Enable Prometheus metrics endpoint")
 	metricsAddr := flag.String("metrics-addr", ":9090", "Address to serve metrics on")
@@ -131,11 +223,33 @@ func main() {
 	// Protocol flags
 	enableHTTP2 := flag.Bool("http2", false, "Enable HTTP/2 protocol detection and support")
 	enableHTTP3 := flag.Bool("http3", false, "Enable HTTP/3 protocol detection and support")
+	maxBodyCompare := flag.Int("max-body-compare", 0, "Compare up to this many body bytes with a direct fetch to detect altered content (0 = disabled)")
+	similarityThreshold := flag.Float64("similarity-threshold", 0, "Similarity (0-1) below which proxied content is flagged as altered (overrides config)")
+	detectionOrder := flag.String("detection-order", "", "Proxy types to try, in order, when detecting a proxy's type (comma-separated, e.g. socks5,socks4,http,https)")
+	resolveOnce := flag.Bool("resolve-once", false, "Resolve each target hostname once and reuse it for the DNS cache TTL instead of per check")
+	resolverSpec := flag.String("resolver", "", "DNS server for proxy and target hostnames, host[:port] (e.g. 1.1.1.1:53) or a DNS-over-HTTPS URL (overrides config)")
+	followRedirects := flag.Bool("follow-redirects", false, "Follow redirects of validation requests, aborting loops and chains longer than max_redirects (redirect_failure)")
+	forceIPv6Target := flag.Bool("6", false, "Validate against the IPv6 address of the validation host and report which proxies reach IPv6 targets (supports_ipv6_target)")
+	minimalHeaders := flag.Bool("minimal-headers", false, "Also send the validation request with only Host and User-Agent and report proxies that only work without the full header set")
+	echoHeaders := flag.Bool("echo-headers", false, "Record the full set of headers the target received through each working proxy (received_headers)")
+	measureThroughput := flag.Bool("measure-throughput", false, "Download a payload (default 1MB) through each working proxy and report its throughput in MB/s (throughput_mbps)")
+	verifyTLS := flag.Bool("verify-tls", false, "Verify target certificates during checks instead of accepting any certificate (verify_tls in config)")
+	inspectTLS := flag.Bool("inspect-tls", false, "Record the certificate of HTTPS validation targets and flag proxies that intercept TLS (tls_info)")
+	detectRotation := flag.Bool("detect-rotation", false, "Sample the exit IP of each working proxy several times to detect rotating pools (is_rotating, observed_exit_ips); multiplies requests per proxy")
+	egressPTR := flag.Bool("egress-ptr", false, "Record the IP each working proxy egresses from and its reverse DNS name (egress_ip, egress_ptr); adds a lookup per proxy")
+	webSocketCheck := flag.Bool("websocket", false, "Verify each working proxy can carry a WebSocket by echoing a frame through it (supports_websocket)")
+	suspiciousCheck := flag.Bool("suspicious-check", false, "Score each working proxy for honeypot-like behavior: accepting any credentials, identical answers, injected trackers, implausible latency (suspicious_score)")
+	checkReputation := flag.Bool("check-reputation", false, "Look up the exit IP of each working proxy on DNS blocklists (default zen.spamhaus.org) and an optional abuse-score API (reputation_score, blocklists)")
+	categoryCheck := flag.Bool("category-check", false, "Report which site categories (social, adult, news, streaming) each working proxy can reach (category_access)")
+	classSpec := flag.String("class", "", "Classify working proxies by exit network and only output these classes (comma-separated: datacenter, residential, mobile, unknown)")
+	requireBoth := flag.Bool("require-both", false, "Only report proxies that handle both HTTP and HTTPS targets as working")
+	httpVersion := flag.String("http-version", "", "HTTP request version to test proxies with (1.0 or 1.1); 1.0 detects proxies that only speak HTTP/1.0")
 
 	// Check mode flags
 	checkMode := flag.String("mode", "basic", "Check mode: basic (connectivity only), intense (advanced security checks), vulns (vulnerability scanning)")
 	enableAdvancedChecks := flag.Bool("advanced", false, "Enable advanced security checks (overrides mode)")
 	enableInteractsh := flag.Bool("interactsh", false, "Enable Interactsh for out-of-band detection (enhances security checks)")
+	dnsLeak := flag.Bool("dns-leak", false, "Detect proxies that leak DNS lookups to the client's resolver (uses Interactsh)")
 	enableFingerprint := flag.Bool("fingerprint", false, "Enable proxy fingerprinting to identify proxy software/vendor")
 	enablePathFingerprint := flag.Bool("path-fingerprint", false, "Enable path-based fingerprinting to test multiple endpoints and detect backend routing")
 	pathFingerprintPaths := flag.String("paths", "", "Comma-separated list of custom paths to test (default: /, /admin, /api, /v1, etc.)")
@@ -183,8 +297,8 @@ func main() {
 	}
 
 	// Validate required flags - proxy list, host, or CIDR is required unless in discovery mode
-	if *proxyList == "" && *proxyHost == "" && *proxyCIDR == "" && !*discoverMode {
-		help.PrintUsageError(os.Stderr, fmt.Errorf("one of -l (file), -host (single host), -cidr (CIDR range), or -discover mode is required"), noColor)
+	if *proxyList == "" && *proxyHost == "" && *proxyCIDR == "" && !*discoverMode && *binaryIn == "" {
+		help.PrintUsageError(os.Stderr, fmt.Errorf("one of -l (file), -host (single host), -cidr (CIDR range), -binary-in (saved results), or -discover mode is required"), noColor)
 		os.Exit(1)
 	}
 
@@ -202,8 +316,15 @@ func main() {
 	if *discoverMode {
 		inputCount++
 	}
+	if *binaryIn != "" {
+		inputCount++
+	}
 	if inputCount > 1 {
-		help.PrintUsageError(os.Stderr, fmt.Errorf("only one of -l, -host, -cidr, or -discover can be used at a time"), noColor)
+		help.PrintUsageError(os.Stderr, fmt.Errorf("only one of -l, -host, -cidr, -binary-in, or -discover can be used at a time"), noColor)
+		os.Exit(1)
+	}
+	if *proxyCIDR == "" && (*cidrPort != "" || *cidrScheme != "" || *cidrForce || *cidrIncludeReserved) {
+		help.PrintUsageError(os.Stderr, fmt.Errorf("-port, -scheme, -force and -include-reserved only apply to -cidr"), noColor)
 		os.Exit(1)
 	}
 
@@ -266,9 +387,27 @@ func main() {
 	if *concurrency > 0 {
 		cfg.Concurrency = *concurrency
 	}
+	if *vulnConcurrency > 0 {
+		cfg.VulnScanConcurrency = *vulnConcurrency
+	}
 	if *timeout > 0 {
 		cfg.Timeout = *timeout
 	}
+	if *maxIdle > 0 {
+		cfg.MaxIdle = *maxIdle
+	}
+	if *maxGoroutines > 0 {
+		cfg.MaxGoroutines = *maxGoroutines
+	}
+	if *debugLines > 0 {
+		cfg.DebugLogLines = *debugLines
+	}
+	if *flushInterval > 0 {
+		cfg.OutputFlushInterval = *flushInterval
+	}
+	if *fsyncOutput {
+		cfg.OutputFsync = true
+	}
 
 	// Override metrics config with CLI flags
 	if *enableMetrics {
@@ -277,6 +416,20 @@ func main() {
 		cfg.Metrics.Path = *metricsPath
 	}
 
+	// Override syslog config with CLI flags
+	if *syslogEnabled {
+		cfg.Syslog.Enabled = true
+	}
+	if *syslogAddr != "" {
+		cfg.Syslog.Address = *syslogAddr
+	}
+	if *syslogFacility != "" {
+		cfg.Syslog.Facility = *syslogFacility
+	}
+	if *syslogSeverity != "" {
+		cfg.Syslog.Severity = *syslogSeverity
+	}
+
 	// Override protocol settings with CLI flags
 	if *enableHTTP2 {
 		cfg.EnableHTTP2 = true
@@ -284,6 +437,77 @@ func main() {
 	if *enableHTTP3 {
 		cfg.EnableHTTP3 = true
 	}
+	if *httpVersion != "" {
+		cfg.HTTPVersion = *httpVersion
+	}
+	if *detectionOrder != "" {
+		cfg.DetectionOrder = nil
+		for _, name := range strings.Split(*detectionOrder, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				cfg.DetectionOrder = append(cfg.DetectionOrder, name)
+			}
+		}
+	}
+	if *resolveOnce {
+		cfg.ResolveOnce = true
+	}
+	if *resolverSpec != "" {
+		cfg.Resolver = *resolverSpec
+	}
+	if *proxyChain != "" {
+		cfg.ProxyChain = *proxyChain
+	}
+	if *requireBoth {
+		cfg.RequireBothHTTPAndHTTPS = true
+	}
+	if *followRedirects {
+		cfg.FollowRedirects = true
+	}
+	if *forceIPv6Target {
+		cfg.ForceIPv6Target = true
+	}
+	if *minimalHeaders {
+		cfg.MinimalHeaders = true
+	}
+	if *echoHeaders {
+		cfg.EchoHeaders = true
+	}
+	if *webSocketCheck {
+		cfg.WebSocketCheck = true
+	}
+	if *egressPTR {
+		cfg.EgressPTR = true
+	}
+	if *measureThroughput {
+		cfg.Throughput.Enabled = true
+	}
+	if *inspectTLS {
+		cfg.TLSInspection.Enabled = true
+	}
+	if *verifyTLS {
+		cfg.VerifyTLS = true
+	}
+	if *detectRotation {
+		cfg.RotationDetection.Enabled = true
+	}
+	if *suspiciousCheck {
+		cfg.SuspiciousCheck.Enabled = true
+	}
+	if *categoryCheck {
+		cfg.CategoryCheck.Enabled = true
+	}
+	if *checkReputation {
+		cfg.Reputation.Enabled = true
+	}
+
+	// Override content similarity settings with CLI flags
+	if *maxBodyCompare > 0 {
+		cfg.ContentSimilarity.Enabled = true
+		cfg.ContentSimilarity.MaxBodyBytes = *maxBodyCompare
+	}
+	if *similarityThreshold > 0 {
+		cfg.ContentSimilarity.Threshold = *similarityThreshold
+	}
 
 	// Override fingerprinting setting with CLI flag
 	if *enableFingerprint {
@@ -353,12 +577,55 @@ func main() {
 		}
 	}
 
+	// Collect warnings for machine-readable output
+	collectedWarnings := output.NewWarnings(output.WarningSourceConfig, validationResult.Warnings)
+	writeWarnings := func() {
+		if *warningsJSON == "" {
+			return
+		}
+		if err := output.WriteWarningsJSON(*warningsJSON, collectedWarnings); err != nil {
+			logger.Error("Failed to write warnings", "error", err, "file", *warningsJSON)
+		} else {
+			logger.ResultsSaved(*warningsJSON, "warnings_json")
+		}
+	}
+
+	// Resolve CSV columns up front so a typo fails before any checks run
+	csvColumns, err := output.ParseCSVColumns(*csvColumnsSpec)
+	if err != nil {
+		logger.Error("Invalid CSV columns", "error", err, "columns", *csvColumnsSpec)
+		os.Exit(1)
+	}
+	if *includeTimingInCSV {
+		csvColumns = output.WithTimingColumns(csvColumns)
+	}
+
+	if *sortBy != "" && *sortBy != "score" && *sortBy != "connect" {
+		logger.Error("Invalid sort order, supported: score, connect", "sort", *sortBy)
+		os.Exit(1)
+	}
+
+	// Filtering by proxy class needs every working proxy classified
+	var classFilter []proxy.ProxyClass
+	if *classSpec != "" {
+		for _, name := range strings.Split(*classSpec, ",") {
+			class, err := proxy.ParseProxyClass(name)
+			if err != nil {
+				logger.Error("Invalid proxy class filter", "error", err, "class", *classSpec)
+				os.Exit(1)
+			}
+			classFilter = append(classFilter, class)
+		}
+		cfg.ProxyClass.Enabled = true
+	}
+
 	// Check for validation errors
 	if !validationResult.Valid {
 		logger.Error("Configuration validation failed", "errors", len(validationResult.Errors))
 		for _, validationErr := range validationResult.Errors {
 			logger.Error("Configuration error", "error", validationErr.Error())
 		}
+		writeWarnings()
 		os.Exit(1)
 	}
 
@@ -402,6 +669,12 @@ func main() {
 		logger.Info("Interactsh enabled for out-of-band detection")
 	}
 
+	// DNS leak detection needs Interactsh to see where lookups come from
+	if *dnsLeak {
+		cfg.AdvancedChecks.TestDNSLeak = true
+		cfg.AdvancedChecks.DisableInteractsh = false
+	}
+
 	// Override discovery settings with CLI flags
 	if *discoverCountries != "" {
 		cfg.Discovery.Countries = strings.Split(*discoverCountries, ",")
@@ -422,15 +695,57 @@ func main() {
 		return
 	}
 
+	// Re-emit saved results in the requested formats without checking anything
+	if *binaryIn != "" {
+		summary, err := output.ReadBinaryOutput(*binaryIn)
+		if err != nil {
+			logger.Error("Failed to read binary results", "error", err, "file", *binaryIn)
+			os.Exit(1)
+		}
+		logger.Info("Results loaded", "count", len(summary.Results), "file", *binaryIn)
+		state := &AppState{
+			logger:        logger,
+			outputFile:    *outputFile,
+			jsonFile:      *jsonFile,
+			jsonSorted:    *jsonSorted,
+			csvFile:       *csvFile,
+			csvColumns:    csvColumns,
+			htmlFile:      *htmlFile,
+			binaryFile:    *binaryFile,
+			workingFile:   *workingFile,
+			anonymousFile: *anonymousFile,
+		}
+		state.writeOutputFiles(summary, summary.Results)
+		return
+	}
+
 	// Load proxies based on input method
 	var proxies []string
 	var warnings []string
+	proxyTimeouts := make(map[string]time.Duration)
+	proxyInputs := make(map[string]string)
+	proxyCountries := make(map[string]string)
 
 	if *proxyList != "" {
 		// Load from file
+		var entries []loader.ProxyEntry
 		var loadErr error
-		proxies, warnings, loadErr = loader.LoadProxies(*proxyList)
+		entries, warnings, loadErr = loader.LoadProxies(*proxyList)
+		proxies = loader.URLs(entries)
+		for _, entry := range entries {
+			if entry.Timeout > 0 {
+				proxyTimeouts[entry.URL] = entry.Timeout
+			}
+			if entry.Input != entry.URL {
+				proxyInputs[entry.URL] = entry.Input
+			}
+			if entry.ExpectCountry != "" {
+				proxyCountries[entry.URL] = entry.ExpectCountry
+			}
+		}
+		collectedWarnings = append(collectedWarnings, output.NewWarnings(output.WarningSourceLoader, warnings)...)
 		if loadErr != nil {
+			writeWarnings()
 			category := errors.GetErrorCategory(loadErr)
 			logger.Error("Failed to load proxies",
 				"error", loadErr,
@@ -447,7 +762,13 @@ func main() {
 	} else if *proxyCIDR != "" {
 		// CIDR range
 		var cidrErr error
-		proxies, cidrErr = expandCIDR(*proxyCIDR)
+		var reservedSkipped int
+		proxies, reservedSkipped, cidrErr = expandCIDR(*proxyCIDR, cidrOptions{
+			port:            *cidrPort,
+			scheme:          *cidrScheme,
+			force:           *cidrForce,
+			includeReserved: *cidrIncludeReserved,
+		})
 		if cidrErr != nil {
 			logger.Error("Failed to expand CIDR range",
 				"error", cidrErr,
@@ -455,11 +776,15 @@ func main() {
 			os.Exit(1)
 		}
 		logger.Info("Expanded CIDR range", "cidr", *proxyCIDR, "count", len(proxies))
+		if reservedSkipped > 0 {
+			logger.Info("Skipped reserved addresses in CIDR range (use -include-reserved to keep them)", "count", reservedSkipped)
+		}
 	}
 
 	// Check if we have any proxies to work with
 	if len(proxies) == 0 {
 		logger.Error("No valid proxies found to check")
+		writeWarnings()
 		os.Exit(1)
 	}
 
@@ -467,6 +792,39 @@ func main() {
 	for _, warning := range warnings {
 		logger.Warn("Proxy loading warning", "warning", warning)
 	}
+	writeWarnings()
+
+	// Skip proxies an interrupted run already checked
+	var scanCheckpoint *checkpoint
+	if *checkpointPath != "" {
+		scanCheckpoint, err = loadCheckpoint(*checkpointPath)
+		if err != nil {
+			logger.Error("Failed to load checkpoint", "error", err, "file", *checkpointPath)
+			os.Exit(1)
+		}
+		total := len(proxies)
+		proxies = scanCheckpoint.remaining(proxies)
+		if skipped := total - len(proxies); skipped > 0 {
+			logger.Info("Resuming from checkpoint", "file", *checkpointPath, "skipped", skipped, "remaining", len(proxies))
+		}
+		if len(proxies) == 0 {
+			logger.Info("All proxies were already checked according to the checkpoint", "file", *checkpointPath)
+			return
+		}
+	}
+
+	// More workers than proxies would only sit idle and inflate the worker wait
+	// timeout. Only an explicit -c is worth a warning; a configured default is
+	// reduced quietly.
+	if clamped, reduced := effectiveConcurrency(cfg.Concurrency, len(proxies)); reduced {
+		if *concurrency > 0 {
+			warning := fmt.Sprintf("concurrency %d exceeds the %d proxies to check, using %d workers", cfg.Concurrency, len(proxies), clamped)
+			logger.Warn("Concurrency exceeds proxy count", "concurrency", cfg.Concurrency, "proxies", len(proxies), "effective_concurrency", clamped)
+			collectedWarnings = append(collectedWarnings, output.NewWarnings(output.WarningSourceConfig, []string{warning})...)
+			writeWarnings()
+		}
+		cfg.Concurrency = clamped
+	}
 
 	// Initialize metrics collector
 	var metricsCollector *metrics.Collector
@@ -479,6 +837,80 @@ func main() {
 		}
 	}
 
+	// Load additional trusted roots for target verification
+	rootCAs, err := proxy.LoadCACertPool(cfg.CACertFile)
+	if err != nil {
+		logger.Error("Failed to load CA certificates", "file", cfg.CACertFile, "error", err)
+		os.Exit(1)
+	}
+
+	// os.Exit skips deferred calls, so exits after the SSH tunnel is up go
+	// through exit to close it first
+	var tunnel *sshtunnel.Tunnel
+	exit := func(code int) {
+		if tunnel != nil {
+			tunnel.Close()
+		}
+		os.Exit(code)
+	}
+
+	// Route proxy connections through the SSH bastion if one was given
+	var baseDialer func(ctx context.Context, network, addr string) (net.Conn, error)
+	if *sshTunnel != "" {
+		tunnel, err = sshtunnel.Open(sshtunnel.Config{
+			Target:         *sshTunnel,
+			KeyFile:        *sshKey,
+			KnownHostsFile: *sshKnownHosts,
+			Timeout:        time.Duration(cfg.Timeout) * time.Second,
+		})
+		if err != nil {
+			logger.Error("Failed to set up SSH tunnel", "bastion", *sshTunnel, "error", err)
+			os.Exit(1)
+		}
+		defer tunnel.Close()
+		baseDialer = tunnel.DialContext
+		logger.Info("Routing proxy checks through SSH tunnel", "bastion", tunnel.Addr())
+	}
+
+	// Pin DNS lookups to the configured resolver. Its own queries go through
+	// the SSH tunnel and the jump proxy like the checks do; the jump proxy's
+	// hostname is looked up with the system resolver to bootstrap the chain.
+	dnsDialer := baseDialer
+	if cfg.Resolver != "" && cfg.ProxyChain != "" {
+		chainDialer, err := proxy.NewChainDialer(cfg.ProxyChain, baseDialer, nil)
+		if err != nil {
+			logger.Error("Invalid proxy chain", "proxy_chain", proxy.RedactProxyURL(cfg.ProxyChain), "error", err)
+			exit(1)
+		}
+		dnsDialer = chainDialer
+	}
+	resolver, err := proxy.NewResolver(cfg.Resolver, dnsDialer)
+	if err != nil {
+		logger.Error("Invalid DNS resolver", "resolver", cfg.Resolver, "error", err)
+		exit(1)
+	}
+	if resolver != nil {
+		// The bastion resolves the proxies it dials itself
+		if *sshTunnel == "" {
+			dialer := &net.Dialer{Resolver: resolver}
+			baseDialer = dialer.DialContext
+		}
+		logger.Info("Resolving hostnames with custom DNS resolver", "resolver", cfg.Resolver)
+	}
+
+	// Pooled clients reach proxies through the jump proxy too; the checker
+	// builds its own chain on top of baseDialer to report each hop
+	poolDialer := baseDialer
+	if cfg.ProxyChain != "" {
+		chainDialer, err := proxy.NewChainDialer(cfg.ProxyChain, baseDialer, resolver)
+		if err != nil {
+			logger.Error("Invalid proxy chain", "proxy_chain", proxy.RedactProxyURL(cfg.ProxyChain), "error", err)
+			exit(1)
+		}
+		poolDialer = chainDialer
+		logger.Info("Checking proxies through jump proxy", "proxy_chain", proxy.RedactProxyURL(cfg.ProxyChain))
+	}
+
 	// Create connection pool
 	poolConfig := pool.Config{
 		MaxIdleConns:          cfg.ConnectionPool.MaxIdleConns,
@@ -491,6 +923,8 @@ func main() {
 		DisableKeepAlives:     cfg.ConnectionPool.DisableKeepAlives,
 		DisableCompression:    cfg.ConnectionPool.DisableCompression,
 		InsecureSkipVerify:    cfg.InsecureSkipVerify,
+		RootCAs:               rootCAs,
+		DialContext:           poolDialer,
 	}
 	connectionPool := pool.NewConnectionPool(poolConfig)
 	logger.Info("Connection pool initialized",
@@ -498,12 +932,21 @@ func main() {
 		"max_idle_conns_per_host", poolConfig.MaxIdleConnsPerHost,
 		"max_conns_per_host", poolConfig.MaxConnsPerHost)
 
+	// The gRPC target only takes effect when the gRPC check is enabled
+	grpcTarget := ""
+	if cfg.GRPCCheck.Enabled {
+		grpcTarget = cfg.GRPCCheck.Target
+		logger.Info("Checking proxies with a gRPC health check", "target", grpcTarget)
+	}
+
 	// Create proxy checker
 	checker := proxy.NewChecker(proxy.Config{
 		Timeout:             time.Duration(cfg.Timeout) * time.Second,
 		ValidationURL:       cfg.TestURLs.DefaultURL,
 		DisallowedKeywords:  cfg.Validation.DisallowedKeywords,
 		MinResponseBytes:    cfg.Validation.MinResponseBytes,
+		MaxResponseBytes:    cfg.Validation.MaxResponseBytes,
+		BodyReadTimeout:     cfg.Validation.BodyReadTimeout,
 		DefaultHeaders:      cfg.DefaultHeaders,
 		UserAgent:           cfg.UserAgent,
 		EnableCloudChecks:   cfg.EnableCloudChecks,
@@ -516,6 +959,38 @@ func main() {
 		InteractshURL:       cfg.InteractshURL,
 		InteractshToken:     cfg.InteractshToken,
 
+		RequireBothHTTPAndHTTPS: cfg.RequireBothHTTPAndHTTPS,
+		MinimalHeaders:          cfg.MinimalHeaders,
+		FollowRedirects:         cfg.FollowRedirects,
+		MaxRedirects:            cfg.MaxRedirects,
+		ForceIPv6Target:         cfg.ForceIPv6Target,
+		EchoHeaders:             cfg.EchoHeaders,
+		EchoHeadersURL:          cfg.EchoHeadersURL,
+		CheckWebSocket:          cfg.WebSocketCheck,
+		WebSocketEchoURL:        cfg.WebSocketEchoURL,
+		LookupEgressPTR:         cfg.EgressPTR,
+		MeasureThroughput:       cfg.Throughput.Enabled,
+		ThroughputURL:           cfg.Throughput.URL,
+		ThroughputBytes:         cfg.Throughput.Size,
+		ThroughputTimeout:       cfg.Throughput.Timeout,
+		DetectRotation:          cfg.RotationDetection.Enabled,
+		RotationSamples:         cfg.RotationDetection.MinSamples,
+		RotationInterval:        cfg.RotationDetection.SampleInterval,
+		RotationThreshold:       cfg.RotationDetection.ConfidenceThreshold,
+		InspectTLS:              cfg.TLSInspection.Enabled,
+		PinnedCertFingerprints:  cfg.TLSInspection.PinnedFingerprints,
+		EgressIPURL:             cfg.EgressIPURL,
+		CheckSuspicious:         cfg.SuspiciousCheck.Enabled,
+		SuspiciousIndicators:    cfg.SuspiciousCheck.Indicators,
+		MinUpstreamLatency:      cfg.SuspiciousCheck.MinUpstreamLatency,
+
+		// Validation quorum across the configured test URLs
+		ValidationURLs:       cfg.TestURLs.URLs(),
+		RequiredSuccessCount: cfg.TestURLs.RequiredSuccessCount,
+
+		// Custom validator run against the response body
+		ExternalValidationCommand: cfg.Validation.ExternalCommand,
+
 		// Rate limiting settings
 		RateLimitEnabled:  *rateLimitEnabled,
 		RateLimitDelay:    *rateLimitDelay,
@@ -529,6 +1004,11 @@ func main() {
 		MaxDelay:        cfg.MaxRetryDelay,
 		BackoffFactor:   cfg.BackoffFactor,
 		RetryableErrors: cfg.RetryableErrors,
+		HonorRetryAfter: cfg.HonorRetryAfter,
+		MaxRetryAfter:   cfg.MaxRetryAfter,
+
+		// Quality score
+		ScoreWeights: cfg.Scoring,
 
 		// Authentication settings
 		AuthEnabled:     cfg.AuthEnabled,
@@ -536,15 +1016,76 @@ func main() {
 		DefaultPassword: cfg.DefaultPassword,
 		AuthMethods:     cfg.AuthMethods,
 
-		// Connection pool
+		// Connection pool and the dialer under it
 		ConnectionPool: connectionPool,
+		BaseDialer:     baseDialer,
+		ProxyChain:     cfg.ProxyChain,
+
+		// Type detection order
+		DetectionOrder: cfg.DetectionOrder,
 
 		// HTTP/2 and HTTP/3 settings
 		EnableHTTP2: cfg.EnableHTTP2,
 		EnableHTTP3: cfg.EnableHTTP3,
+		HTTPVersion: cfg.HTTPVersion,
 
 		// Fingerprinting settings
 		EnableFingerprint: cfg.EnableFingerprint,
+
+		// Content similarity settings
+		ContentSimilarityCheck:     cfg.ContentSimilarity.Enabled,
+		ContentCompareMaxBytes:     cfg.ContentSimilarity.MaxBodyBytes,
+		ContentSimilarityThreshold: cfg.ContentSimilarity.Threshold,
+
+		// Anonymity check settings
+		AnonymityCheckURL:         cfg.AnonymityCheck.URL,
+		AnonymityFallbackURLs:     cfg.AnonymityCheck.FallbackURLs,
+		AnonymityRateLimitBackoff: cfg.AnonymityCheck.RateLimitBackoff,
+
+		// TLS verification settings
+		VerifyTLS:  cfg.VerifyTLS,
+		CACertFile: cfg.CACertFile,
+
+		// Target resolution cache settings
+		ResolveOnce: cfg.ResolveOnce,
+		DNSCacheTTL: cfg.DNSCacheTTL,
+		DNSRetries:  cfg.DNSRetries,
+		Resolver:    resolver,
+
+		// Vuln probe scope
+		VulnPathAllowlist: cfg.VulnPathAllowlist,
+		VulnPathDenylist:  cfg.VulnPathDenylist,
+
+		// Only send GET/HEAD vuln probes
+		ReadOnlyVulnChecks: cfg.ReadOnlyVulnChecks,
+
+		// Vuln scan phase concurrency
+		VulnScanConcurrency: cfg.VulnScanConcurrency,
+
+		// Exit country lookup endpoint
+		GeoIPURL: cfg.GeoIPURL,
+
+		// Proxy classification settings
+		ClassifyProxies:     cfg.ProxyClass.Enabled,
+		ProxyClassURL:       cfg.ProxyClass.URL,
+		DatacenterKeywords:  cfg.ProxyClass.DatacenterKeywords,
+		ResidentialKeywords: cfg.ProxyClass.ResidentialKeywords,
+		MobileKeywords:      cfg.ProxyClass.MobileKeywords,
+
+		// Site category access settings
+		CheckCategories: cfg.CategoryCheck.Enabled,
+		CategoryURLs:    cfg.CategoryCheck.Categories,
+
+		// Exit IP reputation settings
+		CheckReputation:   cfg.Reputation.Enabled,
+		DNSBLZones:        cfg.Reputation.DNSBLZones,
+		ReputationAPIURL:  cfg.Reputation.APIURL,
+		ReputationAPIKey:  cfg.Reputation.APIKey,
+		ReputationTimeout: cfg.Reputation.Timeout,
+
+		// gRPC health-check target
+		GRPCTarget:  grpcTarget,
+		GRPCService: cfg.GRPCCheck.Service,
 	}, *debug || cfg.AdvancedChecks.TestProtocolSmuggling || cfg.AdvancedChecks.TestDNSRebinding, logger)
 
 	// Initialize UI
@@ -558,10 +1099,20 @@ func main() {
 	view.Progress = p
 	view.Total = len(proxies)
 	view.Version = help.GetVersion()
+	view.MaxDebugLines = cfg.DebugLogLines
 	view.SetMode(*verbose, *debug || cfg.AdvancedChecks.TestProtocolSmuggling || cfg.AdvancedChecks.TestDNSRebinding)
 
-	// Set up graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
+	// Set up graceful shutdown, bounded by -max-runtime if given. The idle
+	// watchdog stops the run through stopRun so the stall is told apart from
+	// a user interrupt.
+	runCtx, stopRun := context.WithCancelCause(context.Background())
+	ctx, cancel := context.WithCancel(runCtx)
+	defer cancel()
+	if *maxRuntime > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, *maxRuntime)
+		defer cancelTimeout()
+	}
 	shutdownChan := make(chan os.Signal, 1)
 	signal.Notify(shutdownChan, syscall.SIGINT, syscall.SIGTERM)
 
@@ -578,12 +1129,41 @@ func main() {
 		progressIndicator = progresspkg.NewProgressIndicator(progressConfig)
 	}
 
+	// Open the streaming JSON-lines output before any checks run
+	var jsonlWriter *output.JSONLWriter
+	if *jsonlFile != "" {
+		jsonlWriter, err = output.NewJSONLWriter(*jsonlFile)
+		if err != nil {
+			logger.Error("Failed to create JSON-lines output", "error", err, "file", *jsonlFile)
+			exit(1)
+		}
+		jsonlWriter.SetPreserveInput(*preserveInput)
+		jsonlWriter.SetFlushPolicy(cfg.OutputFlushInterval, cfg.OutputFsync)
+	}
+
+	// Connect to syslog before any checks run
+	var syslogLogger *logging.Logger
+	var syslogCloser io.Closer
+	if cfg.Syslog.Enabled {
+		syslogLogger, syslogCloser, err = newSyslogLogger(cfg.Syslog)
+		if err != nil {
+			logger.Error("Failed to connect to syslog", "error", err, "address", cfg.Syslog.Address)
+			exit(1)
+		}
+	}
+
 	// Create application state
 	state := &AppState{
 		view:              view,
 		checker:           checker,
 		proxies:           proxies,
+		timeouts:          proxyTimeouts,
+		inputs:            proxyInputs,
+		countries:         proxyCountries,
 		concurrency:       cfg.Concurrency,
+		concurrencyHTTP:   *concurrencyHTTP,
+		concurrencySOCKS:  *concurrencySOCKS,
+		startLimit:        worker.NewStartLimiter(*proxiesPerSecond),
 		verbose:           *verbose, // Only use verbose flag
 		debug:             *debug || cfg.AdvancedChecks.TestProtocolSmuggling || cfg.AdvancedChecks.TestDNSRebinding,
 		logger:            logger,
@@ -591,17 +1171,57 @@ func main() {
 		ctx:               ctx,
 		cancel:            cancel,
 		shutdownChan:      shutdownChan,
+		maxRuntime:        *maxRuntime,
+		watchdog:          newIdleWatchdog(cfg.MaxIdle),
+		goroutines:        newGoroutineGuard(cfg.MaxGoroutines, cfg.ThrottleGoroutines),
 		outputFile:        *outputFile,
 		jsonFile:          *jsonFile,
+		jsonSorted:        *jsonSorted,
+		sortBy:            *sortBy,
+		classFilter:       classFilter,
 		workingFile:       *workingFile,
 		anonymousFile:     *anonymousFile,
+		csvFile:           *csvFile,
+		csvColumns:        csvColumns,
+		jsonlWriter:       jsonlWriter,
+		syslog:            syslogLogger,
+		syslogCloser:      syslogCloser,
+		htmlFile:          *htmlFile,
+		binaryFile:        *binaryFile,
+		preserveInput:     *preserveInput,
 		noUI:              *noUI,
+		failFast:          *failFast,
 		progressIndicator: progressIndicator,
 		metricsCollector:  metricsCollector,
 		configWatcher:     configWatcher,
+		keepWarm:          *keepWarm,
+		connectionPool:    connectionPool,
+		validationURL:     cfg.TestURLs.DefaultURL,
+		timeout:           time.Duration(cfg.Timeout) * time.Second,
+		checkpoint:        scanCheckpoint,
 		ticker:            timer.NewWithInterval(100*time.Millisecond, 100*time.Millisecond),
 	}
 
+	// Stop the run and keep partial results if no check completes for max_idle
+	go state.watchdog.run(ctx, func(inFlight []string) {
+		for i, proxyURL := range inFlight {
+			inFlight[i] = proxy.RedactProxyURL(proxyURL)
+		}
+		logger.Warn("No progress within max idle, stopping the run",
+			"max_idle", cfg.MaxIdle, "in_flight", len(inFlight), "in_flight_proxies", inFlight)
+		stopRun(errRunStalled)
+	})
+
+	// Warn about goroutine explosions and export the count
+	go state.goroutines.run(ctx, func(count int) {
+		if metricsCollector != nil {
+			metricsCollector.SetGoroutines(count)
+		}
+	}, func(count int) {
+		logger.Warn("Goroutine count exceeds max_goroutines",
+			"goroutines", count, "max_goroutines", cfg.MaxGoroutines, "throttling", cfg.ThrottleGoroutines)
+	})
+
 	// Start shutdown handler goroutine
 	go func() {
 		<-shutdownChan
@@ -637,7 +1257,10 @@ func main() {
 		logger.Info("Connection pool cleaned up")
 
 		logger.ShutdownComplete()
-		os.Exit(0)
+		if state.failFast && !state.reportFailedProxies(os.Stderr) {
+			exit(1)
+		}
+		exit(0)
 	}()
 
 	if state.noUI {
@@ -645,7 +1268,10 @@ func main() {
 		logger.ProxyCheckStart(len(state.proxies), state.concurrency)
 		state.startCheckingNoUI()
 	} else {
-		// Start the UI
+		// Start the UI; p pauses and resumes proxy feeding
+		state.pause = newPauseGate()
+		state.throughput = progresspkg.NewRateEstimator(progresspkg.DefaultRateWindow)
+		state.throughput.Record(time.Now(), 0)
 		program := tea.NewProgram(state, tea.WithAltScreen(), tea.WithMouseCellMotion())
 
 		// Start a goroutine to forward messages from updateChan to the program
@@ -657,52 +1283,246 @@ func main() {
 
 		if _, err := program.Run(); err != nil {
 			logger.Error("Failed to run TUI program", "error", err)
-			os.Exit(1)
+			exit(1)
 		}
 	}
 
 	// Process results
 	processResults(state)
+
+	// Keep working proxies warm for a follow-up tool if requested
+	if state.keepWarm > 0 {
+		state.keepWorkingProxiesWarm()
+	}
+
+	// Fail the process for CI gates unless every proxy worked
+	if state.failFast && !state.reportFailedProxies(os.Stderr) {
+		exit(1)
+	}
+}
+
+// keepWorkingProxiesWarm periodically pings working proxies through the connection
+// pool so their connections stay open until the keep-warm duration elapses or a
+// shutdown signal is received
+func (s *AppState) keepWorkingProxiesWarm() {
+	targets := keepWarmTargets(s.checker, s.results)
+	if len(targets) == 0 {
+		s.logger.Info("No working proxies to keep warm")
+		return
+	}
+
+	s.logger.Info("Keeping working proxies warm", "proxies", len(targets), "duration", s.keepWarm.String())
+
+	deadline := time.NewTimer(s.keepWarm)
+	defer deadline.Stop()
+	ticker := time.NewTicker(keepWarmInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-deadline.C:
+			s.logger.Info("Keep-warm period elapsed", "proxies", len(targets))
+			return
+		case <-ticker.C:
+			s.pingProxies(targets)
+		}
+	}
+}
+
+// pingProxies sends one request through each proxy using the pooled clients
+func (s *AppState) pingProxies(targets []warmTarget) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.concurrency)
+
+	for _, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(target warmTarget) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			client, err := s.connectionPool.GetClient(target.key, target.timeout)
+			if err != nil {
+				s.logger.Debug("Keep-warm client unavailable", "proxy", proxy.RedactProxyURL(target.key), "error", err)
+				return
+			}
+
+			req, err := http.NewRequestWithContext(s.ctx, http.MethodHead, s.validationURL, nil)
+			if err != nil {
+				return
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				s.logger.Debug("Keep-warm ping failed", "proxy", proxy.RedactProxyURL(target.key), "error", err)
+				return
+			}
+			// Drain the body so the connection returns to the pool
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}(target)
+	}
+
+	wg.Wait()
+}
+
+// warmTarget is a pooled client to keep warm, looked up the way checks get it
+type warmTarget struct {
+	key     string
+	timeout time.Duration
+}
+
+// keepWarmTargets returns the pool keys and timeouts that the checks of the
+// working proxies got their pooled clients with
+func keepWarmTargets(checker *proxy.Checker, results []*proxy.ProxyResult) []warmTarget {
+	var targets []warmTarget
+	seen := make(map[warmTarget]bool)
+
+	for _, result := range results {
+		if !result.Working || result.HTTP10Only {
+			continue
+		}
+
+		key, timeout, ok := checker.PoolKey(result)
+		if !ok {
+			continue
+		}
+
+		target := warmTarget{key: key, timeout: timeout}
+		if !seen[target] {
+			seen[target] = true
+			targets = append(targets, target)
+		}
+	}
+
+	return targets
 }
 
 func processResults(state *AppState) {
+	if state.stoppedEarly() {
+		state.markNotChecked()
+	}
+
+	results := state.results
+	if len(state.classFilter) > 0 {
+		results = output.FilterByProxyClass(results, state.classFilter)
+	}
+	switch state.sortBy {
+	case "score":
+		results = output.SortedByScore(results)
+	case "connect":
+		results = output.SortedByConnectLatency(results)
+	}
+
 	// Generate summary
-	summary := output.GenerateSummary(state.results)
-	outputResults := output.ConvertToOutputFormat(state.results)
+	summary := output.GenerateSummary(results)
+	outputResults := output.ConvertToOutputFormat(results)
+	if state.preserveInput {
+		output.PreserveInput(summary.Results)
+		output.PreserveInput(outputResults)
+	}
 
 	// Log summary statistics
 	state.logger.SummaryStats(summary.TotalProxies, summary.WorkingProxies, summary.AnonymousProxies, summary.SuccessRate)
+	state.logger.Info("Scan footprint", "requests", summary.TotalRequests, "bytes_downloaded", summary.TotalBytesDownloaded)
+	if state.syslog != nil {
+		state.syslog.SummaryStats(summary.TotalProxies, summary.WorkingProxies, summary.AnonymousProxies, summary.SuccessRate)
+		if err := state.syslogCloser.Close(); err != nil {
+			state.logger.Warn("Failed to close syslog connection", "error", err)
+		}
+	}
+	if summary.ContentAlteredCount > 0 {
+		state.logger.Warn("Proxies served content that differs from a direct fetch",
+			"altered_proxies", summary.ContentAlteredCount)
+	}
+	if summary.CountryMismatchCount > 0 {
+		state.logger.Warn("Proxies egress from a different country than expected",
+			"mismatched_proxies", summary.CountryMismatchCount)
+	}
+	if summary.AnonymityDegradedCount > 0 {
+		state.logger.Warn("Anonymity detection degraded by echo endpoint rate limiting, anonymous counts may be undercounted",
+			"affected_proxies", summary.AnonymityDegradedCount)
+	}
+	for i, entry := range summary.Fastest {
+		state.logger.Info("Fastest proxy", "rank", i+1, "proxy", proxy.RedactProxyURL(entry.Proxy), "duration_seconds", entry.Speed.Seconds())
+	}
+	for i, entry := range summary.Slowest {
+		state.logger.Info("Slowest proxy", "rank", i+1, "proxy", proxy.RedactProxyURL(entry.Proxy), "duration_seconds", entry.Speed.Seconds())
+	}
+
+	if state.jsonlWriter != nil {
+		if err := state.jsonlWriter.Close(); err != nil {
+			state.logger.Error("Failed to close JSON-lines output", "error", err)
+		}
+	}
+
+	if err := state.checkpoint.flush(); err != nil {
+		state.logger.Error("Failed to write checkpoint", "error", err)
+	}
+
+	state.writeOutputFiles(summary, outputResults)
+}
+
+// writeOutputFiles writes the results to each output file that was asked for
+func (s *AppState) writeOutputFiles(summary output.SummaryOutput, outputResults []output.ProxyResultOutput) {
+	if s.outputFile != "" {
+		if err := output.WriteTextOutput(s.outputFile, outputResults, summary); err != nil {
+			s.logger.Error("Failed to write text output", "error", err, "file", s.outputFile)
+		} else {
+			s.logger.ResultsSaved(s.outputFile, "text")
+		}
+	}
+
+	if s.jsonFile != "" {
+		jsonSummary := summary
+		if s.jsonSorted {
+			jsonSummary.Results = output.SortedByProxy(summary.Results)
+		}
+		if err := output.WriteJSONOutput(s.jsonFile, jsonSummary); err != nil {
+			s.logger.Error("Failed to write JSON output", "error", err, "file", s.jsonFile)
+		} else {
+			s.logger.ResultsSaved(s.jsonFile, "json")
+		}
+	}
+
+	if s.csvFile != "" {
+		if err := output.WriteCSVOutputWithColumns(s.csvFile, outputResults, s.csvColumns); err != nil {
+			s.logger.Error("Failed to write CSV output", "error", err, "file", s.csvFile)
+		} else {
+			s.logger.ResultsSaved(s.csvFile, "csv")
+		}
+	}
 
-	// Write output files if specified
-	if state.outputFile != "" {
-		if err := output.WriteTextOutput(state.outputFile, outputResults, summary); err != nil {
-			state.logger.Error("Failed to write text output", "error", err, "file", state.outputFile)
+	if s.htmlFile != "" {
+		if err := output.WriteHTMLReport(s.htmlFile, summary); err != nil {
+			s.logger.Error("Failed to write HTML report", "error", err, "file", s.htmlFile)
 		} else {
-			state.logger.ResultsSaved(state.outputFile, "text")
+			s.logger.ResultsSaved(s.htmlFile, "html")
 		}
 	}
 
-	if state.jsonFile != "" {
-		if err := output.WriteJSONOutput(state.jsonFile, summary); err != nil {
-			state.logger.Error("Failed to write JSON output", "error", err, "file", state.jsonFile)
+	if s.binaryFile != "" {
+		if err := output.WriteBinaryOutput(s.binaryFile, summary); err != nil {
+			s.logger.Error("Failed to write binary output", "error", err, "file", s.binaryFile)
 		} else {
-			state.logger.ResultsSaved(state.jsonFile, "json")
+			s.logger.ResultsSaved(s.binaryFile, "binary")
 		}
 	}
 
-	if state.workingFile != "" {
-		if err := output.WriteWorkingProxiesOutput(state.workingFile, outputResults); err != nil {
-			state.logger.Error("Failed to write working proxies", "error", err, "file", state.workingFile)
+	if s.workingFile != "" {
+		if err := output.WriteWorkingProxiesOutput(s.workingFile, outputResults); err != nil {
+			s.logger.Error("Failed to write working proxies", "error", err, "file", s.workingFile)
 		} else {
-			state.logger.ResultsSaved(state.workingFile, "working_proxies")
+			s.logger.ResultsSaved(s.workingFile, "working_proxies")
 		}
 	}
 
-	if state.anonymousFile != "" {
-		if err := output.WriteAnonymousProxiesOutput(state.anonymousFile, outputResults); err != nil {
-			state.logger.Error("Failed to write anonymous proxies", "error", err, "file", state.anonymousFile)
+	if s.anonymousFile != "" {
+		if err := output.WriteAnonymousProxiesOutput(s.anonymousFile, outputResults); err != nil {
+			s.logger.Error("Failed to write anonymous proxies", "error", err, "file", s.anonymousFile)
 		} else {
-			state.logger.ResultsSaved(state.anonymousFile, "anonymous_proxies")
+			s.logger.ResultsSaved(s.anonymousFile, "anonymous_proxies")
 		}
 	}
 }
@@ -746,6 +1566,13 @@ func (s *AppState) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			s.view.Progress.Width = msg.Width - 4
 		}
 
+		// Give the debug log half the screen, leaving room for the stats and checks
+		if s.view.Mode == ui.ModeDebug {
+			s.mutex.Lock()
+			s.view.ResizeDebugLog(ui.DefaultWidth-4, max(5, msg.Height/2))
+			s.mutex.Unlock()
+		}
+
 		return s, tea.Batch(cmds...)
 
 	case tea.KeyMsg:
@@ -754,6 +1581,43 @@ func (s *AppState) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Cancel context to stop workers
 			s.cancel()
 			return s, tea.Quit
+		case "up", "k", "down", "j", "pgup", "pgdown", "home", "end":
+			// Scroll the debug log; end goes back to following new lines
+			if s.view.Mode != ui.ModeDebug {
+				return s, tea.Batch(cmds...)
+			}
+			s.mutex.Lock()
+			switch msg.String() {
+			case "up", "k":
+				s.view.ScrollDebugLog(-1)
+			case "down", "j":
+				s.view.ScrollDebugLog(1)
+			case "pgup":
+				s.view.ScrollDebugLog(-s.view.DebugLog.Height)
+			case "pgdown":
+				s.view.ScrollDebugLog(s.view.DebugLog.Height)
+			case "home":
+				s.view.ScrollDebugLog(-len(s.view.DebugMessages))
+			case "end":
+				s.view.ScrollDebugLog(len(s.view.DebugMessages))
+			}
+			s.mutex.Unlock()
+			return s, tea.Batch(cmds...)
+		case "p", "P":
+			// Stop handing out proxies; checks in flight finish
+			paused := s.pause.toggle()
+			s.watchdog.hold(paused)
+			s.mutex.Lock()
+			s.view.Paused = paused
+			if !paused {
+				// Measure the rate from the resume, not across the pause
+				s.throughput.Reset(time.Now(), s.view.Current)
+			}
+			if s.debug {
+				s.view.AddDebugMessage(fmt.Sprintf("[INFO] Checking paused: %t\n", paused))
+			}
+			s.mutex.Unlock()
+			return s, tea.Batch(cmds...)
 		}
 
 	case checkingStartedMsg:
@@ -784,6 +1648,10 @@ func (s *AppState) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			s.view.AvgSpeed = oldAvg + (msg.result.Speed-oldAvg)/time.Duration(n)
 		}
 
+		// Recent throughput for the ETA
+		s.throughput.Record(time.Now(), s.view.Current)
+		_, s.view.ETA = s.throughput.Estimate(time.Now(), s.view.Total-s.view.Current)
+
 		// Update progress
 		progress := float64(s.view.Current) / float64(s.view.Total)
 		progressCmd := s.view.Progress.SetPercent(progress)
@@ -798,6 +1666,11 @@ func (s *AppState) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, progressCmd)
 		return s, tea.Batch(cmds...)
 
+	case runStoppedMsg:
+		// Stop workers and leave the TUI so completed results are written
+		s.cancel()
+		return s, tea.Quit
+
 	case allChecksCompleteMsg:
 		// All checks complete, can quit or show completion
 		if s.debug {
@@ -809,6 +1682,11 @@ func (s *AppState) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Update spinner every tick
 		s.view.SpinnerIdx++
 
+		// Re-estimate every tick so a stall shows in the ETA
+		s.mutex.Lock()
+		_, s.view.ETA = s.throughput.Estimate(time.Now(), s.view.Total-s.view.Current)
+		s.mutex.Unlock()
+
 		// Update metrics collector if enabled (light operation)
 		if s.metricsCollector != nil {
 			s.mutex.Lock()
@@ -844,9 +1722,17 @@ func (s *AppState) View() string {
 	return s.view.RenderDefault()
 }
 
+// checkOptions returns the per-proxy options given for proxyURL in the proxy list
+func (s *AppState) checkOptions(proxyURL string) proxy.CheckOptions {
+	return proxy.CheckOptions{
+		Timeout:       s.timeouts[proxyURL],
+		ExpectCountry: s.countries[proxyURL],
+	}
+}
+
 func (s *AppState) startChecking() {
 	var wg sync.WaitGroup
-	proxyChan := make(chan string)
+	pools := s.workerPools()
 
 	// Send initial update
 	s.updateChan <- progressUpdateMsg{}
@@ -855,138 +1741,152 @@ func (s *AppState) startChecking() {
 		s.mutex.Lock()
 		s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Starting proxy checks with concurrency: %d\n", s.concurrency))
 		s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Total proxies to check: %d\n", len(s.proxies)))
+		for _, pool := range pools {
+			s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Worker pool %s: %d workers for %d proxies\n", pool.name, pool.size, len(pool.proxies)))
+		}
 		s.mutex.Unlock()
 
 		// Send update
 		s.updateChan <- progressUpdateMsg{}
 	}
 
-	// Start workers
-	for i := 0; i < s.concurrency; i++ {
-		wg.Add(1)
-		go func(workerID int) {
-			// Add panic recovery to prevent worker crashes from affecting the whole application
-			defer func() {
-				if r := recover(); r != nil {
+	// Start workers, each drawing proxies from its pool's channel
+	workerID := 0
+	for _, pool := range pools {
+		for i := 0; i < pool.size; i++ {
+			wg.Add(1)
+			go func(workerID int, proxyChan <-chan string) {
+				// Add panic recovery to prevent worker crashes from affecting the whole application
+				defer func() {
+					if r := recover(); r != nil {
+						s.mutex.Lock()
+						s.view.AddDebugMessage(fmt.Sprintf("[ERROR] Worker %d panicked: %v\n", workerID, r))
+						s.mutex.Unlock()
+
+						// Send update
+						s.updateChan <- progressUpdateMsg{}
+					}
+					wg.Done()
+				}()
+
+				if s.debug {
 					s.mutex.Lock()
-					s.view.AddDebugMessage(fmt.Sprintf("[ERROR] Worker %d panicked: %v\n", workerID, r))
+					s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Worker %d started\n", workerID))
 					s.mutex.Unlock()
 
 					// Send update
 					s.updateChan <- progressUpdateMsg{}
 				}
-				wg.Done()
-			}()
 
-			if s.debug {
-				s.mutex.Lock()
-				s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Worker %d started\n", workerID))
-				s.mutex.Unlock()
+				for proxy := range proxyChan {
+					// Check for cancellation before processing
+					select {
+					case <-s.ctx.Done():
+						if s.debug {
+							s.mutex.Lock()
+							s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Worker %d cancelled\n", workerID))
+							s.mutex.Unlock()
+							s.updateChan <- progressUpdateMsg{}
+						}
+						return
+					default:
+						// Continue processing
+					}
 
-				// Send update
-				s.updateChan <- progressUpdateMsg{}
-			}
+					// Update active job status when starting a check
+					s.mutex.Lock()
+					status := &ui.CheckStatus{
+						Proxy:      proxy,
+						IsActive:   true,
+						LastUpdate: time.Now(),
+					}
+					s.view.ActiveChecks[proxy] = status
+					s.mutex.Unlock()
+
+					// Update queue size when starting a check
+					// Queue size tracked in metrics
+					// Send update
+					s.updateChan <- progressUpdateMsg{}
 
-			for proxy := range proxyChan {
-				// Check for cancellation before processing
-				select {
-				case <-s.ctx.Done():
 					if s.debug {
 						s.mutex.Lock()
-						s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Worker %d cancelled\n", workerID))
+						s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Worker %d checking: %s (goroutines: %d)\n", workerID, proxy, runtime.NumGoroutine()))
 						s.mutex.Unlock()
+
+						// Send update
 						s.updateChan <- progressUpdateMsg{}
 					}
-					return
-				default:
-					// Continue processing
-				}
-
-				// Update active job status when starting a check
-				s.mutex.Lock()
-				status := &ui.CheckStatus{
-					Proxy:      proxy,
-					IsActive:   true,
-					LastUpdate: time.Now(),
-				}
-				s.view.ActiveChecks[proxy] = status
-				s.mutex.Unlock()
 
-				// Update queue size when starting a check
-				// Queue size tracked in metrics
-				// Send update
-				s.updateChan <- progressUpdateMsg{}
+					release := s.goroutines.admit(s.ctx)
+					checkStart := time.Now()
+					s.watchdog.started(proxy)
+					result := s.checker.CheckWithOptions(proxy, s.checkOptions(proxy))
+					s.watchdog.finished(proxy)
+					release()
+					result.Input = s.inputs[proxy]
+
+					// Record metrics if enabled
+					if s.metricsCollector != nil {
+						s.metricsCollector.RecordProxyCheck(result.Working, string(result.Type))
+						s.metricsCollector.ObserveCheckDuration(string(result.Type), result.Working, time.Since(checkStart))
+						if result.IsAnonymous {
+							s.metricsCollector.RecordAnonymousProxy()
+						}
+						if result.CloudProvider != "" {
+							s.metricsCollector.RecordCloudProvider(result.CloudProvider)
+						}
+						if result.Error != nil {
+							s.metricsCollector.RecordError("proxy_check_failed")
+						}
+					}
 
-				if s.debug {
-					s.mutex.Lock()
-					s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Worker %d checking: %s\n", workerID, proxy))
-					s.mutex.Unlock()
+					// Update queue size after each check is no longer needed here as it will be updated in processResult
+					// or when marking a job as inactive
 
 					// Send update
 					s.updateChan <- progressUpdateMsg{}
-				}
 
-				result := s.checker.Check(proxy)
+					// Log debug info based on result
+					if s.debug {
+						s.mutex.Lock()
+						if !result.Working {
+							// Create a more concise error message
+							errorMsg := "Proxy not working"
+							if result.Error != nil {
+								errorMsg = result.Error.Error()
+								// Truncate long error messages
+								if len(errorMsg) > 100 {
+									errorMsg = errorMsg[:97] + "..."
+								}
+							}
+							s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Worker %d failed: %s - %s\n", workerID, proxy, errorMsg))
+						} else {
+							s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Worker %d success: %s (%s)\n", workerID, proxy, result.Type))
+						}
+						s.mutex.Unlock()
 
-				// Record metrics if enabled
-				if s.metricsCollector != nil {
-					s.metricsCollector.RecordProxyCheck(result.Working, string(result.Type), result.Speed)
-					if result.IsAnonymous {
-						s.metricsCollector.RecordAnonymousProxy()
-					}
-					if result.CloudProvider != "" {
-						s.metricsCollector.RecordCloudProvider(result.CloudProvider)
-					}
-					if result.Error != nil {
-						s.metricsCollector.RecordError("proxy_check_failed")
+						// Send update
+						s.updateChan <- progressUpdateMsg{}
 					}
-				}
 
-				// Update queue size after each check is no longer needed here as it will be updated in processResult
-				// or when marking a job as inactive
+					// Always process result (whether working or not) to update counters
+					s.processResult(result)
 
-				// Send update
-				s.updateChan <- progressUpdateMsg{}
+					// Send update
+					s.updateChan <- progressUpdateMsg{}
+				}
 
-				// Log debug info based on result
 				if s.debug {
 					s.mutex.Lock()
-					if !result.Working {
-						// Create a more concise error message
-						errorMsg := "Proxy not working"
-						if result.Error != nil {
-							errorMsg = result.Error.Error()
-							// Truncate long error messages
-							if len(errorMsg) > 100 {
-								errorMsg = errorMsg[:97] + "..."
-							}
-						}
-						s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Worker %d failed: %s - %s\n", workerID, proxy, errorMsg))
-					} else {
-						s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Worker %d success: %s (%s)\n", workerID, proxy, result.Type))
-					}
+					s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Worker %d finished\n", workerID))
 					s.mutex.Unlock()
 
 					// Send update
 					s.updateChan <- progressUpdateMsg{}
 				}
-
-				// Always process result (whether working or not) to update counters
-				s.processResult(result)
-
-				// Send update
-				s.updateChan <- progressUpdateMsg{}
-			}
-
-			if s.debug {
-				s.mutex.Lock()
-				s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Worker %d finished\n", workerID))
-				s.mutex.Unlock()
-
-				// Send update
-				s.updateChan <- progressUpdateMsg{}
-			}
-		}(i)
+			}(workerID, pool.ch)
+			workerID++
+		}
 	}
 
 	// Feed proxies to workers
@@ -999,37 +1899,35 @@ func (s *AppState) startChecking() {
 		s.updateChan <- progressUpdateMsg{}
 	}
 
-	for _, proxy := range s.proxies {
-		// Check for cancellation before sending each proxy
-		select {
-		case <-s.ctx.Done():
-			if s.debug {
-				s.mutex.Lock()
-				s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Proxy feeding cancelled\n"))
-				s.mutex.Unlock()
-				s.updateChan <- progressUpdateMsg{}
-			}
-			close(proxyChan)
-			return
-		default:
-			if s.debug {
-				s.mutex.Lock()
-				s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Sending proxy to channel: %s\n", proxy))
-				s.mutex.Unlock()
-			}
-			proxyChan <- proxy
+	fed := s.feedPools(pools, func(proxy string) {
+		if s.debug {
+			s.mutex.Lock()
+			s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Sending proxy to channel: %s\n", proxy))
+			s.mutex.Unlock()
+		}
+	})
+	if !fed {
+		if s.debug {
+			s.mutex.Lock()
+			s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] Proxy feeding cancelled\n"))
+			s.mutex.Unlock()
+			s.updateChan <- progressUpdateMsg{}
+		}
+		if s.stoppedEarly() {
+			// Checks still in flight may report late, so the update channel stays open
+			s.updateChan <- runStoppedMsg{}
 		}
+		return
 	}
 
 	if s.debug {
 		s.mutex.Lock()
-		s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] All proxies sent to channel, closing\n"))
+		s.view.AddDebugMessage(fmt.Sprintf("[DEBUG] All proxies sent to channels\n"))
 		s.mutex.Unlock()
 
 		// Send update
 		s.updateChan <- progressUpdateMsg{}
 	}
-	close(proxyChan)
 
 	if s.debug {
 		s.mutex.Lock()
@@ -1065,6 +1963,12 @@ func (s *AppState) startChecking() {
 			// Send update
 			s.updateChan <- progressUpdateMsg{}
 		}
+	case <-s.ctx.Done():
+		if s.stoppedEarly() {
+			// Checks still in flight may report late, so the update channel stays open
+			s.updateChan <- runStoppedMsg{}
+			return
+		}
 	case <-time.After(timeout):
 		// Timeout occurred, some workers might be stuck
 		if s.debug {
@@ -1086,6 +1990,12 @@ func (s *AppState) startChecking() {
 }
 
 func (s *AppState) processResult(result *proxy.ProxyResult) {
+	if s.stoppedEarly() {
+		// The TUI has quit, this proxy is reported as not checked instead
+		return
+	}
+	s.streamResult(result)
+
 	// Send message to Update() instead of modifying state directly
 	s.updateChan <- proxyCheckCompleteMsg{
 		proxy:  result.ProxyURL,
@@ -1121,14 +2031,22 @@ func (s *AppState) processResult(result *proxy.ProxyResult) {
 		CloudProvider:  result.CloudProvider,
 		InternalAccess: result.InternalAccess,
 		MetadataAccess: result.MetadataAccess,
+		AnonymityLevel: string(result.AnonymityLevel),
 		SupportsHTTP:   result.SupportsHTTP,
 		SupportsHTTPS:  result.SupportsHTTPS,
+		IPv6Checked:    result.IPv6Checked,
+		SupportsIPv6:   result.SupportsIPv6,
 		CheckResults:   uiCheckResults,
 		DebugInfo:      result.DebugInfo,
 	}
 
 	s.view.ActiveChecks[result.ProxyURL] = status
 
+	// Speed distribution of working proxies for the histogram
+	if result.Working && result.Speed > 0 {
+		s.view.RecordSpeed(result.Speed)
+	}
+
 	// Queue size is tracked in metrics collector
 
 	// Add debug info to the main debug output if in debug mode
@@ -1137,102 +2055,141 @@ func (s *AppState) processResult(result *proxy.ProxyResult) {
 	}
 }
 
+// streamResult appends a completed result to the JSON-lines output and the
+// checkpoint, if enabled
+func (s *AppState) streamResult(result *proxy.ProxyResult) {
+	if err := s.checkpoint.markChecked(result.ProxyURL); err != nil {
+		s.logger.Warn("Failed to write checkpoint", "error", err, "proxy", proxy.RedactProxyURL(result.ProxyURL))
+	}
+	s.syslogResult(result)
+	if s.jsonlWriter == nil {
+		return
+	}
+	if err := s.jsonlWriter.Write(result); err != nil {
+		s.logger.Warn("Failed to write JSON-lines result", "error", err, "proxy", proxy.RedactProxyURL(result.ProxyURL))
+	}
+}
+
 // startCheckingNoUI runs proxy checking without UI (for automation)
 func (s *AppState) startCheckingNoUI() {
 	var wg sync.WaitGroup
-	proxyChan := make(chan string)
+	pools := s.workerPools()
 
 	s.logger.Info("Starting proxy tests", "total", len(s.proxies), "concurrency", s.concurrency)
+	if len(pools) > 0 && pools[0].name != poolAll {
+		for _, pool := range pools {
+			s.logger.Info("Worker pool", "type", pool.name, "workers", pool.size, "proxies", len(pool.proxies))
+		}
+	}
 
 	// Start progress indicator if available
 	if s.progressIndicator != nil {
 		s.progressIndicator.Start(len(s.proxies))
 	}
 
-	// Start workers
-	for i := 0; i < s.concurrency; i++ {
-		wg.Add(1)
-		go func(workerID int) {
-			defer wg.Done()
+	// Start workers, each drawing proxies from its pool's channel
+	workerID := 0
+	for _, pool := range pools {
+		for i := 0; i < pool.size; i++ {
+			wg.Add(1)
+			go func(workerID int, proxyChan <-chan string) {
+				defer wg.Done()
+
+				for proxyURL := range proxyChan {
+					// Check for cancellation before processing
+					select {
+					case <-s.ctx.Done():
+						if s.verbose {
+							s.logger.WithWorker(workerID).Debug("Worker cancelled")
+						}
+						return
+					default:
+						// Continue processing
+					}
 
-			for proxy := range proxyChan {
-				// Check for cancellation before processing
-				select {
-				case <-s.ctx.Done():
 					if s.verbose {
-						s.logger.WithWorker(workerID).Debug("Worker cancelled")
+						s.logger.WithWorker(workerID).WithProxy(proxy.RedactProxyURL(proxyURL)).Debug("Testing proxy", "goroutines", runtime.NumGoroutine())
 					}
-					return
-				default:
-					// Continue processing
-				}
 
-				if s.verbose {
-					s.logger.WithWorker(workerID).WithProxy(proxy).Debug("Testing proxy")
-				}
-
-				result := s.checker.Check(proxy)
-
-				// Record metrics if enabled
-				if s.metricsCollector != nil {
-					s.metricsCollector.RecordProxyCheck(result.Working, string(result.Type), result.Speed)
-					if result.IsAnonymous {
-						s.metricsCollector.RecordAnonymousProxy()
-					}
-					if result.CloudProvider != "" {
-						s.metricsCollector.RecordCloudProvider(result.CloudProvider)
+					release := s.goroutines.admit(s.ctx)
+					checkStart := time.Now()
+					s.watchdog.started(proxyURL)
+					result := s.checker.CheckWithOptions(proxyURL, s.checkOptions(proxyURL))
+					s.watchdog.finished(proxyURL)
+					release()
+					result.Input = s.inputs[proxyURL]
+
+					// Record metrics if enabled
+					if s.metricsCollector != nil {
+						s.metricsCollector.RecordProxyCheck(result.Working, string(result.Type))
+						s.metricsCollector.ObserveCheckDuration(string(result.Type), result.Working, time.Since(checkStart))
+						if result.IsAnonymous {
+							s.metricsCollector.RecordAnonymousProxy()
+						}
+						if result.CloudProvider != "" {
+							s.metricsCollector.RecordCloudProvider(result.CloudProvider)
+						}
+						if result.Error != nil {
+							s.metricsCollector.RecordError("proxy_check_failed")
+						}
 					}
-					if result.Error != nil {
-						s.metricsCollector.RecordError("proxy_check_failed")
+
+					s.mutex.Lock()
+					if s.resultsClosed {
+						// -max-runtime expired while this check was running
+						s.mutex.Unlock()
+						return
 					}
-				}
+					s.results = append(s.results, result)
+					current := len(s.results)
+					s.mutex.Unlock()
 
-				s.mutex.Lock()
-				s.results = append(s.results, result)
-				current := len(s.results)
-				s.mutex.Unlock()
+					s.streamResult(result)
 
-				// Update progress indicator
-				if s.progressIndicator != nil {
-					var message string
-					if result.Working {
-						if result.IsAnonymous {
-							message = "working anonymous proxy"
+					// Update progress indicator
+					if s.progressIndicator != nil {
+						var message string
+						if result.Working {
+							if result.IsAnonymous {
+								message = "working anonymous proxy"
+							} else {
+								message = "working proxy"
+							}
 						} else {
-							message = "working proxy"
+							message = "failed proxy check"
 						}
-					} else {
-						message = "failed proxy check"
+						s.progressIndicator.Update(current, message)
 					}
-					s.progressIndicator.Update(current, message)
-				}
 
-				if result.Working {
-					s.logger.WithContext("progress", fmt.Sprintf("%d/%d", current, len(s.proxies))).ProxySuccess(proxy, result.Speed.Seconds(), result.IsAnonymous, result.CloudProvider)
-				} else {
-					if s.verbose {
-						s.logger.WithContext("progress", fmt.Sprintf("%d/%d", current, len(s.proxies))).ProxyFailure(proxy, result.Error)
+					if result.Working {
+						s.logger.WithContext("progress", fmt.Sprintf("%d/%d", current, len(s.proxies))).ProxySuccess(proxy.RedactProxyURL(proxyURL), result.Speed.Seconds(), result.IsAnonymous, result.CloudProvider)
+					} else {
+						if s.verbose {
+							s.logger.WithContext("progress", fmt.Sprintf("%d/%d", current, len(s.proxies))).ProxyFailure(proxy.RedactProxyURL(proxyURL), result.Error)
+						}
 					}
 				}
-			}
-		}(i)
+			}(workerID, pool.ch)
+			workerID++
+		}
 	}
 
 	// Feed proxies to workers
-	for _, proxy := range s.proxies {
-		select {
-		case <-s.ctx.Done():
-			s.logger.Info("Shutdown requested, stopping proxy feeding")
-			close(proxyChan)
-			return
-		case proxyChan <- proxy:
-			// Proxy sent successfully, continue
-		}
+	if !s.feedPools(pools, nil) && !s.stoppedEarly() {
+		s.logger.Info("Shutdown requested, stopping proxy feeding")
+		return
 	}
-	close(proxyChan)
 
-	// Wait for all workers to finish
-	wg.Wait()
+	// Wait for all workers to finish, or for -max-runtime to expire
+	if !s.waitForWorkers(&wg) {
+		if s.stoppedEarly() {
+			s.logger.Warn("Run stopped early, abandoning unfinished checks", "reason", s.stopReason(), "max_runtime", s.maxRuntime)
+			if s.progressIndicator != nil {
+				s.progressIndicator.Finish("Run stopped early (" + s.stopReason() + ")")
+			}
+		}
+		return
+	}
 
 	// Finish progress indicator
 	if s.progressIndicator != nil {
@@ -1387,6 +2344,8 @@ func runDiscoveryMode(cfg *config.Config, logger *logging.Logger, source, query
 			ValidationURL:       cfg.TestURLs.DefaultURL,
 			DisallowedKeywords:  cfg.Validation.DisallowedKeywords,
 			MinResponseBytes:    cfg.Validation.MinResponseBytes,
+			MaxResponseBytes:    cfg.Validation.MaxResponseBytes,
+			BodyReadTimeout:     cfg.Validation.BodyReadTimeout,
 			DefaultHeaders:      cfg.DefaultHeaders,
 			UserAgent:           cfg.UserAgent,
 			ConnectionPool:      connectionPool,
@@ -1735,45 +2694,3 @@ func saveCandidatesToJSON(result *discovery.DiscoveryResult, filename string) er
 
 	return nil
 }
-
-// expandCIDR expands a CIDR notation into individual IP addresses
-// Supports optional port specification (e.g., "192.168.1.0/24:8080")
-func expandCIDR(cidr string) ([]string, error) {
-	// Check if port is specified
-	var port string
-	cidrParts := strings.Split(cidr, ":")
-	if len(cidrParts) == 2 {
-		cidr = cidrParts[0]
-		port = ":" + cidrParts[1]
-	} else if len(cidrParts) > 2 {
-		return nil, fmt.Errorf("invalid CIDR format: %s", cidr)
-	}
-
-	// Parse CIDR
-	ip, ipNet, err := net.ParseCIDR(cidr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid CIDR notation: %w", err)
-	}
-
-	var ips []string
-	for ip := ip.Mask(ipNet.Mask); ipNet.Contains(ip); inc(ip) {
-		ips = append(ips, ip.String()+port)
-	}
-
-	// Remove network and broadcast addresses for IPv4
-	if len(ips) > 2 {
-		ips = ips[1 : len(ips)-1]
-	}
-
-	return ips, nil
-}
-
-// inc increments an IP address
-func inc(ip net.IP) {
-	for j := len(ip) - 1; j >= 0; j-- {
-		ip[j]++
-		if ip[j] > 0 {
-			break
-		}
-	}
-}
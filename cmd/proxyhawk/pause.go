@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// pauseGate holds back the proxy feeders while the run is paused from the
+// TUI. Checks already handed to a worker finish; no new ones start until the
+// run is resumed. A nil gate never pauses.
+type pauseGate struct {
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	paused bool
+}
+
+// newPauseGate returns a gate that starts out running
+func newPauseGate() *pauseGate {
+	g := &pauseGate{}
+	g.cond = sync.NewCond(&g.mutex)
+	return g
+}
+
+// toggle pauses a running gate or resumes a paused one and reports whether
+// it is now paused
+func (g *pauseGate) toggle() bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.paused = !g.paused
+	if !g.paused {
+		g.cond.Broadcast()
+	}
+	return g.paused
+}
+
+// wait blocks while the gate is paused. It returns false if ctx is done
+// first, so quitting or a cancelled run still stops a paused feeder.
+func (g *pauseGate) wait(ctx context.Context) bool {
+	if g == nil {
+		return ctx.Err() == nil
+	}
+
+	// Wake the waiters below when ctx is cancelled during a pause
+	stop := context.AfterFunc(ctx, func() {
+		g.mutex.Lock()
+		defer g.mutex.Unlock()
+		g.cond.Broadcast()
+	})
+	defer stop()
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	for g.paused && ctx.Err() == nil {
+		g.cond.Wait()
+	}
+	return ctx.Err() == nil
+}
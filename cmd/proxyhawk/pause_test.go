@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPauseGateFeeding(t *testing.T) {
+	state := &AppState{
+		proxies:     []string{"http://1.2.3.4:8080", "http://5.6.7.8:3128"},
+		concurrency: 1,
+		ctx:         context.Background(),
+		pause:       newPauseGate(),
+	}
+	if !state.pause.toggle() {
+		t.Fatal("Expected the first toggle to pause")
+	}
+
+	pools := state.workerPools()
+	fed := make(chan bool)
+	go func() { fed <- state.feedPools(pools, nil) }()
+
+	select {
+	case proxy := <-pools[0].ch:
+		t.Fatalf("Expected no proxy while paused, got %s", proxy)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if state.pause.toggle() {
+		t.Fatal("Expected the second toggle to resume")
+	}
+	for range state.proxies {
+		select {
+		case <-pools[0].ch:
+		case <-time.After(time.Second):
+			t.Fatal("Expected feeding to continue after resuming")
+		}
+	}
+	if !<-fed {
+		t.Error("Expected feeding to complete")
+	}
+}
+
+func TestPauseGateCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	gate := newPauseGate()
+	gate.toggle()
+
+	done := make(chan bool)
+	go func() { done <- gate.wait(ctx) }()
+	cancel()
+
+	select {
+	case ok := <-done:
+		if ok {
+			t.Error("Expected wait to report the cancelled run")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected cancelling the run to release a paused feeder")
+	}
+
+	// A nil gate never pauses
+	var none *pauseGate
+	if !none.wait(context.Background()) {
+		t.Error("Expected a nil gate not to block")
+	}
+}
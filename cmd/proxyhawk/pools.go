@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Worker pool names used when per-type concurrency is configured
+const (
+	poolAll   = "all"
+	poolHTTP  = "http"
+	poolSOCKS = "socks"
+)
+
+// workerPool is a group of workers that draw proxies from their own channel
+type workerPool struct {
+	name    string
+	size    int
+	proxies []string
+	ch      chan string
+}
+
+// proxyPoolName classifies a proxy for the typed worker pools. SOCKS proxies go
+// to the SOCKS pool; everything else, including proxies without a scheme, is HTTP.
+func proxyPoolName(proxy string) string {
+	scheme, _, found := strings.Cut(proxy, "://")
+	if found && strings.HasPrefix(strings.ToLower(scheme), "socks") {
+		return poolSOCKS
+	}
+	return poolHTTP
+}
+
+// effectiveConcurrency caps the requested concurrency at the number of
+// proxies, since workers beyond that would only sit idle. It reports whether
+// the requested value was reduced.
+func effectiveConcurrency(requested, proxies int) (int, bool) {
+	if proxies > 0 && requested > proxies {
+		return proxies, true
+	}
+	return requested, false
+}
+
+// workerPools splits the proxies into worker pools. Without per-type
+// concurrency there is a single pool sized by the overall concurrency; with it,
+// HTTP and SOCKS proxies get separate pools so slow SOCKS handshakes cannot
+// starve the HTTP checks. A type without its own setting uses the overall concurrency,
+// and neither pool gets more workers than it has proxies.
+func (s *AppState) workerPools() []*workerPool {
+	if s.concurrencyHTTP <= 0 && s.concurrencySOCKS <= 0 {
+		return []*workerPool{{name: poolAll, size: s.concurrency, proxies: s.proxies, ch: make(chan string)}}
+	}
+
+	httpPool := &workerPool{name: poolHTTP, size: s.concurrencyHTTP, ch: make(chan string)}
+	socksPool := &workerPool{name: poolSOCKS, size: s.concurrencySOCKS, ch: make(chan string)}
+	for _, pool := range []*workerPool{httpPool, socksPool} {
+		if pool.size <= 0 {
+			pool.size = s.concurrency
+		}
+	}
+
+	for _, proxy := range s.proxies {
+		if proxyPoolName(proxy) == poolSOCKS {
+			socksPool.proxies = append(socksPool.proxies, proxy)
+		} else {
+			httpPool.proxies = append(httpPool.proxies, proxy)
+		}
+	}
+
+	var pools []*workerPool
+	for _, pool := range []*workerPool{httpPool, socksPool} {
+		if len(pool.proxies) > 0 {
+			pool.size, _ = effectiveConcurrency(pool.size, len(pool.proxies))
+			pools = append(pools, pool)
+		}
+	}
+	return pools
+}
+
+// feedPools sends each pool's proxies to its workers and closes the pool
+// channels. Every pool has its own feeder so a busy pool cannot hold back the
+// others. While the run is paused the feeders wait before handing out the
+// next proxy, so the pause is never part of a check's timing. beforeSend, if
+// set, is called for each proxy just before it is sent.
+// It returns false if feeding stopped because the run was cancelled.
+func (s *AppState) feedPools(pools []*workerPool, beforeSend func(proxy string)) bool {
+	var wg sync.WaitGroup
+	var cancelled atomic.Bool
+
+	for _, pool := range pools {
+		wg.Add(1)
+		go func(pool *workerPool) {
+			defer wg.Done()
+			defer close(pool.ch)
+
+			for _, proxy := range pool.proxies {
+				if !s.pause.wait(s.ctx) {
+					cancelled.Store(true)
+					return
+				}
+				// Hold back the next start if the global start rate is capped
+				if err := s.startLimit.Wait(s.ctx); err != nil {
+					cancelled.Store(true)
+					return
+				}
+				if beforeSend != nil {
+					beforeSend(proxy)
+				}
+
+				select {
+				case <-s.ctx.Done():
+					cancelled.Store(true)
+					return
+				case pool.ch <- proxy:
+				}
+			}
+		}(pool)
+	}
+
+	wg.Wait()
+	return !cancelled.Load()
+}
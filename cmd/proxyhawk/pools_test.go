@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestWorkerPools(t *testing.T) {
+	proxies := []string{
+		"http://1.2.3.4:8080",
+		"socks5://5.6.7.8:1080",
+		"https://9.9.9.9:443",
+		"SOCKS4://10.0.0.1:1080",
+		"1.1.1.1:3128",
+	}
+
+	// Without per-type concurrency every proxy shares one pool
+	state := &AppState{proxies: proxies, concurrency: 10}
+	pools := state.workerPools()
+	if len(pools) != 1 || pools[0].name != poolAll || pools[0].size != 10 || len(pools[0].proxies) != len(proxies) {
+		t.Fatalf("Expected a single pool of 10 workers for all proxies, got %+v", pools)
+	}
+
+	// With per-type concurrency proxies are split by scheme; schemeless ones are
+	// HTTP, and a pool never has more workers than proxies
+	state = &AppState{proxies: proxies, concurrency: 10, concurrencySOCKS: 2}
+	pools = state.workerPools()
+	if len(pools) != 2 {
+		t.Fatalf("Expected HTTP and SOCKS pools, got %+v", pools)
+	}
+	if pools[0].name != poolHTTP || pools[0].size != 3 || len(pools[0].proxies) != 3 {
+		t.Errorf("Unexpected HTTP pool: %+v", pools[0])
+	}
+	if pools[1].name != poolSOCKS || pools[1].size != 2 || len(pools[1].proxies) != 2 {
+		t.Errorf("Unexpected SOCKS pool: %+v", pools[1])
+	}
+}
+
+func TestFeedPools(t *testing.T) {
+	state := &AppState{
+		proxies:          []string{"http://1.2.3.4:8080", "socks5://5.6.7.8:1080", "http://9.9.9.9:80"},
+		concurrencyHTTP:  1,
+		concurrencySOCKS: 1,
+		ctx:              context.Background(),
+	}
+	pools := state.workerPools()
+
+	var mutex sync.Mutex
+	var received []string
+	var wg sync.WaitGroup
+	for _, pool := range pools {
+		wg.Add(1)
+		go func(ch <-chan string) {
+			defer wg.Done()
+			for proxy := range ch {
+				mutex.Lock()
+				received = append(received, proxy)
+				mutex.Unlock()
+			}
+		}(pool.ch)
+	}
+
+	if !state.feedPools(pools, nil) {
+		t.Fatal("Expected feeding to complete")
+	}
+	wg.Wait()
+
+	sort.Strings(received)
+	expected := []string{"http://1.2.3.4:8080", "http://9.9.9.9:80", "socks5://5.6.7.8:1080"}
+	if len(received) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, received)
+	}
+	for i := range expected {
+		if received[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, received)
+			break
+		}
+	}
+}
+
+func TestEffectiveConcurrency(t *testing.T) {
+	tests := []struct {
+		requested, proxies int
+		want               int
+		reduced            bool
+	}{
+		{100, 10, 10, true},
+		{10, 10, 10, false},
+		{5, 10, 5, false},
+		{10, 0, 10, false},
+	}
+	for _, tt := range tests {
+		got, reduced := effectiveConcurrency(tt.requested, tt.proxies)
+		if got != tt.want || reduced != tt.reduced {
+			t.Errorf("effectiveConcurrency(%d, %d) = %d, %t; want %d, %t",
+				tt.requested, tt.proxies, got, reduced, tt.want, tt.reduced)
+		}
+	}
+}
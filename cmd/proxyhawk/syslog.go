@@ -0,0 +1,46 @@
+package main
+
+import (
+	"io"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/config"
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/logging"
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/proxy"
+)
+
+// newSyslogLogger connects a logger to the syslog daemon described by cfg.
+// The returned closer closes the connection.
+func newSyslogLogger(cfg config.SyslogConfig) (*logging.Logger, io.Closer, error) {
+	facility, err := logging.ParseSyslogFacility(cfg.Facility)
+	if err != nil {
+		return nil, nil, err
+	}
+	severity, err := logging.ParseSyslogSeverity(cfg.Severity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handler, err := logging.NewSyslogHandler(logging.SyslogConfig{
+		Address:  cfg.Address,
+		Facility: facility,
+		Severity: severity,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return logging.NewLogger(logging.Config{Handler: handler}), handler, nil
+}
+
+// syslogResult sends one completed check to syslog, if enabled
+func (s *AppState) syslogResult(result *proxy.ProxyResult) {
+	if s.syslog == nil {
+		return
+	}
+	proxyURL := proxy.RedactProxyURL(result.ProxyURL)
+	if !result.Working {
+		s.syslog.ProxyFailure(proxyURL, result.Error)
+		return
+	}
+	s.syslog.WithContext("type", result.Type, "score", result.Score).
+		ProxySuccess(proxyURL, result.Speed.Seconds(), result.IsAnonymous, result.CloudProvider)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/loader"
+)
+
+// validateListUsage is printed for a validate-list call without exactly one file
+const validateListUsage = "Usage: proxyhawk validate-list PROXY_LIST > clean.txt"
+
+// runValidateList implements "proxyhawk validate-list FILE": it checks the
+// list without touching the network, writes the cleaned list to stdout and the
+// issues found, with line numbers, to stderr. It returns the exit status.
+func runValidateList(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, validateListUsage)
+		return 2
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer file.Close()
+
+	report, err := loader.CheckList(file)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error reading %s: %v\n", args[0], err)
+		return 1
+	}
+
+	for _, entry := range report.Entries {
+		fmt.Fprintln(stdout, loader.FormatEntry(entry))
+	}
+
+	counts := make(map[string]int)
+	for _, issue := range report.Issues {
+		counts[issue.Kind]++
+		fmt.Fprintf(stderr, "Line %d: [%s] %s\n", issue.Line, issue.Kind, issue.Message)
+	}
+	if report.MixedSchemes() {
+		fmt.Fprintf(stderr, "Mixed schemes: %s (proxies without a scheme are treated as http)\n", report.SchemeSummary())
+	}
+	fmt.Fprintf(stderr, "%d lines, %d clean proxies: %d malformed, %d duplicate, %d reserved, %d private, %d default port\n",
+		report.Lines, len(report.Entries),
+		counts[loader.IssueMalformed], counts[loader.IssueDuplicate], counts[loader.IssueReserved],
+		counts[loader.IssuePrivate], counts[loader.IssueDefaultPort])
+
+	return 0
+}
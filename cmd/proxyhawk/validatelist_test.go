@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunValidateList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proxies.txt")
+	if err := os.WriteFile(path, []byte("1.2.3.4:8080\nhttp://1.2.3.4:8080\n1.2.3.4:99999\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if status := runValidateList([]string{path}, &stdout, &stderr); status != 0 {
+		t.Fatalf("Expected status 0, got %d: %s", status, stderr.String())
+	}
+	if stdout.String() != "http://1.2.3.4:8080\n" {
+		t.Errorf("Expected only the cleaned proxy on stdout, got %q", stdout.String())
+	}
+	for _, want := range []string{"Line 2: [duplicate]", "Line 3: [malformed]", "3 lines, 1 clean proxies"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("Expected %q in the report, got:\n%s", want, stderr.String())
+		}
+	}
+
+	if status := runValidateList(nil, &stdout, &stderr); status != 2 {
+		t.Errorf("Expected usage status 2 without a file, got %d", status)
+	}
+	if status := runValidateList([]string{filepath.Join(t.TempDir(), "missing.txt")}, &stdout, &stderr); status != 1 {
+		t.Errorf("Expected status 1 for a missing file, got %d", status)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// idleWatchdog detects a run that has stopped making progress, e.g. because
+// the network died and every worker is waiting out its timeout. A nil
+// watchdog does nothing.
+type idleWatchdog struct {
+	maxIdle      time.Duration
+	mutex        sync.Mutex
+	lastProgress time.Time
+	inFlight     map[string]bool
+	held         bool // Set while the run is paused, which is not a stall
+}
+
+// newIdleWatchdog returns a watchdog that fires after maxIdle without a
+// completed check, or nil if maxIdle is not positive
+func newIdleWatchdog(maxIdle time.Duration) *idleWatchdog {
+	if maxIdle <= 0 {
+		return nil
+	}
+	return &idleWatchdog{
+		maxIdle:      maxIdle,
+		lastProgress: time.Now(),
+		inFlight:     make(map[string]bool),
+	}
+}
+
+// started records that a check of proxyURL began
+func (w *idleWatchdog) started(proxyURL string) {
+	if w == nil {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.inFlight[proxyURL] = true
+}
+
+// finished records that a check of proxyURL completed, which counts as progress
+func (w *idleWatchdog) finished(proxyURL string) {
+	if w == nil {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	delete(w.inFlight, proxyURL)
+	w.lastProgress = time.Now()
+}
+
+// hold stops a paused run from counting as stalled, or restarts the idle
+// clock when the run resumes
+func (w *idleWatchdog) hold(paused bool) {
+	if w == nil {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.held = paused
+	if !paused {
+		w.lastProgress = time.Now()
+	}
+}
+
+// stalled reports whether no check has completed for maxIdle as of now,
+// along with the proxies that were being checked
+func (w *idleWatchdog) stalled(now time.Time) ([]string, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.held || now.Sub(w.lastProgress) < w.maxIdle {
+		return nil, false
+	}
+	inFlight := make([]string, 0, len(w.inFlight))
+	for proxyURL := range w.inFlight {
+		inFlight = append(inFlight, proxyURL)
+	}
+	sort.Strings(inFlight)
+	return inFlight, true
+}
+
+// run polls for a stall until ctx is done and calls onStall once if one is found
+func (w *idleWatchdog) run(ctx context.Context, onStall func(inFlight []string)) {
+	if w == nil {
+		return
+	}
+
+	interval := w.maxIdle / 4
+	if interval > time.Second {
+		interval = time.Second
+	}
+	if interval <= 0 {
+		interval = w.maxIdle
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if inFlight, ok := w.stalled(now); ok {
+				onStall(inFlight)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIdleWatchdogStalled(t *testing.T) {
+	w := newIdleWatchdog(time.Minute)
+	w.started("http://5.6.7.8:3128")
+	w.started("http://1.2.3.4:8080")
+	w.started("http://9.9.9.9:80")
+	w.finished("http://9.9.9.9:80")
+
+	if _, ok := w.stalled(time.Now()); ok {
+		t.Fatal("Expected no stall right after a check finished")
+	}
+
+	inFlight, ok := w.stalled(time.Now().Add(2 * time.Minute))
+	if !ok {
+		t.Fatal("Expected a stall after max idle without progress")
+	}
+	want := []string{"http://1.2.3.4:8080", "http://5.6.7.8:3128"}
+	if !reflect.DeepEqual(inFlight, want) {
+		t.Errorf("Expected in-flight proxies %v, got %v", want, inFlight)
+	}
+}
+
+func TestIdleWatchdogDisabled(t *testing.T) {
+	w := newIdleWatchdog(0)
+	if w != nil {
+		t.Fatal("Expected no watchdog when max idle is 0")
+	}
+
+	// A nil watchdog is safe to use and returns immediately
+	w.started("http://1.2.3.4:8080")
+	w.finished("http://1.2.3.4:8080")
+	w.run(context.Background(), func([]string) {
+		t.Error("Expected a disabled watchdog never to fire")
+	})
+}
+
+func TestIdleWatchdogStopsRun(t *testing.T) {
+	runCtx, stopRun := context.WithCancelCause(context.Background())
+	ctx, cancel := context.WithCancel(runCtx)
+	defer cancel()
+
+	w := newIdleWatchdog(20 * time.Millisecond)
+	w.started("http://1.2.3.4:8080")
+
+	var stalled []string
+	w.run(ctx, func(inFlight []string) {
+		stalled = inFlight
+		stopRun(errRunStalled)
+	})
+	if len(stalled) != 1 || stalled[0] != "http://1.2.3.4:8080" {
+		t.Errorf("Expected the stuck proxy to be reported, got %v", stalled)
+	}
+
+	s := &AppState{
+		ctx:     ctx,
+		cancel:  cancel,
+		proxies: []string{"http://1.2.3.4:8080"},
+	}
+	if !s.stoppedEarly() {
+		t.Fatal("Expected a stalled run to count as stopped early")
+	}
+	s.markNotChecked()
+	if len(s.results) != 1 || s.results[0].Error == nil ||
+		!strings.Contains(s.results[0].Error.Error(), "not checked (stalled)") {
+		t.Errorf("Expected the proxy to be marked not checked (stalled), got %+v", s.results)
+	}
+}
+
+func TestIdleWatchdogHeldWhilePaused(t *testing.T) {
+	w := newIdleWatchdog(time.Minute)
+	w.hold(true)
+	if _, ok := w.stalled(time.Now().Add(time.Hour)); ok {
+		t.Fatal("Expected a paused run not to count as stalled")
+	}
+
+	// Resuming restarts the idle clock
+	w.hold(false)
+	if _, ok := w.stalled(time.Now().Add(30 * time.Second)); ok {
+		t.Error("Expected the idle time to count from the resume")
+	}
+	if _, ok := w.stalled(time.Now().Add(2 * time.Minute)); !ok {
+		t.Error("Expected a stall after max idle past the resume")
+	}
+}
@@ -15,6 +15,8 @@ import (
 type Config struct {
 	Timeout              int           `yaml:"timeout"`
 	InsecureSkipVerify   bool          `yaml:"insecure_skip_verify"`
+	VerifyTLS            bool          `yaml:"verify_tls"`
+	CACertFile           string        `yaml:"ca_cert_file"`
 	EnableCloudChecks    bool          `yaml:"enable_cloud_checks"`
 	EnableAnonymityCheck bool          `yaml:"enable_anonymity_check"`
 	RateLimitEnabled     bool          `yaml:"rate_limit_enabled"`
@@ -29,6 +31,8 @@ type Config struct {
 	MaxRetryDelay     time.Duration `yaml:"max_retry_delay"`
 	BackoffFactor     float64       `yaml:"backoff_factor"`
 	RetryableErrors   []string      `yaml:"retryable_errors"`
+	HonorRetryAfter   bool          `yaml:"honor_retry_after"`
+	MaxRetryAfter     time.Duration `yaml:"max_retry_after"`
 
 	// Authentication settings
 	AuthEnabled     bool              `yaml:"auth_enabled"`
@@ -49,24 +53,138 @@ type Config struct {
 	// Advanced security checks
 	AdvancedChecks proxy.AdvancedChecks `yaml:"advanced_checks"`
 
+	// Vuln probe scope: path globs or prefixes the vulnerability checks may (or may not) request
+	VulnPathAllowlist []string `yaml:"vuln_path_allowlist"`
+	VulnPathDenylist  []string `yaml:"vuln_path_denylist"`
+
+	// Scoring weights for the 0-100 proxy quality score
+	Scoring proxy.ScoreWeights `yaml:"scoring"`
+
+	// ReadOnlyVulnChecks skips vuln checks that send anything but GET/HEAD (BAN, smuggling, PUT, CONNECT)
+	ReadOnlyVulnChecks bool `yaml:"read_only_vuln_checks"`
+
+	// VulnScanConcurrency caps how many proxies run the vuln scan phase at once (0 = unlimited)
+	VulnScanConcurrency int `yaml:"vuln_scan_concurrency"`
+
+	// MaxIdle stops the whole run if no check completes for this long (0 = disabled)
+	MaxIdle time.Duration `yaml:"max_idle"`
+
+	// MaxGoroutines warns when the process runs more goroutines than this
+	// (0 = disabled); ThrottleGoroutines also starts new checks one at a
+	// time until the count drops back under the limit
+	MaxGoroutines      int  `yaml:"max_goroutines"`
+	ThrottleGoroutines bool `yaml:"throttle_goroutines"`
+
+	// Streaming output durability: how often buffered results are written out
+	// (0 = after every result) and whether each write is fsynced
+	OutputFlushInterval time.Duration `yaml:"output_flush_interval"`
+	OutputFsync         bool          `yaml:"output_fsync"`
+
+	// DebugLogLines caps the lines kept for the scrollable TUI debug log (0 = 1000)
+	DebugLogLines int `yaml:"debug_log_lines"`
+
 	// Response validation settings
 	RequireStatusCode   int      `yaml:"require_status_code"`
 	RequireContentMatch string   `yaml:"require_content_match"`
 	RequireHeaderFields []string `yaml:"require_header_fields"`
 
+	// RequireBothHTTPAndHTTPS only reports proxies that handled both HTTP and HTTPS targets as working
+	RequireBothHTTPAndHTTPS bool `yaml:"require_both_http_and_https"`
+
+	// FollowRedirects follows redirects of validation requests, aborting
+	// chains that revisit a URL or run past MaxRedirects
+	FollowRedirects bool `yaml:"follow_redirects"`
+	MaxRedirects    int  `yaml:"max_redirects"` // 0 = 10
+
+	// ForceIPv6Target validates against the IPv6 address of the validation
+	// host and reports which proxies reach IPv6 targets
+	ForceIPv6Target bool `yaml:"force_ipv6_target"`
+
+	// MinimalHeaders also sends the validation request with only Host and User-Agent to spot header-based blocking
+	MinimalHeaders bool `yaml:"minimal_headers"`
+
+	// EchoHeaders records the full set of headers a header-echo endpoint received through each working proxy
+	EchoHeaders    bool   `yaml:"echo_headers"`
+	EchoHeadersURL string `yaml:"echo_headers_url"` // Empty = anonymity check URL
+
+	// WebSocketCheck opens a WebSocket to an echo endpoint through each working proxy and verifies the echo
+	WebSocketCheck   bool   `yaml:"websocket_check"`
+	WebSocketEchoURL string `yaml:"websocket_echo_url"` // Empty = wss://echo.websocket.org
+
+	// EgressPTR records the IP each working proxy egresses from and its reverse DNS (PTR) name
+	EgressPTR   bool   `yaml:"egress_ptr"`
+	EgressIPURL string `yaml:"egress_ip_url"` // Empty = https://api.ipify.org
+
+	// Heuristics flagging honeypot-like or tampering proxies (suspicious_score)
+	SuspiciousCheck SuspiciousCheckConfig `yaml:"suspicious_check"`
+
 	// Metrics settings
 	Metrics MetricsConfig `yaml:"metrics"`
 
+	// Syslog settings for sending per-proxy results and the summary to a syslog daemon
+	Syslog SyslogConfig `yaml:"syslog"`
+
 	// Connection pool settings
 	ConnectionPool ConnectionPoolConfig `yaml:"connection_pool"`
 
+	// Proxy types tried for proxies without a scheme, in order (empty = HTTP/HTTPS first, then SOCKS)
+	DetectionOrder []string `yaml:"detection_order"`
+
 	// HTTP/2 and HTTP/3 settings
 	EnableHTTP2 bool `yaml:"enable_http2"`
 	EnableHTTP3 bool `yaml:"enable_http3"`
 
+	// HTTP request version used for an additional HTTP/1.0 check ("1.0" or "1.1")
+	HTTPVersion string `yaml:"http_version"`
+
 	// Fingerprinting settings
 	EnableFingerprint bool `yaml:"enable_fingerprint"`
 
+	// Target resolution cache: resolve each target hostname once per TTL instead of per check
+	ResolveOnce bool          `yaml:"resolve_once"`
+	DNSCacheTTL time.Duration `yaml:"dns_cache_ttl"`
+
+	// Resolver pins the DNS server used for proxy and target hostnames:
+	// host[:port] or an https:// DNS-over-HTTPS URL (empty = system resolver)
+	Resolver string `yaml:"resolver"`
+
+	// ProxyChain is a jump proxy URL (http, socks4, socks4a, socks5) every
+	// proxy is reached through, for proxies only reachable via another proxy
+	ProxyChain string `yaml:"proxy_chain"`
+
+	// DNS retry: transient resolver failures are retried separately from the general retry policy
+	DNSRetries int `yaml:"dns_retries"`
+
+	// Anonymity check settings
+	AnonymityCheck AnonymityCheckConfig `yaml:"anonymity_check"`
+
+	// Content similarity settings
+	ContentSimilarity ContentSimilarityConfig `yaml:"content_similarity"`
+
+	// GeoIPURL is the IP-geolocation endpoint used to verify a proxy's expect_country
+	GeoIPURL string `yaml:"geoip_url"`
+
+	// Proxy classification (datacenter, residential, mobile) by exit organization
+	ProxyClass ProxyClassConfig `yaml:"proxy_class"`
+
+	// Download throughput measurement through working proxies
+	Throughput ThroughputConfig `yaml:"throughput"`
+
+	// Rotating pool detection by sampling the exit IP of working proxies
+	RotationDetection RotationDetectionConfig `yaml:"rotation_detection"`
+
+	// TLS certificate inspection of HTTPS validation targets to detect interception
+	TLSInspection TLSInspectionConfig `yaml:"tls_inspection"`
+
+	// Site category access (social, adult, news, streaming) through working proxies
+	CategoryCheck CategoryCheckConfig `yaml:"category_check"`
+
+	// Exit IP reputation lookups on DNSBLs and an abuse-score API
+	Reputation ReputationConfig `yaml:"reputation"`
+
+	// gRPC health-check test target for proxies that front gRPC services
+	GRPCCheck GRPCCheckConfig `yaml:"grpc_check"`
+
 	// Discovery settings
 	Discovery DiscoveryConfig `yaml:"discovery"`
 }
@@ -75,6 +193,18 @@ type Config struct {
 type TestURLConfig struct {
 	DefaultURL string    `yaml:"default_url"`
 	TestURLs   []TestURL `yaml:"test_urls"`
+
+	// RequiredSuccessCount is how many of TestURLs a proxy must reach to count as working (0 = not checked)
+	RequiredSuccessCount int `yaml:"required_success_count"`
+}
+
+// URLs returns the URLs of the configured test URLs in order
+func (c TestURLConfig) URLs() []string {
+	urls := make([]string, 0, len(c.TestURLs))
+	for _, testURL := range c.TestURLs {
+		urls = append(urls, testURL.URL)
+	}
+	return urls
 }
 
 // TestURL represents a single test URL configuration
@@ -83,10 +213,84 @@ type TestURL struct {
 	ExpectText string `yaml:"expect_text"`
 }
 
+// ProxyClassConfig contains settings for classifying working proxies by the
+// ASN/organization they egress from
+type ProxyClassConfig struct {
+	Enabled             bool     `yaml:"enabled"`
+	URL                 string   `yaml:"url"`                  // Endpoint returning the exit ASN/organization
+	DatacenterKeywords  []string `yaml:"datacenter_keywords"`  // Empty = built-in hosting keywords
+	ResidentialKeywords []string `yaml:"residential_keywords"` // Empty = built-in ISP keywords
+	MobileKeywords      []string `yaml:"mobile_keywords"`      // Empty = built-in carrier keywords
+}
+
+// ThroughputConfig contains settings for measuring the download throughput
+// of working proxies
+type ThroughputConfig struct {
+	Enabled bool          `yaml:"enabled"`
+	URL     string        `yaml:"url"`     // Payload URL (empty = Cloudflare speed test endpoint serving size bytes)
+	Size    int64         `yaml:"size"`    // Bytes to download (0 = 1MB)
+	Timeout time.Duration `yaml:"timeout"` // Download deadline (0 = timeout)
+}
+
+// RotationDetectionConfig contains settings for telling whether a proxy
+// endpoint is a pool that rotates its exit IP
+type RotationDetectionConfig struct {
+	Enabled             bool          `yaml:"enabled"`
+	MinSamples          int           `yaml:"min_samples"`          // Exit IP samples per proxy (0 = 5)
+	SampleInterval      time.Duration `yaml:"sample_interval"`      // Pause between samples
+	ConfidenceThreshold float64       `yaml:"confidence_threshold"` // Share of samples that must change exit IP (0 = 0.5)
+}
+
+// TLSInspectionConfig contains settings for recording the certificate HTTPS
+// validation targets present through each proxy and detecting interception
+type TLSInspectionConfig struct {
+	Enabled            bool                `yaml:"enabled"`
+	PinnedFingerprints map[string][]string `yaml:"pinned_fingerprints"` // Hostname to SHA-256 certificate fingerprints (empty = compare with a direct connection)
+}
+
+// CategoryCheckConfig contains settings for testing which site categories
+// working proxies can reach
+type CategoryCheckConfig struct {
+	Enabled    bool                `yaml:"enabled"`
+	Categories map[string][]string `yaml:"categories"` // Category name to representative URLs (empty = built-in categories)
+}
+
+// ReputationConfig contains settings for looking up the exit IP of working
+// proxies on DNS blocklists and an abuse-score API
+type ReputationConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	DNSBLZones []string      `yaml:"dnsbl_zones"` // Empty = zen.spamhaus.org
+	APIURL     string        `yaml:"api_url"`     // Abuse-score endpoint, {ip} is replaced by the exit IP (empty = DNSBLs only)
+	APIKey     string        `yaml:"api_key"`     // Sent in the Key header, as AbuseIPDB expects
+	Timeout    time.Duration `yaml:"timeout"`     // Per lookup (0 = 3s)
+}
+
+// SuspiciousCheckConfig contains settings for the heuristics that score
+// working proxies for honeypot-like or tampering behavior
+type SuspiciousCheckConfig struct {
+	Enabled            bool          `yaml:"enabled"`
+	Indicators         []string      `yaml:"indicators"`           // any_credentials, identical_responses, tracking_injection, latency_anomaly (empty = all)
+	MinUpstreamLatency time.Duration `yaml:"min_upstream_latency"` // Faster answers after sending the request count as latency_anomaly (0 = 2ms)
+}
+
+// GRPCCheckConfig contains settings for checking proxies with a gRPC health
+// check instead of HTTP validation
+type GRPCCheckConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Target  string `yaml:"target"`  // grpc://host:port (h2c) or grpcs://host:port (TLS)
+	Service string `yaml:"service"` // Service name to check (empty = overall server health)
+}
+
 // ValidationConfig contains validation settings
 type ValidationConfig struct {
 	DisallowedKeywords []string `yaml:"disallowed_keywords"`
 	MinResponseBytes   int      `yaml:"min_response_bytes"`
+	// Bodies larger than MaxResponseBytes or still streaming after
+	// BodyReadTimeout are cut off and reported as unbounded
+	MaxResponseBytes int64         `yaml:"max_response_bytes"` // 0 = 10MB
+	BodyReadTimeout  time.Duration `yaml:"body_read_timeout"`  // 0 = the check timeout
+	// ExternalCommand is run with the response body on stdin; exit code 0 means valid
+	ExternalCommand string `yaml:"external_command"`
 }
 
 // MetricsConfig contains metrics and monitoring settings
@@ -96,6 +300,14 @@ type MetricsConfig struct {
 	Path       string `yaml:"path"`
 }
 
+// SyslogConfig contains settings for sending results to syslog (RFC 5424)
+type SyslogConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Address  string `yaml:"address"`  // udp://host:port, tcp://host:port, unix:///path or host:port (empty = local syslog socket)
+	Facility string `yaml:"facility"` // kern, user, daemon, local0-local7, ... (empty = user)
+	Severity string `yaml:"severity"` // Severity of result and summary messages: info, notice, ... (empty = info)
+}
+
 // ConnectionPoolConfig contains HTTP connection pool settings
 type ConnectionPoolConfig struct {
 	MaxIdleConns          int           `yaml:"max_idle_conns"`
@@ -109,6 +321,20 @@ type ConnectionPoolConfig struct {
 	DisableCompression    bool          `yaml:"disable_compression"`
 }
 
+// AnonymityCheckConfig contains settings for the header-echo endpoints used to detect anonymity
+type AnonymityCheckConfig struct {
+	URL              string        `yaml:"url"`
+	FallbackURLs     []string      `yaml:"fallback_urls"`
+	RateLimitBackoff time.Duration `yaml:"rate_limit_backoff"`
+}
+
+// ContentSimilarityConfig contains settings for comparing proxied content with a direct fetch
+type ContentSimilarityConfig struct {
+	Enabled      bool    `yaml:"enabled"`
+	MaxBodyBytes int     `yaml:"max_body_bytes"`
+	Threshold    float64 `yaml:"threshold"`
+}
+
 // DiscoveryConfig holds configuration for proxy discovery
 type DiscoveryConfig struct {
 	// API credentials
@@ -148,9 +374,10 @@ func LoadConfig(filename string) (*Config, error) {
 		return GetDefaultConfig(), nil
 	}
 
-	data, err := os.ReadFile(filename)
+	// Merge any files named by an include directive
+	data, err := readConfigWithIncludes(filename)
 	if err != nil {
-		return nil, errors.NewFileError(errors.ErrorFileReadFailed, "failed to read config file", filename, err)
+		return nil, err
 	}
 
 	var config Config
@@ -223,6 +450,8 @@ func GetDefaultConfig() *Config {
 			"context deadline exceeded",
 			"i/o timeout",
 		},
+		HonorRetryAfter: true,
+		MaxRetryAfter:   time.Minute,
 
 		// Default authentication settings
 		AuthEnabled:     false, // Disabled by default for security
@@ -273,6 +502,51 @@ func GetDefaultConfig() *Config {
 		// HTTP/2 and HTTP/3 settings
 		EnableHTTP2: true,  // Enable HTTP/2 by default
 		EnableHTTP3: false, // Disable HTTP/3 by default (requires additional dependencies)
+		HTTPVersion: "1.1", // Go's default; set to "1.0" to detect HTTP/1.0-only proxies
+
+		// Target resolution cache settings
+		ResolveOnce: false,
+		DNSCacheTTL: 5 * time.Minute,
+
+		// DNS retry settings
+		DNSRetries: 2,
+
+		// Content similarity settings
+		ContentSimilarity: ContentSimilarityConfig{
+			Enabled:      false,
+			MaxBodyBytes: 65536,
+			Threshold:    0.8,
+		},
+
+		// Exit country lookup endpoint
+		GeoIPURL: "https://ipinfo.io/country",
+
+		// Proxy quality score weights
+		Scoring: proxy.DefaultScoreWeights(),
+
+		// Proxy classification settings
+		ProxyClass: ProxyClassConfig{
+			Enabled: false,
+			URL:     "https://ipinfo.io/org",
+		},
+
+		Throughput: ThroughputConfig{
+			Enabled: false,
+			Size:    1 << 20,
+		},
+
+		RotationDetection: RotationDetectionConfig{
+			Enabled:             false,
+			MinSamples:          5,
+			ConfidenceThreshold: 0.5,
+		},
+
+		// Anonymity check settings
+		AnonymityCheck: AnonymityCheckConfig{
+			URL:              "https://httpbin.org/headers",
+			FallbackURLs:     []string{},
+			RateLimitBackoff: 30 * time.Second,
+		},
 
 		// Discovery settings
 		Discovery: DiscoveryConfig{
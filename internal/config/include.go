@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/errors"
+)
+
+// includeKey is the top-level key listing config files to merge into a config
+const includeKey = "include"
+
+// readConfigWithIncludes reads a config file and resolves its include
+// directive. "include:" names one file or a list of files, relative to the
+// including file. The included files are merged in order and the including
+// file is merged last, so later files override keys of earlier ones. Mappings
+// are merged key by key; lists and scalars are replaced. A file without
+// includes is returned unchanged.
+func readConfigWithIncludes(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.NewFileError(errors.ErrorFileReadFailed, "failed to read config file", filename, err)
+	}
+
+	var top map[string]interface{}
+	if err := yaml.Unmarshal(data, &top); err != nil || top[includeKey] == nil {
+		// Parse errors are reported by the caller against the original data
+		return data, nil
+	}
+
+	merged, err := loadIncludedConfig(filename, nil)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(merged)
+}
+
+// loadIncludedConfig loads filename and everything it includes into one
+// mapping. chain holds the files currently being included, to detect cycles;
+// a file may still be included more than once from different branches.
+func loadIncludedConfig(filename string, chain []string) (map[string]interface{}, error) {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		path = filepath.Clean(filename)
+	}
+	for i, visited := range chain {
+		if visited == path {
+			cycle := append(append([]string(nil), chain[i:]...), path)
+			return nil, errors.NewConfigError(errors.ErrorConfigInvalid,
+				"circular config include: "+strings.Join(cycle, " -> "), nil).
+				WithDetail("filename", filename)
+		}
+	}
+	chain = append(chain, path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.NewFileError(errors.ErrorFileReadFailed, "failed to read included config file", path, err)
+	}
+
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, errors.NewConfigError(errors.ErrorConfigParsingFailed, "error parsing config file", err).
+			WithDetail("filename", path)
+	}
+
+	includes, err := includePaths(values[includeKey])
+	if err != nil {
+		return nil, errors.NewConfigError(errors.ErrorConfigInvalid, "invalid include directive", err).
+			WithDetail("filename", path)
+	}
+	delete(values, includeKey)
+
+	merged := make(map[string]interface{})
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		included, err := loadIncludedConfig(include, chain)
+		if err != nil {
+			return nil, err
+		}
+		mergeConfigValues(merged, included)
+	}
+	mergeConfigValues(merged, values)
+
+	return merged, nil
+}
+
+// includePaths returns the files named by an include value: a string or a list of strings
+func includePaths(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		paths := make([]string, 0, len(v))
+		for _, item := range v {
+			path, ok := item.(string)
+			if !ok || path == "" {
+				return nil, fmt.Errorf("include entries must be file paths, got %v", item)
+			}
+			paths = append(paths, path)
+		}
+		return paths, nil
+	default:
+		return nil, fmt.Errorf("include must be a file path or a list of file paths, got %v", value)
+	}
+}
+
+// mergeConfigValues merges src into dst. Nested mappings are merged
+// recursively; any other value in src replaces the one in dst.
+func mergeConfigValues(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeConfigValues(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
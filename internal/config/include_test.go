@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfigFiles writes name -> content files into a temp dir and returns the dir
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadConfigInclude(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"shared/headers.yaml": `
+user_agent: "shared-agent"
+default_headers:
+  Accept: "*/*"
+  DNT: "1"
+`,
+		"shared/checks.yaml": `
+timeout: 5
+default_headers:
+  DNT: "0"
+advanced_checks:
+  test_ssrf: true
+  test_http_methods: ["GET", "POST"]
+`,
+		"prod.yaml": `
+include:
+  - shared/headers.yaml
+  - shared/checks.yaml
+timeout: 20
+base: &base
+  test_ssrf: false
+advanced_checks:
+  <<: *base
+  test_http_methods: ["HEAD"]
+`,
+	})
+
+	cfg, err := LoadConfig(filepath.Join(dir, "prod.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	if cfg.Timeout != 20 {
+		t.Errorf("Expected the including file to override timeout, got %d", cfg.Timeout)
+	}
+	if cfg.UserAgent != "shared-agent" {
+		t.Errorf("Expected user_agent from the first include, got %q", cfg.UserAgent)
+	}
+	if cfg.DefaultHeaders["Accept"] != "*/*" || cfg.DefaultHeaders["DNT"] != "0" {
+		t.Errorf("Expected merged headers with the later include winning, got %v", cfg.DefaultHeaders)
+	}
+	if cfg.AdvancedChecks.TestSSRF {
+		t.Error("Expected the anchored test_ssrf: false to override the include")
+	}
+	if strings.Join(cfg.AdvancedChecks.TestHTTPMethods, ",") != "HEAD" {
+		t.Errorf("Expected lists to be replaced, got %v", cfg.AdvancedChecks.TestHTTPMethods)
+	}
+}
+
+func TestLoadConfigIncludeErrors(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"a.yaml":       "include: b.yaml\ntimeout: 1\n",
+		"b.yaml":       "include: [c.yaml]\n",
+		"c.yaml":       "include: a.yaml\n",
+		"diamond.yaml": "include: [d.yaml, d.yaml]\n",
+		"d.yaml":       "timeout: 3\n",
+		"bad.yaml":     "include: {file: d.yaml}\n",
+		"missing.yaml": "include: nowhere.yaml\n",
+	})
+
+	_, err := LoadConfig(filepath.Join(dir, "a.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "circular config include") ||
+		!strings.Contains(err.Error(), filepath.Join(dir, "a.yaml")+" -> ") {
+		t.Errorf("Expected a circular include error naming the chain, got %v", err)
+	}
+
+	cfg, err := LoadConfig(filepath.Join(dir, "diamond.yaml"))
+	if err != nil || cfg.Timeout != 3 {
+		t.Errorf("Expected a file included twice to load, got %v (timeout %v)", err, cfg)
+	}
+
+	if _, err := LoadConfig(filepath.Join(dir, "bad.yaml")); err == nil || !strings.Contains(err.Error(), "invalid include") {
+		t.Errorf("Expected an invalid include error, got %v", err)
+	}
+	if _, err := LoadConfig(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing included file")
+	}
+}
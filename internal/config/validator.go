@@ -2,9 +2,15 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"net/url"
+	"os/exec"
+	"path"
 	"strings"
 	"time"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/logging"
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/proxy"
 )
 
 // ValidationResult represents the result of configuration validation
@@ -98,6 +104,7 @@ func ValidateConfig(config *Config) *ValidationResult {
 
 	// Validate metrics settings
 	validateMetricsSettings(config, result)
+	validateSyslogSettings(config, result)
 
 	// Validate connection pool settings
 	validateConnectionPoolSettings(config, result)
@@ -105,6 +112,149 @@ func ValidateConfig(config *Config) *ValidationResult {
 	// Validate retry settings
 	validateRetrySettings(config, result)
 
+	// Validate content similarity threshold
+	if config.ContentSimilarity.Enabled && (config.ContentSimilarity.Threshold < 0 || config.ContentSimilarity.Threshold > 1) {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "content_similarity.threshold",
+			Value:   config.ContentSimilarity.Threshold,
+			Message: "similarity threshold must be between 0 and 1",
+		})
+	}
+
+	// Validate the proxy type detection order
+	seenTypes := make(map[string]bool)
+	for _, name := range config.DetectionOrder {
+		lower := strings.ToLower(name)
+		if !proxy.IsDetectionType(lower) {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "detection_order",
+				Value:   name,
+				Message: "unknown proxy type (use http, https, socks4, socks4a or socks5)",
+			})
+		} else if seenTypes[lower] {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("proxy type %s is listed more than once in detection_order", name))
+		}
+		seenTypes[lower] = true
+	}
+
+	// Validate scoring weights
+	validateScoring(config, result)
+
+	// Extra CA roots only matter when certificates are verified
+	if config.CACertFile != "" && !config.VerifyTLS {
+		result.Warnings = append(result.Warnings, "ca_cert_file is set but verify_tls is disabled, the CA file will have no effect")
+	}
+
+	// Validate target resolution cache TTL
+	if config.ResolveOnce && config.DNSCacheTTL < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "dns_cache_ttl",
+			Value:   config.DNSCacheTTL,
+			Message: "DNS cache TTL cannot be negative",
+		})
+	}
+
+	// Validate the DNS resolver
+	if _, err := proxy.NewResolver(config.Resolver, nil); err != nil {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "resolver",
+			Value:   config.Resolver,
+			Message: err.Error(),
+		})
+	}
+
+	// Validate the jump proxy of a proxy chain
+	if config.ProxyChain != "" {
+		if _, err := proxy.NewChainDialer(config.ProxyChain, nil, nil); err != nil {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "proxy_chain",
+				Value:   proxy.RedactProxyURL(config.ProxyChain),
+				Message: err.Error(),
+			})
+		}
+	}
+
+	// Validate DNS retries
+	if config.DNSRetries < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "dns_retries",
+			Value:   config.DNSRetries,
+			Message: "DNS retries cannot be negative",
+		})
+	}
+
+	// Validate vuln scan concurrency
+	if config.VulnScanConcurrency < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "vuln_scan_concurrency",
+			Value:   config.VulnScanConcurrency,
+			Message: "vuln scan concurrency cannot be negative",
+		})
+	}
+
+	// Validate the idle watchdog
+	if config.MaxIdle < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "max_idle",
+			Value:   config.MaxIdle,
+			Message: "max idle cannot be negative",
+		})
+	}
+
+	// Validate the goroutine guardrail
+	if config.MaxGoroutines < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "max_goroutines",
+			Value:   config.MaxGoroutines,
+			Message: "max goroutines cannot be negative",
+		})
+	} else if config.ThrottleGoroutines && config.MaxGoroutines == 0 {
+		result.Warnings = append(result.Warnings, "throttle_goroutines is enabled but max_goroutines is 0, nothing will be throttled")
+	}
+
+	// Validate the debug log size
+	if config.DebugLogLines < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "debug_log_lines",
+			Value:   config.DebugLogLines,
+			Message: "debug log lines cannot be negative",
+		})
+	}
+
+	// Validate the streaming output flush interval
+	if config.OutputFlushInterval < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "output_flush_interval",
+			Value:   config.OutputFlushInterval,
+			Message: "output flush interval cannot be negative",
+		})
+	}
+
+	// Validate vuln probe scope patterns
+	validateVulnPathPatterns("vuln_path_allowlist", config.VulnPathAllowlist, result)
+	validateVulnPathPatterns("vuln_path_denylist", config.VulnPathDenylist, result)
+
+	// Validate HTTP version
+	if config.HTTPVersion != "" && config.HTTPVersion != "1.0" && config.HTTPVersion != "1.1" {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "http_version",
+			Value:   config.HTTPVersion,
+			Message: "http version must be \"1.0\" or \"1.1\"",
+		})
+	}
+
 	return result
 }
 
@@ -144,6 +294,16 @@ func validateURLs(config *Config, result *ValidationResult) {
 		}
 	}
 
+	// Validate the test URL quorum
+	if config.TestURLs.RequiredSuccessCount < 0 || config.TestURLs.RequiredSuccessCount > len(config.TestURLs.TestURLs) {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "test_urls.required_success_count",
+			Value:   config.TestURLs.RequiredSuccessCount,
+			Message: fmt.Sprintf("required success count must be between 0 and the number of test URLs (%d)", len(config.TestURLs.TestURLs)),
+		})
+	}
+
 	// Validate Interactsh URL if provided
 	if config.InteractshURL != "" {
 		if _, err := url.Parse(config.InteractshURL); err != nil {
@@ -155,6 +315,227 @@ func validateURLs(config *Config, result *ValidationResult) {
 			})
 		}
 	}
+
+	// Validate the proxy classification endpoint if provided
+	if config.ProxyClass.URL != "" {
+		if parsed, err := url.Parse(config.ProxyClass.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "proxy_class.url",
+				Value:   config.ProxyClass.URL,
+				Message: "must be an http or https URL",
+			})
+		}
+	}
+
+	// Validate the header-echo endpoint if provided
+	if config.EchoHeadersURL != "" {
+		if parsed, err := url.Parse(config.EchoHeadersURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "echo_headers_url",
+				Value:   config.EchoHeadersURL,
+				Message: "must be an http or https URL",
+			})
+		}
+	}
+
+	// Validate the WebSocket echo endpoint if provided
+	if config.WebSocketEchoURL != "" {
+		if parsed, err := url.Parse(config.WebSocketEchoURL); err != nil || (parsed.Scheme != "ws" && parsed.Scheme != "wss") {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "websocket_echo_url",
+				Value:   config.WebSocketEchoURL,
+				Message: "must be a ws or wss URL",
+			})
+		}
+	}
+
+	// Validate the redirect cap
+	if config.MaxRedirects < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "max_redirects",
+			Value:   config.MaxRedirects,
+			Message: "must not be negative (0 = default of 10)",
+		})
+	}
+
+	// An HTTPS validation URL cannot be pinned to an IPv6 address
+	if config.ForceIPv6Target {
+		if parsed, err := url.Parse(config.TestURLs.DefaultURL); err == nil && parsed.Scheme == "https" && net.ParseIP(parsed.Hostname()) == nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf(
+				"force_ipv6_target only pins plain HTTP validation URLs; %s is only tested over IPv6 if %s has no IPv4 addresses (e.g. use https://api6.ipify.org)",
+				config.TestURLs.DefaultURL, parsed.Hostname()))
+		}
+	}
+
+	// Validate the egress IP endpoint if provided
+	if config.EgressIPURL != "" {
+		if parsed, err := url.Parse(config.EgressIPURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "egress_ip_url",
+				Value:   config.EgressIPURL,
+				Message: "must be an http or https URL",
+			})
+		}
+	}
+
+	// Validate the throughput download
+	if config.Throughput.Enabled {
+		if config.Throughput.URL != "" {
+			if parsed, err := url.Parse(config.Throughput.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+				result.Valid = false
+				result.Errors = append(result.Errors, ConfigValidationError{
+					Field:   "throughput.url",
+					Value:   config.Throughput.URL,
+					Message: "must be an http or https URL",
+				})
+			}
+		}
+		if config.Throughput.Size < 0 {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "throughput.size",
+				Value:   config.Throughput.Size,
+				Message: "payload size cannot be negative",
+			})
+		}
+		if config.Throughput.Timeout < 0 {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "throughput.timeout",
+				Value:   config.Throughput.Timeout,
+				Message: "download timeout cannot be negative",
+			})
+		}
+	}
+
+	// Validate rotation detection sampling
+	if config.RotationDetection.Enabled {
+		if config.RotationDetection.MinSamples == 1 || config.RotationDetection.MinSamples < 0 {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "rotation_detection.min_samples",
+				Value:   config.RotationDetection.MinSamples,
+				Message: "at least 2 samples are needed to detect rotation (0 = default of 5)",
+			})
+		}
+		if config.RotationDetection.SampleInterval < 0 {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "rotation_detection.sample_interval",
+				Value:   config.RotationDetection.SampleInterval,
+				Message: "sample interval cannot be negative",
+			})
+		}
+		if config.RotationDetection.ConfidenceThreshold < 0 || config.RotationDetection.ConfidenceThreshold > 1 {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "rotation_detection.confidence_threshold",
+				Value:   config.RotationDetection.ConfidenceThreshold,
+				Message: "confidence threshold must be between 0 and 1",
+			})
+		}
+	}
+
+	// Validate the pinned certificate fingerprints
+	for host, fingerprints := range config.TLSInspection.PinnedFingerprints {
+		for _, fingerprint := range fingerprints {
+			if !isSHA256Fingerprint(proxy.NormalizeFingerprint(fingerprint)) {
+				result.Valid = false
+				result.Errors = append(result.Errors, ConfigValidationError{
+					Field:   "tls_inspection.pinned_fingerprints." + host,
+					Value:   fingerprint,
+					Message: "must be a hex SHA-256 certificate fingerprint (64 hex digits, colons allowed)",
+				})
+			}
+		}
+	}
+
+	// Validate the suspicious behavior indicators
+	for _, name := range config.SuspiciousCheck.Indicators {
+		if !proxy.IsSuspiciousIndicator(name) {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "suspicious_check.indicators",
+				Value:   name,
+				Message: "must be one of any_credentials, identical_responses, tracking_injection, latency_anomaly",
+			})
+		}
+	}
+	if config.SuspiciousCheck.MinUpstreamLatency < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "suspicious_check.min_upstream_latency",
+			Value:   config.SuspiciousCheck.MinUpstreamLatency,
+			Message: "must not be negative",
+		})
+	}
+
+	// Validate exit IP reputation settings
+	for _, zone := range config.Reputation.DNSBLZones {
+		if strings.Trim(zone, ". ") == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "reputation.dnsbl_zones",
+				Value:   zone,
+				Message: "DNSBL zone cannot be empty",
+			})
+		}
+	}
+	if config.Reputation.APIURL != "" {
+		if parsed, err := url.Parse(strings.ReplaceAll(config.Reputation.APIURL, "{ip}", "192.0.2.1")); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "reputation.api_url",
+				Value:   config.Reputation.APIURL,
+				Message: "abuse score API URL must be an http or https URL",
+			})
+		} else if !strings.Contains(config.Reputation.APIURL, "{ip}") {
+			result.Warnings = append(result.Warnings, "reputation.api_url has no {ip} placeholder, every proxy will get the same abuse score")
+		}
+	}
+	if config.Reputation.Timeout < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "reputation.timeout",
+			Value:   config.Reputation.Timeout,
+			Message: "reputation timeout cannot be negative",
+		})
+	}
+
+	// Validate the category check URLs
+	for name, categoryURLs := range config.CategoryCheck.Categories {
+		if len(categoryURLs) == 0 {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("category_check category %q has no URLs and will be reported as unreachable", name))
+		}
+		for _, categoryURL := range categoryURLs {
+			if parsed, err := url.Parse(categoryURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+				result.Valid = false
+				result.Errors = append(result.Errors, ConfigValidationError{
+					Field:   "category_check.categories." + name,
+					Value:   categoryURL,
+					Message: "must be an http or https URL",
+				})
+			}
+		}
+	}
+
+	// Validate the gRPC test target when the gRPC check is enabled
+	if config.GRPCCheck.Enabled {
+		if parsed, err := url.Parse(config.GRPCCheck.Target); err != nil || (parsed.Scheme != "grpc" && parsed.Scheme != "grpcs") || parsed.Hostname() == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "grpc_check.target",
+				Value:   config.GRPCCheck.Target,
+				Message: "must be a grpc://host:port or grpcs://host:port URL",
+			})
+		}
+	}
 }
 
 // validateHeaders validates HTTP headers
@@ -200,6 +581,31 @@ func validateValidationSettings(config *Config, result *ValidationResult) {
 				config.Validation.MinResponseBytes))
 	}
 
+	if config.Validation.MaxResponseBytes < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "validation.max_response_bytes",
+			Value:   config.Validation.MaxResponseBytes,
+			Message: "maximum response bytes cannot be negative",
+		})
+	} else if config.Validation.MaxResponseBytes > 0 && config.Validation.MaxResponseBytes < int64(config.Validation.MinResponseBytes) {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "validation.max_response_bytes",
+			Value:   config.Validation.MaxResponseBytes,
+			Message: "maximum response bytes cannot be below min_response_bytes",
+		})
+	}
+
+	if config.Validation.BodyReadTimeout < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "validation.body_read_timeout",
+			Value:   config.Validation.BodyReadTimeout,
+			Message: "body read timeout cannot be negative",
+		})
+	}
+
 	// Check for duplicate disallowed keywords
 	seen := make(map[string]bool)
 	for _, keyword := range config.Validation.DisallowedKeywords {
@@ -210,6 +616,22 @@ func validateValidationSettings(config *Config, result *ValidationResult) {
 		}
 		seen[lower] = true
 	}
+
+	// Check the external validation command parses and can be found
+	args, err := proxy.SplitCommand(config.Validation.ExternalCommand)
+	if err != nil {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "validation.external_command",
+			Value:   config.Validation.ExternalCommand,
+			Message: err.Error(),
+		})
+	} else if len(args) > 0 {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("external validation command not found: %v", err))
+		}
+	}
 }
 
 // validateCloudProviders validates cloud provider configurations
@@ -293,6 +715,34 @@ func validateAdvancedChecks(config *Config, result *ValidationResult) {
 		}
 	}
 
+	// Warn about disabled checks that do not exist, which would otherwise still run under a typo
+	for _, name := range checks.DisabledChecks {
+		if !proxy.IsVulnCheckName(name) {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("advanced_checks.disabled_checks: unknown vuln check '%s'", name))
+		}
+	}
+
+	// Validate the redirect chain SSRF test settings
+	if checks.RedirectChainMaxRedirects < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "advanced_checks.redirect_chain_max_redirects",
+			Value:   checks.RedirectChainMaxRedirects,
+			Message: "must not be negative (use disable_redirect_chain to skip the test)",
+		})
+	}
+	for i, host := range checks.RedirectChainHosts {
+		if parsed, err := url.Parse("http://" + host); err != nil || host == "" || parsed.Host != host {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   fmt.Sprintf("advanced_checks.redirect_chain_hosts[%d]", i),
+				Value:   host,
+				Message: "must be a host or host:port without scheme or path",
+			})
+		}
+	}
+
 	// Warn if no security checks are enabled
 	if !checks.TestProtocolSmuggling && !checks.TestDNSRebinding && !checks.TestIPv6 &&
 		len(checks.TestHTTPMethods) == 0 && !checks.TestCachePoisoning && 
@@ -302,6 +752,29 @@ func validateAdvancedChecks(config *Config, result *ValidationResult) {
 	}
 }
 
+// validateVulnPathPatterns checks that vuln probe scope entries are absolute paths and valid globs
+func validateVulnPathPatterns(field string, patterns []string, result *ValidationResult) {
+	for i, pattern := range patterns {
+		if !strings.HasPrefix(pattern, "/") {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   fmt.Sprintf("%s[%d]", field, i),
+				Value:   pattern,
+				Message: "path must start with '/'",
+			})
+			continue
+		}
+		if _, err := path.Match(pattern, "/"); err != nil {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   fmt.Sprintf("%s[%d]", field, i),
+				Value:   pattern,
+				Message: fmt.Sprintf("invalid path pattern: %v", err),
+			})
+		}
+	}
+}
+
 // validateResponseRequirements validates response requirement settings
 func validateResponseRequirements(config *Config, result *ValidationResult) {
 	// Validate status code requirement
@@ -385,6 +858,36 @@ func validateMetricsSettings(config *Config, result *ValidationResult) {
 	}
 }
 
+// validateSyslogSettings validates syslog configuration
+func validateSyslogSettings(config *Config, result *ValidationResult) {
+	if _, err := logging.ParseSyslogFacility(config.Syslog.Facility); err != nil {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "syslog.facility",
+			Value:   config.Syslog.Facility,
+			Message: "must be a syslog facility name such as user, daemon or local0-local7",
+		})
+	}
+	if _, err := logging.ParseSyslogSeverity(config.Syslog.Severity); err != nil {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "syslog.severity",
+			Value:   config.Syslog.Severity,
+			Message: "must be one of emerg, alert, crit, err, warning, notice, info, debug",
+		})
+	}
+	if config.Syslog.Address != "" {
+		if err := logging.ValidateSyslogAddress(config.Syslog.Address); err != nil {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   "syslog.address",
+				Value:   config.Syslog.Address,
+				Message: err.Error(),
+			})
+		}
+	}
+}
+
 // validateConnectionPoolSettings validates connection pool configuration
 func validateConnectionPoolSettings(config *Config, result *ValidationResult) {
 	cp := &config.ConnectionPool
@@ -536,6 +1039,16 @@ func validateRetrySettings(config *Config, result *ValidationResult) {
 			"max retry delay is very high (>5min), which may cause very long delays")
 	}
 
+	// Validate MaxRetryAfter
+	if config.MaxRetryAfter < 0 {
+		result.Valid = false
+		result.Errors = append(result.Errors, ConfigValidationError{
+			Field:   "max_retry_after",
+			Value:   config.MaxRetryAfter,
+			Message: "max retry after cannot be negative",
+		})
+	}
+
 	// Validate BackoffFactor
 	if config.BackoffFactor < 1.0 {
 		result.Valid = false
@@ -583,4 +1096,47 @@ func validateRetrySettings(config *Config, result *ValidationResult) {
 		}
 		seen[lower] = true
 	}
-}
\ No newline at end of file
+}
+
+// validateScoring validates the proxy quality score weights
+func validateScoring(config *Config, result *ValidationResult) {
+	scoring := config.Scoring
+	fields := []struct {
+		name  string
+		value float64
+	}{
+		{"scoring.speed_weight", scoring.Speed},
+		{"scoring.anonymity_weight", scoring.Anonymity},
+		{"scoring.reliability_weight", scoring.Reliability},
+		{"scoring.tampering_penalty", scoring.TamperingPenalty},
+		{"scoring.vulnerability_penalty", scoring.VulnerabilityPenalty},
+	}
+	for _, field := range fields {
+		if field.value < 0 {
+			result.Valid = false
+			result.Errors = append(result.Errors, ConfigValidationError{
+				Field:   field.name,
+				Value:   field.value,
+				Message: "scoring weights and penalties cannot be negative",
+			})
+		}
+	}
+
+	if scoring != (proxy.ScoreWeights{}) && scoring.Speed+scoring.Anonymity+scoring.Reliability == 0 {
+		result.Warnings = append(result.Warnings,
+			"scoring speed, anonymity and reliability weights are all 0, every proxy will score 0")
+	}
+}
+
+// isSHA256Fingerprint reports whether s is 64 lowercase hex digits
+func isSHA256Fingerprint(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -1,8 +1,13 @@
 package errors
 
 import (
+	"context"
+	"crypto/tls"
+	stderrors "errors"
 	"fmt"
+	"net"
 	"strings"
+	"syscall"
 )
 
 // ErrorCode represents different types of errors that can occur
@@ -109,12 +114,24 @@ func (e *ProxyError) Unwrap() error {
 	return e.Cause
 }
 
-// Is implements error comparison for errors.Is()
+// Is implements error comparison for errors.Is(). Besides matching its own code,
+// an error also matches the sentinel for the network failure in its cause, so
+// errors.Is(result.Error, ErrTimeout) works on wrapped check failures.
 func (e *ProxyError) Is(target error) bool {
-	if pe, ok := target.(*ProxyError); ok {
-		return e.Code == pe.Code
+	pe, ok := target.(*ProxyError)
+	if !ok {
+		return false
 	}
-	return false
+	if e.Code == pe.Code {
+		return true
+	}
+
+	// Nested ProxyErrors are compared on their own as errors.Is unwraps the chain
+	var nested *ProxyError
+	if e.Cause == nil || stderrors.As(e.Cause, &nested) {
+		return false
+	}
+	return ClassifyCause(e.Cause) == pe.Code
 }
 
 // WithDetail adds a detail to the error
@@ -144,6 +161,60 @@ func (e *ProxyError) WithOperation(operation string) *ProxyError {
 	return e
 }
 
+// Sentinel errors for branching on check failures with errors.Is.
+// They match any ProxyError with the same code, or whose cause classifies to it.
+var (
+	ErrTimeout           = &ProxyError{Code: ErrorConnectionTimeout, Message: "timeout"}
+	ErrConnRefused       = &ProxyError{Code: ErrorConnectionRefused, Message: "connection refused"}
+	ErrDNSFailed         = &ProxyError{Code: ErrorDNSResolutionFailed, Message: "DNS resolution failed"}
+	ErrTLSFailed         = &ProxyError{Code: ErrorTLSHandshakeFailed, Message: "TLS handshake failed"}
+	ErrProxyAuthRequired = &ProxyError{Code: ErrorProxyAuthRequired, Message: "proxy authentication required"}
+	ErrInvalidURL        = &ProxyError{Code: ErrorProxyInvalidURL, Message: "invalid proxy URL"}
+	ErrNotWorking        = &ProxyError{Code: ErrorProxyNotWorking, Message: "proxy not working"}
+	ErrValidationFailed  = &ProxyError{Code: ErrorProxyValidationFailed, Message: "validation failed"}
+	ErrRateLimited       = &ProxyError{Code: ErrorProxyRateLimited, Message: "rate limited"}
+)
+
+// ClassifyCause maps a raw network error to the matching error code, or 0 if it
+// is not recognised. Some checker paths only keep the error text, so well-known
+// messages are matched as a fallback.
+func ClassifyCause(err error) ErrorCode {
+	if err == nil {
+		return 0
+	}
+
+	var netErr net.Error
+	var dnsErr *net.DNSError
+	var recordErr tls.RecordHeaderError
+	switch {
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return ErrorConnectionTimeout
+	case stderrors.As(err, &dnsErr):
+		return ErrorDNSResolutionFailed
+	case stderrors.As(err, &netErr) && netErr.Timeout():
+		return ErrorConnectionTimeout
+	case stderrors.Is(err, syscall.ECONNREFUSED):
+		return ErrorConnectionRefused
+	case stderrors.As(err, &recordErr):
+		return ErrorTLSHandshakeFailed
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "timeout") || strings.Contains(msg, "deadline exceeded"):
+		return ErrorConnectionTimeout
+	case strings.Contains(msg, "connection refused"):
+		return ErrorConnectionRefused
+	case strings.Contains(msg, "no such host"):
+		return ErrorDNSResolutionFailed
+	case strings.Contains(msg, "tls:") || strings.Contains(msg, "x509:"):
+		return ErrorTLSHandshakeFailed
+	case strings.Contains(msg, "proxy authentication required"):
+		return ErrorProxyAuthRequired
+	}
+	return 0
+}
+
 // Constructor functions for common error types
 
 // NewConfigError creates a configuration-related error
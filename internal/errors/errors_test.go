@@ -1,8 +1,10 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
 	"testing"
 )
 
@@ -427,4 +429,34 @@ func TestErrorWrapping(t *testing.T) {
 	if pe.Code != ErrorConnectionFailed {
 		t.Errorf("errors.As result code = %v, want %v", pe.Code, ErrorConnectionFailed)
 	}
-}
\ No newline at end of file
+}
+
+func TestSentinelErrors(t *testing.T) {
+	_, dialErr := net.Dial("tcp", "127.0.0.1:1")
+	if dialErr == nil {
+		t.Skip("expected connection to port 1 to be refused")
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		sentinel error
+		expected bool
+	}{
+		{"same code", NewProxyError(ErrorProxyValidationFailed, "validation failed", "http://proxy:8080", nil), ErrValidationFailed, true},
+		{"refused cause", NewProxyError(ErrorProxyNotWorking, "proxy check failed", "http://proxy:8080", dialErr), ErrConnRefused, true},
+		{"refused cause still not working", NewProxyError(ErrorProxyNotWorking, "proxy check failed", "http://proxy:8080", dialErr), ErrNotWorking, true},
+		{"deadline cause", NewProxyError(ErrorProxyNotWorking, "proxy check failed", "", context.DeadlineExceeded), ErrTimeout, true},
+		{"message fallback", NewProxyError(ErrorProxyNotWorking, "proxy check failed", "", fmt.Errorf("could not determine proxy type: dial tcp: lookup bad.invalid: no such host")), ErrDNSFailed, true},
+		{"nested error", NewProxyError(ErrorProxyValidationFailed, "validation failed", "", NewHTTPError(ErrorConnectionTimeout, "request failed", "", nil)), ErrTimeout, true},
+		{"unrelated cause", NewProxyError(ErrorProxyNotWorking, "proxy check failed", "", fmt.Errorf("unexpected EOF")), ErrTimeout, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, tt.sentinel); got != tt.expected {
+				t.Errorf("errors.Is(%v, %v) = %v, want %v", tt.err, tt.sentinel, got, tt.expected)
+			}
+		})
+	}
+}
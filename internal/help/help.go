@@ -116,7 +116,8 @@ func GetFullHelp(noColor bool) string {
 	
 	// Usage
 	fmt.Fprintf(b, "Usage:\n")
-	fmt.Fprintf(b, "  proxyhawk [flags]\n\n")
+	fmt.Fprintf(b, "  proxyhawk [flags]\n")
+	fmt.Fprintf(b, "  proxyhawk validate-list FILE > clean.txt\n\n")
 	
 	fmt.Fprintf(b, "Flags:\n")
 	
@@ -124,6 +125,11 @@ func GetFullHelp(noColor bool) string {
 	sectionHeader(b, "TARGET:", noColor)
 	w := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
 	fmt.Fprintf(w, "   -l string\ttarget proxy list file to scan (one proxy per line)\n")
+	fmt.Fprintf(w, "   -cidr string\tscan every address of a CIDR range for open proxies\n")
+	fmt.Fprintf(w, "   -port string\tport to check on each -cidr address\n")
+	fmt.Fprintf(w, "   -scheme string\tscheme for -cidr candidates (http, https, socks4, socks5)\n")
+	fmt.Fprintf(w, "   -force\tallow -cidr ranges larger than 65536 addresses\n")
+	fmt.Fprintf(w, "   -include-reserved\tkeep reserved addresses (loopback, multicast, ...) of the -cidr range\n")
 	fmt.Fprintf(w, "   -config string\tconfiguration file path (default \"config/default.yaml\")\n")
 	w.Flush()
 	fmt.Fprintln(b)
@@ -143,10 +149,52 @@ func GetFullHelp(noColor bool) string {
 	w = tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
 	fmt.Fprintf(w, "   -o string\tfile to save text results\n")
 	fmt.Fprintf(w, "   -j string\tfile to save JSON results\n")
+	fmt.Fprintf(w, "   -json-sorted\tsort JSON results by proxy URL for stable diffs\n")
+	fmt.Fprintf(w, "   -sort string\torder output files by: score (best first), connect (fastest proxy connect first)\n")
+	fmt.Fprintf(w, "   -class string\tonly output proxies of these classes (datacenter, residential, mobile, unknown)\n")
+	fmt.Fprintf(w, "   -category-check\treport which site categories each working proxy can reach\n")
+	fmt.Fprintf(w, "   -check-reputation\tlook up each working proxy's exit IP on DNS blocklists and an abuse-score API\n")
+	fmt.Fprintf(w, "   -echo-headers\trecord the headers the target received through each working proxy\n")
+	fmt.Fprintf(w, "   -measure-throughput\tdownload a payload through each working proxy and report MB/s\n")
+	fmt.Fprintf(w, "   -inspect-tls\trecord HTTPS target certificates and flag proxies that intercept TLS\n")
+	fmt.Fprintf(w, "   -verify-tls\tverify target certificates during checks (off by default)\n")
+	fmt.Fprintf(w, "   -resolver string\tDNS server (host:port) or DoH URL for hostname lookups\n")
+	fmt.Fprintf(w, "   -detect-rotation\tsample each working proxy's exit IP to detect rotating pools\n")
+	fmt.Fprintf(w, "   -egress-ptr\trecord each working proxy's egress IP and its reverse DNS (PTR) name\n")
+	fmt.Fprintf(w, "   -websocket\tverify each working proxy can carry a WebSocket connection\n")
+	fmt.Fprintf(w, "   -suspicious-check\tscore each working proxy for honeypot-like behavior\n")
 	fmt.Fprintf(w, "   -wp string\tfile to save only working proxies\n")
+	fmt.Fprintf(w, "   -csv string\tfile to save CSV results\n")
+	fmt.Fprintf(w, "   -csv-columns string\tcomma-separated CSV columns (e.g. proxy,type,speed,anon)\n")
+	fmt.Fprintf(w, "   -include-timing-in-csv\tadd timing breakdown columns to CSV output\n")
+	fmt.Fprintf(w, "   -jsonl string\tfile to stream results to as JSON lines while checking\n")
+	fmt.Fprintf(w, "   -flush-interval duration\twrite streamed results out this often instead of per result\n")
+	fmt.Fprintf(w, "   -fsync\tfsync streamed output on every write (survives OS crashes, slower)\n")
+	fmt.Fprintf(w, "   -html string\tfile to save a self-contained HTML report\n")
+	fmt.Fprintf(w, "   -binary-out string\tfile to save results in a compact binary format\n")
+	fmt.Fprintf(w, "   -binary-in string\twrite results saved with -binary-out to the output files instead of checking\n")
+	fmt.Fprintf(w, "   -preserve-input\twrite proxies to output files as written in the input list\n")
+	fmt.Fprintf(w, "   -warnings-json string\tfile to save loader and config warnings as JSON\n")
+	fmt.Fprintf(w, "   -syslog\tsend per-proxy results and the summary to syslog (RFC 5424)\n")
+	fmt.Fprintf(w, "   -syslog-addr string\tsyslog daemon: udp://host:514, tcp://host:514 or unix:///path (default: local socket)\n")
+	fmt.Fprintf(w, "   -syslog-facility string\tsyslog facility, e.g. user, daemon, local0\n")
+	fmt.Fprintf(w, "   -syslog-severity string\tseverity of result and summary messages, e.g. info, notice\n")
 	fmt.Fprintf(w, "   -v\tenable verbose output\n")
 	fmt.Fprintf(w, "   -d\tenable debug mode with detailed logs\n")
 	fmt.Fprintf(w, "   -no-ui\tdisable terminal UI (for automation/scripting)\n")
+	fmt.Fprintf(w, "   -progress string\tprogress with -no-ui: none, basic, bar, spinner, dots, percent, line\n")
+	fmt.Fprintf(w, "   -max-runtime duration\tstop the run after this long and write results so far (e.g. 10m)\n")
+	fmt.Fprintf(w, "   -max-idle duration\tstop the run if no check completes for this long (e.g. 2m)\n")
+	fmt.Fprintf(w, "   -debug-lines int\tdebug log lines kept for scrolling in the TUI (default 1000)\n")
+	fmt.Fprintf(w, "   -max-goroutines int\twarn when more goroutines than this are running (guardrail)\n")
+	fmt.Fprintf(w, "   -fail-fast\texit with status 1 if any proxy is not working (CI gate)\n")
+	fmt.Fprintf(w, "   -ssh-tunnel string\tcheck proxies through an SSH bastion (user@host[:port], key auth)\n")
+	fmt.Fprintf(w, "   -proxy-chain string\treach every proxy through this jump proxy (e.g. socks5://jump:1080)\n")
+	fmt.Fprintf(w, "   -keep-warm duration\tkeep connections to working proxies alive after the run (e.g. 5m)\n")
+	fmt.Fprintf(w, "   -follow-redirects\tfollow redirects of validation requests, aborting redirect loops\n")
+	fmt.Fprintf(w, "   -6\tvalidate against the IPv6 address of the validation host (IPv6 targets)\n")
+	fmt.Fprintf(w, "   -detection-order string\tproxy types to try in order when detecting a type (e.g. socks5,http)\n")
+	fmt.Fprintf(w, "   -checkpoint string\tfile recording checked proxies so an interrupted scan can resume\n")
 	w.Flush()
 	fmt.Fprintln(b)
 	
@@ -208,6 +256,11 @@ func GetExamples() []Example {
 			Command:     "proxyhawk -l proxies.txt -wpa anonymous-only.txt --no-ui",
 			Explanation: "Saves only proxies that hide your real IP",
 		},
+		{
+			Description: "Clean a proxy list before checking it",
+			Command:     "proxyhawk validate-list proxies.txt > clean.txt",
+			Explanation: "Reports malformed, duplicate and reserved entries by line without network access",
+		},
 		{
 			Description: "Production deployment with metrics",
 			Command:     "proxyhawk -l proxies.txt --metrics --metrics-addr :9090 -c 100",
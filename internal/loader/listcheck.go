@@ -0,0 +1,170 @@
+package loader
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/validation"
+)
+
+// Kinds of issues reported by CheckList
+const (
+	IssueMalformed   = "malformed"    // The proxy could not be parsed or validated; dropped
+	IssueDuplicate   = "duplicate"    // Same proxy as an earlier line; dropped
+	IssueReserved    = "reserved"     // Loopback, link-local, multicast or other reserved address; dropped
+	IssuePrivate     = "private"      // Private (RFC 1918 / ULA) address; kept
+	IssueDefaultPort = "default_port" // No port given; the scheme's default port was added
+	IssueOption      = "option"       // An option after the proxy was ignored; the proxy is kept
+)
+
+// defaultPorts are the ports written into proxies listed without one
+var defaultPorts = map[string]string{
+	"http":    "80",
+	"https":   "443",
+	"socks4":  "1080",
+	"socks4a": "1080",
+	"socks5":  "1080",
+}
+
+// ListIssue is a problem found on one line of a proxy list
+type ListIssue struct {
+	Line    int
+	Kind    string
+	Message string
+}
+
+// ListReport is the result of checking a proxy list with CheckList
+type ListReport struct {
+	Lines   int            // Lines read, including blank lines and comments
+	Entries []ProxyEntry   // Cleaned list: valid, non-reserved proxies in order, without duplicates
+	Issues  []ListIssue    // Problems found, in line order
+	Schemes map[string]int // Number of cleaned entries per scheme
+}
+
+// MixedSchemes reports whether the cleaned list holds more than one scheme
+func (r *ListReport) MixedSchemes() bool {
+	return len(r.Schemes) > 1
+}
+
+// SchemeSummary formats the per-scheme counts of report, e.g. "http=3, socks5=1"
+func (r *ListReport) SchemeSummary() string {
+	schemes := make([]string, 0, len(r.Schemes))
+	for scheme := range r.Schemes {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+
+	parts := make([]string, len(schemes))
+	for i, scheme := range schemes {
+		parts[i] = fmt.Sprintf("%s=%d", scheme, r.Schemes[scheme])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// CheckList checks the hygiene of a proxy list without any network access.
+// Lines are parsed and normalized like LoadProxies does, every proxy gets an
+// explicit port, and duplicates, malformed entries and reserved addresses are
+// reported and left out of the returned entries. Private addresses are
+// reported but kept, since internal proxies are a common target. Hostnames are
+// not resolved.
+func CheckList(r io.Reader) (*ListReport, error) {
+	validator := validation.NewProxyValidator()
+	report := &ListReport{Schemes: make(map[string]int)}
+	seen := make(map[string]int)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		report.Lines++
+		lineNum := report.Lines
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		addIssue := func(kind, format string, args ...interface{}) {
+			report.Issues = append(report.Issues, ListIssue{Line: lineNum, Kind: kind, Message: fmt.Sprintf(format, args...)})
+		}
+
+		entry, optionErrs, err := parseLine(line, validator)
+		if err != nil {
+			// The validator rejects loopback and multicast addresses; report them as reserved
+			proxy := strings.Fields(line)[0]
+			if isReservedHost(proxy) {
+				addIssue(IssueReserved, "%s is a reserved address", proxy)
+			} else {
+				addIssue(IssueMalformed, "%s: %v", proxy, err)
+			}
+			continue
+		}
+		for _, optionErr := range optionErrs {
+			addIssue(IssueOption, "%s: %v", entry.Input, optionErr)
+		}
+
+		parsed, err := url.Parse(entry.URL)
+		if err != nil {
+			addIssue(IssueMalformed, "%s: %v", entry.Input, err)
+			continue
+		}
+		parsed.Host = strings.ToLower(parsed.Host)
+		if parsed.Port() == "" {
+			if port, ok := defaultPorts[parsed.Scheme]; ok {
+				parsed.Host = net.JoinHostPort(parsed.Hostname(), port)
+				addIssue(IssueDefaultPort, "%s has no port, using %s", entry.Input, port)
+			}
+		}
+		entry.URL = parsed.String()
+
+		if isReservedHost(entry.URL) {
+			addIssue(IssueReserved, "%s is a reserved address", entry.Input)
+			continue
+		}
+		if ip := net.ParseIP(parsed.Hostname()); ip != nil && ip.IsPrivate() {
+			addIssue(IssuePrivate, "%s is a private address", entry.Input)
+		}
+
+		if first, ok := seen[entry.URL]; ok {
+			addIssue(IssueDuplicate, "%s duplicates line %d", entry.Input, first)
+			continue
+		}
+		seen[entry.URL] = lineNum
+
+		report.Entries = append(report.Entries, entry)
+		report.Schemes[parsed.Scheme]++
+	}
+	if err := scanner.Err(); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// isReservedHost reports whether proxy, with or without a scheme, points at a
+// reserved address
+func isReservedHost(proxy string) bool {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	parsed, err := url.Parse(proxy)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(parsed.Hostname())
+	return ip != nil && IsReservedIP(ip)
+}
+
+// FormatEntry writes entry as a proxy list line, keeping its options
+func FormatEntry(entry ProxyEntry) string {
+	line := entry.URL
+	if entry.Timeout > 0 {
+		line += " timeout=" + entry.Timeout.String()
+	}
+	if entry.ExpectCountry != "" {
+		line += " expect_country=" + entry.ExpectCountry
+	}
+	return line
+}
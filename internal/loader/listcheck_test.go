@@ -0,0 +1,74 @@
+package loader
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckList(t *testing.T) {
+	list := strings.Join([]string{
+		"# comment",
+		"1.2.3.4:8080 timeout=30s",
+		"http://1.2.3.4:8080",
+		"HTTP://EXAMPLE.com",
+		"example.com:80",
+		"socks5://5.6.7.8",
+		"127.0.0.1:3128",
+		"http://[fe80::1]:8080",
+		"10.0.0.1:3128 expect_country=us",
+		"ftp://1.2.3.4:21",
+		"",
+		"9.9.9.9:3128 colour=blue",
+	}, "\n")
+
+	report, err := CheckList(strings.NewReader(list))
+	if err != nil {
+		t.Fatalf("CheckList failed: %v", err)
+	}
+
+	var lines []string
+	for _, entry := range report.Entries {
+		lines = append(lines, FormatEntry(entry))
+	}
+	want := []string{
+		"http://1.2.3.4:8080 timeout=30s",
+		"http://example.com:80",
+		"socks5://5.6.7.8:1080",
+		"http://10.0.0.1:3128 expect_country=US",
+		"http://9.9.9.9:3128",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected cleaned list:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(lines, "\n"))
+	}
+	if report.Entries[0].Timeout != 30*time.Second {
+		t.Errorf("Expected the timeout option to be kept, got %v", report.Entries[0].Timeout)
+	}
+
+	wantIssues := []struct {
+		line int
+		kind string
+	}{
+		{3, IssueDuplicate},
+		{4, IssueDefaultPort},
+		{5, IssueDuplicate},
+		{6, IssueDefaultPort},
+		{7, IssueReserved},
+		{8, IssueReserved},
+		{9, IssuePrivate},
+		{10, IssueMalformed},
+		{12, IssueOption},
+	}
+	if len(report.Issues) != len(wantIssues) {
+		t.Fatalf("Expected %d issues, got %+v", len(wantIssues), report.Issues)
+	}
+	for i, want := range wantIssues {
+		if got := report.Issues[i]; got.Line != want.line || got.Kind != want.kind {
+			t.Errorf("Issue %d: expected line %d %s, got line %d %s (%s)", i, want.line, want.kind, got.Line, got.Kind, got.Message)
+		}
+	}
+
+	if report.Lines != 12 || !report.MixedSchemes() || report.SchemeSummary() != "http=4, socks5=1" {
+		t.Errorf("Unexpected summary: %d lines, schemes %s", report.Lines, report.SchemeSummary())
+	}
+}
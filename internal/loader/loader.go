@@ -5,18 +5,41 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/ResistanceIsUseless/ProxyHawk/internal/errors"
 	"github.com/ResistanceIsUseless/ProxyHawk/internal/validation"
 )
 
+// ProxyEntry is a proxy loaded from a list file along with its per-proxy options
+type ProxyEntry struct {
+	URL     string
+	Input   string        // Proxy exactly as written in the list, before normalization
+	Timeout time.Duration // Per-proxy timeout from a "timeout=" suffix (0 = use the configured default)
+
+	// ExpectCountry is the ISO country code the proxy should egress from, from an "expect_country=" suffix
+	ExpectCountry string
+}
+
+// URLs returns the proxy URLs of entries in order
+func URLs(entries []ProxyEntry) []string {
+	urls := make([]string, len(entries))
+	for i, entry := range entries {
+		urls[i] = entry.URL
+	}
+	return urls
+}
+
 // LoadProxies loads and validates proxy addresses from a file using default validation
-func LoadProxies(filename string) ([]string, []string, error) {
+func LoadProxies(filename string) ([]ProxyEntry, []string, error) {
 	return LoadProxiesWithValidator(filename, validation.NewProxyValidator())
 }
 
-// LoadProxiesWithValidator loads and validates proxy addresses with a custom validator
-func LoadProxiesWithValidator(filename string, validator *validation.ProxyValidator) ([]string, []string, error) {
+// LoadProxiesWithValidator loads and validates proxy addresses with a custom validator.
+// Each line holds a proxy URL optionally followed by key=value options; the
+// options recognised are timeout and expect_country, e.g.
+// "http://1.2.3.4:8080 timeout=30s expect_country=US".
+func LoadProxiesWithValidator(filename string, validator *validation.ProxyValidator) ([]ProxyEntry, []string, error) {
 	// Check if file exists
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
 		return nil, nil, errors.NewFileError(errors.ErrorFileNotFound, "proxy file not found", filename, err)
@@ -28,7 +51,7 @@ func LoadProxiesWithValidator(filename string, validator *validation.ProxyValida
 	}
 	defer file.Close()
 
-	var proxies []string
+	var proxies []ProxyEntry
 	var warnings []string
 	lineCount := 0
 	scanner := bufio.NewScanner(file)
@@ -42,26 +65,16 @@ func LoadProxiesWithValidator(filename string, validator *validation.ProxyValida
 			continue
 		}
 
-		// Extract proxy URL (first field if there are multiple)
-		proxy := strings.Fields(line)[0]
-		if proxy == "" {
-			continue
-		}
-
-		// Normalize the proxy URL
-		normalizedProxy, err := validator.NormalizeProxyURL(proxy)
+		entry, optionErrs, err := parseLine(line, validator)
 		if err != nil {
 			warnings = append(warnings, fmt.Sprintf("Line %d: %v", lineCount, err))
 			continue
 		}
-
-		// Validate the normalized proxy
-		if err := validator.ValidateProxyURL(normalizedProxy); err != nil {
-			warnings = append(warnings, fmt.Sprintf("Line %d: %v", lineCount, err))
-			continue
+		for _, optionErr := range optionErrs {
+			warnings = append(warnings, fmt.Sprintf("Line %d: %v", lineCount, optionErr))
 		}
 
-		proxies = append(proxies, normalizedProxy)
+		proxies = append(proxies, entry)
 	}
 
 	// Check for scanner errors
@@ -82,3 +95,73 @@ func LoadProxiesWithValidator(filename string, validator *validation.ProxyValida
 
 	return proxies, warnings, nil
 }
+
+// parseLine parses a non-empty, non-comment line of a proxy list: the proxy
+// URL (first field), normalized and validated, followed by any options. It
+// returns an error when the proxy itself is invalid and the errors of options
+// that were ignored.
+func parseLine(line string, validator *validation.ProxyValidator) (ProxyEntry, []error, error) {
+	fields := strings.Fields(line)
+	proxy := fields[0]
+
+	// Normalize the proxy URL
+	normalizedProxy, err := validator.NormalizeProxyURL(proxy)
+	if err != nil {
+		return ProxyEntry{}, nil, err
+	}
+
+	// Validate the normalized proxy
+	if err := validator.ValidateProxyURL(normalizedProxy); err != nil {
+		return ProxyEntry{}, nil, err
+	}
+
+	// Other trailing fields (e.g. country columns from proxy lists) are ignored as before
+	entry := ProxyEntry{URL: normalizedProxy, Input: proxy}
+	var optionErrs []error
+	for _, option := range fields[1:] {
+		if !strings.Contains(option, "=") {
+			continue
+		}
+		if err := parseOption(&entry, option); err != nil {
+			optionErrs = append(optionErrs, err)
+		}
+	}
+
+	return entry, optionErrs, nil
+}
+
+// parseOption applies a key=value option from a proxy line to entry
+func parseOption(entry *ProxyEntry, option string) error {
+	key, value, _ := strings.Cut(option, "=")
+
+	switch strings.ToLower(key) {
+	case "timeout":
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("ignoring invalid timeout %q, using the default", value)
+		}
+		entry.Timeout = timeout
+	case "expect_country":
+		if !isCountryCode(value) {
+			return fmt.Errorf("ignoring invalid expect_country %q, expected a two-letter country code", value)
+		}
+		entry.ExpectCountry = strings.ToUpper(value)
+	default:
+		return fmt.Errorf("ignoring unknown option %q", key)
+	}
+
+	return nil
+}
+
+// isCountryCode reports whether s is a two-letter ISO 3166 country code
+func isCountryCode(s string) bool {
+	if len(s) != 2 {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package loader
+
+import "net"
+
+// reservedRanges are address blocks that never hold a reachable proxy.
+// Private (RFC 1918) ranges are not listed, since scanning internal networks
+// is a common use.
+var reservedRanges = mustParseCIDRs(
+	"0.0.0.0/8",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets
+}
+
+// IsReservedIP reports whether ip is a loopback, link-local, multicast,
+// unspecified or otherwise reserved address that cannot hold a proxy
+func IsReservedIP(ip net.IP) bool {
+	for _, ipNet := range reservedRanges {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package loader
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIsReservedIP(t *testing.T) {
+	tests := []struct {
+		ip       string
+		reserved bool
+	}{
+		{"0.0.0.1", true},
+		{"127.0.0.1", true},
+		{"169.254.1.1", true},
+		{"224.0.0.1", true},
+		{"240.0.0.1", true},
+		{"::1", true},
+		{"fe80::1", true},
+		{"10.0.0.1", false},
+		{"192.168.1.1", false},
+		{"8.8.8.8", false},
+		{"2001:db8::1", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsReservedIP(net.ParseIP(tt.ip)); got != tt.reserved {
+			t.Errorf("IsReservedIP(%s) = %t, want %t", tt.ip, got, tt.reserved)
+		}
+	}
+}
+
+func TestIsReservedHost(t *testing.T) {
+	for _, proxy := range []string{"0.0.0.1:8080", "http://240.0.0.1:3128", "socks5://[::1]:1080"} {
+		if !isReservedHost(proxy) {
+			t.Errorf("isReservedHost(%q) = false, want true", proxy)
+		}
+	}
+	if isReservedHost("http://example.com:8080") {
+		t.Error("isReservedHost() = true for a hostname, want false")
+	}
+}
@@ -26,6 +26,9 @@ type Config struct {
 	Level  LogLevel
 	Format string // "json" or "text"
 	Output io.Writer
+	// Handler, when set, receives the records instead of a Format handler
+	// writing to Output; it filters levels itself
+	Handler slog.Handler
 }
 
 // NewLogger creates a new structured logger
@@ -54,7 +57,9 @@ func NewLogger(config Config) *Logger {
 		Level: level,
 	}
 
-	if config.Format == "json" {
+	if config.Handler != nil {
+		handler = config.Handler
+	} else if config.Format == "json" {
 		handler = slog.NewJSONHandler(output, opts)
 	} else {
 		handler = slog.NewTextHandler(output, opts)
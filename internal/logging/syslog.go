@@ -0,0 +1,319 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// syslogVersion is the RFC 5424 protocol version
+	syslogVersion = 1
+	// syslogSDID names the structured data element carrying record attributes;
+	// 32473 is the example enterprise number reserved by RFC 5612
+	syslogSDID = "proxyhawk@32473"
+	// syslogDialTimeout bounds connecting to a remote syslog daemon
+	syslogDialTimeout = 5 * time.Second
+)
+
+// localSyslogSockets are tried in order when no syslog address is given
+var localSyslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// syslogFacilities maps facility names to their RFC 5424 codes
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSeverities maps severity names to their RFC 5424 codes
+var syslogSeverities = map[string]int{
+	"emerg": 0, "alert": 1, "crit": 2, "err": 3,
+	"warning": 4, "notice": 5, "info": 6, "debug": 7,
+}
+
+// ParseSyslogFacility returns the code of a facility name such as "user" or
+// "local0"; an empty name is "user"
+func ParseSyslogFacility(name string) (int, error) {
+	if name == "" {
+		return syslogFacilities["user"], nil
+	}
+	code, ok := syslogFacilities[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown syslog facility %q", name)
+	}
+	return code, nil
+}
+
+// ParseSyslogSeverity returns the code of a severity name such as "info" or
+// "notice"; an empty name is "info"
+func ParseSyslogSeverity(name string) (int, error) {
+	if name == "" {
+		return syslogSeverities["info"], nil
+	}
+	code, ok := syslogSeverities[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown syslog severity %q", name)
+	}
+	return code, nil
+}
+
+// SyslogConfig configures a SyslogHandler
+type SyslogConfig struct {
+	// Address of the syslog daemon: udp://host:port, tcp://host:port,
+	// unix:///path or host:port (UDP). Empty = the local syslog socket.
+	Address  string
+	Facility int    // RFC 5424 facility code, see ParseSyslogFacility
+	Severity int    // Severity of debug and info records, see ParseSyslogSeverity
+	AppName  string // APP-NAME field (default: proxyhawk)
+}
+
+// SyslogHandler is an slog.Handler that sends records to a syslog daemon as
+// RFC 5424 messages. Record attributes go into a structured data element.
+// Debug and info records carry the configured severity; warnings and errors
+// keep the warning and err severities so they stand out. Messages over TCP are
+// framed with octet counting (RFC 6587).
+type SyslogHandler struct {
+	sink   *syslogSink
+	attrs  []slog.Attr
+	prefix string // Group prefix for attribute names
+}
+
+// syslogSink is the connection shared by a handler and its derived handlers
+type syslogSink struct {
+	config   SyslogConfig
+	network  string
+	address  string
+	hostname string
+	mutex    sync.Mutex
+	conn     net.Conn
+}
+
+// NewSyslogHandler connects to the configured syslog daemon
+func NewSyslogHandler(config SyslogConfig) (*SyslogHandler, error) {
+	if config.AppName == "" {
+		config.AppName = "proxyhawk"
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	sink := &syslogSink{config: config, hostname: hostname}
+	sink.network, sink.address, err = parseSyslogAddress(config.Address)
+	if err != nil {
+		return nil, err
+	}
+	if err := sink.connect(); err != nil {
+		return nil, err
+	}
+	return &SyslogHandler{sink: sink}, nil
+}
+
+// ValidateSyslogAddress reports whether address is a usable syslog address
+func ValidateSyslogAddress(address string) error {
+	_, _, err := parseSyslogAddress(address)
+	return err
+}
+
+// parseSyslogAddress splits a syslog address into network and address; an
+// empty network means the local socket
+func parseSyslogAddress(address string) (string, string, error) {
+	switch {
+	case address == "":
+		return "", "", nil
+	case strings.HasPrefix(address, "udp://"):
+		return "udp", strings.TrimPrefix(address, "udp://"), nil
+	case strings.HasPrefix(address, "tcp://"):
+		return "tcp", strings.TrimPrefix(address, "tcp://"), nil
+	case strings.HasPrefix(address, "unix://"):
+		return "unixgram", strings.TrimPrefix(address, "unix://"), nil
+	case strings.Contains(address, "://"):
+		return "", "", fmt.Errorf("unsupported syslog address %q: use udp://, tcp:// or unix://", address)
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return "", "", fmt.Errorf("invalid syslog address %q: %w", address, err)
+	}
+	return "udp", address, nil
+}
+
+// connect dials the syslog daemon; the caller holds the mutex or owns the sink
+func (s *syslogSink) connect() error {
+	if s.network != "" {
+		conn, err := net.DialTimeout(s.network, s.address, syslogDialTimeout)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog at %s: %w", s.address, err)
+		}
+		s.conn = conn
+		return nil
+	}
+
+	var lastErr error
+	for _, socket := range localSyslogSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, socket)
+			if err == nil {
+				s.conn = conn
+				return nil
+			}
+			lastErr = err
+		}
+	}
+	return fmt.Errorf("failed to connect to the local syslog daemon: %w", lastErr)
+}
+
+// write sends one message, reconnecting once if the connection was lost
+func (s *syslogSink) write(message string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.conn != nil {
+		if err := s.send(message); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+	if err := s.connect(); err != nil {
+		return err
+	}
+	return s.send(message)
+}
+
+// send writes message to the current connection with the framing its network needs
+func (s *syslogSink) send(message string) error {
+	if s.network == "tcp" {
+		message = fmt.Sprintf("%d %s", len(message), message)
+	}
+	_, err := s.conn.Write([]byte(message))
+	return err
+}
+
+// Close closes the connection to the syslog daemon
+func (h *SyslogHandler) Close() error {
+	h.sink.mutex.Lock()
+	defer h.sink.mutex.Unlock()
+	if h.sink.conn == nil {
+		return nil
+	}
+	err := h.sink.conn.Close()
+	h.sink.conn = nil
+	return err
+}
+
+// Enabled implements slog.Handler; every level is sent
+func (h *SyslogHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle implements slog.Handler
+func (h *SyslogHandler) Handle(_ context.Context, record slog.Record) error {
+	return h.sink.write(h.format(record))
+}
+
+// WithAttrs implements slog.Handler
+func (h *SyslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	derived := *h
+	derived.attrs = append(append([]slog.Attr(nil), h.attrs...), prefixAttrs(h.prefix, attrs)...)
+	return &derived
+}
+
+// WithGroup implements slog.Handler
+func (h *SyslogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	derived := *h
+	derived.prefix = h.prefix + name + "."
+	return &derived
+}
+
+// prefixAttrs returns attrs with their keys prefixed by the group prefix
+func prefixAttrs(prefix string, attrs []slog.Attr) []slog.Attr {
+	if prefix == "" {
+		return attrs
+	}
+	prefixed := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		prefixed[i] = slog.Attr{Key: prefix + attr.Key, Value: attr.Value}
+	}
+	return prefixed
+}
+
+// format renders record as an RFC 5424 message:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG
+func (h *SyslogHandler) format(record slog.Record) string {
+	severity := h.sink.config.Severity
+	switch {
+	case record.Level >= slog.LevelError:
+		severity = syslogSeverities["err"]
+	case record.Level >= slog.LevelWarn:
+		severity = syslogSeverities["warning"]
+	}
+
+	timestamp := record.Time
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	attrs := append([]slog.Attr(nil), h.attrs...)
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, prefixAttrs(h.prefix, []slog.Attr{attr})...)
+		return true
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>%d %s %s %s %d - ",
+		h.sink.config.Facility*8+severity, syslogVersion,
+		timestamp.UTC().Format(time.RFC3339Nano), h.sink.hostname, h.sink.config.AppName, os.Getpid())
+	if len(attrs) == 0 {
+		b.WriteString("-")
+	} else {
+		b.WriteString("[" + syslogSDID)
+		writeSDParams(&b, "", attrs)
+		b.WriteString("]")
+	}
+	b.WriteString(" " + record.Message)
+	return b.String()
+}
+
+// writeSDParams writes attrs as structured data parameters, flattening groups
+func writeSDParams(b *strings.Builder, prefix string, attrs []slog.Attr) {
+	for _, attr := range attrs {
+		value := attr.Value.Resolve()
+		if value.Kind() == slog.KindGroup {
+			writeSDParams(b, prefix+attr.Key+".", value.Group())
+			continue
+		}
+		fmt.Fprintf(b, " %s=\"%s\"", sdParamName(prefix+attr.Key), sdEscape(value.String()))
+	}
+}
+
+// sdParamName makes key a valid SD-NAME: up to 32 printable ASCII characters
+// other than '=', ' ', ']' and '"'
+func sdParamName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	if name == "" {
+		name = "_"
+	}
+	return name
+}
+
+// sdEscape escapes '"', '\' and ']' in a PARAM-VALUE
+func sdEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
@@ -0,0 +1,134 @@
+package logging
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogHandlerUDP(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	defer listener.Close()
+
+	handler, err := NewSyslogHandler(SyslogConfig{
+		Address:  "udp://" + listener.LocalAddr().String(),
+		Facility: 16, // local0
+		Severity: 5,  // notice
+	})
+	if err != nil {
+		t.Fatalf("NewSyslogHandler() error = %v", err)
+	}
+	defer handler.Close()
+
+	logger := NewLogger(Config{Handler: handler})
+	read := func() string {
+		buf := make([]byte, 4096)
+		listener.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := listener.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("ReadFrom() error = %v", err)
+		}
+		return string(buf[:n])
+	}
+
+	logger.ProxySuccess("http://192.0.2.1:8080", 0.25, true, "")
+	message := read()
+	pattern := regexp.MustCompile(`^<133>1 \S+Z \S+ proxyhawk \d+ - \[proxyhawk@32473 proxy="http://192.0.2.1:8080" duration_seconds="0.25" anonymous="true"\] Proxy check successful$`)
+	if !pattern.MatchString(message) {
+		t.Errorf("success message = %q, want local0.notice with structured data", message)
+	}
+
+	logger.ProxyFailure("http://192.0.2.2:8080", errors.New(`bad "reply"]`))
+	message = read()
+	if !strings.HasPrefix(message, "<131>1 ") {
+		t.Errorf("failure message = %q, want local0.err priority <131>", message)
+	}
+	if !strings.Contains(message, `error="bad \"reply\"\]"`) {
+		t.Errorf("failure message = %q, want escaped error value", message)
+	}
+
+	logger.Info("plain")
+	if message = read(); !strings.HasSuffix(message, " - - plain") {
+		t.Errorf("message without attributes = %q, want nil structured data", message)
+	}
+}
+
+func TestSyslogHandlerTCPFraming(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+
+	handler, err := NewSyslogHandler(SyslogConfig{Address: "tcp://" + listener.Addr().String(), Facility: 1, Severity: 6})
+	if err != nil {
+		t.Fatalf("NewSyslogHandler() error = %v", err)
+	}
+	defer handler.Close()
+
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("Accept() error = %v", err)
+	}
+	defer conn.Close()
+
+	NewLogger(Config{Handler: handler}).SummaryStats(10, 4, 2, 40)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	reader := bufio.NewReader(conn)
+	length, err := reader.ReadString(' ')
+	if err != nil {
+		t.Fatalf("reading frame length: %v", err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(length))
+	if err != nil {
+		t.Fatalf("frame length %q is not a number", length)
+	}
+	frame := make([]byte, n)
+	if _, err := io.ReadFull(reader, frame); err != nil {
+		t.Fatalf("reading frame: %v", err)
+	}
+	if !strings.HasPrefix(string(frame), "<14>1 ") || !strings.Contains(string(frame), `working_proxies="4"`) {
+		t.Errorf("frame = %q, want user.info summary", frame)
+	}
+}
+
+func TestParseSyslogNames(t *testing.T) {
+	if code, err := ParseSyslogFacility("LOCAL3"); err != nil || code != 19 {
+		t.Errorf("ParseSyslogFacility(LOCAL3) = %d, %v, want 19", code, err)
+	}
+	if code, err := ParseSyslogFacility(""); err != nil || code != 1 {
+		t.Errorf("ParseSyslogFacility(\"\") = %d, %v, want user (1)", code, err)
+	}
+	if _, err := ParseSyslogFacility("local8"); err == nil {
+		t.Error("ParseSyslogFacility(local8) succeeded, want error")
+	}
+	if code, err := ParseSyslogSeverity("warning"); err != nil || code != 4 {
+		t.Errorf("ParseSyslogSeverity(warning) = %d, %v, want 4", code, err)
+	}
+	if _, err := ParseSyslogSeverity("loud"); err == nil {
+		t.Error("ParseSyslogSeverity(loud) succeeded, want error")
+	}
+}
+
+func TestValidateSyslogAddress(t *testing.T) {
+	for _, address := range []string{"udp://logs:514", "tcp://10.0.0.5:6514", "unix:///dev/log", "logs.example.com:514"} {
+		if err := ValidateSyslogAddress(address); err != nil {
+			t.Errorf("ValidateSyslogAddress(%q) error = %v", address, err)
+		}
+	}
+	for _, address := range []string{"http://logs:514", "logs.example.com"} {
+		if err := ValidateSyslogAddress(address); err == nil {
+			t.Errorf("ValidateSyslogAddress(%q) succeeded, want error", address)
+		}
+	}
+}
@@ -22,13 +22,14 @@ type Collector struct {
 	checksErrors     prometheus.Counter
 
 	// Histograms
-	checkDuration prometheus.Histogram
+	checkDuration *prometheus.HistogramVec
 	responseTime  prometheus.Histogram
 
 	// Gauges
 	activeChecks  prometheus.Gauge
 	queueSize     prometheus.Gauge
 	workersActive prometheus.Gauge
+	goroutines    prometheus.Gauge
 
 	// Labels
 	checksPerType     *prometheus.CounterVec
@@ -86,11 +87,14 @@ func (c *Collector) initMetrics() {
 	})
 
 	// Histograms
-	c.checkDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
-		Name:    "proxyhawk_check_duration_seconds",
-		Help:    "Duration of proxy checks in seconds",
-		Buckets: prometheus.DefBuckets,
-	})
+	c.checkDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "proxyhawk_check_duration_seconds",
+			Help:    "Duration of proxy checks in seconds",
+			Buckets: []float64{0.1, 0.25, 0.5, 1, 2, 5, 10},
+		},
+		[]string{"proxy_type", "outcome"},
+	)
 
 	c.responseTime = prometheus.NewHistogram(prometheus.HistogramOpts{
 		Name:    "proxyhawk_response_time_seconds",
@@ -114,6 +118,11 @@ func (c *Collector) initMetrics() {
 		Help: "Number of active worker goroutines",
 	})
 
+	c.goroutines = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "proxyhawk_goroutines",
+		Help: "Number of goroutines in the process",
+	})
+
 	// Counter vectors with labels
 	c.checksPerType = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -154,6 +163,7 @@ func (c *Collector) registerMetrics() {
 		c.activeChecks,
 		c.queueSize,
 		c.workersActive,
+		c.goroutines,
 		c.checksPerType,
 		c.checksPerProvider,
 		c.errorsPerType,
@@ -212,10 +222,10 @@ func (c *Collector) StopServer() error {
 
 // Metrics recording methods
 
-// RecordProxyCheck records a completed proxy check
-func (c *Collector) RecordProxyCheck(working bool, proxyType string, duration time.Duration) {
+// RecordProxyCheck records a completed proxy check. The check duration is
+// observed separately by ObserveCheckDuration.
+func (c *Collector) RecordProxyCheck(working bool, proxyType string) {
 	c.proxiesChecked.Inc()
-	c.checkDuration.Observe(duration.Seconds())
 	c.checksPerType.WithLabelValues(proxyType).Inc()
 
 	if working {
@@ -225,6 +235,16 @@ func (c *Collector) RecordProxyCheck(working bool, proxyType string, duration ti
 	}
 }
 
+// ObserveCheckDuration records how long a proxy check took, labeled by proxy
+// type and outcome ("working" or "failed")
+func (c *Collector) ObserveCheckDuration(proxyType string, working bool, d time.Duration) {
+	outcome := "failed"
+	if working {
+		outcome = "working"
+	}
+	c.checkDuration.WithLabelValues(proxyType, outcome).Observe(d.Seconds())
+}
+
 // RecordAnonymousProxy records an anonymous proxy discovery
 func (c *Collector) RecordAnonymousProxy() {
 	c.proxiesAnonymous.Inc()
@@ -268,6 +288,11 @@ func (c *Collector) SetWorkersActive(count int) {
 	c.workersActive.Set(float64(count))
 }
 
+// SetGoroutines updates the goroutine count gauge
+func (c *Collector) SetGoroutines(count int) {
+	c.goroutines.Set(float64(count))
+}
+
 // GetRegistry returns the Prometheus registry for external use
 func (c *Collector) GetRegistry() *prometheus.Registry {
 	return c.registry
@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -23,7 +24,7 @@ func TestRecordProxyCheck(t *testing.T) {
 	collector := NewCollector()
 
 	// Record a working proxy
-	collector.RecordProxyCheck(true, "http", time.Second)
+	collector.RecordProxyCheck(true, "http")
 
 	// Check that the counter was incremented
 	if testutil.ToFloat64(collector.proxiesChecked) != 1 {
@@ -39,7 +40,7 @@ func TestRecordProxyCheck(t *testing.T) {
 	}
 
 	// Record a failed proxy
-	collector.RecordProxyCheck(false, "socks5", time.Millisecond*500)
+	collector.RecordProxyCheck(false, "socks5")
 
 	if testutil.ToFloat64(collector.proxiesChecked) != 2 {
 		t.Errorf("Expected proxiesChecked to be 2, got %f", testutil.ToFloat64(collector.proxiesChecked))
@@ -195,7 +196,7 @@ func TestMetricsEndpoint(t *testing.T) {
 	collector := NewCollector()
 
 	// Record some metrics
-	collector.RecordProxyCheck(true, "http", time.Second)
+	collector.RecordProxyCheck(true, "http")
 	collector.RecordAnonymousProxy()
 	collector.SetActiveChecks(3)
 
@@ -279,9 +280,9 @@ func TestMetricsLabels(t *testing.T) {
 	collector := NewCollector()
 
 	// Test proxy type labels
-	collector.RecordProxyCheck(true, "http", time.Second)
-	collector.RecordProxyCheck(true, "socks5", time.Second)
-	collector.RecordProxyCheck(false, "http", time.Second)
+	collector.RecordProxyCheck(true, "http")
+	collector.RecordProxyCheck(true, "socks5")
+	collector.RecordProxyCheck(false, "http")
 
 	httpCount := testutil.ToFloat64(collector.checksPerType.WithLabelValues("http"))
 	if httpCount != 2 {
@@ -300,7 +301,7 @@ func BenchmarkRecordProxyCheck(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		collector.RecordProxyCheck(true, "http", time.Millisecond*100)
+		collector.RecordProxyCheck(true, "http")
 	}
 }
 
@@ -323,3 +324,43 @@ func BenchmarkSetGauges(b *testing.B) {
 		collector.SetWorkersActive(i % 50)
 	}
 }
+
+func TestObserveCheckDuration(t *testing.T) {
+	collector := NewCollector()
+
+	collector.ObserveCheckDuration("http", true, 300*time.Millisecond)
+	collector.ObserveCheckDuration("http", true, 3*time.Second)
+	collector.ObserveCheckDuration("socks5", false, 12*time.Second)
+
+	if count := testutil.CollectAndCount(collector.checkDuration); count != 2 {
+		t.Errorf("Expected 2 labeled series, got %d", count)
+	}
+
+	expected := `
+# HELP proxyhawk_check_duration_seconds Duration of proxy checks in seconds
+# TYPE proxyhawk_check_duration_seconds histogram
+proxyhawk_check_duration_seconds_bucket{outcome="working",proxy_type="http",le="0.1"} 0
+proxyhawk_check_duration_seconds_bucket{outcome="working",proxy_type="http",le="0.25"} 0
+proxyhawk_check_duration_seconds_bucket{outcome="working",proxy_type="http",le="0.5"} 1
+proxyhawk_check_duration_seconds_bucket{outcome="working",proxy_type="http",le="1"} 1
+proxyhawk_check_duration_seconds_bucket{outcome="working",proxy_type="http",le="2"} 1
+proxyhawk_check_duration_seconds_bucket{outcome="working",proxy_type="http",le="5"} 2
+proxyhawk_check_duration_seconds_bucket{outcome="working",proxy_type="http",le="10"} 2
+proxyhawk_check_duration_seconds_bucket{outcome="working",proxy_type="http",le="+Inf"} 2
+proxyhawk_check_duration_seconds_sum{outcome="working",proxy_type="http"} 3.3
+proxyhawk_check_duration_seconds_count{outcome="working",proxy_type="http"} 2
+proxyhawk_check_duration_seconds_bucket{outcome="failed",proxy_type="socks5",le="0.1"} 0
+proxyhawk_check_duration_seconds_bucket{outcome="failed",proxy_type="socks5",le="0.25"} 0
+proxyhawk_check_duration_seconds_bucket{outcome="failed",proxy_type="socks5",le="0.5"} 0
+proxyhawk_check_duration_seconds_bucket{outcome="failed",proxy_type="socks5",le="1"} 0
+proxyhawk_check_duration_seconds_bucket{outcome="failed",proxy_type="socks5",le="2"} 0
+proxyhawk_check_duration_seconds_bucket{outcome="failed",proxy_type="socks5",le="5"} 0
+proxyhawk_check_duration_seconds_bucket{outcome="failed",proxy_type="socks5",le="10"} 0
+proxyhawk_check_duration_seconds_bucket{outcome="failed",proxy_type="socks5",le="+Inf"} 1
+proxyhawk_check_duration_seconds_sum{outcome="failed",proxy_type="socks5"} 12
+proxyhawk_check_duration_seconds_count{outcome="failed",proxy_type="socks5"} 1
+`
+	if err := testutil.CollectAndCompare(collector.checkDuration, strings.NewReader(expected)); err != nil {
+		t.Errorf("Unexpected histogram contents: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"os"
+)
+
+// binaryFormatVersion is bumped whenever the encoded layout changes in a way
+// older readers cannot decode
+const binaryFormatVersion = 1
+
+// binaryResults is the gob-encoded payload of a binary results file
+type binaryResults struct {
+	Version int
+	Summary SummaryOutput
+}
+
+// WriteBinaryOutput writes the summary and all results to a compact gob file
+// that can be re-loaded with ReadBinaryOutput much faster than re-parsing JSON.
+// Values are written unsanitized so they round-trip exactly.
+func WriteBinaryOutput(filename string, summary SummaryOutput) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	if err := gob.NewEncoder(writer).Encode(binaryResults{Version: binaryFormatVersion, Summary: summary}); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// ReadBinaryOutput reads a results file written by WriteBinaryOutput
+func ReadBinaryOutput(filename string) (SummaryOutput, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return SummaryOutput{}, err
+	}
+	defer file.Close()
+
+	var payload binaryResults
+	if err := gob.NewDecoder(bufio.NewReader(file)).Decode(&payload); err != nil {
+		return SummaryOutput{}, fmt.Errorf("failed to decode binary results: %w", err)
+	}
+	if payload.Version != binaryFormatVersion {
+		return SummaryOutput{}, fmt.Errorf("unsupported binary results version %d (expected %d)", payload.Version, binaryFormatVersion)
+	}
+	return payload.Summary, nil
+}
@@ -0,0 +1,85 @@
+package output
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBinaryOutputRoundTrip(t *testing.T) {
+	similarity := 0.95
+	summary := SummaryOutput{
+		TotalProxies:   2,
+		WorkingProxies: 1,
+		SuccessRate:    50,
+		Results: []ProxyResultOutput{
+			{
+				Proxy:             "http://1.2.3.4:8080",
+				Working:           true,
+				Speed:             1500 * time.Millisecond,
+				ContentSimilarity: &similarity,
+				CheckTimes:        []time.Duration{time.Second},
+				Redirects:         []RedirectOutput{{URL: "http://example.com", StatusCode: 302, Location: "/login"}},
+				Timestamp:         time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+			{Proxy: "socks5://5.6.7.8:1080", Error: "<connection refused>"},
+		},
+	}
+
+	filename := filepath.Join(t.TempDir(), "results.bin")
+	if err := WriteBinaryOutput(filename, summary); err != nil {
+		t.Fatalf("WriteBinaryOutput failed: %v", err)
+	}
+
+	loaded, err := ReadBinaryOutput(filename)
+	if err != nil {
+		t.Fatalf("ReadBinaryOutput failed: %v", err)
+	}
+
+	if loaded.TotalProxies != 2 || len(loaded.Results) != 2 {
+		t.Fatalf("Unexpected summary after round trip: %+v", loaded)
+	}
+	first := loaded.Results[0]
+	if first.Speed != 1500*time.Millisecond || first.ContentSimilarity == nil || *first.ContentSimilarity != similarity {
+		t.Errorf("First result did not round trip: %+v", first)
+	}
+	if len(first.Redirects) != 1 || first.Redirects[0].Location != "/login" {
+		t.Errorf("Redirects did not round trip: %+v", first.Redirects)
+	}
+	if !first.Timestamp.Equal(summary.Results[0].Timestamp) {
+		t.Errorf("Timestamp did not round trip: %v", first.Timestamp)
+	}
+	if loaded.Results[1].Error != "<connection refused>" {
+		t.Errorf("Expected the error to be kept unsanitized, got %q", loaded.Results[1].Error)
+	}
+}
+
+func TestReadBinaryOutputRejectsOtherFormats(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "results.json")
+	if err := os.WriteFile(filename, []byte(`{"total_proxies": 1}`), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if _, err := ReadBinaryOutput(filename); err == nil {
+		t.Error("Expected an error when reading a JSON file as binary results")
+	}
+}
+
+func TestReadBinaryOutputRejectsOtherVersions(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "results.bin")
+	file, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	err = gob.NewEncoder(file).Encode(binaryResults{Version: binaryFormatVersion + 1})
+	file.Close()
+	if err != nil {
+		t.Fatalf("Failed to encode results: %v", err)
+	}
+
+	if _, err := ReadBinaryOutput(filename); err == nil {
+		t.Error("Expected an error when reading results of another format version")
+	}
+}
@@ -0,0 +1,243 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvColumn describes a selectable CSV column and how to extract its value
+type csvColumn struct {
+	name  string
+	value func(result ProxyResultOutput) string
+}
+
+// csvColumns lists every available CSV column in output order
+var csvColumns = []csvColumn{
+	{"proxy", func(r ProxyResultOutput) string { return r.Proxy }},
+	{"working", func(r ProxyResultOutput) string { return strconv.FormatBool(r.Working) }},
+	{"type", func(r ProxyResultOutput) string { return r.Type }},
+	{"speed_ms", func(r ProxyResultOutput) string { return formatMillis(r.Speed) }},
+	{"connect_latency_ms", func(r ProxyResultOutput) string {
+		if r.ConnectLatency == 0 {
+			return ""
+		}
+		return formatMillis(r.ConnectLatency)
+	}},
+	{"throughput_mbps", func(r ProxyResultOutput) string {
+		if r.ThroughputMBps == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(r.ThroughputMBps, 'f', 2, 64)
+	}},
+	{"is_anonymous", func(r ProxyResultOutput) string { return strconv.FormatBool(r.IsAnonymous) }},
+	{"anonymity_level", func(r ProxyResultOutput) string { return r.AnonymityLevel }},
+	{"cloud_provider", func(r ProxyResultOutput) string { return r.CloudProvider }},
+	{"real_ip", func(r ProxyResultOutput) string { return r.RealIP }},
+	{"proxy_ip", func(r ProxyResultOutput) string { return r.ProxyIP }},
+	{"country", func(r ProxyResultOutput) string { return r.ExitCountry }},
+	{"internal_access", func(r ProxyResultOutput) string { return strconv.FormatBool(r.InternalAccess) }},
+	{"metadata_access", func(r ProxyResultOutput) string { return strconv.FormatBool(r.MetadataAccess) }},
+	{"enforces_host", func(r ProxyResultOutput) string { return strconv.FormatBool(r.EnforcesHost) }},
+	{"proxy_class", func(r ProxyResultOutput) string { return r.ProxyClass }},
+	{"exit_org", func(r ProxyResultOutput) string { return r.ExitOrg }},
+	{"egress_ip", func(r ProxyResultOutput) string { return r.EgressIP }},
+	{"egress_ptr", func(r ProxyResultOutput) string { return r.EgressPTR }},
+	{"is_rotating", func(r ProxyResultOutput) string {
+		if r.IsRotating == nil {
+			return ""
+		}
+		return strconv.FormatBool(*r.IsRotating)
+	}},
+	{"observed_exit_ips", func(r ProxyResultOutput) string { return strings.Join(r.ObservedExitIPs, ";") }},
+	{"tls_intercepted", func(r ProxyResultOutput) string {
+		if r.TLSInfo == nil || r.TLSInfo.PinSource == "" {
+			return ""
+		}
+		return strconv.FormatBool(r.TLSInfo.Intercepted)
+	}},
+	{"reputation_score", func(r ProxyResultOutput) string {
+		if r.ReputationScore == nil {
+			return ""
+		}
+		return strconv.Itoa(*r.ReputationScore)
+	}},
+	{"blocklists", func(r ProxyResultOutput) string { return strings.Join(r.Blocklists, ";") }},
+	{"content_similarity", func(r ProxyResultOutput) string {
+		if r.ContentSimilarity == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*r.ContentSimilarity, 'f', 3, 64)
+	}},
+	{"score", func(r ProxyResultOutput) string { return strconv.FormatFloat(r.Score, 'f', 1, 64) }},
+	{"findings_count", func(r ProxyResultOutput) string { return strconv.Itoa(r.FindingsCount) }},
+	{"check_times_ms", func(r ProxyResultOutput) string {
+		times := make([]string, len(r.CheckTimes))
+		for i, d := range r.CheckTimes {
+			times[i] = formatMillis(d)
+		}
+		return strings.Join(times, ";")
+	}},
+	{"annotations", func(r ProxyResultOutput) string {
+		pairs := make([]string, 0, len(r.Annotations))
+		for key, value := range r.Annotations {
+			pairs = append(pairs, key+"="+value)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ";")
+	}},
+	{"checked_at", func(r ProxyResultOutput) string { return r.Timestamp.Format(time.RFC3339) }},
+	{"error", func(r ProxyResultOutput) string { return r.Error }},
+}
+
+// csvColumnAliases maps short column names accepted on the command line to their canonical names
+var csvColumnAliases = map[string]string{
+	"speed":     "speed_ms",
+	"anon":      "anonymity_level",
+	"anonymous": "is_anonymous",
+	"cloud":     "cloud_provider",
+	"findings":  "findings_count",
+	"timing":    "check_times_ms",
+	"timestamp": "checked_at",
+	"class":     "proxy_class",
+}
+
+// DefaultCSVColumns is the core column set written when no columns are selected
+var DefaultCSVColumns = []string{"proxy", "working", "type", "speed_ms", "is_anonymous", "cloud_provider", "real_ip", "proxy_ip", "error"}
+
+// TimingCSVColumns are appended by WithTimingColumns
+var TimingCSVColumns = []string{"speed_ms", "check_times_ms", "checked_at"}
+
+// CSVColumnNames returns the names of all available CSV columns
+func CSVColumnNames() []string {
+	names := make([]string, len(csvColumns))
+	for i, column := range csvColumns {
+		names[i] = column.name
+	}
+	return names
+}
+
+// ParseCSVColumns parses a comma-separated column list, resolving aliases.
+// An empty list selects DefaultCSVColumns.
+func ParseCSVColumns(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return append([]string(nil), DefaultCSVColumns...), nil
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if canonical, ok := csvColumnAliases[name]; ok {
+			name = canonical
+		}
+		if findCSVColumn(name) == nil {
+			return nil, fmt.Errorf("unknown CSV column %q (available: %s)", name, strings.Join(CSVColumnNames(), ", "))
+		}
+		if !seen[name] {
+			seen[name] = true
+			columns = append(columns, name)
+		}
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no CSV columns selected")
+	}
+	return columns, nil
+}
+
+// WithTimingColumns appends any timing columns missing from the selection
+func WithTimingColumns(columns []string) []string {
+	result := append([]string(nil), columns...)
+	for _, timing := range TimingCSVColumns {
+		present := false
+		for _, column := range result {
+			if column == timing {
+				present = true
+				break
+			}
+		}
+		if !present {
+			result = append(result, timing)
+		}
+	}
+	return result
+}
+
+// WriteCSVOutput writes results to a CSV file using the default columns
+func WriteCSVOutput(filename string, results []ProxyResultOutput) error {
+	return WriteCSVOutputWithColumns(filename, results, DefaultCSVColumns)
+}
+
+// WriteCSVOutputWithColumns writes results to a CSV file using the selected columns.
+// Results are expected to be sanitized already by ConvertToOutputFormat.
+func WriteCSVOutputWithColumns(filename string, results []ProxyResultOutput, columns []string) error {
+	if len(columns) == 0 {
+		columns = DefaultCSVColumns
+	}
+
+	selected := make([]*csvColumn, len(columns))
+	for i, name := range columns {
+		column := findCSVColumn(name)
+		if column == nil {
+			return fmt.Errorf("unknown CSV column %q", name)
+		}
+		selected[i] = column
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// encoding/csv quotes fields containing commas, quotes or newlines
+	writer := csv.NewWriter(file)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		record := make([]string, len(selected))
+		for i, column := range selected {
+			record[i] = csvSafe(column.value(result))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// findCSVColumn looks up a column by its canonical name
+func findCSVColumn(name string) *csvColumn {
+	for i := range csvColumns {
+		if csvColumns[i].name == name {
+			return &csvColumns[i]
+		}
+	}
+	return nil
+}
+
+// formatMillis formats a duration as whole milliseconds
+func formatMillis(d time.Duration) string {
+	return strconv.FormatInt(d.Milliseconds(), 10)
+}
+
+// csvSafe prevents spreadsheet formula injection by prefixing cells that
+// start with a formula trigger character
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,189 @@
+package output
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCSVColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected []string
+		wantErr  bool
+	}{
+		{"empty uses defaults", "", DefaultCSVColumns, false},
+		{"aliases resolved", "proxy,type,speed,anon", []string{"proxy", "type", "speed_ms", "anonymity_level"}, false},
+		{"duplicates removed", "proxy, Proxy ,error", []string{"proxy", "error"}, false},
+		{"country column", "proxy,country", []string{"proxy", "country"}, false},
+		{"unknown column", "proxy,colour", nil, true},
+		{"only separators", ",,", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, err := ParseCSVColumns(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCSVColumns(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(columns, tt.expected) {
+				t.Errorf("ParseCSVColumns(%q) = %v, want %v", tt.spec, columns, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWithTimingColumns(t *testing.T) {
+	columns := WithTimingColumns([]string{"proxy", "speed_ms"})
+	expected := []string{"proxy", "speed_ms", "check_times_ms", "checked_at"}
+	if !reflect.DeepEqual(columns, expected) {
+		t.Errorf("WithTimingColumns() = %v, want %v", columns, expected)
+	}
+}
+
+func TestWriteCSVOutputWithColumns(t *testing.T) {
+	results := []ProxyResultOutput{
+		{
+			Proxy:          "http://1.2.3.4:8080",
+			Working:        true,
+			Type:           "http",
+			Speed:          1500 * time.Millisecond,
+			AnonymityLevel: "elite",
+			CheckTimes:     []time.Duration{200 * time.Millisecond, 1300 * time.Millisecond},
+			FindingsCount:  2,
+		},
+		{
+			Proxy: "http://5.6.7.8:3128",
+			Error: "=HYPERLINK(\"evil\"), refused",
+		},
+	}
+
+	filename := filepath.Join(t.TempDir(), "results.csv")
+	columns := []string{"proxy", "speed_ms", "anonymity_level", "findings_count", "check_times_ms", "error"}
+	if err := WriteCSVOutputWithColumns(filename, results, columns); err != nil {
+		t.Fatalf("WriteCSVOutputWithColumns() error = %v", err)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatalf("Failed to open CSV: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected header and 2 rows, got %d records", len(records))
+	}
+	if !reflect.DeepEqual(records[0], columns) {
+		t.Errorf("Header = %v, want %v", records[0], columns)
+	}
+	if got := records[1]; got[1] != "1500" || got[2] != "elite" || got[3] != "2" || got[4] != "200;1300" {
+		t.Errorf("Unexpected first row: %v", got)
+	}
+	if got := records[2][5]; !strings.HasPrefix(got, "'=") {
+		t.Errorf("Expected formula to be neutralized, got %q", got)
+	}
+
+	if err := WriteCSVOutputWithColumns(filename, results, []string{"colour"}); err == nil {
+		t.Error("Expected error for unknown column")
+	}
+
+	results[0].ExitCountry = "DE"
+	if err := WriteCSVOutputWithColumns(filename, results, []string{"proxy", "country"}); err != nil {
+		t.Fatalf("WriteCSVOutputWithColumns() with country error = %v", err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if want := "proxy,country\nhttp://1.2.3.4:8080,DE\nhttp://5.6.7.8:3128,\n"; string(data) != want {
+		t.Errorf("CSV with country = %q, want %q", data, want)
+	}
+
+	results[0].Annotations = map[string]string{"tier": "gold", "geo": "internal"}
+	if err := WriteCSVOutputWithColumns(filename, results, []string{"proxy", "annotations"}); err != nil {
+		t.Fatalf("WriteCSVOutputWithColumns() with annotations error = %v", err)
+	}
+	data, err = os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if want := "proxy,annotations\nhttp://1.2.3.4:8080,geo=internal;tier=gold\nhttp://5.6.7.8:3128,\n"; string(data) != want {
+		t.Errorf("CSV with annotations = %q, want %q", data, want)
+	}
+
+	rotating := true
+	results[0].IsRotating = &rotating
+	results[0].ObservedExitIPs = []string{"203.0.113.1", "203.0.113.2"}
+	if err := WriteCSVOutputWithColumns(filename, results, []string{"proxy", "is_rotating", "observed_exit_ips"}); err != nil {
+		t.Fatalf("WriteCSVOutputWithColumns() with rotation error = %v", err)
+	}
+	data, err = os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if want := "proxy,is_rotating,observed_exit_ips\nhttp://1.2.3.4:8080,true,203.0.113.1;203.0.113.2\nhttp://5.6.7.8:3128,,\n"; string(data) != want {
+		t.Errorf("CSV with rotation = %q, want %q", data, want)
+	}
+}
+
+func TestWriteCSVOutput(t *testing.T) {
+	results := []ProxyResultOutput{
+		{
+			Proxy:         "http://1.2.3.4:8080",
+			Working:       true,
+			Type:          "http",
+			Speed:         1234567 * time.Microsecond,
+			IsAnonymous:   true,
+			CloudProvider: "AWS",
+			RealIP:        "9.9.9.9",
+			ProxyIP:       "1.2.3.4",
+		},
+		{
+			Proxy: "http://5.6.7.8:3128",
+			Error: `dial tcp: "connection refused", retry later`,
+		},
+	}
+
+	filename := filepath.Join(t.TempDir(), "results.csv")
+	if err := WriteCSVOutput(filename, results); err != nil {
+		t.Fatalf("WriteCSVOutput() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+
+	// Quotes inside a field must be doubled and the field quoted
+	if !strings.Contains(string(data), `"dial tcp: ""connection refused"", retry later"`) {
+		t.Errorf("Error field not escaped correctly:\n%s", data)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+
+	expectedHeader := []string{"proxy", "working", "type", "speed_ms", "is_anonymous", "cloud_provider", "real_ip", "proxy_ip", "error"}
+	if !reflect.DeepEqual(records[0], expectedHeader) {
+		t.Errorf("Header = %v, want %v", records[0], expectedHeader)
+	}
+
+	expectedRow := []string{"http://1.2.3.4:8080", "true", "http", "1234", "true", "AWS", "9.9.9.9", "1.2.3.4", ""}
+	if !reflect.DeepEqual(records[1], expectedRow) {
+		t.Errorf("Row = %v, want %v", records[1], expectedRow)
+	}
+
+	if records[2][8] != `dial tcp: "connection refused", retry later` {
+		t.Errorf("Error field did not round-trip: %q", records[2][8])
+	}
+}
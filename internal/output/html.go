@@ -0,0 +1,246 @@
+package output
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+)
+
+// htmlFinding is a proxy with at least one security finding, listed in the
+// findings section of the HTML report
+type htmlFinding struct {
+	Proxy  string
+	Issues []string
+}
+
+// htmlReportData is the data rendered by htmlReportTemplate
+type htmlReportData struct {
+	Generated string
+	Summary   SummaryOutput
+	Findings  []htmlFinding
+}
+
+// WriteHTMLReport writes a self-contained HTML report with summary statistics,
+// a sortable and filterable results table and any security findings. The CSS
+// and JavaScript are inlined so the file can be shared on its own.
+func WriteHTMLReport(filename string, summary SummaryOutput) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data := htmlReportData{
+		Generated: time.Now().Format(time.RFC3339),
+		Summary:   summary,
+		Findings:  collectHTMLFindings(summary.Results),
+	}
+
+	return htmlReportTemplate.Execute(file, data)
+}
+
+// collectHTMLFindings lists the security issues found on each proxy, one
+// entry per finding
+func collectHTMLFindings(results []ProxyResultOutput) []htmlFinding {
+	var findings []htmlFinding
+	for _, result := range results {
+		var issues []string
+		if result.InternalAccess {
+			issues = append(issues, "Internal network access")
+		}
+		if result.MetadataAccess {
+			issues = append(issues, "Cloud metadata access")
+		}
+		if result.AnonymityLevel == "transparent" {
+			issues = append(issues, "Transparent proxy leaks the client IP")
+		}
+		if result.ContentAltered {
+			issues = append(issues, "Serves content that differs from a direct fetch")
+		}
+		if result.CountryMismatch {
+			issues = append(issues, fmt.Sprintf("Egresses from %s, expected %s", result.ExitCountry, result.ExpectedCountry))
+		}
+		if result.DNSLeak {
+			issues = append(issues, "Target hostnames are resolved by the client's DNS resolver")
+		}
+		for _, header := range result.LeakingHeaders {
+			issues = append(issues, fmt.Sprintf("Leaks the %s header", header))
+		}
+		if result.ProxyChainDetected {
+			issues = append(issues, "Forwards through another proxy (proxy chain)")
+		}
+		if result.TLSInfo != nil && result.TLSInfo.Intercepted {
+			issues = append(issues, fmt.Sprintf("Intercepts TLS (certificate issued by %s)", result.TLSInfo.Issuer))
+		}
+		if len(result.Blocklists) > 0 {
+			issues = append(issues, fmt.Sprintf("Exit IP listed on %s", strings.Join(result.Blocklists, ", ")))
+		}
+		issues = append(issues, result.Vulnerabilities...)
+		if len(issues) > 0 {
+			findings = append(findings, htmlFinding{Proxy: result.Proxy, Issues: issues})
+		}
+	}
+	return findings
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"seconds": func(d time.Duration) string {
+		if d <= 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	},
+	"millis": func(d time.Duration) int64 {
+		return d.Milliseconds()
+	},
+	"bytes": formatBytes,
+}).Parse(htmlReportSource))
+
+// htmlReportSource is the report template. Sorting and filtering run entirely
+// in the page, so no external assets are needed.
+const htmlReportSource = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>ProxyHawk Report - {{.Generated}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; margin: 0; padding: 2rem; background: #f5f6f8; color: #222; }
+h1 { margin: 0 0 .25rem; }
+.generated { color: #666; margin-bottom: 1.5rem; }
+.stats { display: grid; grid-template-columns: repeat(auto-fill, minmax(160px, 1fr)); gap: 1rem; margin-bottom: 2rem; }
+.stat { background: #fff; border-radius: 8px; padding: 1rem; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+.stat .value { font-size: 1.6rem; font-weight: 600; }
+.stat .label { color: #666; font-size: .85rem; }
+.controls { display: flex; gap: 1rem; margin-bottom: 1rem; }
+.controls input, .controls select { padding: .4rem .6rem; border: 1px solid #ccc; border-radius: 4px; }
+table { width: 100%; border-collapse: collapse; background: #fff; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+th, td { padding: .5rem .75rem; text-align: left; border-bottom: 1px solid #eee; font-size: .9rem; }
+th { background: #fafafa; cursor: pointer; user-select: none; }
+th.sorted-asc::after { content: " \25B2"; }
+th.sorted-desc::after { content: " \25BC"; }
+.ok { color: #1a7f37; }
+.fail { color: #cf222e; }
+.findings { margin-top: 2rem; }
+.finding { background: #fff4e5; border-left: 4px solid #f0883e; padding: .75rem 1rem; margin-bottom: .5rem; border-radius: 4px; }
+.finding ul { margin: .25rem 0 0; }
+</style>
+</head>
+<body>
+<h1>ProxyHawk Report</h1>
+<div class="generated">Generated {{.Generated}}</div>
+
+<div class="stats">
+  <div class="stat"><div class="value">{{.Summary.TotalProxies}}</div><div class="label">Proxies tested</div></div>
+  <div class="stat"><div class="value">{{.Summary.WorkingProxies}}</div><div class="label">Working</div></div>
+  <div class="stat"><div class="value">{{printf "%.2f" .Summary.SuccessRate}}%</div><div class="label">Success rate</div></div>
+  <div class="stat"><div class="value">{{.Summary.AnonymousProxies}}</div><div class="label">Anonymous</div></div>
+  <div class="stat"><div class="value">{{.Summary.CloudProxies}}</div><div class="label">Cloud hosted</div></div>
+  <div class="stat"><div class="value">{{seconds .Summary.AverageSpeed}}</div><div class="label">Average speed</div></div>
+  <div class="stat"><div class="value">{{seconds .Summary.LatencyP50}} / {{seconds .Summary.LatencyP90}} / {{seconds .Summary.LatencyP99}}</div><div class="label">Latency p50 / p90 / p99</div></div>
+  <div class="stat"><div class="value">{{.Summary.TotalRequests}}</div><div class="label">Requests ({{bytes .Summary.TotalBytesDownloaded}})</div></div>
+</div>
+
+<div class="controls">
+  <input id="filter" type="search" placeholder="Filter proxies...">
+  <select id="status">
+    <option value="all">All proxies</option>
+    <option value="working">Working only</option>
+    <option value="failed">Failed only</option>
+  </select>
+</div>
+
+<table id="results">
+<thead>
+<tr>
+  <th data-type="text">Proxy</th>
+  <th data-type="text">Status</th>
+  <th data-type="text">Type</th>
+  <th data-type="number">Speed</th>
+  <th data-type="text">Anonymity</th>
+  <th data-type="text">Cloud</th>
+  <th data-type="number">Findings</th>
+  <th data-type="text">Error</th>
+</tr>
+</thead>
+<tbody>
+{{range .Summary.Results}}
+<tr data-working="{{.Working}}">
+  <td>{{.Proxy}}</td>
+  <td>{{if .Working}}<span class="ok">working</span>{{else}}<span class="fail">failed</span>{{end}}</td>
+  <td>{{.Type}}</td>
+  <td data-sort="{{millis .Speed}}">{{if .Working}}{{seconds .Speed}}{{else}}-{{end}}</td>
+  <td>{{.AnonymityLevel}}</td>
+  <td>{{.CloudProvider}}</td>
+  <td data-sort="{{.FindingsCount}}">{{.FindingsCount}}</td>
+  <td>{{.Error}}</td>
+</tr>
+{{end}}
+</tbody>
+</table>
+
+{{if .Findings}}
+<div class="findings">
+<h2>Findings</h2>
+{{range .Findings}}
+<div class="finding">
+  <strong>{{.Proxy}}</strong>
+  <ul>{{range .Issues}}<li>{{.}}</li>{{end}}</ul>
+</div>
+{{end}}
+</div>
+{{end}}
+
+<script>
+(function () {
+  var table = document.getElementById("results");
+  var body = table.tBodies[0];
+  var filter = document.getElementById("filter");
+  var status = document.getElementById("status");
+
+  function applyFilter() {
+    var text = filter.value.toLowerCase();
+    var mode = status.value;
+    Array.prototype.forEach.call(body.rows, function (row) {
+      var working = row.getAttribute("data-working") === "true";
+      var visible = row.textContent.toLowerCase().indexOf(text) !== -1 &&
+        (mode === "all" || (mode === "working") === working);
+      row.style.display = visible ? "" : "none";
+    });
+  }
+
+  function cellValue(row, index, type) {
+    var cell = row.cells[index];
+    var value = cell.getAttribute("data-sort") || cell.textContent.trim();
+    return type === "number" ? parseFloat(value) || 0 : value.toLowerCase();
+  }
+
+  Array.prototype.forEach.call(table.tHead.rows[0].cells, function (header, index) {
+    header.addEventListener("click", function () {
+      var type = header.getAttribute("data-type");
+      var ascending = !header.classList.contains("sorted-asc");
+      Array.prototype.forEach.call(table.tHead.rows[0].cells, function (h) {
+        h.classList.remove("sorted-asc", "sorted-desc");
+      });
+      header.classList.add(ascending ? "sorted-asc" : "sorted-desc");
+
+      var rows = Array.prototype.slice.call(body.rows);
+      rows.sort(function (a, b) {
+        var x = cellValue(a, index, type), y = cellValue(b, index, type);
+        if (x < y) return ascending ? -1 : 1;
+        if (x > y) return ascending ? 1 : -1;
+        return 0;
+      });
+      rows.forEach(function (row) { body.appendChild(row); });
+    });
+  });
+
+  filter.addEventListener("input", applyFilter);
+  status.addEventListener("change", applyFilter);
+})();
+</script>
+</body>
+</html>
+`
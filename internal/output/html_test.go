@@ -0,0 +1,102 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteHTMLReport(t *testing.T) {
+	summary := SummaryOutput{
+		TotalProxies:   2,
+		WorkingProxies: 1,
+		SuccessRate:    50,
+		Results: []ProxyResultOutput{
+			{
+				Proxy:          "http://good.example.com:8080",
+				Working:        true,
+				Speed:          1500 * time.Millisecond,
+				AnonymityLevel: "elite",
+			},
+			{
+				Proxy:          "http://<script>alert(1)</script>:8080",
+				Working:        false,
+				InternalAccess: true,
+				FindingsCount:  1,
+			},
+		},
+	}
+
+	filename := filepath.Join(t.TempDir(), "report.html")
+	if err := WriteHTMLReport(filename, summary); err != nil {
+		t.Fatalf("WriteHTMLReport failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	report := string(data)
+
+	for _, want := range []string{
+		`<table id="results">`,
+		"http://good.example.com:8080",
+		"1.50s",
+		"Internal network access",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Report missing %q", want)
+		}
+	}
+
+	if strings.Contains(report, "<script>alert(1)</script>") {
+		t.Error("Proxy string was not escaped in the report")
+	}
+}
+
+func TestCollectHTMLFindings(t *testing.T) {
+	findings := collectHTMLFindings([]ProxyResultOutput{
+		{Proxy: "clean", Working: true},
+		{Proxy: "transparent", AnonymityLevel: "transparent"},
+		{Proxy: "metadata", MetadataAccess: true, FindingsCount: 1},
+	})
+
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %d", len(findings))
+	}
+	if findings[0].Proxy != "transparent" || findings[1].Proxy != "metadata" {
+		t.Errorf("Unexpected findings order: %+v", findings)
+	}
+	if len(findings[1].Issues) != 1 {
+		t.Errorf("Expected metadata access to be reported once, got %v", findings[1].Issues)
+	}
+}
+
+func TestCollectHTMLFindingsListsEachFinding(t *testing.T) {
+	findings := collectHTMLFindings([]ProxyResultOutput{{
+		Proxy:              "leaky",
+		InternalAccess:     true,
+		MetadataAccess:     true,
+		LeakingHeaders:     []string{"X-Forwarded-For", "Via"},
+		ProxyChainDetected: true,
+		Vulnerabilities:    []string{"Kong Admin API exposed"},
+		FindingsCount:      5,
+	}})
+
+	if len(findings) != 1 {
+		t.Fatalf("Expected 1 proxy with findings, got %d", len(findings))
+	}
+	want := []string{
+		"Internal network access",
+		"Cloud metadata access",
+		"Leaks the X-Forwarded-For header",
+		"Leaks the Via header",
+		"Forwards through another proxy (proxy chain)",
+		"Kong Admin API exposed",
+	}
+	if strings.Join(findings[0].Issues, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Issues = %q, want %q", findings[0].Issues, want)
+	}
+}
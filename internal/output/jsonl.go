@@ -0,0 +1,152 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/proxy"
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/sanitizer"
+)
+
+// JSONLWriter streams results to a JSON-lines file as each check completes,
+// so an interrupted run still leaves every finished result on disk. Each
+// result is written as a single line, so the file stays valid JSON-lines
+// even if the process stops between writes.
+//
+// By default every line is handed to the OS as it is written, which survives
+// a crash of ProxyHawk but not of the OS. SetFlushPolicy trades durability
+// for speed in either direction: a flush interval buffers lines and writes
+// them in batches (a crash loses at most one interval of results), and fsync
+// forces each flush to stable storage (survives an OS crash or power loss,
+// at the cost of a disk sync per flush).
+type JSONLWriter struct {
+	mutex         sync.Mutex
+	file          *os.File
+	pending       []byte // Lines not yet written to file
+	sanitizer     *sanitizer.Sanitizer
+	preserveInput bool
+
+	flushInterval time.Duration
+	fsync         bool
+	flushTimer    *time.Timer
+	flushErr      error // Error from a timed flush, returned by the next Write or Close
+}
+
+// NewJSONLWriter creates (or truncates) filename for streaming results
+func NewJSONLWriter(filename string) (*JSONLWriter, error) {
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JSONLWriter{
+		file:      file,
+		sanitizer: sanitizer.DefaultSanitizer(),
+	}, nil
+}
+
+// Write appends one sanitized ProxyResultOutput line for result
+func (w *JSONLWriter) Write(result *proxy.ProxyResult) error {
+	converted := ConvertToOutputFormatWithSanitizer([]*proxy.ProxyResult{result}, w.sanitizer)
+	if w.preserveInput {
+		PreserveInput(converted)
+	}
+
+	line, err := json.Marshal(converted[0])
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return fmt.Errorf("JSON-lines writer is closed")
+	}
+
+	if err := w.flushErr; err != nil {
+		w.flushErr = nil
+		return err
+	}
+
+	// Lines are only ever flushed whole, so a crash never leaves a partial line
+	w.pending = append(w.pending, line...)
+	if w.flushInterval <= 0 {
+		return w.flushLocked()
+	}
+	if w.flushTimer == nil {
+		w.flushTimer = time.AfterFunc(w.flushInterval, w.timedFlush)
+	}
+	return nil
+}
+
+// SetFlushPolicy sets how often buffered lines are written out (0 = after
+// every line) and whether each flush is followed by an fsync. Call it before
+// the first Write.
+func (w *JSONLWriter) SetFlushPolicy(interval time.Duration, fsync bool) {
+	w.flushInterval = interval
+	w.fsync = fsync
+}
+
+// timedFlush writes out lines buffered since the flush timer was started
+func (w *JSONLWriter) timedFlush() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.flushTimer = nil
+	if w.file == nil {
+		return
+	}
+	w.flushErr = w.flushLocked()
+}
+
+// flushLocked writes buffered lines to the file and fsyncs it if enabled.
+// The caller must hold the mutex.
+func (w *JSONLWriter) flushLocked() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+	_, err := w.file.Write(w.pending)
+	w.pending = w.pending[:0]
+	if err != nil {
+		return err
+	}
+	if w.fsync {
+		return w.file.Sync()
+	}
+	return nil
+}
+
+// SetPreserveInput makes lines use the proxy as written in the input list
+// instead of its normalized URL (see PreserveInput). Call it before the first Write.
+func (w *JSONLWriter) SetPreserveInput(preserve bool) {
+	w.preserveInput = preserve
+}
+
+// Close flushes any buffered lines and closes the underlying file. Writes
+// after Close return an error.
+func (w *JSONLWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	if w.flushTimer != nil {
+		w.flushTimer.Stop()
+		w.flushTimer = nil
+	}
+	err := w.flushErr
+	if flushErr := w.flushLocked(); err == nil {
+		err = flushErr
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	w.file = nil
+	return err
+}
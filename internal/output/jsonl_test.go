@@ -0,0 +1,118 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/proxy"
+)
+
+func TestJSONLWriter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "results.jsonl")
+	writer, err := NewJSONLWriter(filename)
+	if err != nil {
+		t.Fatalf("NewJSONLWriter failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result := &proxy.ProxyResult{
+				ProxyURL: fmt.Sprintf("http://proxy%d.example.com:8080", i),
+				Working:  i%2 == 0,
+				Speed:    time.Duration(i) * time.Millisecond,
+			}
+			if err := writer.Write(result); err != nil {
+				t.Errorf("Write failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := writer.Write(&proxy.ProxyResult{ProxyURL: "http://late.example.com:8080"}); err == nil {
+		t.Error("Expected an error writing after Close")
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var decoded ProxyResultOutput
+		if err := json.Unmarshal(scanner.Bytes(), &decoded); err != nil {
+			t.Fatalf("Line %d is not valid JSON: %v", lines+1, err)
+		}
+		if decoded.Proxy == "" {
+			t.Errorf("Line %d has no proxy", lines+1)
+		}
+		lines++
+	}
+	if lines != 20 {
+		t.Errorf("Expected 20 lines, got %d", lines)
+	}
+}
+
+func TestJSONLWriterFlushInterval(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "results.jsonl")
+	writer, err := NewJSONLWriter(filename)
+	if err != nil {
+		t.Fatalf("NewJSONLWriter failed: %v", err)
+	}
+	writer.SetFlushPolicy(50*time.Millisecond, true)
+
+	countLines := func() int {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		lines := 0
+		for _, b := range data {
+			if b == '\n' {
+				lines++
+			}
+		}
+		return lines
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := writer.Write(&proxy.ProxyResult{ProxyURL: fmt.Sprintf("http://proxy%d.example.com:8080", i)}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if got := countLines(); got != 0 {
+		t.Errorf("Expected lines to be buffered until the flush interval, got %d on disk", got)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for countLines() != 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := countLines(); got != 3 {
+		t.Fatalf("Expected 3 lines after the flush interval, got %d", got)
+	}
+
+	if err := writer.Write(&proxy.ProxyResult{ProxyURL: "http://last.example.com:8080"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if got := countLines(); got != 4 {
+		t.Errorf("Expected Close to flush the buffered line, got %d lines", got)
+	}
+}
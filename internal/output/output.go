@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ResistanceIsUseless/ProxyHawk/internal/proxy"
@@ -12,20 +15,127 @@ import (
 
 // ProxyResultOutput represents a proxy result for output formatting
 type ProxyResultOutput struct {
-	Proxy          string        `json:"proxy"`
-	Working        bool          `json:"working"`
-	Speed          time.Duration `json:"speed_ns"`
-	InteractshTest bool          `json:"interactsh_test"`
-	RealIP         string        `json:"real_ip,omitempty"`
-	ProxyIP        string        `json:"proxy_ip,omitempty"`
-	IsAnonymous    bool          `json:"is_anonymous"`
-	CloudProvider  string        `json:"cloud_provider,omitempty"`
-	InternalAccess bool          `json:"internal_access"`
-	MetadataAccess bool          `json:"metadata_access"`
-	Timestamp      time.Time     `json:"timestamp"`
-	Error          string        `json:"error,omitempty"`
-	Type           string        `json:"type,omitempty"`
-	
+	Proxy             string        `json:"proxy"`
+	Input             string        `json:"input,omitempty"`
+	Working           bool          `json:"working"`
+	Speed             time.Duration `json:"speed_ns"`
+	ConnectLatency    time.Duration `json:"connect_latency_ns,omitempty"`
+	InteractshTest    bool          `json:"interactsh_test"`
+	RealIP            string        `json:"real_ip,omitempty"`
+	ProxyIP           string        `json:"proxy_ip,omitempty"`
+	IsAnonymous       bool          `json:"is_anonymous"`
+	AnonymityLevel    string        `json:"anonymity_level,omitempty"`
+	AnonymityDegraded bool          `json:"anonymity_degraded,omitempty"`
+	CloudProvider     string        `json:"cloud_provider,omitempty"`
+	InternalAccess    bool          `json:"internal_access"`
+	MetadataAccess    bool          `json:"metadata_access"`
+	EnforcesHost      bool          `json:"enforces_host,omitempty"`
+	DNSLeak           bool          `json:"dns_leak,omitempty"`
+	ContentSimilarity *float64      `json:"content_similarity,omitempty"`
+	ContentAltered    bool          `json:"content_altered,omitempty"`
+	Timestamp         time.Time     `json:"timestamp"`
+	Error             string        `json:"error,omitempty"`
+	Type              string        `json:"type,omitempty"`
+	HTTP10Only        bool          `json:"http10_only,omitempty"`
+	AuthRequired      bool          `json:"auth_required,omitempty"`
+
+	// Minimal header comparison (only with -minimal-headers)
+	MinimalHeadersStatus int  `json:"minimal_headers_status,omitempty"`
+	FullHeadersStatus    int  `json:"full_headers_status,omitempty"`
+	HeaderBlocking       bool `json:"header_blocking,omitempty"`
+
+	// Headers the target received through the proxy (only with -echo-headers)
+	ReceivedHeaders map[string][]string `json:"received_headers,omitempty"`
+
+	// Exit country check (only when an expected country was given for the proxy)
+	ExpectedCountry string `json:"expected_country,omitempty"`
+	ExitCountry     string `json:"exit_country,omitempty"`
+	CountryMismatch bool   `json:"country_mismatch,omitempty"`
+
+	// Network classification (only with proxy classification enabled)
+	ProxyClass string `json:"proxy_class,omitempty"`
+	ExitOrg    string `json:"exit_org,omitempty"`
+
+	// Egress IP and its reverse DNS name (only with -egress-ptr)
+	EgressIP  string `json:"egress_ip,omitempty"`
+	EgressPTR string `json:"egress_ptr,omitempty"`
+
+	// Download throughput (only with -measure-throughput)
+	ThroughputMBps    float64 `json:"throughput_mbps,omitempty"`
+	ThroughputPartial bool    `json:"throughput_partial,omitempty"`
+
+	// Exit IP rotation (only with -detect-rotation)
+	IsRotating      *bool    `json:"is_rotating,omitempty"`
+	ObservedExitIPs []string `json:"observed_exit_ips,omitempty"`
+
+	// Reachability per site category (only with category checks enabled)
+	CategoryAccess map[string]bool `json:"category_access,omitempty"`
+
+	// WebSocket tunneling (only with -websocket)
+	SupportsWebSocket *bool `json:"supports_websocket,omitempty"`
+
+	// Exit IP reputation (only with -check-reputation)
+	ReputationScore *int     `json:"reputation_score,omitempty"`
+	Blocklists      []string `json:"blocklists,omitempty"`
+
+	// Suspicious behavior heuristics (only with -suspicious-check)
+	SuspiciousScore      float64  `json:"suspicious_score,omitempty"`
+	SuspiciousIndicators []string `json:"suspicious_indicators,omitempty"`
+
+	// Why a response body was cut off: size_limit or read_timeout, e.g. a
+	// chunked stream that never ends
+	UnboundedResponse string `json:"unbounded_response,omitempty"`
+
+	// Why a followed redirect chain was aborted: redirect_loop or too_many_redirects
+	RedirectFailure string `json:"redirect_failure,omitempty"`
+
+	// IPv6 connectivity (only with test_ipv6)
+	SupportsIPv6 *bool `json:"supports_ipv6,omitempty"`
+
+	// Certificate of the HTTPS validation target (only with -inspect-tls)
+	TLSInfo *proxy.TLSInfo `json:"tls_info,omitempty"`
+
+	// IPv6 target reachability (only when the validation target is IPv6-only or -6 is used)
+	SupportsIPv6Target *bool `json:"supports_ipv6_target,omitempty"`
+
+	// gRPC health status (only when a gRPC target is configured)
+	GRPCStatus string `json:"grpc_status,omitempty"`
+
+	// Annotations added by an embedder's result hook
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Timing breakdown of the individual check requests
+	CheckTimes []time.Duration `json:"check_times_ns,omitempty"`
+
+	// Retry-After waits taken before retrying a rate limited (429) request
+	RetryAfterDelays []time.Duration `json:"retry_after_delays_ns,omitempty"`
+
+	// Redirects returned (but not followed) by the checked URLs
+	Redirects []RedirectOutput `json:"redirects,omitempty"`
+
+	// Quality score from 0 to 100 (0 when not working)
+	Score float64 `json:"score"`
+
+	// Number of security findings (leaking headers, proxy chain, internal/metadata access)
+	FindingsCount int `json:"findings_count"`
+
+	// Headers leaking client or proxy details, and whether the proxy
+	// forwards through another proxy
+	LeakingHeaders     []string `json:"leaking_headers,omitempty"`
+	ProxyChainDetected bool     `json:"proxy_chain_detected,omitempty"`
+
+	// Findings of the vulnerability scans (only with vuln checks enabled)
+	Vulnerabilities []string `json:"vulnerabilities,omitempty"`
+
+	// Server software and versions seen in front of the proxy
+	DetectedSoftware []proxy.SoftwareInfo `json:"detected_software,omitempty"`
+
+	// Vuln probe paths skipped because they were outside the configured scope
+	SkippedVulnPaths []string `json:"skipped_vuln_paths,omitempty"`
+
+	// State-changing vuln checks skipped because vuln scanning is read-only
+	SkippedVulnChecks []string `json:"skipped_vuln_checks,omitempty"`
+
 	// Protocol support information
 	ProtocolSupport ProtocolSupport `json:"protocol_support"`
 }
@@ -36,24 +146,59 @@ type ProtocolSupport struct {
 	HTTPS  bool `json:"https"`
 	HTTP2  bool `json:"http2"`
 	HTTP3  bool `json:"http3"`
+	HTTP10 bool `json:"http10,omitempty"`
+	GRPC   bool `json:"grpc,omitempty"`
 	SOCKS4 bool `json:"socks4"`
 	SOCKS5 bool `json:"socks5"`
+
+	// CONNECT probe result, independent of HTTPS validation
+	CONNECT           bool `json:"connect"`
+	ConnectStatusCode int  `json:"connect_status_code,omitempty"`
+	ConnectOnly       bool `json:"connect_only,omitempty"`
 }
 
 // SummaryOutput represents summary statistics for output
 type SummaryOutput struct {
-	TotalProxies        int                 `json:"total_proxies"`
-	WorkingProxies      int                 `json:"working_proxies"`
-	InteractshProxies   int                 `json:"interactsh_proxies"`
-	AnonymousProxies    int                 `json:"anonymous_proxies"`
-	CloudProxies        int                 `json:"cloud_proxies"`
-	InternalAccessCount int                 `json:"internal_access_count"`
-	MetadataAccessCount int                 `json:"metadata_access_count"`
-	SuccessRate         float64             `json:"success_rate"`
-	AverageSpeed        time.Duration       `json:"average_speed_ns"`
-	Results             []ProxyResultOutput `json:"results"`
+	TotalProxies           int                 `json:"total_proxies"`
+	WorkingProxies         int                 `json:"working_proxies"`
+	InteractshProxies      int                 `json:"interactsh_proxies"`
+	AnonymousProxies       int                 `json:"anonymous_proxies"`
+	CloudProxies           int                 `json:"cloud_proxies"`
+	InternalAccessCount    int                 `json:"internal_access_count"`
+	MetadataAccessCount    int                 `json:"metadata_access_count"`
+	AnonymityDegradedCount int                 `json:"anonymity_degraded_count"`
+	HTTP10OnlyCount        int                 `json:"http10_only_count"`
+	ContentAlteredCount    int                 `json:"content_altered_count"`
+	CountryMismatchCount   int                 `json:"country_mismatch_count"`
+	TotalRequests          int64               `json:"total_requests"`
+	TotalBytesDownloaded   int64               `json:"total_bytes_downloaded"`
+	SuccessRate            float64             `json:"success_rate"`
+	AverageSpeed           time.Duration       `json:"average_speed_ns"`
+	LatencyP50             time.Duration       `json:"latency_p50_ns"`
+	LatencyP90             time.Duration       `json:"latency_p90_ns"`
+	LatencyP99             time.Duration       `json:"latency_p99_ns"`
+	Fastest                []SpeedRanking      `json:"fastest,omitempty"`
+	Slowest                []SpeedRanking      `json:"slowest,omitempty"`
+	Results                []ProxyResultOutput `json:"results"`
+}
+
+// RedirectOutput is a 3xx response seen during a check
+type RedirectOutput struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+	Location   string `json:"location"`
+}
+
+// SpeedRanking represents a working proxy and its measured speed in the
+// fastest/slowest summary lists
+type SpeedRanking struct {
+	Proxy string        `json:"proxy"`
+	Speed time.Duration `json:"speed_ns"`
 }
 
+// SpeedRankingSize is the number of proxies listed in the fastest and slowest summaries
+const SpeedRankingSize = 5
+
 // ConvertToOutputFormat converts internal proxy results to output format with sanitization
 func ConvertToOutputFormat(results []*proxy.ProxyResult) []ProxyResultOutput {
 	return ConvertToOutputFormatWithSanitizer(results, sanitizer.DefaultSanitizer())
@@ -69,32 +214,340 @@ func ConvertToOutputFormatWithSanitizer(results []*proxy.ProxyResult, s *sanitiz
 		}
 
 		output[i] = ProxyResultOutput{
-			Proxy:          s.SanitizeURL(result.ProxyURL),
-			Working:        result.Working,
-			Speed:          result.Speed,
-			InteractshTest: false, // Will be set if interactsh tests were run
-			RealIP:         s.SanitizeIP(result.RealIP),
-			ProxyIP:        s.SanitizeIP(result.ProxyIP),
-			IsAnonymous:    result.IsAnonymous,
-			CloudProvider:  s.SanitizeString(result.CloudProvider),
-			InternalAccess: result.InternalAccess,
-			MetadataAccess: result.MetadataAccess,
-			Timestamp:      time.Now(),
-			Error:          errorMsg,
-			Type:           s.SanitizeString(string(result.Type)),
+			Proxy:             s.SanitizeURL(result.ProxyURL),
+			Input:             s.SanitizeString(result.Input),
+			Working:           result.Working,
+			Speed:             result.Speed,
+			ConnectLatency:    result.ConnectLatency,
+			InteractshTest:    false, // Will be set if interactsh tests were run
+			RealIP:            s.SanitizeIP(result.RealIP),
+			ProxyIP:           s.SanitizeIP(result.ProxyIP),
+			IsAnonymous:       result.IsAnonymous,
+			AnonymityLevel:    string(result.AnonymityLevel),
+			AnonymityDegraded: result.AnonymityRateLimited,
+			CloudProvider:     s.SanitizeString(result.CloudProvider),
+			InternalAccess:    result.InternalAccess,
+			MetadataAccess:    result.MetadataAccess,
+			EnforcesHost:      result.EnforcesHost,
+			DNSLeak:           result.DNSLeak,
+			ContentAltered:    result.ContentAltered,
+			Timestamp:         time.Now(),
+			Error:             errorMsg,
+			Type:              s.SanitizeString(string(result.Type)),
+			HTTP10Only:        result.HTTP10Only,
+			AuthRequired:      result.AuthRequired,
+			ExpectedCountry:   result.ExpectedCountry,
+			ExitCountry:       s.SanitizeString(result.ExitCountry),
+			CountryMismatch:   result.CountryMismatch,
+			CheckTimes:        checkTimes(result.CheckResults),
+			RetryAfterDelays:  result.RetryAfterDelays,
+			Redirects:         redirects(result.CheckResults, s),
+			Score:             result.Score,
+			FindingsCount:     countFindings(result),
+			SkippedVulnPaths:  result.SkippedVulnPaths,
 			ProtocolSupport: ProtocolSupport{
-				HTTP:   result.SupportsHTTP,
-				HTTPS:  result.SupportsHTTPS,
-				HTTP2:  result.SupportsHTTP2,
-				HTTP3:  result.SupportsHTTP3,
-				SOCKS4: result.Type == proxy.ProxyTypeSOCKS4,
-				SOCKS5: result.Type == proxy.ProxyTypeSOCKS5,
+				HTTP:              result.SupportsHTTP,
+				HTTPS:             result.SupportsHTTPS,
+				HTTP2:             result.SupportsHTTP2,
+				HTTP3:             result.SupportsHTTP3,
+				HTTP10:            result.SupportsHTTP10,
+				GRPC:              result.SupportsGRPC,
+				CONNECT:           result.SupportsConnect,
+				ConnectStatusCode: result.ConnectStatusCode,
+				ConnectOnly:       result.ConnectOnly,
+				SOCKS4:            result.Type == proxy.ProxyTypeSOCKS4 || result.Type == proxy.ProxyTypeSOCKS4A,
+				SOCKS5:            result.Type == proxy.ProxyTypeSOCKS5,
 			},
 		}
+		if result.ContentSimilarityChecked {
+			similarity := result.ContentSimilarity
+			output[i].ContentSimilarity = &similarity
+		}
+		if len(result.DetectedSoftware) > 0 {
+			output[i].DetectedSoftware = detectedSoftware(result.DetectedSoftware, s)
+		}
+		if result.IPv6Checked {
+			supportsIPv6 := result.SupportsIPv6
+			output[i].SupportsIPv6 = &supportsIPv6
+		}
+		if result.TLSInfo != nil {
+			output[i].TLSInfo = tlsInfo(result.TLSInfo, s)
+		}
+		if result.IPv6TargetChecked {
+			supportsIPv6Target := result.SupportsIPv6Target
+			output[i].SupportsIPv6Target = &supportsIPv6Target
+		}
+		if result.ProxyClass != "" {
+			output[i].ProxyClass = string(result.ProxyClass)
+			output[i].ExitOrg = s.SanitizeString(result.ExitOrg)
+		}
+		output[i].EgressIP = s.SanitizeIP(result.EgressIP)
+		output[i].EgressPTR = s.SanitizeString(result.EgressPTR)
+		output[i].ThroughputMBps = result.ThroughputMBps
+		output[i].ThroughputPartial = result.ThroughputPartial
+		if result.RotationChecked {
+			isRotating := result.IsRotating
+			output[i].IsRotating = &isRotating
+			for _, ip := range result.ObservedExitIPs {
+				output[i].ObservedExitIPs = append(output[i].ObservedExitIPs, s.SanitizeIP(ip))
+			}
+		}
+		output[i].CategoryAccess = result.CategoryAccess
+		if result.WebSocketChecked {
+			supportsWebSocket := result.SupportsWebSocket
+			output[i].SupportsWebSocket = &supportsWebSocket
+		}
+		if result.ReputationChecked {
+			reputationScore := result.ReputationScore
+			output[i].ReputationScore = &reputationScore
+			output[i].Blocklists = result.Blocklists
+		}
+		output[i].SuspiciousScore = result.SuspiciousScore
+		output[i].SuspiciousIndicators = result.SuspiciousIndicators
+		output[i].UnboundedResponse = result.UnboundedResponse
+		output[i].RedirectFailure = result.RedirectFailure
+		output[i].GRPCStatus = result.GRPCStatus
+		output[i].Annotations = annotations(result.Annotations, s)
+		output[i].SkippedVulnChecks = result.SkippedVulnChecks
+		if result.MinimalHeadersChecked {
+			output[i].MinimalHeadersStatus = result.MinimalHeadersStatus
+			output[i].FullHeadersStatus = result.FullHeadersStatus
+			output[i].HeaderBlocking = result.HeaderBlocking
+		}
+		output[i].ReceivedHeaders = receivedHeaders(result.ReceivedHeaders, s)
+		for _, header := range result.LeakingHeaders {
+			output[i].LeakingHeaders = append(output[i].LeakingHeaders, s.SanitizeString(header))
+		}
+		output[i].ProxyChainDetected = result.ProxyChainDetected
+		for _, finding := range vulnFindings(result) {
+			output[i].Vulnerabilities = append(output[i].Vulnerabilities, s.SanitizeString(finding))
+		}
 	}
 	return output
 }
 
+// PreserveInput replaces each normalized proxy URL with the proxy string as it
+// was written in the input list, so output files round-trip the original format
+func PreserveInput(results []ProxyResultOutput) {
+	for i := range results {
+		if results[i].Input != "" {
+			results[i].Proxy = results[i].Input
+			results[i].Input = ""
+		}
+	}
+}
+
+// SortedByProxy returns a copy of results ordered by proxy URL (then type), so
+// the same results produce byte-identical JSON regardless of completion order.
+// Measured fields such as speed and timestamp still change between runs, so
+// two runs diff only where those differ. Map keys need no handling since
+// encoding/json always writes them sorted.
+func SortedByProxy(results []ProxyResultOutput) []ProxyResultOutput {
+	sorted := make([]ProxyResultOutput, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Proxy != sorted[j].Proxy {
+			return sorted[i].Proxy < sorted[j].Proxy
+		}
+		return sorted[i].Type < sorted[j].Type
+	})
+	return sorted
+}
+
+// SortedByScore returns a copy of results ordered by quality score, highest
+// first. Proxies with equal scores keep their original order.
+func SortedByScore(results []*proxy.ProxyResult) []*proxy.ProxyResult {
+	sorted := make([]*proxy.ProxyResult, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Score > sorted[j].Score
+	})
+	return sorted
+}
+
+// SortedByConnectLatency returns a copy of results ordered by first-hop
+// connect latency, fastest first. Proxies without a measured connect latency
+// go last; ties keep their original order.
+func SortedByConnectLatency(results []*proxy.ProxyResult) []*proxy.ProxyResult {
+	sorted := make([]*proxy.ProxyResult, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].ConnectLatency, sorted[j].ConnectLatency
+		if a == 0 || b == 0 {
+			return b == 0 && a != 0
+		}
+		return a < b
+	})
+	return sorted
+}
+
+// FilterByProxyClass returns the results classified as one of classes. Proxies
+// that were not classified, such as ones that did not work, are dropped.
+func FilterByProxyClass(results []*proxy.ProxyResult, classes []proxy.ProxyClass) []*proxy.ProxyResult {
+	filtered := make([]*proxy.ProxyResult, 0, len(results))
+	for _, result := range results {
+		for _, class := range classes {
+			if result.ProxyClass == class {
+				filtered = append(filtered, result)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// checkTimes returns the duration of each individual check request
+func checkTimes(checks []proxy.CheckResult) []time.Duration {
+	if len(checks) == 0 {
+		return nil
+	}
+	times := make([]time.Duration, len(checks))
+	for i, check := range checks {
+		times[i] = check.Speed
+	}
+	return times
+}
+
+// redirects returns the redirects recorded on the check requests
+func redirects(checks []proxy.CheckResult, s *sanitizer.Sanitizer) []RedirectOutput {
+	var out []RedirectOutput
+	for _, check := range checks {
+		if check.RedirectLocation == "" {
+			continue
+		}
+		out = append(out, RedirectOutput{
+			URL:        s.SanitizeURL(check.URL),
+			StatusCode: check.StatusCode,
+			Location:   s.SanitizeURL(check.RedirectLocation),
+		})
+	}
+	return out
+}
+
+// detectedSoftware sanitizes software names and versions, which come from
+// response headers and bodies of the checked proxy
+func detectedSoftware(software []proxy.SoftwareInfo, s *sanitizer.Sanitizer) []proxy.SoftwareInfo {
+	out := make([]proxy.SoftwareInfo, len(software))
+	for i, info := range software {
+		out[i] = proxy.SoftwareInfo{
+			Name:    s.SanitizeString(info.Name),
+			Version: s.SanitizeString(info.Version),
+			Source:  info.Source,
+		}
+	}
+	return out
+}
+
+// annotations sanitizes the keys and values a result hook added, which may
+// come from any enrichment source
+func annotations(in map[string]string, s *sanitizer.Sanitizer) map[string]string {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(in))
+	for key, value := range in {
+		out[s.SanitizeString(key)] = s.SanitizeString(value)
+	}
+	return out
+}
+
+// receivedHeaders sanitizes the headers echoed back by the target, which the
+// checked proxy can inject or rewrite
+func receivedHeaders(in map[string][]string, s *sanitizer.Sanitizer) map[string][]string {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string][]string, len(in))
+	for name, values := range in {
+		key := s.SanitizeString(name)
+		for _, value := range values {
+			out[key] = append(out[key], s.SanitizeString(value))
+		}
+	}
+	return out
+}
+
+// tlsInfo sanitizes the certificate names, which the checked proxy can
+// choose when it intercepts TLS
+func tlsInfo(info *proxy.TLSInfo, s *sanitizer.Sanitizer) *proxy.TLSInfo {
+	out := *info
+	out.Subject = s.SanitizeString(info.Subject)
+	out.Issuer = s.SanitizeString(info.Issuer)
+	out.SANs = make([]string, len(info.SANs))
+	for i, san := range info.SANs {
+		out.SANs[i] = s.SanitizeString(san)
+	}
+	return &out
+}
+
+// countFindings counts the security-relevant findings recorded for a proxy
+func countFindings(result *proxy.ProxyResult) int {
+	findings := len(result.LeakingHeaders)
+	if result.ProxyChainDetected {
+		findings++
+	}
+	if result.InternalAccess {
+		findings++
+	}
+	if result.MetadataAccess {
+		findings++
+	}
+	if result.TLSInfo != nil && result.TLSInfo.Intercepted {
+		findings++
+	}
+	if len(result.Blocklists) > 0 {
+		findings++
+	}
+	return findings
+}
+
+// vulnFindings lists the vulnerabilities the vuln scans found on a proxy,
+// one entry per finding the scans count
+func vulnFindings(result *proxy.ProxyResult) []string {
+	var findings []string
+	add := func(found bool, finding string) {
+		if found {
+			findings = append(findings, finding)
+		}
+	}
+
+	if v := result.NginxVulnerabilities; v != nil {
+		add(v.OffBySlashVuln, "Nginx alias off-by-slash path traversal")
+		add(v.K8sAPIExposed, "Kubernetes API exposed")
+		add(v.IngressWebhookExposed, "Ingress admission webhook exposed")
+		add(v.DebugEndpointsExposed, "Nginx debug endpoints exposed")
+		add(v.VulnerableAnnotations, "Ingress annotation injection")
+	}
+	if v := result.ApacheVulnerabilities; v != nil {
+		add(v.CVE_2021_40438_SSRF, "Apache mod_proxy SSRF (CVE-2021-40438)")
+		add(v.CVE_2020_11984_RCE, "Apache mod_proxy_uwsgi overflow (CVE-2020-11984)")
+		add(v.CVE_2021_41773_PathTraversal, "Apache path traversal (CVE-2021-41773)")
+		add(v.CVE_2024_38473_ACLBypass, "Apache ACL bypass (CVE-2024-38473)")
+		add(v.SSRFVulnerable, "Apache SSRF misconfiguration")
+		add(v.PathTraversalVuln, "Apache path traversal")
+	}
+	if v := result.KongVulnerabilities; v != nil {
+		add(v.ManagerExposed, "Kong Manager exposed")
+		add(v.AdminAPIExposed, "Kong Admin API exposed")
+		add(v.UnauthorizedAccess, "Kong configuration readable without authentication")
+	}
+	if v := result.GenericVulnerabilities; v != nil {
+		add(v.OpenProxyToLocalhost, "Proxies to localhost services")
+		add(v.XForwardedForBypass, "X-Forwarded-For access control bypass")
+		add(v.CachePoisonVulnerable, "Cache poisoning")
+		add(v.LinkerdSSRF, "Linkerd SSRF")
+		add(v.SpringBootActuator, "Spring Boot Actuator exposed")
+	}
+	if v := result.ExtendedVulnerabilities; v != nil {
+		add(v.NginxVersionDetected, fmt.Sprintf("Nginx version disclosed (%s)", v.NginxVersion))
+		add(v.NginxConfigExposed, "Nginx configuration exposed")
+		add(v.WebSocketAbuseVulnerable, "WebSocket upgrade abuse")
+		add(v.HTTP2SmugglingVulnerable, "HTTP/2 request smuggling")
+		add(v.ProxyAuthBypass, "Proxy authentication bypass")
+	}
+	return findings
+}
+
 // GenerateSummary creates a summary from proxy results
 func GenerateSummary(results []*proxy.ProxyResult) SummaryOutput {
 	output := ConvertToOutputFormat(results)
@@ -106,6 +559,7 @@ func GenerateSummary(results []*proxy.ProxyResult) SummaryOutput {
 
 	var totalSpeed time.Duration
 	var speedCount int
+	var speeds []time.Duration
 
 	for _, result := range results {
 		if result.Working {
@@ -113,6 +567,7 @@ func GenerateSummary(results []*proxy.ProxyResult) SummaryOutput {
 			if result.Speed > 0 {
 				totalSpeed += result.Speed
 				speedCount++
+				speeds = append(speeds, result.Speed)
 			}
 		}
 
@@ -120,6 +575,22 @@ func GenerateSummary(results []*proxy.ProxyResult) SummaryOutput {
 			summary.AnonymousProxies++
 		}
 
+		if result.AnonymityRateLimited {
+			summary.AnonymityDegradedCount++
+		}
+
+		if result.HTTP10Only {
+			summary.HTTP10OnlyCount++
+		}
+
+		if result.ContentAltered {
+			summary.ContentAlteredCount++
+		}
+
+		if result.CountryMismatch {
+			summary.CountryMismatchCount++
+		}
+
 		if result.CloudProvider != "" {
 			summary.CloudProxies++
 		}
@@ -131,6 +602,9 @@ func GenerateSummary(results []*proxy.ProxyResult) SummaryOutput {
 		if result.MetadataAccess {
 			summary.MetadataAccessCount++
 		}
+
+		summary.TotalRequests += result.RequestCount
+		summary.TotalBytesDownloaded += result.BytesDownloaded
 	}
 
 	if summary.TotalProxies > 0 {
@@ -141,9 +615,61 @@ func GenerateSummary(results []*proxy.ProxyResult) SummaryOutput {
 		summary.AverageSpeed = totalSpeed / time.Duration(speedCount)
 	}
 
+	sort.Slice(speeds, func(i, j int) bool { return speeds[i] < speeds[j] })
+	summary.LatencyP50 = percentile(speeds, 50)
+	summary.LatencyP90 = percentile(speeds, 90)
+	summary.LatencyP99 = percentile(speeds, 99)
+
+	summary.Fastest, summary.Slowest = rankBySpeed(output, SpeedRankingSize)
+
 	return summary
 }
 
+// percentile returns the nearest-rank p-th percentile of speeds, which must be
+// sorted in ascending order. An empty slice yields zero.
+func percentile(speeds []time.Duration, p int) time.Duration {
+	if len(speeds) == 0 {
+		return 0
+	}
+	rank := (p*len(speeds) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return speeds[rank-1]
+}
+
+// rankBySpeed returns up to n of the fastest and slowest working proxies.
+// Proxies without a measured speed are ignored.
+func rankBySpeed(results []ProxyResultOutput, n int) ([]SpeedRanking, []SpeedRanking) {
+	var ranked []SpeedRanking
+	for _, result := range results {
+		if result.Working && result.Speed > 0 {
+			ranked = append(ranked, SpeedRanking{Proxy: result.Proxy, Speed: result.Speed})
+		}
+	}
+	if len(ranked) == 0 {
+		return nil, nil
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Speed < ranked[j].Speed
+	})
+
+	if n > len(ranked) {
+		n = len(ranked)
+	}
+
+	fastest := make([]SpeedRanking, n)
+	copy(fastest, ranked[:n])
+
+	slowest := make([]SpeedRanking, n)
+	for i := 0; i < n; i++ {
+		slowest[i] = ranked[len(ranked)-1-i]
+	}
+
+	return fastest, slowest
+}
+
 // WriteTextOutput writes results to a text file with sanitization
 func WriteTextOutput(filename string, results []ProxyResultOutput, summary SummaryOutput) error {
 	return WriteTextOutputWithSanitizer(filename, results, summary, sanitizer.DefaultSanitizer())
@@ -187,6 +713,19 @@ func WriteTextOutputWithSanitizer(filename string, results []ProxyResultOutput,
 				proxyType := s.SanitizeString(result.Type)
 				fmt.Fprintf(file, " (%s)", proxyType)
 			}
+			fmt.Fprintf(file, " [score %.1f]", result.Score)
+			if result.HTTP10Only {
+				fmt.Fprintf(file, " [HTTP/1.0 only]")
+			}
+			if result.ContentAltered && result.ContentSimilarity != nil {
+				fmt.Fprintf(file, " [content altered: %.2f similarity]", *result.ContentSimilarity)
+			}
+			if result.CountryMismatch {
+				fmt.Fprintf(file, " [exit country %s, expected %s]", s.SanitizeString(result.ExitCountry), result.ExpectedCountry)
+			}
+			if result.ProtocolSupport.ConnectStatusCode != 0 && !result.ProtocolSupport.CONNECT {
+				fmt.Fprintf(file, " [CONNECT refused: %d]", result.ProtocolSupport.ConnectStatusCode)
+			}
 			if result.CloudProvider != "" {
 				cloudProvider := s.SanitizeString(result.CloudProvider)
 				fmt.Fprintf(file, " [%s]", cloudProvider)
@@ -195,6 +734,9 @@ func WriteTextOutputWithSanitizer(filename string, results []ProxyResultOutput,
 			errorMsg := s.SanitizeError(result.Error)
 			fmt.Fprintf(file, " - Error: %s", errorMsg)
 		}
+		if result.HeaderBlocking {
+			fmt.Fprintf(file, " [blocked with full headers: %d, minimal headers: %d]", result.FullHeadersStatus, result.MinimalHeadersStatus)
+		}
 
 		fmt.Fprintf(file, "\n")
 	}
@@ -208,14 +750,60 @@ func WriteTextOutputWithSanitizer(filename string, results []ProxyResultOutput,
 	fmt.Fprintf(file, "Anonymous proxies: %d\n", summary.AnonymousProxies)
 	fmt.Fprintf(file, "Cloud proxies: %d\n", summary.CloudProxies)
 	fmt.Fprintf(file, "Success rate: %.2f%%\n", summary.SuccessRate)
+	fmt.Fprintf(file, "Requests made: %d (%s downloaded)\n", summary.TotalRequests, formatBytes(summary.TotalBytesDownloaded))
+	if summary.HTTP10OnlyCount > 0 {
+		fmt.Fprintf(file, "HTTP/1.0-only proxies: %d\n", summary.HTTP10OnlyCount)
+	}
+	if summary.ContentAlteredCount > 0 {
+		fmt.Fprintf(file, "Proxies serving altered content: %d\n", summary.ContentAlteredCount)
+	}
+	if summary.CountryMismatchCount > 0 {
+		fmt.Fprintf(file, "Proxies egressing from an unexpected country: %d\n", summary.CountryMismatchCount)
+	}
+	if summary.AnonymityDegradedCount > 0 {
+		fmt.Fprintf(file, "Anonymity detection degraded (rate limited): %d\n", summary.AnonymityDegradedCount)
+	}
 
 	if summary.AverageSpeed > 0 {
 		fmt.Fprintf(file, "Average speed: %.2fs\n", summary.AverageSpeed.Seconds())
 	}
+	if summary.WorkingProxies > 0 {
+		fmt.Fprintf(file, "Latency p50/p90/p99: %.2fs / %.2fs / %.2fs\n",
+			summary.LatencyP50.Seconds(), summary.LatencyP90.Seconds(), summary.LatencyP99.Seconds())
+	}
+
+	writeSpeedRanking(file, "Fastest proxies", summary.Fastest, s)
+	writeSpeedRanking(file, "Slowest proxies", summary.Slowest, s)
 
 	return nil
 }
 
+// writeSpeedRanking writes a titled list of proxies and their speeds
+func writeSpeedRanking(file *os.File, title string, ranking []SpeedRanking, s *sanitizer.Sanitizer) {
+	if len(ranking) == 0 {
+		return
+	}
+
+	fmt.Fprintf(file, "\n%s:\n", title)
+	for i, entry := range ranking {
+		fmt.Fprintf(file, "  %d. %s - %.2fs\n", i+1, s.SanitizeString(entry.Proxy), entry.Speed.Seconds())
+	}
+}
+
+// formatBytes renders a byte count using binary units (B, KiB, MiB, ...)
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for value := n / unit; value >= unit; value /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 // WriteJSONOutput writes results to a JSON file with sanitization
 func WriteJSONOutput(filename string, summary SummaryOutput) error {
 	return WriteJSONOutputWithSanitizer(filename, summary, sanitizer.DefaultSanitizer())
@@ -238,6 +826,59 @@ func WriteJSONOutputWithSanitizer(filename string, summary SummaryOutput, s *san
 	return encoder.Encode(sanitizedSummary)
 }
 
+// WarningOutput represents a single machine-readable warning
+type WarningOutput struct {
+	Source  string `json:"source"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+const (
+	// WarningSourceLoader marks warnings produced while parsing the proxy list
+	WarningSourceLoader = "proxy_loader"
+
+	// WarningSourceConfig marks configuration validation warnings
+	WarningSourceConfig = "config_validation"
+)
+
+// NewWarnings converts warning strings into structured warnings for the given source.
+// Loader warnings prefixed with "Line N: " have the line number extracted.
+func NewWarnings(source string, warnings []string) []WarningOutput {
+	converted := make([]WarningOutput, 0, len(warnings))
+	for _, warning := range warnings {
+		entry := WarningOutput{Source: source, Message: warning}
+
+		if rest, ok := strings.CutPrefix(warning, "Line "); ok {
+			if lineStr, message, found := strings.Cut(rest, ": "); found {
+				if line, err := strconv.Atoi(lineStr); err == nil {
+					entry.Line = line
+					entry.Message = message
+				}
+			}
+		}
+
+		converted = append(converted, entry)
+	}
+	return converted
+}
+
+// WriteWarningsJSON writes warnings to a JSON file as an array
+func WriteWarningsJSON(filename string, warnings []WarningOutput) error {
+	if warnings == nil {
+		warnings = []WarningOutput{}
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(warnings)
+}
+
 // sanitizeSummaryOutput applies sanitization to all string fields in summary
 func sanitizeSummaryOutput(summary SummaryOutput, s *sanitizer.Sanitizer) SummaryOutput {
 	// The results are already sanitized by ConvertToOutputFormatWithSanitizer
@@ -269,6 +910,9 @@ func WriteWorkingProxiesOutputWithSanitizer(filename string, results []ProxyResu
 				proxyType := s.SanitizeString(result.Type)
 				fmt.Fprintf(file, " (%s)", proxyType)
 			}
+			if result.HTTP10Only {
+				fmt.Fprintf(file, " [HTTP/1.0 only]")
+			}
 			fmt.Fprintf(file, "\n")
 		}
 	}
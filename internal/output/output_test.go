@@ -3,6 +3,7 @@ package output
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"strings"
 	"testing"
@@ -36,6 +37,10 @@ func TestConvertToOutputFormatWithSanitization(t *testing.T) {
 			CloudProvider: "Evil<script>alert('xss')</script>Cloud",
 			Type:          proxy.ProxyTypeHTTP,
 			Error:         errors.New("Connection failed: <script>alert('xss')</script>"),
+			Annotations:   map[string]string{"<b>geo</b>": "<script>alert('xss')</script>"},
+			ReceivedHeaders: map[string][]string{
+				"X-Injected": {"<script>alert('xss')</script>"},
+			},
 		},
 		{
 			ProxyURL:      "javascript:alert('xss')",
@@ -69,6 +74,23 @@ func TestConvertToOutputFormatWithSanitization(t *testing.T) {
 		t.Errorf("XSS content not sanitized in Error field: %s", output[1].Error)
 	}
 
+	for key, value := range output[1].Annotations {
+		if strings.Contains(key, "<b>") || strings.Contains(value, "<script>") {
+			t.Errorf("XSS content not sanitized in Annotations: %q=%q", key, value)
+		}
+	}
+
+	for name, values := range output[1].ReceivedHeaders {
+		for _, value := range values {
+			if strings.Contains(value, "<script>") {
+				t.Errorf("XSS content not sanitized in ReceivedHeaders: %s: %q", name, value)
+			}
+		}
+	}
+	if len(output[1].ReceivedHeaders) != 1 {
+		t.Errorf("Expected the received header to be kept, got %v", output[1].ReceivedHeaders)
+	}
+
 	// Test that JavaScript URL is handled
 	if output[2].Proxy != "[INVALID_SCHEME]" {
 		t.Errorf("JavaScript URL not properly sanitized: %s", output[2].Proxy)
@@ -401,6 +423,153 @@ func TestLongContentSanitization(t *testing.T) {
 	}
 }
 
+func TestGenerateSummarySpeedRanking(t *testing.T) {
+	var results []*proxy.ProxyResult
+	for i := 1; i <= 7; i++ {
+		results = append(results, &proxy.ProxyResult{
+			ProxyURL: "http://proxy" + string(rune('0'+i)) + ".example.com:8080",
+			Working:  true,
+			Speed:    time.Duration(i) * 100 * time.Millisecond,
+			Type:     proxy.ProxyTypeHTTP,
+		})
+	}
+	// Failed proxies must never appear in the rankings
+	results = append(results, &proxy.ProxyResult{
+		ProxyURL: "http://failed.example.com:8080",
+		Working:  false,
+		Speed:    10 * time.Millisecond,
+	})
+
+	summary := GenerateSummary(results)
+
+	if len(summary.Fastest) != SpeedRankingSize {
+		t.Fatalf("Expected %d fastest proxies, got %d", SpeedRankingSize, len(summary.Fastest))
+	}
+	if len(summary.Slowest) != SpeedRankingSize {
+		t.Fatalf("Expected %d slowest proxies, got %d", SpeedRankingSize, len(summary.Slowest))
+	}
+
+	if summary.Fastest[0].Proxy != "http://proxy1.example.com:8080" {
+		t.Errorf("Expected proxy1 to be fastest, got %s", summary.Fastest[0].Proxy)
+	}
+	if summary.Slowest[0].Proxy != "http://proxy7.example.com:8080" {
+		t.Errorf("Expected proxy7 to be slowest, got %s", summary.Slowest[0].Proxy)
+	}
+
+	for i := 1; i < len(summary.Fastest); i++ {
+		if summary.Fastest[i].Speed < summary.Fastest[i-1].Speed {
+			t.Errorf("Fastest list not in ascending order at index %d", i)
+		}
+		if summary.Slowest[i].Speed > summary.Slowest[i-1].Speed {
+			t.Errorf("Slowest list not in descending order at index %d", i)
+		}
+	}
+
+	empty := GenerateSummary(nil)
+	if empty.Fastest != nil || empty.Slowest != nil {
+		t.Errorf("Expected no rankings for empty results")
+	}
+}
+
+func TestGenerateSummaryLatencyPercentiles(t *testing.T) {
+	var results []*proxy.ProxyResult
+	for i := 1; i <= 100; i++ {
+		results = append(results, &proxy.ProxyResult{
+			ProxyURL: fmt.Sprintf("http://proxy%d.example.com:8080", i),
+			Working:  true,
+			Speed:    time.Duration(i) * time.Millisecond,
+		})
+	}
+	// Failed proxies are excluded from the percentiles
+	results = append(results, &proxy.ProxyResult{
+		ProxyURL: "http://failed.example.com:8080",
+		Speed:    time.Second,
+	})
+
+	summary := GenerateSummary(results)
+	if summary.LatencyP50 != 50*time.Millisecond {
+		t.Errorf("Expected p50 of 50ms, got %v", summary.LatencyP50)
+	}
+	if summary.LatencyP90 != 90*time.Millisecond {
+		t.Errorf("Expected p90 of 90ms, got %v", summary.LatencyP90)
+	}
+	if summary.LatencyP99 != 99*time.Millisecond {
+		t.Errorf("Expected p99 of 99ms, got %v", summary.LatencyP99)
+	}
+
+	empty := GenerateSummary([]*proxy.ProxyResult{{ProxyURL: "http://failed.example.com:8080"}})
+	if empty.LatencyP50 != 0 || empty.LatencyP90 != 0 || empty.LatencyP99 != 0 {
+		t.Errorf("Expected zero percentiles without working proxies, got %v/%v/%v",
+			empty.LatencyP50, empty.LatencyP90, empty.LatencyP99)
+	}
+}
+
+func TestGenerateSummaryTraffic(t *testing.T) {
+	results := []*proxy.ProxyResult{
+		{ProxyURL: "http://a.example.com:8080", Working: true, RequestCount: 4, BytesDownloaded: 2048},
+		{ProxyURL: "http://b.example.com:8080", Working: false, RequestCount: 1},
+	}
+
+	summary := GenerateSummary(results)
+	if summary.TotalRequests != 5 {
+		t.Errorf("Expected 5 total requests, got %d", summary.TotalRequests)
+	}
+	if summary.TotalBytesDownloaded != 2048 {
+		t.Errorf("Expected 2048 bytes downloaded, got %d", summary.TotalBytesDownloaded)
+	}
+	if got := formatBytes(summary.TotalBytesDownloaded); got != "2.0 KiB" {
+		t.Errorf("Expected 2.0 KiB, got %s", got)
+	}
+}
+
+func TestWriteWarningsJSON(t *testing.T) {
+	warnings := NewWarnings(WarningSourceLoader, []string{
+		"Line 3: invalid proxy URL: missing port",
+		"unexpected format",
+	})
+	warnings = append(warnings, NewWarnings(WarningSourceConfig, []string{"rate limiting is enabled but delay is 0, this will have no effect"})...)
+
+	if warnings[0].Line != 3 || warnings[0].Message != "invalid proxy URL: missing port" {
+		t.Errorf("Expected line number to be extracted, got %+v", warnings[0])
+	}
+	if warnings[1].Line != 0 || warnings[1].Message != "unexpected format" {
+		t.Errorf("Expected warning without line number to be kept as-is, got %+v", warnings[1])
+	}
+
+	tmpFile, err := os.CreateTemp("", "warnings_*.json")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	if err := WriteWarningsJSON(tmpFile.Name(), warnings); err != nil {
+		t.Fatalf("Failed to write warnings: %v", err)
+	}
+
+	data, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to read warnings file: %v", err)
+	}
+
+	var decoded []WarningOutput
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Warnings file is not a JSON array: %v", err)
+	}
+	if len(decoded) != 3 || decoded[2].Source != WarningSourceConfig {
+		t.Errorf("Unexpected decoded warnings: %+v", decoded)
+	}
+
+	// An empty warning list should still produce a JSON array
+	if err := WriteWarningsJSON(tmpFile.Name(), nil); err != nil {
+		t.Fatalf("Failed to write empty warnings: %v", err)
+	}
+	data, _ = os.ReadFile(tmpFile.Name())
+	if strings.TrimSpace(string(data)) != "[]" {
+		t.Errorf("Expected empty JSON array, got %s", data)
+	}
+}
+
 // Benchmark tests
 func BenchmarkConvertToOutputFormat(b *testing.B) {
 	results := []*proxy.ProxyResult{
@@ -441,3 +610,129 @@ func BenchmarkConvertToOutputFormatWithXSS(b *testing.B) {
 		ConvertToOutputFormat(results)
 	}
 }
+
+func TestPreserveInput(t *testing.T) {
+	results := ConvertToOutputFormat([]*proxy.ProxyResult{
+		{ProxyURL: "http://1.2.3.4:8080", Input: "1.2.3.4:8080"},
+		{ProxyURL: "socks5://5.6.7.8:1080"},
+	})
+
+	if results[0].Input != "1.2.3.4:8080" {
+		t.Errorf("Expected the original input alongside the proxy, got %q", results[0].Input)
+	}
+
+	PreserveInput(results)
+	if results[0].Proxy != "1.2.3.4:8080" || results[0].Input != "" {
+		t.Errorf("Expected the input to replace the normalized proxy, got %+v", results[0])
+	}
+	if results[1].Proxy != "socks5://5.6.7.8:1080" {
+		t.Errorf("Expected a proxy without input to be unchanged, got %q", results[1].Proxy)
+	}
+}
+
+func TestSortedByProxy(t *testing.T) {
+	results := []ProxyResultOutput{
+		{Proxy: "socks5://5.6.7.8:1080", Type: "socks5"},
+		{Proxy: "http://1.2.3.4:8080", Type: "https"},
+		{Proxy: "http://1.2.3.4:8080", Type: "http"},
+	}
+
+	sorted := SortedByProxy(results)
+	want := []string{"http://1.2.3.4:8080/http", "http://1.2.3.4:8080/https", "socks5://5.6.7.8:1080/socks5"}
+	for i, w := range want {
+		if got := sorted[i].Proxy + "/" + sorted[i].Type; got != w {
+			t.Errorf("SortedByProxy()[%d] = %s, want %s", i, got, w)
+		}
+	}
+	if results[0].Proxy != "socks5://5.6.7.8:1080" {
+		t.Error("SortedByProxy() reordered the input slice")
+	}
+
+	first, err := json.Marshal(sorted)
+	if err != nil {
+		t.Fatalf("Failed to marshal results: %v", err)
+	}
+	second, _ := json.Marshal(SortedByProxy([]ProxyResultOutput{results[2], results[0], results[1]}))
+	if string(first) != string(second) {
+		t.Error("Expected byte-identical JSON for the same results in a different order")
+	}
+}
+
+func TestSortedByScore(t *testing.T) {
+	results := []*proxy.ProxyResult{
+		{ProxyURL: "http://1.1.1.1:8080", Score: 40},
+		{ProxyURL: "http://2.2.2.2:8080", Score: 0},
+		{ProxyURL: "http://3.3.3.3:8080", Score: 85.5},
+		{ProxyURL: "http://4.4.4.4:8080", Score: 40},
+	}
+
+	sorted := SortedByScore(results)
+	want := []string{"http://3.3.3.3:8080", "http://1.1.1.1:8080", "http://4.4.4.4:8080", "http://2.2.2.2:8080"}
+	for i, w := range want {
+		if sorted[i].ProxyURL != w {
+			t.Errorf("SortedByScore()[%d] = %s, want %s", i, sorted[i].ProxyURL, w)
+		}
+	}
+	if results[0].ProxyURL != "http://1.1.1.1:8080" {
+		t.Error("SortedByScore() reordered the input slice")
+	}
+}
+
+func TestSortedByConnectLatency(t *testing.T) {
+	results := []*proxy.ProxyResult{
+		{ProxyURL: "http://1.1.1.1:8080", ConnectLatency: 300 * time.Millisecond},
+		{ProxyURL: "http://2.2.2.2:8080"},
+		{ProxyURL: "http://3.3.3.3:8080", ConnectLatency: 20 * time.Millisecond},
+		{ProxyURL: "http://4.4.4.4:8080", ConnectLatency: 300 * time.Millisecond},
+	}
+
+	sorted := SortedByConnectLatency(results)
+	want := []string{"http://3.3.3.3:8080", "http://1.1.1.1:8080", "http://4.4.4.4:8080", "http://2.2.2.2:8080"}
+	for i, w := range want {
+		if sorted[i].ProxyURL != w {
+			t.Errorf("SortedByConnectLatency()[%d] = %s, want %s", i, sorted[i].ProxyURL, w)
+		}
+	}
+}
+
+func TestFilterByProxyClass(t *testing.T) {
+	results := []*proxy.ProxyResult{
+		{ProxyURL: "http://1.1.1.1:8080", Working: true, ProxyClass: proxy.ProxyClassResidential},
+		{ProxyURL: "http://2.2.2.2:8080", Working: true, ProxyClass: proxy.ProxyClassDatacenter},
+		{ProxyURL: "http://3.3.3.3:8080", Working: true, ProxyClass: proxy.ProxyClassMobile},
+		{ProxyURL: "http://4.4.4.4:8080"},
+	}
+
+	filtered := FilterByProxyClass(results, []proxy.ProxyClass{proxy.ProxyClassResidential, proxy.ProxyClassMobile})
+	if len(filtered) != 2 || filtered[0].ProxyURL != "http://1.1.1.1:8080" || filtered[1].ProxyURL != "http://3.3.3.3:8080" {
+		t.Errorf("Unexpected filtered results: %+v", filtered)
+	}
+
+	output := ConvertToOutputFormat(filtered)
+	if output[0].ProxyClass != "residential" {
+		t.Errorf("Expected proxy_class in output, got %q", output[0].ProxyClass)
+	}
+}
+
+func TestConvertToOutputFormatFindings(t *testing.T) {
+	output := ConvertToOutputFormat([]*proxy.ProxyResult{{
+		ProxyURL:           "http://proxy.example.com:8080",
+		LeakingHeaders:     []string{"Via"},
+		ProxyChainDetected: true,
+		KongVulnerabilities: &proxy.KongVulnResult{
+			AdminAPIExposed: true,
+		},
+		GenericVulnerabilities: &proxy.GenericVulnResult{
+			OpenProxyToLocalhost: true,
+			SpringBootActuator:   true,
+		},
+	}})[0]
+
+	if len(output.LeakingHeaders) != 1 || output.LeakingHeaders[0] != "Via" || !output.ProxyChainDetected {
+		t.Errorf("LeakingHeaders = %v, ProxyChainDetected = %t; want [Via], true", output.LeakingHeaders, output.ProxyChainDetected)
+	}
+	want := []string{"Kong Admin API exposed", "Proxies to localhost services", "Spring Boot Actuator exposed"}
+	if strings.Join(output.Vulnerabilities, "|") != strings.Join(want, "|") {
+		t.Errorf("Vulnerabilities = %q, want %q", output.Vulnerabilities, want)
+	}
+}
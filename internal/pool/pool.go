@@ -1,7 +1,9 @@
 package pool
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"net"
 	"net/http"
 	"net/url"
@@ -28,6 +30,10 @@ type ConnectionPool struct {
 	disableKeepAlives     bool
 	disableCompression    bool
 	insecureSkipVerify    bool
+	rootCAs               *x509.CertPool
+
+	// dialContext replaces the default dialer when set
+	dialContext func(ctx context.Context, network, addr string) (net.Conn, error)
 }
 
 // Config represents connection pool configuration
@@ -42,6 +48,12 @@ type Config struct {
 	DisableKeepAlives     bool          `yaml:"disable_keep_alives"`
 	DisableCompression    bool          `yaml:"disable_compression"`
 	InsecureSkipVerify    bool          `yaml:"insecure_skip_verify"`
+
+	// RootCAs are the roots trusted when verifying targets (nil uses the system roots)
+	RootCAs *x509.CertPool `yaml:"-"`
+
+	// DialContext opens every connection, e.g. through an SSH tunnel (nil dials directly)
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error) `yaml:"-"`
 }
 
 // DefaultConfig returns a connection pool configuration with sensible defaults
@@ -73,6 +85,8 @@ func NewConnectionPool(config Config) *ConnectionPool {
 		disableKeepAlives:     config.DisableKeepAlives,
 		disableCompression:    config.DisableCompression,
 		insecureSkipVerify:    config.InsecureSkipVerify,
+		rootCAs:               config.RootCAs,
+		dialContext:           config.DialContext,
 		clients:               make(map[string]*http.Client),
 		mutex:                 sync.RWMutex{},
 	}
@@ -139,10 +153,14 @@ func (p *ConnectionPool) createClient(proxyURL string, timeout time.Duration) (*
 		DisableCompression:    p.disableCompression,
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: p.insecureSkipVerify,
+			RootCAs:            p.rootCAs,
 		},
 		// Enable HTTP/2 support
 		ForceAttemptHTTP2: true,
 	}
+	if p.dialContext != nil {
+		transport.DialContext = p.dialContext
+	}
 
 	return &http.Client{
 		Transport: transport,
@@ -171,10 +189,14 @@ func (p *ConnectionPool) createDirectClient(timeout time.Duration) *http.Client
 		DisableCompression:    p.disableCompression,
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: p.insecureSkipVerify,
+			RootCAs:            p.rootCAs,
 		},
 		// Enable HTTP/2 support
 		ForceAttemptHTTP2: true,
 	}
+	if p.dialContext != nil {
+		transport.DialContext = p.dialContext
+	}
 
 	return &http.Client{
 		Transport: transport,
@@ -258,6 +280,7 @@ func (p *ConnectionPool) UpdateConfig(config Config) {
 	p.disableKeepAlives = config.DisableKeepAlives
 	p.disableCompression = config.DisableCompression
 	p.insecureSkipVerify = config.InsecureSkipVerify
+	p.rootCAs = config.RootCAs
 }
 
 // GetClientCount returns the number of cached HTTP clients
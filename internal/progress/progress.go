@@ -27,6 +27,7 @@ const (
 	ProgressTypeSpinner  ProgressType = "spinner"  // Spinner with status
 	ProgressTypeDots     ProgressType = "dots"     // Dot progress
 	ProgressTypePercent  ProgressType = "percent"  // Percentage only
+	ProgressTypeLine     ProgressType = "line"     // Single line updated in place
 )
 
 // Config holds configuration for progress indicators
@@ -72,6 +73,8 @@ func NewProgressIndicator(config Config) ProgressIndicator {
 		return &DotsIndicator{config: config}
 	case ProgressTypePercent:
 		return &PercentIndicator{config: config}
+	case ProgressTypeLine:
+		return &LineIndicator{config: config}
 	default:
 		return &BasicIndicator{config: config}
 	}
@@ -101,6 +104,7 @@ func (n *NoneIndicator) SetOutput(writer io.Writer)         {}
 type BasicIndicator struct {
 	config Config
 	stats  Stats
+	rate   RateEstimator // Windowed throughput for the rate and ETA
 	mutex  sync.Mutex
 }
 
@@ -112,6 +116,8 @@ func (b *BasicIndicator) Start(total int) {
 		Total:     total,
 		StartTime: time.Now(),
 	}
+	b.rate = RateEstimator{}
+	b.rate.Record(b.stats.StartTime, 0)
 	
 	fmt.Fprintf(b.config.Output, "Starting proxy tests: %d proxies to check\n", total)
 }
@@ -135,15 +141,10 @@ func (b *BasicIndicator) Update(current int, message string) {
 	}
 	
 	progress := float64(current) / float64(b.stats.Total) * 100
-	elapsed := time.Since(b.stats.StartTime)
 	
-	if current > 0 {
-		b.stats.Rate = float64(current) / elapsed.Seconds()
-		if b.stats.Rate > 0 {
-			remaining := float64(b.stats.Total - current)
-			b.stats.ETA = time.Duration(remaining/b.stats.Rate) * time.Second
-		}
-	}
+	// Rate and ETA from recent throughput, not the average since the start
+	b.rate.Record(b.stats.LastUpdate, current)
+	b.stats.Rate, b.stats.ETA = b.rate.Estimate(b.stats.LastUpdate, b.stats.Total-current)
 	
 	statusLine := fmt.Sprintf("Progress: %d/%d (%.1f%%)", current, b.stats.Total, progress)
 	
@@ -196,6 +197,7 @@ func (b *BasicIndicator) SetOutput(writer io.Writer) {
 type BarIndicator struct {
 	config Config
 	stats  Stats
+	rate   RateEstimator // Windowed throughput for the rate and ETA
 	mutex  sync.Mutex
 }
 
@@ -207,6 +209,8 @@ func (b *BarIndicator) Start(total int) {
 		Total:     total,
 		StartTime: time.Now(),
 	}
+	b.rate = RateEstimator{}
+	b.rate.Record(b.stats.StartTime, 0)
 	
 	fmt.Fprintf(b.config.Output, "ProxyHawk: Testing %d proxies\n", total)
 }
@@ -229,15 +233,10 @@ func (b *BarIndicator) Update(current int, message string) {
 	}
 	
 	progress := float64(current) / float64(b.stats.Total)
-	elapsed := time.Since(b.stats.StartTime)
 	
-	if current > 0 {
-		b.stats.Rate = float64(current) / elapsed.Seconds()
-		if b.stats.Rate > 0 {
-			remaining := float64(b.stats.Total - current)
-			b.stats.ETA = time.Duration(remaining/b.stats.Rate) * time.Second
-		}
-	}
+	// Rate and ETA from recent throughput, not the average since the start
+	b.rate.Record(b.stats.LastUpdate, current)
+	b.stats.Rate, b.stats.ETA = b.rate.Estimate(b.stats.LastUpdate, b.stats.Total-current)
 	
 	// Create progress bar
 	filledWidth := int(progress * float64(b.config.Width))
@@ -309,6 +308,7 @@ func (b *BarIndicator) SetOutput(writer io.Writer) {
 type SpinnerIndicator struct {
 	config      Config
 	stats       Stats
+	rate        RateEstimator // Windowed throughput for the rate and ETA
 	mutex       sync.Mutex
 	ticker      *time.Ticker
 	spinnerIdx  int
@@ -325,6 +325,8 @@ func (s *SpinnerIndicator) Start(total int) {
 		Total:     total,
 		StartTime: time.Now(),
 	}
+	s.rate = RateEstimator{}
+	s.rate.Record(s.stats.StartTime, 0)
 	
 	fmt.Fprintf(s.config.Output, "ProxyHawk: Starting tests for %d proxies\n", total)
 	
@@ -347,15 +349,9 @@ func (s *SpinnerIndicator) updateDisplay() {
 	}
 	
 	progress := float64(s.stats.Current) / float64(s.stats.Total) * 100
-	elapsed := time.Since(s.stats.StartTime)
 	
-	if s.stats.Current > 0 {
-		s.stats.Rate = float64(s.stats.Current) / elapsed.Seconds()
-		if s.stats.Rate > 0 {
-			remaining := float64(s.stats.Total - s.stats.Current)
-			s.stats.ETA = time.Duration(remaining/s.stats.Rate) * time.Second
-		}
-	}
+	// Re-estimated every tick so the ETA grows while no proxy completes
+	s.stats.Rate, s.stats.ETA = s.rate.Estimate(time.Now(), s.stats.Total-s.stats.Current)
 	
 	spinner := spinnerChars[s.spinnerIdx]
 	if s.config.NoColor {
@@ -388,6 +384,7 @@ func (s *SpinnerIndicator) Update(current int, message string) {
 	s.stats.Current = current
 	s.stats.LastUpdate = time.Now()
 	s.lastMessage = message
+	s.rate.Record(s.stats.LastUpdate, current)
 	
 	if message != "" {
 		if strings.Contains(strings.ToLower(message), "success") || 
@@ -577,6 +574,130 @@ func (p *PercentIndicator) SetOutput(writer io.Writer) {
 	p.config.Output = writer
 }
 
+// lineLogInterval is how often LineIndicator prints a new line when its
+// output is not a terminal
+const lineLogInterval = 10 * time.Second
+
+// LineIndicator keeps a single "checked/total working% eta" line updated in
+// place with carriage returns. When the output is not a terminal (piped to a
+// log) it prints a plain line every lineLogInterval and at the end instead.
+type LineIndicator struct {
+	config    Config
+	stats     Stats
+	rate      RateEstimator // Windowed throughput for the rate and ETA
+	mutex     sync.Mutex
+	tty       bool
+	lastLen   int       // Length of the line on screen, padded over by the next one
+	lastPrint time.Time // When the last line was printed in non-terminal mode
+}
+
+func (l *LineIndicator) Start(total int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.stats = Stats{
+		Total:     total,
+		StartTime: time.Now(),
+	}
+	l.rate = RateEstimator{}
+	l.rate.Record(l.stats.StartTime, 0)
+	l.tty = isTerminal(l.config.Output)
+	l.lastLen = 0
+	l.lastPrint = l.stats.StartTime
+}
+
+func (l *LineIndicator) Update(current int, message string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.stats.Current = current
+	l.stats.LastUpdate = time.Now()
+
+	if message != "" {
+		if strings.Contains(strings.ToLower(message), "success") ||
+			strings.Contains(strings.ToLower(message), "working") {
+			l.stats.Working++
+		} else if strings.Contains(strings.ToLower(message), "fail") ||
+			strings.Contains(strings.ToLower(message), "error") {
+			l.stats.Failed++
+		}
+	}
+
+	l.rate.Record(l.stats.LastUpdate, current)
+	l.stats.Rate, l.stats.ETA = l.rate.Estimate(l.stats.LastUpdate, l.stats.Total-current)
+
+	if l.tty {
+		line := l.line()
+		fmt.Fprintf(l.config.Output, "\r%s%s", line, strings.Repeat(" ", max(l.lastLen-len(line), 0)))
+		l.lastLen = len(line)
+		return
+	}
+
+	if current >= l.stats.Total || l.stats.LastUpdate.Sub(l.lastPrint) >= lineLogInterval {
+		fmt.Fprintln(l.config.Output, l.line())
+		l.lastPrint = l.stats.LastUpdate
+	}
+}
+
+// line formats the progress line from the current stats
+func (l *LineIndicator) line() string {
+	working := 0.0
+	if l.stats.Current > 0 {
+		working = float64(l.stats.Working) / float64(l.stats.Current) * 100
+	}
+	eta := "--"
+	if l.stats.Current >= l.stats.Total {
+		eta = "0s"
+	} else if l.stats.ETA > 0 {
+		eta = l.stats.ETA.Round(time.Second).String()
+	}
+	return fmt.Sprintf("%d/%d checked  %.1f%% working  eta %s", l.stats.Current, l.stats.Total, working, eta)
+}
+
+func (l *LineIndicator) Finish(message string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.tty {
+		fmt.Fprint(l.config.Output, "\n")
+	} else if l.lastPrint.Before(l.stats.LastUpdate) || l.stats.LastUpdate.IsZero() {
+		// The run ended between two log lines, print where it stopped
+		fmt.Fprintln(l.config.Output, l.line())
+	}
+
+	elapsed := time.Since(l.stats.StartTime)
+	avgRate := float64(l.stats.Current) / elapsed.Seconds()
+	fmt.Fprintf(l.config.Output, "Completed: %d proxies tested in %v (%.2f/sec)\n",
+		l.stats.Current, elapsed.Round(time.Second), avgRate)
+
+	if l.config.ShowStats && l.stats.Current > 0 {
+		successRate := float64(l.stats.Working) / float64(l.stats.Current) * 100
+		fmt.Fprintf(l.config.Output, "Results: %d working (%.1f%%), %d failed\n",
+			l.stats.Working, successRate, l.stats.Failed)
+	}
+
+	if message != "" {
+		fmt.Fprintf(l.config.Output, "%s\n", message)
+	}
+}
+
+func (l *LineIndicator) SetOutput(writer io.Writer) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.config.Output = writer
+	l.tty = isTerminal(writer)
+}
+
+// isTerminal reports whether w is a terminal, so carriage returns redraw a line
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 // Utility functions
 func max(a, b int) int {
 	if a > b {
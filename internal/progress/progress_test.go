@@ -43,6 +43,7 @@ func TestNewProgressIndicator(t *testing.T) {
 		{"Spinner", ProgressTypeSpinner, "*progress.SpinnerIndicator"},
 		{"Dots", ProgressTypeDots, "*progress.DotsIndicator"},
 		{"Percent", ProgressTypePercent, "*progress.PercentIndicator"},
+		{"Line", ProgressTypeLine, "*progress.LineIndicator"},
 		{"Unknown", ProgressType("unknown"), "*progress.BasicIndicator"},
 	}
 	
@@ -253,6 +254,63 @@ func TestPercentIndicator(t *testing.T) {
 	}
 }
 
+func TestLineIndicator(t *testing.T) {
+	var buf bytes.Buffer
+	config := Config{
+		Type:      ProgressTypeLine,
+		ShowStats: true,
+		Output:    &buf,
+	}
+
+	// A buffer is not a terminal: plain lines, no carriage returns
+	indicator := NewProgressIndicator(config)
+	indicator.Start(4)
+	indicator.Update(1, "working proxy")
+	indicator.Update(2, "failed proxy check")
+	if output := buf.String(); output != "" {
+		t.Errorf("Expected no line before the log interval, got: %q", output)
+	}
+	indicator.Update(3, "working proxy")
+	indicator.Update(4, "working proxy")
+	output := buf.String()
+	if strings.Contains(output, "\r") {
+		t.Errorf("Expected no carriage returns when not a terminal, got: %q", output)
+	}
+	if !strings.Contains(output, "4/4 checked  75.0% working  eta 0s\n") {
+		t.Errorf("Expected the final progress line, got: %q", output)
+	}
+
+	buf.Reset()
+	indicator.Finish("Complete")
+	output = buf.String()
+	if strings.Contains(output, "checked") {
+		t.Errorf("Expected the final line not to be repeated, got: %q", output)
+	}
+	if !strings.Contains(output, "3 working") || !strings.Contains(output, "Complete") {
+		t.Errorf("Expected the results and finish message, got: %q", output)
+	}
+
+	// On a terminal the line is redrawn in place and padded over a longer one
+	buf.Reset()
+	line := &LineIndicator{config: config}
+	line.Start(100)
+	line.tty = true
+	line.Update(10, "working proxy")
+	line.Update(11, "failed proxy check")
+	output = buf.String()
+	parts := strings.Split(output, "\r")
+	if len(parts) != 3 || strings.Contains(output, "\n") {
+		t.Fatalf("Expected two in-place updates, got: %q", output)
+	}
+	if !strings.HasPrefix(parts[1], "10/100 checked  10.0% working  eta ") {
+		t.Errorf("Unexpected progress line: %q", parts[1])
+	}
+	// "9.1%" is shorter than "10.0%", the rest of the old line is blanked
+	if len(parts[2]) != len(parts[1]) || !strings.HasSuffix(parts[2], " ") {
+		t.Errorf("Expected the shorter line to be padded, got: %q", parts[2])
+	}
+}
+
 func TestStatsCalculation(t *testing.T) {
 	var buf bytes.Buffer
 	config := Config{
@@ -397,6 +455,8 @@ func getTypeName(v interface{}) string {
 		return "*progress.DotsIndicator"
 	case *PercentIndicator:
 		return "*progress.PercentIndicator"
+	case *LineIndicator:
+		return "*progress.LineIndicator"
 	default:
 		return "unknown"
 	}
@@ -0,0 +1,95 @@
+package progress
+
+import "time"
+
+// DefaultRateWindow is how far back RateEstimator looks when no window is set
+const DefaultRateWindow = 30 * time.Second
+
+// rateResolution is how many samples per window RateEstimator keeps at most;
+// updates closer together than window/rateResolution share a sample
+const rateResolution = 100
+
+// RateEstimator estimates throughput (proxies per second) over a sliding
+// window of recent progress, so the ETA follows a scan that speeds up or
+// slows down instead of being biased by its first, slowest proxies. The zero
+// value uses DefaultRateWindow. It is not safe for concurrent use.
+type RateEstimator struct {
+	window  time.Duration
+	samples []rateSample // Oldest first; samples[0] is the anchor at or before the window start
+}
+
+// rateSample is the completed count at a point in time
+type rateSample struct {
+	at    time.Time
+	count int
+}
+
+// NewRateEstimator returns an estimator averaging over window
+func NewRateEstimator(window time.Duration) *RateEstimator {
+	return &RateEstimator{window: window}
+}
+
+// Record notes that count items were complete at now. Record the start of
+// the run with a count of 0 so early rates are measured from it.
+func (r *RateEstimator) Record(now time.Time, count int) {
+	window := r.windowOrDefault()
+	if n := len(r.samples); n > 1 && now.Sub(r.samples[n-2].at) < window/rateResolution {
+		// Coalesce bursts of updates into the latest sample
+		r.samples[n-1] = rateSample{at: now, count: count}
+	} else {
+		r.samples = append(r.samples, rateSample{at: now, count: count})
+	}
+	r.prune(now)
+}
+
+// Reset forgets the recorded progress and re-anchors the window at now with
+// count items complete, e.g. when a paused run resumes, so the idle time does
+// not drag the rate down
+func (r *RateEstimator) Reset(now time.Time, count int) {
+	r.samples = append(r.samples[:0], rateSample{at: now, count: count})
+}
+
+// Rate returns the throughput in items per second over the window ending at
+// now. Time since the last update counts too, so a stalled run slows down.
+func (r *RateEstimator) Rate(now time.Time) float64 {
+	r.prune(now)
+	if len(r.samples) == 0 {
+		return 0
+	}
+	first, last := r.samples[0], r.samples[len(r.samples)-1]
+	elapsed := now.Sub(first.at).Seconds()
+	if elapsed <= 0 || last.count <= first.count {
+		return 0
+	}
+	return float64(last.count-first.count) / elapsed
+}
+
+// Estimate returns the windowed rate and the time the remaining items take
+// at that rate (0 while the rate is unknown)
+func (r *RateEstimator) Estimate(now time.Time, remaining int) (float64, time.Duration) {
+	rate := r.Rate(now)
+	if rate <= 0 || remaining <= 0 {
+		return rate, 0
+	}
+	return rate, time.Duration(float64(remaining) / rate * float64(time.Second))
+}
+
+// prune drops samples that fell out of the window, keeping the newest one
+// at or before its start as the anchor the rate is measured from
+func (r *RateEstimator) prune(now time.Time) {
+	cutoff := now.Add(-r.windowOrDefault())
+	drop := 0
+	for drop+1 < len(r.samples) && !r.samples[drop+1].at.After(cutoff) {
+		drop++
+	}
+	if drop > 0 {
+		r.samples = append(r.samples[:0], r.samples[drop:]...)
+	}
+}
+
+func (r *RateEstimator) windowOrDefault() time.Duration {
+	if r.window > 0 {
+		return r.window
+	}
+	return DefaultRateWindow
+}
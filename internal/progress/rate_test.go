@@ -0,0 +1,86 @@
+package progress
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRateEstimatorFollowsRecentThroughput(t *testing.T) {
+	start := time.Now()
+	r := NewRateEstimator(10 * time.Second)
+	r.Record(start, 0)
+
+	// One proxy every 10s for a minute, then ten per second
+	count := 0
+	for sec := 1; sec <= 60; sec++ {
+		if sec%10 == 0 {
+			count++
+		}
+		r.Record(start.Add(time.Duration(sec)*time.Second), count)
+	}
+	if rate := r.Rate(start.Add(60 * time.Second)); math.Abs(rate-0.1) > 0.01 {
+		t.Errorf("Expected 0.1/s during the slow phase, got %.3f", rate)
+	}
+
+	for sec := 61; sec <= 80; sec++ {
+		count += 10
+		r.Record(start.Add(time.Duration(sec)*time.Second), count)
+	}
+	now := start.Add(80 * time.Second)
+	rate, eta := r.Estimate(now, 300)
+	if math.Abs(rate-10) > 0.01 {
+		t.Errorf("Expected the rate to follow the speed-up to 10/s, got %.3f", rate)
+	}
+	if eta != 30*time.Second {
+		t.Errorf("Expected 300 remaining at 10/s to take 30s, got %v", eta)
+	}
+
+	// A stall slows the estimate down as time passes without progress
+	if stalled := r.Rate(now.Add(5 * time.Second)); stalled >= rate {
+		t.Errorf("Expected the rate to drop during a stall, got %.3f", stalled)
+	}
+}
+
+func TestRateEstimatorUnknown(t *testing.T) {
+	var r RateEstimator
+	now := time.Now()
+	if rate, eta := r.Estimate(now, 10); rate != 0 || eta != 0 {
+		t.Errorf("Expected no estimate without samples, got %.3f, %v", rate, eta)
+	}
+
+	r.Record(now, 0)
+	if rate, eta := r.Estimate(now.Add(time.Second), 10); rate != 0 || eta != 0 {
+		t.Errorf("Expected no estimate before any progress, got %.3f, %v", rate, eta)
+	}
+}
+
+func TestRateEstimatorReset(t *testing.T) {
+	start := time.Now()
+	r := NewRateEstimator(30 * time.Second)
+	r.Record(start, 0)
+	r.Record(start.Add(5*time.Second), 50)
+
+	// Paused for 20s, then resumed at the same speed
+	resumed := start.Add(25 * time.Second)
+	r.Reset(resumed, 50)
+	r.Record(resumed.Add(5*time.Second), 100)
+	if rate := r.Rate(resumed.Add(5 * time.Second)); math.Abs(rate-10) > 0.01 {
+		t.Errorf("Expected 10/s measured from the resume, got %.3f", rate)
+	}
+}
+
+func TestRateEstimatorBoundedSamples(t *testing.T) {
+	start := time.Now()
+	r := NewRateEstimator(10 * time.Second)
+	r.Record(start, 0)
+	for i := 1; i <= 100000; i++ {
+		r.Record(start.Add(time.Duration(i)*time.Millisecond), i)
+	}
+	if len(r.samples) > rateResolution+3 {
+		t.Errorf("Expected at most %d samples, got %d", rateResolution+3, len(r.samples))
+	}
+	if rate := r.Rate(start.Add(100 * time.Second)); math.Abs(rate-1000) > 20 {
+		t.Errorf("Expected about 1000/s, got %.1f", rate)
+	}
+}
@@ -3,6 +3,7 @@ package proxy
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -14,10 +15,13 @@ type AdvancedChecks struct {
 	TestProtocolSmuggling     bool     `yaml:"test_protocol_smuggling"`
 	TestDNSRebinding          bool     `yaml:"test_dns_rebinding"`
 	TestIPv6                  bool     `yaml:"test_ipv6"`
+	IPv6ValidationURL         string   `yaml:"ipv6_validation_url"`           // AAAA-only endpoint for test_ipv6 (default: DefaultIPv6ValidationURL)
 	TestHTTPMethods           []string `yaml:"test_http_methods"`
 	TestCachePoisoning        bool     `yaml:"test_cache_poisoning"`
 	TestHostHeaderInjection   bool     `yaml:"test_host_header_injection"`
+	TestHostEnforcement       bool     `yaml:"test_host_enforcement"`         // Compare the target's real Host with a wrong Host
 	TestSSRF                  bool     `yaml:"test_ssrf"`
+	TestDNSLeak               bool     `yaml:"test_dns_leak"`                 // Detect proxies that make the client resolve target hostnames (requires Interactsh)
 	DisableInteractsh         bool     `yaml:"disable_interactsh"`            // Set to true to disable Interactsh and use basic checks
 	TestNginxVulnerabilities    bool `yaml:"test_nginx_vulnerabilities"`     // Test for nginx-specific vulnerabilities
 	TestApacheVulnerabilities   bool `yaml:"test_apache_vulnerabilities"`    // Test for Apache mod_proxy vulnerabilities
@@ -25,6 +29,10 @@ type AdvancedChecks struct {
 	TestGenericVulnerabilities  bool `yaml:"test_generic_vulnerabilities"`   // Test for generic proxy misconfigurations
 	TestExtendedVulnerabilities bool `yaml:"test_extended_vulnerabilities"`  // Test for extended/medium-priority vulnerabilities
 	TestVendorVulnerabilities   bool `yaml:"test_vendor_vulnerabilities"`    // Test for vendor-specific vulnerabilities (HAProxy, Squid, Traefik, etc.)
+	DisabledChecks              []string `yaml:"disabled_checks"`            // Vendor/extended checks to skip, by JSON field name (see VulnCheckNames)
+	DisableRedirectChain        bool     `yaml:"disable_redirect_chain"`       // Skip the redirect chain SSRF test, which goes through external redirect helpers
+	RedirectChainHosts          []string `yaml:"redirect_chain_hosts"`         // httpbin-compatible redirect helper hosts (default: DefaultRedirectChainHosts)
+	RedirectChainMaxRedirects   int      `yaml:"redirect_chain_max_redirects"` // Redirects followed by the redirect chain test (0 = DefaultRedirectChainMaxRedirects)
 }
 
 // AdvancedCheckResult represents the result of advanced security checks
@@ -35,6 +43,7 @@ type AdvancedCheckResult struct {
 	HTTPMethods         []*CheckResult
 	CachePoisoning      *CheckResult
 	HostHeaderInjection *CheckResult
+	HostEnforcement     *CheckResult
 	SSRF                *CheckResult
 }
 
@@ -71,7 +80,7 @@ func (c *Checker) performAdvancedChecks(client *http.Client, result *ProxyResult
 	}
 
 	// Protocol Smuggling Test
-	if c.config.AdvancedChecks.TestProtocolSmuggling {
+	if c.config.AdvancedChecks.TestProtocolSmuggling && !c.skipMutatingVulnCheck(VulnCheckProtocolSmuggling, result) {
 		if tester != nil {
 			res, err := tester.PerformInteractshTest(client, c, func(url string) (*http.Request, error) {
 				req, err := http.NewRequest("POST", fmt.Sprintf("http://%s", url), strings.NewReader("test"))
@@ -120,27 +129,18 @@ func (c *Checker) performAdvancedChecks(client *http.Client, result *ProxyResult
 
 	// IPv6 Test
 	if c.config.AdvancedChecks.TestIPv6 {
-		if tester != nil {
-			res, err := tester.PerformInteractshTest(client, c, func(url string) (*http.Request, error) {
-				return http.NewRequest("GET", fmt.Sprintf("http://[%s]", url), nil)
-			})
-			if err == nil {
-				advancedResults.IPv6 = res
-				result.CheckResults = append(result.CheckResults, *res)
-			}
-		} else {
-			if res, err := c.checkIPv6Support(client, testDomain); err == nil {
-				advancedResults.IPv6 = res
-				result.CheckResults = append(result.CheckResults, *res)
-			}
+		res := c.checkIPv6Connectivity(client, result)
+		advancedResults.IPv6 = res
+		if res.StatusCode > 0 {
+			result.CheckResults = append(result.CheckResults, *res)
 		}
 	}
 
 	// HTTP Methods Test
-	if len(c.config.AdvancedChecks.TestHTTPMethods) > 0 {
+	if methods := c.vulnTestMethods(result); len(methods) > 0 {
 		var results []*CheckResult
 		if tester != nil {
-			for _, method := range c.config.AdvancedChecks.TestHTTPMethods {
+			for _, method := range methods {
 				res, err := tester.PerformInteractshTest(client, c, func(url string) (*http.Request, error) {
 					return http.NewRequest(method, fmt.Sprintf("http://%s", url), nil)
 				})
@@ -183,6 +183,16 @@ func (c *Checker) performAdvancedChecks(client *http.Client, result *ProxyResult
 		}
 	}
 
+	// Host Enforcement Test (also the baseline for host header injection)
+	if c.config.AdvancedChecks.TestHostEnforcement || c.config.AdvancedChecks.TestHostHeaderInjection {
+		if res, err := c.checkHostEnforcement(client, result); err == nil {
+			advancedResults.HostEnforcement = res
+			result.CheckResults = append(result.CheckResults, *res)
+		} else if c.debug {
+			result.DebugInfo += fmt.Sprintf("[HOST ENFORCEMENT] Check failed: %v\n", err)
+		}
+	}
+
 	// Host Header Injection Test
 	if c.config.AdvancedChecks.TestHostHeaderInjection {
 		if tester != nil {
@@ -218,12 +228,24 @@ func (c *Checker) performAdvancedChecks(client *http.Client, result *ProxyResult
 		}
 	}
 
+	// DNS Leak Test (needs Interactsh to see which resolver queried the hostname)
+	if c.config.AdvancedChecks.TestDNSLeak {
+		if tester != nil {
+			c.checkDNSLeak(client, tester, result)
+		} else if c.debug {
+			result.DebugInfo += "[DNS LEAK] Skipped, Interactsh is not available\n"
+		}
+	}
+
+	// Path-enumeration vuln probes only touch paths within the configured scope
+	vulnClient := c.scopeVulnPaths(client, result)
+
 	// Nginx Vulnerability Tests
 	if c.config.AdvancedChecks.TestNginxVulnerabilities {
 		if c.debug {
 			result.DebugInfo += "[NGINX VULNS] Running nginx-specific vulnerability checks\n"
 		}
-		nginxResults := c.performNginxVulnerabilityChecks(client, result)
+		nginxResults := c.performNginxVulnerabilityChecks(vulnClient, result)
 		result.NginxVulnerabilities = nginxResults
 
 		if c.debug {
@@ -237,7 +259,7 @@ func (c *Checker) performAdvancedChecks(client *http.Client, result *ProxyResult
 		if c.debug {
 			result.DebugInfo += "[APACHE VULNS] Running Apache mod_proxy vulnerability checks\n"
 		}
-		apacheResults := c.performApacheVulnerabilityChecks(client, result)
+		apacheResults := c.performApacheVulnerabilityChecks(vulnClient, result)
 		result.ApacheVulnerabilities = apacheResults
 
 		if c.debug {
@@ -251,7 +273,7 @@ func (c *Checker) performAdvancedChecks(client *http.Client, result *ProxyResult
 		if c.debug {
 			result.DebugInfo += "[KONG VULNS] Running Kong API Gateway vulnerability checks\n"
 		}
-		kongResults := c.performKongVulnerabilityChecks(client, result)
+		kongResults := c.performKongVulnerabilityChecks(vulnClient, result)
 		result.KongVulnerabilities = kongResults
 
 		if c.debug {
@@ -265,7 +287,7 @@ func (c *Checker) performAdvancedChecks(client *http.Client, result *ProxyResult
 		if c.debug {
 			result.DebugInfo += "[GENERIC VULNS] Running generic proxy misconfiguration checks\n"
 		}
-		genericResults := c.performGenericVulnerabilityChecks(client, result)
+		genericResults := c.performGenericVulnerabilityChecks(vulnClient, result)
 		result.GenericVulnerabilities = genericResults
 
 		if c.debug {
@@ -280,7 +302,7 @@ func (c *Checker) performAdvancedChecks(client *http.Client, result *ProxyResult
 		if c.debug {
 			result.DebugInfo += "[EXTENDED VULNS] Running extended vulnerability checks\n"
 		}
-		extendedResults := c.performExtendedVulnerabilityChecks(client, result)
+		extendedResults := c.performExtendedVulnerabilityChecks(vulnClient, result)
 		result.ExtendedVulnerabilities = extendedResults
 
 		if c.debug {
@@ -295,7 +317,7 @@ func (c *Checker) performAdvancedChecks(client *http.Client, result *ProxyResult
 		if c.debug {
 			result.DebugInfo += "[VENDOR VULNS] Running vendor-specific vulnerability checks\n"
 		}
-		vendorResults := c.performVendorVulnerabilityChecks(client, result)
+		vendorResults := c.performVendorVulnerabilityChecks(vulnClient, result)
 		result.VendorVulnerabilities = vendorResults
 
 		if c.debug {
@@ -320,7 +342,9 @@ func (c *Checker) hasAdvancedChecks() bool {
 		len(checks.TestHTTPMethods) > 0 ||
 		checks.TestCachePoisoning ||
 		checks.TestHostHeaderInjection ||
+		checks.TestHostEnforcement ||
 		checks.TestSSRF ||
+		checks.TestDNSLeak ||
 		checks.TestNginxVulnerabilities ||
 		checks.TestApacheVulnerabilities ||
 		checks.TestKongVulnerabilities ||
@@ -426,40 +450,11 @@ func (c *Checker) checkDNSRebinding(client *http.Client, testDomain string) (*Ch
 	return result, nil
 }
 
-func (c *Checker) checkIPv6Support(client *http.Client, testDomain string) (*CheckResult, error) {
-	result := &CheckResult{
-		URL:     fmt.Sprintf("http://[%s]", testDomain),
-		Success: false,
-	}
-
-	// Apply rate limiting
-	c.applyRateLimit(testDomain, &ProxyResult{DebugInfo: ""})
-
-	req, err := http.NewRequest("GET", result.URL, nil)
-	if err != nil {
-		return result, err
-	}
-
-	start := time.Now()
-	resp, err := client.Do(req)
-	if err != nil {
-		result.Error = err.Error()
-		return result, err
-	}
-	defer resp.Body.Close()
-
-	result.Speed = time.Since(start)
-	result.StatusCode = resp.StatusCode
-	result.Success = resp.StatusCode < 400
-
-	return result, nil
-}
-
 func (c *Checker) checkHTTPMethods(client *http.Client, testDomain string) ([]*CheckResult, error) {
 	var results []*CheckResult
 	baseURL := fmt.Sprintf("http://%s", testDomain)
 
-	for _, method := range c.config.AdvancedChecks.TestHTTPMethods {
+	for _, method := range c.vulnTestMethods(nil) {
 		// Apply rate limiting between method tests
 		c.applyRateLimit(testDomain, &ProxyResult{DebugInfo: ""})
 
@@ -526,6 +521,68 @@ func (c *Checker) checkCachePoisoning(client *http.Client, testDomain string) (*
 	return result, nil
 }
 
+// wrongHostHeader is a Host value that no real site should answer for
+const wrongHostHeader = "proxyhawk-host-check.invalid"
+
+// checkHostEnforcement requests the validation URL once with its real Host and
+// once with a wrong Host. A proxy that answers the first but rejects the second
+// only serves configured hosts, which is typical of CDNs and reverse proxies.
+func (c *Checker) checkHostEnforcement(client *http.Client, result *ProxyResult) (*CheckResult, error) {
+	checkResult := &CheckResult{
+		URL: c.config.ValidationURL,
+	}
+
+	realStatus, err := c.requestWithHost(client, "", result)
+	if err != nil {
+		return nil, fmt.Errorf("request with real Host failed: %w", err)
+	}
+	checkResult.StatusCode = realStatus
+
+	wrongStatus, wrongErr := c.requestWithHost(client, wrongHostHeader, result)
+
+	// Enforcement means the real Host works and the wrong one is refused or answered differently
+	realWorks := realStatus < 400
+	wrongRejected := wrongErr != nil || wrongStatus >= 400 || wrongStatus != realStatus
+	result.EnforcesHost = realWorks && wrongRejected
+	checkResult.Success = true
+
+	if c.debug {
+		wrongOutcome := fmt.Sprintf("%d", wrongStatus)
+		if wrongErr != nil {
+			wrongOutcome = wrongErr.Error()
+		}
+		result.DebugInfo += fmt.Sprintf("[HOST ENFORCEMENT] Real Host: %d, wrong Host: %s, enforces Host: %t\n",
+			realStatus, wrongOutcome, result.EnforcesHost)
+	}
+
+	return checkResult, nil
+}
+
+// requestWithHost requests the validation URL, overriding the Host header when host is set
+func (c *Checker) requestWithHost(client *http.Client, host string, result *ProxyResult) (int, error) {
+	c.applyRateLimit(c.config.ValidationURL, result)
+
+	req, err := http.NewRequest("GET", c.config.ValidationURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	if host != "" {
+		req.Host = host
+	}
+	if c.config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
+
 func (c *Checker) checkHostHeaderInjection(client *http.Client, testDomain string) (*CheckResult, error) {
 	result := &CheckResult{
 		URL:     fmt.Sprintf("http://%s", testDomain),
@@ -19,6 +19,7 @@ type AuthMethod string
 const (
 	AuthMethodBasic  AuthMethod = "basic"
 	AuthMethodDigest AuthMethod = "digest"
+	AuthMethodNTLM   AuthMethod = "ntlm"
 )
 
 // ProxyAuth holds authentication credentials for a proxy
@@ -102,11 +103,21 @@ func (c *Checker) cleanProxyURL(proxyURL *url.URL) string {
 	return cleanURL.String()
 }
 
+// RedactProxyURL masks the password of a proxy URL taken from the proxy list,
+// so per-proxy credentials never show up in logs or debug output
+func RedactProxyURL(proxyURL string) string {
+	parsed, err := url.Parse(proxyURL)
+	if err != nil || parsed.User == nil {
+		return proxyURL
+	}
+	return parsed.Redacted()
+}
+
 // createAuthenticatedHTTPTransport creates an HTTP transport with proxy authentication
 func (c *Checker) createAuthenticatedHTTPTransport(proxyURL *url.URL, scheme string, auth *ProxyAuth, result *ProxyResult) *http.Transport {
 	transport := &http.Transport{
-		TLSHandshakeTimeout:   c.config.Timeout / 2,
-		ResponseHeaderTimeout: c.config.Timeout / 2,
+		TLSHandshakeTimeout:   c.timeout(result) / 2,
+		ResponseHeaderTimeout: c.timeout(result) / 2,
 		ExpectContinueTimeout: 1 * time.Second,
 		MaxIdleConns:          100,
 		MaxIdleConnsPerHost:   10,
@@ -115,6 +126,16 @@ func (c *Checker) createAuthenticatedHTTPTransport(proxyURL *url.URL, scheme str
 		ForceAttemptHTTP2:     false,
 	}
 
+	// NTLM authenticates the tunnel connection, so dial it ourselves
+	if c.useNTLM(scheme, auth) {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[AUTH] Using NTLM-capable %s tunnel for %s\n",
+				scheme, c.cleanProxyURL(proxyURL))
+		}
+		transport.DialContext = c.ntlmTunnelDialer(proxyURL, scheme, auth, result)
+		return transport
+	}
+
 	if auth != nil {
 		// Set up authenticated proxy
 		proxyURLWithAuth := &url.URL{
@@ -146,6 +167,10 @@ func (c *Checker) createAuthenticatedHTTPTransport(proxyURL *url.URL, scheme str
 		}
 	}
 
+	if c.config.BaseDialer != nil {
+		transport.DialContext = c.config.BaseDialer
+	}
+
 	return transport
 }
 
@@ -172,12 +197,28 @@ func (c *Checker) createAuthenticatedSOCKSDialer(proxyURL *url.URL, scheme strin
 		}
 	}
 
+	// Reach the SOCKS proxy itself through the base dialer when one is set
+	if c.config.BaseDialer != nil {
+		dialFunc = c.baseSOCKSDialer(proxyURL.Host, scheme, auth, c.timeout(result))
+	}
+
 	return func(ctx context.Context, network, addr string) (net.Conn, error) {
 		if c.debug {
 			result.DebugInfo += fmt.Sprintf("[AUTH] Dialing %s address: %s through %s proxy\n",
 				network, addr, scheme)
 		}
 
+		// SOCKS4 resolves the target locally; reuse the cached address when enabled
+		if scheme == "socks4" && c.config.ResolveOnce {
+			if host, port, err := net.SplitHostPort(addr); err == nil {
+				ip, err := c.resolveTargetIPv4(host, result)
+				if err != nil {
+					return nil, err
+				}
+				addr = net.JoinHostPort(ip, port)
+			}
+		}
+
 		conn, err := dialFunc(network, addr)
 		if err != nil && c.debug {
 			result.DebugInfo += fmt.Sprintf("[AUTH] Dial error: %v\n", err)
@@ -201,6 +242,7 @@ func (c *Checker) validateAuthConfig() {
 	validMethods := map[string]bool{
 		string(AuthMethodBasic):  true,
 		string(AuthMethodDigest): true,
+		string(AuthMethodNTLM):   true,
 	}
 
 	filteredMethods := make([]string, 0, len(c.config.AuthMethods))
@@ -0,0 +1,175 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	xproxy "golang.org/x/net/proxy"
+)
+
+// dialContext opens a connection with the configured BaseDialer, or directly
+// when none is set
+func (c *Checker) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if c.config.BaseDialer != nil {
+		return c.config.BaseDialer(ctx, network, addr)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// dialTimeout is net.DialTimeout through the base dialer
+func (c *Checker) dialTimeout(network, addr string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return c.dialContext(ctx, network, addr)
+}
+
+// dialTLS is tls.Dial through the base dialer
+func (c *Checker) dialTLS(addr string, config *tls.Config) (*tls.Conn, error) {
+	if c.config.BaseDialer == nil {
+		return tls.Dial("tcp", addr, config)
+	}
+	return c.dialTLSContext(context.Background(), "tcp", addr, config)
+}
+
+// dialTLSContext opens a TLS connection to addr through the base dialer
+func (c *Checker) dialTLSContext(ctx context.Context, network, addr string, config *tls.Config) (*tls.Conn, error) {
+	conn, err := c.dialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// directClient returns a client for requests the checker makes without a
+// proxy, such as reputation lookups, dialing through the base dialer
+func (c *Checker) directClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext:         c.dialContext,
+			TLSHandshakeTimeout: timeout,
+			DisableKeepAlives:   true,
+		},
+		Timeout: timeout,
+	}
+}
+
+// baseDialer adapts the checker's base dialer to golang.org/x/net/proxy
+type baseDialer struct {
+	c       *Checker
+	timeout time.Duration
+}
+
+func (d baseDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.c.dialTimeout(network, addr, d.timeout)
+}
+
+func (d baseDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return d.c.dialContext(ctx, network, addr)
+}
+
+// baseSOCKSDialer returns a SOCKS dial function whose connection to the proxy
+// goes through the base dialer. It replaces h12.io/socks, which always dials
+// the proxy directly, when a BaseDialer is configured.
+func (c *Checker) baseSOCKSDialer(proxyHost, scheme string, auth *ProxyAuth, timeout time.Duration) func(string, string) (net.Conn, error) {
+	forward := baseDialer{c: c, timeout: timeout}
+
+	if scheme == "socks5" {
+		var socksAuth *xproxy.Auth
+		if auth != nil {
+			socksAuth = &xproxy.Auth{User: auth.Username, Password: auth.Password}
+		}
+		dialer, err := xproxy.SOCKS5("tcp", proxyHost, socksAuth, forward)
+		if err != nil {
+			return func(string, string) (net.Conn, error) { return nil, err }
+		}
+		return dialer.Dial
+	}
+
+	return func(network, addr string) (net.Conn, error) {
+		conn, err := forward.Dial("tcp", proxyHost)
+		if err != nil {
+			return nil, err
+		}
+		userID := ""
+		if auth != nil {
+			userID = auth.Username
+		}
+		if err := socks4Handshake(conn, addr, scheme == "socks4a", userID, c.resolver(), timeout); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+}
+
+// socks4Handshake asks a SOCKS4 proxy on conn to connect to addr. SOCKS4a
+// passes the hostname to the proxy; plain SOCKS4 resolves it with resolver.
+func socks4Handshake(conn net.Conn, addr string, remoteResolve bool, userID string, resolver *net.Resolver, timeout time.Duration) error {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid port in %s", addr)
+	}
+
+	ip := net.ParseIP(host).To4()
+	if ip == nil && !remoteResolve {
+		ips, err := resolver.LookupIP(context.Background(), "ip", host)
+		if err != nil {
+			return err
+		}
+		for _, candidate := range ips {
+			if ip = candidate.To4(); ip != nil {
+				break
+			}
+		}
+		if ip == nil {
+			return fmt.Errorf("socks4: no IPv4 address for %s", host)
+		}
+	}
+
+	req := []byte{0x04, 0x01, 0, 0}
+	binary.BigEndian.PutUint16(req[2:], uint16(port))
+	if ip == nil {
+		// SOCKS4a: an address of 0.0.0.x tells the proxy a hostname follows
+		req = append(req, 0, 0, 0, 1)
+	} else {
+		req = append(req, ip...)
+	}
+	req = append(req, userID...)
+	req = append(req, 0)
+	if ip == nil {
+		req = append(req, host...)
+		req = append(req, 0)
+	}
+
+	conn.SetDeadline(time.Now().Add(timeout))
+	defer conn.SetDeadline(time.Time{})
+
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+	resp := make([]byte, 8)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return err
+	}
+	if resp[1] != 0x5a {
+		return fmt.Errorf("socks4: connect to %s rejected (code 0x%02x)", addr, resp[1])
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBaseDialerUsedForHTTPProxy(t *testing.T) {
+	// The test server answers the absolute-form request a proxy would get
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.String()))
+	}))
+	defer proxyServer.Close()
+
+	var dials int32
+	checker := NewChecker(Config{
+		Timeout: 2 * time.Second,
+		BaseDialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			atomic.AddInt32(&dials, 1)
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}, false, nil)
+
+	proxyURL, _ := url.Parse(proxyServer.URL)
+	result := &ProxyResult{}
+	client, err := checker.createClient(proxyURL, "http", result)
+	if err != nil {
+		t.Fatalf("createClient() error: %v", err)
+	}
+
+	resp, err := client.Get("http://target.example/")
+	if err != nil {
+		t.Fatalf("Request through the proxy failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if atomic.LoadInt32(&dials) == 0 {
+		t.Error("Expected the proxy connection to go through the base dialer")
+	}
+	if string(body) != "http://target.example/" {
+		t.Errorf("Expected the request to reach the proxy, got %q", body)
+	}
+}
+
+func TestSOCKS4Handshake(t *testing.T) {
+	tests := []struct {
+		name          string
+		addr          string
+		remoteResolve bool
+		reply         byte
+		wantRequest   []byte
+		wantErr       bool
+	}{
+		{
+			name:        "socks4 with an IP",
+			addr:        "10.1.2.3:80",
+			reply:       0x5a,
+			wantRequest: []byte{0x04, 0x01, 0x00, 0x50, 10, 1, 2, 3, 'u', 0},
+		},
+		{
+			name:          "socks4a sends the hostname",
+			addr:          "example.com:443",
+			remoteResolve: true,
+			reply:         0x5a,
+			wantRequest:   append([]byte{0x04, 0x01, 0x01, 0xbb, 0, 0, 0, 1, 'u', 0}, append([]byte("example.com"), 0)...),
+		},
+		{
+			name:    "rejected",
+			addr:    "10.1.2.3:80",
+			reply:   0x5b,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+
+			requests := make(chan []byte, 1)
+			go func() {
+				defer server.Close()
+				buf := make([]byte, 256)
+				n, _ := server.Read(buf)
+				requests <- buf[:n]
+				server.Write([]byte{0x00, tt.reply, 0, 0, 0, 0, 0, 0})
+			}()
+
+			err := socks4Handshake(client, tt.addr, tt.remoteResolve, "u", net.DefaultResolver, time.Second)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected a rejected handshake to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("socks4Handshake() error: %v", err)
+			}
+			if got := <-requests; !bytes.Equal(got, tt.wantRequest) {
+				t.Errorf("Request = %v, want %v", got, tt.wantRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultMaxResponseBytes caps validation bodies when MaxResponseBytes is unset
+	DefaultMaxResponseBytes = 10 << 20
+
+	// UnboundedSizeLimit marks a body that was still going past MaxResponseBytes
+	UnboundedSizeLimit = "size_limit"
+	// UnboundedReadTimeout marks a body that was still streaming after BodyReadTimeout
+	UnboundedReadTimeout = "read_timeout"
+)
+
+// unboundedBodyError reports a response body that was cut off, typically a
+// chunked stream from a misbehaving proxy that never sends its final chunk
+type unboundedBodyError struct {
+	reason string // UnboundedSizeLimit or UnboundedReadTimeout
+	read   int    // Bytes read before giving up
+}
+
+func (e *unboundedBodyError) Error() string {
+	if e.reason == UnboundedSizeLimit {
+		return fmt.Sprintf("response body exceeds the size limit (%d bytes read)", e.read)
+	}
+	return fmt.Sprintf("response body still streaming after the read timeout (%d bytes read)", e.read)
+}
+
+// readBody reads a response body of unknown length, such as a chunked
+// response without Content-Length, and gives up once it is larger than
+// MaxResponseBytes or still streaming after BodyReadTimeout. Giving up
+// returns an *unboundedBodyError and records its reason on the result.
+func (c *Checker) readBody(body io.ReadCloser, result *ProxyResult) ([]byte, error) {
+	maxBytes := c.config.MaxResponseBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxResponseBytes
+	}
+	timeout := c.config.BodyReadTimeout
+	if timeout <= 0 {
+		timeout = c.timeout(result)
+	}
+
+	// Closing the body unblocks a pending Read once the deadline passes
+	var expired atomic.Bool
+	timer := time.AfterFunc(timeout, func() {
+		expired.Store(true)
+		body.Close()
+	})
+	defer timer.Stop()
+
+	data, err := io.ReadAll(io.LimitReader(body, maxBytes+1))
+	switch {
+	case expired.Load():
+		err = &unboundedBodyError{reason: UnboundedReadTimeout, read: len(data)}
+	case int64(len(data)) > maxBytes:
+		data = data[:maxBytes]
+		err = &unboundedBodyError{reason: UnboundedSizeLimit, read: len(data)}
+	}
+
+	if unbounded, ok := err.(*unboundedBodyError); ok {
+		result.UnboundedResponse = unbounded.reason
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[BODY] Gave up reading response: %v\n", err)
+		}
+	}
+	return data, err
+}
+
+// cancelOnClose releases a request's context once its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close implements io.Closer
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,101 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/errors"
+)
+
+// endlessChunks streams chunks until the client goes away, like a proxy that
+// never sends the final chunk of a chunked response
+func endlessChunks(w http.ResponseWriter, r *http.Request, delay time.Duration) {
+	flusher := w.(http.Flusher)
+	chunk := strings.Repeat("x", 1024)
+	for {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			return
+		}
+		flusher.Flush()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+func TestPerformChecksUnboundedResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		delay  time.Duration
+		config Config
+		want   string
+	}{
+		{"fast endless stream", 0, Config{MaxResponseBytes: 64 * 1024, BodyReadTimeout: 5 * time.Second}, UnboundedSizeLimit},
+		{"slow endless stream", 10 * time.Millisecond, Config{MaxResponseBytes: 1 << 30, BodyReadTimeout: 200 * time.Millisecond}, UnboundedReadTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				endlessChunks(w, r, tt.delay)
+			}))
+			defer target.Close()
+
+			tt.config.Timeout = 10 * time.Second
+			tt.config.ValidationURL = target.URL
+			checker := NewChecker(tt.config, false, nil)
+
+			result := &ProxyResult{}
+			start := time.Now()
+			err := checker.performChecks(http.DefaultClient, result)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Fatalf("performChecks() took %v on an endless stream", elapsed)
+			}
+
+			if err == nil {
+				t.Fatal("performChecks() accepted an endless stream")
+			}
+			proxyErr, ok := err.(*errors.ProxyError)
+			if !ok || proxyErr.Details["reason"] != tt.want {
+				t.Errorf("performChecks() error = %v, want reason %q", err, tt.want)
+			}
+			if result.UnboundedResponse != tt.want {
+				t.Errorf("UnboundedResponse = %q, want %q", result.UnboundedResponse, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadBodyChunkedWithinLimits(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Flushing before the end forces chunked encoding without Content-Length
+		w.Write([]byte("hello "))
+		w.(http.Flusher).Flush()
+		w.Write([]byte("world"))
+	}))
+	defer target.Close()
+
+	resp, err := http.Get(target.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.ContentLength != -1 {
+		t.Fatalf("ContentLength = %d, want a chunked response", resp.ContentLength)
+	}
+
+	checker := NewChecker(Config{Timeout: time.Second}, false, nil)
+	result := &ProxyResult{}
+	body, err := checker.readBody(resp.Body, result)
+	if err != nil || string(body) != "hello world" {
+		t.Errorf("readBody() = %q, %v; want \"hello world\"", body, err)
+	}
+	if result.UnboundedResponse != "" {
+		t.Errorf("UnboundedResponse = %q for a finished body", result.UnboundedResponse)
+	}
+}
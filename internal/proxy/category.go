@@ -0,0 +1,55 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// defaultCategoryURLs are the representative sites checked per category when
+// no categories are configured
+var defaultCategoryURLs = map[string][]string{
+	"social":    {"https://www.facebook.com/", "https://www.reddit.com/"},
+	"adult":     {"https://www.pornhub.com/", "https://www.xvideos.com/"},
+	"news":      {"https://www.bbc.com/news", "https://www.cnn.com/"},
+	"streaming": {"https://www.youtube.com/", "https://www.netflix.com/"},
+}
+
+// checkCategoryAccess requests the representative URLs of each site category
+// through the proxy and records in result.CategoryAccess whether the category
+// is reachable. A category counts as reachable when any of its URLs answers
+// with a 2xx status; redirects do not count, since filtering proxies commonly
+// redirect blocked sites to a block page.
+func (c *Checker) checkCategoryAccess(client *http.Client, result *ProxyResult) {
+	categories := c.config.CategoryURLs
+	if len(categories) == 0 {
+		categories = defaultCategoryURLs
+	}
+
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result.CategoryAccess = make(map[string]bool, len(names))
+	for _, name := range names {
+		reachable := false
+		for _, categoryURL := range categories[name] {
+			check := c.fetchValidationURL(client, categoryURL, result)
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[CATEGORY] %s %s: status=%d %s\n",
+					name, categoryURL, check.StatusCode, check.Error)
+			}
+			if check.StatusCode >= 200 && check.StatusCode < 300 {
+				reachable = true
+				break
+			}
+		}
+		result.CategoryAccess[name] = reachable
+	}
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[CATEGORY] Access: %v\n", result.CategoryAccess)
+	}
+}
@@ -0,0 +1,59 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckCategoryAccess(t *testing.T) {
+	// Stands in for a target reached through a proxy that filters /adult
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/adult":
+			w.WriteHeader(http.StatusForbidden)
+		case "/news-down":
+			w.WriteHeader(http.StatusBadGateway)
+		case "/social-blocked":
+			// Filtering proxies often redirect to a block page
+			http.Redirect(w, r, "/blocked", http.StatusFound)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{
+		Timeout: time.Second,
+		CategoryURLs: map[string][]string{
+			"adult":  {server.URL + "/adult"},
+			"news":   {server.URL + "/news-down", server.URL + "/news"},
+			"social": {server.URL + "/social-blocked"},
+			"video":  {server.URL + "/video"},
+		},
+	}, true, nil)
+
+	// Like the checker's own clients, don't follow the redirect
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	result := &ProxyResult{}
+	checker.checkCategoryAccess(client, result)
+
+	want := map[string]bool{"adult": false, "news": true, "social": false, "video": true}
+	if len(result.CategoryAccess) != len(want) {
+		t.Fatalf("Expected %d categories, got %v", len(want), result.CategoryAccess)
+	}
+	for name, reachable := range want {
+		if result.CategoryAccess[name] != reachable {
+			t.Errorf("CategoryAccess[%s] = %t, want %t", name, result.CategoryAccess[name], reachable)
+		}
+	}
+	if len(result.CheckResults) != 0 {
+		t.Errorf("Expected category requests to stay out of CheckResults, got %d", len(result.CheckResults))
+	}
+}
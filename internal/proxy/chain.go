@@ -0,0 +1,226 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	xproxy "golang.org/x/net/proxy"
+)
+
+// chainHandshakeTimeout bounds a jump proxy handshake when the caller sets no deadline
+const chainHandshakeTimeout = 10 * time.Second
+
+// DialFunc opens a network connection, like net.Dialer.DialContext
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// ChainHopError reports which link of a proxy chain failed: hop 1 is the
+// connection to the jump proxy, hop 2 the jump proxy's connection onwards
+type ChainHopError struct {
+	Hop  int
+	Jump string // Jump proxy URL without credentials
+	Addr string // Address the jump proxy was asked to reach
+	Err  error
+}
+
+func (e *ChainHopError) Error() string {
+	if e.Hop == 1 {
+		return fmt.Sprintf("proxy chain hop 1: jump proxy %s is down: %v", e.Jump, e.Err)
+	}
+	return fmt.Sprintf("proxy chain hop 2: jump proxy %s could not reach %s: %v", e.Jump, e.Addr, e.Err)
+}
+
+func (e *ChainHopError) Unwrap() error {
+	return e.Err
+}
+
+// chainDialer reaches addresses through a jump proxy, which it connects to
+// with forward
+type chainDialer struct {
+	jump     *url.URL
+	forward  DialFunc
+	resolver *net.Resolver // Resolves destinations for plain SOCKS4 jump proxies
+}
+
+// NewChainDialer returns a dialer that connects through the jump proxy at
+// jumpURL (http, socks4, socks4a or socks5, credentials in the URL), reaching
+// the jump proxy itself with forward (nil dials directly). resolver looks up
+// destinations for plain SOCKS4 jump proxies (nil = the system resolver).
+func NewChainDialer(jumpURL string, forward DialFunc, resolver *net.Resolver) (DialFunc, error) {
+	dialer, err := newChainDialer(jumpURL, forward, resolver)
+	if err != nil {
+		return nil, err
+	}
+	return dialer.DialContext, nil
+}
+
+func newChainDialer(jumpURL string, forward DialFunc, resolver *net.Resolver) (*chainDialer, error) {
+	jump, err := url.Parse(strings.TrimSpace(jumpURL))
+	if err != nil || jump.Host == "" {
+		return nil, fmt.Errorf("invalid proxy chain URL %q", jumpURL)
+	}
+	switch jump.Scheme {
+	case "http", "socks4", "socks4a", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy chain scheme %q: use http, socks4, socks4a or socks5", jump.Scheme)
+	}
+	if jump.Port() == "" {
+		return nil, fmt.Errorf("proxy chain URL %q has no port", RedactProxyURL(jumpURL))
+	}
+
+	if forward == nil {
+		var dialer net.Dialer
+		forward = dialer.DialContext
+	}
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &chainDialer{jump: jump, forward: forward, resolver: resolver}, nil
+}
+
+// DialContext connects to addr through the jump proxy
+func (d *chainDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := d.dialJump(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tunnel, err := d.connect(ctx, conn, addr)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tunnel, nil
+}
+
+// dialJump opens the connection to the jump proxy (hop 1)
+func (d *chainDialer) dialJump(ctx context.Context) (net.Conn, error) {
+	conn, err := d.forward(ctx, "tcp", d.jump.Host)
+	if err != nil {
+		return nil, &ChainHopError{Hop: 1, Jump: d.jump.Redacted(), Err: err}
+	}
+	return conn, nil
+}
+
+// connect asks the jump proxy on conn to open a tunnel to addr (hop 2)
+func (d *chainDialer) connect(ctx context.Context, conn net.Conn, addr string) (net.Conn, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	tunnel, err := d.handshake(ctx, conn, addr)
+	if err != nil {
+		return nil, &ChainHopError{Hop: 2, Jump: d.jump.Redacted(), Addr: addr, Err: err}
+	}
+	return tunnel, nil
+}
+
+// handshake speaks the jump proxy's protocol on conn to reach addr
+func (d *chainDialer) handshake(ctx context.Context, conn net.Conn, addr string) (net.Conn, error) {
+	username := d.jump.User.Username()
+	password, _ := d.jump.User.Password()
+
+	switch d.jump.Scheme {
+	case "socks5":
+		var auth *xproxy.Auth
+		if d.jump.User != nil {
+			auth = &xproxy.Auth{User: username, Password: password}
+		}
+		dialer, err := xproxy.SOCKS5("tcp", d.jump.Host, auth, establishedDialer{conn})
+		if err != nil {
+			return nil, err
+		}
+		return dialer.(xproxy.ContextDialer).DialContext(ctx, "tcp", addr)
+
+	case "socks4", "socks4a":
+		timeout := chainHandshakeTimeout
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+		if err := socks4Handshake(conn, addr, d.jump.Scheme == "socks4a", username, d.resolver, timeout); err != nil {
+			return nil, err
+		}
+		return conn, nil
+	}
+
+	request := fmt.Sprintf("CONNECT %s HTTP/1.1\r\nHost: %s\r\n", addr, addr)
+	if d.jump.User != nil {
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		request += fmt.Sprintf("Proxy-Authorization: Basic %s\r\n", credentials)
+	}
+	request += "\r\n"
+	if _, err := conn.Write([]byte(request)); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("CONNECT %s answered %s", addr, resp.Status)
+	}
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// establishedDialer hands golang.org/x/net/proxy a connection that is
+// already open, so its SOCKS5 handshake runs on the jump proxy connection
+type establishedDialer struct {
+	conn net.Conn
+}
+
+func (d establishedDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.conn, nil
+}
+
+func (d establishedDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return d.conn, nil
+}
+
+// checkChain reaches the proxy through the jump proxy once before checking
+// it, so a broken chain fails with the link that broke instead of a generic
+// connection error
+func (c *Checker) checkChain(proxyURL *url.URL, result *ProxyResult) error {
+	timeout := c.timeout(result)
+	if timeout <= 0 {
+		timeout = chainHandshakeTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn, err := c.chain.dialJump(ctx)
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[CHAIN] Hop 1 failed: %v\n", err)
+		}
+		return err
+	}
+	defer conn.Close()
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[CHAIN] Hop 1 OK: connected to jump proxy %s\n", c.chain.jump.Redacted())
+	}
+
+	tunnel, err := c.chain.connect(ctx, conn, proxyURL.Host)
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[CHAIN] Hop 2 failed: %v\n", err)
+		}
+		return err
+	}
+	tunnel.Close()
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[CHAIN] Hop 2 OK: jump proxy reached %s\n", proxyURL.Host)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startJumpProxy starts a jump proxy that relays CONNECT requests (http) or
+// SOCKS5 connects without authentication (socks5) to their destination
+func startJumpProxy(t *testing.T, scheme string) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+
+				var addr string
+				if scheme == "http" {
+					req, err := http.ReadRequest(reader)
+					if err != nil || req.Method != http.MethodConnect {
+						return
+					}
+					addr = req.Host
+				} else {
+					// Greeting, then VER CMD RSV ATYP(IPv4) ADDR PORT
+					greeting := make([]byte, 2)
+					if _, err := io.ReadFull(reader, greeting); err != nil {
+						return
+					}
+					io.ReadFull(reader, make([]byte, greeting[1]))
+					conn.Write([]byte{0x05, 0x00})
+					req := make([]byte, 10)
+					if _, err := io.ReadFull(reader, req); err != nil || req[3] != 0x01 {
+						return
+					}
+					addr = net.JoinHostPort(net.IP(req[4:8]).String(), strconv.Itoa(int(binary.BigEndian.Uint16(req[8:]))))
+				}
+
+				target, err := net.DialTimeout("tcp", addr, time.Second)
+				if err != nil {
+					if scheme == "http" {
+						conn.Write([]byte("HTTP/1.1 502 Bad Gateway\r\nContent-Length: 0\r\n\r\n"))
+					} else {
+						conn.Write([]byte{0x05, 0x05, 0, 0x01, 0, 0, 0, 0, 0, 0})
+					}
+					return
+				}
+				defer target.Close()
+				if scheme == "http" {
+					conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+				} else {
+					conn.Write([]byte{0x05, 0x00, 0, 0x01, 0, 0, 0, 0, 0, 0})
+				}
+				go io.Copy(target, reader)
+				io.Copy(conn, target)
+			}(conn)
+		}
+	}()
+
+	return listener
+}
+
+// startEchoServer starts a TCP server that echoes what it receives
+func startEchoServer(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}(conn)
+		}
+	}()
+	return listener
+}
+
+// closedAddr returns an address nothing listens on
+func closedAddr(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	return addr
+}
+
+// TestChainDialer tests that connections are relayed through each kind of jump proxy
+func TestChainDialer(t *testing.T) {
+	echo := startEchoServer(t)
+	defer echo.Close()
+
+	for _, scheme := range []string{"http", "socks5"} {
+		t.Run(scheme, func(t *testing.T) {
+			jump := startJumpProxy(t, scheme)
+			defer jump.Close()
+
+			dial, err := NewChainDialer(scheme+"://"+jump.Addr().String(), nil, nil)
+			if err != nil {
+				t.Fatalf("NewChainDialer failed: %v", err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			conn, err := dial(ctx, "tcp", echo.Addr().String())
+			if err != nil {
+				t.Fatalf("Dial through jump proxy failed: %v", err)
+			}
+			defer conn.Close()
+
+			conn.Write([]byte("ping"))
+			reply := make([]byte, 4)
+			if _, err := io.ReadFull(conn, reply); err != nil || string(reply) != "ping" {
+				t.Errorf("Expected the echo through the chain, got %q, %v", reply, err)
+			}
+		})
+	}
+}
+
+// TestNewChainDialerInvalid tests that unusable jump proxy URLs are rejected
+func TestNewChainDialerInvalid(t *testing.T) {
+	for _, spec := range []string{"", "jump:1080", "ftp://jump:1080", "socks5://jump"} {
+		if _, err := NewChainDialer(spec, nil, nil); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+// TestCheckChainHops tests that each hop is reported and a broken link is named
+func TestCheckChainHops(t *testing.T) {
+	jump := startJumpProxy(t, "http")
+	defer jump.Close()
+	proxyListener := startEchoServer(t)
+	defer proxyListener.Close()
+
+	checker := NewChecker(Config{Timeout: 2 * time.Second, ProxyChain: "http://" + jump.Addr().String()}, true, nil)
+	result := &ProxyResult{}
+	proxyURL, _ := url.Parse("http://" + proxyListener.Addr().String())
+	if err := checker.checkChain(proxyURL, result); err != nil {
+		t.Fatalf("Expected the chain to work: %v", err)
+	}
+	if !strings.Contains(result.DebugInfo, "Hop 1 OK") || !strings.Contains(result.DebugInfo, "Hop 2 OK") {
+		t.Errorf("Expected both hops in the debug info, got: %s", result.DebugInfo)
+	}
+
+	// The jump proxy cannot reach the proxy
+	result = &ProxyResult{}
+	proxyURL, _ = url.Parse("http://" + closedAddr(t))
+	err := checker.checkChain(proxyURL, result)
+	var hopErr *ChainHopError
+	if !errors.As(err, &hopErr) || hopErr.Hop != 2 {
+		t.Errorf("Expected a hop 2 error, got %v", err)
+	}
+
+	// The jump proxy is down: the check fails naming the first hop
+	down := NewChecker(Config{Timeout: 2 * time.Second, ProxyChain: "socks5://" + closedAddr(t)}, false, nil)
+	checked := down.Check("http://" + proxyListener.Addr().String())
+	if checked.Working || checked.Error == nil || !strings.Contains(checked.Error.Error(), "hop 1") {
+		t.Errorf("Expected the check to fail at hop 1, got working=%t, error %v", checked.Working, checked.Error)
+	}
+
+	invalid := NewChecker(Config{ProxyChain: "ftp://jump:21"}, false, nil)
+	if checked := invalid.Check("http://" + proxyListener.Addr().String()); checked.Error == nil {
+		t.Error("Expected an invalid proxy chain to fail the check")
+	}
+}
@@ -16,11 +16,30 @@ import (
 
 // NewChecker creates a new proxy checker
 func NewChecker(config Config, debug bool, logger *logging.Logger) *Checker {
+	// Dial proxy hostnames through the configured resolver
+	if config.Resolver != nil && config.BaseDialer == nil {
+		dialer := &net.Dialer{Resolver: config.Resolver}
+		config.BaseDialer = dialer.DialContext
+	}
+
+	// Reach every proxy through the jump proxy of the chain
+	var chain *chainDialer
+	var chainErr error
+	if config.ProxyChain != "" {
+		chain, chainErr = newChainDialer(config.ProxyChain, config.BaseDialer, config.Resolver)
+		if chainErr == nil {
+			config.BaseDialer = chain.DialContext
+		}
+	}
+
 	checker := &Checker{
 		config:      config,
 		debug:       debug,
 		logger:      logger,
 		rateLimiter: make(map[string]time.Time),
+		echoBackoff: make(map[string]time.Time),
+		chain:       chain,
+		chainErr:    chainErr,
 	}
 
 	// Validate and normalize retry configuration
@@ -29,21 +48,48 @@ func NewChecker(config Config, debug bool, logger *logging.Logger) *Checker {
 	// Validate and normalize authentication configuration
 	checker.validateAuthConfig()
 
+	if config.VulnScanConcurrency > 0 {
+		checker.vulnScanSlots = make(chan struct{}, config.VulnScanConcurrency)
+	}
+
 	return checker
 }
 
 // Check validates a proxy and returns detailed information about its functionality
 func (c *Checker) Check(proxyURL string) *ProxyResult {
+	return c.CheckWithTimeout(proxyURL, 0)
+}
+
+// CheckWithTimeout is like Check but uses timeout instead of the configured
+// timeout for this proxy. A zero timeout falls back to the configured one.
+func (c *Checker) CheckWithTimeout(proxyURL string, timeout time.Duration) *ProxyResult {
+	return c.CheckWithOptions(proxyURL, CheckOptions{Timeout: timeout})
+}
+
+// CheckWithOptions is like Check but applies per-proxy options such as a
+// timeout override or an expected exit country
+func (c *Checker) CheckWithOptions(proxyURL string, opts CheckOptions) *ProxyResult {
 	result := &ProxyResult{
-		ProxyURL:      proxyURL,
-		Type:          ProxyTypeUnknown,
-		CheckResults:  []CheckResult{},
-		SupportsHTTP:  false,
-		SupportsHTTPS: false,
+		Timeout:         opts.Timeout,
+		ExpectedCountry: opts.ExpectCountry,
+		ProxyURL:        proxyURL,
+		Type:            ProxyTypeUnknown,
+		CheckResults:    []CheckResult{},
+		SupportsHTTP:    false,
+		SupportsHTTPS:   false,
+	}
+
+	// Hand the finished result to the embedder's hook; deferred first so it
+	// runs after scoring
+	if c.config.ResultHook != nil {
+		defer c.config.ResultHook(result)
 	}
 
+	// Score whatever the check found, whichever way it returns
+	defer c.scoreResult(result)
+
 	if c.debug {
-		result.DebugInfo += fmt.Sprintf("[PROXY CHECK] Starting check for: %s\n", proxyURL)
+		result.DebugInfo += fmt.Sprintf("[PROXY CHECK] Starting check for: %s\n", RedactProxyURL(proxyURL))
 	}
 
 	// Parse the proxy URL
@@ -56,13 +102,48 @@ func (c *Checker) Check(proxyURL string) *ProxyResult {
 		return result
 	}
 
+	// Make sure both links of a proxy chain work, naming the one that broke
+	if c.chainErr != nil {
+		result.Error = errors.NewProxyError(errors.ErrorProxyConnectionFailed, "invalid proxy chain", proxyURL, c.chainErr)
+		return result
+	}
+	if c.chain != nil {
+		if err := c.checkChain(parsedURL, result); err != nil {
+			result.Error = errors.NewProxyError(errors.ErrorProxyConnectionFailed, "proxy chain failed", proxyURL, err)
+			return result
+		}
+	}
+
+	// gRPC targets replace HTTP type detection and validation entirely
+	if c.config.GRPCTarget != "" {
+		return c.checkGRPC(parsedURL, result)
+	}
+
 	// Create a phased approach with clear stage markers in debug output
 	if c.debug {
-		result.DebugInfo += fmt.Sprintf("[PHASE 1/2] Detecting proxy type for %s\n", proxyURL)
+		result.DebugInfo += fmt.Sprintf("[PHASE 1/2] Detecting proxy type for %s\n", RedactProxyURL(proxyURL))
 	}
 
 	// Determine proxy type
 	proxyType, client, err := c.determineProxyType(parsedURL, result)
+	if err != nil && c.useHTTP10() && isPlainHTTPProxy(parsedURL) {
+		// net/http always speaks HTTP/1.1, so a proxy that only handles HTTP/1.0
+		// fails type detection. Retry the validation request as HTTP/1.0.
+		if c.checkHTTP10Support(parsedURL, result) {
+			result.Type = ProxyTypeHTTP
+			result.SupportsHTTP = true
+			result.HTTP10Only = true
+			if err := c.checkRequiredProtocols(result); err != nil {
+				result.Error = errors.NewProxyError(errors.ErrorProxyValidationFailed, "validation failed", proxyURL, err)
+				return result
+			}
+			result.Working = true
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[RESULT] Proxy only works over HTTP/1.0, skipping remaining phases\n")
+			}
+			return result
+		}
+	}
 	if err != nil {
 		// Proxy doesn't work as a forward proxy, but it might still have vulnerabilities
 		// Try direct vulnerability scanning as fallback if advanced checks are enabled
@@ -72,8 +153,12 @@ func (c *Checker) Check(proxyURL string) *ProxyResult {
 			}
 
 			// Try to scan the target as a web server directly
-			if directResult := c.performDirectScan(parsedURL, result); directResult {
+			release := c.acquireVulnScanSlot(result)
+			directResult := c.performDirectScan(parsedURL, result)
+			release()
+			if directResult {
 				// Direct scan found something useful
+				collectDetectedSoftware(result)
 				if c.debug {
 					result.DebugInfo += fmt.Sprintf("[FALLBACK] Direct scan completed with findings\n")
 				}
@@ -81,8 +166,13 @@ func (c *Checker) Check(proxyURL string) *ProxyResult {
 			}
 		}
 
-		// Create a more concise error message
-		result.Error = errors.NewProxyError(errors.ErrorProxyNotWorking, "proxy check failed", proxyURL, err)
+		// Create a more concise error message. A proxy that demanded
+		// credentials we could not satisfy is alive, so say so.
+		if result.AuthRequired {
+			result.Error = errors.NewProxyError(errors.ErrorProxyAuthRequired, "proxy requires authentication", proxyURL, err)
+		} else {
+			result.Error = errors.NewProxyError(errors.ErrorProxyNotWorking, "proxy check failed", proxyURL, err)
+		}
 		if c.debug {
 			result.DebugInfo += fmt.Sprintf("[RESULT] Proxy type detection failed and no vulnerabilities found: %v\n", err)
 		}
@@ -96,6 +186,16 @@ func (c *Checker) Check(proxyURL string) *ProxyResult {
 
 	result.Type = proxyType
 
+	// A proxy that also answered a plain GET through another candidate type is not CONNECT-only
+	if result.SupportsHTTP {
+		result.ConnectOnly = false
+	}
+
+	// Probe CONNECT separately so a failed HTTPS validation can be attributed
+	if proxyType == ProxyTypeHTTP && isPlainHTTPProxy(parsedURL) {
+		c.probeConnect(parsedURL, result)
+	}
+
 	// Perform checks using the determined client
 	if err := c.performChecks(client, result); err != nil {
 		result.Error = errors.NewProxyError(errors.ErrorProxyValidationFailed, "validation failed", proxyURL, err)
@@ -109,12 +209,51 @@ func (c *Checker) Check(proxyURL string) *ProxyResult {
 		result.DebugInfo += fmt.Sprintf("[PHASE 2/2 COMPLETE] Validation successful\n")
 	}
 
+	if c.useHTTP10() && proxyType == ProxyTypeHTTP && isPlainHTTPProxy(parsedURL) {
+		c.checkHTTP10Support(parsedURL, result)
+	}
+
+	if result.ExpectedCountry != "" {
+		c.checkExitCountry(client, result)
+	}
+
+	if c.config.ClassifyProxies {
+		c.classifyProxy(client, result)
+	}
+
+	if c.config.MeasureThroughput {
+		c.measureThroughput(client, result)
+	}
+
+	if c.config.DetectRotation {
+		c.detectRotation(client, result)
+	}
+
+	if c.config.EchoHeaders {
+		c.recordReceivedHeaders(client, result)
+	}
+
+	if c.config.CheckCategories {
+		c.checkCategoryAccess(client, result)
+	}
+
+	if c.config.CheckWebSocket {
+		c.checkWebSocketSupport(client, result)
+	}
+
+	if c.config.CheckSuspicious {
+		c.checkSuspiciousBehavior(parsedURL, client, result)
+	}
+
 	// PHASE 3: Advanced Security Checks (if enabled)
 	if c.hasAdvancedChecks() {
 		if c.debug {
 			result.DebugInfo += fmt.Sprintf("[PHASE 3/3] Running advanced security checks\n")
 		}
-		if err := c.performAdvancedChecks(client, result); err != nil {
+		release := c.acquireVulnScanSlot(result)
+		err := c.performAdvancedChecks(client, result)
+		release()
+		if err != nil {
 			if c.debug {
 				result.DebugInfo += fmt.Sprintf("[PHASE 3/3] Advanced checks encountered error: %v\n", err)
 			}
@@ -130,7 +269,7 @@ func (c *Checker) Check(proxyURL string) *ProxyResult {
 	if c.debug {
 		result.DebugInfo += fmt.Sprintf("[PHASE 4/4] Checking proxy anonymity and chain detection\n")
 	}
-	anonymous, anonLevel, detectedIP, leakingHeaders, chainDetected, chainInfo, anonErr := c.checkAnonymity(client)
+	anonymous, anonLevel, detectedIP, leakingHeaders, chainDetected, chainInfo, anonErr := c.checkAnonymity(client, result)
 	if anonErr == nil {
 		result.IsAnonymous = anonymous
 		result.AnonymityLevel = anonLevel
@@ -151,6 +290,16 @@ func (c *Checker) Check(proxyURL string) *ProxyResult {
 		result.DebugInfo += fmt.Sprintf("[PHASE 4/4] Anonymity check failed: %v\n", anonErr)
 	}
 
+	// The anonymity check has found the exit IP by now, so these reuse it
+	// instead of asking the egress IP endpoint again
+	if c.config.LookupEgressPTR {
+		c.recordEgressPTR(client, result)
+	}
+
+	if c.config.CheckReputation {
+		c.checkReputation(client, result)
+	}
+
 	// PHASE 5: Proxy Fingerprinting (if enabled)
 	if c.config.EnableFingerprint {
 		if c.debug {
@@ -166,8 +315,10 @@ func (c *Checker) Check(proxyURL string) *ProxyResult {
 		}
 	}
 
+	collectDetectedSoftware(result)
+
 	if c.debug {
-		result.DebugInfo += fmt.Sprintf("[SUMMARY] Proxy check results for %s:\n", proxyURL)
+		result.DebugInfo += fmt.Sprintf("[SUMMARY] Proxy check results for %s:\n", RedactProxyURL(proxyURL))
 		result.DebugInfo += fmt.Sprintf("  - Type: %s\n", result.Type)
 		result.DebugInfo += fmt.Sprintf("  - Working: %t\n", result.Working)
 		result.DebugInfo += fmt.Sprintf("  - Speed: %v\n", result.Speed)
@@ -186,14 +337,8 @@ func (c *Checker) determineProxyType(proxyURL *url.URL, result *ProxyResult) (Pr
 	var lastError string
 
 	// Use local validation URLs instead of mutating shared config
-	validationURLHTTP := "http://api.ipify.org?format=json"
-	validationURLHTTPS := "https://api.ipify.org?format=json"
-
-	// Save the original validation URL to restore after testing
-	origValidationURL := c.config.ValidationURL
-	defer func() {
-		c.config.ValidationURL = origValidationURL
-	}()
+	validationURLHTTP := detectionURLHTTP
+	validationURLHTTPS := detectionURLHTTPS
 
 	// First check if the proxy URL already specifies a scheme we can use
 	if proxyURL.Scheme != "" {
@@ -208,6 +353,8 @@ func (c *Checker) determineProxyType(proxyURL *url.URL, result *ProxyResult) (Pr
 			proxyType = ProxyTypeHTTPS
 		case "socks4":
 			proxyType = ProxyTypeSOCKS4
+		case "socks4a":
+			proxyType = ProxyTypeSOCKS4A
 		case "socks5":
 			proxyType = ProxyTypeSOCKS5
 		}
@@ -221,17 +368,20 @@ func (c *Checker) determineProxyType(proxyURL *url.URL, result *ProxyResult) (Pr
 			client, err := c.createClient(proxyURL, scheme, result)
 			if err == nil {
 				// Test with HTTP endpoint
-				c.config.ValidationURL = validationURLHTTP
-				httpSuccess, httpTestErr, httpCheckResult := c.testClientWithDetails(client, proxyType, result)
+				httpSuccess, httpTestErr, httpCheckResult := c.testClientWithDetails(client, proxyType, validationURLHTTP, result)
 
 				// Add the check result to our collection
 				if httpCheckResult != nil {
 					result.CheckResults = append(result.CheckResults, *httpCheckResult)
 				}
 
+				// A refused or unroutable proxy fails every other attempt too
+				if httpCheckResult != nil && httpCheckResult.unreachable {
+					return ProxyTypeUnknown, nil, c.proxyUnreachableError(proxyURL, httpTestErr, result)
+				}
+
 				// Then test with HTTPS endpoint
-				c.config.ValidationURL = validationURLHTTPS
-				httpsSuccess, httpsTestErr, httpsCheckResult := c.testClientWithDetails(client, proxyType, result)
+				httpsSuccess, httpsTestErr, httpsCheckResult := c.testClientWithDetails(client, proxyType, validationURLHTTPS, result)
 
 				// Add the check result to our collection
 				if httpsCheckResult != nil {
@@ -239,6 +389,9 @@ func (c *Checker) determineProxyType(proxyURL *url.URL, result *ProxyResult) (Pr
 				}
 
 				// Set protocol support based on results
+				if proxyType == ProxyTypeHTTP || proxyType == ProxyTypeHTTPS {
+					c.noteConnectOnly(httpCheckResult, httpsSuccess, result)
+				}
 				if httpSuccess {
 					result.SupportsHTTP = true
 					if c.debug {
@@ -277,6 +430,11 @@ func (c *Checker) determineProxyType(proxyURL *url.URL, result *ProxyResult) (Pr
 		}
 	}
 
+	// A configured detection order replaces the default cascade below
+	if len(c.config.DetectionOrder) > 0 {
+		return c.detectInOrder(proxyURL, result)
+	}
+
 	// If URL scheme detection failed, now try protocols in order: HTTP, HTTPS, SOCKS4, SOCKS5
 	// First try HTTP/HTTPS proxies
 	httpProxyCandidates := []struct {
@@ -317,14 +475,18 @@ func (c *Checker) determineProxyType(proxyURL *url.URL, result *ProxyResult) (Pr
 		}
 
 		// Test with HTTP endpoint
-		c.config.ValidationURL = validationURLHTTP
-		httpSuccess, httpTestErr, httpCheckResult := c.testClientWithDetails(client, candidate.proxyType, result)
+		httpSuccess, httpTestErr, httpCheckResult := c.testClientWithDetails(client, candidate.proxyType, validationURLHTTP, result)
 
 		// Add the check result to our collection
 		if httpCheckResult != nil {
 			result.CheckResults = append(result.CheckResults, *httpCheckResult)
 		}
 
+		// A refused or unroutable proxy fails every other attempt too
+		if httpCheckResult != nil && httpCheckResult.unreachable {
+			return ProxyTypeUnknown, nil, c.proxyUnreachableError(proxyURL, httpTestErr, result)
+		}
+
 		if httpSuccess {
 			httpResults = append(httpResults, httpTestResult{
 				proxyType: candidate.proxyType,
@@ -343,14 +505,14 @@ func (c *Checker) determineProxyType(proxyURL *url.URL, result *ProxyResult) (Pr
 		}
 
 		// Then test with HTTPS endpoint
-		c.config.ValidationURL = validationURLHTTPS
-		httpsSuccess, httpsTestErr, httpsCheckResult := c.testClientWithDetails(client, candidate.proxyType, result)
+		httpsSuccess, httpsTestErr, httpsCheckResult := c.testClientWithDetails(client, candidate.proxyType, validationURLHTTPS, result)
 
 		// Add the check result to our collection
 		if httpsCheckResult != nil {
 			result.CheckResults = append(result.CheckResults, *httpsCheckResult)
 		}
 
+		c.noteConnectOnly(httpCheckResult, httpsSuccess, result)
 		if httpsSuccess {
 			httpResults = append(httpResults, httpTestResult{
 				proxyType: candidate.proxyType,
@@ -416,32 +578,8 @@ func (c *Checker) determineProxyType(proxyURL *url.URL, result *ProxyResult) (Pr
 	}
 
 	// If HTTP/HTTPS failed, try HTTP/2 and HTTP/3 if enabled
-	if c.config.EnableHTTP2 || c.config.EnableHTTP3 {
-		if c.debug {
-			result.DebugInfo += fmt.Sprintf("[TYPE] Testing advanced HTTP protocols (HTTP/2, HTTP/3): %s\n", proxyURL.Host)
-		}
-
-		// Test HTTP/2 support if enabled
-		if c.config.EnableHTTP2 {
-			if success, client := c.detectHTTP2Protocol(proxyURL, result); success {
-				result.SupportsHTTP2 = true
-				if c.debug {
-					result.DebugInfo += fmt.Sprintf("[TYPE] Selected HTTP/2 proxy\n")
-				}
-				return ProxyTypeHTTP2, client, nil
-			}
-		}
-
-		// Test HTTP/3 support if enabled
-		if c.config.EnableHTTP3 {
-			if success, client := c.detectHTTP3Protocol(proxyURL, result); success {
-				result.SupportsHTTP3 = true
-				if c.debug {
-					result.DebugInfo += fmt.Sprintf("[TYPE] Selected HTTP/3 proxy\n")
-				}
-				return ProxyTypeHTTP3, client, nil
-			}
-		}
+	if proxyType, client, ok := c.detectAdvancedHTTP(proxyURL, result); ok {
+		return proxyType, client, nil
 	}
 
 	// If HTTP/HTTPS failed, try SOCKS proxies
@@ -483,14 +621,18 @@ func (c *Checker) determineProxyType(proxyURL *url.URL, result *ProxyResult) (Pr
 		}
 
 		// Test with HTTP endpoint
-		c.config.ValidationURL = validationURLHTTP
-		httpSuccess, httpTestErr, httpCheckResult := c.testClientWithDetails(client, candidate.proxyType, result)
+		httpSuccess, httpTestErr, httpCheckResult := c.testClientWithDetails(client, candidate.proxyType, validationURLHTTP, result)
 
 		// Add the check result to our collection
 		if httpCheckResult != nil {
 			result.CheckResults = append(result.CheckResults, *httpCheckResult)
 		}
 
+		// A refused or unroutable proxy fails every other attempt too
+		if httpCheckResult != nil && httpCheckResult.unreachable {
+			return ProxyTypeUnknown, nil, c.proxyUnreachableError(proxyURL, httpTestErr, result)
+		}
+
 		if httpSuccess {
 			socksResults = append(socksResults, socksTestResult{
 				proxyType: candidate.proxyType,
@@ -509,8 +651,7 @@ func (c *Checker) determineProxyType(proxyURL *url.URL, result *ProxyResult) (Pr
 		}
 
 		// Test with HTTPS endpoint
-		c.config.ValidationURL = validationURLHTTPS
-		httpsSuccess, httpsTestErr, httpsCheckResult := c.testClientWithDetails(client, candidate.proxyType, result)
+		httpsSuccess, httpsTestErr, httpsCheckResult := c.testClientWithDetails(client, candidate.proxyType, validationURLHTTPS, result)
 
 		// Add the check result to our collection
 		if httpsCheckResult != nil {
@@ -618,45 +759,120 @@ func (c *Checker) determineProxyType(proxyURL *url.URL, result *ProxyResult) (Pr
 	return ProxyTypeUnknown, nil, fmt.Errorf("could not determine proxy type: %s", lastError)
 }
 
+// detectAdvancedHTTP tries HTTP/2 and then HTTP/3 when they are enabled
+func (c *Checker) detectAdvancedHTTP(proxyURL *url.URL, result *ProxyResult) (ProxyType, *http.Client, bool) {
+	if !c.config.EnableHTTP2 && !c.config.EnableHTTP3 {
+		return ProxyTypeUnknown, nil, false
+	}
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[TYPE] Testing advanced HTTP protocols (HTTP/2, HTTP/3): %s\n", proxyURL.Host)
+	}
+
+	// Test HTTP/2 support if enabled
+	if c.config.EnableHTTP2 {
+		if success, client := c.detectHTTP2Protocol(proxyURL, result); success {
+			result.SupportsHTTP2 = true
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[TYPE] Selected HTTP/2 proxy\n")
+			}
+			return ProxyTypeHTTP2, client, true
+		}
+	}
+
+	// Test HTTP/3 support if enabled
+	if c.config.EnableHTTP3 {
+		if success, client := c.detectHTTP3Protocol(proxyURL, result); success {
+			result.SupportsHTTP3 = true
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[TYPE] Selected HTTP/3 proxy\n")
+			}
+			return ProxyTypeHTTP3, client, true
+		}
+	}
+
+	return ProxyTypeUnknown, nil, false
+}
+
 // performChecks runs all configured checks for the proxy
 func (c *Checker) performChecks(client *http.Client, result *ProxyResult) error {
 	start := time.Now()
+	validationURL := c.validationURL(result)
 
 	if c.debug {
 		result.DebugInfo += fmt.Sprintf("[VALIDATE] Running validation checks\n")
 	}
 
+	// Find out whether the validation target also tests IPv6 target support
+	requestURL, requestHost, ipv6Target, err := c.ipv6ValidationTarget(validationURL, result)
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[VALIDATE] %v\n", err)
+		}
+		return errors.NewHTTPError(errors.ErrorHTTPRequestFailed, "validation target has no IPv6 address", validationURL, err)
+	}
+	if ipv6Target {
+		result.IPv6TargetChecked = true
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[VALIDATE] Validation target is reached over IPv6: %s\n", requestURL)
+		}
+	}
+
 	// Make the request to the validation URL (with retry logic if enabled)
-	resp, err := c.makeRequestWithRetry(client, c.config.ValidationURL, result)
+	resp, err := c.makeRequestWithRetryHost(client, requestURL, requestHost, result)
 	if err != nil {
 		if c.debug {
 			result.DebugInfo += fmt.Sprintf("[VALIDATE] Request failed: %v\n", err)
 		}
-		return errors.NewHTTPError(errors.ErrorHTTPRequestFailed, "request failed", c.config.ValidationURL, err)
+		if c.config.MinimalHeaders {
+			c.compareMinimalHeaders(client, validationURL, 0, result)
+		}
+		if redirectErr, ok := asRedirectError(err); ok {
+			return errors.NewHTTPError(errors.ErrorHTTPRequestFailed, "redirect chain aborted", validationURL, err).
+				WithDetail("reason", redirectErr.reason)
+		}
+		return errors.NewHTTPError(errors.ErrorHTTPRequestFailed, "request failed", validationURL, err)
 	}
 	defer resp.Body.Close()
+	// A proxy that could not connect out over IPv6 answers with a gateway error
+	result.SupportsIPv6Target = ipv6Target && resp.StatusCode < 400
 
 	// Record the time taken
 	duration := time.Since(start)
 	result.Speed = duration
 
+	// Inspecting the certificate may need a direct handshake with the target,
+	// which must not count towards the proxy's speed
+	if c.config.InspectTLS {
+		c.inspectTLS(resp.TLS, requestURL, result)
+	}
+
 	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.readBody(resp.Body, result)
 	if err != nil {
 		if c.debug {
 			result.DebugInfo += fmt.Sprintf("[VALIDATE] Failed to read response body: %v\n", err)
 		}
+		if unbounded, ok := err.(*unboundedBodyError); ok {
+			return errors.NewHTTPError(errors.ErrorHTTPResponseInvalid, "unbounded response body", validationURL, err).
+				WithDetail("reason", unbounded.reason)
+		}
 		return fmt.Errorf("failed to read response body: %v", err)
 	}
 
 	// Create a check result for the validation
 	validationCheck := CheckResult{
-		URL:        c.config.ValidationURL,
+		URL:        validationURL,
 		Success:    true,
 		Speed:      duration,
 		StatusCode: resp.StatusCode,
 		BodySize:   int64(len(body)),
 	}
+	c.recordRedirect(resp, &validationCheck, result)
+
+	// Compare with a minimal header set before any check can reject the proxy
+	if c.config.MinimalHeaders {
+		c.compareMinimalHeaders(client, validationURL, resp.StatusCode, result)
+	}
 
 	// Perform validation checks
 	if c.debug {
@@ -666,13 +882,13 @@ func (c *Checker) performChecks(client *http.Client, result *ProxyResult) error
 	// Check response status code
 	if c.config.RequireStatusCode > 0 && resp.StatusCode != c.config.RequireStatusCode {
 		validationCheck.Success = false
-		validationCheck.Error = fmt.Sprintf("unexpected status code: %d (expected: %d)",
-			resp.StatusCode, c.config.RequireStatusCode)
+		validationCheck.Error = fmt.Sprintf("unexpected status code: %d (expected: %d)%s",
+			resp.StatusCode, c.config.RequireStatusCode, redirectNote(validationCheck))
 		result.CheckResults = append(result.CheckResults, validationCheck)
 		if c.debug {
 			result.DebugInfo += fmt.Sprintf("[VALIDATE] Status code check failed: %s\n", validationCheck.Error)
 		}
-		return errors.NewHTTPError(errors.ErrorHTTPUnexpectedStatus, "unexpected status code", c.config.ValidationURL, nil).
+		return errors.NewHTTPError(errors.ErrorHTTPUnexpectedStatus, "unexpected status code", validationURL, nil).
 			WithDetail("status_code", resp.StatusCode).
 			WithDetail("expected_code", c.config.RequireStatusCode)
 	}
@@ -683,13 +899,13 @@ func (c *Checker) performChecks(client *http.Client, result *ProxyResult) error
 	}
 	if len(body) < c.config.MinResponseBytes {
 		validationCheck.Success = false
-		validationCheck.Error = fmt.Sprintf("response too small: %d bytes (min: %d)",
-			len(body), c.config.MinResponseBytes)
+		validationCheck.Error = fmt.Sprintf("response too small: %d bytes (min: %d)%s",
+			len(body), c.config.MinResponseBytes, redirectNote(validationCheck))
 		result.CheckResults = append(result.CheckResults, validationCheck)
 		if c.debug {
 			result.DebugInfo += fmt.Sprintf("[VALIDATE] Response size check failed: %s\n", validationCheck.Error)
 		}
-		return fmt.Errorf("response too small: %d bytes", len(body))
+		return fmt.Errorf("response too small: %d bytes%s", len(body), redirectNote(validationCheck))
 	}
 
 	// Check for disallowed keywords
@@ -741,9 +957,32 @@ func (c *Checker) performChecks(client *http.Client, result *ProxyResult) error
 		}
 	}
 
+	// Let an external command have the final say on the body
+	if c.config.ExternalValidationCommand != "" {
+		if err := c.validateResponseExternal(body, result); err != nil {
+			validationCheck.Success = false
+			validationCheck.Error = err.Error()
+			result.CheckResults = append(result.CheckResults, validationCheck)
+			return err
+		}
+	}
+
 	// All checks passed, add the successful validation result
 	result.CheckResults = append(result.CheckResults, validationCheck)
 
+	// Compare with a direct fetch to catch altered content
+	if c.config.ContentSimilarityCheck {
+		c.checkContentSimilarity(body, result)
+	}
+
+	if err := c.checkRequiredProtocols(result); err != nil {
+		return err
+	}
+
+	if err := c.checkValidationQuorum(client, result); err != nil {
+		return err
+	}
+
 	if c.debug {
 		result.DebugInfo += fmt.Sprintf("[VALIDATE] All validation checks passed\n")
 	}
@@ -754,6 +993,18 @@ func (c *Checker) performChecks(client *http.Client, result *ProxyResult) error
 	return nil
 }
 
+// checkRequiredProtocols fails the check when RequireBothHTTPAndHTTPS is set
+// and the proxy did not handle both HTTP and HTTPS targets
+func (c *Checker) checkRequiredProtocols(result *ProxyResult) error {
+	if !c.config.RequireBothHTTPAndHTTPS || (result.SupportsHTTP && result.SupportsHTTPS) {
+		return nil
+	}
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[VALIDATE] Both HTTP and HTTPS required (HTTP: %t, HTTPS: %t)\n", result.SupportsHTTP, result.SupportsHTTPS)
+	}
+	return fmt.Errorf("proxy must support both HTTP and HTTPS (HTTP: %t, HTTPS: %t)", result.SupportsHTTP, result.SupportsHTTPS)
+}
+
 // performSingleCheck performs a single URL check
 func (c *Checker) performSingleCheck(client *http.Client, testURL string, result *ProxyResult) (*CheckResult, error) {
 	start := time.Now()
@@ -782,7 +1033,7 @@ func (c *Checker) performSingleCheck(client *http.Client, testURL string, result
 
 	// If rDNS lookup is enabled, try to use it for the Host header
 	if c.config.UseRDNS {
-		if host, err := lookupRDNS(req.URL.Hostname()); err == nil && host != "" {
+		if host, err := c.resolveTargetRDNS(req.URL.Hostname(), result); err == nil && host != "" {
 			if c.debug {
 				result.DebugInfo += fmt.Sprintf("[DEBUG] Using rDNS host: %s\n", host)
 			}
@@ -794,7 +1045,7 @@ func (c *Checker) performSingleCheck(client *http.Client, testURL string, result
 		result.DebugInfo += fmt.Sprintf("[DEBUG] Sending request with headers: %v\n", req.Header)
 	}
 
-	resp, err := client.Do(req)
+	resp, err := c.doWithDNSRetry(client, req, result)
 	if err != nil {
 		checkResult.Error = err.Error()
 		if c.debug {
@@ -804,7 +1055,7 @@ func (c *Checker) performSingleCheck(client *http.Client, testURL string, result
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.readBody(resp.Body, result)
 	if err != nil {
 		checkResult.Error = err.Error()
 		if c.debug {
@@ -817,6 +1068,7 @@ func (c *Checker) performSingleCheck(client *http.Client, testURL string, result
 	checkResult.BodySize = int64(len(body))
 	checkResult.Speed = time.Since(start)
 	checkResult.Success = c.validateResponse(resp, body)
+	c.recordRedirect(resp, checkResult, result)
 
 	if c.debug {
 		result.DebugInfo += fmt.Sprintf("[DEBUG] Response: status=%d, size=%d bytes, time=%v, success=%v\n",
@@ -826,9 +1078,18 @@ func (c *Checker) performSingleCheck(client *http.Client, testURL string, result
 	return checkResult, nil
 }
 
+// timeout returns the timeout for the proxy being checked, preferring its
+// per-proxy override over the configured default
+func (c *Checker) timeout(result *ProxyResult) time.Duration {
+	if result != nil && result.Timeout > 0 {
+		return result.Timeout
+	}
+	return c.config.Timeout
+}
+
 // lookupRDNS performs a reverse DNS lookup on an IP address
-func lookupRDNS(ip string) (string, error) {
-	names, err := net.LookupAddr(ip)
+func (c *Checker) lookupRDNS(ip string) (string, error) {
+	names, err := c.resolver().LookupAddr(context.Background(), ip)
 	if err != nil {
 		return "", err
 	}
@@ -840,12 +1101,19 @@ func lookupRDNS(ip string) (string, error) {
 }
 
 func (c *Checker) makeRequest(client *http.Client, urlStr string, result *ProxyResult) (*http.Response, error) {
-	// Create a context with the configured timeout
-	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
-	defer cancel()
+	return c.makeRequestWithHost(client, urlStr, "", result)
+}
+
+// makeRequestWithHost is makeRequest sending hostHeader as the Host header
+// when it is set, for URLs pinned to an address
+func (c *Checker) makeRequestWithHost(client *http.Client, urlStr, hostHeader string, result *ProxyResult) (*http.Response, error) {
+	// Create a context with the configured timeout; it stays alive until the
+	// response body is closed so the caller can still read the body
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout(result))
 
-	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
+	req, err := http.NewRequestWithContext(withConnectTrace(ctx, result), "GET", urlStr, nil)
 	if err != nil {
+		cancel()
 		if c.debug {
 			result.DebugInfo += fmt.Sprintf("[DEBUG] Error creating request: %v\n", err)
 		}
@@ -853,25 +1121,27 @@ func (c *Checker) makeRequest(client *http.Client, urlStr string, result *ProxyR
 	}
 
 	// Apply rate limiting if enabled
-	if parsedURL, err := url.Parse(urlStr); err == nil {
-		c.applyRateLimit(parsedURL.Hostname(), result)
-	}
+	host := req.URL.Hostname()
+	c.applyRateLimit(host, result)
 
 	// Set headers
 	for key, value := range c.config.DefaultHeaders {
 		req.Header.Set(key, value)
 	}
 	req.Header.Set("User-Agent", c.config.UserAgent)
+	if hostHeader != "" {
+		req.Host = hostHeader
+	}
 
 	if c.debug {
 		result.DebugInfo += fmt.Sprintf("[DEBUG] Making request to: %s\n", urlStr)
 
 		// Get proxy information in a more readable format
 		proxyInfo := "direct connection"
-		if transport, ok := client.Transport.(*http.Transport); ok && transport.Proxy != nil {
+		if transport, ok := unwrapTransport(client.Transport).(*http.Transport); ok && transport.Proxy != nil {
 			// Try to get the proxy URL by making a test request
 			if proxyURL, err := transport.Proxy(req); err == nil && proxyURL != nil {
-				proxyInfo = proxyURL.String()
+				proxyInfo = proxyURL.Redacted()
 			} else {
 				proxyInfo = "configured (address unavailable)"
 			}
@@ -888,7 +1158,7 @@ func (c *Checker) makeRequest(client *http.Client, urlStr string, result *ProxyR
 	}
 
 	start := time.Now()
-	resp, err := client.Do(req)
+	resp, err := c.doWithDNSRetry(client, req, result)
 	duration := time.Since(start)
 
 	if c.debug {
@@ -904,7 +1174,14 @@ func (c *Checker) makeRequest(client *http.Client, urlStr string, result *ProxyR
 		}
 	}
 
-	return resp, err
+	if err != nil {
+		cancel()
+		return resp, err
+	}
+
+	c.honorRetryAfter(host, resp, result)
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
 }
 
 // performDirectScan attempts to scan the target directly as a web server when proxy connection fails
@@ -933,7 +1210,7 @@ func (c *Checker) performDirectScan(proxyURL *url.URL, result *ProxyResult) bool
 
 	// Create a direct HTTP client (not using the target as a proxy)
 	directClient := &http.Client{
-		Timeout: c.config.Timeout,
+		Timeout: c.timeout(result),
 		Transport: &http.Transport{
 			MaxIdleConns:        100,
 			MaxIdleConnsPerHost: 10,
@@ -945,6 +1222,9 @@ func (c *Checker) performDirectScan(proxyURL *url.URL, result *ProxyResult) bool
 			return http.ErrUseLastResponse // Don't follow redirects
 		},
 	}
+	if c.config.BaseDialer != nil {
+		directClient.Transport.(*http.Transport).DialContext = c.config.BaseDialer
+	}
 
 	// Test 1: Try to access root path to see if it responds
 	req, err := http.NewRequest("GET", targetURL, nil)
@@ -1068,9 +1348,9 @@ func (c *Checker) performDirectScan(proxyURL *url.URL, result *ProxyResult) bool
 
 		ssrfTargets := []string{
 			"http://169.254.169.254/latest/meta-data/", // AWS metadata
-			"http://metadata.google.internal/",          // GCP metadata
-			"http://localhost:8080/",                    // Localhost
-			"http://127.0.0.1:6379/",                    // Redis
+			"http://metadata.google.internal/",         // GCP metadata
+			"http://localhost:8080/",                   // Localhost
+			"http://127.0.0.1:6379/",                   // Redis
 		}
 
 		for _, ssrfTarget := range ssrfTargets {
@@ -1141,7 +1421,7 @@ func (c *Checker) performDirectScan(proxyURL *url.URL, result *ProxyResult) bool
 	}
 
 	// Protocol Smuggling Test
-	if c.config.AdvancedChecks.TestProtocolSmuggling {
+	if c.config.AdvancedChecks.TestProtocolSmuggling && !c.skipMutatingVulnCheck(VulnCheckProtocolSmuggling, result) {
 		if c.debug {
 			result.DebugInfo += fmt.Sprintf("[DIRECT SCAN] Testing protocol smuggling\n")
 		}
@@ -1199,30 +1479,23 @@ func (c *Checker) performDirectScan(proxyURL *url.URL, result *ProxyResult) bool
 		if c.debug {
 			result.DebugInfo += fmt.Sprintf("[DIRECT SCAN] Testing IPv6 support\n")
 		}
-		if tester != nil {
-			res, err := tester.PerformInteractshTest(directClient, c, func(url string) (*http.Request, error) {
-				return http.NewRequest("GET", fmt.Sprintf("http://[%s]", url), nil)
-			})
-			if err == nil && res != nil && res.Success {
-				result.CheckResults = append(result.CheckResults, *res)
-				foundSomething = true
-			}
-		} else {
-			if res, err := c.checkIPv6Support(directClient, testDomain); err == nil && res != nil && res.Success {
-				result.CheckResults = append(result.CheckResults, *res)
-				foundSomething = true
-			}
+		res := c.checkIPv6Connectivity(directClient, result)
+		if res.StatusCode > 0 {
+			result.CheckResults = append(result.CheckResults, *res)
+		}
+		if res.Success {
+			foundSomething = true
 		}
 	}
 
 	// HTTP Methods Test
-	if len(c.config.AdvancedChecks.TestHTTPMethods) > 0 {
+	if methods := c.vulnTestMethods(result); len(methods) > 0 {
 		if c.debug {
-			result.DebugInfo += fmt.Sprintf("[DIRECT SCAN] Testing HTTP methods: %v\n", c.config.AdvancedChecks.TestHTTPMethods)
+			result.DebugInfo += fmt.Sprintf("[DIRECT SCAN] Testing HTTP methods: %v\n", methods)
 		}
 		var results []*CheckResult
 		if tester != nil {
-			for _, method := range c.config.AdvancedChecks.TestHTTPMethods {
+			for _, method := range methods {
 				res, err := tester.PerformInteractshTest(directClient, c, func(url string) (*http.Request, error) {
 					return http.NewRequest(method, fmt.Sprintf("http://%s", url), nil)
 				})
@@ -1311,12 +1584,15 @@ func (c *Checker) performDirectScan(proxyURL *url.URL, result *ProxyResult) bool
 		}
 	}
 
+	// Path-enumeration vuln probes only touch paths within the configured scope
+	vulnClient := c.scopeVulnPaths(directClient, result)
+
 	// Nginx Vulnerability Tests
 	if c.config.AdvancedChecks.TestNginxVulnerabilities {
 		if c.debug {
 			result.DebugInfo += "[DIRECT SCAN - NGINX VULNS] Running nginx-specific vulnerability checks\n"
 		}
-		nginxResults := c.performNginxVulnerabilityChecks(directClient, result)
+		nginxResults := c.performNginxVulnerabilityChecks(vulnClient, result)
 		result.NginxVulnerabilities = nginxResults
 
 		// Count findings
@@ -1350,7 +1626,7 @@ func (c *Checker) performDirectScan(proxyURL *url.URL, result *ProxyResult) bool
 		if c.debug {
 			result.DebugInfo += "[DIRECT SCAN - APACHE VULNS] Running Apache mod_proxy vulnerability checks\n"
 		}
-		apacheResults := c.performApacheVulnerabilityChecks(directClient, result)
+		apacheResults := c.performApacheVulnerabilityChecks(vulnClient, result)
 		result.ApacheVulnerabilities = apacheResults
 
 		// Count findings
@@ -1387,7 +1663,7 @@ func (c *Checker) performDirectScan(proxyURL *url.URL, result *ProxyResult) bool
 		if c.debug {
 			result.DebugInfo += "[DIRECT SCAN - KONG VULNS] Running Kong API Gateway vulnerability checks\n"
 		}
-		kongResults := c.performKongVulnerabilityChecks(directClient, result)
+		kongResults := c.performKongVulnerabilityChecks(vulnClient, result)
 		result.KongVulnerabilities = kongResults
 
 		// Count findings
@@ -1415,7 +1691,7 @@ func (c *Checker) performDirectScan(proxyURL *url.URL, result *ProxyResult) bool
 		if c.debug {
 			result.DebugInfo += "[DIRECT SCAN - GENERIC VULNS] Running generic proxy misconfiguration checks\n"
 		}
-		genericResults := c.performGenericVulnerabilityChecks(directClient, result)
+		genericResults := c.performGenericVulnerabilityChecks(vulnClient, result)
 		result.GenericVulnerabilities = genericResults
 
 		// Count findings
@@ -1449,7 +1725,7 @@ func (c *Checker) performDirectScan(proxyURL *url.URL, result *ProxyResult) bool
 		if c.debug {
 			result.DebugInfo += "[DIRECT SCAN - EXTENDED VULNS] Running extended vulnerability checks\n"
 		}
-		extendedResults := c.performExtendedVulnerabilityChecks(directClient, result)
+		extendedResults := c.performExtendedVulnerabilityChecks(vulnClient, result)
 		result.ExtendedVulnerabilities = extendedResults
 
 		// Count findings
@@ -1483,7 +1759,7 @@ func (c *Checker) performDirectScan(proxyURL *url.URL, result *ProxyResult) bool
 		if c.debug {
 			result.DebugInfo += "[DIRECT SCAN - VENDOR VULNS] Running vendor-specific vulnerability checks\n"
 		}
-		vendorResults := c.performVendorVulnerabilityChecks(directClient, result)
+		vendorResults := c.performVendorVulnerabilityChecks(vulnClient, result)
 		result.VendorVulnerabilities = vendorResults
 
 		// Count findings
@@ -1520,7 +1796,7 @@ func (c *Checker) performDirectScan(proxyURL *url.URL, result *ProxyResult) bool
 		if c.debug {
 			result.DebugInfo += "[DIRECT SCAN - ADVANCED SSRF] Running advanced SSRF vulnerability checks\n"
 		}
-		advancedSSRFResults := c.performAdvancedSSRFChecks(directClient, result)
+		advancedSSRFResults := c.performAdvancedSSRFChecks(vulnClient, result)
 		result.AdvancedSSRFVulnerabilities = advancedSSRFResults
 
 		// Count findings
@@ -1609,8 +1885,8 @@ func (c *Checker) performDirectScan(proxyURL *url.URL, result *ProxyResult) bool
 			checksRun = append(checksRun, "  └─ Advanced SSRF: Protocol Smuggling (9 schemes)")
 			totalRequests += 9
 
-			checksRun = append(checksRun, "  └─ Advanced SSRF: Header Injection (40 tests)")
-			totalRequests += 40
+			checksRun = append(checksRun, "  └─ Advanced SSRF: Header Injection (50 tests)")
+			totalRequests += 50
 
 			checksRun = append(checksRun, "  └─ Advanced SSRF: proxy_pass Traversal (7 patterns)")
 			totalRequests += 7
@@ -1628,8 +1904,8 @@ func (c *Checker) performDirectScan(proxyURL *url.URL, result *ProxyResult) bool
 			checksRun = append(checksRun, "  └─ Advanced SSRF: HTTP/2 Header Injection (4 patterns)")
 			totalRequests += 4
 
-			checksRun = append(checksRun, "  └─ Advanced SSRF: AWS IMDSv2 Bypass (7 tests)")
-			totalRequests += 7
+			checksRun = append(checksRun, "  └─ Advanced SSRF: AWS IMDSv2 Token Workflow (2 steps)")
+			totalRequests += 2
 
 			// Priority 3 Advanced Checks
 			checksRun = append(checksRun, "  └─ Advanced SSRF: URL Encoding Bypass (12 patterns)")
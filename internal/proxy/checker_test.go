@@ -608,3 +608,47 @@ func BenchmarkProxyResultCreation(b *testing.B) {
 		_ = result
 	}
 }
+
+// TestCheckerTimeoutOverride tests that a per-proxy timeout takes precedence over the configured one
+func TestCheckerTimeoutOverride(t *testing.T) {
+	checker := NewChecker(Config{Timeout: 10 * time.Second}, false, nil)
+
+	if got := checker.timeout(&ProxyResult{}); got != 10*time.Second {
+		t.Errorf("Expected the configured timeout without an override, got %v", got)
+	}
+	if got := checker.timeout(&ProxyResult{Timeout: 30 * time.Second}); got != 30*time.Second {
+		t.Errorf("Expected the per-proxy timeout, got %v", got)
+	}
+
+	result := checker.CheckWithTimeout("://invalid-url", 30*time.Second)
+	if result.Timeout != 30*time.Second {
+		t.Errorf("Expected CheckWithTimeout to record the override, got %v", result.Timeout)
+	}
+}
+
+// TestCheckRequiredProtocols tests that RequireBothHTTPAndHTTPS rejects proxies missing either protocol
+func TestCheckRequiredProtocols(t *testing.T) {
+	tests := []struct {
+		name        string
+		requireBoth bool
+		http, https bool
+		wantErr     bool
+	}{
+		{"not required", false, true, false, false},
+		{"both supported", true, true, true, false},
+		{"HTTP only", true, true, false, true},
+		{"HTTPS only", true, false, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(Config{RequireBothHTTPAndHTTPS: tt.requireBoth}, false, nil)
+			result := &ProxyResult{SupportsHTTP: tt.http, SupportsHTTPS: tt.https}
+
+			err := checker.checkRequiredProtocols(result)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkRequiredProtocols() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
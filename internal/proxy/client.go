@@ -1,11 +1,11 @@
 package proxy
 
 import (
-	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -19,18 +19,23 @@ func (c *Checker) createClient(proxyURL *url.URL, scheme string, result *ProxyRe
 	// Extract authentication information
 	auth := c.getProxyAuth(proxyURL, result)
 
-	// Try to use connection pool if available
-	if c.config.ConnectionPool != nil {
+	// Try to use connection pool if available. Pooled clients go through
+	// net/http's proxy support, which cannot speak SOCKS4a or NTLM, so skip it there.
+	if c.config.ConnectionPool != nil && scheme != "socks4a" && !c.useNTLM(scheme, auth) {
 		if pool, ok := c.config.ConnectionPool.(interface {
 			GetClient(string, time.Duration) (*http.Client, error)
 		}); ok {
-			fullProxyURL := fmt.Sprintf("%s://%s", scheme, proxyURL.Host)
-			client, err := pool.GetClient(fullProxyURL, c.config.Timeout)
+			key := poolKey(proxyURL.Host, scheme, auth)
+			client, err := pool.GetClient(key, c.timeout(result))
 			if err == nil {
 				if c.debug {
-					result.DebugInfo += fmt.Sprintf("[DEBUG] Using connection pool client for: %s\n", fullProxyURL)
+					result.DebugInfo += fmt.Sprintf("[DEBUG] Using connection pool client for: %s\n", RedactProxyURL(key))
 				}
-				return client, nil
+				// The pooled client is shared, so redirect handling goes on
+				// this check's copy like it does on a manual client
+				pooled := countTraffic(client, result)
+				pooled.CheckRedirect = c.checkRedirect(result)
+				return pooled, nil
 			}
 			if c.debug {
 				result.DebugInfo += fmt.Sprintf("[DEBUG] Connection pool failed, falling back to manual client creation: %v\n", err)
@@ -45,29 +50,33 @@ func (c *Checker) createClient(proxyURL *url.URL, scheme string, result *ProxyRe
 	case scheme == "http" || scheme == "https":
 		transport = c.createAuthenticatedHTTPTransport(proxyURL, scheme, auth, result)
 
-	case scheme == "socks4" || scheme == "socks5":
+	case scheme == "socks4" || scheme == "socks4a" || scheme == "socks5":
+		if c.debug {
+			if socksResolvesRemotely(scheme) {
+				result.DebugInfo += fmt.Sprintf("[DEBUG] %s: destination hostnames resolved remotely by the proxy\n", scheme)
+			} else {
+				result.DebugInfo += fmt.Sprintf("[DEBUG] %s: destination hostnames resolved locally before dialing\n", scheme)
+			}
+		}
 		transport = &http.Transport{
-			TLSHandshakeTimeout:   c.config.Timeout / 2,
-			ResponseHeaderTimeout: c.config.Timeout / 2,
+			TLSHandshakeTimeout:   c.timeout(result) / 2,
+			ResponseHeaderTimeout: c.timeout(result) / 2,
 			ExpectContinueTimeout: 1 * time.Second,
 			MaxIdleConns:          100,
 			MaxIdleConnsPerHost:   10,
 			IdleConnTimeout:       90 * time.Second,
 			DisableKeepAlives:     true,
 			ForceAttemptHTTP2:     false,
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
 		}
 		transport.DialContext = c.createAuthenticatedSOCKSDialer(proxyURL, scheme, auth, result)
 	}
 
-	// Set TLS config if not already set
-	if transport.TLSClientConfig == nil {
-		transport.TLSClientConfig = &tls.Config{
-			InsecureSkipVerify: true,
-		}
+	// Set TLS config, verifying targets only when configured to
+	tlsConfig, err := c.tlsConfig(result)
+	if err != nil {
+		return nil, err
 	}
+	transport.TLSClientConfig = tlsConfig
 
 	// Add warning about disabled TLS verification
 	if transport.TLSClientConfig != nil && transport.TLSClientConfig.InsecureSkipVerify {
@@ -76,11 +85,9 @@ func (c *Checker) createClient(proxyURL *url.URL, scheme string, result *ProxyRe
 	}
 
 	client := &http.Client{
-		Transport: transport,
-		Timeout:   c.config.Timeout,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
+		Transport: &countingTransport{base: transport, result: result},
+		Timeout:   c.timeout(result),
+		CheckRedirect: c.checkRedirect(result),
 	}
 
 	if c.debug {
@@ -90,17 +97,67 @@ func (c *Checker) createClient(proxyURL *url.URL, scheme string, result *ProxyRe
 	return client, nil
 }
 
-// testClientWithDetails tests if the client works with a simple request and returns detailed information
-func (c *Checker) testClientWithDetails(client *http.Client, proxyType ProxyType, result *ProxyResult) (bool, string, *CheckResult) {
+// poolKey returns the connection pool key for a proxy. It keeps the
+// credentials so pooled clients authenticate like manual ones.
+func poolKey(host, scheme string, auth *ProxyAuth) string {
+	key := &url.URL{Scheme: scheme, Host: host}
+	if auth != nil {
+		key.User = url.UserPassword(auth.Username, auth.Password)
+	}
+	return key.String()
+}
+
+// PoolKey returns the connection pool key and timeout that checks of the
+// proxy in result get their pooled client with, so callers such as
+// keep-warm reuse that client and its connections. ok is false when checks
+// of the proxy don't go through the pool.
+func (c *Checker) PoolKey(result *ProxyResult) (key string, timeout time.Duration, ok bool) {
+	proxyURL := result.ProxyURL
+	if !strings.Contains(proxyURL, "://") {
+		proxyURL = "http://" + proxyURL
+	}
+	parsed, err := url.Parse(proxyURL)
+	if err != nil || parsed.Host == "" {
+		return "", 0, false
+	}
+
+	scheme := string(result.Type)
+	switch result.Type {
+	case ProxyTypeHTTP, ProxyTypeHTTPS, ProxyTypeSOCKS4, ProxyTypeSOCKS5:
+	case ProxyTypeSOCKS4A:
+		// Pooled clients go through net/http, which cannot speak SOCKS4a
+		return "", 0, false
+	default:
+		scheme = "http"
+	}
+
+	auth := c.getProxyAuth(parsed, &ProxyResult{})
+	if c.useNTLM(scheme, auth) {
+		return "", 0, false
+	}
+	return poolKey(parsed.Host, scheme, auth), c.timeout(result), true
+}
+
+// socksResolvesRemotely reports whether a SOCKS scheme hands the destination
+// hostname to the proxy unresolved. SOCKS4 only carries an IPv4 address, so the
+// hostname has to be looked up locally; SOCKS4a and SOCKS5 send it as-is.
+func socksResolvesRemotely(scheme string) bool {
+	return scheme == "socks4a" || scheme == "socks5"
+}
+
+// testClientWithDetails tests if the client works with a simple request to
+// testURL and returns detailed information. The URL is passed in rather than
+// read from the config because the config is shared by concurrent checks.
+func (c *Checker) testClientWithDetails(client *http.Client, proxyType ProxyType, testURL string, result *ProxyResult) (bool, string, *CheckResult) {
 	// Use different validation URLs based on proxy type
-	testURL := c.config.ValidationURL
-	if proxyType == ProxyTypeSOCKS4 || proxyType == ProxyTypeSOCKS5 {
+	if proxyType == ProxyTypeSOCKS4 || proxyType == ProxyTypeSOCKS4A || proxyType == ProxyTypeSOCKS5 {
 		// For SOCKS proxies, try a plain HTTP URL first
 		testURL = "http://api.ipify.org?format=json"
 	}
 
 	checkResult := &CheckResult{
-		URL: testURL,
+		URL:       testURL,
+		proxyType: proxyType,
 	}
 
 	start := time.Now()
@@ -123,16 +180,25 @@ func (c *Checker) testClientWithDetails(client *http.Client, proxyType ProxyType
 		req.Header.Set("User-Agent", c.config.UserAgent)
 	}
 
-	resp, err := client.Do(req)
+	resp, err := c.doWithDNSRetry(client, req, result)
 	if err != nil {
 		checkResult.Error = err.Error()
 		checkResult.Speed = time.Since(start)
+		checkResult.unreachable = proxyUnreachable(err)
+		// net/http reports a 407 answer to CONNECT as an error
+		if strings.Contains(strings.ToLower(err.Error()), "proxy authentication required") {
+			result.AuthRequired = true
+		}
 		return false, err.Error(), checkResult
 	}
 	defer resp.Body.Close()
 
 	checkResult.StatusCode = resp.StatusCode
+	if resp.StatusCode == http.StatusProxyAuthRequired {
+		result.AuthRequired = true
+	}
 	checkResult.Speed = time.Since(start)
+	c.recordRedirect(resp, checkResult, result)
 
 	// Read the response body
 	body, err := io.ReadAll(resp.Body)
@@ -145,8 +211,8 @@ func (c *Checker) testClientWithDetails(client *http.Client, proxyType ProxyType
 
 	// Check if response is valid
 	if !c.validateResponse(resp, body) {
-		checkResult.Error = "response validation failed"
-		return false, "response validation failed", checkResult
+		checkResult.Error = "response validation failed" + redirectNote(*checkResult)
+		return false, checkResult.Error, checkResult
 	}
 
 	checkResult.Success = true
@@ -155,12 +221,12 @@ func (c *Checker) testClientWithDetails(client *http.Client, proxyType ProxyType
 
 // testClientWithError tests if the client works with a simple request and returns an error message
 func (c *Checker) testClientWithError(client *http.Client, proxyType ProxyType, result *ProxyResult) (bool, string) {
-	success, errorMsg, _ := c.testClientWithDetails(client, proxyType, result)
+	success, errorMsg, _ := c.testClientWithDetails(client, proxyType, c.config.ValidationURL, result)
 	return success, errorMsg
 }
 
 // testClient tests if the client works with a simple request
 func (c *Checker) testClient(client *http.Client, proxyType ProxyType, result *ProxyResult) bool {
-	success, _, _ := c.testClientWithDetails(client, proxyType, result)
+	success, _, _ := c.testClientWithDetails(client, proxyType, c.config.ValidationURL, result)
 	return success
 }
\ No newline at end of file
@@ -0,0 +1,109 @@
+package proxy
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// probeConnect issues a bare CONNECT to the validation host on port 443 and
+// records whether the proxy agreed to open a tunnel. This is independent of the
+// GET-based validation so a failed HTTPS check can be told apart from a proxy
+// that simply refuses CONNECT.
+func (c *Checker) probeConnect(proxyURL *url.URL, result *ProxyResult) {
+	target, err := url.Parse(c.config.ValidationURL)
+	if err != nil || target.Hostname() == "" {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[CONNECT] Skipping probe, invalid validation URL: %s\n", c.config.ValidationURL)
+		}
+		return
+	}
+	targetAddr := net.JoinHostPort(target.Hostname(), "443")
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[CONNECT] Probing CONNECT %s via %s\n", targetAddr, proxyURL.Host)
+	}
+
+	c.applyRateLimit(targetAddr, result)
+
+	conn, err := c.dialTimeout("tcp", proxyURL.Host, c.timeout(result))
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[CONNECT] Failed to connect to proxy: %v\n", err)
+		}
+		return
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(c.timeout(result)))
+
+	request := fmt.Sprintf("CONNECT %s HTTP/1.1\r\nHost: %s\r\n", targetAddr, targetAddr)
+	if c.config.UserAgent != "" {
+		request += fmt.Sprintf("User-Agent: %s\r\n", c.config.UserAgent)
+	}
+	if auth := c.getProxyAuth(proxyURL, result); auth != nil {
+		credentials := base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password))
+		request += fmt.Sprintf("Proxy-Authorization: Basic %s\r\n", credentials)
+	}
+	request += "\r\n"
+
+	if _, err := conn.Write([]byte(request)); err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[CONNECT] Failed to send request: %v\n", err)
+		}
+		return
+	}
+	recordTraffic(result, 1, 0)
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[CONNECT] Failed to read response: %v\n", err)
+		}
+		return
+	}
+	resp.Body.Close()
+
+	result.ConnectStatusCode = resp.StatusCode
+	result.SupportsConnect = resp.StatusCode >= 200 && resp.StatusCode < 300
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[CONNECT] Proxy answered %d (supported: %t)\n", resp.StatusCode, result.SupportsConnect)
+	}
+}
+
+// noteConnectOnly marks an HTTP proxy as CONNECT-only when it rejected the
+// plain GET with 405 or 501 but tunneled the HTTPS request
+func (c *Checker) noteConnectOnly(httpCheck *CheckResult, httpsSuccess bool, result *ProxyResult) {
+	if !httpsSuccess || httpCheck == nil || httpCheck.Success {
+		return
+	}
+	if httpCheck.StatusCode != http.StatusMethodNotAllowed && httpCheck.StatusCode != http.StatusNotImplemented {
+		return
+	}
+	result.ConnectOnly = true
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[CONNECT] Proxy rejected plain GET with %d but tunnels HTTPS, treating it as CONNECT-only\n", httpCheck.StatusCode)
+	}
+}
+
+// validationURL returns the URL used by the validation checks. CONNECT-only
+// proxies cannot fetch plain HTTP, so an http:// validation URL is upgraded to
+// https:// to go through a CONNECT tunnel instead.
+func (c *Checker) validationURL(result *ProxyResult) string {
+	if !result.ConnectOnly {
+		return c.config.ValidationURL
+	}
+	target, err := url.Parse(c.config.ValidationURL)
+	if err != nil || target.Scheme != "http" {
+		return c.config.ValidationURL
+	}
+	target.Scheme = "https"
+	if target.Port() == "80" {
+		target.Host = target.Hostname()
+	}
+	return target.String()
+}
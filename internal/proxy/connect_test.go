@@ -0,0 +1,144 @@
+package proxy
+
+import (
+	"bufio"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startConnectProxy starts a fake proxy that answers CONNECT requests with the given status line
+func startConnectProxy(t *testing.T, statusLine string) (net.Listener, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+
+	requests := make(chan string, 1)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				requestLine, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil {
+					return
+				}
+				requests <- strings.TrimSpace(requestLine)
+				conn.Write([]byte(statusLine + "\r\nContent-Length: 0\r\n\r\n"))
+			}(conn)
+		}
+	}()
+
+	return listener, requests
+}
+
+// TestProbeConnect tests that the CONNECT probe records support and the returned status
+func TestProbeConnect(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusLine string
+		supported  bool
+		statusCode int
+	}{
+		{"connect allowed", "HTTP/1.1 200 Connection established", true, 200},
+		{"connect forbidden", "HTTP/1.1 403 Forbidden", false, 403},
+		{"method not allowed", "HTTP/1.1 405 Method Not Allowed", false, 405},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, requests := startConnectProxy(t, tt.statusLine)
+			defer listener.Close()
+
+			checker := NewChecker(Config{
+				Timeout:       2 * time.Second,
+				ValidationURL: "https://api.ipify.org?format=json",
+			}, false, nil)
+
+			proxyURL, _ := url.Parse("http://" + listener.Addr().String())
+			result := &ProxyResult{}
+			checker.probeConnect(proxyURL, result)
+
+			if requestLine := <-requests; requestLine != "CONNECT api.ipify.org:443 HTTP/1.1" {
+				t.Errorf("Unexpected request line: %q", requestLine)
+			}
+			if result.SupportsConnect != tt.supported {
+				t.Errorf("SupportsConnect = %t, want %t", result.SupportsConnect, tt.supported)
+			}
+			if result.ConnectStatusCode != tt.statusCode {
+				t.Errorf("ConnectStatusCode = %d, want %d", result.ConnectStatusCode, tt.statusCode)
+			}
+		})
+	}
+}
+
+// TestProbeConnectUnreachableProxy tests that an unreachable proxy leaves the status unset
+func TestProbeConnectUnreachableProxy(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	checker := NewChecker(Config{
+		Timeout:       time.Second,
+		ValidationURL: "https://api.ipify.org",
+	}, false, nil)
+
+	proxyURL, _ := url.Parse("http://" + addr)
+	result := &ProxyResult{}
+	checker.probeConnect(proxyURL, result)
+
+	if result.SupportsConnect || result.ConnectStatusCode != 0 {
+		t.Errorf("Expected no CONNECT result for unreachable proxy, got supported=%t status=%d",
+			result.SupportsConnect, result.ConnectStatusCode)
+	}
+}
+
+// TestNoteConnectOnly tests that only a 405/501 rejection of plain GET combined with working HTTPS marks a proxy CONNECT-only
+func TestNoteConnectOnly(t *testing.T) {
+	checker := NewChecker(Config{}, false, nil)
+
+	tests := []struct {
+		name         string
+		httpCheck    *CheckResult
+		httpsSuccess bool
+		want         bool
+	}{
+		{"method not allowed", &CheckResult{StatusCode: 405}, true, true},
+		{"not implemented", &CheckResult{StatusCode: 501}, true, true},
+		{"https failed too", &CheckResult{StatusCode: 405}, false, false},
+		{"forbidden", &CheckResult{StatusCode: 403}, true, false},
+		{"http worked", &CheckResult{StatusCode: 200, Success: true}, true, false},
+		{"no http result", nil, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &ProxyResult{}
+			checker.noteConnectOnly(tt.httpCheck, tt.httpsSuccess, result)
+			if result.ConnectOnly != tt.want {
+				t.Errorf("ConnectOnly = %t, want %t", result.ConnectOnly, tt.want)
+			}
+		})
+	}
+}
+
+// TestValidationURLConnectOnly tests that CONNECT-only proxies validate against an https:// URL
+func TestValidationURLConnectOnly(t *testing.T) {
+	checker := NewChecker(Config{ValidationURL: "http://api.ipify.org:80/?format=json"}, false, nil)
+
+	if got := checker.validationURL(&ProxyResult{}); got != "http://api.ipify.org:80/?format=json" {
+		t.Errorf("Expected the configured URL for a regular proxy, got %s", got)
+	}
+	if got := checker.validationURL(&ProxyResult{ConnectOnly: true}); got != "https://api.ipify.org/?format=json" {
+		t.Errorf("Expected an https URL for a CONNECT-only proxy, got %s", got)
+	}
+}
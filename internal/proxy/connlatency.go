@@ -0,0 +1,55 @@
+package proxy
+
+import (
+	"context"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// withConnectTrace returns ctx with an httptrace hook that records on result
+// how long the connection through the proxy took to become usable: the TCP
+// connect to the proxy plus its handshake (SOCKS negotiation or CONNECT), but
+// not the TLS handshake with the target that may follow. This first-hop time
+// is recorded once, for the first request that opens a new connection, and is
+// kept apart from Speed, which covers the whole request.
+func withConnectTrace(ctx context.Context, result *ProxyResult) context.Context {
+	if result.ConnectLatency > 0 {
+		return ctx
+	}
+
+	var (
+		mu       sync.Mutex
+		start    time.Time
+		recorded bool
+	)
+	record := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if recorded || start.IsZero() {
+			return
+		}
+		recorded = true
+		result.ConnectLatency = time.Since(start)
+	}
+
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			mu.Lock()
+			start = time.Now()
+			mu.Unlock()
+		},
+		// For HTTPS targets the tunnel is up once the TLS handshake with the target starts
+		TLSHandshakeStart: record,
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				// A pooled connection says nothing about the cost of connecting
+				mu.Lock()
+				recorded = true
+				mu.Unlock()
+				return
+			}
+			record()
+		},
+	})
+}
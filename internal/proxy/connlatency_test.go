@@ -0,0 +1,56 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// TestConnectLatency tests that the first hop is measured apart from the
+// whole request, using a proxy that answers slowly once connected
+func TestConnectLatency(t *testing.T) {
+	const egressDelay = 200 * time.Millisecond
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(egressDelay)
+		w.Write([]byte("ok"))
+	}))
+	defer proxyServer.Close()
+	proxyURL, _ := url.Parse(proxyServer.URL)
+
+	const connectDelay = 50 * time.Millisecond
+	transport := &http.Transport{
+		Proxy: http.ProxyURL(proxyURL),
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			time.Sleep(connectDelay)
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+		DisableKeepAlives: true,
+	}
+	client := &http.Client{Transport: transport}
+	checker := NewChecker(Config{Timeout: 2 * time.Second, UserAgent: "test"}, false, nil)
+
+	result := &ProxyResult{}
+	resp, err := checker.makeRequest(client, "http://example.com/", result)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if result.ConnectLatency < connectDelay || result.ConnectLatency >= egressDelay {
+		t.Errorf("Expected connect latency between %v and %v, got %v", connectDelay, egressDelay, result.ConnectLatency)
+	}
+
+	// Only the first connection is recorded
+	first := result.ConnectLatency
+	resp, err = checker.makeRequest(client, "http://example.com/", result)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if result.ConnectLatency != first {
+		t.Errorf("Expected the first connect latency to be kept, got %v then %v", first, result.ConnectLatency)
+	}
+}
@@ -0,0 +1,101 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// detectionURLHTTP and detectionURLHTTPS are fetched through a proxy to
+	// find out which type it is
+	detectionURLHTTP  = "http://api.ipify.org?format=json"
+	detectionURLHTTPS = "https://api.ipify.org?format=json"
+)
+
+// detectionTypes maps the names accepted in Config.DetectionOrder to proxy types
+var detectionTypes = map[string]ProxyType{
+	"http":    ProxyTypeHTTP,
+	"https":   ProxyTypeHTTPS,
+	"socks4":  ProxyTypeSOCKS4,
+	"socks4a": ProxyTypeSOCKS4A,
+	"socks5":  ProxyTypeSOCKS5,
+}
+
+// IsDetectionType reports whether name can be used in Config.DetectionOrder
+func IsDetectionType(name string) bool {
+	_, ok := detectionTypes[strings.ToLower(name)]
+	return ok
+}
+
+// detectInOrder tries the proxy types of Config.DetectionOrder one after the
+// other and settles on the first that carries either the HTTP or the HTTPS
+// validation request. It replaces the default cascade, which tries HTTP
+// before SOCKS, for lists known to hold mostly one type of proxy.
+func (c *Checker) detectInOrder(proxyURL *url.URL, result *ProxyResult) (ProxyType, *http.Client, error) {
+	var lastError string
+
+	for _, name := range c.config.DetectionOrder {
+		scheme := strings.ToLower(name)
+		proxyType, ok := detectionTypes[scheme]
+		if !ok {
+			continue
+		}
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[TYPE] Trying as %s proxy (detection order)\n", proxyType)
+		}
+
+		client, err := c.createClient(proxyURL, scheme, result)
+		if err != nil {
+			lastError = fmt.Sprintf("client creation failed for %s: %v", proxyType, err)
+			continue
+		}
+
+		// Test with HTTP endpoint
+		httpSuccess, httpTestErr, httpCheckResult := c.testClientWithDetails(client, proxyType, detectionURLHTTP, result)
+		if httpCheckResult != nil {
+			result.CheckResults = append(result.CheckResults, *httpCheckResult)
+		}
+
+		// A refused or unroutable proxy fails every other attempt too
+		if httpCheckResult != nil && httpCheckResult.unreachable {
+			return ProxyTypeUnknown, nil, c.proxyUnreachableError(proxyURL, httpTestErr, result)
+		}
+
+		// Then test with HTTPS endpoint
+		httpsSuccess, httpsTestErr, httpsCheckResult := c.testClientWithDetails(client, proxyType, detectionURLHTTPS, result)
+		if httpsCheckResult != nil {
+			result.CheckResults = append(result.CheckResults, *httpsCheckResult)
+		}
+
+		if proxyType == ProxyTypeHTTP || proxyType == ProxyTypeHTTPS {
+			c.noteConnectOnly(httpCheckResult, httpsSuccess, result)
+		}
+		result.SupportsHTTP = result.SupportsHTTP || httpSuccess
+		result.SupportsHTTPS = result.SupportsHTTPS || httpsSuccess
+
+		if httpSuccess || httpsSuccess {
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[TYPE] Selected %s proxy (HTTP: %v, HTTPS: %v)\n",
+					proxyType, httpSuccess, httpsSuccess)
+			}
+			return proxyType, client, nil
+		}
+
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[TYPE] Failed as %s proxy: HTTP: %s, HTTPS: %s\n",
+				proxyType, httpTestErr, httpsTestErr)
+		}
+		lastError = fmt.Sprintf("HTTP: %s, HTTPS: %s", httpTestErr, httpsTestErr)
+	}
+
+	if proxyType, client, ok := c.detectAdvancedHTTP(proxyURL, result); ok {
+		return proxyType, client, nil
+	}
+
+	if lastError == "" {
+		lastError = "no proxy type in the detection order succeeded"
+	}
+	return ProxyTypeUnknown, nil, fmt.Errorf("could not determine proxy type: %s", lastError)
+}
@@ -0,0 +1,129 @@
+package proxy
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startHTTPOnlyProxy starts a fake HTTP proxy that answers plain requests,
+// refuses CONNECT and drops SOCKS handshakes, counting the handshakes
+func startHTTPOnlyProxy(t *testing.T) (net.Listener, *int32) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+
+	var socksHandshakes int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				first, err := reader.Peek(1)
+				if err != nil {
+					return
+				}
+				if first[0] == 0x04 || first[0] == 0x05 {
+					atomic.AddInt32(&socksHandshakes, 1)
+					return
+				}
+				req, err := http.ReadRequest(reader)
+				if err != nil {
+					return
+				}
+				if req.Method == http.MethodConnect {
+					conn.Write([]byte("HTTP/1.1 403 Forbidden\r\nContent-Length: 0\r\n\r\n"))
+					return
+				}
+				body := `{"ip":"203.0.113.7"}`
+				conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 20\r\n\r\n" + body))
+			}(conn)
+		}
+	}()
+
+	return listener, &socksHandshakes
+}
+
+// TestDetectInOrder tests that detection follows the configured order and
+// stops at the first proxy type that works
+func TestDetectInOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		order     []string
+		wantSOCKS bool
+	}{
+		{"http first", []string{"http", "socks5", "socks4"}, false},
+		{"socks first", []string{"socks5", "socks4", "http"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, socksHandshakes := startHTTPOnlyProxy(t)
+			defer listener.Close()
+
+			checker := NewChecker(Config{
+				Timeout:        2 * time.Second,
+				DetectionOrder: tt.order,
+			}, false, nil)
+
+			proxyURL, _ := url.Parse("//" + listener.Addr().String())
+			result := &ProxyResult{}
+			proxyType, client, err := checker.determineProxyType(proxyURL, result)
+			if err != nil {
+				t.Fatalf("Expected detection to succeed, got %v", err)
+			}
+			if proxyType != ProxyTypeHTTP || client == nil {
+				t.Errorf("Expected an HTTP proxy client, got %s", proxyType)
+			}
+			if !result.SupportsHTTP || result.SupportsHTTPS {
+				t.Errorf("Expected HTTP support only, got HTTP %t, HTTPS %t", result.SupportsHTTP, result.SupportsHTTPS)
+			}
+			if tried := atomic.LoadInt32(socksHandshakes) > 0; tried != tt.wantSOCKS {
+				t.Errorf("SOCKS tried = %t, want %t", tried, tt.wantSOCKS)
+			}
+		})
+	}
+}
+
+// TestDetectInOrderFailure tests that detection fails when no listed type works
+func TestDetectInOrderFailure(t *testing.T) {
+	listener, socksHandshakes := startHTTPOnlyProxy(t)
+	defer listener.Close()
+
+	checker := NewChecker(Config{
+		Timeout:        2 * time.Second,
+		DetectionOrder: []string{"socks5", "socks4"},
+	}, false, nil)
+
+	proxyURL, _ := url.Parse("//" + listener.Addr().String())
+	proxyType, client, err := checker.determineProxyType(proxyURL, &ProxyResult{})
+	if err == nil || client != nil || proxyType != ProxyTypeUnknown {
+		t.Fatalf("Expected detection to fail, got %s, %v", proxyType, err)
+	}
+	if atomic.LoadInt32(socksHandshakes) == 0 {
+		t.Error("Expected the SOCKS types to be tried")
+	}
+}
+
+// TestIsDetectionType tests the names accepted in the detection order
+func TestIsDetectionType(t *testing.T) {
+	for _, name := range []string{"http", "HTTPS", "socks4", "socks4a", "socks5"} {
+		if !IsDetectionType(name) {
+			t.Errorf("Expected %q to be a detection type", name)
+		}
+	}
+	for _, name := range []string{"", "socks", "ftp", "http2"} {
+		if IsDetectionType(name) {
+			t.Errorf("Expected %q to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// dnsLeakWait is how long DNS interactions are collected for each probe hostname
+const dnsLeakWait = 5 * time.Second
+
+// dnsLeakProbe hands out unique out-of-band hostnames and reports which
+// addresses queried them. InteractshTester implements it.
+type dnsLeakProbe interface {
+	GenerateURL() string
+	DNSQuerySources(hostname string, timeout time.Duration) []string
+}
+
+// DNSQuerySources returns the addresses that sent DNS queries for hostname
+func (t *InteractshTester) DNSQuerySources(hostname string, timeout time.Duration) []string {
+	var sources []string
+	for _, interaction := range t.CheckInteractions(interactshCorrelationID(hostname), timeout) {
+		if !strings.EqualFold(interaction.Protocol, "dns") {
+			continue
+		}
+		sources = append(sources, interactionHost(interaction.RemoteAddress))
+	}
+	return sources
+}
+
+// interactshCorrelationID returns the subdomain Interactsh files interactions under
+func interactshCorrelationID(hostname string) string {
+	id, _, _ := strings.Cut(hostname, ".")
+	return strings.ToLower(id)
+}
+
+// interactionHost strips the port from an interaction's remote address
+func interactionHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// checkDNSLeak detects proxies that make the client resolve target hostnames.
+// It requests a unique hostname through the proxy and reports a leak if that
+// hostname is queried by one of the client's resolvers, which are probed once
+// per checker.
+func (c *Checker) checkDNSLeak(client *http.Client, probe dnsLeakProbe, result *ProxyResult) {
+	c.clientResolversOnce.Do(func() {
+		c.clientResolvers = c.probeClientResolvers(probe)
+	})
+	if len(c.clientResolvers) == 0 {
+		if c.debug {
+			result.DebugInfo += "[DNS LEAK] Could not identify the client's resolver, skipping\n"
+		}
+		return
+	}
+
+	proxiedHost := probe.GenerateURL()
+	c.applyRateLimit(proxiedHost, result)
+	resp, err := client.Get(fmt.Sprintf("http://%s/", proxiedHost))
+	if err != nil {
+		// Nothing was requested through the proxy, so there is no lookup to wait for
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[DNS LEAK] Request through the proxy failed, result inconclusive: %v\n", err)
+		}
+		return
+	}
+	resp.Body.Close()
+
+	proxiedSources := probe.DNSQuerySources(proxiedHost, dnsLeakWait)
+	for _, source := range proxiedSources {
+		if c.clientResolvers[source] {
+			result.DNSLeak = true
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[DNS LEAK] Proxied hostname was resolved by the client's resolver %s\n", source)
+			}
+			return
+		}
+	}
+
+	if c.debug {
+		if len(proxiedSources) == 0 {
+			result.DebugInfo += "[DNS LEAK] No DNS query seen for the proxied hostname, result inconclusive\n"
+		} else {
+			result.DebugInfo += fmt.Sprintf("[DNS LEAK] Proxied hostname was resolved remotely by %s\n", strings.Join(proxiedSources, ", "))
+		}
+	}
+}
+
+// probeClientResolvers resolves a unique hostname through the checker's
+// resolver, the one a leaking client lookup would go to, and returns the
+// addresses that queried it
+func (c *Checker) probeClientResolvers(probe dnsLeakProbe) map[string]bool {
+	localHost := probe.GenerateURL()
+	ctx, cancel := context.WithTimeout(context.Background(), dnsLeakWait)
+	c.resolver().LookupHost(ctx, localHost)
+	cancel()
+
+	resolvers := make(map[string]bool)
+	for _, source := range probe.DNSQuerySources(localHost, dnsLeakWait) {
+		resolvers[source] = true
+	}
+	return resolvers
+}
@@ -0,0 +1,174 @@
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDNSLeakProbe hands out numbered hostnames and reports fixed DNS sources for them
+type fakeDNSLeakProbe struct {
+	next    int
+	sources map[string][]string
+	waited  []string // Hostnames DNSQuerySources was asked about
+}
+
+func (p *fakeDNSLeakProbe) GenerateURL() string {
+	p.next++
+	return fmt.Sprintf("probe%d.oast.example", p.next)
+}
+
+func (p *fakeDNSLeakProbe) DNSQuerySources(hostname string, timeout time.Duration) []string {
+	p.waited = append(p.waited, hostname)
+	return p.sources[hostname]
+}
+
+func TestCheckDNSLeak(t *testing.T) {
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer proxyServer.Close()
+	proxyURL, _ := url.Parse(proxyServer.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	tests := []struct {
+		name     string
+		proxied  []string
+		wantLeak bool
+	}{
+		{"resolved by the client's resolver", []string{"192.0.2.53"}, true},
+		{"resolved by the proxy's resolver", []string{"198.51.100.53"}, false},
+		{"no query seen", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := &fakeDNSLeakProbe{sources: map[string][]string{
+				"probe1.oast.example": {"192.0.2.53"},
+				"probe2.oast.example": tt.proxied,
+			}}
+			checker := NewChecker(Config{Timeout: 2 * time.Second}, true, nil)
+			result := &ProxyResult{}
+
+			checker.checkDNSLeak(client, probe, result)
+			if result.DNSLeak != tt.wantLeak {
+				t.Errorf("DNSLeak = %v, want %v (debug: %s)", result.DNSLeak, tt.wantLeak, result.DebugInfo)
+			}
+		})
+	}
+}
+
+func TestCheckDNSLeakWithoutClientResolver(t *testing.T) {
+	probe := &fakeDNSLeakProbe{sources: map[string][]string{}}
+	checker := NewChecker(Config{}, true, nil)
+	result := &ProxyResult{}
+
+	checker.checkDNSLeak(http.DefaultClient, probe, result)
+	if result.DNSLeak || probe.next != 1 {
+		t.Errorf("Expected the check to stop after the local probe, got leak=%v probes=%d", result.DNSLeak, probe.next)
+	}
+	if !strings.Contains(result.DebugInfo, "Could not identify") {
+		t.Errorf("Expected a debug note, got %q", result.DebugInfo)
+	}
+}
+
+func TestCheckDNSLeakProbesClientResolverOnce(t *testing.T) {
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer proxyServer.Close()
+	proxyURL, _ := url.Parse(proxyServer.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	probe := &fakeDNSLeakProbe{sources: map[string][]string{
+		"probe1.oast.example": {"192.0.2.53"},
+		"probe3.oast.example": {"192.0.2.53"},
+	}}
+	checker := NewChecker(Config{Timeout: 2 * time.Second}, true, nil)
+
+	first, second := &ProxyResult{}, &ProxyResult{}
+	checker.checkDNSLeak(client, probe, first)
+	checker.checkDNSLeak(client, probe, second)
+	if probe.next != 3 {
+		t.Errorf("Expected one local probe and one per proxy, got %d hostnames", probe.next)
+	}
+	if first.DNSLeak || !second.DNSLeak {
+		t.Errorf("Expected only the second proxy to leak, got %v and %v", first.DNSLeak, second.DNSLeak)
+	}
+}
+
+func TestProbeClientResolversUsesConfiguredResolver(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("Cannot listen on UDP: %v", err)
+	}
+	defer server.Close()
+	queried := make(chan struct{}, 16)
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := server.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if strings.Contains(string(buf[:n]), "oast") {
+				queried <- struct{}{}
+			}
+			server.WriteTo(fakeDNSAnswer(buf[:n], net.IPv4(192, 0, 2, 1)), addr)
+		}
+	}()
+
+	resolver, err := NewResolver(server.LocalAddr().String(), nil)
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+	probe := &fakeDNSLeakProbe{sources: map[string][]string{
+		"probe1.oast.example": {"192.0.2.53"},
+	}}
+	checker := NewChecker(Config{Resolver: resolver}, false, nil)
+
+	resolvers := checker.probeClientResolvers(probe)
+	if !resolvers["192.0.2.53"] {
+		t.Errorf("Expected the probe's DNS source to be returned, got %v", resolvers)
+	}
+	select {
+	case <-queried:
+	default:
+		t.Error("Expected the local probe to be resolved through the configured resolver")
+	}
+}
+
+func TestCheckDNSLeakFailedRequestDoesNotWait(t *testing.T) {
+	proxyServer := httptest.NewServer(http.NotFoundHandler())
+	proxyURL, _ := url.Parse(proxyServer.URL)
+	proxyServer.Close()
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	probe := &fakeDNSLeakProbe{sources: map[string][]string{
+		"probe1.oast.example": {"192.0.2.53"},
+	}}
+	checker := NewChecker(Config{Timeout: 2 * time.Second}, true, nil)
+	result := &ProxyResult{}
+
+	checker.checkDNSLeak(client, probe, result)
+	if len(probe.waited) != 1 || probe.waited[0] != "probe1.oast.example" {
+		t.Errorf("Expected to wait only for the local probe, waited for %v", probe.waited)
+	}
+	if result.DNSLeak || !strings.Contains(result.DebugInfo, "inconclusive") {
+		t.Errorf("Expected an inconclusive result, got leak=%v debug=%q", result.DNSLeak, result.DebugInfo)
+	}
+}
+
+func TestInteractshCorrelationID(t *testing.T) {
+	if got := interactshCorrelationID("ABC123xyz.oast.fun"); got != "abc123xyz" {
+		t.Errorf("interactshCorrelationID() = %q", got)
+	}
+	if got := interactionHost("203.0.113.7:5353"); got != "203.0.113.7" {
+		t.Errorf("interactionHost() = %q", got)
+	}
+}
@@ -0,0 +1,53 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// dnsRetryDelay is the wait before the first DNS retry; it doubles on each retry
+const dnsRetryDelay = 100 * time.Millisecond
+
+// isTransientDNSError reports whether err is a DNS failure that is likely to
+// succeed when retried right away (SERVFAIL, resolver timeout or temporary
+// failure). Unknown hosts and non-DNS errors such as refused connections are not.
+func isTransientDNSError(err error) bool {
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || dnsErr.IsNotFound {
+		return false
+	}
+	return dnsErr.IsTimeout || dnsErr.IsTemporary || strings.Contains(dnsErr.Err, "server misbehaving")
+}
+
+// withDNSRetry runs operation, retrying it up to DNSRetries times while it
+// fails with a transient DNS error. This is separate from the general retry
+// policy and applies even when RetryEnabled is off.
+func (c *Checker) withDNSRetry(operation func() error, operationName string, result *ProxyResult) error {
+	err := operation()
+	delay := dnsRetryDelay
+	for attempt := 1; attempt <= c.config.DNSRetries && isTransientDNSError(err); attempt++ {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[DNS RETRY] %s hit a transient DNS failure (%v), retry %d/%d in %v\n",
+				operationName, err, attempt, c.config.DNSRetries, delay)
+		}
+		time.Sleep(delay)
+		delay *= 2
+		err = operation()
+	}
+	return err
+}
+
+// doWithDNSRetry sends a body-less request, retrying transient DNS failures
+func (c *Checker) doWithDNSRetry(client *http.Client, req *http.Request, result *ProxyResult) (*http.Response, error) {
+	var resp *http.Response
+	err := c.withDNSRetry(func() error {
+		var err error
+		resp, err = client.Do(req)
+		return err
+	}, fmt.Sprintf("request to %s", req.URL), result)
+	return resp, err
+}
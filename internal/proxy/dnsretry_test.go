@@ -0,0 +1,69 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+	"testing"
+)
+
+func TestIsTransientDNSError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"servfail", &net.DNSError{Err: "server misbehaving", Name: "example.com"}, true},
+		{"resolver timeout", &net.DNSError{Err: "i/o timeout", IsTimeout: true}, true},
+		{"temporary failure", &net.DNSError{Err: "temporary failure", IsTemporary: true}, true},
+		{"unknown host", &net.DNSError{Err: "no such host", IsNotFound: true}, false},
+		{"wrapped servfail", &url.Error{Op: "Get", URL: "http://example.com", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "server misbehaving"}}}, true},
+		{"connection refused", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientDNSError(tt.err); got != tt.want {
+				t.Errorf("isTransientDNSError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithDNSRetry(t *testing.T) {
+	checker := NewChecker(Config{DNSRetries: 2}, false, nil)
+	servfail := &net.DNSError{Err: "server misbehaving", Name: "example.com"}
+
+	calls := 0
+	err := checker.withDNSRetry(func() error {
+		calls++
+		if calls < 2 {
+			return servfail
+		}
+		return nil
+	}, "lookup", &ProxyResult{})
+	if err != nil || calls != 2 {
+		t.Errorf("Expected success on the second call, got %d calls (err %v)", calls, err)
+	}
+
+	calls = 0
+	err = checker.withDNSRetry(func() error {
+		calls++
+		return servfail
+	}, "lookup", &ProxyResult{})
+	if !errors.Is(err, servfail) || calls != 3 {
+		t.Errorf("Expected 3 calls before giving up, got %d (err %v)", calls, err)
+	}
+
+	calls = 0
+	checker.withDNSRetry(func() error {
+		calls++
+		return fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED)
+	}, "request", &ProxyResult{})
+	if calls != 1 {
+		t.Errorf("Expected a refused connection not to be retried, got %d calls", calls)
+	}
+}
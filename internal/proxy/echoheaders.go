@@ -0,0 +1,143 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// maxEchoBodyBytes caps how much of a header-echo response is read; echoed
+// headers fit in far less, and a larger body fails to parse
+const maxEchoBodyBytes = 64 << 10
+
+// echoHeadersURL returns the header-echo endpoint used by -echo-headers: the
+// configured URL, falling back to the anonymity check endpoint
+func (c *Checker) echoHeadersURL() string {
+	if c.config.EchoHeadersURL != "" {
+		return c.config.EchoHeadersURL
+	}
+	return c.anonymityEndpoints()[0]
+}
+
+// recordReceivedHeaders sends the full configured header set through the proxy
+// to a header-echo endpoint and stores the headers the target actually received
+// in result.ReceivedHeaders, exposing headers the proxy injected or stripped
+func (c *Checker) recordReceivedHeaders(client *http.Client, result *ProxyResult) {
+	echoURL := c.echoHeadersURL()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout(result))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", echoURL, nil)
+	if err != nil {
+		return
+	}
+	for key, value := range c.config.DefaultHeaders {
+		req.Header.Set(key, value)
+	}
+	if c.config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+	sent := req.Header.Clone()
+
+	resp, err := c.doWithDNSRetry(client, req, result)
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[ECHO] Request to %s failed: %v\n", echoURL, err)
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxEchoBodyBytes))
+	if err != nil || resp.StatusCode != http.StatusOK {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[ECHO] %s returned status %d\n", echoURL, resp.StatusCode)
+		}
+		return
+	}
+
+	received, err := parseEchoedHeaders(body)
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[ECHO] Could not parse response from %s: %v\n", echoURL, err)
+		}
+		return
+	}
+	result.ReceivedHeaders = received
+
+	if c.debug {
+		added, stripped := diffEchoedHeaders(sent, received)
+		result.DebugInfo += fmt.Sprintf("[ECHO] Target received %d headers (added: %s; stripped: %s)\n",
+			len(received), joinOrNone(added), joinOrNone(stripped))
+	}
+}
+
+// parseEchoedHeaders extracts the "headers" object of an httpbin-style echo
+// response. Values may be a single string or a list of strings; header names
+// are canonicalized.
+func parseEchoedHeaders(body []byte) (map[string][]string, error) {
+	var echo struct {
+		Headers map[string]json.RawMessage `json:"headers"`
+	}
+	if err := json.Unmarshal(body, &echo); err != nil {
+		return nil, err
+	}
+	if echo.Headers == nil {
+		return nil, fmt.Errorf("response has no headers object")
+	}
+
+	headers := make(map[string][]string, len(echo.Headers))
+	for name, raw := range echo.Headers {
+		key := http.CanonicalHeaderKey(name)
+		var single string
+		if err := json.Unmarshal(raw, &single); err == nil {
+			headers[key] = append(headers[key], single)
+			continue
+		}
+		var multiple []string
+		if err := json.Unmarshal(raw, &multiple); err != nil {
+			return nil, fmt.Errorf("header %s: unsupported value %s", name, raw)
+		}
+		headers[key] = append(headers[key], multiple...)
+	}
+	return headers, nil
+}
+
+// transportHeaders are set by net/http itself rather than by the request, so
+// they are not reported as added by the proxy
+var transportHeaders = map[string]bool{
+	"Host":            true,
+	"Accept-Encoding": true,
+	"Content-Length":  true,
+}
+
+// diffEchoedHeaders returns the sorted names of headers the target received
+// that were not sent and of sent headers the target never saw
+func diffEchoedHeaders(sent http.Header, received map[string][]string) (added, stripped []string) {
+	for name := range received {
+		if _, ok := sent[name]; !ok && !transportHeaders[name] {
+			added = append(added, name)
+		}
+	}
+	for name := range sent {
+		if _, ok := received[name]; !ok {
+			stripped = append(stripped, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(stripped)
+	return added, stripped
+}
+
+// joinOrNone joins names for debug output, or returns "none"
+func joinOrNone(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
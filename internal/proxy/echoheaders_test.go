@@ -0,0 +1,75 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEchoedHeaders(t *testing.T) {
+	got, err := parseEchoedHeaders([]byte(`{"headers":{"via":"1.1 squid","X-Forwarded-For":["1.2.3.4","5.6.7.8"]}}`))
+	if err != nil {
+		t.Fatalf("parseEchoedHeaders() error = %v", err)
+	}
+	want := map[string][]string{
+		"Via":             {"1.1 squid"},
+		"X-Forwarded-For": {"1.2.3.4", "5.6.7.8"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseEchoedHeaders() = %v, want %v", got, want)
+	}
+
+	for _, body := range []string{`not json`, `{"origin":"1.2.3.4"}`, `{"headers":{"Via":1}}`} {
+		if _, err := parseEchoedHeaders([]byte(body)); err == nil {
+			t.Errorf("parseEchoedHeaders(%s) expected an error", body)
+		}
+	}
+}
+
+func TestRecordReceivedHeaders(t *testing.T) {
+	// Echo server standing in for a target behind a proxy that adds Via and drops DNT
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers := map[string]string{"Host": r.Host, "Via": "1.1 proxy"}
+		for name := range r.Header {
+			if name != "Dnt" {
+				headers[name] = r.Header.Get(name)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"headers": headers})
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{
+		Timeout:        time.Second,
+		UserAgent:      "ProxyHawk-Test",
+		DefaultHeaders: map[string]string{"DNT": "1"},
+		EchoHeadersURL: server.URL,
+	}, true, nil)
+
+	result := &ProxyResult{}
+	checker.recordReceivedHeaders(server.Client(), result)
+
+	if got := result.ReceivedHeaders["Via"]; len(got) != 1 || got[0] != "1.1 proxy" {
+		t.Errorf("Expected the injected Via header to be recorded, got %v", result.ReceivedHeaders)
+	}
+	if got := result.ReceivedHeaders["User-Agent"]; len(got) != 1 || got[0] != "ProxyHawk-Test" {
+		t.Errorf("Expected the User-Agent to be recorded, got %v", result.ReceivedHeaders)
+	}
+	if _, ok := result.ReceivedHeaders["Dnt"]; ok {
+		t.Errorf("Expected the stripped DNT header to be absent, got %v", result.ReceivedHeaders)
+	}
+	if !strings.Contains(result.DebugInfo, "added: Via") || !strings.Contains(result.DebugInfo, "stripped: Dnt") {
+		t.Errorf("Expected debug output to list added and stripped headers, got %q", result.DebugInfo)
+	}
+
+	server.Close()
+	failed := &ProxyResult{}
+	checker.recordReceivedHeaders(http.DefaultClient, failed)
+	if failed.ReceivedHeaders != nil {
+		t.Errorf("Expected no headers when the echo endpoint is unreachable, got %v", failed.ReceivedHeaders)
+	}
+}
@@ -0,0 +1,70 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// defaultEgressIPURL returns the caller's IP address as plain text
+const defaultEgressIPURL = "https://api.ipify.org"
+
+// recordEgressPTR looks up the IP address the proxy egresses from and its
+// reverse DNS (PTR) name, reusing the IP the anonymity check detected when
+// there is one. PTR names often name the hosting provider, e.g.
+// ec2-... or ...googleusercontent.com. A failed lookup leaves the fields empty.
+func (c *Checker) recordEgressPTR(client *http.Client, result *ProxyResult) {
+	ip, err := c.exitIP(client, result)
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[PTR] Egress IP lookup failed: %v\n", err)
+		}
+		return
+	}
+	result.EgressIP = ip
+
+	name, err := c.cachedLookup("ptr:"+ip, func() (string, error) {
+		var name string
+		err := c.withDNSRetry(func() error {
+			var err error
+			name, err = c.lookupRDNS(ip)
+			return err
+		}, "reverse lookup of "+ip, result)
+		return name, err
+	}, result)
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[PTR] Reverse lookup of %s failed: %v\n", ip, err)
+		}
+		return
+	}
+	result.EgressPTR = name
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[PTR] Egress IP %s, PTR: %s\n", ip, name)
+	}
+}
+
+// parseEgressIP extracts the IP address from an IP endpoint response, either a
+// bare address or a JSON object with an ip field
+func parseEgressIP(body []byte) (string, error) {
+	text := strings.TrimSpace(string(body))
+
+	if strings.HasPrefix(text, "{") {
+		var fields struct {
+			IP string `json:"ip"`
+		}
+		if err := json.Unmarshal([]byte(text), &fields); err != nil {
+			return "", fmt.Errorf("invalid egress IP response: %w", err)
+		}
+		text = strings.TrimSpace(fields.IP)
+	}
+
+	ip := net.ParseIP(text)
+	if ip == nil {
+		return "", fmt.Errorf("egress IP response %q is not an IP address", text)
+	}
+	return ip.String(), nil
+}
@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseEgressIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{"plain text", "203.0.113.7\n", "203.0.113.7", false},
+		{"ipify json", `{"ip":"203.0.113.7"}`, "203.0.113.7", false},
+		{"ipv6", "2001:db8::1", "2001:db8::1", false},
+		{"json without ip", `{"origin":"203.0.113.7"}`, "", true},
+		{"html", "<html>blocked</html>", "", true},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEgressIP([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseEgressIP() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseEgressIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordEgressPTR(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ip":"127.0.0.1"}`))
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{Timeout: time.Second, LookupEgressPTR: true, EgressIPURL: server.URL}, false, nil)
+
+	result := &ProxyResult{}
+	checker.recordEgressPTR(server.Client(), result)
+	if result.EgressIP != "127.0.0.1" {
+		t.Errorf("Expected egress IP 127.0.0.1, got %q", result.EgressIP)
+	}
+	if result.EgressPTR != "localhost" {
+		t.Errorf("Expected PTR localhost, got %q", result.EgressPTR)
+	}
+
+	server.Close()
+	failed := &ProxyResult{}
+	checker.recordEgressPTR(http.DefaultClient, failed)
+	if failed.EgressIP != "" || failed.EgressPTR != "" {
+		t.Errorf("Expected a failed lookup to leave the fields empty, got ip=%q ptr=%q", failed.EgressIP, failed.EgressPTR)
+	}
+}
+
+func TestRecordEgressPTRReusesDetectedIP(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("203.0.113.7"))
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{Timeout: time.Second, LookupEgressPTR: true, EgressIPURL: server.URL}, false, nil)
+
+	result := &ProxyResult{DetectedIP: "127.0.0.1"}
+	checker.recordEgressPTR(server.Client(), result)
+	if requests != 0 {
+		t.Errorf("Expected the detected IP to be reused, got %d egress IP requests", requests)
+	}
+	if result.EgressIP != "127.0.0.1" {
+		t.Errorf("Expected egress IP 127.0.0.1, got %q", result.EgressIP)
+	}
+}
@@ -0,0 +1,101 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/google/shlex"
+)
+
+const (
+	// maxExternalStderrBytes caps the stderr of the external validation
+	// command kept for debug output
+	maxExternalStderrBytes = 4 << 10
+	// externalWaitDelay bounds how long a killed external command's output
+	// is waited for, in case a background child keeps it open
+	externalWaitDelay = time.Second
+)
+
+// SplitCommand splits an external command line into its arguments the way a
+// shell would, honoring single and double quotes and backslash escapes. It
+// does not expand variables or globs.
+func SplitCommand(command string) ([]string, error) {
+	args, err := shlex.Split(command)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command %q: %v", command, err)
+	}
+	return args, nil
+}
+
+// validateResponseExternal pipes the validation response body to the
+// configured external command and accepts it only if the command exits 0.
+// The command is split with SplitCommand and run without a shell; it is
+// killed if it outlives the proxy timeout. The start of its stderr is kept
+// in DebugInfo.
+func (c *Checker) validateResponseExternal(body []byte, result *ProxyResult) error {
+	args, err := SplitCommand(c.config.ExternalValidationCommand)
+	if err != nil {
+		return fmt.Errorf("external validation command: %v", err)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout(result))
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(body)
+	stderr := &cappedBuffer{max: maxExternalStderrBytes}
+	cmd.Stderr = stderr
+	cmd.WaitDelay = externalWaitDelay
+
+	err = cmd.Run()
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[VALIDATE] External command %q exited: %v\n", args[0], exitStatus(err))
+		if stderr.Len() > 0 {
+			result.DebugInfo += fmt.Sprintf("[VALIDATE] External command stderr:\n%s\n",
+				strings.TrimRight(stderr.String(), "\n"))
+		}
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("external validation command timed out after %v", c.timeout(result))
+	}
+	if err != nil {
+		return fmt.Errorf("external validation command rejected the response: %v", err)
+	}
+	return nil
+}
+
+// cappedBuffer keeps the first max bytes written to it and silently drops
+// the rest, so a chatty command is neither stored in full nor failed. The
+// buffer is a field rather than embedded so io.Copy cannot bypass Write
+// through bytes.Buffer's ReadFrom.
+type cappedBuffer struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) Len() int       { return b.buf.Len() }
+func (b *cappedBuffer) String() string { return b.buf.String() }
+
+// exitStatus describes how a command finished for debug output
+func exitStatus(err error) string {
+	if err == nil {
+		return "exit status 0"
+	}
+	return err.Error()
+}
@@ -0,0 +1,97 @@
+package proxy
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateResponseExternal(t *testing.T) {
+	for _, name := range []string{"grep", "cat", "sleep", "sh"} {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Skipf("%s not available: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		command   string
+		body      string
+		wantErr   string
+		wantDebug string
+	}{
+		{name: "exit 0 accepts", command: "grep -q origin", body: `{"origin": "1.2.3.4"}`},
+		{name: "non-zero exit rejects", command: "grep -q origin", body: "blocked", wantErr: "rejected the response"},
+		{name: "stderr is captured", command: "cat /nonexistent/proxyhawk", wantErr: "rejected the response", wantDebug: "/nonexistent/proxyhawk"},
+		{name: "timeout kills the command", command: "sleep 5", wantErr: "timed out"},
+		{name: "quoted arguments stay whole", command: `grep -q "hello world"`, body: "hello world"},
+		{name: "quoted arguments are not split", command: `grep -q "hello world"`, body: "hello", wantErr: "rejected the response"},
+		{name: "unterminated quote is an error", command: `grep -q "hello`, body: "hello", wantErr: "invalid command"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(Config{
+				Timeout:                   200 * time.Millisecond,
+				ExternalValidationCommand: tt.command,
+			}, true, nil)
+
+			result := &ProxyResult{}
+			err := checker.validateResponseExternal([]byte(tt.body), result)
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Expected the response to be accepted, got %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+			if tt.wantDebug != "" && !strings.Contains(result.DebugInfo, tt.wantDebug) {
+				t.Errorf("Expected stderr in debug info, got:\n%s", result.DebugInfo)
+			}
+		})
+	}
+}
+
+func TestValidateResponseExternalStderrCapped(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skipf("sh not available: %v", err)
+	}
+
+	checker := NewChecker(Config{
+		Timeout:                   2 * time.Second,
+		ExternalValidationCommand: `sh -c "i=0; while [ $i -lt 2000 ]; do echo 0123456789 >&2; i=$((i+1)); done; exit 1"`,
+	}, true, nil)
+
+	result := &ProxyResult{}
+	if err := checker.validateResponseExternal([]byte("body"), result); err == nil {
+		t.Fatal("Expected the command to reject the response")
+	}
+	if len(result.DebugInfo) > maxExternalStderrBytes+512 {
+		t.Errorf("Expected stderr capped at %d bytes, debug info is %d bytes", maxExternalStderrBytes, len(result.DebugInfo))
+	}
+	if !strings.Contains(result.DebugInfo, "0123456789") {
+		t.Errorf("Expected the start of stderr in debug info, got:\n%s", result.DebugInfo)
+	}
+}
+
+func TestValidateResponseExternalBackgroundChild(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skipf("sh not available: %v", err)
+	}
+
+	// The background sleep inherits stderr and outlives the killed shell
+	checker := NewChecker(Config{
+		Timeout:                   200 * time.Millisecond,
+		ExternalValidationCommand: `sh -c "sleep 10 & sleep 10"`,
+	}, false, nil)
+
+	start := time.Now()
+	err := checker.validateResponseExternal([]byte("body"), &ProxyResult{})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected the command to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the check to return shortly after the timeout, took %v", elapsed)
+	}
+}
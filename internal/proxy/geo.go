@@ -0,0 +1,109 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// defaultGeoIPURL returns the caller's country code as plain text
+	defaultGeoIPURL = "https://ipinfo.io/country"
+
+	// maxGeoIPBodyBytes caps how much of the geolocation response is read
+	maxGeoIPBodyBytes = 4096
+)
+
+// checkExitCountry looks up the country the proxy egresses from and compares it
+// with the expected country. A failed lookup leaves ExitCountry empty and is not
+// treated as a mismatch.
+func (c *Checker) checkExitCountry(client *http.Client, result *ProxyResult) {
+	country, err := c.lookupExitCountry(client, result)
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[GEO] Exit country lookup failed: %v\n", err)
+		}
+		return
+	}
+
+	result.ExitCountry = country
+	result.CountryMismatch = !strings.EqualFold(country, result.ExpectedCountry)
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[GEO] Exit country: %s (expected: %s, mismatch: %t)\n",
+			country, result.ExpectedCountry, result.CountryMismatch)
+	}
+}
+
+// lookupExitCountry queries the geolocation endpoint through client
+func (c *Checker) lookupExitCountry(client *http.Client, result *ProxyResult) (string, error) {
+	geoURL := c.config.GeoIPURL
+	if geoURL == "" {
+		geoURL = defaultGeoIPURL
+	}
+
+	body, err := c.fetchIPInfo(client, geoURL, result)
+	if err != nil {
+		return "", err
+	}
+	return parseCountryCode(body)
+}
+
+// fetchIPInfo requests an IP information endpoint through client and returns
+// the start of its body
+func (c *Checker) fetchIPInfo(client *http.Client, infoURL string, result *ProxyResult) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout(result))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", infoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range c.config.DefaultHeaders {
+		req.Header.Set(key, value)
+	}
+	if c.config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	resp, err := c.doWithDNSRetry(client, req, result)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", infoURL, resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxGeoIPBodyBytes))
+}
+
+// parseCountryCode extracts a country code from a geolocation response, either
+// a bare code ("US") or a JSON object with a country, country_code or
+// countryCode field
+func parseCountryCode(body []byte) (string, error) {
+	text := strings.TrimSpace(string(body))
+
+	if strings.HasPrefix(text, "{") {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &fields); err != nil {
+			return "", fmt.Errorf("invalid geolocation response: %w", err)
+		}
+		text = ""
+		for _, key := range []string{"country", "country_code", "countryCode"} {
+			if value, ok := fields[key].(string); ok && value != "" {
+				text = value
+				break
+			}
+		}
+	}
+
+	if len(text) != 2 {
+		return "", fmt.Errorf("no country code in geolocation response")
+	}
+	return strings.ToUpper(text), nil
+}
@@ -0,0 +1,64 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseCountryCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{"plain text", "us\n", "US", false},
+		{"ipinfo json", `{"ip":"1.2.3.4","country":"DE"}`, "DE", false},
+		{"ip-api json", `{"status":"success","countryCode":"FR"}`, "FR", false},
+		{"country name", "United States", "", true},
+		{"json without country", `{"ip":"1.2.3.4"}`, "", true},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCountryCode([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCountryCode() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseCountryCode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckExitCountry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("NL\n"))
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{Timeout: time.Second, GeoIPURL: server.URL}, false, nil)
+
+	match := &ProxyResult{ExpectedCountry: "NL"}
+	checker.checkExitCountry(server.Client(), match)
+	if match.ExitCountry != "NL" || match.CountryMismatch {
+		t.Errorf("Expected a matching exit country, got country=%q mismatch=%t", match.ExitCountry, match.CountryMismatch)
+	}
+
+	mismatch := &ProxyResult{ExpectedCountry: "US"}
+	checker.checkExitCountry(server.Client(), mismatch)
+	if mismatch.ExitCountry != "NL" || !mismatch.CountryMismatch {
+		t.Errorf("Expected a mismatch against US, got country=%q mismatch=%t", mismatch.ExitCountry, mismatch.CountryMismatch)
+	}
+
+	server.Close()
+	failed := &ProxyResult{ExpectedCountry: "US"}
+	checker.checkExitCountry(http.DefaultClient, failed)
+	if failed.ExitCountry != "" || failed.CountryMismatch {
+		t.Errorf("Expected a failed lookup not to be flagged, got country=%q mismatch=%t", failed.ExitCountry, failed.CountryMismatch)
+	}
+}
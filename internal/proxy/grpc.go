@@ -0,0 +1,323 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/errors"
+	"golang.org/x/net/http2"
+)
+
+// gRPC health checking protocol (grpc.health.v1.HealthCheckResponse.ServingStatus)
+var grpcServingStatus = map[uint64]string{
+	0: "UNKNOWN",
+	1: "SERVING",
+	2: "NOT_SERVING",
+	3: "SERVICE_UNKNOWN",
+}
+
+// grpcHealthCheckPath is the method every gRPC health service implements
+const grpcHealthCheckPath = "/grpc.health.v1.Health/Check"
+
+// checkGRPC replaces HTTP validation when a gRPC target is configured: it
+// tunnels to the target through the proxy, issues grpc.health.v1.Health/Check
+// over HTTP/2 (h2c for grpc://, TLS for grpcs://) and records the serving
+// status. The proxy counts as working when the service reports SERVING, and
+// its speed is the time the health check took.
+func (c *Checker) checkGRPC(proxyURL *url.URL, result *ProxyResult) *ProxyResult {
+	target, err := url.Parse(c.config.GRPCTarget)
+	if err != nil || target.Host == "" || (target.Scheme != "grpc" && target.Scheme != "grpcs") {
+		result.Error = errors.NewProxyError(errors.ErrorProxyValidationFailed, "invalid gRPC target", result.ProxyURL,
+			fmt.Errorf("expected grpc://host:port or grpcs://host:port, got %q", c.config.GRPCTarget))
+		return result
+	}
+
+	scheme := strings.ToLower(proxyURL.Scheme)
+	if scheme == "" {
+		scheme = "http"
+	}
+	switch scheme {
+	case "http":
+		result.Type = ProxyTypeHTTP
+	case "https":
+		result.Type = ProxyTypeHTTPS
+	case "socks4":
+		result.Type = ProxyTypeSOCKS4
+	case "socks4a":
+		result.Type = ProxyTypeSOCKS4A
+	case "socks5":
+		result.Type = ProxyTypeSOCKS5
+	default:
+		result.Error = errors.NewProxyError(errors.ErrorProxyInvalidURL, "unsupported proxy scheme for gRPC", result.ProxyURL, nil)
+		return result
+	}
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[GRPC] Checking %s through %s proxy\n", c.config.GRPCTarget, scheme)
+	}
+
+	start := time.Now()
+	status, err := c.grpcHealthCheck(proxyURL, scheme, target, result)
+	if err != nil {
+		result.Error = errors.NewProxyError(errors.ErrorProxyNotWorking, "gRPC health check failed", result.ProxyURL, err)
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[GRPC] Health check failed: %v\n", err)
+		}
+		return result
+	}
+
+	result.Speed = time.Since(start)
+	result.SupportsGRPC = true
+	result.GRPCStatus = status
+	result.Working = status == "SERVING"
+	if !result.Working {
+		result.Error = errors.NewProxyError(errors.ErrorProxyValidationFailed, "gRPC service not serving", result.ProxyURL,
+			fmt.Errorf("health status %s", status))
+	}
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[GRPC] Health status: %s\n", status)
+	}
+	return result
+}
+
+// grpcHealthCheck opens an HTTP/2 connection to target through the proxy and
+// returns the health status it reports
+func (c *Checker) grpcHealthCheck(proxyURL *url.URL, scheme string, target *url.URL, result *ProxyResult) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout(result))
+	defer cancel()
+
+	targetAddr := target.Host
+	if target.Port() == "" {
+		port := "80"
+		if target.Scheme == "grpcs" {
+			port = "443"
+		}
+		targetAddr = net.JoinHostPort(target.Hostname(), port)
+	}
+
+	c.applyRateLimit(target.Hostname(), result)
+
+	conn, err := c.dialThroughProxy(ctx, proxyURL, scheme, targetAddr, result)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	requestScheme := "http"
+	if target.Scheme == "grpcs" {
+		tlsConfig, err := c.tlsConfig(result)
+		if err != nil {
+			return "", err
+		}
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = target.Hostname()
+		tlsConfig.NextProtos = []string{"h2"}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return "", fmt.Errorf("TLS handshake with %s failed: %w", targetAddr, err)
+		}
+		conn = tlsConn
+		requestScheme = "https"
+	}
+
+	clientConn, err := (&http2.Transport{}).NewClientConn(conn)
+	if err != nil {
+		return "", err
+	}
+	defer clientConn.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		requestScheme+"://"+targetAddr+grpcHealthCheckPath, bytes.NewReader(grpcHealthCheckRequest(c.config.GRPCService)))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	if c.config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	resp, err := clientConn.RoundTrip(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	recordTraffic(result, 1, 0)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected HTTP status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", err
+	}
+	recordTraffic(result, 0, int64(len(body)))
+
+	// Trailers-only responses carry grpc-status in the headers
+	grpcStatus := resp.Trailer.Get("Grpc-Status")
+	if grpcStatus == "" {
+		grpcStatus = resp.Header.Get("Grpc-Status")
+	}
+	if grpcStatus != "" && grpcStatus != "0" {
+		message := resp.Trailer.Get("Grpc-Message")
+		if message == "" {
+			message = resp.Header.Get("Grpc-Message")
+		}
+		return "", fmt.Errorf("grpc-status %s: %s", grpcStatus, message)
+	}
+
+	return parseGRPCHealthResponse(body)
+}
+
+// dialThroughProxy opens a connection to targetAddr through the proxy, using
+// CONNECT for HTTP(S) proxies and the SOCKS handshake otherwise
+func (c *Checker) dialThroughProxy(ctx context.Context, proxyURL *url.URL, scheme, targetAddr string, result *ProxyResult) (net.Conn, error) {
+	auth := c.getProxyAuth(proxyURL, result)
+
+	if strings.HasPrefix(scheme, "socks") {
+		return c.createAuthenticatedSOCKSDialer(proxyURL, scheme, auth, result)(ctx, "tcp", targetAddr)
+	}
+
+	conn, err := c.dialContext(ctx, "tcp", proxyURL.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname(), InsecureSkipVerify: true})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake with proxy failed: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	request := fmt.Sprintf("CONNECT %s HTTP/1.1\r\nHost: %s\r\n", targetAddr, targetAddr)
+	if c.config.UserAgent != "" {
+		request += fmt.Sprintf("User-Agent: %s\r\n", c.config.UserAgent)
+	}
+	if auth != nil {
+		credentials := base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password))
+		request += fmt.Sprintf("Proxy-Authorization: Basic %s\r\n", credentials)
+	}
+	request += "\r\n"
+
+	if _, err := conn.Write([]byte(request)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	recordTraffic(result, 1, 0)
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNECT response: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		conn.Close()
+		return nil, fmt.Errorf("proxy refused CONNECT to %s: %s", targetAddr, resp.Status)
+	}
+
+	conn.SetDeadline(time.Time{})
+	if reader.Buffered() > 0 {
+		// The target may have spoken first (HTTP/2 servers send SETTINGS right
+		// away), so keep whatever arrived with the CONNECT response
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a net.Conn whose reads drain a bufio.Reader first
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (b *bufferedConn) Read(p []byte) (int, error) {
+	return b.reader.Read(p)
+}
+
+// grpcHealthCheckRequest encodes a length-prefixed HealthCheckRequest{service}
+func grpcHealthCheckRequest(service string) []byte {
+	var message []byte
+	if service != "" {
+		// Field 1 (service), wire type 2 (length-delimited)
+		message = append(message, 0x0a)
+		message = binary.AppendUvarint(message, uint64(len(service)))
+		message = append(message, service...)
+	}
+
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	return append(frame, message...)
+}
+
+// parseGRPCHealthResponse decodes the status from a length-prefixed
+// HealthCheckResponse message
+func parseGRPCHealthResponse(body []byte) (string, error) {
+	if len(body) < 5 {
+		return "", fmt.Errorf("short gRPC response (%d bytes)", len(body))
+	}
+	if body[0] != 0 {
+		return "", fmt.Errorf("compressed gRPC responses are not supported")
+	}
+	length := binary.BigEndian.Uint32(body[1:5])
+	if uint32(len(body)-5) < length {
+		return "", fmt.Errorf("truncated gRPC response")
+	}
+	message := body[5 : 5+length]
+
+	// An empty message is the default status, UNKNOWN
+	var status uint64
+	for len(message) > 0 {
+		tag, n := binary.Uvarint(message)
+		if n <= 0 {
+			return "", fmt.Errorf("invalid gRPC response")
+		}
+		message = message[n:]
+
+		switch tag & 0x7 {
+		case 0: // varint
+			value, n := binary.Uvarint(message)
+			if n <= 0 {
+				return "", fmt.Errorf("invalid gRPC response")
+			}
+			message = message[n:]
+			if tag>>3 == 1 {
+				status = value
+			}
+		case 2: // length-delimited, skipped
+			size, n := binary.Uvarint(message)
+			if n <= 0 || uint64(len(message)-n) < size {
+				return "", fmt.Errorf("invalid gRPC response")
+			}
+			message = message[n+int(size):]
+		default:
+			return "", fmt.Errorf("unexpected field in gRPC response")
+		}
+	}
+
+	if name, ok := grpcServingStatus[status]; ok {
+		return name, nil
+	}
+	return fmt.Sprintf("STATUS_%d", status), nil
+}
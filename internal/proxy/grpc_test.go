@@ -0,0 +1,174 @@
+package proxy
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// startGRPCHealthServer starts an h2c server answering Health/Check with the given serving status
+func startGRPCHealthServer(t *testing.T, status byte) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != grpcHealthCheckPath || r.Header.Get("Content-Type") != "application/grpc" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.Write([]byte{0, 0, 0, 0, 2, 0x08, status})
+		w.Header().Set("Grpc-Status", "0")
+	})
+
+	server := &http2.Server{}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.ServeConn(conn, &http2.ServeConnOpts{Handler: handler})
+		}
+	}()
+	return listener
+}
+
+// startTunnelProxy starts a proxy that accepts CONNECT and pipes the tunnel to the requested address
+func startTunnelProxy(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				req, err := http.ReadRequest(reader)
+				if err != nil || req.Method != http.MethodConnect {
+					return
+				}
+				upstream, err := net.Dial("tcp", req.Host)
+				if err != nil {
+					conn.Write([]byte("HTTP/1.1 502 Bad Gateway\r\n\r\n"))
+					return
+				}
+				defer upstream.Close()
+				conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+				go io.Copy(upstream, reader)
+				io.Copy(conn, upstream)
+			}(conn)
+		}
+	}()
+	return listener
+}
+
+// TestCheckGRPC tests the health check through an HTTP CONNECT proxy
+func TestCheckGRPC(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  byte
+		want    string
+		working bool
+	}{
+		{"serving", 1, "SERVING", true},
+		{"not serving", 2, "NOT_SERVING", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := startGRPCHealthServer(t, tt.status)
+			defer server.Close()
+			proxyListener := startTunnelProxy(t)
+			defer proxyListener.Close()
+
+			checker := NewChecker(Config{
+				Timeout:    2 * time.Second,
+				GRPCTarget: "grpc://" + server.Addr().String(),
+			}, false, nil)
+
+			result := checker.Check("http://" + proxyListener.Addr().String())
+			if !result.SupportsGRPC {
+				t.Fatalf("SupportsGRPC = false, error: %v", result.Error)
+			}
+			if result.GRPCStatus != tt.want {
+				t.Errorf("GRPCStatus = %q, want %q", result.GRPCStatus, tt.want)
+			}
+			if result.Working != tt.working {
+				t.Errorf("Working = %t, want %t", result.Working, tt.working)
+			}
+			if result.Type != ProxyTypeHTTP {
+				t.Errorf("Type = %s, want %s", result.Type, ProxyTypeHTTP)
+			}
+			if result.Speed <= 0 {
+				t.Errorf("Speed = %v, want the health check duration", result.Speed)
+			}
+		})
+	}
+}
+
+// TestCheckGRPCInvalidTarget tests that a malformed target fails without dialing
+func TestCheckGRPCInvalidTarget(t *testing.T) {
+	checker := NewChecker(Config{Timeout: time.Second, GRPCTarget: "http://example.com:50051"}, false, nil)
+	result := checker.Check("http://127.0.0.1:1")
+	if result.Working || result.SupportsGRPC || result.Error == nil {
+		t.Errorf("Expected invalid target to fail, got working=%t grpc=%t err=%v", result.Working, result.SupportsGRPC, result.Error)
+	}
+}
+
+// TestParseGRPCHealthResponse tests decoding of HealthCheckResponse frames
+func TestParseGRPCHealthResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    []byte
+		want    string
+		wantErr bool
+	}{
+		{"serving", []byte{0, 0, 0, 0, 2, 0x08, 1}, "SERVING", false},
+		{"service unknown", []byte{0, 0, 0, 0, 2, 0x08, 3}, "SERVICE_UNKNOWN", false},
+		{"empty message", []byte{0, 0, 0, 0, 0}, "UNKNOWN", false},
+		{"unknown status", []byte{0, 0, 0, 0, 2, 0x08, 9}, "STATUS_9", false},
+		{"skips string field", []byte{0, 0, 0, 0, 5, 0x12, 1, 'x', 0x08, 1}, "SERVING", false},
+		{"short", []byte{0, 0}, "", true},
+		{"truncated", []byte{0, 0, 0, 0, 9, 0x08}, "", true},
+		{"compressed", []byte{1, 0, 0, 0, 2, 0x08, 1}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGRPCHealthResponse(tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGRPCHealthCheckRequest tests encoding of HealthCheckRequest frames
+func TestGRPCHealthCheckRequest(t *testing.T) {
+	if got := grpcHealthCheckRequest(""); string(got) != string([]byte{0, 0, 0, 0, 0}) {
+		t.Errorf("empty service: got %v", got)
+	}
+	want := []byte{0, 0, 0, 0, 5, 0x0a, 3, 'a', 'p', 'i'}
+	if got := grpcHealthCheckRequest("api"); string(got) != string(want) {
+		t.Errorf("service api: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// compareMinimalHeaders repeats the validation request with only Host and
+// User-Agent and records how its status differs from the full header set, to
+// diagnose targets or proxies that block on header order or presence.
+// fullStatus is 0 when the full-header request got no response.
+func (c *Checker) compareMinimalHeaders(client *http.Client, validationURL string, fullStatus int, result *ProxyResult) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout(result))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", validationURL, nil)
+	if err != nil {
+		return
+	}
+	if c.config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	resp, err := c.doWithDNSRetry(client, req, result)
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[HEADERS] Minimal-header request failed: %v\n", err)
+		}
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	result.MinimalHeadersChecked = true
+	result.MinimalHeadersStatus = resp.StatusCode
+	result.FullHeadersStatus = fullStatus
+	result.HeaderBlocking = !c.statusAccepted(fullStatus) && c.statusAccepted(resp.StatusCode)
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[HEADERS] Full headers: %d, minimal headers: %d (header blocking: %t)\n",
+			fullStatus, resp.StatusCode, result.HeaderBlocking)
+	}
+}
+
+// statusAccepted reports whether status counts as a successful response: the
+// required status code when one is configured, otherwise any 2xx
+func (c *Checker) statusAccepted(status int) bool {
+	if c.config.RequireStatusCode > 0 {
+		return status == c.config.RequireStatusCode
+	}
+	return status >= 200 && status < 300
+}
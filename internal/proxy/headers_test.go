@@ -0,0 +1,61 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCompareMinimalHeaders(t *testing.T) {
+	var gotHeaders http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = r.Header.Clone()
+		if r.Header.Get("Accept-Language") != "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{
+		Timeout:        time.Second,
+		UserAgent:      "ProxyHawk-Test",
+		DefaultHeaders: map[string]string{"Accept-Language": "en-US"},
+		MinimalHeaders: true,
+	}, false, nil)
+
+	result := &ProxyResult{}
+	checker.compareMinimalHeaders(server.Client(), server.URL, http.StatusForbidden, result)
+
+	if !result.MinimalHeadersChecked || result.MinimalHeadersStatus != http.StatusOK {
+		t.Fatalf("Expected the minimal-header request to succeed, got checked=%t status=%d",
+			result.MinimalHeadersChecked, result.MinimalHeadersStatus)
+	}
+	if !result.HeaderBlocking || result.FullHeadersStatus != http.StatusForbidden {
+		t.Errorf("Expected header blocking against a 403 with full headers, got blocking=%t full=%d",
+			result.HeaderBlocking, result.FullHeadersStatus)
+	}
+	if gotHeaders.Get("User-Agent") != "ProxyHawk-Test" || gotHeaders.Get("Accept-Language") != "" {
+		t.Errorf("Expected only the configured User-Agent to be sent, got %v", gotHeaders)
+	}
+
+	same := &ProxyResult{}
+	checker.compareMinimalHeaders(server.Client(), server.URL, http.StatusOK, same)
+	if same.HeaderBlocking {
+		t.Error("Expected no header blocking when the full header set was accepted")
+	}
+}
+
+func TestStatusAccepted(t *testing.T) {
+	checker := NewChecker(Config{}, false, nil)
+	if !checker.statusAccepted(204) || checker.statusAccepted(403) || checker.statusAccepted(0) {
+		t.Error("Expected only 2xx statuses to be accepted without a required status code")
+	}
+
+	strict := NewChecker(Config{RequireStatusCode: 200}, false, nil)
+	if strict.statusAccepted(204) || !strict.statusAccepted(200) {
+		t.Error("Expected only the required status code to be accepted")
+	}
+}
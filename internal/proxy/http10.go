@@ -0,0 +1,132 @@
+package proxy
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// HTTPVersion10 forces proxy requests to be written as HTTP/1.0
+	HTTPVersion10 = "1.0"
+
+	// HTTPVersion11 is the default request version used by net/http
+	HTTPVersion11 = "1.1"
+)
+
+// useHTTP10 reports whether the checker has been configured to test proxies over HTTP/1.0
+func (c *Checker) useHTTP10() bool {
+	return c.config.HTTPVersion == HTTPVersion10
+}
+
+// checkHTTP10 sends the validation request through an HTTP proxy as an HTTP/1.0
+// request. net/http always writes HTTP/1.1, so the request is written by hand
+// on a raw connection to the proxy.
+func (c *Checker) checkHTTP10(proxyURL *url.URL, result *ProxyResult) (*CheckResult, error) {
+	// HTTP/1.0 has no CONNECT tunnelling worth testing here, so always use a plain HTTP target
+	targetURL, err := url.Parse(c.config.ValidationURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validation URL: %w", err)
+	}
+	targetURL.Scheme = "http"
+
+	checkResult := &CheckResult{
+		URL: targetURL.String(),
+	}
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[HTTP/1.0] Testing %s via %s\n", targetURL, proxyURL.Host)
+	}
+
+	c.applyRateLimit(targetURL.String(), result)
+
+	start := time.Now()
+	conn, err := c.dialTimeout("tcp", proxyURL.Host, c.timeout(result))
+	if err != nil {
+		checkResult.Error = err.Error()
+		return checkResult, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(c.timeout(result)))
+
+	var raw strings.Builder
+	fmt.Fprintf(&raw, "GET %s HTTP/1.0\r\n", targetURL.String())
+	fmt.Fprintf(&raw, "Host: %s\r\n", targetURL.Host)
+	for key, value := range c.config.DefaultHeaders {
+		fmt.Fprintf(&raw, "%s: %s\r\n", key, value)
+	}
+	if c.config.UserAgent != "" {
+		fmt.Fprintf(&raw, "User-Agent: %s\r\n", c.config.UserAgent)
+	}
+	if auth := c.getProxyAuth(proxyURL, result); auth != nil {
+		credentials := base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password))
+		fmt.Fprintf(&raw, "Proxy-Authorization: Basic %s\r\n", credentials)
+	}
+	raw.WriteString("\r\n")
+
+	if _, err := io.WriteString(conn, raw.String()); err != nil {
+		checkResult.Error = err.Error()
+		return checkResult, err
+	}
+	recordTraffic(result, 1, 0)
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: "GET"})
+	if err != nil {
+		checkResult.Error = err.Error()
+		return checkResult, err
+	}
+	defer resp.Body.Close()
+
+	body, err := c.readBody(resp.Body, result)
+	recordTraffic(result, 0, int64(len(body)))
+	checkResult.Speed = time.Since(start)
+	checkResult.StatusCode = resp.StatusCode
+	checkResult.BodySize = int64(len(body))
+	c.recordRedirect(resp, checkResult, result)
+	if err != nil {
+		checkResult.Error = err.Error()
+		return checkResult, err
+	}
+
+	if !c.validateResponse(resp, body) {
+		checkResult.Error = "response validation failed"
+		return checkResult, fmt.Errorf("response validation failed (status %d)", resp.StatusCode)
+	}
+
+	checkResult.Success = true
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[HTTP/1.0] Success: %s %d in %v\n", resp.Proto, resp.StatusCode, checkResult.Speed)
+	}
+
+	return checkResult, nil
+}
+
+// checkHTTP10Support runs the HTTP/1.0 check and records the outcome on the result
+func (c *Checker) checkHTTP10Support(proxyURL *url.URL, result *ProxyResult) bool {
+	checkResult, err := c.checkHTTP10(proxyURL, result)
+	if checkResult != nil {
+		result.CheckResults = append(result.CheckResults, *checkResult)
+	}
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[HTTP/1.0] Check failed: %v\n", err)
+		}
+		return false
+	}
+
+	result.SupportsHTTP10 = true
+	if result.Speed == 0 {
+		result.Speed = checkResult.Speed
+	}
+	return true
+}
+
+// isPlainHTTPProxy reports whether a proxy URL can be tested with a raw HTTP/1.0 request
+func isPlainHTTPProxy(proxyURL *url.URL) bool {
+	return proxyURL.Host != "" && (proxyURL.Scheme == "" || proxyURL.Scheme == "http")
+}
@@ -0,0 +1,125 @@
+package proxy
+
+import (
+	"bufio"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startHTTP10OnlyProxy starts a fake forward proxy that answers HTTP/1.0
+// requests and rejects anything else with 505 HTTP Version Not Supported
+func startHTTP10OnlyProxy(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				requestLine, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil {
+					return
+				}
+				if strings.HasSuffix(strings.TrimSpace(requestLine), "HTTP/1.0") {
+					conn.Write([]byte("HTTP/1.0 200 OK\r\nContent-Type: text/plain\r\n\r\nhello from a legacy proxy"))
+					return
+				}
+				conn.Write([]byte("HTTP/1.1 505 HTTP Version Not Supported\r\nContent-Length: 0\r\n\r\n"))
+			}(conn)
+		}
+	}()
+
+	return listener
+}
+
+// TestCheckHTTP10 tests that a raw HTTP/1.0 request is sent through the proxy
+func TestCheckHTTP10(t *testing.T) {
+	listener := startHTTP10OnlyProxy(t)
+	defer listener.Close()
+
+	checker := NewChecker(Config{
+		Timeout:       2 * time.Second,
+		ValidationURL: "https://example.com/",
+		HTTPVersion:   HTTPVersion10,
+	}, false, nil)
+
+	proxyURL, _ := url.Parse("http://" + listener.Addr().String())
+	result := &ProxyResult{}
+
+	checkResult, err := checker.checkHTTP10(proxyURL, result)
+	if err != nil {
+		t.Fatalf("Expected HTTP/1.0 check to succeed, got %v", err)
+	}
+	if !checkResult.Success || checkResult.StatusCode != 200 {
+		t.Errorf("Unexpected check result: %+v", checkResult)
+	}
+	if checkResult.URL != "http://example.com/" {
+		t.Errorf("Expected HTTP/1.0 check to use a plain HTTP target, got %s", checkResult.URL)
+	}
+}
+
+// TestCheckReportsHTTP10OnlyProxy tests that a proxy which only works over
+// HTTP/1.0 is reported as working and flagged as HTTP/1.0 only
+func TestCheckReportsHTTP10OnlyProxy(t *testing.T) {
+	listener := startHTTP10OnlyProxy(t)
+	defer listener.Close()
+
+	config := Config{
+		Timeout:       2 * time.Second,
+		ValidationURL: "http://example.com/",
+	}
+	proxyAddr := "http://" + listener.Addr().String()
+
+	// With the default HTTP/1.1 requests the proxy is unusable
+	result := NewChecker(config, false, nil).Check(proxyAddr)
+	if result.Working || result.HTTP10Only {
+		t.Errorf("Expected proxy to fail over HTTP/1.1, got working=%t http10Only=%t", result.Working, result.HTTP10Only)
+	}
+
+	config.HTTPVersion = HTTPVersion10
+	result = NewChecker(config, false, nil).Check(proxyAddr)
+	if !result.Working || !result.HTTP10Only || !result.SupportsHTTP10 {
+		t.Errorf("Expected HTTP/1.0-only proxy to be reported, got working=%t http10Only=%t supportsHTTP10=%t",
+			result.Working, result.HTTP10Only, result.SupportsHTTP10)
+	}
+	if result.Type != ProxyTypeHTTP {
+		t.Errorf("Expected proxy type %s, got %s", ProxyTypeHTTP, result.Type)
+	}
+}
+
+// TestCheckHTTP10BodyCapped tests that the HTTP/1.0 check stops reading the
+// proxy's response at MaxResponseBytes
+func TestCheckHTTP10BodyCapped(t *testing.T) {
+	listener := startHTTP10OnlyProxy(t)
+	defer listener.Close()
+
+	checker := NewChecker(Config{
+		Timeout:          2 * time.Second,
+		ValidationURL:    "http://example.com/",
+		HTTPVersion:      HTTPVersion10,
+		MaxResponseBytes: 10,
+	}, false, nil)
+
+	proxyURL, _ := url.Parse("http://" + listener.Addr().String())
+	result := &ProxyResult{}
+
+	checkResult, err := checker.checkHTTP10(proxyURL, result)
+	if err == nil {
+		t.Fatal("Expected the oversized HTTP/1.0 response to fail the check")
+	}
+	if checkResult.BodySize != 10 {
+		t.Errorf("Expected the body read to stop at 10 bytes, read %d", checkResult.BodySize)
+	}
+	if result.UnboundedResponse != UnboundedSizeLimit {
+		t.Errorf("Expected UnboundedResponse %q, got %q", UnboundedSizeLimit, result.UnboundedResponse)
+	}
+}
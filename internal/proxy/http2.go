@@ -45,8 +45,8 @@ func (c *Checker) createHTTP2Transport(proxyURL *url.URL, scheme string, auth *P
 	}
 
 	transport := &http.Transport{
-		TLSHandshakeTimeout:   c.config.Timeout / 2,
-		ResponseHeaderTimeout: c.config.Timeout / 2,
+		TLSHandshakeTimeout:   c.timeout(result) / 2,
+		ResponseHeaderTimeout: c.timeout(result) / 2,
 		ExpectContinueTimeout: 1 * time.Second,
 		MaxIdleConns:          100,
 		MaxIdleConnsPerHost:   10,
@@ -91,6 +91,10 @@ func (c *Checker) createHTTP2Transport(proxyURL *url.URL, scheme string, auth *P
 		// In production, you would implement a custom DialTLS function
 	}
 
+	if c.config.BaseDialer != nil {
+		transport.DialContext = c.config.BaseDialer
+	}
+
 	if c.debug {
 		result.DebugInfo += fmt.Sprintf("[HTTP2] HTTP/2 transport configured with max concurrent streams: %d\n", 
 			http2Config.MaxConcurrentStreams)
@@ -112,11 +116,9 @@ func (c *Checker) testHTTP2Support(proxyURL *url.URL, scheme string, result *Pro
 	transport := c.createHTTP2Transport(proxyURL, scheme, auth, result)
 
 	client := &http.Client{
-		Transport: transport,
-		Timeout:   c.config.Timeout,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
+		Transport:     transport,
+		Timeout:       c.timeout(result),
+		CheckRedirect: c.checkRedirect(result),
 	}
 
 	// Test with HTTPS endpoint (HTTP/2 typically requires TLS)
@@ -52,7 +52,7 @@ func (c *Checker) testHTTP3Support(proxyURL *url.URL, scheme string, result *Pro
 
 	// Create a standard HTTPS client to check for HTTP/3 support indicators
 	client := &http.Client{
-		Timeout: c.config.Timeout,
+		Timeout: c.timeout(result),
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
@@ -67,7 +67,7 @@ func (c *Checker) testHTTP3Support(proxyURL *url.URL, scheme string, result *Pro
 	start := time.Now()
 	
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout(result))
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, "GET", testURL, nil)
@@ -0,0 +1,119 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultIPv6ValidationURL only has an AAAA record, so reaching it through a
+// proxy shows the proxy can connect out over IPv6
+const DefaultIPv6ValidationURL = "http://api6.ipify.org"
+
+// checkIPv6Connectivity requests an IPv6-only endpoint through client and
+// records in result.SupportsIPv6 whether the proxy reached it. Proxies that
+// only connect out over IPv4 report false; the rest of the check is unaffected.
+func (c *Checker) checkIPv6Connectivity(client *http.Client, result *ProxyResult) *CheckResult {
+	result.IPv6Checked = true
+	check := &CheckResult{URL: c.config.AdvancedChecks.IPv6ValidationURL}
+	if check.URL == "" {
+		check.URL = DefaultIPv6ValidationURL
+	}
+
+	// SOCKS4 requests carry a 4-byte address, so there is no way to ask for IPv6
+	if result.Type == ProxyTypeSOCKS4 {
+		check.Error = "SOCKS4 cannot address IPv6 targets"
+		if c.debug {
+			result.DebugInfo += "[IPV6] Skipped: SOCKS4 cannot address IPv6 targets\n"
+		}
+		return check
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout(result))
+	defer cancel()
+
+	targetURL, host, err := c.ipv6Target(ctx, check.URL, result)
+	if err != nil {
+		check.Error = err.Error()
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[IPV6] %v\n", err)
+		}
+		return check
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", targetURL, nil)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	req.Host = host
+	if c.config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+	c.applyRateLimit(req.URL.Hostname(), result)
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		check.Error = err.Error()
+		check.Speed = time.Since(start)
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[IPV6] %s not reachable through proxy: %v\n", targetURL, err)
+		}
+		return check
+	}
+	defer resp.Body.Close()
+
+	size, _ := io.Copy(io.Discard, resp.Body)
+	check.Speed = time.Since(start)
+	check.StatusCode = resp.StatusCode
+	check.BodySize = size
+	check.Success = resp.StatusCode < 400
+	if !check.Success {
+		check.Error = fmt.Sprintf("unexpected status code: %d", resp.StatusCode)
+	}
+	result.SupportsIPv6 = check.Success
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[IPV6] %s through proxy: status %d (IPv6 supported: %t)\n",
+			targetURL, resp.StatusCode, result.SupportsIPv6)
+	}
+	return check
+}
+
+// ipv6Target pins a plain HTTP validation URL to the host's AAAA address, so
+// the proxy has to dial it over IPv6 even when the host also has an A record.
+// It returns the URL to request and the Host header to send. HTTPS URLs are
+// left as is because the certificate will not match an address literal; they
+// must point at a host without A records.
+func (c *Checker) ipv6Target(ctx context.Context, rawURL string, result *ProxyResult) (string, string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid IPv6 validation URL: %v", err)
+	}
+	host := parsed.Host
+	if parsed.Scheme != "http" || net.ParseIP(parsed.Hostname()) != nil {
+		return rawURL, host, nil
+	}
+
+	var ips []net.IP
+	err = c.withDNSRetry(func() error {
+		var err error
+		ips, err = c.resolver().LookupIP(ctx, "ip6", parsed.Hostname())
+		return err
+	}, "AAAA lookup of "+parsed.Hostname(), result)
+	if err != nil || len(ips) == 0 {
+		return "", "", fmt.Errorf("no IPv6 address found for %s: %v", parsed.Hostname(), err)
+	}
+
+	if port := parsed.Port(); port != "" {
+		parsed.Host = net.JoinHostPort(ips[0].String(), port)
+	} else {
+		parsed.Host = "[" + ips[0].String() + "]"
+	}
+	return parsed.String(), host, nil
+}
@@ -0,0 +1,108 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// startForwardProxy runs a plain HTTP forward proxy that dials targets with network
+func startForwardProxy(t *testing.T, network string) *url.URL {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.RequestURI = ""
+		resp, err := transport.RoundTrip(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(proxy.Close)
+	proxyURL, _ := url.Parse(proxy.URL)
+	return proxyURL
+}
+
+func TestCheckIPv6Connectivity(t *testing.T) {
+	listener, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback not available: %v", err)
+	}
+	target := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "::1")
+	}))
+	target.Listener = listener
+	target.Start()
+	defer target.Close()
+
+	tests := []struct {
+		name    string
+		network string
+		want    bool
+	}{
+		{"dual-stack proxy", "tcp", true},
+		{"IPv4-only proxy", "tcp4", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(Config{
+				Timeout:        5 * time.Second,
+				AdvancedChecks: AdvancedChecks{TestIPv6: true, IPv6ValidationURL: target.URL},
+			}, false, nil)
+			client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(startForwardProxy(t, tt.network))}}
+			result := &ProxyResult{Type: ProxyTypeHTTP}
+
+			check := checker.checkIPv6Connectivity(client, result)
+			if !result.IPv6Checked || result.SupportsIPv6 != tt.want || check.Success != tt.want {
+				t.Errorf("IPv6Checked = %t, SupportsIPv6 = %t, check = %+v; want SupportsIPv6 %t",
+					result.IPv6Checked, result.SupportsIPv6, check, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckIPv6ConnectivitySOCKS4(t *testing.T) {
+	checker := NewChecker(Config{Timeout: time.Second}, false, nil)
+	result := &ProxyResult{Type: ProxyTypeSOCKS4}
+
+	// The client must not be used: SOCKS4 cannot carry an IPv6 address
+	check := checker.checkIPv6Connectivity(nil, result)
+	if !result.IPv6Checked || result.SupportsIPv6 || check.Error == "" {
+		t.Errorf("IPv6Checked = %t, SupportsIPv6 = %t, check = %+v", result.IPv6Checked, result.SupportsIPv6, check)
+	}
+}
+
+func TestIPv6TargetPinsAAAAAddress(t *testing.T) {
+	checker := NewChecker(Config{Timeout: time.Second}, false, nil)
+	ctx := context.Background()
+
+	// Address literals and HTTPS URLs are requested as given
+	for _, rawURL := range []string{"http://[2001:db8::1]:8080/ip", "https://api6.example.com/"} {
+		got, host, err := checker.ipv6Target(ctx, rawURL, &ProxyResult{})
+		if err != nil || got != rawURL {
+			t.Errorf("ipv6Target(%q) = %q, %q, %v; want unchanged", rawURL, got, host, err)
+		}
+	}
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip6", "localhost")
+	if err != nil || len(ips) == 0 {
+		t.Skip("localhost has no IPv6 address")
+	}
+	got, host, err := checker.ipv6Target(ctx, "http://localhost:8080/ip", &ProxyResult{})
+	if want := "http://" + net.JoinHostPort(ips[0].String(), "8080") + "/ip"; err != nil || got != want || host != "localhost:8080" {
+		t.Errorf("ipv6Target() = %q, %q, %v; want %q, localhost:8080", got, host, err, want)
+	}
+}
@@ -0,0 +1,101 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// targetFamilies caches whether validation hosts have IPv4 addresses, so a
+// host is looked up once per checker instead of once per proxy
+type targetFamilies struct {
+	mutex   sync.Mutex
+	entries map[string]string // hostname -> "ipv4", or "ipv6" when it has only AAAA records
+	group   singleflight.Group
+}
+
+// ipv6ValidationTarget decides whether the validation request shows if the
+// proxy can reach IPv6 targets: the URL host is an IPv6 literal or has only
+// AAAA records, or ForceIPv6Target is set. It returns the URL to request, the
+// Host header to send (empty = the URL's) and whether the request goes to an
+// IPv6 address. With ForceIPv6Target a plain HTTP URL is pinned to the host's
+// AAAA address; an error means the host has none. The address family of a
+// host is looked up once per checker.
+func (c *Checker) ipv6ValidationTarget(rawURL string, result *ProxyResult) (string, string, bool, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL, "", false, nil
+	}
+	hostname := parsed.Hostname()
+	if ip := net.ParseIP(hostname); ip != nil {
+		return rawURL, "", ip.To4() == nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout(result))
+	defer cancel()
+
+	if c.config.ForceIPv6Target && parsed.Scheme == "http" {
+		target, host, err := c.ipv6Target(ctx, rawURL, result)
+		if err != nil {
+			return "", "", false, err
+		}
+		return target, host, true, nil
+	}
+
+	family, err := c.targetFamily(ctx, hostname, result)
+	if err != nil {
+		// The validation request reports the lookup failure itself
+		return rawURL, "", false, nil
+	}
+
+	if family != "ipv6" && c.config.ForceIPv6Target && c.debug {
+		result.DebugInfo += "[IPV6] " + hostname + " also has IPv4 addresses and an HTTPS URL cannot be pinned to IPv6; not testing the IPv6 target\n"
+	}
+	return rawURL, "", family == "ipv6", nil
+}
+
+// targetFamily returns "ipv6" when hostname has only IPv6 addresses and
+// "ipv4" otherwise. Concurrent checks share one lookup; failed lookups are
+// not cached so a later check can retry.
+func (c *Checker) targetFamily(ctx context.Context, hostname string, result *ProxyResult) (string, error) {
+	c.targetFamilies.mutex.Lock()
+	family, ok := c.targetFamilies.entries[hostname]
+	c.targetFamilies.mutex.Unlock()
+	if ok {
+		return family, nil
+	}
+
+	value, err, _ := c.targetFamilies.group.Do(hostname, func() (interface{}, error) {
+		var ips []net.IP
+		err := c.withDNSRetry(func() error {
+			var err error
+			ips, err = c.resolver().LookupIP(ctx, "ip", hostname)
+			return err
+		}, "lookup of "+hostname, result)
+		if err != nil {
+			return "", err
+		}
+		family := "ipv6"
+		for _, ip := range ips {
+			if ip.To4() != nil {
+				family = "ipv4"
+				break
+			}
+		}
+
+		c.targetFamilies.mutex.Lock()
+		if c.targetFamilies.entries == nil {
+			c.targetFamilies.entries = make(map[string]string)
+		}
+		c.targetFamilies.entries[hostname] = family
+		c.targetFamilies.mutex.Unlock()
+		return family, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return value.(string), nil
+}
@@ -0,0 +1,159 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startIPv6Target runs a validation target listening only on the IPv6 loopback
+// and records the Host header of the last request
+func startIPv6Target(t *testing.T) (*httptest.Server, *string) {
+	listener, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback not available: %v", err)
+	}
+	var host string
+	target := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+		io.WriteString(w, "::1")
+	}))
+	target.Listener = listener
+	target.Start()
+	t.Cleanup(target.Close)
+	return target, &host
+}
+
+func TestPerformChecksIPv6Target(t *testing.T) {
+	target, _ := startIPv6Target(t)
+
+	tests := []struct {
+		name    string
+		network string
+		want    bool
+	}{
+		{"dual-stack proxy", "tcp", true},
+		{"IPv4-only proxy", "tcp4", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(Config{
+				Timeout:           5 * time.Second,
+				ValidationURL:     target.URL,
+				RequireStatusCode: http.StatusOK,
+			}, false, nil)
+			client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(startForwardProxy(t, tt.network))}}
+			result := &ProxyResult{Type: ProxyTypeHTTP}
+
+			err := checker.performChecks(client, result)
+			if (err == nil) != tt.want {
+				t.Errorf("performChecks() error = %v, want success %t", err, tt.want)
+			}
+			if !result.IPv6TargetChecked || result.SupportsIPv6Target != tt.want {
+				t.Errorf("IPv6TargetChecked = %t, SupportsIPv6Target = %t; want SupportsIPv6Target %t",
+					result.IPv6TargetChecked, result.SupportsIPv6Target, tt.want)
+			}
+		})
+	}
+}
+
+func TestPerformChecksIPv4TargetNotChecked(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "127.0.0.1")
+	}))
+	defer target.Close()
+
+	checker := NewChecker(Config{Timeout: 5 * time.Second, ValidationURL: target.URL}, false, nil)
+	result := &ProxyResult{Type: ProxyTypeHTTP}
+	if err := checker.performChecks(http.DefaultClient, result); err != nil {
+		t.Fatalf("performChecks() error = %v", err)
+	}
+	if result.IPv6TargetChecked || result.SupportsIPv6Target {
+		t.Errorf("IPv6TargetChecked = %t, SupportsIPv6Target = %t; want an IPv4 target not to count",
+			result.IPv6TargetChecked, result.SupportsIPv6Target)
+	}
+}
+
+func TestForceIPv6TargetPinsValidationURL(t *testing.T) {
+	ips, err := net.DefaultResolver.LookupIP(context.Background(), "ip6", "localhost")
+	if err != nil || len(ips) == 0 || !ips[0].IsLoopback() {
+		t.Skip("localhost has no IPv6 loopback address")
+	}
+	target, host := startIPv6Target(t)
+	targetURL, _ := url.Parse(target.URL)
+	validationURL := "http://localhost:" + targetURL.Port() + "/"
+
+	checker := NewChecker(Config{
+		Timeout:         5 * time.Second,
+		ValidationURL:   validationURL,
+		ForceIPv6Target: true,
+	}, false, nil)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(startForwardProxy(t, "tcp"))}}
+	result := &ProxyResult{Type: ProxyTypeHTTP}
+
+	if err := checker.performChecks(client, result); err != nil {
+		t.Fatalf("performChecks() error = %v", err)
+	}
+	if !result.IPv6TargetChecked || !result.SupportsIPv6Target {
+		t.Errorf("IPv6TargetChecked = %t, SupportsIPv6Target = %t; want both", result.IPv6TargetChecked, result.SupportsIPv6Target)
+	}
+	// The request went to the pinned address but kept the original Host header
+	if want := "localhost:" + targetURL.Port(); *host != want {
+		t.Errorf("target received Host %q, want %q", *host, want)
+	}
+}
+
+// TestTargetFamilyLookedUpOnce tests that concurrent and later checks share
+// one lookup of the validation host
+func TestTargetFamilyLookedUpOnce(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("Cannot listen on UDP: %v", err)
+	}
+	defer server.Close()
+	var queries int64
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := server.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			atomic.AddInt64(&queries, 1)
+			server.WriteTo(fakeDNSAnswer(buf[:n], net.IPv4(192, 0, 2, 10)), addr)
+		}
+	}()
+
+	resolver, err := NewResolver(server.LocalAddr().String(), nil)
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+	checker := NewChecker(Config{Timeout: 5 * time.Second, Resolver: resolver}, false, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, ipv6, err := checker.ipv6ValidationTarget("http://target.example.com/", &ProxyResult{})
+			if err != nil || ipv6 {
+				t.Errorf("ipv6ValidationTarget() = %t, %v; want an IPv4 target", ipv6, err)
+			}
+		}()
+	}
+	wg.Wait()
+	checker.ipv6ValidationTarget("http://target.example.com/", &ProxyResult{})
+
+	// One lookup asks for A and AAAA records
+	if n := atomic.LoadInt64(&queries); n > 2 {
+		t.Errorf("Expected a single lookup of the target, got %d DNS queries", n)
+	}
+}
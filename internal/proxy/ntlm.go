@@ -0,0 +1,394 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4"
+)
+
+// NTLM message flags (MS-NLMP 2.2.2.5)
+const (
+	ntlmNegotiateUnicode     = 0x00000001
+	ntlmNegotiateOEM         = 0x00000002
+	ntlmRequestTarget        = 0x00000004
+	ntlmNegotiateNTLM        = 0x00000200
+	ntlmNegotiateAlwaysSign  = 0x00008000
+	ntlmNegotiateExtendedSec = 0x00080000
+	ntlmNegotiateTargetInfo  = 0x00800000
+	ntlmNegotiate128         = 0x20000000
+	ntlmNegotiate56          = 0x80000000
+
+	ntlmNegotiateFlags = ntlmNegotiateUnicode | ntlmNegotiateOEM | ntlmRequestTarget | ntlmNegotiateNTLM |
+		ntlmNegotiateAlwaysSign | ntlmNegotiateExtendedSec | ntlmNegotiate128 | ntlmNegotiate56
+
+	// ntlmAvTimestamp is the AV pair carrying the server's FILETIME in the target info
+	ntlmAvTimestamp = 7
+)
+
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+// errProxyAuthRequired is returned by the NTLM tunnel dialer when the proxy
+// demands credentials that could not be satisfied
+var errProxyAuthRequired = errors.New("proxy authentication required")
+
+// ntlmChallenge is the part of an NTLM CHALLENGE message needed to answer it
+type ntlmChallenge struct {
+	flags           uint32
+	serverChallenge []byte
+	targetInfo      []byte
+}
+
+// authMethodEnabled reports whether method is one of the configured auth methods
+func (c *Checker) authMethodEnabled(method AuthMethod) bool {
+	for _, configured := range c.config.AuthMethods {
+		if strings.EqualFold(configured, string(method)) {
+			return true
+		}
+	}
+	return false
+}
+
+// useNTLM reports whether HTTP(S) proxy traffic must go through the NTLM
+// tunnel dialer instead of net/http's proxy support
+func (c *Checker) useNTLM(scheme string, auth *ProxyAuth) bool {
+	return auth != nil && (scheme == "http" || scheme == "https") && c.authMethodEnabled(AuthMethodNTLM)
+}
+
+// ntlmTunnelDialer returns a DialContext that opens a CONNECT tunnel through
+// the proxy to every address, answering an NTLM challenge when the proxy
+// replies 407 with Proxy-Authenticate: NTLM. Basic credentials are sent up
+// front when basic is also enabled. NTLM authenticates the connection rather
+// than a request, so plain HTTP targets are tunneled too.
+func (c *Checker) ntlmTunnelDialer(proxyURL *url.URL, scheme string, auth *ProxyAuth, result *ProxyResult) func(context.Context, string, string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, reader, err := c.dialProxyConn(ctx, proxyURL, scheme)
+		if err != nil {
+			return nil, err
+		}
+
+		basic := ""
+		if c.authMethodEnabled(AuthMethodBasic) {
+			basic = "Basic " + base64.StdEncoding.EncodeToString([]byte(auth.Username+":"+auth.Password))
+		}
+		resp, err := c.sendConnect(conn, reader, addr, basic, result)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return tunnelConn(conn, reader), nil
+		}
+		if resp.StatusCode != http.StatusProxyAuthRequired {
+			conn.Close()
+			return nil, fmt.Errorf("proxy refused CONNECT to %s: %s", addr, resp.Status)
+		}
+		if !offersNTLM(resp) {
+			conn.Close()
+			result.AuthRequired = true
+			return nil, fmt.Errorf("%w: proxy does not offer NTLM (%s)", errProxyAuthRequired,
+				strings.Join(resp.Header.Values("Proxy-Authenticate"), ", "))
+		}
+
+		// Proxies may drop the connection after the first 407
+		if resp.Close {
+			conn.Close()
+			if conn, reader, err = c.dialProxyConn(ctx, proxyURL, scheme); err != nil {
+				return nil, err
+			}
+		}
+
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[AUTH] Proxy requested NTLM, authenticating as %s\n", auth.Username)
+		}
+
+		resp, err = c.sendConnect(conn, reader, addr, "NTLM "+base64.StdEncoding.EncodeToString(ntlmNegotiateMessage()), result)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		challenge, err := ntlmChallengeFromResponse(resp)
+		if err != nil {
+			conn.Close()
+			result.AuthRequired = true
+			return nil, fmt.Errorf("%w: %v", errProxyAuthRequired, err)
+		}
+
+		domain, user := splitNTLMUser(auth.Username)
+		authenticate, err := ntlmAuthenticateMessage(challenge, domain, user, auth.Password, time.Now())
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		resp, err = c.sendConnect(conn, reader, addr, "NTLM "+base64.StdEncoding.EncodeToString(authenticate), result)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			conn.Close()
+			result.AuthRequired = true
+			return nil, fmt.Errorf("%w: NTLM authentication rejected (%s)", errProxyAuthRequired, resp.Status)
+		}
+
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[AUTH] NTLM authentication succeeded, tunnel to %s open\n", addr)
+		}
+		return tunnelConn(conn, reader), nil
+	}
+}
+
+// dialProxyConn connects to the proxy, speaking TLS to it for https proxies
+func (c *Checker) dialProxyConn(ctx context.Context, proxyURL *url.URL, scheme string) (net.Conn, *bufio.Reader, error) {
+	conn, err := c.dialContext(ctx, "tcp", proxyURL.Host)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to proxy: %w", err)
+	}
+	if scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname(), InsecureSkipVerify: true})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("TLS handshake with proxy failed: %w", err)
+		}
+		conn = tlsConn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	return conn, bufio.NewReader(conn), nil
+}
+
+// sendConnect writes a CONNECT for addr with an optional Proxy-Authorization
+// value and reads the response, draining its body so the connection can be reused
+func (c *Checker) sendConnect(conn net.Conn, reader *bufio.Reader, addr, authorization string, result *ProxyResult) (*http.Response, error) {
+	request := fmt.Sprintf("CONNECT %s HTTP/1.1\r\nHost: %s\r\n", addr, addr)
+	if c.config.UserAgent != "" {
+		request += fmt.Sprintf("User-Agent: %s\r\n", c.config.UserAgent)
+	}
+	if authorization != "" {
+		request += fmt.Sprintf("Proxy-Authorization: %s\r\n", authorization)
+	}
+	request += "Proxy-Connection: Keep-Alive\r\n\r\n"
+
+	if _, err := conn.Write([]byte(request)); err != nil {
+		return nil, err
+	}
+	recordTraffic(result, 1, 0)
+
+	resp, err := http.ReadResponse(reader, &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CONNECT response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		resp.Body.Close()
+	}
+	return resp, nil
+}
+
+// tunnelConn returns an established tunnel with no deadline, keeping any
+// bytes that arrived with the CONNECT response
+func tunnelConn(conn net.Conn, reader *bufio.Reader) net.Conn {
+	conn.SetDeadline(time.Time{})
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}
+	}
+	return conn
+}
+
+// offersNTLM reports whether a 407 response offers NTLM authentication
+func offersNTLM(resp *http.Response) bool {
+	for _, value := range resp.Header.Values("Proxy-Authenticate") {
+		if scheme, _, _ := strings.Cut(value, " "); strings.EqualFold(scheme, "NTLM") {
+			return true
+		}
+	}
+	return false
+}
+
+// splitNTLMUser splits a DOMAIN\user name into its domain and user. Names
+// without a backslash (plain or user@domain) are sent with an empty domain.
+func splitNTLMUser(username string) (string, string) {
+	if domain, user, ok := strings.Cut(username, `\`); ok {
+		return domain, user
+	}
+	return "", username
+}
+
+// ntlmChallengeFromResponse extracts the CHALLENGE message from a 407 response
+func ntlmChallengeFromResponse(resp *http.Response) (*ntlmChallenge, error) {
+	if resp.StatusCode != http.StatusProxyAuthRequired {
+		return nil, fmt.Errorf("expected an NTLM challenge, got %s", resp.Status)
+	}
+	for _, value := range resp.Header.Values("Proxy-Authenticate") {
+		scheme, token, _ := strings.Cut(value, " ")
+		if !strings.EqualFold(scheme, "NTLM") || strings.TrimSpace(token) == "" {
+			continue
+		}
+		message, err := base64.StdEncoding.DecodeString(strings.TrimSpace(token))
+		if err != nil {
+			return nil, fmt.Errorf("invalid NTLM challenge encoding: %v", err)
+		}
+		return parseNTLMChallenge(message)
+	}
+	return nil, errors.New("proxy did not send an NTLM challenge")
+}
+
+// ntlmNegotiateMessage builds the NEGOTIATE (type 1) message
+func ntlmNegotiateMessage() []byte {
+	message := make([]byte, 32)
+	copy(message, ntlmSignature)
+	binary.LittleEndian.PutUint32(message[8:], 1)
+	binary.LittleEndian.PutUint32(message[12:], ntlmNegotiateFlags)
+	// Empty domain and workstation fields point at the end of the message
+	binary.LittleEndian.PutUint32(message[20:], 32)
+	binary.LittleEndian.PutUint32(message[28:], 32)
+	return message
+}
+
+// parseNTLMChallenge decodes a CHALLENGE (type 2) message
+func parseNTLMChallenge(message []byte) (*ntlmChallenge, error) {
+	if len(message) < 32 || !bytes.Equal(message[:8], ntlmSignature) || binary.LittleEndian.Uint32(message[8:]) != 2 {
+		return nil, errors.New("not an NTLM challenge message")
+	}
+
+	challenge := &ntlmChallenge{
+		flags:           binary.LittleEndian.Uint32(message[20:]),
+		serverChallenge: message[24:32],
+	}
+	if len(message) >= 48 {
+		length := int(binary.LittleEndian.Uint16(message[40:]))
+		offset := int(binary.LittleEndian.Uint32(message[44:]))
+		if offset+length > len(message) {
+			return nil, errors.New("truncated NTLM target info")
+		}
+		challenge.targetInfo = message[offset : offset+length]
+	}
+	return challenge, nil
+}
+
+// ntlmAuthenticateMessage builds the AUTHENTICATE (type 3) message with an
+// NTLMv2 response to challenge
+func ntlmAuthenticateMessage(challenge *ntlmChallenge, domain, user, password string, now time.Time) ([]byte, error) {
+	clientChallenge := make([]byte, 8)
+	if _, err := rand.Read(clientChallenge); err != nil {
+		return nil, err
+	}
+
+	// Use the server's timestamp when it sends one; the LMv2 response must then be empty
+	timestamp, serverTime := ntlmTimestamp(challenge.targetInfo)
+	if !serverTime {
+		timestamp = ntlmFiletime(now)
+	}
+
+	hash := ntowfv2(domain, user, password)
+	ntResponse := ntlmv2Response(hash, challenge.serverChallenge, clientChallenge, timestamp, challenge.targetInfo)
+	lmResponse := make([]byte, 24)
+	if !serverTime {
+		lmResponse = lmv2Response(hash, challenge.serverChallenge, clientChallenge)
+	}
+
+	flags := uint32(ntlmNegotiateFlags) & challenge.flags
+	if flags&ntlmNegotiateUnicode == 0 {
+		flags |= ntlmNegotiateUnicode
+	}
+
+	fields := [][]byte{lmResponse, ntResponse, utf16LE(domain), utf16LE(user), nil, nil}
+	header := make([]byte, 64)
+	copy(header, ntlmSignature)
+	binary.LittleEndian.PutUint32(header[8:], 3)
+	binary.LittleEndian.PutUint32(header[60:], flags)
+
+	payload := []byte{}
+	offset := len(header)
+	for i, field := range fields {
+		position := 12 + i*8
+		binary.LittleEndian.PutUint16(header[position:], uint16(len(field)))
+		binary.LittleEndian.PutUint16(header[position+2:], uint16(len(field)))
+		binary.LittleEndian.PutUint32(header[position+4:], uint32(offset+len(payload)))
+		payload = append(payload, field...)
+	}
+	return append(header, payload...), nil
+}
+
+// ntowfv2 derives the NTLMv2 key from the credentials (MS-NLMP 3.3.2)
+func ntowfv2(domain, user, password string) []byte {
+	md4Hash := md4.New()
+	md4Hash.Write(utf16LE(password))
+	mac := hmac.New(md5.New, md4Hash.Sum(nil))
+	mac.Write(utf16LE(strings.ToUpper(user) + domain))
+	return mac.Sum(nil)
+}
+
+// ntlmv2Response computes NTProofStr followed by the client blob
+func ntlmv2Response(hash, serverChallenge, clientChallenge, timestamp, targetInfo []byte) []byte {
+	blob := []byte{0x01, 0x01, 0, 0, 0, 0, 0, 0}
+	blob = append(blob, timestamp...)
+	blob = append(blob, clientChallenge...)
+	blob = append(blob, 0, 0, 0, 0)
+	blob = append(blob, targetInfo...)
+	blob = append(blob, 0, 0, 0, 0)
+
+	mac := hmac.New(md5.New, hash)
+	mac.Write(serverChallenge)
+	mac.Write(blob)
+	return append(mac.Sum(nil), blob...)
+}
+
+// lmv2Response computes the LMv2 response
+func lmv2Response(hash, serverChallenge, clientChallenge []byte) []byte {
+	mac := hmac.New(md5.New, hash)
+	mac.Write(serverChallenge)
+	mac.Write(clientChallenge)
+	return append(mac.Sum(nil), clientChallenge...)
+}
+
+// ntlmTimestamp returns the MsvAvTimestamp value from the target info, if present
+func ntlmTimestamp(targetInfo []byte) ([]byte, bool) {
+	for len(targetInfo) >= 4 {
+		id := binary.LittleEndian.Uint16(targetInfo)
+		length := int(binary.LittleEndian.Uint16(targetInfo[2:]))
+		if id == 0 || 4+length > len(targetInfo) {
+			break
+		}
+		if id == ntlmAvTimestamp && length == 8 {
+			return targetInfo[4:12], true
+		}
+		targetInfo = targetInfo[4+length:]
+	}
+	return nil, false
+}
+
+// ntlmFiletime encodes t as a Windows FILETIME (100ns intervals since 1601)
+func ntlmFiletime(t time.Time) []byte {
+	const epochDelta = 116444736000000000
+	filetime := make([]byte, 8)
+	binary.LittleEndian.PutUint64(filetime, uint64(t.UnixNano()/100+epochDelta))
+	return filetime
+}
+
+// utf16LE encodes s as little-endian UTF-16
+func utf16LE(s string) []byte {
+	encoded := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(encoded))
+	for i, r := range encoded {
+		binary.LittleEndian.PutUint16(b[2*i:], r)
+	}
+	return b
+}
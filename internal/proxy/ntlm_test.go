@@ -0,0 +1,238 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// ntlmTestTargetInfo is the target info from the MS-NLMP 4.2.4 example
+func ntlmTestTargetInfo() []byte {
+	info := []byte{0x02, 0x00, 0x0c, 0x00}
+	info = append(info, utf16LE("Domain")...)
+	info = append(info, 0x01, 0x00, 0x0c, 0x00)
+	info = append(info, utf16LE("Server")...)
+	return append(info, 0, 0, 0, 0)
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// TestNTLMv2Vectors checks the NTLMv2 computations against MS-NLMP 4.2.4
+func TestNTLMv2Vectors(t *testing.T) {
+	serverChallenge := mustHex(t, "0123456789abcdef")
+	clientChallenge := bytes.Repeat([]byte{0xaa}, 8)
+
+	hash := ntowfv2("Domain", "User", "Password")
+	if want := mustHex(t, "0c868a403bfd7a93a3001ef22ef02e3f"); !bytes.Equal(hash, want) {
+		t.Fatalf("NTOWFv2 = %x, want %x", hash, want)
+	}
+
+	response := ntlmv2Response(hash, serverChallenge, clientChallenge, make([]byte, 8), ntlmTestTargetInfo())
+	if want := mustHex(t, "68cd0ab851e51c96aabc927bebef6a1c"); !bytes.Equal(response[:16], want) {
+		t.Errorf("NTProofStr = %x, want %x", response[:16], want)
+	}
+
+	lm := lmv2Response(hash, serverChallenge, clientChallenge)
+	if want := append(mustHex(t, "86c35097ac9cec102554764a57cccc19"), clientChallenge...); !bytes.Equal(lm, want) {
+		t.Errorf("LMv2 = %x, want %x", lm, want)
+	}
+}
+
+// TestSplitNTLMUser tests the supported username formats
+func TestSplitNTLMUser(t *testing.T) {
+	tests := []struct {
+		username, domain, user string
+	}{
+		{`CORP\alice`, "CORP", "alice"},
+		{"alice@corp.example.com", "", "alice@corp.example.com"},
+		{"alice", "", "alice"},
+	}
+	for _, tt := range tests {
+		if domain, user := splitNTLMUser(tt.username); domain != tt.domain || user != tt.user {
+			t.Errorf("splitNTLMUser(%q) = %q, %q; want %q, %q", tt.username, domain, user, tt.domain, tt.user)
+		}
+	}
+}
+
+// TestParseNTLMChallenge tests decoding a CHALLENGE message
+func TestParseNTLMChallenge(t *testing.T) {
+	targetInfo := ntlmTestTargetInfo()
+	challenge, err := parseNTLMChallenge(testNTLMChallengeMessage([]byte("12345678"), targetInfo))
+	if err != nil {
+		t.Fatalf("parseNTLMChallenge() error = %v", err)
+	}
+	if string(challenge.serverChallenge) != "12345678" || !bytes.Equal(challenge.targetInfo, targetInfo) {
+		t.Errorf("parseNTLMChallenge() = %+v", challenge)
+	}
+
+	if _, err := parseNTLMChallenge(ntlmNegotiateMessage()); err == nil {
+		t.Error("parseNTLMChallenge() accepted a NEGOTIATE message")
+	}
+}
+
+// testNTLMChallengeMessage builds a CHALLENGE message
+func testNTLMChallengeMessage(serverChallenge, targetInfo []byte) []byte {
+	message := make([]byte, 48)
+	copy(message, ntlmSignature)
+	binary.LittleEndian.PutUint32(message[8:], 2)
+	binary.LittleEndian.PutUint32(message[20:], ntlmNegotiateFlags|ntlmNegotiateTargetInfo)
+	copy(message[24:], serverChallenge)
+	binary.LittleEndian.PutUint16(message[40:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint16(message[42:], uint16(len(targetInfo)))
+	binary.LittleEndian.PutUint32(message[44:], 48)
+	return append(message, targetInfo...)
+}
+
+// ntlmSecBuf returns the payload a security buffer at offset points to
+func ntlmSecBuf(message []byte, offset int) []byte {
+	length := int(binary.LittleEndian.Uint16(message[offset:]))
+	start := int(binary.LittleEndian.Uint32(message[offset+4:]))
+	return message[start : start+length]
+}
+
+func decodeUTF16LE(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// startNTLMProxy runs a CONNECT proxy that requires NTLM with the given
+// credentials and tunnels authenticated connections to their target
+func startNTLMProxy(t *testing.T, domain, user, password string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start proxy: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	serverChallenge := []byte("proxyhwk")
+	challenge := base64.StdEncoding.EncodeToString(testNTLMChallengeMessage(serverChallenge, ntlmTestTargetInfo()))
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					req, err := http.ReadRequest(reader)
+					if err != nil {
+						return
+					}
+					token := strings.TrimPrefix(req.Header.Get("Proxy-Authorization"), "NTLM ")
+					message, _ := base64.StdEncoding.DecodeString(token)
+
+					switch {
+					case len(message) > 8 && message[8] == 1:
+						io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\nProxy-Authenticate: NTLM "+challenge+"\r\nContent-Length: 0\r\n\r\n")
+					case len(message) > 8 && message[8] == 3:
+						ntResponse := ntlmSecBuf(message, 20)
+						hash := ntowfv2(decodeUTF16LE(ntlmSecBuf(message, 28)), decodeUTF16LE(ntlmSecBuf(message, 36)), password)
+						mac := hmac.New(md5.New, hash)
+						mac.Write(serverChallenge)
+						mac.Write(ntResponse[16:])
+						if !hmac.Equal(mac.Sum(nil), ntResponse[:16]) ||
+							decodeUTF16LE(ntlmSecBuf(message, 28)) != domain || decodeUTF16LE(ntlmSecBuf(message, 36)) != user {
+							io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\nProxy-Authenticate: NTLM\r\nContent-Length: 0\r\n\r\n")
+							return
+						}
+						target, err := net.Dial("tcp", req.Host)
+						if err != nil {
+							io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+							return
+						}
+						defer target.Close()
+						io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+						go io.Copy(target, reader)
+						io.Copy(conn, target)
+						return
+					default:
+						// Close after the first 407 like many proxies do
+						io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\nProxy-Authenticate: NTLM\r\nProxy-Authenticate: Negotiate\r\nConnection: close\r\nContent-Length: 0\r\n\r\n")
+						return
+					}
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// TestNTLMProxyAuthentication tests the CONNECT handshake against an NTLM proxy
+func TestNTLMProxyAuthentication(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer target.Close()
+
+	proxyAddr := startNTLMProxy(t, "CORP", "alice", "s3cret")
+	checker := NewChecker(Config{Timeout: 5 * time.Second, AuthMethods: []string{"ntlm"}}, false, nil)
+
+	tests := []struct {
+		name     string
+		password string
+		wantOK   bool
+	}{
+		{"valid credentials", "s3cret", true},
+		{"wrong password", "wrong", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxyURL := &url.URL{Scheme: "http", Host: proxyAddr, User: url.UserPassword(`CORP\alice`, tt.password)}
+			result := &ProxyResult{}
+			client, err := checker.createClient(proxyURL, "http", result)
+			if err != nil {
+				t.Fatalf("createClient() error = %v", err)
+			}
+
+			resp, err := client.Get(target.URL)
+			if tt.wantOK {
+				if err != nil {
+					t.Fatalf("request through NTLM proxy failed: %v", err)
+				}
+				defer resp.Body.Close()
+				if body, _ := io.ReadAll(resp.Body); string(body) != "ok" {
+					t.Errorf("body = %q, want ok", body)
+				}
+				if result.AuthRequired {
+					t.Error("AuthRequired set after successful authentication")
+				}
+				return
+			}
+
+			if err == nil {
+				resp.Body.Close()
+				t.Fatal("request succeeded with the wrong password")
+			}
+			if !errors.Is(err, errProxyAuthRequired) || !result.AuthRequired {
+				t.Errorf("err = %v, AuthRequired = %t; want an auth-required failure", err, result.AuthRequired)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ProxyClass is the kind of network a proxy egresses from
+type ProxyClass string
+
+const (
+	ProxyClassDatacenter  ProxyClass = "datacenter"
+	ProxyClassResidential ProxyClass = "residential"
+	ProxyClassMobile      ProxyClass = "mobile"
+	ProxyClassUnknown     ProxyClass = "unknown"
+)
+
+// defaultProxyClassURL returns the caller's ASN and organization as plain text
+const defaultProxyClassURL = "https://ipinfo.io/org"
+
+// Built-in organization keywords, used when none are configured
+var (
+	defaultDatacenterKeywords = []string{
+		"hosting", "datacenter", "data center", "cloud", "server", "servers", "vps", "colo",
+		"colocation",
+		"amazon", "google", "microsoft", "digitalocean", "linode", "akamai", "ovh",
+		"hetzner", "vultr", "oracle", "alibaba", "tencent", "choopa", "leaseweb",
+		"contabo", "m247", "scaleway", "cloudflare", "fastly",
+	}
+	defaultResidentialKeywords = []string{
+		"telecom", "telecommunications", "communications", "broadband", "cable", "isp", "internet service",
+		"dsl", "fiber", "fibre", "comcast", "charter", "spectrum", "cox", "verizon",
+		"at&t", "centurylink", "deutsche telekom", "orange", "telefonica", "bt",
+		"virgin media", "rogers", "bell canada", "shaw",
+	}
+	defaultMobileKeywords = []string{
+		"mobile", "wireless", "cellular", "lte", "5g", "t-mobile", "vodafone",
+		"sprint", "airtel", "jio", "mts", "megafon", "beeline",
+	}
+)
+
+// ParseProxyClass parses a class name as accepted by -class
+func ParseProxyClass(name string) (ProxyClass, error) {
+	switch class := ProxyClass(strings.ToLower(strings.TrimSpace(name))); class {
+	case ProxyClassDatacenter, ProxyClassResidential, ProxyClassMobile, ProxyClassUnknown:
+		return class, nil
+	}
+	return "", fmt.Errorf("unknown proxy class %q (expected datacenter, residential, mobile or unknown)", name)
+}
+
+// classifyProxy looks up the organization the proxy egresses from and tags the
+// result as datacenter, residential or mobile from its keywords. A failed
+// lookup leaves the proxy unknown.
+func (c *Checker) classifyProxy(client *http.Client, result *ProxyResult) {
+	result.ProxyClass = ProxyClassUnknown
+
+	classURL := c.config.ProxyClassURL
+	if classURL == "" {
+		classURL = defaultProxyClassURL
+	}
+	body, err := c.fetchIPInfo(client, classURL, result)
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[CLASS] Exit organization lookup failed: %v\n", err)
+		}
+		return
+	}
+
+	result.ExitOrg = parseOrg(body)
+	result.ProxyClass = c.classifyOrg(result.ExitOrg)
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[CLASS] Exit organization: %q, class: %s\n", result.ExitOrg, result.ProxyClass)
+	}
+}
+
+// classifyOrg matches an ASN/organization string against the configured
+// keywords, as whole words so short ones like "lte" or "cox" do not match
+// inside longer names. Mobile keywords are checked first, since carriers also match the
+// generic ISP keywords, then datacenter and residential.
+func (c *Checker) classifyOrg(org string) ProxyClass {
+	org = strings.ToLower(org)
+	if org == "" {
+		return ProxyClassUnknown
+	}
+
+	classes := []struct {
+		class    ProxyClass
+		keywords []string
+		defaults []string
+	}{
+		{ProxyClassMobile, c.config.MobileKeywords, defaultMobileKeywords},
+		{ProxyClassDatacenter, c.config.DatacenterKeywords, defaultDatacenterKeywords},
+		{ProxyClassResidential, c.config.ResidentialKeywords, defaultResidentialKeywords},
+	}
+	for _, candidate := range classes {
+		keywords := candidate.keywords
+		if len(keywords) == 0 {
+			keywords = candidate.defaults
+		}
+		for _, keyword := range keywords {
+			if containsWord(org, strings.ToLower(strings.TrimSpace(keyword))) {
+				return candidate.class
+			}
+		}
+	}
+	return ProxyClassUnknown
+}
+
+// containsWord reports whether word occurs in text with no letter or digit
+// directly before or after it
+func containsWord(text, word string) bool {
+	if word == "" {
+		return false
+	}
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], word)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(word)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(text) || !isWordRune(after)) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+// isWordRune reports whether r is part of a word
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// parseOrg extracts the organization from an IP info response, either plain
+// text ("AS7922 Comcast Cable Communications, LLC") or a JSON object with an
+// org field
+func parseOrg(body []byte) string {
+	text := strings.TrimSpace(string(body))
+	if strings.HasPrefix(text, "{") {
+		var fields struct {
+			Org string `json:"org"`
+		}
+		if err := json.Unmarshal([]byte(text), &fields); err != nil {
+			return ""
+		}
+		text = fields.Org
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClassifyOrg(t *testing.T) {
+	checker := NewChecker(Config{}, false, nil)
+
+	tests := []struct {
+		org  string
+		want ProxyClass
+	}{
+		{"AS14061 DigitalOcean, LLC", ProxyClassDatacenter},
+		{"AS16509 Amazon.com, Inc.", ProxyClassDatacenter},
+		{"AS7922 Comcast Cable Communications, LLC", ProxyClassResidential},
+		{"AS21928 T-Mobile USA, Inc.", ProxyClassMobile},
+		{"AS64500 Example Org", ProxyClassUnknown},
+		{"AS64501 Colorado State University", ProxyClassUnknown},
+		{"AS64502 Elte Research Network", ProxyClassUnknown},
+		{"AS64503 Wisp Partners", ProxyClassUnknown},
+		{"AS64504 Smts Holdings", ProxyClassUnknown},
+		{"AS64505 Coxswain Rowing Club", ProxyClassUnknown},
+		{"AS64506 Example Colo", ProxyClassDatacenter},
+		{"AS64507 Example LTE", ProxyClassMobile},
+		{"AS22773 Cox Communications Inc.", ProxyClassResidential},
+		{"AS2856 British Telecommunications PLC (BT)", ProxyClassResidential},
+		{"", ProxyClassUnknown},
+	}
+	for _, tt := range tests {
+		if got := checker.classifyOrg(tt.org); got != tt.want {
+			t.Errorf("classifyOrg(%q) = %s, want %s", tt.org, got, tt.want)
+		}
+	}
+
+	custom := NewChecker(Config{ResidentialKeywords: []string{"example org"}}, false, nil)
+	if got := custom.classifyOrg("AS64500 Example Org"); got != ProxyClassResidential {
+		t.Errorf("Expected configured keywords to classify the org, got %s", got)
+	}
+	if got := custom.classifyOrg("AS7922 Comcast Cable Communications, LLC"); got != ProxyClassUnknown {
+		t.Errorf("Expected configured keywords to replace the built-in list, got %s", got)
+	}
+}
+
+func TestClassifyProxy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ip": "203.0.113.7", "org": "AS7922 Comcast Cable Communications, LLC"}`))
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{
+		Timeout:         time.Second,
+		ClassifyProxies: true,
+		ProxyClassURL:   server.URL,
+	}, false, nil)
+
+	result := &ProxyResult{}
+	checker.classifyProxy(server.Client(), result)
+	if result.ProxyClass != ProxyClassResidential || result.ExitOrg != "AS7922 Comcast Cable Communications, LLC" {
+		t.Errorf("Unexpected classification: class=%s org=%q", result.ProxyClass, result.ExitOrg)
+	}
+
+	server.Close()
+	failed := &ProxyResult{}
+	checker.classifyProxy(server.Client(), failed)
+	if failed.ProxyClass != ProxyClassUnknown || failed.ExitOrg != "" {
+		t.Errorf("Expected a failed lookup to leave the proxy unknown, got class=%s org=%q", failed.ProxyClass, failed.ExitOrg)
+	}
+}
+
+func TestParseProxyClass(t *testing.T) {
+	if class, err := ParseProxyClass(" Residential "); err != nil || class != ProxyClassResidential {
+		t.Errorf("ParseProxyClass() = %s, %v", class, err)
+	}
+	if _, err := ParseProxyClass("isp"); err == nil {
+		t.Error("Expected an unknown class to be rejected")
+	}
+}
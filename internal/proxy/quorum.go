@@ -0,0 +1,83 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// checkValidationQuorum requests each of the configured ValidationURLs through
+// the proxy and fails unless at least RequiredSuccessCount of them answered
+// with an accepted status. Every URL's outcome is appended to CheckResults.
+// It does nothing unless both settings are configured.
+func (c *Checker) checkValidationQuorum(client *http.Client, result *ProxyResult) error {
+	required := c.config.RequiredSuccessCount
+	if required <= 0 || len(c.config.ValidationURLs) == 0 {
+		return nil
+	}
+
+	successes := 0
+	for _, validationURL := range c.config.ValidationURLs {
+		check := c.fetchValidationURL(client, validationURL, result)
+		result.CheckResults = append(result.CheckResults, check)
+		if check.Success {
+			successes++
+		}
+
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[QUORUM] %s: success=%t status=%d %s\n",
+				validationURL, check.Success, check.StatusCode, check.Error)
+		}
+	}
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[QUORUM] %d of %d validation URLs succeeded (required: %d)\n",
+			successes, len(c.config.ValidationURLs), required)
+	}
+
+	if successes < required {
+		return fmt.Errorf("only %d of %d validation URLs succeeded (required: %d)",
+			successes, len(c.config.ValidationURLs), required)
+	}
+	return nil
+}
+
+// fetchValidationURL requests validationURL through client and reports the outcome
+func (c *Checker) fetchValidationURL(client *http.Client, validationURL string, result *ProxyResult) CheckResult {
+	check := CheckResult{URL: validationURL}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout(result))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", validationURL, nil)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	for key, value := range c.config.DefaultHeaders {
+		req.Header.Set(key, value)
+	}
+	if c.config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	start := time.Now()
+	resp, err := c.doWithDNSRetry(client, req, result)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	defer resp.Body.Close()
+
+	size, _ := io.Copy(io.Discard, resp.Body)
+	check.Speed = time.Since(start)
+	check.StatusCode = resp.StatusCode
+	check.BodySize = size
+	check.Success = c.statusAccepted(resp.StatusCode)
+	if !check.Success {
+		check.Error = fmt.Sprintf("unexpected status code: %d", resp.StatusCode)
+	}
+	return check
+}
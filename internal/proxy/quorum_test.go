@@ -0,0 +1,54 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCheckValidationQuorum(t *testing.T) {
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer ok.Close()
+	blocked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer blocked.Close()
+
+	urls := []string{ok.URL, blocked.URL, ok.URL + "/second"}
+
+	tests := []struct {
+		name     string
+		required int
+		wantErr  bool
+		checks   int
+	}{
+		{"disabled", 0, false, 0},
+		{"two of three", 2, false, 3},
+		{"all three", 3, true, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(Config{
+				Timeout:              time.Second,
+				ValidationURLs:       urls,
+				RequiredSuccessCount: tt.required,
+			}, false, nil)
+
+			result := &ProxyResult{}
+			err := checker.checkValidationQuorum(http.DefaultClient, result)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkValidationQuorum() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if len(result.CheckResults) != tt.checks {
+				t.Fatalf("Expected %d check results, got %d", tt.checks, len(result.CheckResults))
+			}
+			if tt.checks > 0 && (result.CheckResults[1].Success || result.CheckResults[1].StatusCode != http.StatusForbidden) {
+				t.Errorf("Expected the blocked URL to be recorded as a failed check, got %+v", result.CheckResults[1])
+			}
+		})
+	}
+}
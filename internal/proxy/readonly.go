@@ -0,0 +1,77 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Vuln checks that send state-changing or connection-desyncing requests, and
+// are skipped when ReadOnlyVulnChecks is set. TestHTTPMethods is also cut down
+// to GET and HEAD; every other vuln probe only issues GET or HEAD requests.
+const (
+	VulnCheckProtocolSmuggling   = "protocol-smuggling"          // POST with both Content-Length and Transfer-Encoding
+	VulnCheckHTTP2Smuggling      = "http2-request-smuggling"     // POST with CL+TE and a DELETE :method pseudo-header
+	VulnCheckIngressWebhook      = "nginx-ingress-cve-2025-1974" // POST of an AdmissionReview to the admission webhook
+	VulnCheckHAProxySmuggling    = "haproxy-cve-2023-40225"      // POST with a manipulated Content-Length
+	VulnCheckSquidSmuggling      = "squid-cve-2020-15810"        // Request smuggling with both Content-Length and Transfer-Encoding
+	VulnCheckEnvoyOriginalDst    = "envoy-cve-2022-21654"        // CONNECT through the original_dst cluster
+	VulnCheckVarnishBan          = "varnish-ban-lurker"          // BAN, which invalidates cached objects
+	VulnCheckVarnishSmuggling    = "varnish-cve-2022-45060"      // Request smuggling with both Content-Length and Transfer-Encoding
+	VulnCheckIMDSv2TokenWorkflow = "aws-imdsv2-token"            // PUT to the instance metadata token endpoint
+)
+
+// MutatingVulnChecks lists the checks skipped by ReadOnlyVulnChecks
+var MutatingVulnChecks = []string{
+	VulnCheckProtocolSmuggling,
+	VulnCheckHTTP2Smuggling,
+	VulnCheckIngressWebhook,
+	VulnCheckHAProxySmuggling,
+	VulnCheckSquidSmuggling,
+	VulnCheckEnvoyOriginalDst,
+	VulnCheckVarnishBan,
+	VulnCheckVarnishSmuggling,
+	VulnCheckIMDSv2TokenWorkflow,
+}
+
+// skipMutatingVulnCheck reports whether the named check must be skipped
+// because vuln scanning is restricted to read-only requests, noting each
+// skipped check once on the result
+func (c *Checker) skipMutatingVulnCheck(name string, result *ProxyResult) bool {
+	if !c.config.ReadOnlyVulnChecks {
+		return false
+	}
+
+	for _, skipped := range result.SkippedVulnChecks {
+		if skipped == name {
+			return true
+		}
+	}
+	result.SkippedVulnChecks = append(result.SkippedVulnChecks, name)
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[READ-ONLY] Skipping state-changing check: %s\n", name)
+	}
+	return true
+}
+
+// vulnTestMethods returns the configured TestHTTPMethods that may be sent:
+// all of them normally, only GET and HEAD when vuln scanning is read-only.
+// Dropped methods are noted on result when it is not nil.
+func (c *Checker) vulnTestMethods(result *ProxyResult) []string {
+	if !c.config.ReadOnlyVulnChecks {
+		return c.config.AdvancedChecks.TestHTTPMethods
+	}
+
+	var methods []string
+	for _, method := range c.config.AdvancedChecks.TestHTTPMethods {
+		switch strings.ToUpper(method) {
+		case http.MethodGet, http.MethodHead:
+			methods = append(methods, method)
+		default:
+			if result != nil {
+				c.skipMutatingVulnCheck("http-method-"+strings.ToUpper(method), result)
+			}
+		}
+	}
+	return methods
+}
@@ -0,0 +1,88 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestReadOnlyVulnChecks tests that read-only vuln scanning sends nothing but
+// GET and HEAD and notes the skipped checks on the result
+func TestReadOnlyVulnChecks(t *testing.T) {
+	var mutex sync.Mutex
+	methods := map[string]bool{}
+	transferEncoding := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		methods[r.Method] = true
+		if len(r.TransferEncoding) > 0 {
+			transferEncoding = true
+		}
+		mutex.Unlock()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{
+		Timeout:            2 * time.Second,
+		ValidationURL:      server.URL,
+		ReadOnlyVulnChecks: true,
+		AdvancedChecks: AdvancedChecks{
+			TestHTTPMethods: []string{"GET", "head", "DELETE", "PUT"},
+		},
+	}, false, nil)
+	client := &http.Client{Timeout: 2 * time.Second}
+
+	result := &ProxyResult{}
+	checker.performVendorVulnerabilityChecks(client, result)
+	checker.testHTTP2RequestSmuggling(client, result)
+	checker.testIngressWebhookCVE2025_1974(client, result)
+	checker.testVarnishBanLurk(client, result)
+
+	for method := range methods {
+		if method != http.MethodGet && method != http.MethodHead {
+			t.Errorf("Read-only vuln scanning sent a %s request", method)
+		}
+	}
+	if transferEncoding {
+		t.Error("Read-only vuln scanning sent a request with Transfer-Encoding")
+	}
+
+	if got := checker.vulnTestMethods(result); len(got) != 2 || got[0] != "GET" || got[1] != "head" {
+		t.Errorf("vulnTestMethods() = %v, want [GET head]", got)
+	}
+
+	skipped := map[string]int{}
+	for _, name := range result.SkippedVulnChecks {
+		skipped[name]++
+	}
+	for _, name := range []string{
+		VulnCheckHAProxySmuggling, VulnCheckSquidSmuggling, VulnCheckEnvoyOriginalDst,
+		VulnCheckVarnishBan, VulnCheckVarnishSmuggling, VulnCheckHTTP2Smuggling,
+		VulnCheckIngressWebhook, "http-method-DELETE", "http-method-PUT",
+	} {
+		if skipped[name] != 1 {
+			t.Errorf("Expected %s to be noted as skipped once, got %d", name, skipped[name])
+		}
+	}
+}
+
+// TestVulnChecksNotReadOnly tests that nothing is skipped by default
+func TestVulnChecksNotReadOnly(t *testing.T) {
+	checker := NewChecker(Config{
+		AdvancedChecks: AdvancedChecks{TestHTTPMethods: []string{"GET", "DELETE"}},
+	}, false, nil)
+
+	result := &ProxyResult{}
+	if checker.skipMutatingVulnCheck(VulnCheckVarnishBan, result) {
+		t.Error("Expected checks to run when vuln scanning is not read-only")
+	}
+	if got := checker.vulnTestMethods(result); len(got) != 2 {
+		t.Errorf("vulnTestMethods() = %v, want every configured method", got)
+	}
+	if len(result.SkippedVulnChecks) != 0 {
+		t.Errorf("Expected no skipped checks, got %v", result.SkippedVulnChecks)
+	}
+}
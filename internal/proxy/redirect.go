@@ -0,0 +1,125 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// DefaultMaxRedirects caps followed redirects when MaxRedirects is unset
+	DefaultMaxRedirects = 10
+
+	// RedirectLoop marks a redirect chain that came back to a URL it already visited
+	RedirectLoop = "redirect_loop"
+	// RedirectLimit marks a redirect chain longer than MaxRedirects
+	RedirectLimit = "too_many_redirects"
+)
+
+// redirectError aborts a redirect chain that loops or runs too long
+type redirectError struct {
+	reason string // RedirectLoop or RedirectLimit
+	url    string // The repeated URL of a loop, or the URL past the limit
+	count  int    // Redirect that revisited the URL, or the redirect limit
+}
+
+func (e *redirectError) Error() string {
+	if e.reason == RedirectLoop {
+		return fmt.Sprintf("redirect loop: redirect %d returned to %s", e.count, e.url)
+	}
+	return fmt.Sprintf("stopped after %d redirects", e.count)
+}
+
+// asRedirectError returns the redirect error wrapped in err, if any
+func asRedirectError(err error) (*redirectError, bool) {
+	var redirectErr *redirectError
+	if errors.As(err, &redirectErr) {
+		return redirectErr, true
+	}
+	return nil, false
+}
+
+// checkRedirect returns the redirect policy of the proxy clients. Unless
+// FollowRedirects is set, the redirect response itself is returned. Followed
+// chains are aborted as soon as they revisit a URL, which catches a loop on
+// the first repeat instead of at the count cap, and after MaxRedirects hops.
+// The reason is recorded in result.RedirectFailure.
+func (c *Checker) checkRedirect(result *ProxyResult) func(req *http.Request, via []*http.Request) error {
+	if !c.config.FollowRedirects {
+		return func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	maxRedirects := c.config.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+
+	return func(req *http.Request, via []*http.Request) error {
+		target := req.URL.String()
+		for _, previous := range via {
+			if previous.URL.String() != target {
+				continue
+			}
+			result.RedirectFailure = RedirectLoop
+			if c.debug {
+				chain := make([]string, 0, len(via)+1)
+				for _, hop := range via {
+					chain = append(chain, hop.URL.String())
+				}
+				result.DebugInfo += fmt.Sprintf("[REDIRECT] Loop detected, %s repeated: %s\n",
+					target, strings.Join(append(chain, target), " -> "))
+			}
+			return &redirectError{reason: RedirectLoop, url: target, count: len(via)}
+		}
+
+		if len(via) > maxRedirects {
+			result.RedirectFailure = RedirectLimit
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[REDIRECT] Stopped after %d redirects at %s\n", maxRedirects, target)
+			}
+			return &redirectError{reason: RedirectLimit, url: target, count: maxRedirects}
+		}
+
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[REDIRECT] Following redirect %d/%d to %s\n", len(via), maxRedirects, target)
+		}
+		return nil
+	}
+}
+
+// recordRedirect notes a redirect response on the check result. Redirects are
+// not followed unless FollowRedirects is set, so keeping the status and
+// Location explains checks that would otherwise just fail on an unexpected
+// status or a small body.
+func (c *Checker) recordRedirect(resp *http.Response, checkResult *CheckResult, result *ProxyResult) {
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 {
+		return
+	}
+
+	location, err := resp.Location()
+	if err != nil {
+		// No Location header, or one that doesn't parse; keep it verbatim if present
+		checkResult.RedirectLocation = resp.Header.Get("Location")
+	} else {
+		checkResult.RedirectLocation = location.String()
+	}
+	if checkResult.RedirectLocation == "" {
+		return
+	}
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[REDIRECT] %s answered %d redirecting to %s (not followed)\n",
+			checkResult.URL, resp.StatusCode, checkResult.RedirectLocation)
+	}
+}
+
+// redirectNote returns a suffix describing a recorded redirect for validation errors
+func redirectNote(checkResult CheckResult) string {
+	if checkResult.RedirectLocation == "" {
+		return ""
+	}
+	return fmt.Sprintf(", redirected %d to %s", checkResult.StatusCode, checkResult.RedirectLocation)
+}
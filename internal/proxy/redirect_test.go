@@ -0,0 +1,139 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/errors"
+)
+
+// TestPerformChecksRecordsRedirect tests that a redirect from the target is
+// recorded on the check result and explained in the validation error
+func TestPerformChecksRecordsRedirect(t *testing.T) {
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://login.example.com/sso", http.StatusMovedPermanently)
+	}))
+	defer proxyServer.Close()
+
+	checker := NewChecker(Config{
+		Timeout:          2 * time.Second,
+		ValidationURL:    "http://example.com/",
+		MinResponseBytes: 500,
+	}, false, nil)
+
+	proxyURL, _ := url.Parse(proxyServer.URL)
+	client := &http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	result := &ProxyResult{}
+
+	err := checker.performChecks(client, result)
+	if err == nil || !strings.Contains(err.Error(), "redirected 301 to https://login.example.com/sso") {
+		t.Fatalf("Expected the redirect in the validation error, got %v", err)
+	}
+
+	if len(result.CheckResults) != 1 {
+		t.Fatalf("Expected 1 check result, got %d", len(result.CheckResults))
+	}
+	check := result.CheckResults[0]
+	if check.StatusCode != http.StatusMovedPermanently || check.RedirectLocation != "https://login.example.com/sso" {
+		t.Errorf("Expected 301 to https://login.example.com/sso, got %d to %q", check.StatusCode, check.RedirectLocation)
+	}
+}
+
+// redirectTarget serves /ok and redirects every other path as the routes say;
+// unlisted paths redirect to the next number, an endless chain of new URLs
+func redirectTarget(routes map[string]string) *httptest.Server {
+	hops := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ok" {
+			w.Write([]byte(`{"ip":"203.0.113.7"}`))
+			return
+		}
+		next, ok := routes[r.URL.Path]
+		if !ok {
+			hops++
+			next = fmt.Sprintf("/hop/%d", hops)
+		}
+		http.Redirect(w, r, next, http.StatusFound)
+	}))
+}
+
+// TestFollowRedirects tests that followed redirect chains end in the final
+// response, abort on the first repeated URL, and stop at the redirect cap
+func TestFollowRedirects(t *testing.T) {
+	tests := []struct {
+		name     string
+		routes   map[string]string
+		reason   string
+		repeated string
+	}{
+		{"chain to success", map[string]string{"/start": "/middle", "/middle": "/ok"}, "", ""},
+		{"loop", map[string]string{"/start": "/a", "/a": "/b", "/b": "/a"}, RedirectLoop, "/a"},
+		{"self redirect", map[string]string{"/start": "/start"}, RedirectLoop, "/start"},
+		{"endless chain", map[string]string{}, RedirectLimit, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := redirectTarget(tt.routes)
+			defer target.Close()
+
+			checker := NewChecker(Config{
+				Timeout:         2 * time.Second,
+				ValidationURL:   target.URL + "/start",
+				FollowRedirects: true,
+				MaxRedirects:    3,
+			}, true, nil)
+
+			result := &ProxyResult{}
+			client := &http.Client{CheckRedirect: checker.checkRedirect(result)}
+			err := checker.performChecks(client, result)
+
+			if tt.reason == "" {
+				if err != nil || result.RedirectFailure != "" {
+					t.Fatalf("Expected the chain to be followed, got %v (%q)", err, result.RedirectFailure)
+				}
+				return
+			}
+			proxyErr, ok := err.(*errors.ProxyError)
+			if !ok || proxyErr.Details["reason"] != tt.reason {
+				t.Fatalf("performChecks() error = %v, want reason %q", err, tt.reason)
+			}
+			if result.RedirectFailure != tt.reason {
+				t.Errorf("RedirectFailure = %q, want %q", result.RedirectFailure, tt.reason)
+			}
+			if tt.repeated != "" && !strings.Contains(result.DebugInfo, target.URL+tt.repeated+" repeated") {
+				t.Errorf("Expected the repeated URL in the debug log, got:\n%s", result.DebugInfo)
+			}
+		})
+	}
+}
+
+// TestRedirectsNotFollowedByDefault tests that without FollowRedirects the
+// redirect response itself is returned
+func TestRedirectsNotFollowedByDefault(t *testing.T) {
+	target := redirectTarget(map[string]string{"/start": "/ok"})
+	defer target.Close()
+
+	checker := NewChecker(Config{Timeout: 2 * time.Second}, false, nil)
+	result := &ProxyResult{}
+	client := &http.Client{CheckRedirect: checker.checkRedirect(result)}
+
+	resp, err := client.Get(target.URL + "/start")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("Expected the 302 itself, got %d", resp.StatusCode)
+	}
+}
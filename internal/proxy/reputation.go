@@ -0,0 +1,313 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// DefaultDNSBLZone is the blocklist queried when no DNSBL zones are configured
+	DefaultDNSBLZone = "zen.spamhaus.org"
+	// defaultReputationTimeout bounds each DNSBL query and the abuse-score API call
+	defaultReputationTimeout = 3 * time.Second
+	// maxReputationBodyBytes caps the abuse-score API response read
+	maxReputationBodyBytes = 64 << 10
+)
+
+// reputationCache holds the DNSBL and abuse-score answers for exit IPs, shared
+// by every check in a run. Concurrent lookups of the same answer share one
+// query. Failed lookups are not cached so a later check can retry them.
+type reputationCache struct {
+	mutex    sync.Mutex
+	listings map[string]bool // zone + "|" + IP -> listed
+	scores   map[string]int  // IP -> abuse score
+	group    singleflight.Group
+}
+
+// checkReputation looks up the proxy's exit IP on the configured DNSBLs and
+// the abuse-score API, recording the blocklists it is on and a 0-100
+// reputation score (higher is worse)
+func (c *Checker) checkReputation(client *http.Client, result *ProxyResult) {
+	ip, err := c.exitIP(client, result)
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[REPUTATION] Exit IP lookup failed: %v\n", err)
+		}
+		return
+	}
+	if result.ProxyIP == "" {
+		result.ProxyIP = ip
+	}
+
+	zones := c.config.DNSBLZones
+	if len(zones) == 0 {
+		zones = []string{DefaultDNSBLZone}
+	}
+
+	queried := 0
+	result.Blocklists = nil
+	for _, zone := range zones {
+		listed, err := c.dnsblListed(ip, zone)
+		if err != nil {
+			// A slow or refusing blocklist only loses its own verdict
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[REPUTATION] DNSBL %s lookup for %s failed: %v\n", zone, ip, err)
+			}
+			continue
+		}
+		queried++
+		if listed {
+			result.Blocklists = append(result.Blocklists, zone)
+		}
+	}
+
+	checked := queried > 0
+	score := 0
+	if queried > 0 {
+		score = 100 * len(result.Blocklists) / queried
+	}
+	if c.config.ReputationAPIURL != "" {
+		apiScore, err := c.abuseScore(ip)
+		if err != nil {
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[REPUTATION] Abuse score lookup for %s failed: %v\n", ip, err)
+			}
+		} else {
+			checked = true
+			if apiScore > score {
+				score = apiScore
+			}
+		}
+	}
+
+	result.ReputationChecked = checked
+	result.ReputationScore = score
+	if len(result.Blocklists) > 0 {
+		result.SecurityWarnings = append(result.SecurityWarnings, fmt.Sprintf(
+			"Exit IP %s is listed on %s", ip, strings.Join(result.Blocklists, ", ")))
+	}
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[REPUTATION] Exit IP %s: score %d, blocklists %v (%d of %d zones answered)\n",
+			ip, score, result.Blocklists, queried, len(zones))
+	}
+}
+
+// exitIP returns the IP the proxy egresses from, reusing one found by an
+// earlier check before asking the egress IP endpoint through the proxy
+func (c *Checker) exitIP(client *http.Client, result *ProxyResult) (string, error) {
+	for _, known := range []string{result.EgressIP, result.DetectedIP} {
+		if ip := net.ParseIP(known); ip != nil {
+			return ip.String(), nil
+		}
+	}
+
+	ipURL := c.config.EgressIPURL
+	if ipURL == "" {
+		ipURL = defaultEgressIPURL
+	}
+	body, err := c.fetchIPInfo(client, ipURL, result)
+	if err != nil {
+		return "", err
+	}
+	return parseEgressIP(body)
+}
+
+// dnsblListed reports whether ip is listed on the DNSBL zone, from the cache
+// when it was looked up before in this run
+func (c *Checker) dnsblListed(ip, zone string) (bool, error) {
+	key := zone + "|" + ip
+	c.reputation.mutex.Lock()
+	listed, ok := c.reputation.listings[key]
+	c.reputation.mutex.Unlock()
+	if ok {
+		return listed, nil
+	}
+
+	value, err, _ := c.reputation.group.Do("dnsbl|"+key, func() (interface{}, error) {
+		listed, err := c.queryDNSBL(ip, zone)
+		if err != nil {
+			return false, err
+		}
+
+		c.reputation.mutex.Lock()
+		if c.reputation.listings == nil {
+			c.reputation.listings = make(map[string]bool)
+		}
+		c.reputation.listings[key] = listed
+		c.reputation.mutex.Unlock()
+		return listed, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return value.(bool), nil
+}
+
+// queryDNSBL looks up ip in the DNSBL zone. A listed IP resolves to a
+// 127.0.0.0/8 address; NXDOMAIN means not listed. Spamhaus answers
+// 127.255.255.x to refused queries (e.g. through public resolvers), which is
+// an error rather than a listing.
+func (c *Checker) queryDNSBL(ip, zone string) (bool, error) {
+	name, err := dnsblName(ip, zone)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.reputationTimeout())
+	defer cancel()
+	addrs, err := c.resolver().LookupHost(ctx, name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, addr := range addrs {
+		answer := net.ParseIP(addr).To4()
+		if answer == nil || answer[0] != 127 {
+			continue
+		}
+		if answer[1] == 255 && answer[2] == 255 {
+			return false, fmt.Errorf("%s refused the query (answer %s)", zone, addr)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// dnsblName returns the DNSBL query name for ip: the reversed octets of an
+// IPv4 address or the reversed nibbles of an IPv6 address, under zone
+func dnsblName(ip, zone string) (string, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return "", fmt.Errorf("%q is not an IP address", ip)
+	}
+	zone = strings.Trim(zone, ".")
+
+	if v4 := parsed.To4(); v4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.%s", v4[3], v4[2], v4[1], v4[0], zone), nil
+	}
+
+	const hexDigits = "0123456789abcdef"
+	v6 := parsed.To16()
+	labels := make([]string, 0, 33)
+	for i := len(v6) - 1; i >= 0; i-- {
+		labels = append(labels, string(hexDigits[v6[i]&0x0f]), string(hexDigits[v6[i]>>4]))
+	}
+	labels = append(labels, zone)
+	return strings.Join(labels, "."), nil
+}
+
+// abuseScore asks the abuse-score API for ip's score, from the cache when it
+// was looked up before in this run
+func (c *Checker) abuseScore(ip string) (int, error) {
+	c.reputation.mutex.Lock()
+	score, ok := c.reputation.scores[ip]
+	c.reputation.mutex.Unlock()
+	if ok {
+		return score, nil
+	}
+
+	value, err, _ := c.reputation.group.Do("score|"+ip, func() (interface{}, error) {
+		score, err := c.queryAbuseScore(ip)
+		if err != nil {
+			return 0, err
+		}
+
+		c.reputation.mutex.Lock()
+		if c.reputation.scores == nil {
+			c.reputation.scores = make(map[string]int)
+		}
+		c.reputation.scores[ip] = score
+		c.reputation.mutex.Unlock()
+		return score, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return value.(int), nil
+}
+
+// queryAbuseScore calls ReputationAPIURL, with {ip} replaced by the exit IP,
+// through the base dialer rather than through the proxy. The response is an
+// AbuseIPDB-style {"data": {"abuseConfidenceScore": N}} or a flat
+// {"score": N}, with N from 0 to 100.
+func (c *Checker) queryAbuseScore(ip string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.reputationTimeout())
+	defer cancel()
+
+	apiURL := strings.ReplaceAll(c.config.ReputationAPIURL, "{ip}", ip)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.config.ReputationAPIKey != "" {
+		req.Header.Set("Key", c.config.ReputationAPIKey)
+	}
+	if c.config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	resp, err := c.directClient(c.reputationTimeout()).Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("abuse score API returned status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxReputationBodyBytes))
+	if err != nil {
+		return 0, err
+	}
+	return parseAbuseScore(body)
+}
+
+// parseAbuseScore extracts the 0-100 score from an abuse-score API response
+func parseAbuseScore(body []byte) (int, error) {
+	var fields struct {
+		Data *struct {
+			AbuseConfidenceScore *int `json:"abuseConfidenceScore"`
+		} `json:"data"`
+		Score *int `json:"score"`
+	}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return 0, fmt.Errorf("invalid abuse score response: %w", err)
+	}
+
+	var score *int
+	switch {
+	case fields.Data != nil && fields.Data.AbuseConfidenceScore != nil:
+		score = fields.Data.AbuseConfidenceScore
+	case fields.Score != nil:
+		score = fields.Score
+	default:
+		return 0, fmt.Errorf("abuse score response has no abuseConfidenceScore or score field")
+	}
+	if *score < 0 || *score > 100 {
+		return 0, fmt.Errorf("abuse score %d is outside 0-100", *score)
+	}
+	return *score, nil
+}
+
+// reputationTimeout returns the timeout for one reputation lookup
+func (c *Checker) reputationTimeout() time.Duration {
+	if c.config.ReputationTimeout > 0 {
+		return c.config.ReputationTimeout
+	}
+	return defaultReputationTimeout
+}
@@ -0,0 +1,250 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startDNSBLServer starts a DNS server answering DNSBL queries by zone:
+// listed.test lists every IP, refused.test refuses like Spamhaus does for
+// public resolvers, slow.test never answers and anything else is NXDOMAIN.
+// It returns a resolver for the server and the number of queries per zone.
+func startDNSBLServer(t *testing.T) (*net.Resolver, func(zone string) int) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("Cannot listen on UDP: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	var mutex sync.Mutex
+	queries := make(map[string]int)
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := server.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			query := append([]byte(nil), buf[:n]...)
+			if len(query) < 13 {
+				continue
+			}
+
+			var labels []string
+			for i := 12; i < len(query) && query[i] != 0; i += int(query[i]) + 1 {
+				end := i + 1 + int(query[i])
+				if end > len(query) {
+					break
+				}
+				labels = append(labels, string(query[i+1:end]))
+			}
+			zone := ""
+			if len(labels) >= 2 {
+				zone = strings.Join(labels[len(labels)-2:], ".")
+			}
+			mutex.Lock()
+			queries[zone]++
+			mutex.Unlock()
+
+			switch zone {
+			case "listed.test":
+				server.WriteTo(fakeDNSAnswer(query, net.IPv4(127, 0, 0, 2)), addr)
+			case "refused.test":
+				server.WriteTo(fakeDNSAnswer(query, net.IPv4(127, 255, 255, 254)), addr)
+			case "slow.test":
+			default:
+				// Header with NXDOMAIN and the question, no records
+				end := 12
+				for end < len(query) && query[end] != 0 {
+					end += int(query[end]) + 1
+				}
+				if end+5 > len(query) {
+					continue
+				}
+				answer := append([]byte(nil), query[:2]...)
+				answer = append(answer, 0x81, 0x83, 0, 1, 0, 0, 0, 0, 0, 0)
+				server.WriteTo(append(answer, query[12:end+5]...), addr)
+			}
+		}
+	}()
+
+	resolver, err := NewResolver(server.LocalAddr().String(), nil)
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+	return resolver, func(zone string) int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return queries[zone]
+	}
+}
+
+// TestDNSBLName tests the query names for IPv4 and IPv6 addresses
+func TestDNSBLName(t *testing.T) {
+	name, err := dnsblName("192.0.2.1", "zen.spamhaus.org.")
+	if err != nil || name != "1.2.0.192.zen.spamhaus.org" {
+		t.Errorf("Unexpected IPv4 query name %q, %v", name, err)
+	}
+
+	name, err = dnsblName("2001:db8::1", "zen.spamhaus.org")
+	want := "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.zen.spamhaus.org"
+	if err != nil || name != want {
+		t.Errorf("Unexpected IPv6 query name %q, %v", name, err)
+	}
+
+	if _, err := dnsblName("not-an-ip", "zen.spamhaus.org"); err == nil {
+		t.Error("Expected an error for an invalid IP")
+	}
+}
+
+// TestParseAbuseScore tests the supported abuse-score response shapes
+func TestParseAbuseScore(t *testing.T) {
+	tests := []struct {
+		body    string
+		want    int
+		wantErr bool
+	}{
+		{body: `{"data":{"ipAddress":"192.0.2.1","abuseConfidenceScore":87}}`, want: 87},
+		{body: `{"score":12}`, want: 12},
+		{body: `{"data":{"abuseConfidenceScore":0}}`, want: 0},
+		{body: `{"data":{}}`, wantErr: true},
+		{body: `{"score":150}`, wantErr: true},
+		{body: `not json`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseAbuseScore([]byte(tt.body))
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("parseAbuseScore(%s) = %d, %v; want %d, error %t", tt.body, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// TestCheckReputation tests that listings and the abuse score are recorded,
+// failing blocklists are skipped and lookups are cached per IP
+func TestCheckReputation(t *testing.T) {
+	resolver, queries := startDNSBLServer(t)
+
+	var mutex sync.Mutex
+	apiCalls := 0
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		apiCalls++
+		mutex.Unlock()
+		if r.URL.Query().Get("ipAddress") != "192.0.2.1" || r.Header.Get("Key") != "secret" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"data":{"abuseConfidenceScore":37}}`))
+	}))
+	defer api.Close()
+
+	checker := NewChecker(Config{
+		CheckReputation:   true,
+		DNSBLZones:        []string{"listed.test", "clean.test", "refused.test", "slow.test"},
+		ReputationAPIURL:  api.URL + "/check?ipAddress={ip}",
+		ReputationAPIKey:  "secret",
+		ReputationTimeout: 300 * time.Millisecond,
+		Resolver:          resolver,
+	}, true, nil)
+
+	for i := 0; i < 2; i++ {
+		result := &ProxyResult{EgressIP: "192.0.2.1"}
+		checker.checkReputation(nil, result)
+
+		if !result.ReputationChecked {
+			t.Fatalf("Expected the reputation to be checked, debug: %s", result.DebugInfo)
+		}
+		if len(result.Blocklists) != 1 || result.Blocklists[0] != "listed.test" {
+			t.Errorf("Expected only listed.test, got %v", result.Blocklists)
+		}
+		// One of the two answering zones lists the IP (50), above the abuse score
+		if result.ReputationScore != 50 {
+			t.Errorf("Expected reputation score 50, got %d", result.ReputationScore)
+		}
+		if result.ProxyIP != "192.0.2.1" {
+			t.Errorf("Expected the exit IP to be recorded as proxy IP, got %q", result.ProxyIP)
+		}
+		if len(result.SecurityWarnings) == 0 {
+			t.Error("Expected a security warning for the listing")
+		}
+	}
+
+	if queries("listed.test") == 0 || queries("clean.test") == 0 {
+		t.Fatal("Expected the blocklists to be queried")
+	}
+	listed, clean := queries("listed.test"), queries("clean.test")
+	checker.checkReputation(nil, &ProxyResult{EgressIP: "192.0.2.1"})
+	if queries("listed.test") != listed || queries("clean.test") != clean {
+		t.Error("Expected answered blocklist lookups to be cached for the run")
+	}
+	mutex.Lock()
+	if apiCalls != 1 {
+		t.Errorf("Expected 1 abuse score API call, got %d", apiCalls)
+	}
+	mutex.Unlock()
+
+	// A higher abuse score wins over the blocklist share
+	onlyAPI := NewChecker(Config{
+		DNSBLZones:        []string{"clean.test"},
+		ReputationAPIURL:  api.URL + "/check?ipAddress={ip}",
+		ReputationAPIKey:  "secret",
+		ReputationTimeout: 300 * time.Millisecond,
+		Resolver:          resolver,
+	}, false, nil)
+	result := &ProxyResult{DetectedIP: "192.0.2.1"}
+	onlyAPI.checkReputation(nil, result)
+	if result.ReputationScore != 37 || len(result.Blocklists) != 0 {
+		t.Errorf("Expected score 37 and no blocklists, got %d, %v", result.ReputationScore, result.Blocklists)
+	}
+}
+
+func TestReputationLookupsCoalesceThroughBaseDialer(t *testing.T) {
+	var mutex sync.Mutex
+	apiCalls := 0
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		apiCalls++
+		mutex.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"score":12}`))
+	}))
+	defer api.Close()
+
+	var dials int32
+	checker := NewChecker(Config{
+		ReputationAPIURL: api.URL + "/{ip}",
+		BaseDialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			atomic.AddInt32(&dials, 1)
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}, false, nil)
+
+	// Checks finishing together ask once for the same exit IP
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if score, err := checker.abuseScore("192.0.2.9"); err != nil || score != 12 {
+				t.Errorf("abuseScore() = %d, %v; want 12", score, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if apiCalls != 1 {
+		t.Errorf("Expected 1 abuse score API call for concurrent lookups, got %d", apiCalls)
+	}
+	if atomic.LoadInt32(&dials) == 0 {
+		t.Error("Expected the abuse score API to be dialed through the base dialer")
+	}
+}
@@ -0,0 +1,98 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// defaultResolveCacheTTL is how long cached target resolutions are reused when
+// ResolveOnce is enabled and no DNSCacheTTL is configured
+const defaultResolveCacheTTL = 5 * time.Minute
+
+// resolveEntry is a cached lookup result
+type resolveEntry struct {
+	value   string
+	expires time.Time
+}
+
+// resolveCache holds target hostname lookups shared by every check in a run
+type resolveCache struct {
+	mutex   sync.Mutex
+	entries map[string]resolveEntry
+}
+
+// cachedLookup returns the cached value for key, running lookup on a miss or
+// after the configured TTL has expired. Failed lookups are not cached.
+func (c *Checker) cachedLookup(key string, lookup func() (string, error), result *ProxyResult) (string, error) {
+	if !c.config.ResolveOnce {
+		return lookup()
+	}
+
+	c.resolveCache.mutex.Lock()
+	entry, ok := c.resolveCache.entries[key]
+	c.resolveCache.mutex.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[DNS] Using cached resolution for %s: %s\n", key, entry.value)
+		}
+		return entry.value, nil
+	}
+
+	value, err := lookup()
+	if err != nil {
+		return "", err
+	}
+
+	ttl := c.config.DNSCacheTTL
+	if ttl <= 0 {
+		ttl = defaultResolveCacheTTL
+	}
+
+	c.resolveCache.mutex.Lock()
+	if c.resolveCache.entries == nil {
+		c.resolveCache.entries = make(map[string]resolveEntry)
+	}
+	c.resolveCache.entries[key] = resolveEntry{value: value, expires: time.Now().Add(ttl)}
+	c.resolveCache.mutex.Unlock()
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[DNS] Resolved %s to %s, caching for %v\n", key, value, ttl)
+	}
+	return value, nil
+}
+
+// resolveTargetIPv4 resolves a target hostname to an IPv4 address, as SOCKS4
+// needs the address before dialing. IP literals are returned unchanged.
+func (c *Checker) resolveTargetIPv4(host string, result *ProxyResult) (string, error) {
+	if net.ParseIP(host) != nil {
+		return host, nil
+	}
+
+	return c.cachedLookup("ipv4:"+host, func() (string, error) {
+		var ips []net.IP
+		err := c.withDNSRetry(func() error {
+			var err error
+			ips, err = c.resolver().LookupIP(context.Background(), "ip", host)
+			return err
+		}, "lookup of "+host, result)
+		if err != nil {
+			return "", err
+		}
+		for _, ip := range ips {
+			if ip4 := ip.To4(); ip4 != nil {
+				return ip4.String(), nil
+			}
+		}
+		return "", fmt.Errorf("no IPv4 address found for %s", host)
+	}, result)
+}
+
+// resolveTargetRDNS returns the reverse DNS name used for the Host header
+func (c *Checker) resolveTargetRDNS(host string, result *ProxyResult) (string, error) {
+	return c.cachedLookup("rdns:"+host, func() (string, error) {
+		return c.lookupRDNS(host)
+	}, result)
+}
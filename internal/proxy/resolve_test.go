@@ -0,0 +1,44 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCachedLookup tests that lookups are reused until the TTL expires and only when ResolveOnce is set
+func TestCachedLookup(t *testing.T) {
+	lookups := 0
+	lookup := func() (string, error) {
+		lookups++
+		return "93.184.216.34", nil
+	}
+
+	checker := NewChecker(Config{ResolveOnce: true, DNSCacheTTL: 50 * time.Millisecond}, false, nil)
+	result := &ProxyResult{}
+
+	for i := 0; i < 3; i++ {
+		if ip, err := checker.cachedLookup("ipv4:example.com", lookup, result); err != nil || ip != "93.184.216.34" {
+			t.Fatalf("Unexpected lookup result: %s, %v", ip, err)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("Expected 1 lookup within the TTL, got %d", lookups)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	checker.cachedLookup("ipv4:example.com", lookup, result)
+	if lookups != 2 {
+		t.Errorf("Expected the lookup to be repeated after the TTL, got %d lookups", lookups)
+	}
+
+	uncached := NewChecker(Config{}, false, nil)
+	uncached.cachedLookup("ipv4:example.com", lookup, result)
+	uncached.cachedLookup("ipv4:example.com", lookup, result)
+	if lookups != 4 {
+		t.Errorf("Expected every lookup to run without ResolveOnce, got %d lookups", lookups)
+	}
+
+	if ip, err := checker.resolveTargetIPv4("10.0.0.1", result); err != nil || ip != "10.0.0.1" {
+		t.Errorf("Expected IP literals to pass through, got %s, %v", ip, err)
+	}
+}
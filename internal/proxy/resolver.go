@@ -0,0 +1,179 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// dohTimeout bounds one DNS-over-HTTPS exchange when the resolver sets no deadline
+	dohTimeout = 10 * time.Second
+	// dohMaxResponse is the largest DNS message a DoH server may return
+	dohMaxResponse = 65535
+)
+
+// NewResolver builds the DNS resolver named by spec: host[:port] of a DNS
+// server (port 53 by default), or an https:// DNS-over-HTTPS endpoint such as
+// https://cloudflare-dns.com/dns-query. An empty spec returns nil, which means
+// the system resolver. The resolver reaches its server with dial (nil dials
+// directly), so its queries take the same route as the checks; queries
+// through dial go over TCP, since tunnels and jump proxies carry no UDP.
+func NewResolver(spec string, dial DialFunc) (*net.Resolver, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(spec, "https://") {
+		endpoint, err := url.Parse(spec)
+		if err != nil || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid DNS-over-HTTPS resolver %q", spec)
+		}
+		client := &http.Client{Transport: &http.Transport{ForceAttemptHTTP2: true, DialContext: dial}}
+		return newDoHResolver(endpoint.String(), client), nil
+	}
+	if strings.Contains(spec, "://") {
+		return nil, fmt.Errorf("unsupported resolver %q: use host[:port] or an https:// DNS-over-HTTPS URL", spec)
+	}
+
+	server := spec
+	if _, _, err := net.SplitHostPort(spec); err != nil {
+		// A bare address; IPv6 literals need brackets before a port is added
+		server = net.JoinHostPort(strings.Trim(spec, "[]"), "53")
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		return nil, fmt.Errorf("invalid resolver address %q: %v", spec, err)
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			if dial != nil {
+				return dial(ctx, "tcp", server)
+			}
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, server)
+		},
+	}, nil
+}
+
+// newDoHResolver returns a resolver that sends its queries to a
+// DNS-over-HTTPS endpoint with client
+func newDoHResolver(endpoint string, client *http.Client) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return &dohConn{ctx: ctx, client: client, endpoint: endpoint}, nil
+		},
+	}
+}
+
+// resolver returns the configured resolver, or the system resolver
+func (c *Checker) resolver() *net.Resolver {
+	if c.config.Resolver != nil {
+		return c.config.Resolver
+	}
+	return net.DefaultResolver
+}
+
+// dohConn carries the DNS messages of Go's resolver over DNS-over-HTTPS
+// (RFC 8484). The resolver treats a conn that is not a net.PacketConn as a
+// TCP stream, writing each query with a two-byte length prefix and reading
+// the answer the same way; every complete query is POSTed to the endpoint and
+// its answer framed for reading.
+type dohConn struct {
+	ctx      context.Context
+	client   *http.Client
+	endpoint string
+	deadline time.Time
+	query    bytes.Buffer
+	answer   bytes.Buffer
+}
+
+// Write buffers a framed query and exchanges it once it is complete
+func (c *dohConn) Write(b []byte) (int, error) {
+	c.query.Write(b)
+	pending := c.query.Bytes()
+	if len(pending) < 2 {
+		return len(b), nil
+	}
+	size := int(binary.BigEndian.Uint16(pending))
+	if len(pending) < 2+size {
+		return len(b), nil
+	}
+
+	message := append([]byte(nil), pending[2:2+size]...)
+	c.query.Next(2 + size)
+	answer, err := c.exchange(message)
+	if err != nil {
+		return 0, err
+	}
+	var prefix [2]byte
+	binary.BigEndian.PutUint16(prefix[:], uint16(len(answer)))
+	c.answer.Write(prefix[:])
+	c.answer.Write(answer)
+	return len(b), nil
+}
+
+// exchange POSTs one DNS message to the endpoint and returns the answer
+func (c *dohConn) exchange(message []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(c.ctx, dohTimeout)
+	defer cancel()
+	if !c.deadline.IsZero() {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadline(ctx, c.deadline)
+		defer cancelDeadline()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(message))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DNS-over-HTTPS server %s answered %s", c.endpoint, resp.Status)
+	}
+	answer, err := io.ReadAll(io.LimitReader(resp.Body, dohMaxResponse+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(answer) > dohMaxResponse {
+		return nil, fmt.Errorf("DNS-over-HTTPS answer from %s is too large", c.endpoint)
+	}
+	return answer, nil
+}
+
+// Read returns the framed answers of the queries written so far
+func (c *dohConn) Read(b []byte) (int, error) {
+	if c.answer.Len() == 0 {
+		return 0, io.EOF
+	}
+	return c.answer.Read(b)
+}
+
+func (c *dohConn) Close() error                       { return nil }
+func (c *dohConn) LocalAddr() net.Addr                { return dohAddr(c.endpoint) }
+func (c *dohConn) RemoteAddr() net.Addr               { return dohAddr(c.endpoint) }
+func (c *dohConn) SetDeadline(t time.Time) error      { c.deadline = t; return nil }
+func (c *dohConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *dohConn) SetWriteDeadline(t time.Time) error { c.deadline = t; return nil }
+
+// dohAddr is the address of a DNS-over-HTTPS endpoint
+type dohAddr string
+
+func (a dohAddr) Network() string { return "https" }
+func (a dohAddr) String() string  { return string(a) }
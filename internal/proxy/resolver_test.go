@@ -0,0 +1,252 @@
+package proxy
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDNSAnswer answers a DNS query with ip for A questions and with no
+// records for anything else
+func fakeDNSAnswer(query []byte, ip net.IP) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+	// Question: the name labels, then type and class
+	end := 12
+	for end < len(query) && query[end] != 0 {
+		end += int(query[end]) + 1
+	}
+	end += 5
+	if end > len(query) {
+		return nil
+	}
+	qtype := binary.BigEndian.Uint16(query[end-4:])
+
+	answer := append([]byte(nil), query[:2]...)                 // ID
+	answer = append(answer, 0x81, 0x80, 0, 1, 0, 0, 0, 0, 0, 0) // Flags, one question
+	answer = append(answer, query[12:end]...)
+	if qtype == 1 {
+		answer[7] = 1                                              // One answer
+		answer = append(answer, 0xc0, 12, 0, 1, 0, 1, 0, 0, 0, 60) // Name pointer, A, IN, TTL
+		answer = append(answer, 0, 4)
+		answer = append(answer, ip.To4()...)
+	}
+	return answer
+}
+
+// TestNewResolver tests parsing of resolver specs
+func TestNewResolver(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantNil bool
+		wantErr bool
+	}{
+		{spec: "", wantNil: true},
+		{spec: "1.1.1.1"},
+		{spec: "1.1.1.1:53"},
+		{spec: "2606:4700:4700::1111"},
+		{spec: "[2606:4700:4700::1111]:53"},
+		{spec: "dns.example.com:5353"},
+		{spec: "https://cloudflare-dns.com/dns-query"},
+		{spec: "https://", wantErr: true},
+		{spec: "udp://1.1.1.1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			resolver, err := NewResolver(tt.spec, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewResolver(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !tt.wantErr && (resolver == nil) != tt.wantNil {
+				t.Errorf("NewResolver(%q) = %v, want nil: %v", tt.spec, resolver, tt.wantNil)
+			}
+		})
+	}
+}
+
+// TestResolverDNSServer tests lookups against a pinned DNS server and that
+// the checker dials proxy hostnames through it
+func TestResolverDNSServer(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("Cannot listen on UDP: %v", err)
+	}
+	defer server.Close()
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := server.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			server.WriteTo(fakeDNSAnswer(buf[:n], net.IPv4(127, 0, 0, 1)), addr)
+		}
+	}()
+
+	resolver, err := NewResolver(server.LocalAddr().String(), nil)
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ips, err := resolver.LookupIP(ctx, "ip4", "proxy.example.com")
+	if err != nil || len(ips) != 1 || !ips[0].Equal(net.IPv4(127, 0, 0, 1)) {
+		t.Fatalf("Expected proxy.example.com to resolve to 127.0.0.1, got %v, %v", ips, err)
+	}
+
+	// A proxy hostname only the pinned server knows is dialed through it
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	checker := NewChecker(Config{Resolver: resolver}, false, nil)
+	if checker.config.BaseDialer == nil {
+		t.Fatal("Expected a resolver to install a base dialer")
+	}
+	conn, err := checker.config.BaseDialer(ctx, "tcp4", net.JoinHostPort("proxy.example.com", port))
+	if err != nil {
+		t.Fatalf("Expected to dial the proxy hostname through the resolver: %v", err)
+	}
+	conn.Close()
+}
+
+// TestResolverDoH tests lookups over DNS-over-HTTPS
+func TestResolverDoH(t *testing.T) {
+	queries := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/dns-message" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		query, _ := io.ReadAll(r.Body)
+		queries++
+		w.Header().Set("Content-Type", "application/dns-message")
+		w.Write(fakeDNSAnswer(query, net.IPv4(192, 0, 2, 10)))
+	}))
+	defer server.Close()
+
+	resolver := newDoHResolver(server.URL+"/dns-query", server.Client())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ips, err := resolver.LookupIP(ctx, "ip4", "target.example.com")
+	if err != nil {
+		t.Fatalf("DoH lookup failed: %v", err)
+	}
+	if len(ips) != 1 || !ips[0].Equal(net.IPv4(192, 0, 2, 10)) {
+		t.Errorf("Expected 192.0.2.10, got %v", ips)
+	}
+	if queries == 0 {
+		t.Error("Expected the query to be sent to the DoH endpoint")
+	}
+
+	checker := NewChecker(Config{Resolver: resolver}, false, nil)
+	if checker.resolver() != resolver {
+		t.Error("Expected the checker to use the configured resolver")
+	}
+	if NewChecker(Config{}, false, nil).resolver() != net.DefaultResolver {
+		t.Error("Expected the system resolver without one configured")
+	}
+}
+
+// TestResolverDialsThroughDial tests that a resolver given a dial func sends
+// its queries through it, over TCP for a plain DNS server
+func TestResolverDialsThroughDial(t *testing.T) {
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	go func() {
+		for {
+			conn, err := server.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				for {
+					var size [2]byte
+					if _, err := io.ReadFull(conn, size[:]); err != nil {
+						return
+					}
+					query := make([]byte, binary.BigEndian.Uint16(size[:]))
+					if _, err := io.ReadFull(conn, query); err != nil {
+						return
+					}
+					answer := fakeDNSAnswer(query, net.IPv4(192, 0, 2, 20))
+					binary.BigEndian.PutUint16(size[:], uint16(len(answer)))
+					conn.Write(append(size[:], answer...))
+				}
+			}(conn)
+		}
+	}()
+
+	var dialed []string
+	var mu sync.Mutex
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		mu.Lock()
+		dialed = append(dialed, network+" "+addr)
+		mu.Unlock()
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	resolver, err := NewResolver(server.Addr().String(), dial)
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ips, err := resolver.LookupIP(ctx, "ip4", "target.example.com")
+	if err != nil || len(ips) != 1 || !ips[0].Equal(net.IPv4(192, 0, 2, 20)) {
+		t.Fatalf("Expected target.example.com to resolve to 192.0.2.20, got %v, %v", ips, err)
+	}
+
+	mu.Lock()
+	if len(dialed) == 0 {
+		t.Error("Expected the DNS query to go through the dial func")
+	}
+	for _, d := range dialed {
+		if d != "tcp "+server.Addr().String() {
+			t.Errorf("Expected a TCP dial to the DNS server, got %q", d)
+		}
+	}
+	dialed = nil
+	mu.Unlock()
+
+	// The DoH client reaches its endpoint through the dial func too
+	refuse := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		mu.Lock()
+		dialed = append(dialed, network+" "+addr)
+		mu.Unlock()
+		return nil, errors.New("dial refused")
+	}
+	resolver, err = NewResolver("https://doh.example.com:8443/dns-query", refuse)
+	if err != nil {
+		t.Fatalf("NewResolver failed: %v", err)
+	}
+	resolver.LookupIP(ctx, "ip4", "target.example.com")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dialed) == 0 || dialed[0] != "tcp doh.example.com:8443" {
+		t.Errorf("Expected the DoH endpoint to be dialed through the dial func, got %v", dialed)
+	}
+}
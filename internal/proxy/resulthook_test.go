@@ -0,0 +1,45 @@
+package proxy
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResultHook(t *testing.T) {
+	var mutex sync.Mutex
+	seen := map[string]bool{}
+	checker := NewChecker(Config{
+		Timeout: time.Second,
+		ResultHook: func(result *ProxyResult) {
+			mutex.Lock()
+			seen[result.ProxyURL] = true
+			mutex.Unlock()
+			result.Annotations = map[string]string{"reputation": "trusted"}
+			// Scoring always assigns Score, so this only survives if the hook runs last
+			result.Score = 42
+		},
+	}, false, nil)
+
+	// Hooks run on the worker goroutines, so call Check concurrently
+	urls := []string{"http://127.0.0.1:1", "socks5://127.0.0.1:1", "://invalid"}
+	results := make([]*ProxyResult, len(urls))
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			results[i] = checker.Check(u)
+		}(i, u)
+	}
+	wg.Wait()
+
+	if len(seen) != len(urls) {
+		t.Fatalf("hook saw %d results, want %d: %v", len(seen), len(urls), seen)
+	}
+	for i, result := range results {
+		if result.Annotations["reputation"] != "trusted" || result.Score != 42 {
+			t.Errorf("%s: Annotations = %v, Score = %v; want the hook's changes", urls[i], result.Annotations, result.Score)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package proxy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"net"
@@ -53,6 +54,17 @@ func (c *Checker) isRetryableError(err error) bool {
 		return false
 	}
 
+	// A 429 with Retry-After is retried once the target allows it
+	var retryAfter *retryAfterError
+	if errors.As(err, &retryAfter) {
+		return true
+	}
+
+	// A redirect loop or an overlong chain ends the same way on every attempt
+	if _, ok := asRedirectError(err); ok {
+		return false
+	}
+
 	errorText := strings.ToLower(err.Error())
 	
 	// Check custom retryable error patterns from config
@@ -118,11 +130,29 @@ func (c *Checker) executeWithRetry(ctx context.Context, operation func() error,
 		// If this is a retry attempt, wait with exponential backoff
 		if attempt > 0 {
 			delay := c.calculateBackoffDelay(attempt - 1)
-			
+
+			// Wait as long as the target asked when it sent Retry-After
+			var retryAfter *retryAfterError
+			if errors.As(lastErr, &retryAfter) {
+				delay = retryAfter.delay
+				if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+					if c.debug {
+						result.DebugInfo += fmt.Sprintf("[RETRY] Retry-After of %v for %s exceeds the remaining retry time, giving up\n",
+							delay, operationName)
+					}
+					return lastErr
+				}
+				result.RetryAfterDelays = append(result.RetryAfterDelays, delay)
+			}
+
 			if c.debug {
 				result.DebugInfo += fmt.Sprintf("[RETRY] Attempt %d/%d for %s failed: %v\n", 
 					attempt, c.config.MaxRetries, operationName, lastErr)
-				result.DebugInfo += fmt.Sprintf("[RETRY] Waiting %v before retry %d...\n", delay, attempt)
+				if retryAfter != nil {
+					result.DebugInfo += fmt.Sprintf("[RETRY] Honoring Retry-After: waiting %v before retry %d...\n", delay, attempt)
+				} else {
+					result.DebugInfo += fmt.Sprintf("[RETRY] Waiting %v before retry %d...\n", delay, attempt)
+				}
 			}
 			
 			// Use context-aware sleep
@@ -177,23 +207,49 @@ func (c *Checker) executeWithRetry(ctx context.Context, operation func() error,
 
 // makeRequestWithRetry wraps makeRequest with retry logic
 func (c *Checker) makeRequestWithRetry(client *http.Client, urlStr string, result *ProxyResult) (*http.Response, error) {
+	return c.makeRequestWithRetryHost(client, urlStr, "", result)
+}
+
+// makeRequestWithRetryHost wraps makeRequestWithHost with retry logic
+func (c *Checker) makeRequestWithRetryHost(client *http.Client, urlStr, hostHeader string, result *ProxyResult) (*http.Response, error) {
 	var response *http.Response
-	
-	// Create a context for the entire retry operation (separate from individual request timeouts)
-	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout*time.Duration(c.config.MaxRetries+1))
+
+	// Create a context for the entire retry operation (separate from individual request timeouts),
+	// leaving room for the retries to wait out Retry-After
+	budget := c.timeout(result) * time.Duration(c.config.MaxRetries+1)
+	if c.config.HonorRetryAfter {
+		budget += c.config.MaxRetryAfter * time.Duration(c.config.MaxRetries)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), budget)
 	defer cancel()
-	
+
 	operation := func() error {
-		resp, err := c.makeRequest(client, urlStr, result)
+		// Discard a rate limited response that is being retried
+		if response != nil {
+			response.Body.Close()
+			response = nil
+		}
+		resp, err := c.makeRequestWithHost(client, urlStr, hostHeader, result)
 		if err != nil {
 			return err
 		}
 		response = resp
+		if delay, ok := c.retryAfterDelay(resp); ok {
+			return &retryAfterError{delay: delay}
+		}
 		return nil
 	}
-	
+
 	err := c.executeWithRetry(ctx, operation, fmt.Sprintf("request to %s", urlStr), result)
+	var retryAfter *retryAfterError
+	if errors.As(err, &retryAfter) {
+		// Still rate limited after the retries; report the 429 itself
+		return response, nil
+	}
 	if err != nil {
+		if response != nil {
+			response.Body.Close()
+		}
 		return nil, err
 	}
 	
@@ -215,6 +271,9 @@ func (c *Checker) validateRetryConfig() {
 	if c.config.BackoffFactor <= 1.0 {
 		c.config.BackoffFactor = 2.0
 	}
+	if c.config.MaxRetryAfter <= 0 {
+		c.config.MaxRetryAfter = defaultMaxRetryAfter
+	}
 	
 	// Ensure max delay is at least initial delay
 	if c.config.MaxDelay < c.config.InitialDelay {
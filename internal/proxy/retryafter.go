@@ -0,0 +1,117 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultMaxRetryAfter caps how long a single Retry-After can hold back a
+// retry or the rate limiter when MaxRetryAfter is not configured, so a
+// misbehaving target cannot stall the whole scan
+const defaultMaxRetryAfter = time.Minute
+
+// retryAfterError reports a 429 response that carried Retry-After, so the
+// retry loop waits as long as the target asked instead of backing off
+type retryAfterError struct {
+	delay time.Duration // Requested delay, capped at MaxRetryAfter
+}
+
+func (e *retryAfterError) Error() string {
+	return fmt.Sprintf("target rate limited the request (429), retry after %v", e.delay)
+}
+
+// parseRetryAfter reads a Retry-After value in either delay-seconds or
+// HTTP-date form and returns how long to wait from now
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	when, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if delay := when.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
+
+// retryAfterDelay returns how long to wait before retrying resp when it is a
+// 429 with Retry-After and HonorRetryAfter is enabled, capped at MaxRetryAfter
+func (c *Checker) retryAfterDelay(resp *http.Response) (time.Duration, bool) {
+	if !c.config.HonorRetryAfter || resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		return 0, false
+	}
+	return c.capRetryAfter(delay), true
+}
+
+// capRetryAfter limits a requested Retry-After delay to MaxRetryAfter
+func (c *Checker) capRetryAfter(delay time.Duration) time.Duration {
+	if delay > c.config.MaxRetryAfter {
+		return c.config.MaxRetryAfter
+	}
+	return delay
+}
+
+// honorRetryAfter pushes back the next allowed request for the rate limit key
+// of host when a 429 or 503 response carries Retry-After, capped at
+// MaxRetryAfter. It only applies when rate limiting is enabled, since that is
+// what spaces out later requests.
+func (c *Checker) honorRetryAfter(host string, resp *http.Response, result *ProxyResult) {
+	if !c.config.RateLimitEnabled || resp == nil {
+		return
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return
+	}
+
+	now := time.Now()
+	delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+	if !ok || delay <= 0 {
+		return
+	}
+	delay = c.capRetryAfter(delay)
+
+	// The limiter stores the last request time and waits RateLimitDelay after
+	// it, so backdate the entry to make the next wait equal the delay
+	rateLimitKey := c.rateLimitKey(host, result)
+	nextAllowed := now.Add(delay)
+	c.rateLimiterLock.Lock()
+	if last, exists := c.rateLimiter[rateLimitKey]; !exists || last.Add(c.config.RateLimitDelay).Before(nextAllowed) {
+		c.rateLimiter[rateLimitKey] = nextAllowed.Add(-c.config.RateLimitDelay)
+	}
+	c.rateLimiterLock.Unlock()
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[DEBUG] Rate limiting: honoring Retry-After of %v from %s (status %d)\n",
+			delay, host, resp.StatusCode)
+	}
+}
+
+// rateLimitKey returns the rateLimiter entry that requests to host are spaced by
+func (c *Checker) rateLimitKey(host string, result *ProxyResult) string {
+	if c.config.RateLimitPerProxy && result.ProxyURL != "" {
+		return result.ProxyURL
+	}
+	if c.config.RateLimitPerHost {
+		return host
+	}
+	return "global"
+}
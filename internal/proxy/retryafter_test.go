@@ -0,0 +1,175 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{" 0 ", 0, true},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"", 0, false},
+		{"-5", 0, false},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %t; want %v, %t", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMakeRequestHonorsRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{
+		Timeout:          time.Second,
+		RateLimitEnabled: true,
+		RateLimitDelay:   10 * time.Millisecond,
+		RateLimitPerHost: true,
+	}, true, nil)
+
+	result := &ProxyResult{}
+	resp, err := checker.makeRequest(server.Client(), server.URL, result)
+	if err != nil {
+		t.Fatalf("makeRequest() error: %v", err)
+	}
+	resp.Body.Close()
+
+	if !strings.Contains(result.DebugInfo, "honoring Retry-After of 1s") {
+		t.Errorf("Expected the honored delay in debug output, got:\n%s", result.DebugInfo)
+	}
+
+	start := time.Now()
+	checker.applyRateLimit("127.0.0.1", &ProxyResult{})
+	if elapsed := time.Since(start); elapsed < 800*time.Millisecond {
+		t.Errorf("Expected the next request to wait out Retry-After, only waited %v", elapsed)
+	}
+}
+
+func TestRetryAfterRateLimitCapped(t *testing.T) {
+	checker := NewChecker(Config{RateLimitEnabled: true, MaxRetryAfter: 2 * time.Second}, true, nil)
+	resp := &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{"Retry-After": []string{"3600"}},
+	}
+
+	result := &ProxyResult{}
+	checker.honorRetryAfter("example.com", resp, result)
+
+	if !strings.Contains(result.DebugInfo, "honoring Retry-After of 2s") {
+		t.Errorf("Expected the delay capped at MaxRetryAfter, got:\n%s", result.DebugInfo)
+	}
+}
+
+func TestRetryAfterIgnoredWithoutRateLimiting(t *testing.T) {
+	checker := NewChecker(Config{}, true, nil)
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"30"}},
+	}
+
+	result := &ProxyResult{}
+	checker.honorRetryAfter("example.com", resp, result)
+
+	if len(checker.rateLimiter) != 0 || result.DebugInfo != "" {
+		t.Errorf("Expected Retry-After to be ignored with rate limiting disabled")
+	}
+}
+
+func TestMakeRequestWithRetryWaitsForRetryAfter(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			// Longer than MaxRetryAfter, so the wait is capped
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{
+		Timeout:         5 * time.Second,
+		RetryEnabled:    true,
+		MaxRetries:      2,
+		InitialDelay:    time.Millisecond,
+		HonorRetryAfter: true,
+		MaxRetryAfter:   50 * time.Millisecond,
+	}, false, nil)
+	result := &ProxyResult{}
+
+	start := time.Now()
+	resp, err := checker.makeRequestWithRetry(http.DefaultClient, server.URL, result)
+	if err != nil {
+		t.Fatalf("makeRequestWithRetry() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || attempts != 2 {
+		t.Errorf("status = %d after %d attempts, want 200 after 2", resp.StatusCode, attempts)
+	}
+	if len(result.RetryAfterDelays) != 1 || result.RetryAfterDelays[0] != 50*time.Millisecond {
+		t.Errorf("RetryAfterDelays = %v, want [50ms]", result.RetryAfterDelays)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("retry came after %v, before the Retry-After delay", elapsed)
+	}
+}
+
+func TestMakeRequestWithRetryReportsPersistent429(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	for _, honor := range []bool{true, false} {
+		attempts = 0
+		checker := NewChecker(Config{
+			Timeout:         5 * time.Second,
+			RetryEnabled:    true,
+			MaxRetries:      2,
+			InitialDelay:    time.Millisecond,
+			HonorRetryAfter: honor,
+		}, false, nil)
+		result := &ProxyResult{}
+
+		resp, err := checker.makeRequestWithRetry(http.DefaultClient, server.URL, result)
+		if err != nil {
+			t.Fatalf("honor=%t: makeRequestWithRetry() error = %v", honor, err)
+		}
+		resp.Body.Close()
+
+		wantAttempts, wantDelays := 1, 0
+		if honor {
+			wantAttempts, wantDelays = 3, 2
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempts != wantAttempts || len(result.RetryAfterDelays) != wantDelays {
+			t.Errorf("honor=%t: status %d, %d attempts, %d delays; want 429, %d, %d",
+				honor, resp.StatusCode, attempts, len(result.RetryAfterDelays), wantAttempts, wantDelays)
+		}
+	}
+}
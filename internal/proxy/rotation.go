@@ -0,0 +1,117 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultRotationSamples is how many exit IP samples rotation detection takes
+	// when RotationSamples is unset
+	DefaultRotationSamples = 5
+
+	// DefaultRotationThreshold is the share of samples that must change exit IP
+	// for a proxy to count as rotating when RotationThreshold is unset
+	DefaultRotationThreshold = 0.5
+)
+
+// detectRotation samples the exit IP of the proxy several times, each over a
+// new connection, to tell whether the endpoint is a rotating pool behind a
+// single hostname. The proxy is rotating when the share of samples whose exit
+// IP differs from the previous sample reaches the threshold.
+func (c *Checker) detectRotation(client *http.Client, result *ProxyResult) {
+	samples := c.config.RotationSamples
+	if samples < 2 {
+		samples = DefaultRotationSamples
+	}
+	threshold := c.config.RotationThreshold
+	if threshold <= 0 {
+		threshold = DefaultRotationThreshold
+	}
+
+	var observed []string
+	seen := make(map[string]bool)
+	sampled, changes := 0, 0
+	previous := ""
+	for i := 0; i < samples; i++ {
+		if i > 0 && c.config.RotationInterval > 0 {
+			time.Sleep(c.config.RotationInterval)
+		}
+
+		ip, err := c.sampleExitIP(client, result)
+		if err != nil {
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[ROTATION] Sample %d/%d failed: %v\n", i+1, samples, err)
+			}
+			continue
+		}
+		if sampled > 0 && ip != previous {
+			changes++
+		}
+		previous = ip
+		sampled++
+		if !seen[ip] {
+			seen[ip] = true
+			observed = append(observed, ip)
+		}
+	}
+
+	if sampled < 2 {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[ROTATION] Only %d of %d samples succeeded, rotation unknown\n", sampled, samples)
+		}
+		return
+	}
+
+	score := float64(changes) / float64(sampled-1)
+	result.RotationChecked = true
+	result.ObservedExitIPs = observed
+	result.IsRotating = len(observed) > 1 && score >= threshold
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[ROTATION] %d exit IPs over %d samples, %.2f changed (threshold %.2f): rotating=%t\n",
+			len(observed), sampled, score, threshold, result.IsRotating)
+	}
+}
+
+// sampleExitIP fetches the exit IP of the proxy over a new connection, so a
+// pool that rotates per connection shows a new IP on every sample
+func (c *Checker) sampleExitIP(client *http.Client, result *ProxyResult) (string, error) {
+	ipURL := c.config.EgressIPURL
+	if ipURL == "" {
+		ipURL = defaultEgressIPURL
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout(result))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", ipURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Close = true
+	for key, value := range c.config.DefaultHeaders {
+		req.Header.Set(key, value)
+	}
+	if c.config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	resp, err := c.doWithDNSRetry(client, req, result)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s returned status %d", ipURL, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxGeoIPBodyBytes))
+	if err != nil {
+		return "", err
+	}
+	return parseEgressIP(body)
+}
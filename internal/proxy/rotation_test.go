@@ -0,0 +1,74 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDetectRotation(t *testing.T) {
+	tests := []struct {
+		name         string
+		exitIPs      []string // Answered in turn, one per request
+		wantRotating bool
+		wantObserved []string
+	}{
+		{"static", []string{"203.0.113.1"}, false, []string{"203.0.113.1"}},
+		{"rotating per request", []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"}, true,
+			[]string{"203.0.113.1", "203.0.113.2", "203.0.113.3"}},
+		{"single change", []string{"203.0.113.1", "203.0.113.1", "203.0.113.1", "203.0.113.2", "203.0.113.2"}, false,
+			[]string{"203.0.113.1", "203.0.113.2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&requests, 1) - 1
+				ip := tt.exitIPs[int(n)%len(tt.exitIPs)]
+				fmt.Fprintf(w, `{"ip":%q}`, ip)
+			}))
+			defer server.Close()
+
+			checker := NewChecker(Config{
+				Timeout:         time.Second,
+				DetectRotation:  true,
+				RotationSamples: 5,
+				EgressIPURL:     server.URL,
+			}, false, nil)
+
+			result := &ProxyResult{}
+			checker.detectRotation(server.Client(), result)
+			if atomic.LoadInt32(&requests) != 5 {
+				t.Errorf("Expected 5 samples, got %d", requests)
+			}
+			if !result.RotationChecked || result.IsRotating != tt.wantRotating {
+				t.Errorf("Expected rotating=%t, got checked=%t rotating=%t",
+					tt.wantRotating, result.RotationChecked, result.IsRotating)
+			}
+			if !reflect.DeepEqual(result.ObservedExitIPs, tt.wantObserved) {
+				t.Errorf("Expected exit IPs %v, got %v", tt.wantObserved, result.ObservedExitIPs)
+			}
+		})
+	}
+}
+
+func TestDetectRotationFailedSamples(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{Timeout: time.Second, DetectRotation: true, EgressIPURL: server.URL}, false, nil)
+
+	result := &ProxyResult{}
+	checker.detectRotation(server.Client(), result)
+	if result.RotationChecked || result.IsRotating || len(result.ObservedExitIPs) != 0 {
+		t.Errorf("Expected rotation to stay unknown, got checked=%t rotating=%t ips=%v",
+			result.RotationChecked, result.IsRotating, result.ObservedExitIPs)
+	}
+}
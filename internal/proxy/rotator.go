@@ -0,0 +1,133 @@
+package proxy
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RotationStrategy selects how a Rotator hands out proxies
+type RotationStrategy string
+
+const (
+	// RotationRoundRobin cycles through the proxies in order
+	RotationRoundRobin RotationStrategy = "round-robin"
+	// RotationRandom picks a proxy uniformly at random
+	RotationRandom RotationStrategy = "random"
+	// RotationLeastRecentlyUsed picks the proxy that has gone longest without being handed out
+	RotationLeastRecentlyUsed RotationStrategy = "least-recently-used"
+	// RotationWeighted picks at random, weighted towards faster proxies
+	RotationWeighted RotationStrategy = "weighted"
+)
+
+// rotatorProxy is a proxy tracked by a Rotator
+type rotatorProxy struct {
+	url      string
+	weight   float64 // Inverse of the measured speed in seconds
+	lastUsed uint64  // Sequence number of the last Next() that returned this proxy (0 = never)
+}
+
+// Rotator hands out working proxies according to a rotation strategy.
+// It is safe for concurrent use.
+type Rotator struct {
+	strategy RotationStrategy
+	proxies  []rotatorProxy
+	mutex    sync.Mutex
+	next     int        // Next index for round-robin
+	sequence uint64     // Incremented on every Next() for least-recently-used
+	rng      *rand.Rand // Guarded by mutex
+}
+
+// NewRotator creates a Rotator over the working proxies in results
+func NewRotator(results []*ProxyResult, strategy RotationStrategy) (*Rotator, error) {
+	switch strategy {
+	case RotationRoundRobin, RotationRandom, RotationLeastRecentlyUsed, RotationWeighted:
+	default:
+		return nil, fmt.Errorf("unknown rotation strategy: %s", strategy)
+	}
+
+	r := &Rotator{
+		strategy: strategy,
+		rng:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+
+	for _, result := range results {
+		if result == nil || !result.Working {
+			continue
+		}
+		url := result.ProxyURL
+		if url == "" {
+			url = result.Proxy
+		}
+
+		// Proxies without a measured speed are weighted as if they took one second
+		speed := result.Speed
+		if speed <= 0 {
+			speed = time.Second
+		}
+		r.proxies = append(r.proxies, rotatorProxy{url: url, weight: 1 / speed.Seconds()})
+	}
+
+	return r, nil
+}
+
+// Len returns the number of proxies in the rotation
+func (r *Rotator) Len() int {
+	return len(r.proxies)
+}
+
+// Next returns the next proxy URL, or an empty string if there are no working proxies
+func (r *Rotator) Next() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if len(r.proxies) == 0 {
+		return ""
+	}
+
+	var index int
+	switch r.strategy {
+	case RotationRandom:
+		index = r.rng.Intn(len(r.proxies))
+	case RotationLeastRecentlyUsed:
+		index = r.leastRecentlyUsed()
+	case RotationWeighted:
+		index = r.weighted()
+	default:
+		index = r.next
+		r.next = (r.next + 1) % len(r.proxies)
+	}
+
+	r.sequence++
+	r.proxies[index].lastUsed = r.sequence
+	return r.proxies[index].url
+}
+
+// leastRecentlyUsed returns the index of the proxy handed out longest ago
+func (r *Rotator) leastRecentlyUsed() int {
+	oldest := 0
+	for i, p := range r.proxies {
+		if p.lastUsed < r.proxies[oldest].lastUsed {
+			oldest = i
+		}
+	}
+	return oldest
+}
+
+// weighted returns a random index with probability proportional to proxy speed
+func (r *Rotator) weighted() int {
+	var total float64
+	for _, p := range r.proxies {
+		total += p.weight
+	}
+
+	target := r.rng.Float64() * total
+	for i, p := range r.proxies {
+		target -= p.weight
+		if target < 0 {
+			return i
+		}
+	}
+	return len(r.proxies) - 1
+}
@@ -0,0 +1,69 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+)
+
+func rotatorResults() []*ProxyResult {
+	return []*ProxyResult{
+		{ProxyURL: "http://a.example.com:8080", Working: true, Speed: 10 * time.Millisecond},
+		{ProxyURL: "http://b.example.com:8080", Working: true, Speed: 1 * time.Second},
+		{ProxyURL: "http://dead.example.com:8080", Working: false},
+		{ProxyURL: "http://c.example.com:8080", Working: true, Speed: 1 * time.Second},
+	}
+}
+
+// TestRotatorStrategies tests that each strategy only hands out working proxies in the expected pattern
+func TestRotatorStrategies(t *testing.T) {
+	roundRobin, err := NewRotator(rotatorResults(), RotationRoundRobin)
+	if err != nil {
+		t.Fatalf("NewRotator failed: %v", err)
+	}
+	if roundRobin.Len() != 3 {
+		t.Fatalf("Expected 3 working proxies, got %d", roundRobin.Len())
+	}
+	expected := []string{"http://a.example.com:8080", "http://b.example.com:8080", "http://c.example.com:8080", "http://a.example.com:8080"}
+	for i, want := range expected {
+		if got := roundRobin.Next(); got != want {
+			t.Errorf("Round-robin pick %d: expected %s, got %s", i, want, got)
+		}
+	}
+
+	lru, _ := NewRotator(rotatorResults(), RotationLeastRecentlyUsed)
+	seen := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		seen[lru.Next()] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("Expected least-recently-used to hand out every proxy once, got %v", seen)
+	}
+
+	weighted, _ := NewRotator(rotatorResults(), RotationWeighted)
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		counts[weighted.Next()]++
+	}
+	if counts["http://dead.example.com:8080"] != 0 {
+		t.Error("Weighted rotation returned a failed proxy")
+	}
+	if counts["http://a.example.com:8080"] < counts["http://b.example.com:8080"] {
+		t.Errorf("Expected the fastest proxy to be picked most often, got %v", counts)
+	}
+
+	random, _ := NewRotator(rotatorResults(), RotationRandom)
+	for i := 0; i < 100; i++ {
+		if random.Next() == "http://dead.example.com:8080" {
+			t.Fatal("Random rotation returned a failed proxy")
+		}
+	}
+
+	empty, _ := NewRotator(nil, RotationRoundRobin)
+	if empty.Next() != "" {
+		t.Error("Expected an empty string from an empty rotator")
+	}
+
+	if _, err := NewRotator(nil, "fastest"); err == nil {
+		t.Error("Expected an error for an unknown strategy")
+	}
+}
@@ -0,0 +1,100 @@
+package proxy
+
+import (
+	"math"
+	"time"
+)
+
+// ScoreWeights configures how ProxyResult.Score combines the check results.
+// The weights are relative to each other; the penalties are points taken off
+// the 0-100 score.
+type ScoreWeights struct {
+	Speed                float64 `yaml:"speed_weight"`          // Weight of response time relative to the timeout
+	Anonymity            float64 `yaml:"anonymity_weight"`      // Weight of the anonymity level
+	Reliability          float64 `yaml:"reliability_weight"`    // Weight of the share of applicable check requests that succeeded
+	TamperingPenalty     float64 `yaml:"tampering_penalty"`     // Points off when the proxy altered content
+	VulnerabilityPenalty float64 `yaml:"vulnerability_penalty"` // Points off per internal or metadata access finding
+}
+
+// DefaultScoreWeights returns the weights used when none are configured
+func DefaultScoreWeights() ScoreWeights {
+	return ScoreWeights{
+		Speed:                0.4,
+		Anonymity:            0.35,
+		Reliability:          0.25,
+		TamperingPenalty:     50,
+		VulnerabilityPenalty: 20,
+	}
+}
+
+// anonymityScores rates each anonymity level from 0 to 1
+var anonymityScores = map[AnonymityLevel]float64{
+	AnonymityElite:   1.0,
+	AnonymityBasic:   0.6,
+	AnonymityUnknown: 0.3,
+}
+
+// Score rates a checked proxy from 0 to 100 so proxies can be ranked. Proxies
+// that do not work score 0. Speed is measured against timeout, so a proxy that
+// answers instantly gets the full speed weight and one that takes the whole
+// timeout gets none.
+func (w ScoreWeights) Score(result *ProxyResult, timeout time.Duration) float64 {
+	if !result.Working {
+		return 0
+	}
+	total := w.Speed + w.Anonymity + w.Reliability
+	if total <= 0 {
+		return 0
+	}
+
+	speed := 0.0
+	if timeout > 0 && result.Speed < timeout {
+		speed = 1 - float64(result.Speed)/float64(timeout)
+	}
+
+	anonymity := anonymityScores[result.AnonymityLevel]
+	if result.AnonymityLevel == "" {
+		anonymity = anonymityScores[AnonymityUnknown]
+	}
+
+	// A single successful check says nothing about reliability beyond working.
+	// Type detection attempts with other protocols failing is expected, so
+	// only checks that apply to the detected type count.
+	reliability := 1.0
+	applicable, succeeded := 0, 0
+	for _, check := range result.CheckResults {
+		if check.proxyType != "" && check.proxyType != result.Type {
+			continue
+		}
+		applicable++
+		if check.Success {
+			succeeded++
+		}
+	}
+	if applicable > 1 {
+		reliability = float64(succeeded) / float64(applicable)
+	}
+
+	score := 100 * (w.Speed*speed + w.Anonymity*anonymity + w.Reliability*reliability) / total
+	if result.ContentAltered {
+		score -= w.TamperingPenalty
+	}
+	if result.InternalAccess {
+		score -= w.VulnerabilityPenalty
+	}
+	if result.MetadataAccess {
+		score -= w.VulnerabilityPenalty
+	}
+
+	// Round to one decimal so output is stable and readable
+	return math.Round(math.Max(score, 0)*10) / 10
+}
+
+// scoreResult sets result.Score using the configured weights
+func (c *Checker) scoreResult(result *ProxyResult) {
+	weights := c.config.ScoreWeights
+	if weights == (ScoreWeights{}) {
+		weights = DefaultScoreWeights()
+	}
+	result.Score = weights.Score(result, c.timeout(result))
+}
@@ -0,0 +1,66 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScoreWeights(t *testing.T) {
+	weights := DefaultScoreWeights()
+	timeout := 10 * time.Second
+
+	tests := []struct {
+		name   string
+		result ProxyResult
+		want   float64
+	}{
+		{"not working", ProxyResult{Speed: time.Second, AnonymityLevel: AnonymityElite}, 0},
+		{"instant elite", ProxyResult{Working: true, AnonymityLevel: AnonymityElite}, 100},
+		// 0.4*0.5 + 0.35*0.6 + 0.25*1
+		{"half timeout anonymous", ProxyResult{Working: true, Speed: 5 * time.Second, AnonymityLevel: AnonymityBasic}, 66},
+		// 0.4*0.5 + 0 + 0.25*0.5
+		{"transparent with a failed check", ProxyResult{
+			Working:        true,
+			Speed:          5 * time.Second,
+			AnonymityLevel: AnonymityNone,
+			CheckResults:   []CheckResult{{Success: true}, {Success: false}},
+		}, 32.5},
+		// Failed attempts as other protocols during type detection do not count
+		{"socks5 after other protocols failed", ProxyResult{
+			Working:        true,
+			Type:           ProxyTypeSOCKS5,
+			AnonymityLevel: AnonymityElite,
+			CheckResults: []CheckResult{
+				{proxyType: ProxyTypeHTTP}, {proxyType: ProxyTypeHTTPS},
+				{proxyType: ProxyTypeSOCKS5, Success: true}, {Success: true},
+			},
+		}, 100},
+		{"tampering", ProxyResult{Working: true, AnonymityLevel: AnonymityElite, ContentAltered: true}, 50},
+		{"internal and metadata access", ProxyResult{Working: true, AnonymityLevel: AnonymityElite, InternalAccess: true, MetadataAccess: true}, 60},
+		{"penalties floor at 0", ProxyResult{Working: true, Speed: timeout, ContentAltered: true, InternalAccess: true}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := weights.Score(&tt.result, timeout); got != tt.want {
+			t.Errorf("%s: Score() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScoreResultUsesConfiguredWeights(t *testing.T) {
+	result := &ProxyResult{Working: true, Speed: 5 * time.Second, AnonymityLevel: AnonymityElite}
+
+	// Only speed counts
+	checker := NewChecker(Config{Timeout: 10 * time.Second, ScoreWeights: ScoreWeights{Speed: 1}}, false, nil)
+	checker.scoreResult(result)
+	if result.Score != 50 {
+		t.Errorf("Score = %v with speed-only weights, want 50", result.Score)
+	}
+
+	// Unset weights fall back to the defaults
+	checker = NewChecker(Config{Timeout: 10 * time.Second}, false, nil)
+	checker.scoreResult(result)
+	if want := DefaultScoreWeights().Score(result, 10*time.Second); result.Score != want {
+		t.Errorf("Score = %v with default weights, want %v", result.Score, want)
+	}
+}
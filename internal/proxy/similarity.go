@@ -0,0 +1,116 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+const (
+	// defaultContentSimilarityThreshold is the similarity below which content is flagged as altered
+	defaultContentSimilarityThreshold = 0.8
+)
+
+// checkContentSimilarity compares the proxied validation body with a direct fetch
+// of the same URL. A token Jaccard ratio tolerates small dynamic differences
+// (timestamps, request IDs) while still catching injected or replaced content.
+func (c *Checker) checkContentSimilarity(proxiedBody []byte, result *ProxyResult) {
+	directBody, err := c.directValidationBody()
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[SIMILARITY] Direct fetch failed, skipping comparison: %v\n", err)
+		}
+		return
+	}
+
+	similarity := contentSimilarity(c.capBody(directBody), c.capBody(proxiedBody))
+	result.ContentSimilarity = similarity
+	result.ContentSimilarityChecked = true
+
+	threshold := c.config.ContentSimilarityThreshold
+	if threshold <= 0 {
+		threshold = defaultContentSimilarityThreshold
+	}
+	result.ContentAltered = similarity < threshold
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[SIMILARITY] Similarity to direct fetch: %.2f (threshold: %.2f, altered: %t)\n",
+			similarity, threshold, result.ContentAltered)
+	}
+}
+
+// directValidationBody fetches the validation URL without a proxy once per
+// checker, reading at most MaxResponseBytes of it
+func (c *Checker) directValidationBody() ([]byte, error) {
+	c.directBodyOnce.Do(func() {
+		client := c.directClient(c.config.Timeout)
+
+		req, err := http.NewRequest("GET", c.config.ValidationURL, nil)
+		if err != nil {
+			c.directBodyErr = err
+			return
+		}
+		for key, value := range c.config.DefaultHeaders {
+			req.Header.Set(key, value)
+		}
+		if c.config.UserAgent != "" {
+			req.Header.Set("User-Agent", c.config.UserAgent)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			c.directBodyErr = err
+			return
+		}
+		defer resp.Body.Close()
+
+		maxBytes := c.config.MaxResponseBytes
+		if maxBytes <= 0 {
+			maxBytes = DefaultMaxResponseBytes
+		}
+		c.directBody, c.directBodyErr = io.ReadAll(io.LimitReader(resp.Body, maxBytes))
+	})
+	return c.directBody, c.directBodyErr
+}
+
+// capBody limits a body to the configured comparison size
+func (c *Checker) capBody(body []byte) []byte {
+	if c.config.ContentCompareMaxBytes > 0 && len(body) > c.config.ContentCompareMaxBytes {
+		return body[:c.config.ContentCompareMaxBytes]
+	}
+	return body
+}
+
+// contentSimilarity returns the Jaccard similarity of the word token sets of two bodies
+func contentSimilarity(a, b []byte) float64 {
+	tokensA := tokenSet(a)
+	tokensB := tokenSet(b)
+
+	if len(tokensA) == 0 && len(tokensB) == 0 {
+		return 1
+	}
+
+	intersection := 0
+	for token := range tokensA {
+		if tokensB[token] {
+			intersection++
+		}
+	}
+	union := len(tokensA) + len(tokensB) - intersection
+
+	return float64(intersection) / float64(union)
+}
+
+// tokenSet splits a body into a set of lowercase alphanumeric tokens
+func tokenSet(body []byte) map[string]bool {
+	tokens := make(map[string]bool)
+	fields := strings.FieldsFunc(strings.ToLower(string(body)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, field := range fields {
+		tokens[field] = true
+	}
+	return tokens
+}
@@ -0,0 +1,87 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestContentSimilarity tests the token Jaccard similarity calculation
+func TestContentSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		min  float64
+		max  float64
+	}{
+		{"identical", "<html><body>Hello world</body></html>", "<html><body>Hello world</body></html>", 1, 1},
+		{"both empty", "", "", 1, 1},
+		{"minor dynamic change", "<p>Welcome back. Generated at 10:01 request abc123 for example site home page</p>",
+			"<p>Welcome back. Generated at 10:02 request def456 for example site home page</p>", 0.6, 0.99},
+		{"replaced content", "<p>Welcome to the example site</p>", "<script>steal()</script><p>Buy cheap pills now</p>", 0, 0.2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			similarity := contentSimilarity([]byte(tt.a), []byte(tt.b))
+			if similarity < tt.min || similarity > tt.max {
+				t.Errorf("contentSimilarity() = %.2f, want between %.2f and %.2f", similarity, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+// TestCheckContentSimilarity tests flagging of proxied content that differs from a direct fetch
+func TestCheckContentSimilarity(t *testing.T) {
+	directHits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		directHits++
+		w.Write([]byte("<html><body>The quick brown fox jumps over the lazy dog</body></html>"))
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{
+		Timeout:                    5 * time.Second,
+		ValidationURL:              server.URL,
+		ContentSimilarityCheck:     true,
+		ContentSimilarityThreshold: 0.8,
+	}, false, nil)
+
+	unchanged := &ProxyResult{}
+	checker.checkContentSimilarity([]byte("<html><body>The quick brown fox jumps over the lazy dog</body></html>"), unchanged)
+	if !unchanged.ContentSimilarityChecked || unchanged.ContentAltered || unchanged.ContentSimilarity != 1 {
+		t.Errorf("Expected unchanged content to match, got similarity=%.2f altered=%t",
+			unchanged.ContentSimilarity, unchanged.ContentAltered)
+	}
+
+	altered := &ProxyResult{}
+	checker.checkContentSimilarity([]byte("<html><body><script src=//ads.example></script>Sponsored content</body></html>"), altered)
+	if !altered.ContentAltered {
+		t.Errorf("Expected altered content to be flagged, got similarity=%.2f", altered.ContentSimilarity)
+	}
+
+	// The direct fetch is shared between checks
+	if directHits != 1 {
+		t.Errorf("Expected one direct fetch, got %d", directHits)
+	}
+}
+
+func TestDirectValidationBodyCapped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 4096)))
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{Timeout: 5 * time.Second, ValidationURL: server.URL, MaxResponseBytes: 1024}, false, nil)
+
+	body, err := checker.directValidationBody()
+	if err != nil {
+		t.Fatalf("directValidationBody() error = %v", err)
+	}
+	if len(body) != 1024 {
+		t.Errorf("Expected the direct body capped at 1024 bytes, got %d", len(body))
+	}
+}
@@ -0,0 +1,76 @@
+package proxy
+
+import "strings"
+
+// Sources recorded on SoftwareInfo
+const (
+	SoftwareSourceFingerprint = "fingerprint"
+	SoftwareSourceExtended    = "extended_checks"
+	SoftwareSourceVendor      = "vendor_checks"
+)
+
+// SoftwareInfo is a piece of server software seen in front of a proxy
+type SoftwareInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Source  string `json:"source"` // Check that detected it
+}
+
+// collectDetectedSoftware gathers the software and versions found by
+// fingerprinting and the vuln checks into result.DetectedSoftware, so they
+// are available in one place instead of scattered across the result structs
+func collectDetectedSoftware(result *ProxyResult) {
+	var detected []SoftwareInfo
+	add := func(name, version, source string) {
+		if name == "" {
+			return
+		}
+		for i := range detected {
+			if !strings.EqualFold(detected[i].Name, name) {
+				continue
+			}
+			if detected[i].Version == "" && version != "" {
+				detected[i].Version = version
+				detected[i].Source = source
+			}
+			if detected[i].Version == version || version == "" {
+				return
+			}
+		}
+		detected = append(detected, SoftwareInfo{Name: name, Version: version, Source: source})
+	}
+
+	if fp := result.Fingerprint; fp != nil && fp.ProxySoftware != ProxySoftwareUnknown {
+		add(string(fp.ProxySoftware), fp.Version, SoftwareSourceFingerprint)
+	}
+
+	if ext := result.ExtendedVulnerabilities; ext != nil && ext.NginxVersionDetected {
+		add(string(ProxySoftwareNginx), ext.NginxVersion, SoftwareSourceExtended)
+	}
+
+	if vendor := result.VendorVulnerabilities; vendor != nil {
+		if vendor.HAProxyVersionDetected {
+			add(string(ProxySoftwareHAProxy), vendor.HAProxyVersion, SoftwareSourceVendor)
+		}
+		if vendor.SquidVersionDetected {
+			add(string(ProxySoftwareSquid), vendor.SquidVersion, SoftwareSourceVendor)
+		}
+		if vendor.EnvoyVersionDetected {
+			add(string(ProxySoftwareEnvoy), vendor.EnvoyVersion, SoftwareSourceVendor)
+		}
+		if vendor.CaddyVersionDetected {
+			add(string(ProxySoftwareCaddy), vendor.CaddyVersion, SoftwareSourceVendor)
+		}
+		if vendor.VarnishVersionDetected {
+			add(string(ProxySoftwareVarnish), vendor.VarnishVersion, SoftwareSourceVendor)
+		}
+		if vendor.F5VersionDetected {
+			add("f5-bigip", vendor.F5Version, SoftwareSourceVendor)
+		}
+		if vendor.NginxPlusVersionDetected {
+			add("nginx-plus", vendor.NginxPlusVersion, SoftwareSourceVendor)
+		}
+	}
+
+	result.DetectedSoftware = detected
+}
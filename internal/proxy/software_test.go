@@ -0,0 +1,42 @@
+package proxy
+
+import "testing"
+
+func TestCollectDetectedSoftware(t *testing.T) {
+	result := &ProxyResult{
+		Fingerprint: &FingerprintResult{ProxySoftware: ProxySoftwareSquid},
+		ExtendedVulnerabilities: &ExtendedVulnResult{
+			NginxVersionDetected: true,
+			NginxVersion:         "1.18.0",
+		},
+		VendorVulnerabilities: &VendorVulnResult{
+			SquidVersionDetected: true,
+			SquidVersion:         "5.7",
+			HAProxyVersion:       "2.4", // Not detected, ignored
+		},
+	}
+
+	collectDetectedSoftware(result)
+
+	want := []SoftwareInfo{
+		{Name: "squid", Version: "5.7", Source: SoftwareSourceVendor},
+		{Name: "nginx", Version: "1.18.0", Source: SoftwareSourceExtended},
+	}
+	if len(result.DetectedSoftware) != len(want) {
+		t.Fatalf("DetectedSoftware = %+v, want %+v", result.DetectedSoftware, want)
+	}
+	for i := range want {
+		if result.DetectedSoftware[i] != want[i] {
+			t.Errorf("DetectedSoftware[%d] = %+v, want %+v", i, result.DetectedSoftware[i], want[i])
+		}
+	}
+}
+
+func TestCollectDetectedSoftwareUnknownFingerprint(t *testing.T) {
+	result := &ProxyResult{Fingerprint: &FingerprintResult{ProxySoftware: ProxySoftwareUnknown}}
+
+	collectDetectedSoftware(result)
+	if len(result.DetectedSoftware) != 0 {
+		t.Errorf("Expected no software for an unknown fingerprint, got %+v", result.DetectedSoftware)
+	}
+}
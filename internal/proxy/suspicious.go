@@ -0,0 +1,305 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Indicators of suspicious proxy behavior, see checkSuspiciousBehavior
+const (
+	IndicatorAnyCredentials     = "any_credentials"     // Proxy accepted random credentials in place of the configured ones
+	IndicatorIdenticalResponses = "identical_responses" // Proxy answered two different targets with the same response
+	IndicatorTrackingInjection  = "tracking_injection"  // Proxied page carried scripts or trackers the direct fetch did not
+	IndicatorLatencyAnomaly     = "latency_anomaly"     // Proxy answered too fast to have contacted the target
+)
+
+const (
+	// defaultMinUpstreamLatency is the least time a forwarded request is
+	// expected to take from being sent to the proxy to its first response byte
+	defaultMinUpstreamLatency = 2 * time.Millisecond
+	// maxSuspiciousBodyBytes limits how much of a probe response is compared
+	maxSuspiciousBodyBytes = 64 * 1024
+)
+
+// suspiciousIndicatorWeights is how much each indicator adds to SuspiciousScore
+var suspiciousIndicatorWeights = map[string]float64{
+	IndicatorAnyCredentials:     0.4,
+	IndicatorIdenticalResponses: 0.4,
+	IndicatorTrackingInjection:  0.4,
+	IndicatorLatencyAnomaly:     0.2,
+}
+
+// trackingMarkers are lowercase fragments of injected scripts and trackers
+var trackingMarkers = [][]byte{
+	[]byte("<script"),
+	[]byte("<iframe"),
+	[]byte("document.write("),
+	[]byte("google-analytics.com"),
+	[]byte("googletagmanager.com"),
+	[]byte("connect.facebook.net"),
+}
+
+// IsSuspiciousIndicator reports whether name is a known suspicious behavior indicator
+func IsSuspiciousIndicator(name string) bool {
+	_, ok := suspiciousIndicatorWeights[name]
+	return ok
+}
+
+// suspiciousIndicatorEnabled reports whether the indicator is tested; all
+// indicators are when none are configured
+func (c *Checker) suspiciousIndicatorEnabled(name string) bool {
+	if len(c.config.SuspiciousIndicators) == 0 {
+		return true
+	}
+	for _, enabled := range c.config.SuspiciousIndicators {
+		if enabled == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSuspiciousBehavior runs heuristics that flag proxies behaving like
+// honeypots or tampering middleboxes and records the indicators that fired in
+// result.SuspiciousIndicators. SuspiciousScore adds up their weights, capped
+// at 1. No single indicator proves anything; they are meant for triage.
+func (c *Checker) checkSuspiciousBehavior(proxyURL *url.URL, client *http.Client, result *ProxyResult) {
+	var indicators []string
+	flag := func(name, reason string) {
+		indicators = append(indicators, name)
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[SUSPICIOUS] %s: %s\n", name, reason)
+		}
+	}
+
+	if c.suspiciousIndicatorEnabled(IndicatorAnyCredentials) {
+		if c.acceptsAnyCredentials(proxyURL, result) {
+			flag(IndicatorAnyCredentials, "random credentials were accepted")
+		}
+	}
+
+	latency := c.suspiciousIndicatorEnabled(IndicatorLatencyAnomaly)
+	if latency || c.suspiciousIndicatorEnabled(IndicatorIdenticalResponses) || c.suspiciousIndicatorEnabled(IndicatorTrackingInjection) {
+		probe, err := c.fetchProbeBody(client, c.config.ValidationURL, result)
+		if err != nil {
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[SUSPICIOUS] Validation fetch failed, skipping response indicators: %v\n", err)
+			}
+		} else {
+			if c.suspiciousIndicatorEnabled(IndicatorIdenticalResponses) {
+				// A host that cannot resolve must not produce the validation page
+				probeURL := "http://proxyhawk-" + randomToken() + ".invalid/"
+				other, err := c.fetchProbeBody(client, probeURL, result)
+				if err == nil && other.status == probe.status && len(probe.body) > 0 && bytes.Equal(other.body, probe.body) {
+					flag(IndicatorIdenticalResponses, fmt.Sprintf("%s answered like %s", probeURL, c.config.ValidationURL))
+				}
+			}
+			if c.suspiciousIndicatorEnabled(IndicatorTrackingInjection) {
+				if marker := c.injectedTrackingMarker(probe.body, result); marker != "" {
+					flag(IndicatorTrackingInjection, fmt.Sprintf("proxied body contains %q absent from the direct fetch", marker))
+				}
+			}
+			if latency && probe.answer > 0 {
+				threshold := c.config.MinUpstreamLatency
+				if threshold <= 0 {
+					threshold = defaultMinUpstreamLatency
+				}
+				if probe.answer < threshold {
+					flag(IndicatorLatencyAnomaly, fmt.Sprintf("answered %v after the request was sent, below %v", probe.answer, threshold))
+				}
+			}
+		}
+	}
+
+	sort.Strings(indicators)
+	score := 0.0
+	for _, name := range indicators {
+		score += suspiciousIndicatorWeights[name]
+	}
+	if score > 1 {
+		score = 1
+	}
+	result.SuspiciousIndicators = indicators
+	result.SuspiciousScore = score
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[SUSPICIOUS] Score: %.2f %v\n", score, indicators)
+	}
+}
+
+// acceptsAnyCredentials checks a proxy that is used with credentials again
+// with random ones and reports whether it still serves the validation URL.
+// Proxies used without credentials, and proxies that do not turn away a
+// request without credentials, are not tested: accepting anything means
+// nothing when no credentials are needed.
+func (c *Checker) acceptsAnyCredentials(proxyURL *url.URL, result *ProxyResult) bool {
+	// A scratch result keeps the extra clients' debug output and warnings apart
+	scratch := &ProxyResult{Timeout: result.Timeout}
+	if c.getProxyAuth(proxyURL, scratch) == nil {
+		return false
+	}
+
+	scheme := string(result.Type)
+	switch result.Type {
+	case ProxyTypeHTTP, ProxyTypeHTTPS, ProxyTypeSOCKS4, ProxyTypeSOCKS4A, ProxyTypeSOCKS5:
+	default:
+		return false
+	}
+
+	if !c.requiresCredentials(proxyURL, scheme, result, scratch) {
+		return false
+	}
+
+	bogusURL := *proxyURL
+	bogusURL.User = url.UserPassword("proxyhawk-"+randomToken(), randomToken())
+	client, err := c.createClient(&bogusURL, scheme, scratch)
+	if err != nil {
+		return false
+	}
+
+	check := c.fetchValidationURL(client, c.config.ValidationURL, scratch)
+	recordTraffic(result, scratch.RequestCount, scratch.BytesDownloaded)
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[SUSPICIOUS] Random credentials: status=%d %s\n", check.StatusCode, check.Error)
+	}
+	return check.Success
+}
+
+// requiresCredentials requests the validation URL through the proxy without
+// any credentials, not even the configured defaults, and reports whether the
+// proxy turned it away: a 407 from an HTTP proxy, a failed handshake from a
+// SOCKS proxy
+func (c *Checker) requiresCredentials(proxyURL *url.URL, scheme string, result, scratch *ProxyResult) bool {
+	bareURL := *proxyURL
+	bareURL.User = nil
+
+	var transport *http.Transport
+	if scheme == "http" || scheme == "https" {
+		transport = c.createAuthenticatedHTTPTransport(&bareURL, scheme, nil, scratch)
+	} else {
+		transport = &http.Transport{DisableKeepAlives: true}
+		transport.DialContext = c.createAuthenticatedSOCKSDialer(&bareURL, scheme, nil, scratch)
+	}
+	tlsConfig, err := c.tlsConfig(scratch)
+	if err != nil {
+		return false
+	}
+	transport.TLSClientConfig = tlsConfig
+	defer transport.CloseIdleConnections()
+
+	client := &http.Client{
+		Transport: &countingTransport{base: transport, result: scratch},
+		Timeout:   c.timeout(result),
+	}
+	check := c.fetchValidationURL(client, c.config.ValidationURL, scratch)
+	recordTraffic(result, scratch.RequestCount, scratch.BytesDownloaded)
+	scratch.RequestCount, scratch.BytesDownloaded = 0, 0
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[SUSPICIOUS] Without credentials: status=%d %s\n", check.StatusCode, check.Error)
+	}
+
+	if scheme == "http" || scheme == "https" {
+		return check.StatusCode == http.StatusProxyAuthRequired
+	}
+	return check.StatusCode == 0 && check.Error != ""
+}
+
+// injectedTrackingMarker returns the first tracking marker found in the
+// proxied body but not in a direct fetch of the validation URL, or "" when
+// there is none or the direct fetch failed
+func (c *Checker) injectedTrackingMarker(proxiedBody []byte, result *ProxyResult) string {
+	directBody, err := c.directValidationBody()
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[SUSPICIOUS] Direct fetch failed, skipping tracking check: %v\n", err)
+		}
+		return ""
+	}
+
+	proxied := bytes.ToLower(proxiedBody)
+	direct := bytes.ToLower(directBody)
+	for _, marker := range trackingMarkers {
+		if bytes.Contains(proxied, marker) && !bytes.Contains(direct, marker) {
+			return string(marker)
+		}
+	}
+	return ""
+}
+
+// probeResponse is a response to a suspicious behavior probe
+type probeResponse struct {
+	status int
+	body   []byte        // Up to maxSuspiciousBodyBytes of the body
+	answer time.Duration // From writing the request to the first response byte
+}
+
+// fetchProbeBody requests targetURL through the proxy and returns the
+// response along with how long the proxy took to start answering it
+func (c *Checker) fetchProbeBody(client *http.Client, targetURL string, result *ProxyResult) (probeResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout(result))
+	defer cancel()
+
+	// The hooks run on the transport's goroutines
+	var (
+		mu               sync.Mutex
+		wrote, firstByte time.Time
+	)
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			mu.Lock()
+			wrote = time.Now()
+			mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			mu.Lock()
+			firstByte = time.Now()
+			mu.Unlock()
+		},
+	})
+
+	req, err := http.NewRequestWithContext(ctx, "GET", targetURL, nil)
+	if err != nil {
+		return probeResponse{}, err
+	}
+	for key, value := range c.config.DefaultHeaders {
+		req.Header.Set(key, value)
+	}
+	if c.config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return probeResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	probe := probeResponse{status: resp.StatusCode}
+	mu.Lock()
+	if !wrote.IsZero() && firstByte.After(wrote) {
+		probe.answer = firstByte.Sub(wrote)
+	}
+	mu.Unlock()
+	probe.body, err = io.ReadAll(io.LimitReader(resp.Body, maxSuspiciousBodyBytes))
+	if err != nil {
+		return probeResponse{}, err
+	}
+	return probe, nil
+}
+
+// randomToken returns 16 random hex characters
+func randomToken() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,186 @@
+package proxy
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckSuspiciousBehavior(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"ip":"198.51.100.7"}`)
+	}))
+	defer target.Close()
+
+	wantAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:secret"))
+
+	// An honest proxy checks credentials, fails unresolvable hosts and forwards the rest untouched
+	honest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") != wantAuth {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		if strings.HasSuffix(r.URL.Hostname(), ".invalid") {
+			http.Error(w, "unknown host", http.StatusBadGateway)
+			return
+		}
+		resp, err := http.Get(r.URL.String())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	defer honest.Close()
+
+	// A honeypot asks for credentials, takes any and answers everything
+	// itself with a page carrying a tracker
+	honeypot := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") == "" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		io.WriteString(w, `<html><script src="https://www.google-analytics.com/analytics.js"></script></html>`)
+	}))
+	defer honeypot.Close()
+
+	// An open proxy ignores credentials altogether, which says nothing suspicious
+	open := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Hostname(), ".invalid") {
+			http.Error(w, "unknown host", http.StatusBadGateway)
+			return
+		}
+		resp, err := http.Get(r.URL.String())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	defer open.Close()
+
+	tests := []struct {
+		name  string
+		proxy *httptest.Server
+		want  []string
+		score float64
+	}{
+		{"honest proxy", honest, nil, 0},
+		{"honeypot", honeypot, []string{IndicatorAnyCredentials, IndicatorIdenticalResponses, IndicatorTrackingInjection}, 1},
+		{"open proxy", open, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(Config{
+				Timeout:         2 * time.Second,
+				ValidationURL:   target.URL,
+				CheckSuspicious: true,
+				SuspiciousIndicators: []string{
+					IndicatorAnyCredentials, IndicatorIdenticalResponses, IndicatorTrackingInjection,
+				},
+			}, false, nil)
+
+			proxyURL, _ := url.Parse(strings.Replace(tt.proxy.URL, "http://", "http://user:secret@", 1))
+			result := &ProxyResult{Type: ProxyTypeHTTP}
+			client, err := checker.createClient(proxyURL, "http", result)
+			if err != nil {
+				t.Fatalf("createClient() error = %v", err)
+			}
+
+			checker.checkSuspiciousBehavior(proxyURL, client, result)
+
+			if !reflect.DeepEqual(result.SuspiciousIndicators, tt.want) {
+				t.Errorf("SuspiciousIndicators = %v, want %v", result.SuspiciousIndicators, tt.want)
+			}
+			if result.SuspiciousScore != tt.score {
+				t.Errorf("SuspiciousScore = %v, want %v", result.SuspiciousScore, tt.score)
+			}
+			if result.RequestCount == 0 {
+				t.Error("probe requests were not counted")
+			}
+		})
+	}
+}
+
+func TestSuspiciousLatencyAnomaly(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		io.WriteString(w, "ok")
+	}))
+	defer target.Close()
+
+	forwarding := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, err := http.Get(r.URL.String())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		io.Copy(w, resp.Body)
+	}))
+	defer forwarding.Close()
+
+	instant := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer instant.Close()
+
+	tests := []struct {
+		name      string
+		proxy     *httptest.Server
+		threshold time.Duration
+		want      bool
+	}{
+		{"instant answer", instant, 20 * time.Millisecond, true},
+		{"forwarded answer", forwarding, 20 * time.Millisecond, false},
+		{"custom threshold", forwarding, time.Second, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(Config{
+				Timeout:              2 * time.Second,
+				ValidationURL:        target.URL,
+				CheckSuspicious:      true,
+				SuspiciousIndicators: []string{IndicatorLatencyAnomaly},
+				MinUpstreamLatency:   tt.threshold,
+			}, false, nil)
+			proxyURL, _ := url.Parse(tt.proxy.URL)
+			client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+			// Timings of other requests must not matter
+			result := &ProxyResult{Speed: time.Hour, ConnectLatency: time.Millisecond}
+
+			checker.checkSuspiciousBehavior(proxyURL, client, result)
+
+			if got := len(result.SuspiciousIndicators) == 1; got != tt.want {
+				t.Errorf("latency_anomaly flagged = %v, want %v (indicators %v)", got, tt.want, result.SuspiciousIndicators)
+			}
+			if tt.want && result.SuspiciousScore != suspiciousIndicatorWeights[IndicatorLatencyAnomaly] {
+				t.Errorf("SuspiciousScore = %v, want %v", result.SuspiciousScore, suspiciousIndicatorWeights[IndicatorLatencyAnomaly])
+			}
+		})
+	}
+}
+
+func TestIsSuspiciousIndicator(t *testing.T) {
+	for _, name := range []string{IndicatorAnyCredentials, IndicatorIdenticalResponses, IndicatorTrackingInjection, IndicatorLatencyAnomaly} {
+		if !IsSuspiciousIndicator(name) {
+			t.Errorf("IsSuspiciousIndicator(%q) = false, want true", name)
+		}
+	}
+	if IsSuspiciousIndicator("open_proxy") {
+		t.Error("IsSuspiciousIndicator(\"open_proxy\") = true, want false")
+	}
+}
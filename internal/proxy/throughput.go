@@ -0,0 +1,110 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultThroughputBytes is the payload size downloaded by the throughput
+	// check when ThroughputBytes is unset
+	DefaultThroughputBytes = 1 << 20
+
+	// defaultThroughputURL serves a payload of the requested number of bytes
+	defaultThroughputURL = "https://speed.cloudflare.com/__down?bytes=%d"
+
+	// bytesPerMB converts bytes to the MB of ThroughputMBps
+	bytesPerMB = 1 << 20
+)
+
+// throughputURL returns the payload URL of the throughput check: the
+// configured URL, or the default endpoint sized to the payload
+func (c *Checker) throughputURL(size int64) string {
+	if c.config.ThroughputURL != "" {
+		return c.config.ThroughputURL
+	}
+	return fmt.Sprintf(defaultThroughputURL, size)
+}
+
+// measureThroughput downloads a payload through the proxy and records the
+// transfer rate in result.ThroughputMBps. The rate covers the body only, so
+// the latency to the first byte does not lower it. A transfer cut off by the
+// deadline still reports the rate of what arrived, marked as partial.
+func (c *Checker) measureThroughput(client *http.Client, result *ProxyResult) {
+	size := c.config.ThroughputBytes
+	if size <= 0 {
+		size = DefaultThroughputBytes
+	}
+	timeout := c.config.ThroughputTimeout
+	if timeout <= 0 {
+		timeout = c.timeout(result)
+	}
+	payloadURL := c.throughputURL(size)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", payloadURL, nil)
+	if err != nil {
+		return
+	}
+	for key, value := range c.config.DefaultHeaders {
+		req.Header.Set(key, value)
+	}
+	if c.config.UserAgent != "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+	// Compressed payloads would overstate the rate
+	req.Header.Set("Accept-Encoding", "identity")
+
+	// The deadline is the context's; the client's own timeout is the check
+	// timeout, which would cut a longer download short as a failure
+	download := *client
+	download.Timeout = 0
+	resp, err := download.Do(req)
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[THROUGHPUT] Request to %s failed: %v\n", payloadURL, err)
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[THROUGHPUT] %s returned status %d\n", payloadURL, resp.StatusCode)
+		}
+		return
+	}
+
+	start := time.Now()
+	read, err := io.Copy(io.Discard, io.LimitReader(resp.Body, size))
+	elapsed := time.Since(start)
+
+	partial := false
+	if err != nil {
+		// Only the deadline cutting the transfer short yields a partial rate
+		if ctx.Err() == nil {
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[THROUGHPUT] Download from %s failed after %d bytes: %v\n", payloadURL, read, err)
+			}
+			return
+		}
+		partial = true
+	}
+	if read == 0 || elapsed <= 0 {
+		return
+	}
+
+	result.ThroughputMBps = float64(read) / bytesPerMB / elapsed.Seconds()
+	result.ThroughputBytes = read
+	result.ThroughputPartial = partial
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[THROUGHPUT] %d bytes in %v: %.2f MB/s (partial: %t)\n",
+			read, elapsed, result.ThroughputMBps, partial)
+	}
+}
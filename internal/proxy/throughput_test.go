@@ -0,0 +1,112 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMeasureThroughput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(64<<10))
+		w.Write([]byte(strings.Repeat("x", 64<<10)))
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{
+		Timeout:           2 * time.Second,
+		MeasureThroughput: true,
+		ThroughputURL:     server.URL,
+		ThroughputBytes:   32 << 10,
+	}, false, nil)
+
+	result := &ProxyResult{}
+	checker.measureThroughput(server.Client(), result)
+	if result.ThroughputMBps <= 0 || result.ThroughputPartial {
+		t.Errorf("Expected a complete measurement, got %.2f MB/s (partial: %t)", result.ThroughputMBps, result.ThroughputPartial)
+	}
+	if result.ThroughputBytes != 32<<10 {
+		t.Errorf("Expected the download to stop at the payload size, read %d bytes", result.ThroughputBytes)
+	}
+}
+
+func TestMeasureThroughputPartial(t *testing.T) {
+	// Sends a first chunk, then stalls past the deadline
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 4096)))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{
+		Timeout:           time.Second,
+		MeasureThroughput: true,
+		ThroughputURL:     server.URL,
+		ThroughputTimeout: 300 * time.Millisecond,
+	}, false, nil)
+
+	start := time.Now()
+	result := &ProxyResult{}
+	checker.measureThroughput(server.Client(), result)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the deadline to stop the download, took %v", elapsed)
+	}
+	if !result.ThroughputPartial || result.ThroughputMBps <= 0 || result.ThroughputBytes != 4096 {
+		t.Errorf("Expected a partial measurement of 4096 bytes, got %.4f MB/s over %d bytes (partial: %t)",
+			result.ThroughputMBps, result.ThroughputBytes, result.ThroughputPartial)
+	}
+}
+
+func TestMeasureThroughputOutlastsCheckTimeout(t *testing.T) {
+	// Trickles the payload out over longer than the check timeout
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(4096))
+		for i := 0; i < 4; i++ {
+			w.Write([]byte(strings.Repeat("x", 1024)))
+			w.(http.Flusher).Flush()
+			time.Sleep(150 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{
+		Timeout:           200 * time.Millisecond,
+		MeasureThroughput: true,
+		ThroughputURL:     server.URL,
+		ThroughputBytes:   4096,
+		ThroughputTimeout: 5 * time.Second,
+	}, false, nil)
+
+	// Checks hand over a client bounded by the check timeout
+	client := server.Client()
+	client.Timeout = checker.timeout(nil)
+
+	result := &ProxyResult{}
+	checker.measureThroughput(client, result)
+	if result.ThroughputMBps <= 0 || result.ThroughputPartial || result.ThroughputBytes != 4096 {
+		t.Errorf("Expected a complete measurement of 4096 bytes, got %.4f MB/s over %d bytes (partial: %t)",
+			result.ThroughputMBps, result.ThroughputBytes, result.ThroughputPartial)
+	}
+}
+
+func TestMeasureThroughputFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{Timeout: time.Second, MeasureThroughput: true, ThroughputURL: server.URL}, false, nil)
+
+	result := &ProxyResult{}
+	checker.measureThroughput(server.Client(), result)
+	if result.ThroughputMBps != 0 || result.ThroughputBytes != 0 {
+		t.Errorf("Expected no measurement for a rejected download, got %.2f MB/s", result.ThroughputMBps)
+	}
+}
@@ -0,0 +1,53 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// LoadCACertPool returns the system root CAs extended with the PEM certificates
+// in caCertFile. An empty file name returns nil so the system roots are used as-is.
+func LoadCACertPool(caCertFile string) (*x509.CertPool, error) {
+	if caCertFile == "" {
+		return nil, nil
+	}
+
+	pemData, err := os.ReadFile(caCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+	}
+
+	roots, err := x509.SystemCertPool()
+	if err != nil || roots == nil {
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(pemData) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", caCertFile)
+	}
+
+	return roots, nil
+}
+
+// tlsConfig returns the TLS configuration used for connections to targets.
+// Verification is skipped unless VerifyTLS is set, in which case the roots from
+// CACertFile are trusted alongside the system roots.
+func (c *Checker) tlsConfig(result *ProxyResult) (*tls.Config, error) {
+	if !c.config.VerifyTLS {
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	}
+
+	c.rootCAsOnce.Do(func() {
+		c.rootCAs, c.rootCAsErr = LoadCACertPool(c.config.CACertFile)
+	})
+	if c.rootCAsErr != nil {
+		return nil, c.rootCAsErr
+	}
+
+	if c.debug && c.rootCAs != nil {
+		result.DebugInfo += fmt.Sprintf("[TLS] Verifying targets with additional roots from %s\n", c.config.CACertFile)
+	}
+
+	return &tls.Config{RootCAs: c.rootCAs}, nil
+}
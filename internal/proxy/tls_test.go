@@ -0,0 +1,57 @@
+package proxy
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestTLSConfigCACertFile tests that VerifyTLS trusts the roots loaded from CACertFile
+func TestTLSConfigCACertFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	get := func(config Config) error {
+		checker := NewChecker(config, false, nil)
+		tlsConfig, err := checker.tlsConfig(&ProxyResult{})
+		if err != nil {
+			return err
+		}
+		client := &http.Client{
+			Timeout:   5 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	if err := get(Config{VerifyTLS: true}); err == nil {
+		t.Error("Expected verification to fail without the CA file")
+	}
+	if err := get(Config{VerifyTLS: true, CACertFile: caFile}); err != nil {
+		t.Errorf("Expected verification to succeed with the CA file, got: %v", err)
+	}
+	if err := get(Config{}); err != nil {
+		t.Errorf("Expected unverified request to succeed, got: %v", err)
+	}
+
+	if _, err := LoadCACertPool(filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("Expected an error for a missing CA file")
+	}
+}
@@ -0,0 +1,180 @@
+package proxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Where the fingerprints a proxied certificate chain is compared with come from
+const (
+	PinSourceConfigured = "configured" // PinnedCertFingerprints for the host
+	PinSourceDirect     = "direct"     // The chain the target presents without the proxy
+)
+
+// TLSInfo describes the certificate a target presented through the proxy
+type TLSInfo struct {
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	SANs        []string  `json:"sans,omitempty"`
+	NotBefore   time.Time `json:"not_before"`
+	NotAfter    time.Time `json:"not_after"`
+	Fingerprint string    `json:"fingerprint"` // Hex SHA-256 of the leaf certificate
+
+	// Intercepted is set when no certificate of the chain matches a pinned
+	// fingerprint, i.e. the proxy presented its own certificate (MITM)
+	Intercepted bool   `json:"intercepted"`
+	PinSource   string `json:"pin_source,omitempty"` // PinSourceConfigured or PinSourceDirect; empty = nothing to compare with
+}
+
+// directPins caches the fingerprints of the chains targets present directly
+type directPins struct {
+	mutex   sync.Mutex
+	entries map[string][]string // host:port -> fingerprints
+}
+
+// CertFingerprint returns the hex SHA-256 fingerprint of a DER certificate
+func CertFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeFingerprint lowercases a hex fingerprint and strips the colons
+// and spaces it is often written with
+func NormalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.NewReplacer(":", "", " ", "").Replace(fingerprint))
+}
+
+// inspectTLS records the certificate the target presented on a response
+// received through the proxy and compares its chain with the pinned
+// fingerprints for the host
+func (c *Checker) inspectTLS(state *tls.ConnectionState, targetURL string, result *ProxyResult) {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[TLS] No certificate to inspect for %s (not an HTTPS target)\n", targetURL)
+		}
+		return
+	}
+
+	leaf := state.PeerCertificates[0]
+	info := &TLSInfo{
+		Subject:     leaf.Subject.String(),
+		Issuer:      leaf.Issuer.String(),
+		SANs:        certSANs(leaf),
+		NotBefore:   leaf.NotBefore,
+		NotAfter:    leaf.NotAfter,
+		Fingerprint: CertFingerprint(leaf),
+	}
+	result.TLSInfo = info
+
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return
+	}
+	pins, source := c.certPins(parsed, result)
+	if len(pins) == 0 {
+		return
+	}
+	info.PinSource = source
+
+	pinned := make(map[string]bool, len(pins))
+	for _, pin := range pins {
+		pinned[NormalizeFingerprint(pin)] = true
+	}
+	info.Intercepted = true
+	for _, cert := range state.PeerCertificates {
+		if pinned[CertFingerprint(cert)] {
+			info.Intercepted = false
+			break
+		}
+	}
+
+	if info.Intercepted {
+		result.SecurityWarnings = append(result.SecurityWarnings, fmt.Sprintf(
+			"TLS certificate for %s (issuer %s) does not match the %s fingerprints - proxy intercepts TLS",
+			parsed.Hostname(), info.Issuer, source))
+	}
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[TLS] %s: subject %q, issuer %q, expires %s, leaf %s, intercepted: %t (%s pins)\n",
+			parsed.Host, info.Subject, info.Issuer, info.NotAfter.Format(time.RFC3339), info.Fingerprint, info.Intercepted, source)
+	}
+}
+
+// certPins returns the fingerprints a proxied chain for target should
+// contain: the configured ones for its host, or else those of the chain the
+// target presents on a direct connection
+func (c *Checker) certPins(target *url.URL, result *ProxyResult) ([]string, string) {
+	for host, pins := range c.config.PinnedCertFingerprints {
+		if strings.EqualFold(host, target.Hostname()) && len(pins) > 0 {
+			return pins, PinSourceConfigured
+		}
+	}
+
+	port := target.Port()
+	if port == "" {
+		port = "443"
+	}
+	addr := net.JoinHostPort(target.Hostname(), port)
+
+	c.directPins.mutex.Lock()
+	pins, ok := c.directPins.entries[addr]
+	c.directPins.mutex.Unlock()
+	if ok {
+		return pins, PinSourceDirect
+	}
+
+	pins, err := c.directCertFingerprints(target.Hostname(), addr, result)
+	if err != nil {
+		// Failed handshakes are not cached so a later check can retry
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[TLS] Direct handshake with %s failed, cannot tell whether TLS is intercepted: %v\n", addr, err)
+		}
+		return nil, ""
+	}
+
+	c.directPins.mutex.Lock()
+	if c.directPins.entries == nil {
+		c.directPins.entries = make(map[string][]string)
+	}
+	c.directPins.entries[addr] = pins
+	c.directPins.mutex.Unlock()
+	return pins, PinSourceDirect
+}
+
+// directCertFingerprints completes a TLS handshake with addr without the
+// proxy being checked and returns the fingerprints of every certificate of
+// its chain. It dials through the base dialer, so an SSH tunnel, proxy chain
+// or custom resolver is used like for every other connection.
+func (c *Checker) directCertFingerprints(serverName, addr string, result *ProxyResult) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout(result))
+	defer cancel()
+	conn, err := c.dialTLSContext(ctx, "tcp", addr, &tls.Config{ServerName: serverName, InsecureSkipVerify: true})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	pins := make([]string, len(certs))
+	for i, cert := range certs {
+		pins[i] = CertFingerprint(cert)
+	}
+	return pins, nil
+}
+
+// certSANs lists the DNS names and IP addresses a certificate is valid for
+func certSANs(cert *x509.Certificate) []string {
+	sans := append([]string(nil), cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return sans
+}
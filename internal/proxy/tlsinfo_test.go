@@ -0,0 +1,211 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// selfSignedCert creates a certificate for 127.0.0.1 issued by commonName
+func selfSignedCert(t *testing.T, commonName string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// startInterceptingProxy starts a CONNECT proxy that terminates every tunnel
+// at its own TLS server instead of the requested address
+func startInterceptingProxy(t *testing.T) net.Listener {
+	interceptor := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "intercepted")
+	}))
+	interceptor.TLS = &tls.Config{Certificates: []tls.Certificate{selfSignedCert(t, "Intercepting Proxy CA")}}
+	interceptor.StartTLS()
+	t.Cleanup(interceptor.Close)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				if req, err := http.ReadRequest(reader); err != nil || req.Method != http.MethodConnect {
+					return
+				}
+				upstream, err := net.Dial("tcp", interceptor.Listener.Addr().String())
+				if err != nil {
+					return
+				}
+				defer upstream.Close()
+				conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+				go io.Copy(upstream, reader)
+				io.Copy(conn, upstream)
+			}(conn)
+		}
+	}()
+	return listener
+}
+
+// tlsInspectionClient returns a client using the CONNECT proxy at addr that
+// accepts any target certificate, as the checker does by default
+func tlsInspectionClient(addr string) *http.Client {
+	proxyURL := &url.URL{Scheme: "http", Host: addr}
+	return &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+}
+
+func TestInspectTLS(t *testing.T) {
+	target := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer target.Close()
+	targetFingerprint := CertFingerprint(target.Certificate())
+
+	// Pins are accepted in the colon-separated uppercase form tools print
+	var colonPin []string
+	for i := 0; i < len(targetFingerprint); i += 2 {
+		colonPin = append(colonPin, strings.ToUpper(targetFingerprint[i:i+2]))
+	}
+
+	tunnel := startTunnelProxy(t)
+	defer tunnel.Close()
+	intercepting := startInterceptingProxy(t)
+
+	tests := []struct {
+		name        string
+		proxy       net.Listener
+		pins        map[string][]string
+		source      string
+		intercepted bool
+	}{
+		{"tunnel, direct pins", tunnel, nil, PinSourceDirect, false},
+		{"intercepting, direct pins", intercepting, nil, PinSourceDirect, true},
+		{"tunnel, configured pin", tunnel, map[string][]string{"127.0.0.1": {strings.Join(colonPin, ":")}}, PinSourceConfigured, false},
+		{"tunnel, wrong configured pin", tunnel, map[string][]string{"127.0.0.1": {strings.Repeat("ab", 32)}}, PinSourceConfigured, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(Config{
+				Timeout:                5 * time.Second,
+				ValidationURL:          target.URL,
+				InspectTLS:             true,
+				PinnedCertFingerprints: tt.pins,
+			}, false, nil)
+			result := &ProxyResult{Type: ProxyTypeHTTP}
+
+			if err := checker.performChecks(tlsInspectionClient(tt.proxy.Addr().String()), result); err != nil {
+				t.Fatalf("performChecks() error = %v", err)
+			}
+			info := result.TLSInfo
+			if info == nil {
+				t.Fatal("TLSInfo not recorded")
+			}
+			if info.PinSource != tt.source || info.Intercepted != tt.intercepted {
+				t.Errorf("PinSource = %q, Intercepted = %t; want %q, %t", info.PinSource, info.Intercepted, tt.source, tt.intercepted)
+			}
+			if tt.intercepted {
+				if len(result.SecurityWarnings) == 0 {
+					t.Error("Expected a security warning for the intercepted certificate")
+				}
+				return
+			}
+			if info.Fingerprint != targetFingerprint || info.Subject == "" || info.Issuer == "" || info.NotAfter.IsZero() {
+				t.Errorf("TLSInfo = %+v, want the target certificate %s", info, targetFingerprint)
+			}
+		})
+	}
+}
+
+func TestInspectTLSPlainHTTPTarget(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer target.Close()
+
+	checker := NewChecker(Config{Timeout: 5 * time.Second, ValidationURL: target.URL, InspectTLS: true}, false, nil)
+	result := &ProxyResult{Type: ProxyTypeHTTP}
+	if err := checker.performChecks(http.DefaultClient, result); err != nil {
+		t.Fatalf("performChecks() error = %v", err)
+	}
+	if result.TLSInfo != nil {
+		t.Errorf("TLSInfo = %+v, want none for a plain HTTP target", result.TLSInfo)
+	}
+}
+
+// TestInspectTLSDirectHandshakeUsesBaseDialer tests that the direct handshake
+// for the comparison chain goes through the base dialer, e.g. an SSH tunnel
+func TestInspectTLSDirectHandshakeUsesBaseDialer(t *testing.T) {
+	target := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer target.Close()
+	tunnel := startTunnelProxy(t)
+	defer tunnel.Close()
+
+	targetAddr := target.Listener.Addr().String()
+	dialed := make(chan string, 4)
+	checker := NewChecker(Config{
+		Timeout:       5 * time.Second,
+		ValidationURL: target.URL,
+		InspectTLS:    true,
+		BaseDialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed <- addr
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}, false, nil)
+	result := &ProxyResult{Type: ProxyTypeHTTP}
+
+	if err := checker.performChecks(tlsInspectionClient(tunnel.Addr().String()), result); err != nil {
+		t.Fatalf("performChecks() error = %v", err)
+	}
+	if result.TLSInfo == nil || result.TLSInfo.PinSource != PinSourceDirect {
+		t.Fatalf("TLSInfo = %+v, want direct pins", result.TLSInfo)
+	}
+	close(dialed)
+	for addr := range dialed {
+		if addr == targetAddr {
+			return
+		}
+	}
+	t.Errorf("Expected the direct handshake with %s to use the base dialer", targetAddr)
+}
@@ -0,0 +1,69 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// countingTransport records every request sent through a proxy client and the
+// response body bytes read back on the result being checked
+type countingTransport struct {
+	base   http.RoundTripper
+	result *ProxyResult
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recordTraffic(t.result, 1, 0)
+
+	resp, err := t.base.RoundTrip(req)
+	if resp != nil && resp.Body != nil {
+		resp.Body = &countingBody{ReadCloser: resp.Body, result: t.result}
+	}
+	return resp, err
+}
+
+// countingBody adds the bytes read from a response body to the result's total
+type countingBody struct {
+	io.ReadCloser
+	result *ProxyResult
+}
+
+// Read implements io.Reader
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	recordTraffic(b.result, 0, int64(n))
+	return n, err
+}
+
+// countTraffic returns a copy of client whose requests are counted on result.
+// The copy shares the original transport, so pooled connections are still reused.
+func countTraffic(client *http.Client, result *ProxyResult) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	counted := *client
+	counted.Transport = &countingTransport{base: base, result: result}
+	return &counted
+}
+
+// unwrapTransport returns the transport underneath a traffic-counting wrapper
+func unwrapTransport(rt http.RoundTripper) http.RoundTripper {
+	if counting, ok := rt.(*countingTransport); ok {
+		return counting.base
+	}
+	return rt
+}
+
+// recordTraffic adds requests and downloaded bytes to the result's counters
+func recordTraffic(result *ProxyResult, requests, bytes int64) {
+	if requests != 0 {
+		atomic.AddInt64(&result.RequestCount, requests)
+	}
+	if bytes != 0 {
+		atomic.AddInt64(&result.BytesDownloaded, bytes)
+	}
+}
@@ -0,0 +1,72 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/pool"
+)
+
+// TestCountTraffic tests that requests and body bytes are recorded on the result
+func TestCountTraffic(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0123456789"))
+	}))
+	defer server.Close()
+
+	result := &ProxyResult{}
+	client := countTraffic(&http.Client{}, result)
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	if result.RequestCount != 3 {
+		t.Errorf("Expected 3 requests, got %d", result.RequestCount)
+	}
+	if result.BytesDownloaded != 30 {
+		t.Errorf("Expected 30 bytes downloaded, got %d", result.BytesDownloaded)
+	}
+}
+
+// TestPooledClientRedirects tests that a client taken from the connection
+// pool handles redirects like a manual one and counts its traffic
+func TestPooledClientRedirects(t *testing.T) {
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://login.example.com/sso", http.StatusMovedPermanently)
+	}))
+	defer proxyServer.Close()
+
+	checker := NewChecker(Config{
+		Timeout:        2 * time.Second,
+		ConnectionPool: pool.NewConnectionPool(pool.DefaultConfig()),
+	}, true, nil)
+
+	proxyURL, _ := url.Parse(proxyServer.URL)
+	result := &ProxyResult{}
+	client, err := checker.createClient(proxyURL, "http", result)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	resp, err := client.Get("http://example.com/start")
+	if err != nil {
+		t.Fatalf("Expected the redirect response itself, got error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMovedPermanently {
+		t.Errorf("Expected the 301 itself, got %d", resp.StatusCode)
+	}
+	if result.RequestCount != 1 {
+		t.Errorf("Expected 1 counted request, got %d", result.RequestCount)
+	}
+}
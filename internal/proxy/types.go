@@ -1,6 +1,9 @@
 package proxy
 
 import (
+	"context"
+	"crypto/x509"
+	"net"
 	"sync"
 	"time"
 
@@ -18,6 +21,7 @@ const (
 	ProxyTypeHTTP2   ProxyType = "http2"
 	ProxyTypeHTTP3   ProxyType = "http3"
 	ProxyTypeSOCKS4  ProxyType = "socks4"
+	ProxyTypeSOCKS4A ProxyType = "socks4a"
 	ProxyTypeSOCKS5  ProxyType = "socks5"
 )
 
@@ -29,12 +33,18 @@ type Config struct {
 	ValidationPattern  string
 	DisallowedKeywords []string
 	MinResponseBytes   int
+	MaxResponseBytes   int64         // Give up on bodies larger than this (default: 10MB)
+	BodyReadTimeout    time.Duration // Give up on bodies still streaming after this (default: Timeout)
 	DefaultHeaders     map[string]string
 	UserAgent          string
 	EnableCloudChecks  bool
 	CloudProviders     []cloudcheck.CloudProvider
 	UseRDNS            bool // Whether to use rDNS lookup for host headers
 
+	// ExternalValidationCommand gets the validation response body on stdin and
+	// must exit 0 for the proxy to count as working (empty = disabled)
+	ExternalValidationCommand string
+
 	// Rate limiting settings
 	RateLimitEnabled  bool          // Whether rate limiting is enabled
 	RateLimitDelay    time.Duration // Delay between requests to the same host
@@ -48,6 +58,11 @@ type Config struct {
 	MaxDelay        time.Duration // Maximum delay between retries (default: 30s)
 	BackoffFactor   float64       // Exponential backoff multiplier (default: 2.0)
 	RetryableErrors []string      // List of error patterns that should trigger retries
+	HonorRetryAfter bool          // Wait out Retry-After before retrying a 429 response
+	MaxRetryAfter   time.Duration // Longest Retry-After a retry or the rate limiter waits for (default: 1m)
+
+	// ScoreWeights configures ProxyResult.Score (zero value uses DefaultScoreWeights)
+	ScoreWeights ScoreWeights
 
 	// Authentication settings
 	AuthEnabled     bool     // Whether proxy authentication is enabled
@@ -60,6 +75,59 @@ type Config struct {
 	RequireContentMatch string
 	RequireHeaderFields []string
 
+	// RequireBothHTTPAndHTTPS only reports a proxy as working when it handled both HTTP and HTTPS targets
+	RequireBothHTTPAndHTTPS bool
+
+	// Validation quorum: a proxy must also reach RequiredSuccessCount of ValidationURLs (0 = disabled)
+	ValidationURLs       []string
+	RequiredSuccessCount int
+
+	// MinimalHeaders repeats the validation request with only Host and User-Agent and reports differences
+	MinimalHeaders bool
+
+	// EchoHeaders records the headers a header-echo endpoint received through the proxy
+	EchoHeaders    bool
+	EchoHeadersURL string // Header-echo endpoint (default: AnonymityCheckURL)
+
+	// CheckWebSocket opens a WebSocket to an echo endpoint through the proxy and verifies the echo
+	CheckWebSocket   bool
+	WebSocketEchoURL string // WebSocket echo endpoint (default: wss://echo.websocket.org)
+
+	// LookupEgressPTR records the IP each working proxy egresses from and its reverse DNS (PTR) name
+	LookupEgressPTR bool
+	EgressIPURL     string // Endpoint returning the caller's IP address (default: https://api.ipify.org)
+
+	// TLS inspection: record the certificate of HTTPS validation targets and
+	// flag chains that match none of the pinned fingerprints (TLSInfo)
+	InspectTLS             bool
+	PinnedCertFingerprints map[string][]string // Hostname -> SHA-256 fingerprints, one of which the chain must contain (default: the chain seen without the proxy)
+
+	// ForceIPv6Target validates against the IPv6 address of the validation
+	// host, so only proxies that reach IPv6 targets pass (SupportsIPv6Target)
+	ForceIPv6Target bool
+
+	// Redirect following for validation requests (off = redirects are reported, not followed)
+	FollowRedirects bool
+	MaxRedirects    int // Redirects followed per request (default: DefaultMaxRedirects)
+
+	// Throughput measurement: download a payload through each working proxy
+	MeasureThroughput bool
+	ThroughputURL     string        // Payload URL (default: a Cloudflare endpoint serving ThroughputBytes)
+	ThroughputBytes   int64         // Bytes to download (default: DefaultThroughputBytes)
+	ThroughputTimeout time.Duration // Deadline of the download (default: Timeout)
+
+	// Rotation detection: sample the exit IP several times to spot rotating
+	// pools behind one endpoint. Samples are fetched from EgressIPURL.
+	DetectRotation    bool
+	RotationSamples   int           // Exit IP samples per proxy (default: DefaultRotationSamples)
+	RotationInterval  time.Duration // Pause between samples (0 = none)
+	RotationThreshold float64       // Share of samples that must change exit IP, 0-1 (default: DefaultRotationThreshold)
+
+	// Suspicious behavior heuristics (honeypots, tampering proxies), scored into SuspiciousScore
+	CheckSuspicious      bool
+	SuspiciousIndicators []string      // Indicators to test (empty = all)
+	MinUpstreamLatency   time.Duration // Least time a forwarded request should take from being sent to its answer (default: 2ms)
+
 	// Advanced security checks
 	AdvancedChecks AdvancedChecks
 
@@ -70,12 +138,106 @@ type Config struct {
 	// Connection pool settings
 	ConnectionPool interface{} // Will be set to *pool.ConnectionPool, but using interface{} to avoid circular import
 
+	// BaseDialer opens every connection to a proxy, e.g. through an SSH tunnel (nil dials directly)
+	BaseDialer func(ctx context.Context, network, addr string) (net.Conn, error)
+
+	// Resolver looks up proxy and target hostnames, see NewResolver (nil = the
+	// system resolver). Without a BaseDialer, proxies are dialed through it too.
+	Resolver *net.Resolver
+
+	// ProxyChain is a jump proxy URL (http, socks4, socks4a or socks5) every
+	// proxy is reached through, for proxies only reachable via another proxy.
+	// The jump proxy itself is dialed with BaseDialer.
+	ProxyChain string
+
+	// DetectionOrder lists the proxy types tried for proxies without a scheme,
+	// e.g. ["socks5", "socks4", "http", "https"]; detection stops at the first
+	// type that works (empty = HTTP/HTTPS first, then SOCKS)
+	DetectionOrder []string
+
 	// HTTP/2 and HTTP/3 settings
 	EnableHTTP2 bool // Whether to enable HTTP/2 protocol detection and support
 	EnableHTTP3 bool // Whether to enable HTTP/3 protocol detection and support
 
 	// Fingerprinting settings
 	EnableFingerprint bool // Whether to enable proxy software fingerprinting
+
+	// HTTPVersion is the request version used for an additional HTTP/1.0 check ("1.0" or "1.1", default: "1.1")
+	HTTPVersion string
+
+	// Content similarity settings
+	ContentSimilarityCheck     bool    // Compare the proxied validation body with a direct fetch
+	ContentCompareMaxBytes     int     // Maximum bytes of each body to compare (0 = whole body)
+	ContentSimilarityThreshold float64 // Similarity below which content is flagged as altered (default: 0.8)
+
+	// Anonymity check settings
+	AnonymityCheckURL         string        // Header-echo endpoint used for anonymity detection (default: httpbin.org/headers)
+	AnonymityFallbackURLs     []string      // Alternate header-echo endpoints used while the primary is rate limited
+	AnonymityRateLimitBackoff time.Duration // How long all workers avoid an endpoint after it returns 429 (default: 30s)
+
+	// TLS verification settings
+	VerifyTLS  bool   // Verify target certificates instead of skipping verification
+	CACertFile string // PEM file of additional trusted root CAs used when VerifyTLS is set
+
+	// Target resolution cache settings
+	ResolveOnce bool          // Resolve each target hostname once and reuse it across checks
+	DNSCacheTTL time.Duration // How long cached resolutions are reused (default: 5m)
+
+	// DNSRetries is how many times a transient DNS failure (SERVFAIL, timeout) is retried, independent of RetryEnabled
+	DNSRetries int
+
+	// Vuln probe scope: paths (globs or prefixes) the path-enumeration checks may touch
+	VulnPathAllowlist []string // Only these paths are probed when set
+	VulnPathDenylist  []string // These paths are never probed; wins over the allowlist
+
+	// ReadOnlyVulnChecks restricts vuln scanning to GET/HEAD requests, skipping
+	// the checks in MutatingVulnChecks (BAN, POST/DELETE smuggling, PUT, CONNECT)
+	ReadOnlyVulnChecks bool
+
+	// VulnScanConcurrency caps how many proxies run the advanced/vuln scan phase at once (0 = unlimited)
+	VulnScanConcurrency int
+
+	// GeoIPURL is the IP-geolocation endpoint queried through proxies with an expected exit country
+	GeoIPURL string
+
+	// Proxy classification by the ASN/organization the proxy egresses from
+	ClassifyProxies     bool     // Tag working proxies as datacenter, residential, mobile or unknown
+	ProxyClassURL       string   // Endpoint returning the exit ASN/organization (default: https://ipinfo.io/org)
+	DatacenterKeywords  []string // Organization keywords marking datacenter networks (empty = built-in list)
+	ResidentialKeywords []string // Organization keywords marking residential ISPs (empty = built-in list)
+	MobileKeywords      []string // Organization keywords marking mobile carriers (empty = built-in list)
+
+	// Site category access: request representative URLs per category through working proxies
+	CheckCategories bool
+	CategoryURLs    map[string][]string // Category name to representative URLs (empty = built-in categories)
+
+	// gRPC test target: when set, proxies are checked by issuing a
+	// grpc.health.v1.Health/Check to this target instead of HTTP validation
+	GRPCTarget  string // grpc://host:port (h2c) or grpcs://host:port (TLS)
+	GRPCService string // Service name sent in the health check (empty = overall server health)
+
+	// Exit IP reputation: DNSBL and abuse-score lookups of each working
+	// proxy's exit IP, cached per IP for the run
+	CheckReputation   bool
+	DNSBLZones        []string      // DNSBL zones to query (empty = DefaultDNSBLZone)
+	ReputationAPIURL  string        // Abuse-score endpoint, {ip} is replaced by the exit IP (empty = DNSBLs only)
+	ReputationAPIKey  string        // Sent in the Key header, as AbuseIPDB expects
+	ReputationTimeout time.Duration // Per lookup (0 = 3s)
+
+	// ResultHook, when set, is called with every finished result before
+	// Check returns it, so embedders can annotate or rewrite results with
+	// their own enrichment (a custom geo database, internal reputation). It
+	// runs synchronously on the goroutine that called Check, which for
+	// concurrent scans means several worker goroutines at once: the hook must
+	// be safe for concurrent use, may only modify the result it is given, and
+	// delays the scan for as long as it runs.
+	ResultHook func(*ProxyResult)
+}
+
+// CheckOptions are per-proxy settings for a single check
+type CheckOptions struct {
+	Timeout       time.Duration // Overrides the configured timeout when non-zero
+	ExpectCountry string        // ISO country code the proxy should egress from (empty = not checked)
 }
 
 // CheckResult represents the result of a single check
@@ -86,6 +248,15 @@ type CheckResult struct {
 	Error      string
 	StatusCode int
 	BodySize   int64
+
+	// RedirectLocation is the Location of a 3xx response, recorded without following it
+	RedirectLocation string
+
+	// unreachable is set when the request failed because the proxy refused the connection or had no route
+	unreachable bool
+
+	// proxyType is the protocol the proxy was tried as during type detection (empty for other checks)
+	proxyType ProxyType
 }
 
 // AnonymityLevel represents the anonymity level of a proxy
@@ -105,7 +276,10 @@ type ProxyResult struct {
 	ProxyURL              string
 	Working               bool
 	Speed                 time.Duration
-	Error                 error
+	ConnectLatency        time.Duration // Time to connect to the proxy and complete its handshake (first hop), part of Speed
+	Timeout               time.Duration // Per-proxy timeout override used for this check (0 = configured default)
+	Input                 string        // Proxy as written in the input list, if it differs from ProxyURL
+	Error                 error // *errors.ProxyError; match with errors.Is against errors.ErrTimeout, errors.ErrConnRefused, ...
 	Type                  ProxyType
 	ProxyType             ProxyType
 	CheckResults          []CheckResult
@@ -113,28 +287,132 @@ type ProxyResult struct {
 	AnonymityLevel        AnonymityLevel // Detailed anonymity level
 	RealIP                string
 	ProxyIP               string
-	DetectedIP            string         // IP address detected during anonymity check
-	LeakingHeaders        []string       // Headers that leak information
-	ProxyChainDetected    bool           // Whether proxy-behind-proxy was detected
-	ProxyChainInfo        string         // Details about proxy chain
+	DetectedIP            string   // IP address detected during anonymity check
+	AnonymityRateLimited  bool     // Anonymity detection degraded because the echo endpoint rate limited us
+	LeakingHeaders        []string // Headers that leak information
+	ProxyChainDetected    bool     // Whether proxy-behind-proxy was detected
+	ProxyChainInfo        string   // Details about proxy chain
 	CloudProvider         string
 	InternalAccess        bool
 	MetadataAccess        bool
 	ResolvedHost          string
 	AdvancedChecksPassed  bool
+	EnforcesHost          bool // Proxy answers the target's real Host but rejects a wrong Host
+	DNSLeak               bool // Target hostname was resolved by the client's resolver instead of the proxy's
 	AdvancedChecksDetails map[string]interface{}
 	DebugInfo             string
 	SecurityWarnings      []string // Security warnings (e.g., TLS verification disabled)
 
 	// New fields for protocol support
-	SupportsHTTP  bool
-	SupportsHTTPS bool
-	SupportsHTTP2 bool
-	SupportsHTTP3 bool
+	SupportsHTTP      bool
+	SupportsHTTPS     bool
+	SupportsHTTP2     bool
+	SupportsHTTP3     bool
+	SupportsHTTP10    bool // Proxy answered an HTTP/1.0 request (only checked when HTTPVersion is "1.0")
+	HTTP10Only        bool // Proxy works over HTTP/1.0 but failed every HTTP/1.1 check
+	SupportsConnect   bool // Proxy accepted a bare CONNECT to the validation host on port 443
+	ConnectStatusCode int  // Status returned by the CONNECT probe (0 if the probe could not complete)
+	ConnectOnly       bool // Proxy rejects plain GET (405/501) but tunnels HTTPS with CONNECT
+
+	// Retry-After waits taken before retrying a 429 response (only with HonorRetryAfter)
+	RetryAfterDelays []time.Duration
+
+	// AuthRequired is set when the proxy answered 407 and the configured
+	// credentials (if any) did not satisfy it
+	AuthRequired bool
+
+	// Content similarity against a direct fetch (only when ContentSimilarityCheck is enabled)
+	ContentSimilarity        float64 // Token Jaccard similarity, 0-1
+	ContentSimilarityChecked bool    // Whether the comparison ran
+	ContentAltered           bool    // Similarity fell below the configured threshold
+
+	// Minimal header comparison (only when MinimalHeaders is enabled)
+	MinimalHeadersChecked bool // Whether the minimal-header request got a response
+	MinimalHeadersStatus  int  // Status of the validation request sent with only Host and User-Agent
+	FullHeadersStatus     int  // Status of the same request with the full header set (0 if it got no response)
+	HeaderBlocking        bool // The full header set was rejected while the minimal set was accepted
+
+	// Headers the echo endpoint received through the proxy (only when EchoHeaders is enabled)
+	ReceivedHeaders map[string][]string
+
+	// Exit country (only when an expected country was given for the proxy)
+	ExpectedCountry string // Country the proxy was expected to egress from
+	ExitCountry     string // Country reported by the geolocation endpoint (empty if the lookup failed)
+	CountryMismatch bool   // ExitCountry differs from ExpectedCountry
+
+	// Network classification (only with proxy classification enabled)
+	ProxyClass ProxyClass // datacenter, residential, mobile or unknown
+	ExitOrg    string     // ASN and organization the proxy egresses from
+
+	// Egress reverse DNS (only when LookupEgressPTR is enabled)
+	EgressIP  string // IP address the proxy egresses from
+	EgressPTR string // PTR name of EgressIP (empty if it has none or the lookup failed)
+
+	// Download throughput (only when MeasureThroughput is enabled)
+	ThroughputMBps    float64 // Payload bytes per second of body transfer, in MB (2^20 bytes)
+	ThroughputBytes   int64   // Payload bytes the rate is based on
+	ThroughputPartial bool    // The download hit its deadline before the full payload arrived
+
+	// Exit IP rotation (only when DetectRotation is enabled)
+	RotationChecked bool     // Whether at least two exit IP samples succeeded
+	IsRotating      bool     // The exit IP changed on at least RotationThreshold of the samples
+	ObservedExitIPs []string // Distinct exit IPs in the order they were first seen
+
+	// Site categories the proxy can reach (only with category checks enabled)
+	CategoryAccess map[string]bool
+
+	// Exit IP reputation (only when CheckReputation is enabled)
+	ReputationChecked bool     // Whether any DNSBL or the abuse-score API answered
+	ReputationScore   int      // 0-100, higher is worse: the abuse score or the share of answering DNSBLs listing the IP, whichever is higher
+	Blocklists        []string // DNSBL zones listing the exit IP
+
+	// WebSocket tunneling (only when CheckWebSocket is enabled)
+	WebSocketChecked  bool // Whether the WebSocket handshake was attempted
+	SupportsWebSocket bool // A frame sent over a WebSocket through the proxy was echoed back
+
+	// Suspicious behavior (only when CheckSuspicious is enabled)
+	SuspiciousScore      float64  // 0-1, sum of the weights of the indicators that fired
+	SuspiciousIndicators []string // Indicators that fired, e.g. any_credentials, tracking_injection
+
+	// Why a response body was cut off: UnboundedSizeLimit or UnboundedReadTimeout
+	// (empty = every body was read in full)
+	UnboundedResponse string
+
+	// Why a followed redirect chain was aborted: RedirectLoop or RedirectLimit
+	// (only when FollowRedirects is enabled)
+	RedirectFailure string
+
+	// IPv6 connectivity (only when AdvancedChecks.TestIPv6 is enabled)
+	IPv6Checked  bool // Whether the IPv6-only endpoint was tried
+	SupportsIPv6 bool // Proxy reached the IPv6-only endpoint
+
+	// Certificate of the HTTPS validation target (only when InspectTLS is enabled)
+	TLSInfo *TLSInfo
+
+	// IPv6 validation target (only when the validation URL is IPv6-only or ForceIPv6Target is set)
+	IPv6TargetChecked  bool // Whether the validation request went to an IPv6 address
+	SupportsIPv6Target bool // Proxy reached the validation target over IPv6
+
+	// gRPC health check (only when a gRPC target is configured)
+	SupportsGRPC bool   // Proxy tunneled a gRPC health check to the target
+	GRPCStatus   string // Serving status reported by the target, e.g. SERVING or NOT_SERVING
+
+	// Quality score from 0 to 100 combining speed, anonymity and reliability (0 when not working)
+	Score float64
+
+	// Free-form key/value annotations added by Config.ResultHook; never set by the checker itself
+	Annotations map[string]string
+
+	// Traffic sent through the proxy, updated atomically while checks run
+	RequestCount    int64 // HTTP requests issued through the proxy
+	BytesDownloaded int64 // Response body bytes read through the proxy
 
 	// Fingerprinting information
 	Fingerprint *FingerprintResult `json:"fingerprint,omitempty"`
 
+	// Server software and versions found by fingerprinting and the vuln checks
+	DetectedSoftware []SoftwareInfo `json:"detected_software,omitempty"`
+
 	// Vulnerability scan results
 	NginxVulnerabilities    *NginxVulnResult    `json:"nginx_vulnerabilities,omitempty"`
 	ApacheVulnerabilities   *ApacheVulnResult   `json:"apache_vulnerabilities,omitempty"`
@@ -143,6 +421,12 @@ type ProxyResult struct {
 	ExtendedVulnerabilities *ExtendedVulnResult `json:"extended_vulnerabilities,omitempty"`
 	VendorVulnerabilities   *VendorVulnResult   `json:"vendor_vulnerabilities,omitempty"`
 	AdvancedSSRFVulnerabilities *AdvancedSSRFResult `json:"advanced_ssrf_vulnerabilities,omitempty"`
+
+	// Vuln probe paths skipped because they fall outside VulnPathAllowlist/VulnPathDenylist
+	SkippedVulnPaths []string `json:"skipped_vuln_paths,omitempty"`
+
+	// State-changing vuln checks skipped because ReadOnlyVulnChecks is set
+	SkippedVulnChecks []string `json:"skipped_vuln_checks,omitempty"`
 }
 
 // Checker represents the main proxy checker
@@ -152,4 +436,40 @@ type Checker struct {
 	logger          *logging.Logger      // Logger for output
 	rateLimiter     map[string]time.Time // Map of host to last request time
 	rateLimiterLock sync.Mutex           // Mutex to protect the rate limiter map
+	echoBackoff     map[string]time.Time // Map of anonymity echo endpoint to the end of its rate-limit backoff
+	echoBackoffLock sync.Mutex           // Mutex to protect the echo backoff map
+
+	// Direct fetch of the validation URL, shared by all content similarity checks
+	directBodyOnce sync.Once
+	directBody     []byte
+	directBodyErr  error
+
+	// Root CAs for target verification, loaded once from CACertFile
+	rootCAsOnce sync.Once
+	rootCAs     *x509.CertPool
+	rootCAsErr  error
+
+	// Target hostname resolutions shared across checks when ResolveOnce is set
+	resolveCache resolveCache
+
+	// Fingerprints of the certificate chains targets present without a proxy
+	directPins directPins
+
+	// Resolvers the client's own lookups reach, probed once for DNS leak checks
+	clientResolversOnce sync.Once
+	clientResolvers     map[string]bool
+
+	// Address families of validation hosts, for SupportsIPv6Target
+	targetFamilies targetFamilies
+
+	// DNSBL and abuse-score answers for exit IPs
+	reputation reputationCache
+
+	// Slots bounding concurrent vuln scans (nil when VulnScanConcurrency is unset)
+	vulnScanSlots chan struct{}
+
+	// Jump proxy every proxy is reached through (nil without ProxyChain), or
+	// why ProxyChain could not be used
+	chain    *chainDialer
+	chainErr error
 }
@@ -0,0 +1,28 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"syscall"
+)
+
+// proxyUnreachable reports whether err shows that the proxy itself refused
+// the connection or cannot be routed to, so every other protocol and proxy
+// type tried on the same host:port is bound to fail the same way. Failures
+// the proxy relays about the target (a SOCKS "connection refused" reply, a
+// 502 from an HTTP proxy) are not dial errors and do not match.
+func proxyUnreachable(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EHOSTUNREACH) ||
+		errors.Is(err, syscall.ENETUNREACH)
+}
+
+// proxyUnreachableError ends type detection for a proxy that cannot be connected to
+func (c *Checker) proxyUnreachableError(proxyURL *url.URL, errMsg string, result *ProxyResult) error {
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[TYPE] %s is unreachable, skipping remaining protocols and proxy types: %s\n",
+			proxyURL.Host, errMsg)
+	}
+	return fmt.Errorf("could not determine proxy type: %s", errMsg)
+}
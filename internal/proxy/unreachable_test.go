@@ -0,0 +1,70 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// closedPort returns an address on localhost that refuses connections
+func closedPort(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	return addr
+}
+
+// TestProxyUnreachable tests which errors count as terminal for a proxy
+func TestProxyUnreachable(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection refused", refused, true},
+		{"wrapped refusal", &url.Error{Op: "Get", URL: "http://example.com", Err: refused}, true},
+		{"no route to host", fmt.Errorf("proxyconnect: %w", syscall.EHOSTUNREACH), true},
+		{"network unreachable", syscall.ENETUNREACH, true},
+		{"socks reply from target", errors.New("unknown error connection refused"), false},
+		{"timeout", errors.New("i/o timeout"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := proxyUnreachable(tt.err); got != tt.want {
+			t.Errorf("%s: proxyUnreachable() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestDetermineProxyTypeStopsWhenRefused tests that a refused proxy is not
+// retried with the other protocols and proxy types
+func TestDetermineProxyTypeStopsWhenRefused(t *testing.T) {
+	addr := closedPort(t)
+	checker := NewChecker(Config{Timeout: 2 * time.Second}, false, nil)
+
+	// Without a scheme every candidate type would otherwise be tried
+	for _, proxyURL := range []string{"http://" + addr, "//" + addr} {
+		parsed, _ := url.Parse(proxyURL)
+		result := &ProxyResult{}
+		proxyType, client, err := checker.determineProxyType(parsed, result)
+		if err == nil || client != nil || proxyType != ProxyTypeUnknown {
+			t.Fatalf("%s: expected detection to fail, got %s, %v", proxyURL, proxyType, err)
+		}
+		if !strings.Contains(err.Error(), "connection refused") {
+			t.Errorf("%s: expected the refusal in the error, got %v", proxyURL, err)
+		}
+		if len(result.CheckResults) != 1 {
+			t.Errorf("%s: expected a single attempt, got %d", proxyURL, len(result.CheckResults))
+		}
+	}
+}
@@ -9,6 +9,16 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/errors"
+)
+
+const (
+	// defaultAnonymityCheckURL echoes request headers back as JSON
+	defaultAnonymityCheckURL = "https://httpbin.org/headers"
+
+	// defaultAnonymityRateLimitBackoff is how long an echo endpoint is avoided after a 429
+	defaultAnonymityRateLimitBackoff = 30 * time.Second
 )
 
 // validateResponse validates the HTTP response
@@ -66,34 +76,16 @@ func isValidIP(ip string) bool {
 
 // checkAnonymity checks if the proxy is anonymous and detects proxy chaining
 // Returns: isAnonymous, anonymityLevel, detectedIP, leakingHeaders, chainDetected, chainInfo, error
-func (c *Checker) checkAnonymity(client *http.Client) (bool, AnonymityLevel, string, []string, bool, string, error) {
+func (c *Checker) checkAnonymity(client *http.Client, result *ProxyResult) (bool, AnonymityLevel, string, []string, bool, string, error) {
 	// First, get our real IP without proxy
 	realIP, err := getRealIP()
-	if err != nil {
+	if err != nil && c.debug {
 		// If we can't get real IP, we can't properly validate anonymity
-		if c.debug {
-			// Note: result.DebugInfo not available here, log to stderr if needed
-		}
+		result.DebugInfo += fmt.Sprintf("[ANONYMITY] Failed to determine real IP: %v\n", err)
 	}
 
 	// Use a service that returns headers to detect IP leaks
-	testURL := "https://httpbin.org/headers"
-
-	req, err := http.NewRequest("GET", testURL, nil)
-	if err != nil {
-		return false, AnonymityUnknown, "", nil, false, "", err
-	}
-
-	// Set a unique User-Agent to identify our request
-	req.Header.Set("User-Agent", c.config.UserAgent)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return false, AnonymityUnknown, "", nil, false, "", err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.fetchEchoHeaders(client, result)
 	if err != nil {
 		return false, AnonymityUnknown, "", nil, false, "", err
 	}
@@ -167,6 +159,115 @@ func (c *Checker) checkAnonymity(client *http.Client) (bool, AnonymityLevel, str
 	}
 }
 
+// anonymityEndpoints returns the configured header-echo endpoints in order of preference
+func (c *Checker) anonymityEndpoints() []string {
+	primary := c.config.AnonymityCheckURL
+	if primary == "" {
+		primary = defaultAnonymityCheckURL
+	}
+	return append([]string{primary}, c.config.AnonymityFallbackURLs...)
+}
+
+// fetchEchoHeaders requests the header-echo endpoint through the proxy. Endpoints that
+// answer 429 are put into a backoff shared by all workers and the next configured
+// endpoint is tried instead. If every endpoint is backing off, the call waits for the
+// earliest backoff to expire before giving up.
+func (c *Checker) fetchEchoHeaders(client *http.Client, result *ProxyResult) ([]byte, error) {
+	endpoints := c.anonymityEndpoints()
+
+	for attempt := 0; attempt < 2; attempt++ {
+		var wait time.Duration
+		for _, endpoint := range endpoints {
+			if remaining := c.echoBackoffRemaining(endpoint); remaining > 0 {
+				result.AnonymityRateLimited = true
+				if wait == 0 || remaining < wait {
+					wait = remaining
+				}
+				continue
+			}
+
+			body, statusCode, err := c.requestEchoEndpoint(client, endpoint)
+			if err != nil {
+				return nil, err
+			}
+			if statusCode != http.StatusTooManyRequests {
+				// An endpoint answered, so anonymity detection is not degraded
+				result.AnonymityRateLimited = false
+				return body, nil
+			}
+
+			backoff := c.startEchoBackoff(endpoint)
+			result.AnonymityRateLimited = true
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[ANONYMITY] %s returned 429, backing off for %v\n", endpoint, backoff)
+			}
+			if wait == 0 || backoff < wait {
+				wait = backoff
+			}
+		}
+
+		// All endpoints are rate limited; pause once and try again
+		if attempt == 0 && wait > 0 {
+			result.AnonymityRateLimited = true
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[ANONYMITY] All echo endpoints rate limited, waiting %v\n", wait)
+			}
+			time.Sleep(wait)
+		}
+	}
+
+	return nil, errors.NewHTTPError(errors.ErrorProxyRateLimited, "anonymity echo endpoints rate limited", endpoints[0], nil)
+}
+
+// requestEchoEndpoint performs a single request against a header-echo endpoint
+func (c *Checker) requestEchoEndpoint(client *http.Client, endpoint string) ([]byte, int, error) {
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Set a unique User-Agent to identify our request
+	req.Header.Set("User-Agent", c.config.UserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+
+	return body, resp.StatusCode, nil
+}
+
+// echoBackoffRemaining returns how long an echo endpoint is still backing off
+func (c *Checker) echoBackoffRemaining(endpoint string) time.Duration {
+	c.echoBackoffLock.Lock()
+	defer c.echoBackoffLock.Unlock()
+
+	if until, exists := c.echoBackoff[endpoint]; exists {
+		return time.Until(until)
+	}
+	return 0
+}
+
+// startEchoBackoff marks an echo endpoint as rate limited for all workers
+func (c *Checker) startEchoBackoff(endpoint string) time.Duration {
+	backoff := c.config.AnonymityRateLimitBackoff
+	if backoff <= 0 {
+		backoff = defaultAnonymityRateLimitBackoff
+	}
+
+	c.echoBackoffLock.Lock()
+	c.echoBackoff[endpoint] = time.Now().Add(backoff)
+	c.echoBackoffLock.Unlock()
+
+	return backoff
+}
+
 // getRealIP gets our actual public IP address without using a proxy
 func getRealIP() (string, error) {
 	client := &http.Client{Timeout: 5 * time.Second}
@@ -398,10 +499,7 @@ func (c *Checker) applyRateLimit(host string, result *ProxyResult) {
 	}
 
 	// Determine the key for rate limiting
-	rateLimitKey := "global"
-	if c.config.RateLimitPerHost {
-		rateLimitKey = host
-	}
+	rateLimitKey := c.rateLimitKey(host, result)
 
 	// Calculate wait time while holding lock
 	c.rateLimiterLock.Lock()
@@ -455,7 +553,7 @@ func (c *Checker) applyProxyRateLimit(proxyURL string, result *ProxyResult) {
 	// Sleep outside the lock to prevent contention
 	if waitTime > 0 {
 		if c.debug {
-			result.DebugInfo += fmt.Sprintf("[DEBUG] Per-proxy rate limiting: waiting %v for proxy %s\n", waitTime, proxyURL)
+			result.DebugInfo += fmt.Sprintf("[DEBUG] Per-proxy rate limiting: waiting %v for proxy %s\n", waitTime, RedactProxyURL(proxyURL))
 		}
 		time.Sleep(waitTime)
 	}
@@ -466,6 +564,6 @@ func (c *Checker) applyProxyRateLimit(proxyURL string, result *ProxyResult) {
 	c.rateLimiterLock.Unlock()
 
 	if c.debug {
-		result.DebugInfo += fmt.Sprintf("[DEBUG] Per-proxy rate limiting applied for %s\n", proxyURL)
+		result.DebugInfo += fmt.Sprintf("[DEBUG] Per-proxy rate limiting applied for %s\n", RedactProxyURL(proxyURL))
 	}
 }
\ No newline at end of file
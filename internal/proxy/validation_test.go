@@ -3,6 +3,7 @@ package proxy
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -440,4 +441,68 @@ func TestRateLimitingTimePrecision(t *testing.T) {
 	if elapsed < expectedDelay-tolerance || elapsed > expectedDelay+tolerance*3 {
 		t.Errorf("Rate limiting precision issue: expected ~%v, got %v", expectedDelay, elapsed)
 	}
-}
\ No newline at end of file
+}
+
+// TestFetchEchoHeadersRateLimitFallback tests that a 429 from the primary echo
+// endpoint backs off and falls back to the next configured endpoint
+func TestFetchEchoHeadersRateLimitFallback(t *testing.T) {
+	primaryHits := 0
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryHits++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer primary.Close()
+
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"headers": {}}`))
+	}))
+	defer fallback.Close()
+
+	config := Config{
+		AnonymityCheckURL:         primary.URL,
+		AnonymityFallbackURLs:     []string{fallback.URL},
+		AnonymityRateLimitBackoff: time.Minute,
+	}
+	checker := NewChecker(config, false, nil)
+
+	for i := 0; i < 3; i++ {
+		result := &ProxyResult{}
+		body, err := checker.fetchEchoHeaders(http.DefaultClient, result)
+		if err != nil {
+			t.Fatalf("Expected fallback endpoint to succeed, got %v", err)
+		}
+		if string(body) != `{"headers": {}}` {
+			t.Errorf("Unexpected body from fallback endpoint: %s", body)
+		}
+		if result.AnonymityRateLimited {
+			t.Error("Expected a successful fallback not to mark the result as rate limited")
+		}
+	}
+
+	// The primary endpoint should be skipped while it is backing off
+	if primaryHits != 1 {
+		t.Errorf("Expected primary endpoint to be hit once, got %d", primaryHits)
+	}
+}
+
+// TestFetchEchoHeadersAllRateLimited tests that the result is marked as rate
+// limited when no echo endpoint answers
+func TestFetchEchoHeadersAllRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{
+		AnonymityCheckURL:         server.URL,
+		AnonymityRateLimitBackoff: 10 * time.Millisecond,
+	}, false, nil)
+
+	result := &ProxyResult{}
+	if _, err := checker.fetchEchoHeaders(http.DefaultClient, result); err == nil {
+		t.Fatal("Expected an error when every echo endpoint is rate limited")
+	}
+	if !result.AnonymityRateLimited {
+		t.Error("Expected result to be marked as rate limited")
+	}
+}
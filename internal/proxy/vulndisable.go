@@ -0,0 +1,103 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// VulnCheckNames lists the vendor and extended vuln checks that can be turned
+// off with AdvancedChecks.DisabledChecks. Each check is named after the JSON
+// field of its main finding in VendorVulnResult or ExtendedVulnResult.
+var VulnCheckNames = []string{
+	// Vendor checks
+	"haproxy_stats_exposed",
+	"haproxy_cve_2023_40225",
+	"haproxy_cve_2021_40346",
+	"haproxy_version_detected",
+	"squid_cache_manager_exposed",
+	"squid_cve_2021_46784",
+	"squid_cve_2020_15810",
+	"squid_version_detected",
+	"traefik_dashboard_exposed",
+	"traefik_api_exposed",
+	"traefik_cve_2024_45410",
+	"envoy_admin_exposed",
+	"envoy_cve_2022_21654",
+	"envoy_version_detected",
+	"caddy_admin_api_exposed",
+	"caddy_version_detected",
+	"varnish_ban_lurk_exposed",
+	"varnish_cve_2022_45060",
+	"varnish_version_detected",
+	"aws_alb_header_injection",
+	"cloudflare_worker_bypass",
+	"cloudflare_cache_poisoning",
+	"f5_icontrol_exposed",
+	"f5_tmui_exposed",
+	"f5_version_detected",
+	"nginx_plus_api_exposed",
+	"nginx_plus_dashboard",
+	"nginx_plus_version_detected",
+
+	// Extended checks
+	"nginx_version_detected",
+	"nginx_config_exposed",
+	"nginx_proxy_cache_bypass",
+	"nginx_subrequest_auth_bypass",
+	"websocket_abuse_vulnerable",
+	"http2_smuggling_vulnerable",
+	"proxy_auth_bypass",
+	"apache_server_status_exposed",
+	"cgi_script_exposed",
+	"apache_cve_2019_10092",
+	"apache_mod_rewrite_ssrf",
+	"apache_htaccess_override",
+}
+
+// vulnCheckKey normalizes a check name for matching. The _exposed, _detected
+// and _vulnerable suffixes are optional, so varnish_ban_lurk names the same
+// check as varnish_ban_lurk_exposed.
+func vulnCheckKey(name string) string {
+	key := strings.ToLower(strings.TrimSpace(name))
+	for _, suffix := range []string{"_exposed", "_detected", "_vulnerable"} {
+		key = strings.TrimSuffix(key, suffix)
+	}
+	return key
+}
+
+// IsVulnCheckName reports whether name is a check that DisabledChecks can turn off
+func IsVulnCheckName(name string) bool {
+	key := vulnCheckKey(name)
+	for _, known := range VulnCheckNames {
+		if vulnCheckKey(known) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// vulnCheckDisabled reports whether the named check is listed in
+// AdvancedChecks.DisabledChecks, noting the skip in the debug output
+func (c *Checker) vulnCheckDisabled(name string, result *ProxyResult) bool {
+	key := vulnCheckKey(name)
+	for _, disabled := range c.config.AdvancedChecks.DisabledChecks {
+		if vulnCheckKey(disabled) == key {
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[DISABLED] Skipping vuln check: %s\n", name)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// namedVulnCheck wraps check so that it does nothing when the named check is disabled
+func (c *Checker) namedVulnCheck(name string, check vulnCheck) vulnCheck {
+	return func(client *http.Client, result *ProxyResult) {
+		if c.vulnCheckDisabled(name, result) {
+			return
+		}
+		check(client, result)
+	}
+}
@@ -0,0 +1,66 @@
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIsVulnCheckName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"varnish_ban_lurk_exposed", true},
+		{"varnish_ban_lurk", true},
+		{"Apache_Mod_Rewrite_SSRF", true},
+		{"http2_smuggling", true},
+		{"varnish_ban", false},
+		{"no_such_check", false},
+	}
+	for _, tt := range tests {
+		if got := IsVulnCheckName(tt.name); got != tt.want {
+			t.Errorf("IsVulnCheckName(%q) = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDisabledVulnChecks(t *testing.T) {
+	// Stands in for the proxy; every vendor and extended check connects to it
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	proxyURL, _ := url.Parse(server.URL)
+	client := &http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)},
+		Timeout:   time.Second,
+	}
+
+	checker := NewChecker(Config{
+		Timeout:        time.Second,
+		AdvancedChecks: AdvancedChecks{DisabledChecks: VulnCheckNames},
+	}, true, nil)
+
+	result := &ProxyResult{Proxy: server.URL, ProxyURL: server.URL}
+	checker.performVendorVulnerabilityChecks(client, result)
+	checker.performExtendedVulnerabilityChecks(client, result)
+
+	if got := connections.Load(); got != 0 {
+		t.Errorf("Expected no connections with every check disabled, got %d", got)
+	}
+	if got := strings.Count(result.DebugInfo, "[DISABLED]"); got != len(VulnCheckNames) {
+		t.Errorf("Expected %d disabled checks in the debug output, got %d", len(VulnCheckNames), got)
+	}
+}
@@ -0,0 +1,22 @@
+package proxy
+
+import (
+	"fmt"
+	"time"
+)
+
+// acquireVulnScanSlot blocks until another proxy may enter the vuln scan phase
+// and returns a function that frees the slot. Without VulnScanConcurrency it
+// returns immediately, so vuln scans run at the main check concurrency.
+func (c *Checker) acquireVulnScanSlot(result *ProxyResult) func() {
+	if c.vulnScanSlots == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+	c.vulnScanSlots <- struct{}{}
+	if waited := time.Since(start); c.debug && waited > time.Millisecond {
+		result.DebugInfo += fmt.Sprintf("[VULN SCAN] Waited %v for a vuln scan slot\n", waited.Round(time.Millisecond))
+	}
+	return func() { <-c.vulnScanSlots }
+}
@@ -0,0 +1,46 @@
+package proxy
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAcquireVulnScanSlot(t *testing.T) {
+	checker := NewChecker(Config{VulnScanConcurrency: 2}, false, nil)
+
+	var running, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release := checker.acquireVulnScanSlot(&ProxyResult{})
+			defer release()
+
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}()
+	}
+	wg.Wait()
+
+	if peak != 2 {
+		t.Errorf("Expected at most 2 concurrent vuln scans, peak was %d", peak)
+	}
+}
+
+func TestAcquireVulnScanSlotUnlimited(t *testing.T) {
+	checker := NewChecker(Config{}, false, nil)
+	if checker.vulnScanSlots != nil {
+		t.Fatal("Expected no vuln scan limit without VulnScanConcurrency")
+	}
+	checker.acquireVulnScanSlot(&ProxyResult{})()
+}
@@ -0,0 +1,69 @@
+package proxy
+
+import (
+	"net/http"
+	"sync"
+)
+
+// vulnCheckWorkers bounds how many of one proxy's vuln checks run at once
+const vulnCheckWorkers = 5
+
+// vulnCheck is one independent vuln check. It stores its findings in fields of
+// the findings struct that no other check writes, and writes debug output and
+// skipped checks to result, which is its own copy of the proxy's result.
+type vulnCheck func(client *http.Client, result *ProxyResult)
+
+// runVulnChecks runs checks concurrently on at most vulnCheckWorkers
+// goroutines, all sharing client. Each check gets its own copy of result;
+// once every check has finished their debug output and skipped checks and
+// paths are merged back into result in the order of checks, so the debug log
+// reads the same as if the checks had run one after another.
+func (c *Checker) runVulnChecks(client *http.Client, result *ProxyResult, checks []vulnCheck) {
+	scratches := make([]*ProxyResult, len(checks))
+	for i := range checks {
+		scratch := *result
+		scratch.DebugInfo = ""
+		scratch.SkippedVulnChecks = append([]string(nil), result.SkippedVulnChecks...)
+		scratch.SkippedVulnPaths = append([]string(nil), result.SkippedVulnPaths...)
+		scratches[i] = &scratch
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, vulnCheckWorkers)
+	for i, check := range checks {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(check vulnCheck, scratch *ProxyResult) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			// Out-of-scope paths are noted on this check's copy
+			check(c.scopeVulnPaths(client, scratch), scratch)
+		}(check, scratches[i])
+	}
+	wg.Wait()
+
+	for _, scratch := range scratches {
+		result.DebugInfo += scratch.DebugInfo
+		result.SkippedVulnChecks = appendMissing(result.SkippedVulnChecks, scratch.SkippedVulnChecks)
+		result.SkippedVulnPaths = appendMissing(result.SkippedVulnPaths, scratch.SkippedVulnPaths)
+	}
+}
+
+// appendMissing appends the names in add that are not already in names
+func appendMissing(names, add []string) []string {
+	for _, name := range add {
+		found := false
+		for _, existing := range names {
+			if existing == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,64 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunVulnChecks(t *testing.T) {
+	checker := NewChecker(Config{ReadOnlyVulnChecks: true}, true, nil)
+	result := &ProxyResult{DebugInfo: "before\n", SkippedVulnChecks: []string{"earlier"}}
+
+	var running, maxRunning atomic.Int32
+	found := make([]bool, 12)
+	var checks []vulnCheck
+	for i := range found {
+		i := i
+		checks = append(checks, func(client *http.Client, result *ProxyResult) {
+			now := running.Add(1)
+			defer running.Add(-1)
+			for {
+				seen := maxRunning.Load()
+				if now <= seen || maxRunning.CompareAndSwap(seen, now) {
+					break
+				}
+			}
+
+			// Later checks finish first, so merging in completion order would reverse them
+			time.Sleep(time.Duration(len(found)-i) * 5 * time.Millisecond)
+			result.DebugInfo += fmt.Sprintf("check %d\n", i)
+			if i%2 == 0 {
+				checker.skipMutatingVulnCheck("shared", result)
+			}
+			found[i] = true
+		})
+	}
+
+	checker.runVulnChecks(http.DefaultClient, result, checks)
+
+	if got := maxRunning.Load(); got > vulnCheckWorkers || got < 2 {
+		t.Errorf("Expected between 2 and %d checks at once, got %d", vulnCheckWorkers, got)
+	}
+	for i, ok := range found {
+		if !ok {
+			t.Errorf("Check %d did not run", i)
+		}
+	}
+
+	want := "before\n"
+	for i := range found {
+		want += fmt.Sprintf("check %d\n", i)
+		if i%2 == 0 {
+			want += "[READ-ONLY] Skipping state-changing check: shared\n"
+		}
+	}
+	if result.DebugInfo != want {
+		t.Errorf("Expected debug output in check order:\n%s\ngot:\n%s", want, result.DebugInfo)
+	}
+	if len(result.SkippedVulnChecks) != 2 || result.SkippedVulnChecks[0] != "earlier" || result.SkippedVulnChecks[1] != "shared" {
+		t.Errorf("Expected skipped checks to be merged once, got %v", result.SkippedVulnChecks)
+	}
+}
@@ -292,6 +292,10 @@ func (c *Checker) testWebSocketAbuseVulnerabilities(client *http.Client, result
 
 // testHTTP2RequestSmuggling tests for HTTP/2 request smuggling vulnerabilities
 func (c *Checker) testHTTP2RequestSmuggling(client *http.Client, result *ProxyResult) (bool, []string) {
+	if c.skipMutatingVulnCheck(VulnCheckHTTP2Smuggling, result) {
+		return false, nil
+	}
+
 	if c.debug {
 		result.DebugInfo += "[HTTP/2 SMUGGLING] Testing for HTTP/2 request smuggling vulnerabilities\n"
 	}
@@ -904,31 +908,56 @@ func (c *Checker) testApacheHtaccessOverride(client *http.Client, result *ProxyR
 	return false
 }
 
-// performExtendedVulnerabilityChecks runs all extended/medium-priority vulnerability checks
+// performExtendedVulnerabilityChecks runs all extended/medium-priority
+// vulnerability checks except those disabled by name
 func (c *Checker) performExtendedVulnerabilityChecks(client *http.Client, result *ProxyResult) *ExtendedVulnResult {
 	extendedResult := &ExtendedVulnResult{}
 
 	// Nginx extended checks
-	extendedResult.NginxVersionDetected, extendedResult.NginxVersion = c.testNginxVersionDetection(client, result)
-	extendedResult.NginxConfigExposed, extendedResult.NginxConfigPaths = c.testNginxConfigExposure(client, result)
-	extendedResult.NginxProxyCacheBypass = c.testNginxProxyCacheBypass(client, result)
-	extendedResult.NginxSubrequestAuthBypass = c.testNginxSubrequestAuthBypass(client, result)
+	if !c.vulnCheckDisabled("nginx_version_detected", result) {
+		extendedResult.NginxVersionDetected, extendedResult.NginxVersion = c.testNginxVersionDetection(client, result)
+	}
+	if !c.vulnCheckDisabled("nginx_config_exposed", result) {
+		extendedResult.NginxConfigExposed, extendedResult.NginxConfigPaths = c.testNginxConfigExposure(client, result)
+	}
+	if !c.vulnCheckDisabled("nginx_proxy_cache_bypass", result) {
+		extendedResult.NginxProxyCacheBypass = c.testNginxProxyCacheBypass(client, result)
+	}
+	if !c.vulnCheckDisabled("nginx_subrequest_auth_bypass", result) {
+		extendedResult.NginxSubrequestAuthBypass = c.testNginxSubrequestAuthBypass(client, result)
+	}
 
 	// WebSocket checks
-	extendedResult.WebSocketAbuseVulnerable, extendedResult.WebSocketIssues = c.testWebSocketAbuseVulnerabilities(client, result)
+	if !c.vulnCheckDisabled("websocket_abuse_vulnerable", result) {
+		extendedResult.WebSocketAbuseVulnerable, extendedResult.WebSocketIssues = c.testWebSocketAbuseVulnerabilities(client, result)
+	}
 
 	// HTTP/2 checks
-	extendedResult.HTTP2SmugglingVulnerable, extendedResult.HTTP2SmugglingVectors = c.testHTTP2RequestSmuggling(client, result)
+	if !c.vulnCheckDisabled("http2_smuggling_vulnerable", result) {
+		extendedResult.HTTP2SmugglingVulnerable, extendedResult.HTTP2SmugglingVectors = c.testHTTP2RequestSmuggling(client, result)
+	}
 
 	// Authentication checks
-	extendedResult.ProxyAuthBypass, extendedResult.ProxyAuthBypassMethods = c.testProxyAuthenticationBypass(client, result)
+	if !c.vulnCheckDisabled("proxy_auth_bypass", result) {
+		extendedResult.ProxyAuthBypass, extendedResult.ProxyAuthBypassMethods = c.testProxyAuthenticationBypass(client, result)
+	}
 
 	// Apache extended checks
-	extendedResult.ApacheServerStatusExposed, extendedResult.ServerStatusPath = c.testApacheServerStatus(client, result)
-	extendedResult.CGIScriptExposed, extendedResult.CGIScriptPaths = c.testCGIScriptExposure(client, result)
-	extendedResult.ApacheCVE_2019_10092 = c.testApacheCVE_2019_10092(client, result)
-	extendedResult.ApacheModRewriteSSRF = c.testApacheModRewriteSSRF(client, result)
-	extendedResult.ApacheHtaccessOverride = c.testApacheHtaccessOverride(client, result)
+	if !c.vulnCheckDisabled("apache_server_status_exposed", result) {
+		extendedResult.ApacheServerStatusExposed, extendedResult.ServerStatusPath = c.testApacheServerStatus(client, result)
+	}
+	if !c.vulnCheckDisabled("cgi_script_exposed", result) {
+		extendedResult.CGIScriptExposed, extendedResult.CGIScriptPaths = c.testCGIScriptExposure(client, result)
+	}
+	if !c.vulnCheckDisabled("apache_cve_2019_10092", result) {
+		extendedResult.ApacheCVE_2019_10092 = c.testApacheCVE_2019_10092(client, result)
+	}
+	if !c.vulnCheckDisabled("apache_mod_rewrite_ssrf", result) {
+		extendedResult.ApacheModRewriteSSRF = c.testApacheModRewriteSSRF(client, result)
+	}
+	if !c.vulnCheckDisabled("apache_htaccess_override", result) {
+		extendedResult.ApacheHtaccessOverride = c.testApacheHtaccessOverride(client, result)
+	}
 
 	return extendedResult
 }
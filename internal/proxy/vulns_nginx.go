@@ -200,6 +200,10 @@ func (c *Checker) testK8sAPIExposure(client *http.Client, result *ProxyResult) (
 
 // testIngressWebhookCVE2025_1974 tests for CVE-2025-1974 nginx ingress admission controller RCE
 func (c *Checker) testIngressWebhookCVE2025_1974(client *http.Client, result *ProxyResult) bool {
+	if c.skipMutatingVulnCheck(VulnCheckIngressWebhook, result) {
+		return false
+	}
+
 	if c.debug {
 		result.DebugInfo += "[CVE-2025-1974] Testing for Nginx Ingress admission controller RCE\n"
 	}
@@ -4,6 +4,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -55,7 +56,8 @@ type AdvancedSSRFResult struct {
 	FragmentDetails     []string `json:"fragment_details,omitempty"`
 }
 
-// performAdvancedSSRFChecks runs all advanced SSRF vulnerability checks
+// performAdvancedSSRFChecks runs all advanced SSRF vulnerability checks. The
+// checks are independent, so they run concurrently (see runVulnChecks).
 func (c *Checker) performAdvancedSSRFChecks(client *http.Client, result *ProxyResult) *AdvancedSSRFResult {
 	advancedResult := &AdvancedSSRFResult{}
 
@@ -63,119 +65,139 @@ func (c *Checker) performAdvancedSSRFChecks(client *http.Client, result *ProxyRe
 		result.DebugInfo += "[ADVANCED SSRF] Starting advanced SSRF vulnerability checks\n"
 	}
 
-	// Test 1: URL Parser Differentials (Orange Tsai research)
-	vulnerable, patterns := c.testURLParserDifferentials(client, result)
-	if vulnerable {
-		advancedResult.ParserDifferentialVuln = true
-		advancedResult.ParserBypassPatterns = patterns
-	}
-
-	// Test 2: IP Obfuscation Bypass
-	vulnerable, formats := c.testIPObfuscation(client, result)
-	if vulnerable {
-		advancedResult.IPObfuscationBypass = true
-		advancedResult.BypassedIPFormats = formats
-	}
-
-	// Test 3: Redirect Chain SSRF
-	vulnerable, targets := c.testRedirectChainSSRF(client, result)
-	if vulnerable {
-		advancedResult.RedirectChainVuln = true
-		advancedResult.RedirectChainTargets = targets
-	}
-
-	// Test 4: Protocol Smuggling
-	vulnerable, schemes := c.testProtocolSmuggling(client, result)
-	if vulnerable {
-		advancedResult.ProtocolSmugglingVuln = true
-		advancedResult.ProtocolSchemes = schemes
-	}
-
-	// Test 5: Header Injection SSRF
-	vulnerable, headers := c.testHeaderInjectionSSRF(client, result)
-	if vulnerable {
-		advancedResult.HeaderInjectionSSRF = true
-		advancedResult.VulnerableHeaders = headers
-	}
-
-	// Test 6: Nginx proxy_pass Trailing Slash Traversal
-	vulnerable, paths := c.testProxyPassTraversal(client, result)
-	if vulnerable {
-		advancedResult.ProxyPassTraversalVuln = true
-		advancedResult.TraversalPaths = paths
-	}
-
-	// Test 7: Host Header SSRF
-	vulnerable, hostTargets := c.testHostHeaderSSRF(client, result)
-	if vulnerable {
-		advancedResult.HostHeaderSSRF = true
-		advancedResult.HostHeaderTargets = hostTargets
-	}
-
-	// Test 8: SNI Proxy SSRF (TLS SNI field manipulation)
-	vulnerable, sniTargets := c.testSNIProxySSRF(result)
-	if vulnerable {
-		advancedResult.SNIProxySSRF = true
-		advancedResult.SNITargets = sniTargets
-	}
-
-	// Test 9: DNS Rebinding with Interactsh
-	vulnerable, rebindDetails := c.testDNSRebinding(client, result)
-	if vulnerable {
-		advancedResult.DNSRebindingVuln = true
-		advancedResult.RebindingDetails = rebindDetails
-	}
-
-	// Test 10: HTTP/2 Header Injection (CRLF in binary headers)
-	vulnerable, injectedHeaders := c.testHTTP2HeaderInjection(result)
-	if vulnerable {
-		advancedResult.HTTP2HeaderInjection = true
-		advancedResult.InjectedHeaders = injectedHeaders
-	}
-
-	// Test 11: AWS IMDSv2 Token Workflow
-	vulnerable, imdsDetails := c.testIMDSv2Bypass(client, result)
-	if vulnerable {
-		advancedResult.IMDSv2Bypass = true
-		advancedResult.IMDSv2Details = imdsDetails
-	}
-
-	// Priority 3 Checks
-
-	// Test 12: URL Encoding Bypass
-	vulnerable, encodingDetails := c.testEncodingBypass(client, result)
-	if vulnerable {
-		advancedResult.EncodingBypass = true
-		advancedResult.EncodingDetails = encodingDetails
-	}
-
-	// Test 13: Multiple Host Headers
-	vulnerable, hostDetails := c.testMultipleHostHeaders(client, result)
-	if vulnerable {
-		advancedResult.MultipleHostHeaders = true
-		advancedResult.HostHeaderDetails = hostDetails
-	}
-
-	// Test 14: Cloud-Specific Headers
-	vulnerable, cloudDetails := c.testCloudHeaders(client, result)
-	if vulnerable {
-		advancedResult.CloudHeadersBypass = true
-		advancedResult.CloudHeaderDetails = cloudDetails
-	}
+	c.runVulnChecks(client, result, []vulnCheck{
+		// Test 1: URL Parser Differentials (Orange Tsai research)
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testURLParserDifferentials(client, result)
+			if vulnerable {
+				advancedResult.ParserDifferentialVuln = true
+				advancedResult.ParserBypassPatterns = details
+			}
+		},
+		// Test 2: IP Obfuscation Bypass
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testIPObfuscation(client, result)
+			if vulnerable {
+				advancedResult.IPObfuscationBypass = true
+				advancedResult.BypassedIPFormats = details
+			}
+		},
+		// Test 3: Redirect Chain SSRF
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testRedirectChainSSRF(client, result)
+			if vulnerable {
+				advancedResult.RedirectChainVuln = true
+				advancedResult.RedirectChainTargets = details
+			}
+		},
+		// Test 4: Protocol Smuggling
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testProtocolSmuggling(client, result)
+			if vulnerable {
+				advancedResult.ProtocolSmugglingVuln = true
+				advancedResult.ProtocolSchemes = details
+			}
+		},
+		// Test 5: Header Injection SSRF
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testHeaderInjectionSSRF(client, result)
+			if vulnerable {
+				advancedResult.HeaderInjectionSSRF = true
+				advancedResult.VulnerableHeaders = details
+			}
+		},
+		// Test 6: Nginx proxy_pass Trailing Slash Traversal
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testProxyPassTraversal(client, result)
+			if vulnerable {
+				advancedResult.ProxyPassTraversalVuln = true
+				advancedResult.TraversalPaths = details
+			}
+		},
+		// Test 7: Host Header SSRF
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testHostHeaderSSRF(client, result)
+			if vulnerable {
+				advancedResult.HostHeaderSSRF = true
+				advancedResult.HostHeaderTargets = details
+			}
+		},
+		// Test 8: SNI Proxy SSRF (TLS SNI field manipulation)
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testSNIProxySSRF(result)
+			if vulnerable {
+				advancedResult.SNIProxySSRF = true
+				advancedResult.SNITargets = details
+			}
+		},
+		// Test 9: DNS Rebinding with Interactsh
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testDNSRebinding(client, result)
+			if vulnerable {
+				advancedResult.DNSRebindingVuln = true
+				advancedResult.RebindingDetails = details
+			}
+		},
+		// Test 10: HTTP/2 Header Injection (CRLF in binary headers)
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testHTTP2HeaderInjection(result)
+			if vulnerable {
+				advancedResult.HTTP2HeaderInjection = true
+				advancedResult.InjectedHeaders = details
+			}
+		},
+		// Test 11: AWS IMDSv2 Token Workflow
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testIMDSv2Bypass(client, result)
+			advancedResult.IMDSv2Bypass = vulnerable
+			if len(details) > 0 {
+				advancedResult.IMDSv2Details = details
+			}
+		},
 
-	// Test 15: Port Specification Tricks
-	vulnerable, portDetails := c.testPortTricks(client, result)
-	if vulnerable {
-		advancedResult.PortTricks = true
-		advancedResult.PortTrickDetails = portDetails
-	}
+		// Priority 3 checks
 
-	// Test 16: Fragment/Query Manipulation
-	vulnerable, fragmentDetails := c.testFragmentQuery(client, result)
-	if vulnerable {
-		advancedResult.FragmentQuery = true
-		advancedResult.FragmentDetails = fragmentDetails
-	}
+		// Test 12: URL Encoding Bypass
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testEncodingBypass(client, result)
+			if vulnerable {
+				advancedResult.EncodingBypass = true
+				advancedResult.EncodingDetails = details
+			}
+		},
+		// Test 13: Multiple Host Headers
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testMultipleHostHeaders(client, result)
+			if vulnerable {
+				advancedResult.MultipleHostHeaders = true
+				advancedResult.HostHeaderDetails = details
+			}
+		},
+		// Test 14: Cloud-Specific Headers
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testCloudHeaders(client, result)
+			if vulnerable {
+				advancedResult.CloudHeadersBypass = true
+				advancedResult.CloudHeaderDetails = details
+			}
+		},
+		// Test 15: Port Specification Tricks
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testPortTricks(client, result)
+			if vulnerable {
+				advancedResult.PortTricks = true
+				advancedResult.PortTrickDetails = details
+			}
+		},
+		// Test 16: Fragment/Query Manipulation
+		func(client *http.Client, result *ProxyResult) {
+			vulnerable, details := c.testFragmentQuery(client, result)
+			if vulnerable {
+				advancedResult.FragmentQuery = true
+				advancedResult.FragmentDetails = details
+			}
+		},
+	})
 
 	if c.debug {
 		result.DebugInfo += "[ADVANCED SSRF] Complete\n"
@@ -443,8 +465,39 @@ func (c *Checker) testIPObfuscation(client *http.Client, result *ProxyResult) (b
 	return vulnerable, bypassedFormats
 }
 
+// DefaultRedirectChainHosts are the httpbin-compatible redirect helpers used
+// by the redirect chain SSRF test when AdvancedChecks.RedirectChainHosts is empty
+var DefaultRedirectChainHosts = []string{"httpbin.org"}
+
+// DefaultRedirectChainMaxRedirects is how many redirects the redirect chain
+// SSRF test follows when AdvancedChecks.RedirectChainMaxRedirects is 0
+const DefaultRedirectChainMaxRedirects = 10
+
+// redirectChainHosts returns the redirect helper hosts for the redirect chain test
+func (c *Checker) redirectChainHosts() []string {
+	if len(c.config.AdvancedChecks.RedirectChainHosts) > 0 {
+		return c.config.AdvancedChecks.RedirectChainHosts
+	}
+	return DefaultRedirectChainHosts
+}
+
+// redirectChainMaxRedirects returns how many redirects the redirect chain test follows
+func (c *Checker) redirectChainMaxRedirects() int {
+	if c.config.AdvancedChecks.RedirectChainMaxRedirects > 0 {
+		return c.config.AdvancedChecks.RedirectChainMaxRedirects
+	}
+	return DefaultRedirectChainMaxRedirects
+}
+
 // testRedirectChainSSRF tests for SSRF via redirect chains
 func (c *Checker) testRedirectChainSSRF(client *http.Client, result *ProxyResult) (bool, []string) {
+	if c.config.AdvancedChecks.DisableRedirectChain {
+		if c.debug {
+			result.DebugInfo += "[REDIRECT CHAIN] Disabled by advanced_checks.disable_redirect_chain\n"
+		}
+		return false, nil
+	}
+
 	if c.debug {
 		result.DebugInfo += "[REDIRECT CHAIN] Testing SSRF via redirect chains\n"
 	}
@@ -453,41 +506,44 @@ func (c *Checker) testRedirectChainSSRF(client *http.Client, result *ProxyResult
 	targets := []string{}
 
 	// Create a client that FOLLOWS redirects (opposite of our normal behavior)
+	maxRedirects := c.redirectChainMaxRedirects()
 	redirectClient := &http.Client{
-		Timeout: c.config.Timeout,
+		Timeout:   c.timeout(result),
 		Transport: client.Transport,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			// Follow up to 10 redirects
-			if len(via) >= 10 {
-				return fmt.Errorf("stopped after 10 redirects")
+			if len(via) > maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
 			}
 			return nil
 		},
 	}
 
-	// Test cases for redirect-based SSRF
+	// Test cases for redirect-based SSRF, one per internal target and helper host
 	// In a real implementation, you'd use Interactsh to create redirect endpoints
 	// For now, we test if the proxy follows redirects at all
-	testCases := []struct {
+	type redirectTestCase struct {
 		redirectURL string
 		target      string
 		description string
+	}
+	internalTargets := []struct {
+		url         string
+		target      string
+		description string
 	}{
-		{
-			redirectURL: "http://httpbin.org/redirect-to?url=http://169.254.169.254/latest/meta-data/",
-			target:      "169.254.169.254",
-			description: "Redirect to AWS metadata",
-		},
-		{
-			redirectURL: "http://httpbin.org/redirect-to?url=http://metadata.google.internal/",
-			target:      "metadata.google.internal",
-			description: "Redirect to GCP metadata",
-		},
-		{
-			redirectURL: "http://httpbin.org/redirect-to?url=http://localhost:6379/",
-			target:      "localhost:6379",
-			description: "Redirect to Redis",
-		},
+		{"http://169.254.169.254/latest/meta-data/", "169.254.169.254", "Redirect to AWS metadata"},
+		{"http://metadata.google.internal/", "metadata.google.internal", "Redirect to GCP metadata"},
+		{"http://localhost:6379/", "localhost:6379", "Redirect to Redis"},
+	}
+	var testCases []redirectTestCase
+	for _, host := range c.redirectChainHosts() {
+		for _, internal := range internalTargets {
+			testCases = append(testCases, redirectTestCase{
+				redirectURL: fmt.Sprintf("http://%s/redirect-to?url=%s", host, internal.url),
+				target:      internal.target,
+				description: internal.description,
+			})
+		}
 	}
 
 	for _, tc := range testCases {
@@ -682,6 +738,53 @@ func (c *Checker) testProtocolSmuggling(client *http.Client, result *ProxyResult
 	return vulnerable, schemes
 }
 
+// headerSSRFTarget is an internal service probed by the header injection SSRF
+// test, with the request headers it requires and the response markers that
+// show it was reached
+type headerSSRFTarget struct {
+	cloud      string            // Cloud whose metadata service this is ("" for generic local targets)
+	host       string            // Host placed in the injected header
+	path       string            // Metadata path used by the URL-rewriting headers
+	headers    map[string]string // Headers the service requires (e.g. Metadata: true for Azure)
+	indicators []string          // Body markers that identify the service's response
+}
+
+// headerSSRFTargets are the internal services probed via header injection.
+// Azure and GCP refuse metadata requests without their Metadata headers, so
+// each cloud is probed with its own header set and indicators.
+var headerSSRFTargets = []headerSSRFTarget{
+	{
+		cloud:      "AWS",
+		host:       "169.254.169.254",
+		path:       "/latest/meta-data/",
+		indicators: []string{"ami-id", "instance-id"},
+	},
+	{
+		cloud:      "Azure",
+		host:       "169.254.169.254",
+		path:       "/metadata/instance?api-version=2021-02-01",
+		headers:    map[string]string{"Metadata": "true"},
+		indicators: []string{`"compute"`, `"vmId"`, `"subscriptionId"`},
+	},
+	{
+		cloud:      "GCP",
+		host:       "metadata.google.internal",
+		path:       "/computeMetadata/v1/",
+		headers:    map[string]string{"Metadata-Flavor": "Google"},
+		indicators: []string{"computeMetadata", "project-id", "service-accounts/"},
+	},
+	{
+		host:       "localhost",
+		path:       "/latest/meta-data/",
+		indicators: []string{"ami-id", "instance-id", "computeMetadata"},
+	},
+	{
+		host:       "127.0.0.1",
+		path:       "/latest/meta-data/",
+		indicators: []string{"ami-id", "instance-id", "computeMetadata"},
+	},
+}
+
 // testHeaderInjectionSSRF tests for SSRF via header injection
 func (c *Checker) testHeaderInjectionSSRF(client *http.Client, result *ProxyResult) (bool, []string) {
 	if c.debug {
@@ -691,13 +794,6 @@ func (c *Checker) testHeaderInjectionSSRF(client *http.Client, result *ProxyResu
 	vulnerable := false
 	vulnerableHeaders := []string{}
 
-	internalTargets := []string{
-		"169.254.169.254",
-		"metadata.google.internal",
-		"localhost",
-		"127.0.0.1",
-	}
-
 	// Headers that might influence backend routing
 	testHeaders := map[string]string{
 		"X-Forwarded-Host":     "",
@@ -713,7 +809,7 @@ func (c *Checker) testHeaderInjectionSSRF(client *http.Client, result *ProxyResu
 	}
 
 	for headerName := range testHeaders {
-		for _, target := range internalTargets {
+		for _, target := range headerSSRFTargets {
 			req, err := http.NewRequest("GET", c.config.ValidationURL, nil)
 			if err != nil {
 				continue
@@ -722,11 +818,16 @@ func (c *Checker) testHeaderInjectionSSRF(client *http.Client, result *ProxyResu
 			// Set the header to internal target
 			switch headerName {
 			case "Forwarded":
-				req.Header.Set(headerName, fmt.Sprintf("for=%s;host=%s;proto=http", target, target))
+				req.Header.Set(headerName, fmt.Sprintf("for=%s;host=%s;proto=http", target.host, target.host))
 			case "X-Original-URL", "X-Rewrite-URL":
-				req.Header.Set(headerName, fmt.Sprintf("http://%s/latest/meta-data/", target))
+				req.Header.Set(headerName, fmt.Sprintf("http://%s%s", target.host, target.path))
 			default:
-				req.Header.Set(headerName, target)
+				req.Header.Set(headerName, target.host)
+			}
+
+			// Cloud metadata services reject requests without their own headers
+			for name, value := range target.headers {
+				req.Header.Set(name, value)
 			}
 
 			req.Header.Set("User-Agent", c.config.UserAgent)
@@ -742,16 +843,16 @@ func (c *Checker) testHeaderInjectionSSRF(client *http.Client, result *ProxyResu
 			bodyStr := string(body)
 
 			// Check if backend responded with internal service data
-			if resp.StatusCode == 200 && (strings.Contains(bodyStr, "ami-id") ||
-				strings.Contains(bodyStr, "instance-id") ||
-				strings.Contains(bodyStr, "metadata") ||
-				strings.Contains(bodyStr, "computeMetadata")) {
-
+			if resp.StatusCode == 200 && containsAny(bodyStr, target.indicators) {
 				vulnerable = true
-				vulnerableHeaders = append(vulnerableHeaders, fmt.Sprintf("%s → %s", headerName, target))
+				finding := fmt.Sprintf("%s → %s", headerName, target.host)
+				if target.cloud != "" {
+					finding += fmt.Sprintf(" (%s metadata)", target.cloud)
+				}
+				vulnerableHeaders = append(vulnerableHeaders, finding)
 
 				if c.debug {
-					result.DebugInfo += fmt.Sprintf("  [VULN] Header injection SSRF: %s targets %s\n", headerName, target)
+					result.DebugInfo += fmt.Sprintf("  [VULN] Header injection SSRF: %s\n", finding)
 				}
 
 				break // Found vulnerability with this header
@@ -762,6 +863,16 @@ func (c *Checker) testHeaderInjectionSSRF(client *http.Client, result *ProxyResu
 	return vulnerable, vulnerableHeaders
 }
 
+// containsAny reports whether s contains any of the substrings
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
 // testProxyPassTraversal tests for Nginx proxy_pass trailing slash path traversal
 func (c *Checker) testProxyPassTraversal(client *http.Client, result *ProxyResult) (bool, []string) {
 	if c.debug {
@@ -947,7 +1058,7 @@ func (c *Checker) testSNIProxySSRF(result *ProxyResult) (bool, []string) {
 		}
 
 		// Create custom dialer
-		conn, err := tls.Dial("tcp", fmt.Sprintf("%s:%s", host, port), tlsConfig)
+		conn, err := c.dialTLS(net.JoinHostPort(host, port), tlsConfig)
 		if err != nil {
 			// Connection failed - this might actually indicate the proxy tried to connect to internal target
 			if strings.Contains(err.Error(), "connection refused") ||
@@ -191,12 +191,17 @@ func (c *Checker) testHTTP2HeaderInjection(result *ProxyResult) (bool, []string)
 					InsecureSkipVerify: true,
 				},
 			}
+			if c.config.BaseDialer != nil {
+				transport.DialTLSContext = func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+					return c.dialTLSContext(ctx, network, addr, cfg)
+				}
+			}
 		} else {
 			// HTTP/2 cleartext (h2c)
 			transport = &http2.Transport{
 				AllowHTTP: true,
 				DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
-					return net.Dial(network, addr)
+					return c.dialContext(context.Background(), network, addr)
 				},
 			}
 		}
@@ -267,176 +272,132 @@ func (c *Checker) testHTTP2HeaderInjection(result *ProxyResult) (bool, []string)
 	return vulnerable, injectedHeaders
 }
 
-// testIMDSv2Bypass tests for AWS IMDSv2 token workflow bypass vulnerabilities
-// IMDSv2 requires: PUT request to get session token, then use token in X-aws-ec2-metadata-token header
-// Tests if proxy properly handles this two-step workflow or if it can be bypassed
+// IMDSv2 endpoints on the AWS instance metadata service
+const (
+	imdsv2TokenURL       = "http://169.254.169.254/latest/api/token"
+	imdsv2CredentialsURL = "http://169.254.169.254/latest/meta-data/iam/security-credentials/"
+)
+
+// testIMDSv2Bypass tests whether the proxy lets a client complete the AWS IMDSv2
+// token workflow: a PUT to /latest/api/token for a session token, then a GET of
+// the IAM security-credentials listing with that token. The proxy is only
+// reported vulnerable when both steps succeed and an IAM role is returned; the
+// details record each step that leaked. Role credentials themselves are not fetched.
 func (c *Checker) testIMDSv2Bypass(client *http.Client, result *ProxyResult) (bool, []string) {
+	if c.skipMutatingVulnCheck(VulnCheckIMDSv2TokenWorkflow, result) {
+		return false, nil
+	}
+
 	if c.debug {
 		result.DebugInfo += "[IMDSv2 BYPASS] Testing AWS IMDSv2 token workflow\n"
 	}
 
-	vulnerable := false
 	imdsDetails := []string{}
 
-	// IMDSv2 endpoint
-	imdsv2Endpoint := "http://169.254.169.254/latest/api/token"
-	metadataEndpoint := "http://169.254.169.254/latest/meta-data/"
-
-	// Step 1: Try to get IMDSv2 session token
-	// PUT /latest/api/token with X-aws-ec2-metadata-token-ttl-seconds header
+	// Step 1: PUT /latest/api/token with the required TTL header
 	if c.debug {
 		result.DebugInfo += "  Step 1: Requesting IMDSv2 session token\n"
 	}
 
-	tokenReq, err := http.NewRequest("PUT", imdsv2Endpoint, nil)
+	tokenReq, err := http.NewRequest("PUT", imdsv2TokenURL, nil)
 	if err != nil {
 		return false, nil
 	}
-
-	// IMDSv2 requires this header to specify token TTL
 	tokenReq.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	tokenReq = tokenReq.WithContext(ctx)
-
-	tokenResp, err := client.Do(tokenReq)
-	var sessionToken string
-
-	if err == nil {
-		defer tokenResp.Body.Close()
-		if tokenResp.StatusCode == 200 {
-			tokenBytes, _ := io.ReadAll(tokenResp.Body)
-			sessionToken = string(tokenBytes)
-
-			if c.debug {
-				result.DebugInfo += fmt.Sprintf("  [INFO] Obtained IMDSv2 token (length: %d)\n", len(sessionToken))
-			}
+	status, tokenBody, err := c.doIMDSRequest(client, tokenReq)
+	if err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("  Token request failed: %v\n", err)
 		}
+		return false, nil
 	}
-
-	// Step 2: Try to access metadata using the token
-	if sessionToken != "" {
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
 		if c.debug {
-			result.DebugInfo += "  Step 2: Accessing metadata with IMDSv2 token\n"
+			result.DebugInfo += fmt.Sprintf("  Token request refused with %d, IMDSv2 not reachable\n", status)
 		}
+		return false, nil
+	}
 
-		metadataReq, err := http.NewRequest("GET", metadataEndpoint, nil)
-		if err == nil {
-			// Add the session token header required by IMDSv2
-			metadataReq.Header.Set("X-aws-ec2-metadata-token", sessionToken)
-
-			ctx2, cancel2 := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel2()
-			metadataReq = metadataReq.WithContext(ctx2)
-
-			metadataResp, err := client.Do(metadataReq)
-			if err == nil {
-				defer metadataResp.Body.Close()
-				body, _ := io.ReadAll(metadataResp.Body)
-				bodyStr := string(body)
-
-				if metadataResp.StatusCode == 200 && (strings.Contains(bodyStr, "ami-id") ||
-					strings.Contains(bodyStr, "instance-id") ||
-					strings.Contains(bodyStr, "security-credentials")) {
-
-					vulnerable = true
-					imdsDetails = append(imdsDetails, "IMDSv2 token workflow bypass - full access to metadata service")
-
-					if c.debug {
-						result.DebugInfo += "  [VULN] IMDSv2 bypass successful - accessed metadata with token\n"
-					}
-				}
-			}
+	sessionToken := strings.TrimSpace(string(tokenBody))
+	if status != http.StatusOK || sessionToken == "" || strings.ContainsAny(sessionToken, " \r\n<>") {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("  No session token returned (status %d)\n", status)
 		}
+		return false, nil
 	}
 
-	// Step 3: Try IMDSv1 fallback (should be blocked if IMDSv2 is enforced)
+	imdsDetails = append(imdsDetails, "Step 1: IMDSv2 session token issued through proxy")
 	if c.debug {
-		result.DebugInfo += "  Step 3: Testing IMDSv1 fallback (should be blocked)\n"
-	}
-
-	fallbackReq, err := http.NewRequest("GET", metadataEndpoint, nil)
-	if err == nil {
-		ctx3, cancel3 := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel3()
-		fallbackReq = fallbackReq.WithContext(ctx3)
-
-		fallbackResp, err := client.Do(fallbackReq)
-		if err == nil {
-			defer fallbackResp.Body.Close()
-			body, _ := io.ReadAll(fallbackResp.Body)
-			bodyStr := string(body)
-
-			// If IMDSv1 (no token) works, it's a vulnerability
-			if fallbackResp.StatusCode == 200 && (strings.Contains(bodyStr, "ami-id") ||
-				strings.Contains(bodyStr, "instance-id")) {
-
-				vulnerable = true
-				imdsDetails = append(imdsDetails, "IMDSv1 fallback allowed - IMDSv2 enforcement bypassed")
-
-				if c.debug {
-					result.DebugInfo += "  [VULN] IMDSv1 fallback works - IMDSv2 not enforced\n"
-				}
-			}
-		}
+		result.DebugInfo += fmt.Sprintf("  [INFO] Obtained IMDSv2 token (length: %d)\n", len(sessionToken))
 	}
 
-	// Step 4: Test token manipulation attempts
+	// Step 2: GET the IAM security-credentials listing with the session token
 	if c.debug {
-		result.DebugInfo += "  Step 4: Testing token manipulation\n"
+		result.DebugInfo += "  Step 2: Listing IAM security credentials with IMDSv2 token\n"
 	}
 
-	manipulationTests := []struct {
-		description string
-		token       string
-	}{
-		{
-			description: "Empty token",
-			token:       "",
-		},
-		{
-			description: "Invalid token",
-			token:       "AAAAAAAAAAAAAAAAAAAA",
-		},
-		{
-			description: "Token with CRLF injection",
-			token:       "token\r\nX-Forwarded-For: 169.254.169.254",
-		},
+	credsReq, err := http.NewRequest("GET", imdsv2CredentialsURL, nil)
+	if err != nil {
+		return false, imdsDetails
 	}
+	credsReq.Header.Set("X-aws-ec2-metadata-token", sessionToken)
 
-	for _, test := range manipulationTests {
-		manipReq, err := http.NewRequest("GET", metadataEndpoint, nil)
-		if err != nil {
-			continue
+	status, credsBody, err := c.doIMDSRequest(client, credsReq)
+	if err != nil || status != http.StatusOK {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("  Credentials request did not succeed (status %d, error %v)\n", status, err)
 		}
+		return false, imdsDetails
+	}
 
-		if test.token != "" {
-			manipReq.Header.Set("X-aws-ec2-metadata-token", test.token)
+	role := firstIMDSRole(string(credsBody))
+	if role == "" {
+		if c.debug {
+			result.DebugInfo += "  Credentials listing returned no IAM role\n"
 		}
+		return false, imdsDetails
+	}
 
-		ctx4, cancel4 := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel4()
-		manipReq = manipReq.WithContext(ctx4)
+	imdsDetails = append(imdsDetails, fmt.Sprintf("Step 2: IAM security credentials exposed for role %q", role))
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("  [VULN] IMDSv2 workflow completed - IAM role %q exposed\n", role)
+	}
 
-		manipResp, err := client.Do(manipReq)
-		if err == nil {
-			defer manipResp.Body.Close()
-			body, _ := io.ReadAll(manipResp.Body)
-			bodyStr := string(body)
+	return true, imdsDetails
+}
 
-			if manipResp.StatusCode == 200 && (strings.Contains(bodyStr, "ami-id") ||
-				strings.Contains(bodyStr, "instance-id")) {
+// doIMDSRequest sends an IMDS request with a short timeout and returns the
+// status and body. The response body is always closed.
+func (c *Checker) doIMDSRequest(client *http.Client, req *http.Request) (int, []byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-				vulnerable = true
-				imdsDetails = append(imdsDetails, fmt.Sprintf("Token manipulation bypass: %s", test.description))
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
 
-				if c.debug {
-					result.DebugInfo += fmt.Sprintf("  [VULN] Token manipulation successful: %s\n", test.description)
-				}
-			}
-		}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return resp.StatusCode, nil, err
 	}
+	return resp.StatusCode, body, nil
+}
 
-	return vulnerable, imdsDetails
+// firstIMDSRole returns the first IAM role name in a security-credentials
+// listing, or "" if the body does not look like one (e.g. an HTML error page)
+func firstIMDSRole(body string) string {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.ContainsAny(line, " <>{}\"") {
+			return ""
+		}
+		return line
+	}
+	return ""
 }
@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// imdsTransport fakes the AWS metadata service behind a proxy
+type imdsTransport struct {
+	tokenStatus int
+	credsBody   string
+	gotToken    string
+}
+
+func (t *imdsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	respond := func(status int, body string) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     make(http.Header),
+			Request:    req,
+		}, nil
+	}
+
+	switch req.URL.String() {
+	case imdsv2TokenURL:
+		if req.Method != http.MethodPut || req.Header.Get("X-aws-ec2-metadata-token-ttl-seconds") != "21600" {
+			return respond(http.StatusBadRequest, "")
+		}
+		if t.tokenStatus != http.StatusOK {
+			// Refusals may come back without a usable body
+			return &http.Response{StatusCode: t.tokenStatus, Body: http.NoBody, Header: make(http.Header), Request: req}, nil
+		}
+		return respond(http.StatusOK, "AQAEAFakeSessionToken==")
+	case imdsv2CredentialsURL:
+		t.gotToken = req.Header.Get("X-aws-ec2-metadata-token")
+		if t.gotToken == "" {
+			return respond(http.StatusUnauthorized, "")
+		}
+		return respond(http.StatusOK, t.credsBody)
+	}
+	return respond(http.StatusNotFound, "")
+}
+
+// TestIMDSv2Bypass tests the two-step IMDSv2 token workflow
+func TestIMDSv2Bypass(t *testing.T) {
+	tests := []struct {
+		name        string
+		tokenStatus int
+		credsBody   string
+		vulnerable  bool
+		details     int
+	}{
+		{"full workflow leaks role", http.StatusOK, "ec2-admin-role\n", true, 2},
+		{"token issued but no role", http.StatusOK, "", false, 1},
+		{"html error page is not a role", http.StatusOK, "<html>denied</html>", false, 1},
+		{"token refused with 401", http.StatusUnauthorized, "", false, 0},
+		{"token refused with 403", http.StatusForbidden, "", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &imdsTransport{tokenStatus: tt.tokenStatus, credsBody: tt.credsBody}
+			client := &http.Client{Transport: transport}
+			checker := NewChecker(Config{}, true, nil)
+
+			vulnerable, details := checker.testIMDSv2Bypass(client, &ProxyResult{})
+			if vulnerable != tt.vulnerable {
+				t.Errorf("Expected vulnerable=%t, got %t", tt.vulnerable, vulnerable)
+			}
+			if len(details) != tt.details {
+				t.Errorf("Expected %d details, got %v", tt.details, details)
+			}
+			if tt.tokenStatus == http.StatusOK && transport.gotToken != "AQAEAFakeSessionToken==" {
+				t.Errorf("Expected the session token on the credentials request, got %q", transport.gotToken)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// metadataBackendTransport fakes a proxy whose backend follows X-Forwarded-Host
+// to a cloud metadata service, which only answers with its required header
+type metadataBackendTransport struct {
+	cloud string
+}
+
+func (t *metadataBackendTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := "<html>ok</html>"
+	if req.Header.Get("X-Forwarded-Host") != "" {
+		switch {
+		case t.cloud == "Azure" && req.Header.Get("Metadata") == "true":
+			body = `{"compute":{"vmId":"abc","subscriptionId":"123"}}`
+		case t.cloud == "GCP" && req.Header.Get("Metadata-Flavor") == "Google":
+			body = "attributes/\nproject-id\nservice-accounts/\n"
+		}
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+		Request:    req,
+	}, nil
+}
+
+// TestHeaderInjectionSSRFCloudMetadata tests that Azure and GCP metadata are
+// only reached when probed with their required headers and are tagged by cloud
+func TestHeaderInjectionSSRFCloudMetadata(t *testing.T) {
+	for _, cloud := range []string{"Azure", "GCP"} {
+		t.Run(cloud, func(t *testing.T) {
+			client := &http.Client{Transport: &metadataBackendTransport{cloud: cloud}}
+			checker := NewChecker(Config{ValidationURL: "http://example.com/"}, false, nil)
+
+			vulnerable, headers := checker.testHeaderInjectionSSRF(client, &ProxyResult{})
+			if !vulnerable {
+				t.Fatalf("Expected %s metadata to be detected", cloud)
+			}
+
+			found := false
+			for _, header := range headers {
+				if strings.HasPrefix(header, "X-Forwarded-Host") && strings.Contains(header, cloud+" metadata") {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected X-Forwarded-Host finding tagged with %s, got %v", cloud, headers)
+			}
+		})
+	}
+}
+
+// redirectLoopTransport fakes a proxy that answers every request with a
+// redirect to another page and records the URLs it was asked for
+type redirectLoopTransport struct {
+	urls []string
+}
+
+func (t *redirectLoopTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.urls = append(t.urls, req.URL.String())
+	header := make(http.Header)
+	header.Set("Location", fmt.Sprintf("http://example.com/hop%d", len(t.urls)))
+	return &http.Response{
+		StatusCode: http.StatusFound,
+		Body:       io.NopCloser(strings.NewReader("")),
+		Header:     header,
+		Request:    req,
+	}, nil
+}
+
+// TestRedirectChainSSRFSettings tests the redirect cap, the helper hosts and
+// disabling the redirect chain test
+func TestRedirectChainSSRFSettings(t *testing.T) {
+	transport := &redirectLoopTransport{}
+	checker := NewChecker(Config{
+		ValidationURL: "http://example.com/",
+		AdvancedChecks: AdvancedChecks{
+			RedirectChainHosts:        []string{"redirect.lab.internal:8080"},
+			RedirectChainMaxRedirects: 2,
+		},
+	}, false, nil)
+
+	checker.testRedirectChainSSRF(&http.Client{Transport: transport}, &ProxyResult{})
+
+	// Three internal targets, each followed through two redirects
+	if len(transport.urls) != 9 {
+		t.Fatalf("Expected 9 requests with a cap of 2 redirects, got %d: %v", len(transport.urls), transport.urls)
+	}
+	for _, requested := range transport.urls {
+		if strings.Contains(requested, "httpbin.org") {
+			t.Errorf("Expected only the configured helper host, got %s", requested)
+		}
+	}
+	if !strings.Contains(transport.urls[0], url.QueryEscape("http://redirect.lab.internal:8080/redirect-to")) {
+		t.Errorf("Expected the configured helper host in %s", transport.urls[0])
+	}
+
+	transport = &redirectLoopTransport{}
+	checker.config.AdvancedChecks.DisableRedirectChain = true
+	vulnerable, _ := checker.testRedirectChainSSRF(&http.Client{Transport: transport}, &ProxyResult{})
+	if vulnerable || len(transport.urls) != 0 {
+		t.Errorf("Expected the disabled test to send nothing, got %d requests", len(transport.urls))
+	}
+}
@@ -127,6 +127,10 @@ func (c *Checker) testHAProxyStatsExposure(client *http.Client, result *ProxyRes
 
 // testHAProxyCVE_2023_40225 tests for HAProxy request smuggling via content-length
 func (c *Checker) testHAProxyCVE_2023_40225(client *http.Client, result *ProxyResult) bool {
+	if c.skipMutatingVulnCheck(VulnCheckHAProxySmuggling, result) {
+		return false
+	}
+
 	if c.debug {
 		result.DebugInfo += "[HAPROXY CVE-2023-40225] Testing for request smuggling vulnerability\n"
 	}
@@ -337,6 +341,10 @@ func (c *Checker) testSquidCVE_2021_46784(client *http.Client, result *ProxyResu
 
 // testSquidCVE_2020_15810 tests for Squid HTTP request smuggling
 func (c *Checker) testSquidCVE_2020_15810(client *http.Client, result *ProxyResult) bool {
+	if c.skipMutatingVulnCheck(VulnCheckSquidSmuggling, result) {
+		return false
+	}
+
 	if c.debug {
 		result.DebugInfo += "[SQUID CVE-2020-15810] Testing for HTTP request smuggling\n"
 	}
@@ -602,6 +610,10 @@ func (c *Checker) testEnvoyAdmin(client *http.Client, result *ProxyResult) (bool
 
 // testEnvoyCVE_2022_21654 tests for Envoy SSRF in original_dst cluster
 func (c *Checker) testEnvoyCVE_2022_21654(client *http.Client, result *ProxyResult) bool {
+	if c.skipMutatingVulnCheck(VulnCheckEnvoyOriginalDst, result) {
+		return false
+	}
+
 	if c.debug {
 		result.DebugInfo += "[ENVOY CVE-2022-21654] Testing for SSRF in original_dst cluster\n"
 	}
@@ -756,6 +768,10 @@ func (c *Checker) testCaddyVersionDetection(client *http.Client, result *ProxyRe
 
 // testVarnishBanLurk tests for exposed Varnish ban lurker
 func (c *Checker) testVarnishBanLurk(client *http.Client, result *ProxyResult) bool {
+	if c.skipMutatingVulnCheck(VulnCheckVarnishBan, result) {
+		return false
+	}
+
 	if c.debug {
 		result.DebugInfo += "[VARNISH BAN] Testing for Varnish ban lurker exposure\n"
 	}
@@ -786,6 +802,10 @@ func (c *Checker) testVarnishBanLurk(client *http.Client, result *ProxyResult) b
 
 // testVarnishCVE_2022_45060 tests for Varnish request smuggling
 func (c *Checker) testVarnishCVE_2022_45060(client *http.Client, result *ProxyResult) bool {
+	if c.skipMutatingVulnCheck(VulnCheckVarnishSmuggling, result) {
+		return false
+	}
+
 	if c.debug {
 		result.DebugInfo += "[VARNISH CVE-2022-45060] Testing for request smuggling\n"
 	}
@@ -1280,55 +1300,115 @@ func (c *Checker) testNginxPlusVersionDetection(client *http.Client, result *Pro
 	return false, ""
 }
 
-// performVendorVulnerabilityChecks runs all vendor-specific vulnerability checks
+// performVendorVulnerabilityChecks runs all vendor-specific vulnerability
+// checks except those disabled by name. The checks are independent, so they
+// run concurrently (see runVulnChecks).
 func (c *Checker) performVendorVulnerabilityChecks(client *http.Client, result *ProxyResult) *VendorVulnResult {
 	vendorResult := &VendorVulnResult{}
 
-	// HAProxy checks
-	vendorResult.HAProxyStatsExposed, vendorResult.HAProxyStatsPath = c.testHAProxyStatsExposure(client, result)
-	vendorResult.HAProxyCVE_2023_40225 = c.testHAProxyCVE_2023_40225(client, result)
-	vendorResult.HAProxyCVE_2021_40346 = c.testHAProxyCVE_2021_40346(client, result)
-	vendorResult.HAProxyVersionDetected, vendorResult.HAProxyVersion = c.testHAProxyVersionDetection(client, result)
-
-	// Squid checks
-	vendorResult.SquidCacheManagerExposed, vendorResult.SquidCacheManagerPaths = c.testSquidCacheManager(client, result)
-	vendorResult.SquidCVE_2021_46784 = c.testSquidCVE_2021_46784(client, result)
-	vendorResult.SquidCVE_2020_15810 = c.testSquidCVE_2020_15810(client, result)
-	vendorResult.SquidVersionDetected, vendorResult.SquidVersion = c.testSquidVersionDetection(client, result)
-
-	// Traefik checks
-	vendorResult.TraefikDashboardExposed, vendorResult.TraefikDashboardPath = c.testTraefikDashboard(client, result)
-	vendorResult.TraefikAPIExposed, vendorResult.TraefikAPIPaths = c.testTraefikAPI(client, result)
-	vendorResult.TraefikCVE_2024_45410 = c.testTraefikCVE_2024_45410(client, result)
-
-	// Envoy checks
-	vendorResult.EnvoyAdminExposed, vendorResult.EnvoyAdminPath = c.testEnvoyAdmin(client, result)
-	vendorResult.EnvoyCVE_2022_21654 = c.testEnvoyCVE_2022_21654(client, result)
-	vendorResult.EnvoyVersionDetected, vendorResult.EnvoyVersion = c.testEnvoyVersionDetection(client, result)
-
-	// Caddy checks
-	vendorResult.CaddyAdminAPIExposed, vendorResult.CaddyAdminPath = c.testCaddyAdminAPI(client, result)
-	vendorResult.CaddyVersionDetected, vendorResult.CaddyVersion = c.testCaddyVersionDetection(client, result)
-
-	// Varnish checks
-	vendorResult.VarnishBanLurkExposed = c.testVarnishBanLurk(client, result)
-	vendorResult.VarnishCVE_2022_45060 = c.testVarnishCVE_2022_45060(client, result)
-	vendorResult.VarnishVersionDetected, vendorResult.VarnishVersion = c.testVarnishVersionDetection(client, result)
-
-	// Cloud-specific checks
-	vendorResult.AWSALBHeaderInjection = c.testAWSALBHeaderInjection(client, result)
-	vendorResult.CloudflareWorkerBypass = c.testCloudflareWorkerBypass(client, result)
-	vendorResult.CloudflareCachePoisoning = c.testCloudflareCachePoisoning(client, result)
-
-	// F5 BIG-IP checks
-	vendorResult.F5iControlExposed, vendorResult.F5iControlPath = c.testF5iControlAPI(client, result)
-	vendorResult.F5TMUIExposed = c.testF5TMUI(client, result)
-	vendorResult.F5VersionDetected, vendorResult.F5Version = c.testF5VersionDetection(client, result)
-
-	// Nginx Plus checks
-	vendorResult.NginxPlusAPIExposed, vendorResult.NginxPlusAPIPath = c.testNginxPlusAPI(client, result)
-	vendorResult.NginxPlusDashboard = c.testNginxPlusDashboard(client, result)
-	vendorResult.NginxPlusVersionDetected, vendorResult.NginxPlusVersion = c.testNginxPlusVersionDetection(client, result)
+	c.runVulnChecks(client, result, []vulnCheck{
+		// HAProxy checks
+		c.namedVulnCheck("haproxy_stats_exposed", func(client *http.Client, result *ProxyResult) {
+			vendorResult.HAProxyStatsExposed, vendorResult.HAProxyStatsPath = c.testHAProxyStatsExposure(client, result)
+		}),
+		c.namedVulnCheck("haproxy_cve_2023_40225", func(client *http.Client, result *ProxyResult) {
+			vendorResult.HAProxyCVE_2023_40225 = c.testHAProxyCVE_2023_40225(client, result)
+		}),
+		c.namedVulnCheck("haproxy_cve_2021_40346", func(client *http.Client, result *ProxyResult) {
+			vendorResult.HAProxyCVE_2021_40346 = c.testHAProxyCVE_2021_40346(client, result)
+		}),
+		c.namedVulnCheck("haproxy_version_detected", func(client *http.Client, result *ProxyResult) {
+			vendorResult.HAProxyVersionDetected, vendorResult.HAProxyVersion = c.testHAProxyVersionDetection(client, result)
+		}),
+
+		// Squid checks
+		c.namedVulnCheck("squid_cache_manager_exposed", func(client *http.Client, result *ProxyResult) {
+			vendorResult.SquidCacheManagerExposed, vendorResult.SquidCacheManagerPaths = c.testSquidCacheManager(client, result)
+		}),
+		c.namedVulnCheck("squid_cve_2021_46784", func(client *http.Client, result *ProxyResult) {
+			vendorResult.SquidCVE_2021_46784 = c.testSquidCVE_2021_46784(client, result)
+		}),
+		c.namedVulnCheck("squid_cve_2020_15810", func(client *http.Client, result *ProxyResult) {
+			vendorResult.SquidCVE_2020_15810 = c.testSquidCVE_2020_15810(client, result)
+		}),
+		c.namedVulnCheck("squid_version_detected", func(client *http.Client, result *ProxyResult) {
+			vendorResult.SquidVersionDetected, vendorResult.SquidVersion = c.testSquidVersionDetection(client, result)
+		}),
+
+		// Traefik checks
+		c.namedVulnCheck("traefik_dashboard_exposed", func(client *http.Client, result *ProxyResult) {
+			vendorResult.TraefikDashboardExposed, vendorResult.TraefikDashboardPath = c.testTraefikDashboard(client, result)
+		}),
+		c.namedVulnCheck("traefik_api_exposed", func(client *http.Client, result *ProxyResult) {
+			vendorResult.TraefikAPIExposed, vendorResult.TraefikAPIPaths = c.testTraefikAPI(client, result)
+		}),
+		c.namedVulnCheck("traefik_cve_2024_45410", func(client *http.Client, result *ProxyResult) {
+			vendorResult.TraefikCVE_2024_45410 = c.testTraefikCVE_2024_45410(client, result)
+		}),
+
+		// Envoy checks
+		c.namedVulnCheck("envoy_admin_exposed", func(client *http.Client, result *ProxyResult) {
+			vendorResult.EnvoyAdminExposed, vendorResult.EnvoyAdminPath = c.testEnvoyAdmin(client, result)
+		}),
+		c.namedVulnCheck("envoy_cve_2022_21654", func(client *http.Client, result *ProxyResult) {
+			vendorResult.EnvoyCVE_2022_21654 = c.testEnvoyCVE_2022_21654(client, result)
+		}),
+		c.namedVulnCheck("envoy_version_detected", func(client *http.Client, result *ProxyResult) {
+			vendorResult.EnvoyVersionDetected, vendorResult.EnvoyVersion = c.testEnvoyVersionDetection(client, result)
+		}),
+
+		// Caddy checks
+		c.namedVulnCheck("caddy_admin_api_exposed", func(client *http.Client, result *ProxyResult) {
+			vendorResult.CaddyAdminAPIExposed, vendorResult.CaddyAdminPath = c.testCaddyAdminAPI(client, result)
+		}),
+		c.namedVulnCheck("caddy_version_detected", func(client *http.Client, result *ProxyResult) {
+			vendorResult.CaddyVersionDetected, vendorResult.CaddyVersion = c.testCaddyVersionDetection(client, result)
+		}),
+
+		// Varnish checks
+		c.namedVulnCheck("varnish_ban_lurk_exposed", func(client *http.Client, result *ProxyResult) {
+			vendorResult.VarnishBanLurkExposed = c.testVarnishBanLurk(client, result)
+		}),
+		c.namedVulnCheck("varnish_cve_2022_45060", func(client *http.Client, result *ProxyResult) {
+			vendorResult.VarnishCVE_2022_45060 = c.testVarnishCVE_2022_45060(client, result)
+		}),
+		c.namedVulnCheck("varnish_version_detected", func(client *http.Client, result *ProxyResult) {
+			vendorResult.VarnishVersionDetected, vendorResult.VarnishVersion = c.testVarnishVersionDetection(client, result)
+		}),
+
+		// Cloud-specific checks
+		c.namedVulnCheck("aws_alb_header_injection", func(client *http.Client, result *ProxyResult) {
+			vendorResult.AWSALBHeaderInjection = c.testAWSALBHeaderInjection(client, result)
+		}),
+		c.namedVulnCheck("cloudflare_worker_bypass", func(client *http.Client, result *ProxyResult) {
+			vendorResult.CloudflareWorkerBypass = c.testCloudflareWorkerBypass(client, result)
+		}),
+		c.namedVulnCheck("cloudflare_cache_poisoning", func(client *http.Client, result *ProxyResult) {
+			vendorResult.CloudflareCachePoisoning = c.testCloudflareCachePoisoning(client, result)
+		}),
+
+		// F5 BIG-IP checks
+		c.namedVulnCheck("f5_icontrol_exposed", func(client *http.Client, result *ProxyResult) {
+			vendorResult.F5iControlExposed, vendorResult.F5iControlPath = c.testF5iControlAPI(client, result)
+		}),
+		c.namedVulnCheck("f5_tmui_exposed", func(client *http.Client, result *ProxyResult) {
+			vendorResult.F5TMUIExposed = c.testF5TMUI(client, result)
+		}),
+		c.namedVulnCheck("f5_version_detected", func(client *http.Client, result *ProxyResult) {
+			vendorResult.F5VersionDetected, vendorResult.F5Version = c.testF5VersionDetection(client, result)
+		}),
+
+		// Nginx Plus checks
+		c.namedVulnCheck("nginx_plus_api_exposed", func(client *http.Client, result *ProxyResult) {
+			vendorResult.NginxPlusAPIExposed, vendorResult.NginxPlusAPIPath = c.testNginxPlusAPI(client, result)
+		}),
+		c.namedVulnCheck("nginx_plus_dashboard", func(client *http.Client, result *ProxyResult) {
+			vendorResult.NginxPlusDashboard = c.testNginxPlusDashboard(client, result)
+		}),
+		c.namedVulnCheck("nginx_plus_version_detected", func(client *http.Client, result *ProxyResult) {
+			vendorResult.NginxPlusVersionDetected, vendorResult.NginxPlusVersion = c.testNginxPlusVersionDetection(client, result)
+		}),
+	})
 
 	return vendorResult
 }
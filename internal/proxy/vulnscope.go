@@ -0,0 +1,100 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// errVulnPathOutOfScope is returned for vuln probes whose path is excluded by
+// the configured allowlist or denylist
+var errVulnPathOutOfScope = errors.New("path is outside the configured vuln probing scope")
+
+// scopedTransport refuses requests whose path is not allowed by the checker's
+// vuln path allowlist and denylist, noting each skipped path on the result
+type scopedTransport struct {
+	base    http.RoundTripper
+	checker *Checker
+	result  *ProxyResult
+	mutex   sync.Mutex
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *scopedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.checker.vulnPathAllowed(req.URL.Path) {
+		t.noteSkipped(req.URL.Path)
+		return nil, errVulnPathOutOfScope
+	}
+	return t.base.RoundTrip(req)
+}
+
+// noteSkipped records a skipped path once on the result
+func (t *scopedTransport) noteSkipped(p string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, skipped := range t.result.SkippedVulnPaths {
+		if skipped == p {
+			return
+		}
+	}
+	t.result.SkippedVulnPaths = append(t.result.SkippedVulnPaths, p)
+	if t.checker.debug {
+		t.result.DebugInfo += fmt.Sprintf("[SCOPE] Skipping out-of-scope probe path: %s\n", p)
+	}
+}
+
+// scopeVulnPaths returns a copy of client that only sends vuln probes to paths
+// allowed by VulnPathAllowlist and VulnPathDenylist. The client is returned
+// unchanged when neither list is configured. Probes written on raw connections
+// (request smuggling and similar) do not go through the client and are not scoped.
+func (c *Checker) scopeVulnPaths(client *http.Client, result *ProxyResult) *http.Client {
+	if len(c.config.VulnPathAllowlist) == 0 && len(c.config.VulnPathDenylist) == 0 {
+		return client
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	scoped := *client
+	scoped.Transport = &scopedTransport{base: base, checker: c, result: result}
+	return &scoped
+}
+
+// vulnPathAllowed reports whether a probe may be sent to p. The denylist wins
+// over the allowlist, and an empty allowlist allows every path.
+func (c *Checker) vulnPathAllowed(p string) bool {
+	if p == "" {
+		p = "/"
+	}
+	for _, pattern := range c.config.VulnPathDenylist {
+		if matchVulnPath(pattern, p) {
+			return false
+		}
+	}
+	if len(c.config.VulnPathAllowlist) == 0 {
+		return true
+	}
+	for _, pattern := range c.config.VulnPathAllowlist {
+		if matchVulnPath(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchVulnPath matches p against a glob pattern (e.g. "/admin/*") or treats
+// the pattern as a path prefix, so "/server-status" also covers "/server-status/x".
+// p is the URL path without the query string.
+func matchVulnPath(pattern, p string) bool {
+	if matched, err := path.Match(pattern, p); err == nil && matched {
+		return true
+	}
+	prefix := strings.TrimSuffix(pattern, "/")
+	return p == prefix || strings.HasPrefix(p, prefix+"/")
+}
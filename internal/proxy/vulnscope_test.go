@@ -0,0 +1,67 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestVulnPathAllowed tests allowlist and denylist matching of vuln probe paths
+func TestVulnPathAllowed(t *testing.T) {
+	checker := NewChecker(Config{
+		VulnPathAllowlist: []string{"/server-status", "/admin/*"},
+		VulnPathDenylist:  []string{"/admin/secret"},
+	}, false, nil)
+
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"/server-status", true},
+		{"/server-status/extended", true},
+		{"/server-statusx", false},
+		{"/admin/panel", true},
+		{"/admin/secret", false},
+		{"/haproxy", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := checker.vulnPathAllowed(tt.path); got != tt.allowed {
+			t.Errorf("vulnPathAllowed(%q) = %t, want %t", tt.path, got, tt.allowed)
+		}
+	}
+}
+
+// TestScopeVulnPaths tests that out-of-scope probes are never sent and are noted on the result
+func TestScopeVulnPaths(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{VulnPathDenylist: []string{"/haproxy"}}, true, nil)
+	result := &ProxyResult{}
+	client := checker.scopeVulnPaths(server.Client(), result)
+
+	for _, p := range []string{"/stats", "/haproxy", "/haproxy?stats"} {
+		resp, err := client.Get(server.URL + p)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}
+
+	if len(requested) != 1 || requested[0] != "/stats" {
+		t.Errorf("Expected only /stats to reach the server, got %v", requested)
+	}
+	if len(result.SkippedVulnPaths) != 1 || result.SkippedVulnPaths[0] != "/haproxy" {
+		t.Errorf("Expected /haproxy to be noted once as skipped, got %v", result.SkippedVulnPaths)
+	}
+
+	// Without any scope configured the client is used as-is
+	unscoped := NewChecker(Config{}, false, nil)
+	if c := server.Client(); unscoped.scopeVulnPaths(c, result) != c {
+		t.Error("Expected the client to be returned unchanged without a scope")
+	}
+}
@@ -0,0 +1,115 @@
+package proxy
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// defaultWebSocketEchoURL is the echo endpoint used by the WebSocket check
+// when none is configured
+const defaultWebSocketEchoURL = "wss://echo.websocket.org"
+
+// webSocketMaxMessages bounds how many messages are read while waiting for
+// the echo, since some echo servers greet the client first
+const webSocketMaxMessages = 5
+
+// webSocketEchoURL returns the echo endpoint used by the WebSocket check
+func (c *Checker) webSocketEchoURL() string {
+	if c.config.WebSocketEchoURL != "" {
+		return c.config.WebSocketEchoURL
+	}
+	return defaultWebSocketEchoURL
+}
+
+// checkWebSocketSupport opens a WebSocket to the echo endpoint through the
+// proxy, sends a text frame and reports in result.SupportsWebSocket whether
+// the same frame came back. Unlike the WebSocket abuse check, this confirms
+// the proxy can carry a working WebSocket connection.
+func (c *Checker) checkWebSocketSupport(client *http.Client, result *ProxyResult) {
+	echoURL := c.webSocketEchoURL()
+
+	transport, ok := unwrapTransport(client.Transport).(*http.Transport)
+	if !ok {
+		if c.debug {
+			result.DebugInfo += "[WEBSOCKET] Client transport does not support WebSocket dialing, skipping\n"
+		}
+		return
+	}
+	result.WebSocketChecked = true
+
+	// Connect through the proxy the same way the client does: HTTP proxies via
+	// CONNECT, SOCKS and NTLM proxies through the transport's dialer
+	dialer := websocket.Dialer{
+		Proxy:            transport.Proxy,
+		NetDialContext:   transport.DialContext,
+		TLSClientConfig:  transport.TLSClientConfig,
+		HandshakeTimeout: c.timeout(result),
+	}
+	header := http.Header{}
+	if c.config.UserAgent != "" {
+		header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout(result))
+	defer cancel()
+
+	conn, resp, err := dialer.DialContext(ctx, echoURL, header)
+	if err != nil {
+		if c.debug {
+			if resp != nil {
+				result.DebugInfo += fmt.Sprintf("[WEBSOCKET] Handshake with %s failed with status %d: %v\n", echoURL, resp.StatusCode, err)
+			} else {
+				result.DebugInfo += fmt.Sprintf("[WEBSOCKET] Handshake with %s failed: %v\n", echoURL, err)
+			}
+		}
+		return
+	}
+	defer conn.Close()
+	recordTraffic(result, 1, 0)
+
+	deadline, _ := ctx.Deadline()
+	conn.SetWriteDeadline(deadline)
+	conn.SetReadDeadline(deadline)
+
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	payload := "proxyhawk-" + hex.EncodeToString(nonce)
+
+	start := time.Now()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(payload)); err != nil {
+		if c.debug {
+			result.DebugInfo += fmt.Sprintf("[WEBSOCKET] Sending frame to %s failed: %v\n", echoURL, err)
+		}
+		return
+	}
+
+	for i := 0; i < webSocketMaxMessages; i++ {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[WEBSOCKET] Reading echo from %s failed: %v\n", echoURL, err)
+			}
+			return
+		}
+		recordTraffic(result, 0, int64(len(message)))
+		if string(message) == payload {
+			result.SupportsWebSocket = true
+			if c.debug {
+				result.DebugInfo += fmt.Sprintf("[WEBSOCKET] Frame echoed by %s in %v\n", echoURL, time.Since(start))
+			}
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), deadline)
+			return
+		}
+	}
+
+	if c.debug {
+		result.DebugInfo += fmt.Sprintf("[WEBSOCKET] %s did not echo the frame within %d messages\n", echoURL, webSocketMaxMessages)
+	}
+}
@@ -0,0 +1,76 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// startWebSocketServer starts a WebSocket server that greets the client and
+// then answers every message with reply(message)
+func startWebSocketServer(t *testing.T, reply func(string) string) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, []byte("Request served by test"))
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(websocket.TextMessage, []byte(reply(string(message))))
+		}
+	}))
+}
+
+func TestCheckWebSocketSupport(t *testing.T) {
+	echo := startWebSocketServer(t, func(message string) string { return message })
+	defer echo.Close()
+	garble := startWebSocketServer(t, strings.ToUpper)
+	defer garble.Close()
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plain.Close()
+
+	proxyListener := startTunnelProxy(t)
+	defer proxyListener.Close()
+	proxyURL, _ := url.Parse("http://" + proxyListener.Addr().String())
+
+	tests := []struct {
+		name   string
+		server *httptest.Server
+		want   bool
+	}{
+		{"echoed frame", echo, true},
+		{"altered frame", garble, false},
+		{"no upgrade", plain, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(Config{
+				Timeout:          2 * time.Second,
+				CheckWebSocket:   true,
+				WebSocketEchoURL: "ws" + strings.TrimPrefix(tt.server.URL, "http"),
+			}, true, nil)
+			client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+			result := &ProxyResult{}
+			checker.checkWebSocketSupport(client, result)
+			if !result.WebSocketChecked {
+				t.Fatal("Expected the WebSocket check to run")
+			}
+			if result.SupportsWebSocket != tt.want {
+				t.Errorf("SupportsWebSocket = %t, want %t\n%s", result.SupportsWebSocket, tt.want, result.DebugInfo)
+			}
+		})
+	}
+}
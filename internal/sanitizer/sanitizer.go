@@ -120,10 +120,11 @@ func (s *Sanitizer) SanitizeURL(input string) string {
 
 	// Check for allowed schemes
 	allowedSchemes := map[string]bool{
-		"http":   true,
-		"https":  true,
-		"socks4": true,
-		"socks5": true,
+		"http":    true,
+		"https":   true,
+		"socks4":  true,
+		"socks4a": true,
+		"socks5":  true,
 	}
 
 	if !allowedSchemes[parsedURL.Scheme] {
@@ -0,0 +1,185 @@
+// Package sshtunnel opens an SSH connection to a bastion host and dials
+// through it, so proxy checks can run from networks whose only egress is a
+// jump host.
+package sshtunnel
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// defaultKeyFiles are tried in ~/.ssh when no key file is given
+var defaultKeyFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// Config describes the bastion to tunnel through
+type Config struct {
+	Target         string        // user@host[:port]; the user defaults to the current user and the port to 22
+	KeyFile        string        // Private key for authentication (default: ~/.ssh/id_ed25519, id_ecdsa, id_rsa)
+	KnownHostsFile string        // Known hosts used to verify the bastion (default: ~/.ssh/known_hosts)
+	Timeout        time.Duration // Timeout for connecting to the bastion
+}
+
+// Tunnel is an established SSH connection that dials on the bastion's side
+type Tunnel struct {
+	client *ssh.Client
+	addr   string
+}
+
+// Open connects and authenticates to the bastion described by cfg. Keys are
+// taken from KeyFile (or the default ~/.ssh keys) and from ssh-agent when
+// SSH_AUTH_SOCK is set. The bastion's host key must be in the known hosts file.
+func Open(cfg Config) (*Tunnel, error) {
+	username, addr, err := ParseTarget(cfg.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	signers, err := loadSigners(cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	knownHostsFile := cfg.KnownHostsFile
+	if knownHostsFile == "" {
+		knownHostsFile = filepath.Join(homeDir(), ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("ssh tunnel: cannot read known hosts file %s: %w", knownHostsFile, err)
+	}
+
+	client, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            username,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signers...)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         cfg.Timeout,
+	})
+	if err != nil {
+		var keyErr *knownhosts.KeyError
+		if stderrors.As(err, &keyErr) {
+			if len(keyErr.Want) == 0 {
+				return nil, fmt.Errorf("ssh tunnel: host key for %s is not in %s (connect once with ssh to add it): %w", addr, knownHostsFile, err)
+			}
+			return nil, fmt.Errorf("ssh tunnel: host key for %s does not match %s: %w", addr, knownHostsFile, err)
+		}
+		return nil, fmt.Errorf("ssh tunnel: cannot connect to %s as %s: %w", addr, username, err)
+	}
+
+	return &Tunnel{client: client, addr: addr}, nil
+}
+
+// DialContext opens a TCP connection to addr from the bastion. It has the
+// signature of net.Dialer.DialContext so it can back HTTP transports.
+func (t *Tunnel) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := t.client.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("ssh tunnel via %s: %w", t.addr, err)
+	}
+	return conn, nil
+}
+
+// Addr returns the bastion's address
+func (t *Tunnel) Addr() string {
+	return t.addr
+}
+
+// Close closes the SSH connection and every connection dialed through it
+func (t *Tunnel) Close() error {
+	return t.client.Close()
+}
+
+// ParseTarget splits user@host[:port] into the login user and the bastion's
+// address, defaulting to the current user and port 22
+func ParseTarget(target string) (string, string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", "", fmt.Errorf("ssh tunnel: no bastion given (expected user@host[:port])")
+	}
+
+	username, host := "", target
+	if i := strings.LastIndex(target, "@"); i >= 0 {
+		username, host = target[:i], target[i+1:]
+	}
+	if username == "" {
+		current, err := user.Current()
+		if err != nil {
+			return "", "", fmt.Errorf("ssh tunnel: no user in %q and the current user is unknown: %w", target, err)
+		}
+		username = current.Username
+	}
+
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), "22")
+	}
+	if h, _, _ := net.SplitHostPort(host); h == "" {
+		return "", "", fmt.Errorf("ssh tunnel: no host in %q (expected user@host[:port])", target)
+	}
+	return username, host, nil
+}
+
+// loadSigners returns the keys to authenticate with: keyFile, or the default
+// ~/.ssh keys when it is empty, plus any keys held by ssh-agent
+func loadSigners(keyFile string) ([]ssh.Signer, error) {
+	var signers []ssh.Signer
+
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			if agentSigners, err := agent.NewClient(conn).Signers(); err == nil {
+				signers = append(signers, agentSigners...)
+			}
+		}
+	}
+
+	keyFiles := []string{keyFile}
+	if keyFile == "" {
+		keyFiles = nil
+		for _, name := range defaultKeyFiles {
+			keyFiles = append(keyFiles, filepath.Join(homeDir(), ".ssh", name))
+		}
+	}
+
+	for _, path := range keyFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if keyFile == "" && os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("ssh tunnel: cannot read key file %s: %w", path, err)
+		}
+
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			var passErr *ssh.PassphraseMissingError
+			if stderrors.As(err, &passErr) {
+				if keyFile == "" {
+					continue
+				}
+				return nil, fmt.Errorf("ssh tunnel: key file %s is passphrase protected; load it into ssh-agent instead", path)
+			}
+			return nil, fmt.Errorf("ssh tunnel: cannot parse key file %s: %w", path, err)
+		}
+		signers = append(signers, signer)
+	}
+
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("ssh tunnel: no usable private key found (use -ssh-key or ssh-agent)")
+	}
+	return signers, nil
+}
+
+// homeDir returns the current user's home directory, or "" if it is unknown
+func homeDir() string {
+	home, _ := os.UserHomeDir()
+	return home
+}
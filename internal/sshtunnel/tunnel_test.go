@@ -0,0 +1,230 @@
+package sshtunnel
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		target   string
+		wantUser string
+		wantAddr string
+		wantErr  bool
+	}{
+		{target: "ops@bastion.example.com", wantUser: "ops", wantAddr: "bastion.example.com:22"},
+		{target: "ops@10.0.0.1:2222", wantUser: "ops", wantAddr: "10.0.0.1:2222"},
+		{target: "ops@[2001:db8::1]", wantUser: "ops", wantAddr: "[2001:db8::1]:22"},
+		{target: "ops@", wantErr: true},
+		{target: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		user, addr, err := ParseTarget(tt.target)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseTarget(%q) expected an error", tt.target)
+			}
+			continue
+		}
+		if err != nil || user != tt.wantUser || addr != tt.wantAddr {
+			t.Errorf("ParseTarget(%q) = %q, %q, %v; want %q, %q", tt.target, user, addr, err, tt.wantUser, tt.wantAddr)
+		}
+	}
+}
+
+func TestOpenTunnelDialsThroughBastion(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("via bastion"))
+	}))
+	defer target.Close()
+
+	dir := t.TempDir()
+	clientKey := writeKey(t, filepath.Join(dir, "id_ed25519"))
+	bastion, hostKey := startBastion(t, clientKey.PublicKey())
+	writeKnownHosts(t, filepath.Join(dir, "known_hosts"), bastion, hostKey.PublicKey())
+
+	tunnel, err := Open(Config{
+		Target:         "ops@" + bastion,
+		KeyFile:        filepath.Join(dir, "id_ed25519"),
+		KnownHostsFile: filepath.Join(dir, "known_hosts"),
+		Timeout:        5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	defer tunnel.Close()
+
+	client := &http.Client{Transport: &http.Transport{DialContext: tunnel.DialContext}}
+	resp, err := client.Get(target.URL)
+	if err != nil {
+		t.Fatalf("Request through the tunnel failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "via bastion" {
+		t.Errorf("Unexpected body through the tunnel: %q", body)
+	}
+}
+
+func TestOpenTunnelRejectsUnknownHostKey(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	dir := t.TempDir()
+	clientKey := writeKey(t, filepath.Join(dir, "id_ed25519"))
+	bastion, _ := startBastion(t, clientKey.PublicKey())
+	if err := os.WriteFile(filepath.Join(dir, "known_hosts"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Open(Config{
+		Target:         "ops@" + bastion,
+		KeyFile:        filepath.Join(dir, "id_ed25519"),
+		KnownHostsFile: filepath.Join(dir, "known_hosts"),
+		Timeout:        5 * time.Second,
+	})
+	if err == nil || !strings.Contains(err.Error(), "is not in") {
+		t.Errorf("Expected an unknown host key error, got %v", err)
+	}
+}
+
+func TestOpenTunnelMissingKeyFile(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	_, err := Open(Config{Target: "ops@127.0.0.1:1", KeyFile: filepath.Join(t.TempDir(), "missing")})
+	if err == nil || !strings.Contains(err.Error(), "cannot read key file") {
+		t.Errorf("Expected a key file error, got %v", err)
+	}
+}
+
+// writeKey saves a new ed25519 private key to path and returns its signer
+func writeKey(t *testing.T, path string) ssh.Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+// writeKnownHosts saves a known hosts file trusting key for addr
+func writeKnownHosts(t *testing.T, path, addr string, key ssh.PublicKey) {
+	t.Helper()
+	line := knownhosts.Line([]string{knownhosts.Normalize(addr)}, key)
+	if err := os.WriteFile(path, []byte(line+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// startBastion runs a minimal SSH server that accepts clientKey and forwards
+// direct-tcpip channels, returning its address and host key
+func startBastion(t *testing.T, clientKey ssh.PublicKey) (string, ssh.Signer) {
+	t.Helper()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostKey, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveBastionConn(conn, config)
+		}
+	}()
+
+	return listener.Addr().String(), hostKey
+}
+
+func serveBastionConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "direct-tcpip" {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported")
+			continue
+		}
+
+		// host string, port uint32, origin host string, origin port uint32
+		data := newChannel.ExtraData()
+		hostLen := binary.BigEndian.Uint32(data)
+		host := string(data[4 : 4+hostLen])
+		port := binary.BigEndian.Uint32(data[4+hostLen:])
+
+		upstream, err := net.Dial("tcp", net.JoinHostPort(host, strconv.FormatUint(uint64(port), 10)))
+		if err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			upstream.Close()
+			continue
+		}
+		go ssh.DiscardRequests(requests)
+		go func() {
+			io.Copy(channel, upstream)
+			channel.Close()
+		}()
+		go func() {
+			io.Copy(upstream, channel)
+			upstream.Close()
+		}()
+	}
+}
@@ -60,7 +60,9 @@ func (v *View) DebugInfoString() string {
 
 // SetDebugInfo provides backward compatibility
 func (v *View) SetDebugInfoFromString(info string) {
-	v.DebugMessages = []string{info}
+	v.DebugMessages = v.DebugMessages[:0]
+	v.debugRendered = v.debugRendered[:0]
+	v.AddDebugMessage(info)
 }
 
 // AppendDebugInfo provides backward compatibility
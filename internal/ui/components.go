@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -21,8 +22,9 @@ type Component interface {
 
 // HeaderComponent displays the application header
 type HeaderComponent struct {
-	Title string
-	Mode  ViewMode
+	Title  string
+	Mode   ViewMode
+	Paused bool
 }
 
 func (h *HeaderComponent) Render() string {
@@ -35,6 +37,10 @@ func (h *HeaderComponent) Render() string {
 		title += " • Debug Mode"
 	}
 
+	if h.Paused {
+		title += " • PAUSED"
+	}
+
 	return HeaderStyle.Render(title)
 }
 
@@ -50,6 +56,8 @@ type StatsBarComponent struct {
 	Failed      int
 	Active      int
 	AvgSpeed    time.Duration
+	ETA         time.Duration
+	Paused      bool
 }
 
 func (s *StatsBarComponent) Render() string {
@@ -103,6 +111,13 @@ func (s *StatsBarComponent) Render() string {
 			ProxySpeedStyle.Render(speedStr)))
 	}
 
+	// Time left, meaningless while paused
+	if s.ETA > 0 && !s.Paused && s.Current < s.Total {
+		items = append(items, fmt.Sprintf("%s %s",
+			MetricLabelStyle.Render("ETA:"),
+			MetricValueStyle.Render(s.ETA.Round(time.Second).String())))
+	}
+
 	// Join with separator
 	content := strings.Join(items, "  •  ")
 	return StatsBarStyle.Render(content)
@@ -128,6 +143,65 @@ func (p *ProgressComponent) Render() string {
 	return p.Progress.View()
 }
 
+// =============================================================================
+// SPEED HISTOGRAM COMPONENT
+// =============================================================================
+
+// SpeedHistogramComponent shows how working proxies spread over the
+// response-speed buckets as a compact bar chart
+type SpeedHistogramComponent struct {
+	Buckets  [NumSpeedBuckets]int
+	MaxWidth int
+}
+
+func (h *SpeedHistogramComponent) Render() string {
+	total, largest := 0, 0
+	for _, count := range h.Buckets {
+		total += count
+		if count > largest {
+			largest = count
+		}
+	}
+	if total == 0 {
+		return ""
+	}
+
+	maxWidth := h.MaxWidth
+	if maxWidth <= 0 {
+		maxWidth = 30
+	}
+
+	var lines []string
+	for i, count := range h.Buckets {
+		width := count * maxWidth / largest
+		if count > 0 && width == 0 {
+			width = 1
+		}
+		bar := strings.Repeat("█", width) + strings.Repeat("░", maxWidth-width)
+		lines = append(lines, fmt.Sprintf("%s %s %s",
+			MetricLabelStyle.Render(fmt.Sprintf("%-7s", speedBucketLabel(i))),
+			ProxySpeedStyle.Render(bar),
+			MetricValueStyle.Render(fmt.Sprintf("%d", count))))
+	}
+
+	return HistogramStyle.Render(strings.Join(lines, "\n"))
+}
+
+// speedBucketLabel names a histogram bucket by its upper bound
+func speedBucketLabel(i int) string {
+	if i >= len(SpeedBucketBounds) {
+		return ">" + formatBound(SpeedBucketBounds[len(SpeedBucketBounds)-1])
+	}
+	return "<" + formatBound(SpeedBucketBounds[i])
+}
+
+func formatBound(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%gs", d.Seconds())
+}
+
 // =============================================================================
 // ACTIVE CHECKS COMPONENT
 // =============================================================================
@@ -282,6 +356,23 @@ func (a *ActiveChecksComponent) renderCheckDetails(status *CheckStatus) string {
 			b.WriteString(" " + dimStyle.Render(fmt.Sprintf("• Cloud: %s", status.CloudProvider)))
 		}
 
+		// Show anonymity classification, flagging transparent proxies that leak the client IP
+		switch status.AnonymityLevel {
+		case "transparent":
+			b.WriteString(" " + WarningStyle.Render("• Transparent"))
+		case "anonymous", "elite":
+			b.WriteString(" " + dimStyle.Render(fmt.Sprintf("• Anonymity: %s", status.AnonymityLevel)))
+		}
+
+		// Show IPv6 connectivity when it was tested
+		if status.IPv6Checked {
+			if status.SupportsIPv6 {
+				b.WriteString(" " + dimStyle.Render("• IPv6"))
+			} else {
+				b.WriteString(" " + dimStyle.Render("• IPv4 only"))
+			}
+		}
+
 		// Show internal access flags
 		if status.InternalAccess {
 			b.WriteString(" " + WarningStyle.Render("• Internal Access"))
@@ -359,49 +450,26 @@ func (f *FooterComponent) Render() string {
 // DEBUG LOG COMPONENT
 // =============================================================================
 
-// DebugLogComponent shows recent debug messages
+// DebugLogComponent shows the debug log in a scrollable viewport
 type DebugLogComponent struct {
-	Messages []string
-	MaxLines int
+	Viewport viewport.Model
+	Scrolled bool
 }
 
 func (d *DebugLogComponent) Render() string {
-	if len(d.Messages) == 0 {
+	if d.Viewport.TotalLineCount() == 0 || d.Viewport.Height <= 0 {
 		return ""
 	}
 
-	maxLines := d.MaxLines
-	if maxLines <= 0 {
-		maxLines = 10
+	title := "Debug Log  •  ↑/↓ pgup/pgdn to scroll"
+	if d.Scrolled {
+		title = fmt.Sprintf("Debug Log  •  %.0f%%  •  end to follow", d.Viewport.ScrollPercent()*100)
 	}
 
-	// Take last N messages
-	start := 0
-	if len(d.Messages) > maxLines {
-		start = len(d.Messages) - maxLines
-	}
-	messages := d.Messages[start:]
-
 	var b strings.Builder
-	b.WriteString(dimStyle.Render("Debug Log"))
+	b.WriteString(dimStyle.Render(title))
 	b.WriteString("\n\n")
-
-	for _, msg := range messages {
-		// Colorize based on content
-		style := dimStyle
-		if strings.Contains(strings.ToLower(msg), "error") ||
-		   strings.Contains(strings.ToLower(msg), "fail") {
-			style = ErrorStyle
-		} else if strings.Contains(strings.ToLower(msg), "success") ||
-		          strings.Contains(strings.ToLower(msg), "working") {
-			style = SuccessStyle
-		} else if strings.Contains(strings.ToLower(msg), "warn") {
-			style = WarningStyle
-		}
-
-		b.WriteString(style.Render("  " + msg))
-		b.WriteString("\n")
-	}
+	b.WriteString(d.Viewport.View())
 
 	return lipgloss.NewStyle().
 		Border(thinBorderStyle).
@@ -410,3 +478,24 @@ func (d *DebugLogComponent) Render() string {
 		Width(DefaultWidth).
 		Render(b.String())
 }
+
+// renderDebugLine colors a debug log line by its content and cuts it to
+// width, so long lines do not wrap and push others out of the viewport
+func renderDebugLine(line string, width int) string {
+	lower := strings.ToLower(line)
+	style := dimStyle
+	if strings.Contains(lower, "error") ||
+		strings.Contains(lower, "fail") {
+		style = ErrorStyle
+	} else if strings.Contains(lower, "success") ||
+		strings.Contains(lower, "working") {
+		style = SuccessStyle
+	} else if strings.Contains(lower, "warn") {
+		style = WarningStyle
+	}
+
+	if width > 0 {
+		style = style.Copy().MaxWidth(width)
+	}
+	return style.Render("  " + line)
+}
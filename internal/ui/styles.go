@@ -90,6 +90,11 @@ var (
 			Padding(0, 1).
 			Width(DefaultWidth)
 
+	// Speed histogram section
+	HistogramStyle = lipgloss.NewStyle().
+			Padding(0, 1).
+			Width(DefaultWidth)
+
 	// Active checks section
 	ChecksSectionStyle = lipgloss.NewStyle().
 				Border(thinBorderStyle, true, false, false, false).
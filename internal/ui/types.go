@@ -1,9 +1,11 @@
 package ui
 
 import (
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/viewport"
 )
 
 // ViewMode represents the display mode
@@ -15,6 +17,38 @@ const (
 	ModeDebug
 )
 
+// SpeedBucketBounds are the upper bounds of the response-speed histogram
+// buckets; speeds above the last bound fall into a final overflow bucket
+var SpeedBucketBounds = [NumSpeedBuckets - 1]time.Duration{
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+}
+
+// NumSpeedBuckets is the number of response-speed histogram buckets
+const NumSpeedBuckets = 6
+
+// SpeedBucket returns the histogram bucket index for a response time
+func SpeedBucket(speed time.Duration) int {
+	for i, bound := range SpeedBucketBounds {
+		if speed < bound {
+			return i
+		}
+	}
+	return NumSpeedBuckets - 1
+}
+
+const (
+	// DefaultMaxDebugLines is how many debug log lines are kept when
+	// MaxDebugLines is not set
+	DefaultMaxDebugLines = 1000
+	// DefaultDebugLogHeight is the debug log viewport height until the
+	// terminal size is known
+	DefaultDebugLogHeight = 15
+)
+
 // View represents the main UI state
 type View struct {
 	// Progress tracking
@@ -27,7 +61,9 @@ type View struct {
 	Failed  int
 
 	// Performance metrics
-	AvgSpeed time.Duration
+	AvgSpeed     time.Duration
+	SpeedBuckets [NumSpeedBuckets]int // Working proxies per SpeedBucketBounds bucket
+	ETA          time.Duration        // Time left at the recent throughput (0 = unknown)
 
 	// Active state
 	ActiveChecks map[string]*CheckStatus
@@ -36,8 +72,16 @@ type View struct {
 	// Display mode
 	Mode ViewMode
 
-	// Debug messages
+	// Paused is set while proxy feeding is paused from the keyboard
+	Paused bool
+
+	// Debug log lines, the viewport scrolling through them and whether the
+	// user scrolled away from the tail, which stops following new lines
 	DebugMessages []string
+	MaxDebugLines int // Lines kept (0 = DefaultMaxDebugLines)
+	DebugLog      viewport.Model
+	DebugScrolled bool
+	debugRendered []string // DebugMessages styled and cut to the viewport width
 
 	// Version information
 	Version string
@@ -49,6 +93,7 @@ func NewView() *View {
 		Progress:      progress.New(progress.WithDefaultGradient()),
 		ActiveChecks:  make(map[string]*CheckStatus),
 		DebugMessages: make([]string, 0),
+		DebugLog:      viewport.New(DefaultWidth-4, DefaultDebugLogHeight),
 		Mode:          ModeDefault,
 	}
 }
@@ -64,13 +109,62 @@ func (v *View) SetMode(verbose, debug bool) {
 	}
 }
 
-// AddDebugMessage adds a debug message to the log
+// AddDebugMessage adds a debug message to the log, one entry per line. Only
+// the last MaxDebugLines lines are kept; the viewport follows the tail unless
+// the user scrolled up.
 func (v *View) AddDebugMessage(msg string) {
-	v.DebugMessages = append(v.DebugMessages, msg)
-	// Keep only last 50 messages
-	if len(v.DebugMessages) > 50 {
-		v.DebugMessages = v.DebugMessages[len(v.DebugMessages)-50:]
+	for _, line := range strings.Split(strings.TrimRight(msg, "\n"), "\n") {
+		v.DebugMessages = append(v.DebugMessages, line)
+		v.debugRendered = append(v.debugRendered, renderDebugLine(line, v.DebugLog.Width))
+	}
+
+	limit := v.MaxDebugLines
+	if limit <= 0 {
+		limit = DefaultMaxDebugLines
 	}
+	dropped := len(v.DebugMessages) - limit
+	if dropped > 0 {
+		v.DebugMessages = v.DebugMessages[dropped:]
+		v.debugRendered = v.debugRendered[dropped:]
+		// Keep a scrolled-up view on the same lines
+		v.DebugLog.SetYOffset(v.DebugLog.YOffset - dropped)
+	}
+
+	v.DebugLog.SetContent(strings.Join(v.debugRendered, "\n"))
+	if !v.DebugScrolled {
+		v.DebugLog.GotoBottom()
+	}
+}
+
+// ScrollDebugLog moves the debug log by delta lines, up when negative.
+// Scrolling up stops following new lines until the tail is reached again.
+func (v *View) ScrollDebugLog(delta int) {
+	if delta < 0 {
+		v.DebugLog.LineUp(-delta)
+	} else {
+		v.DebugLog.LineDown(delta)
+	}
+	v.DebugScrolled = !v.DebugLog.AtBottom()
+}
+
+// ResizeDebugLog sets the debug log viewport size, e.g. after the terminal
+// was resized
+func (v *View) ResizeDebugLog(width, height int) {
+	v.DebugLog.Width = width
+	v.DebugLog.Height = height
+	v.debugRendered = v.debugRendered[:0]
+	for _, line := range v.DebugMessages {
+		v.debugRendered = append(v.debugRendered, renderDebugLine(line, width))
+	}
+	v.DebugLog.SetContent(strings.Join(v.debugRendered, "\n"))
+	if !v.DebugScrolled {
+		v.DebugLog.GotoBottom()
+	}
+}
+
+// RecordSpeed counts a working proxy's response time in its speed bucket
+func (v *View) RecordSpeed(speed time.Duration) {
+	v.SpeedBuckets[SpeedBucket(speed)]++
 }
 
 // UpdateProgress updates the progress bar
@@ -116,8 +210,11 @@ type CheckStatus struct {
 	CloudProvider  string
 	InternalAccess bool
 	MetadataAccess bool
+	AnonymityLevel string
 	SupportsHTTP   bool
 	SupportsHTTPS  bool
+	IPv6Checked    bool
+	SupportsIPv6   bool
 	DebugInfo      string
 }
 
@@ -14,8 +14,9 @@ func (v *View) Render() string {
 
 	// Header - always visible
 	header := &HeaderComponent{
-		Title: "ProxyHawk",
-		Mode:  v.Mode,
+		Title:  "ProxyHawk",
+		Mode:   v.Mode,
+		Paused: v.Paused,
 	}
 	sections = append(sections, header.Render())
 
@@ -27,6 +28,8 @@ func (v *View) Render() string {
 		Failed:   v.Failed,
 		Active:   v.CountActive(),
 		AvgSpeed: v.AvgSpeed,
+		ETA:      v.ETA,
+		Paused:   v.Paused,
 	}
 	sections = append(sections, statsBar.Render())
 
@@ -40,6 +43,15 @@ func (v *View) Render() string {
 		sections = append(sections, progressView)
 	}
 
+	// Speed histogram - once a working proxy has been timed
+	histogram := &SpeedHistogramComponent{
+		Buckets:  v.SpeedBuckets,
+		MaxWidth: 30,
+	}
+	if histogramView := histogram.Render(); histogramView != "" {
+		sections = append(sections, histogramView)
+	}
+
 	// Active checks - visible based on mode
 	activeChecks := &ActiveChecksComponent{
 		Checks:     v.ActiveChecks,
@@ -54,8 +66,8 @@ func (v *View) Render() string {
 	// Debug log - only in debug mode
 	if v.Mode == ModeDebug && len(v.DebugMessages) > 0 {
 		debugLog := &DebugLogComponent{
-			Messages: v.DebugMessages,
-			MaxLines: 15,
+			Viewport: v.DebugLog,
+			Scrolled: v.DebugScrolled,
 		}
 		if debugView := debugLog.Render(); debugView != "" {
 			sections = append(sections, debugView)
@@ -115,6 +127,12 @@ func (v *View) getMaxVisible() int {
 func (v *View) getFooterHints() []string {
 	hints := []string{"press q to quit"}
 
+	if v.Paused {
+		hints = append(hints, "press p to resume")
+	} else {
+		hints = append(hints, "press p to pause")
+	}
+
 	if v.Mode == ModeDefault {
 		hints = append(hints, "use -v for verbose", "use -d for debug")
 	}
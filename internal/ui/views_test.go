@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -44,7 +45,7 @@ func TestView_RenderVerbose(t *testing.T) {
 		Current:      5,
 		ActiveChecks: make(map[string]*CheckStatus),
 		SpinnerIdx:   0,
-		DisplayMode:  ViewDisplayMode{IsVerbose: true},
+		Mode:         ModeVerbose,
 	}
 
 	// Add a test check with detailed information
@@ -82,45 +83,43 @@ func TestView_RenderVerbose(t *testing.T) {
 
 func TestGetStatusIcon(t *testing.T) {
 	tests := []struct {
-		name     string
-		success  bool
-		partial  bool
-		complete bool
-		expected string
+		name       string
+		working    bool
+		failed     bool
+		inProgress bool
+		expected   string
 	}{
 		{
-			name:     "Incomplete check",
-			success:  false,
-			partial:  false,
-			complete: false,
-			expected: IconSpinner,
+			name:     "Not checked",
+			expected: IconEmpty,
+		},
+		{
+			name:       "In progress",
+			working:    true,
+			inProgress: true,
+			expected:   IconActive,
 		},
 		{
-			name:     "All successful",
-			success:  true,
-			partial:  false,
-			complete: true,
+			name:     "Working",
+			working:  true,
 			expected: IconSuccess,
 		},
 		{
-			name:     "All failed",
-			success:  false,
-			partial:  false,
-			complete: true,
+			name:     "Failed",
+			failed:   true,
 			expected: IconError,
 		},
 		{
-			name:     "Partial success",
-			success:  false,
-			partial:  true,
-			complete: true,
-			expected: IconWarning,
+			name:     "Working wins over failed",
+			working:  true,
+			failed:   true,
+			expected: IconSuccess,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := GetStatusIcon(tt.success, tt.partial, tt.complete)
+			result := GetStatusIcon(tt.working, tt.failed, tt.inProgress)
 			if result != tt.expected {
 				t.Errorf("GetStatusIcon() = %v, want %v", result, tt.expected)
 			}
@@ -128,36 +127,20 @@ func TestGetStatusIcon(t *testing.T) {
 	}
 }
 
-func TestView_CalculateWorkingCount(t *testing.T) {
+func TestView_MetricsCompat(t *testing.T) {
 	view := &View{
+		Total:        10,
+		Current:      4,
+		Working:      2,
 		ActiveChecks: make(map[string]*CheckStatus),
 	}
 
-	// Add test data
-	view.ActiveChecks["proxy1"] = &CheckStatus{
-		CheckResults: []CheckResult{
-			{Success: true},
-			{Success: true},
-		},
-	}
-	view.ActiveChecks["proxy2"] = &CheckStatus{
-		CheckResults: []CheckResult{
-			{Success: false},
-			{Success: false},
-		},
+	metrics := view.MetricsCompat()
+	if metrics.SuccessRate != 50 {
+		t.Errorf("SuccessRate = %v, want 50", metrics.SuccessRate)
 	}
-	view.ActiveChecks["proxy3"] = &CheckStatus{
-		CheckResults: []CheckResult{
-			{Success: true},
-			{Success: false},
-		},
-	}
-
-	result := view.calculateWorkingCount()
-	expected := 2 // proxy1 and proxy3 have at least one successful check
-
-	if result != expected {
-		t.Errorf("calculateWorkingCount() = %v, want %v", result, expected)
+	if metrics.QueueSize != 6 {
+		t.Errorf("QueueSize = %v, want 6", metrics.QueueSize)
 	}
 }
 
@@ -220,7 +203,7 @@ func TestView_IsValid(t *testing.T) {
 	}
 }
 
-func TestView_GetCompletionPercentage(t *testing.T) {
+func TestView_UpdateProgress(t *testing.T) {
 	tests := []struct {
 		name     string
 		total    int
@@ -228,17 +211,90 @@ func TestView_GetCompletionPercentage(t *testing.T) {
 		expected float64
 	}{
 		{"Zero total", 0, 0, 0},
-		{"Half complete", 10, 5, 50},
-		{"Fully complete", 10, 10, 100},
+		{"Half complete", 10, 5, 0.5},
+		{"Fully complete", 10, 10, 1},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			view := &View{Total: tt.total, Current: tt.current}
-			result := view.GetCompletionPercentage()
-			if result != tt.expected {
-				t.Errorf("GetCompletionPercentage() = %v, want %v", result, tt.expected)
+			view := NewView()
+			view.UpdateProgress(tt.current, tt.total)
+			if view.Current != tt.current || view.Total != tt.total {
+				t.Errorf("UpdateProgress() set %d/%d, want %d/%d", view.Current, view.Total, tt.current, tt.total)
+			}
+			if result := view.Progress.Percent(); result != tt.expected {
+				t.Errorf("Progress.Percent() = %v, want %v", result, tt.expected)
 			}
 		})
 	}
 }
+
+func TestView_SpeedHistogram(t *testing.T) {
+	view := NewView()
+	view.Total = 10
+
+	if output := view.RenderDefault(); strings.Contains(output, "<250ms") {
+		t.Error("Expected no histogram before any working proxy is timed")
+	}
+
+	for _, speed := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 700 * time.Millisecond, 8 * time.Second} {
+		view.RecordSpeed(speed)
+	}
+	expected := [NumSpeedBuckets]int{2, 0, 1, 0, 0, 1}
+	if view.SpeedBuckets != expected {
+		t.Errorf("SpeedBuckets = %v, want %v", view.SpeedBuckets, expected)
+	}
+
+	output := view.RenderDefault()
+	for _, label := range []string{"<250ms", "<1s", ">5s"} {
+		if !strings.Contains(output, label) {
+			t.Errorf("Expected histogram label %s in output", label)
+		}
+	}
+}
+
+func TestView_DebugLogScrolling(t *testing.T) {
+	view := NewView()
+	view.Total = 10
+	view.MaxDebugLines = 100
+	view.SetMode(false, true)
+
+	for i := 0; i < 150; i++ {
+		view.AddDebugMessage(fmt.Sprintf("[DEBUG] line %d\n", i))
+	}
+	if len(view.DebugMessages) != 100 || view.DebugMessages[0] != "[DEBUG] line 50" {
+		t.Fatalf("Expected the last 100 lines to be kept, got %d starting with %q", len(view.DebugMessages), view.DebugMessages[0])
+	}
+	if !view.DebugLog.AtBottom() || !strings.Contains(view.Render(), "line 149") {
+		t.Error("Expected the debug log to follow the tail")
+	}
+
+	// Scrolling up stops following new lines
+	view.ScrollDebugLog(-20)
+	view.AddDebugMessage("[DEBUG] line 150\n[DEBUG] line 151")
+	if !view.DebugScrolled || view.DebugLog.AtBottom() {
+		t.Error("Expected the scrolled-up debug log to stay in place")
+	}
+	if output := view.Render(); strings.Contains(output, "line 151") {
+		t.Error("Expected the newest line to be off screen while scrolled up")
+	}
+
+	// Scrolling back to the end follows again
+	view.ScrollDebugLog(len(view.DebugMessages))
+	view.AddDebugMessage("[DEBUG] line 152")
+	if view.DebugScrolled || !strings.Contains(view.Render(), "line 152") {
+		t.Error("Expected the debug log to follow the tail again")
+	}
+}
+
+func TestStatsBarETA(t *testing.T) {
+	bar := &StatsBarComponent{Current: 40, Total: 100, Working: 10, ETA: 95 * time.Second}
+	if output := bar.Render(); !strings.Contains(output, "ETA:") || !strings.Contains(output, "1m35s") {
+		t.Errorf("Expected the ETA in the stats bar, got: %s", output)
+	}
+
+	bar.Paused = true
+	if output := bar.Render(); strings.Contains(output, "ETA:") {
+		t.Errorf("Expected no ETA while paused, got: %s", output)
+	}
+}
@@ -46,7 +46,7 @@ type ProxyValidator struct {
 func NewProxyValidator() *ProxyValidator {
 	return &ProxyValidator{
 		allowPrivateIPs:   true, // Allow private IPs for internal infrastructure scanning
-		supportedSchemes:  []string{"http", "https", "socks4", "socks5"},
+		supportedSchemes:  []string{"http", "https", "socks4", "socks4a", "socks5"},
 		maxHostnameLength: 253,
 		maxPortNumber:     65535,
 	}
@@ -332,7 +332,7 @@ func (v *ProxyValidator) validatePort(portStr string) error {
 // validateSchemeSpecific performs scheme-specific validation
 func (v *ProxyValidator) validateSchemeSpecific(parsed *url.URL) error {
 	switch strings.ToLower(parsed.Scheme) {
-	case "socks4", "socks5":
+	case "socks4", "socks4a", "socks5":
 		// SOCKS proxies shouldn't have path, query, or fragment
 		if parsed.Path != "" && parsed.Path != "/" {
 			return ValidationError{
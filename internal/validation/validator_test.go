@@ -29,6 +29,11 @@ func TestProxyValidator_ValidateProxyURL(t *testing.T) {
 			url:     "socks5://proxy.example.com:1080",
 			wantErr: false,
 		},
+		{
+			name:    "valid SOCKS4a proxy",
+			url:     "socks4a://proxy.example.com:1080",
+			wantErr: false,
+		},
 		{
 			name:    "valid IP address proxy",
 			url:     "http://93.184.216.34:8080", // example.com IP
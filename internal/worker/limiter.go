@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StartLimiter is a token bucket that caps how fast new proxy checks are
+// started across all workers. It only gates starts; checks that have started
+// still run concurrently. A nil *StartLimiter never waits.
+type StartLimiter struct {
+	rate   float64 // Tokens added per second
+	tokens float64 // Available tokens; negative while callers are waiting on reservations
+	last   time.Time
+	mutex  sync.Mutex
+}
+
+// NewStartLimiter returns a limiter allowing perSecond check starts per second
+// with no burst beyond a single start, or nil if perSecond is not positive
+func NewStartLimiter(perSecond float64) *StartLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &StartLimiter{
+		rate:   perSecond,
+		tokens: 1,
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until the next check may start or ctx is done
+func (l *StartLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	l.mutex.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > 1 {
+		l.tokens = 1
+	}
+	l.last = now
+
+	// Reserve a token; if none is available the reservation puts the bucket in debt
+	l.tokens--
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mutex.Unlock()
+
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
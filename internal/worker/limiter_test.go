@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestStartLimiterRate tests that concurrent callers are paced to the configured rate
+func TestStartLimiterRate(t *testing.T) {
+	limiter := NewStartLimiter(50)
+	ctx := context.Background()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 11; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(ctx); err != nil {
+				t.Errorf("Wait failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The first start is immediate, the other 10 are spaced 20ms apart
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("Expected 11 starts at 50/s to take at least 200ms, took %v", elapsed)
+	}
+}
+
+// TestStartLimiterCancel tests that a waiting caller returns when the context is cancelled
+func TestStartLimiterCancel(t *testing.T) {
+	limiter := NewStartLimiter(0.1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := limiter.Wait(ctx); err != nil {
+		t.Fatalf("Expected the first start to be immediate, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	if err := limiter.Wait(ctx); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+// TestStartLimiterDisabled tests that a non-positive rate disables limiting
+func TestStartLimiterDisabled(t *testing.T) {
+	limiter := NewStartLimiter(0)
+	if limiter != nil {
+		t.Fatal("Expected a nil limiter for a zero rate")
+	}
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Errorf("Expected a nil limiter not to wait, got %v", err)
+	}
+}
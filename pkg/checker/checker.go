@@ -0,0 +1,28 @@
+// Package checker lets other Go programs embed the ProxyHawk proxy checker.
+// The types are the checker's own, so results can be enriched in place with
+// Config.ResultHook before they reach the output writers.
+package checker
+
+import "github.com/ResistanceIsUseless/ProxyHawk/internal/proxy"
+
+type (
+	// Config configures a Checker, including the optional ResultHook
+	Config = proxy.Config
+	// Checker checks proxies; it is safe for concurrent use
+	Checker = proxy.Checker
+	// ProxyResult is the outcome of checking one proxy
+	ProxyResult = proxy.ProxyResult
+	// CheckResult is one request made while checking a proxy
+	CheckResult = proxy.CheckResult
+	// ProxyType is the protocol a proxy speaks
+	ProxyType = proxy.ProxyType
+	// AnonymityLevel is how much of the client a proxy reveals
+	AnonymityLevel = proxy.AnonymityLevel
+)
+
+// New creates a Checker. Set config.ResultHook to annotate or rewrite each
+// result before Check returns it; see Config.ResultHook for the rules a hook
+// must follow.
+func New(config Config, debug bool) *Checker {
+	return proxy.NewChecker(config, debug, nil)
+}
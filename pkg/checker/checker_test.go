@@ -0,0 +1,23 @@
+package checker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewRunsResultHook(t *testing.T) {
+	c := New(Config{
+		Timeout: time.Second,
+		ResultHook: func(result *ProxyResult) {
+			result.Annotations = map[string]string{"source": "embedder"}
+		},
+	}, false)
+
+	result := c.Check("http://127.0.0.1:1")
+	if result.Working {
+		t.Fatal("Expected a closed port not to work")
+	}
+	if result.Annotations["source"] != "embedder" {
+		t.Errorf("Annotations = %v, want the hook's", result.Annotations)
+	}
+}
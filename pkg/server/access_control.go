@@ -0,0 +1,276 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/armon/go-socks5"
+)
+
+// ErrDestinationDenied is returned for connections to a destination in a
+// denied IP range
+var ErrDestinationDenied = errors.New("destination address is not allowed")
+
+// DefaultDeniedCIDRs are denied unless covered by AccessControlConfig.AllowCIDRs:
+// the private (RFC 1918, carrier-grade NAT and IPv6 unique local), loopback,
+// "this network" and link-local ranges, so clients of a shared proxy cannot
+// reach internal networks, services on the proxy host or cloud metadata endpoints
+var DefaultDeniedCIDRs = []string{
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"0.0.0.0/8",
+	"169.254.0.0/16",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+}
+
+// destinationDialTimeout bounds direct dials to a checked destination
+const destinationDialTimeout = 30 * time.Second
+
+// AccessControlConfig restricts the destinations clients of the SOCKS5 and
+// HTTP proxies may connect to. A destination in AllowCIDRs is always allowed;
+// otherwise it is denied when it falls in DenyCIDRs or DefaultDeniedCIDRs.
+type AccessControlConfig struct {
+	AllowCIDRs []string `yaml:"allow_cidrs"`
+	DenyCIDRs  []string `yaml:"deny_cidrs"`
+}
+
+// accessPolicy is the parsed form of an AccessControlConfig
+type accessPolicy struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// newAccessPolicy parses config; the default denied ranges are always included
+func newAccessPolicy(config AccessControlConfig) (*accessPolicy, error) {
+	allow, err := parseCIDRs(config.AllowCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allow_cidrs: %w", err)
+	}
+	deny, err := parseCIDRs(append(append([]string(nil), DefaultDeniedCIDRs...), config.DenyCIDRs...))
+	if err != nil {
+		return nil, fmt.Errorf("invalid deny_cidrs: %w", err)
+	}
+	return &accessPolicy{allow: allow, deny: deny}, nil
+}
+
+// ValidateAccessControl reports whether every range of config parses
+func ValidateAccessControl(config AccessControlConfig) error {
+	_, err := newAccessPolicy(config)
+	return err
+}
+
+// parseCIDRs parses CIDR ranges; a bare IP address is a single-address range
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if ip := net.ParseIP(cidr); ip != nil {
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// allowed reports whether connections to ip are allowed
+func (p *accessPolicy) allowed(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4 // Match IPv4-mapped IPv6 addresses against IPv4 ranges
+	}
+	for _, ipNet := range p.allow {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	for _, ipNet := range p.deny {
+		if ipNet.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// SetAccessControl replaces the destination access policy of the proxies
+func (r *ProxyRouter) SetAccessControl(config AccessControlConfig) error {
+	policy, err := newAccessPolicy(config)
+	if err != nil {
+		return err
+	}
+	r.access.Store(policy)
+	return nil
+}
+
+// BlockedConnections returns how many connections were rejected because of
+// their destination
+func (r *ProxyRouter) BlockedConnections() int64 {
+	return atomic.LoadInt64(&r.blocked)
+}
+
+// checkDestinationIP rejects ip if the access policy denies it
+func (r *ProxyRouter) checkDestinationIP(ip net.IP, target string) error {
+	if r.access.Load().allowed(ip) {
+		return nil
+	}
+	atomic.AddInt64(&r.blocked, 1)
+	r.logger.Warn("Blocked connection to denied destination", "target", target, "ip", ip.String())
+	return ErrDestinationDenied
+}
+
+// checkDestination resolves host and rejects it if any of its addresses is
+// denied, so a name with one internal address cannot be used to reach it
+func (r *ProxyRouter) checkDestination(ctx context.Context, host string) error {
+	_, err := r.resolveDestination(ctx, host)
+	return err
+}
+
+// resolveDestination returns the addresses of host once all of them passed
+// the access policy
+func (r *ProxyRouter) resolveDestination(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, r.checkDestinationIP(ip, host)
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		if err := r.checkDestinationIP(addr.IP, host); err != nil {
+			return nil, err
+		}
+		ips = append(ips, addr.IP)
+	}
+	return ips, nil
+}
+
+// dialDestination connects directly to addr. The host is resolved and checked
+// at dial time and one of the checked addresses is dialed, so a name that
+// resolved to a public address when the request was screened cannot be
+// rebound to a denied one.
+func (r *ProxyRouter) dialDestination(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := r.resolveDestination(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := net.Dialer{Timeout: destinationDialTimeout}
+	for _, ip := range ips {
+		var conn net.Conn
+		conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// upstreamContextKey holds the *upstreamChoice of an HTTP proxy request
+const upstreamContextKey routerContextKey = "upstream"
+
+// upstreamChoice records the upstream proxy picked for an HTTP proxy request,
+// so the transport can tell the dial to it from a direct dial to the
+// destination, which the access policy applies to
+type upstreamChoice struct {
+	addr atomic.Pointer[string]
+}
+
+// set records proxyURL as the upstream the transport will dial
+func (u *upstreamChoice) set(proxyURL *url.URL) {
+	port := proxyURL.Port()
+	if port == "" {
+		port = defaultProxyPorts[proxyURL.Scheme]
+	}
+	addr := net.JoinHostPort(proxyURL.Hostname(), port)
+	u.addr.Store(&addr)
+}
+
+// is reports whether addr is the recorded upstream
+func (u *upstreamChoice) is(addr string) bool {
+	upstream := u.addr.Load()
+	return upstream != nil && *upstream == addr
+}
+
+// defaultProxyPorts are the ports net/http dials for an upstream proxy URL
+// without one
+var defaultProxyPorts = map[string]string{
+	"http":   "80",
+	"https":  "443",
+	"socks5": "1080",
+}
+
+// dialTransport is the dialer of the HTTP proxy's transport. The dial to the
+// upstream proxy picked for the request goes out as-is; every other dial,
+// which is a direct one to the destination, goes through dialDestination.
+func (r *ProxyRouter) dialTransport(ctx context.Context, network, addr string) (net.Conn, error) {
+	if upstream, ok := ctx.Value(upstreamContextKey).(*upstreamChoice); ok && upstream.is(addr) {
+		dialer := net.Dialer{Timeout: destinationDialTimeout}
+		return dialer.DialContext(ctx, network, addr)
+	}
+	return r.dialDestination(ctx, network, addr)
+}
+
+// restrictDestinations answers 403 to HTTP proxy requests and CONNECT tunnels
+// for denied destinations. Direct dials are checked again when they are made.
+func (r *ProxyRouter) restrictDestinations(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		host := req.URL.Hostname()
+		if host == "" {
+			host, _, _ = net.SplitHostPort(req.Host)
+			if host == "" {
+				host = req.Host
+			}
+		}
+
+		if err := r.checkDestination(req.Context(), host); err != nil {
+			status := http.StatusForbidden
+			if !errors.Is(err, ErrDestinationDenied) {
+				status = http.StatusBadGateway
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), upstreamContextKey, &upstreamChoice{})))
+	})
+}
+
+// accessRules applies the access policy to SOCKS5 requests, which the
+// server rejects with a "connection not allowed by ruleset" reply
+type accessRules struct {
+	router *ProxyRouter
+}
+
+// Allow implements socks5.RuleSet. The destination is already resolved, and
+// the connection is dialed to the checked address.
+func (a *accessRules) Allow(ctx context.Context, req *socks5.Request) (context.Context, bool) {
+	// Pass the client IP on to the dialer
+	if req.RemoteAddr != nil {
+		ctx = context.WithValue(ctx, clientIPContextKey, req.RemoteAddr.IP.String())
+	}
+	if req.DestAddr == nil || req.DestAddr.IP == nil {
+		return ctx, false
+	}
+	return ctx, a.router.checkDestinationIP(req.DestAddr.IP, req.DestAddr.String()) == nil
+}
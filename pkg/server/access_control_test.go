@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/armon/go-socks5"
+)
+
+func TestAccessPolicy(t *testing.T) {
+	policy, err := newAccessPolicy(AccessControlConfig{
+		AllowCIDRs: []string{"10.20.0.0/16", "192.168.1.10"},
+		DenyCIDRs:  []string{"127.0.0.0/8", "203.0.113.0/24"},
+	})
+	if err != nil {
+		t.Fatalf("newAccessPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		ip      string
+		allowed bool
+	}{
+		{"10.1.2.3", false},        // RFC 1918, denied by default
+		{"172.16.5.4", false},      // RFC 1918
+		{"192.168.1.1", false},     // RFC 1918
+		{"169.254.169.254", false}, // Link-local metadata endpoint
+		{"fe80::1", false},         // IPv6 link-local
+		{"fd00::1", false},         // IPv6 unique local
+		{"::ffff:10.0.0.1", false}, // IPv4-mapped private address
+		{"100.64.1.1", false},      // Carrier-grade NAT
+		{"0.0.0.0", false},         // "This network", reaches the local host
+		{"::1", false},             // IPv6 loopback
+		{"10.20.30.40", true},      // Allowed range wins over the default deny
+		{"192.168.1.10", true},     // Allowed single address
+		{"127.0.0.1", false},       // Loopback, also configured
+		{"203.0.113.9", false},     // Configured deny
+		{"8.8.8.8", true},          // Public
+		{"2001:4860:4860::8888", true},
+	}
+	for _, tt := range tests {
+		if got := policy.allowed(net.ParseIP(tt.ip)); got != tt.allowed {
+			t.Errorf("allowed(%s) = %t, want %t", tt.ip, got, tt.allowed)
+		}
+	}
+
+	if err := ValidateAccessControl(AccessControlConfig{DenyCIDRs: []string{"10.0.0.0/33"}}); err == nil {
+		t.Error("Expected an invalid deny range to be rejected")
+	}
+	if err := ValidateAccessControl(AccessControlConfig{AllowCIDRs: []string{"internal"}}); err == nil {
+		t.Error("Expected an invalid allow range to be rejected")
+	}
+}
+
+func TestRestrictDestinations(t *testing.T) {
+	router := NewProxyRouter(NewProxyPoolManager(nil, StrategyRandom), StrategyRandom, nopLogger{})
+	handler := router.restrictDestinations(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		status int
+	}{
+		{"private HTTP target", http.MethodGet, "http://10.0.0.1/admin", http.StatusForbidden},
+		{"metadata endpoint", http.MethodGet, "http://169.254.169.254/latest/meta-data/", http.StatusForbidden},
+		{"private CONNECT target", http.MethodConnect, "192.168.1.1:443", http.StatusForbidden},
+		{"loopback target", http.MethodGet, "http://127.0.0.1:8080/", http.StatusForbidden},
+		{"public CONNECT target", http.MethodConnect, "8.8.8.8:443", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			if recorder.Code != tt.status {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.status)
+			}
+		})
+	}
+
+	if blocked := router.BlockedConnections(); blocked != 4 {
+		t.Errorf("BlockedConnections() = %d, want 4", blocked)
+	}
+}
+
+// TestDialDestination tests that direct dials are checked against the policy
+// when they are made, including names resolving to a denied address
+func TestDialDestination(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	router := NewProxyRouter(NewProxyPoolManager(nil, StrategyRandom), StrategyRandom, nopLogger{})
+	for _, addr := range []string{listener.Addr().String(), net.JoinHostPort("localhost", port)} {
+		if _, err := router.dialDestination(context.Background(), "tcp", addr); !errors.Is(err, ErrDestinationDenied) {
+			t.Errorf("dialDestination(%s) error = %v, want ErrDestinationDenied", addr, err)
+		}
+	}
+
+	// The transport dials the upstream picked for the request as-is
+	upstream := &upstreamChoice{}
+	upstream.set(&url.URL{Scheme: "http", Host: listener.Addr().String()})
+	ctx := context.WithValue(context.Background(), upstreamContextKey, upstream)
+	conn, err := router.dialTransport(ctx, "tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dialTransport() to the upstream error = %v", err)
+	}
+	conn.Close()
+
+	if err := router.SetAccessControl(AccessControlConfig{AllowCIDRs: []string{"127.0.0.1"}}); err != nil {
+		t.Fatalf("SetAccessControl() error = %v", err)
+	}
+	conn, err = router.dialDestination(context.Background(), "tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dialDestination() to an allowed address error = %v", err)
+	}
+	conn.Close()
+}
+
+// TestSOCKS5AccessRules tests that a SOCKS5 CONNECT to a denied address is
+// answered with the "not allowed by ruleset" reply without being dialed
+func TestSOCKS5AccessRules(t *testing.T) {
+	router := NewProxyRouter(NewProxyPoolManager(nil, StrategyRandom), StrategyRandom, nopLogger{})
+	dialed := make(chan string, 1)
+	server, err := socks5.New(&socks5.Config{
+		Rules: &accessRules{router: router},
+		Dial: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed <- addr
+			return nil, io.EOF
+		},
+	})
+	if err != nil {
+		t.Fatalf("socks5.New() error = %v", err)
+	}
+
+	client, serverConn := net.Pipe()
+	defer client.Close()
+	go server.ServeConn(serverConn)
+
+	// Greeting without authentication, then CONNECT 10.0.0.1:80
+	client.Write([]byte{5, 1, 0})
+	greeting := make([]byte, 2)
+	if _, err := io.ReadFull(client, greeting); err != nil {
+		t.Fatalf("Failed to read the greeting reply: %v", err)
+	}
+	client.Write([]byte{5, 1, 0, 1, 10, 0, 0, 1, 0, 80})
+	reply := make([]byte, 10)
+	if _, err := io.ReadFull(client, reply); err != nil {
+		t.Fatalf("Failed to read the CONNECT reply: %v", err)
+	}
+
+	if reply[1] != 2 {
+		t.Errorf("CONNECT reply code = %d, want 2 (not allowed by ruleset)", reply[1])
+	}
+	select {
+	case addr := <-dialed:
+		t.Errorf("Denied destination %s was dialed", addr)
+	default:
+	}
+	if blocked := router.BlockedConnections(); blocked != 1 {
+		t.Errorf("BlockedConnections() = %d, want 1", blocked)
+	}
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/output"
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/proxy"
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/validation"
+)
+
+const (
+	// defaultCheckTimeout is the per-request timeout of an on-demand check
+	defaultCheckTimeout = 10 * time.Second
+	// maxCheckTimeout caps the timeout a caller may ask for
+	maxCheckTimeout = 60 * time.Second
+	// checkDeadlineFactor bounds a whole check, which makes several
+	// requests, to this many per-request timeouts
+	checkDeadlineFactor = 3
+	// defaultCheckTestURL is fetched through the proxy when no test_url is given
+	defaultCheckTestURL = "https://api.ipify.org?format=json"
+	// maxCheckRequestSize limits the body of a check request
+	maxCheckRequestSize = 64 * 1024
+	// maxConcurrentChecks caps the checks running at once, including
+	// abandoned ones still finishing in the background
+	maxConcurrentChecks = 8
+)
+
+// CheckRequest is the body of POST /api/check
+type CheckRequest struct {
+	Proxy   string `json:"proxy"`              // Proxy URL, e.g. socks5://1.2.3.4:1080
+	TestURL string `json:"test_url,omitempty"` // URL fetched through the proxy (default: defaultCheckTestURL)
+	Timeout string `json:"timeout,omitempty"`  // Per-request timeout, e.g. "5s" (default: defaultCheckTimeout)
+}
+
+// handleCheck handles POST /api/check: it checks one proxy with the
+// standard proxy checker and returns the result in the JSON output format.
+// Malformed requests, including loopback and multicast proxy addresses, get
+// a 400. Callers must present the API token, or connect from loopback when
+// none is configured, and at most maxConcurrentChecks checks run at once.
+func (s *WebSocketService) handleCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSONError(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+	if !s.authorizeCheck(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="proxyhawk"`)
+		writeJSONError(w, http.StatusUnauthorized, "missing or invalid API token")
+		return
+	}
+
+	var req CheckRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxCheckRequestSize)).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+
+	proxyURL, err := validation.NewProxyValidator().NormalizeProxyURL(req.Proxy)
+	if req.Proxy == "" || err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid proxy URL %q: %v", req.Proxy, err))
+		return
+	}
+
+	testURL := defaultCheckTestURL
+	if req.TestURL != "" {
+		parsed, err := url.Parse(req.TestURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid test_url %q: must be an http or https URL", req.TestURL))
+			return
+		}
+		testURL = req.TestURL
+	}
+
+	timeout := defaultCheckTimeout
+	if req.Timeout != "" {
+		timeout, err = time.ParseDuration(req.Timeout)
+		if err != nil || timeout <= 0 {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid timeout %q", req.Timeout))
+			return
+		}
+		if timeout > maxCheckTimeout {
+			timeout = maxCheckTimeout
+		}
+	}
+
+	checker := proxy.NewChecker(proxy.Config{
+		Timeout:       timeout,
+		ValidationURL: testURL,
+	}, false, nil)
+
+	select {
+	case s.checkSlots <- struct{}{}:
+	default:
+		w.Header().Set("Retry-After", "1")
+		writeJSONError(w, http.StatusServiceUnavailable, "too many checks in progress")
+		return
+	}
+
+	// The checker cannot be cancelled, so an abandoned check finishes in the
+	// background, bounded by its own request timeouts, and keeps its slot
+	// until then
+	done := make(chan *proxy.ProxyResult, 1)
+	go func() {
+		defer func() { <-s.checkSlots }()
+		done <- checker.Check(proxyURL)
+	}()
+
+	select {
+	case result := <-done:
+		s.logger.Info("Checked proxy on demand", "proxy", proxy.RedactProxyURL(proxyURL), "working", result.Working)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(output.ConvertToOutputFormat([]*proxy.ProxyResult{result})[0])
+	case <-time.After(checkDeadlineFactor * timeout):
+		writeJSONError(w, http.StatusGatewayTimeout, fmt.Sprintf("check did not finish within %v", checkDeadlineFactor*timeout))
+	case <-r.Context().Done():
+		// The client went away; nobody is left to answer
+	}
+}
+
+// authorizeCheck reports whether r may run checks: with an API token
+// configured it must carry it as a bearer token, otherwise it must come
+// from a loopback address
+func (s *WebSocketService) authorizeCheck(r *http.Request) bool {
+	if s.apiToken != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.apiToken)) == 1
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// writeJSONError writes an API error as {"error": message}
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
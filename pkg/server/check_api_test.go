@@ -0,0 +1,118 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// nopLogger discards log output
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (nopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (nopLogger) Error(msg string, keysAndValues ...interface{}) {}
+
+func TestHandleCheck(t *testing.T) {
+	service := NewWebSocketService(nil, nil, nopLogger{})
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+	}{
+		{"wrong method", http.MethodGet, "", http.StatusMethodNotAllowed},
+		{"malformed body", http.MethodPost, "{", http.StatusBadRequest},
+		{"missing proxy", http.MethodPost, `{}`, http.StatusBadRequest},
+		{"malformed proxy", http.MethodPost, `{"proxy":"ftp://1.2.3.4:21"}`, http.StatusBadRequest},
+		{"malformed test URL", http.MethodPost, `{"proxy":"http://1.2.3.4:8080","test_url":"file:///etc/passwd"}`, http.StatusBadRequest},
+		{"malformed timeout", http.MethodPost, `{"proxy":"http://1.2.3.4:8080","timeout":"soon"}`, http.StatusBadRequest},
+		{"loopback proxy", http.MethodPost, `{"proxy":"http://127.0.0.1:8080"}`, http.StatusBadRequest},
+		{"unreachable proxy", http.MethodPost, `{"proxy":"http://192.0.2.1:8080","test_url":"http://example.com/","timeout":"200ms"}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "/api/check", strings.NewReader(tt.body))
+			req.RemoteAddr = "127.0.0.1:40000"
+			service.handleCheck(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+
+			var body map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Expected a JSON body, got %q", rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if body["error"] == nil {
+					t.Errorf("Expected an error message, got %v", body)
+				}
+				return
+			}
+			if body["working"] != false || body["proxy"] != "http://192.0.2.1:8080" || body["error"] == nil {
+				t.Errorf("Expected a failed check result, got %v", body)
+			}
+		})
+	}
+}
+
+func TestHandleCheckAuthorization(t *testing.T) {
+	const body = `{"proxy":"ftp://1.2.3.4:21"}` // Rejected with a 400 once authorized
+
+	tests := []struct {
+		name       string
+		token      string
+		remoteAddr string
+		header     string
+		wantStatus int
+	}{
+		{"loopback without token", "", "127.0.0.1:40000", "", http.StatusBadRequest},
+		{"IPv6 loopback without token", "", "[::1]:40000", "", http.StatusBadRequest},
+		{"remote without token", "", "192.0.2.10:40000", "", http.StatusUnauthorized},
+		{"remote with token", "s3cret", "192.0.2.10:40000", "Bearer s3cret", http.StatusBadRequest},
+		{"wrong token", "s3cret", "192.0.2.10:40000", "Bearer guess", http.StatusUnauthorized},
+		{"missing token", "s3cret", "127.0.0.1:40000", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewWebSocketService(nil, nil, nopLogger{})
+			service.apiToken = tt.token
+
+			req := httptest.NewRequest(http.MethodPost, "/api/check", strings.NewReader(body))
+			req.RemoteAddr = tt.remoteAddr
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			service.handleCheck(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleCheckConcurrencyLimit(t *testing.T) {
+	service := NewWebSocketService(nil, nil, nopLogger{})
+	for i := 0; i < maxConcurrentChecks; i++ {
+		service.checkSlots <- struct{}{}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/check", strings.NewReader(`{"proxy":"http://192.0.2.1:8080"}`))
+	req.RemoteAddr = "127.0.0.1:40000"
+	rec := httptest.NewRecorder()
+	service.handleCheck(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status %d with every slot taken, got %d: %s", http.StatusServiceUnavailable, rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Expected a Retry-After header")
+	}
+}
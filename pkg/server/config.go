@@ -13,6 +13,7 @@ type ServerConfig struct {
 	Server   ServerSettings            `yaml:"server"`
 	Regions  map[string]*RegionConfig  `yaml:"regions"`
 	Selection SelectionSettings        `yaml:"selection"`
+	AccessControl AccessControlConfig  `yaml:"access_control"`
 	RoundRobin RoundRobinSettings       `yaml:"round_robin_detection"`
 	HealthCheck HealthCheckSettings     `yaml:"health_check"`
 	Cache    CacheSettings             `yaml:"cache"`
@@ -23,6 +24,9 @@ type ServerConfig struct {
 type ServerSettings struct {
 	Proxy ProxySettings `yaml:"proxy"`
 	API   APISettings   `yaml:"api"`
+	
+	// How long shutdown waits for in-flight proxied connections (default 30s)
+	ShutdownGracePeriod time.Duration `yaml:"shutdown_grace_period"`
 }
 
 // ProxySettings holds proxy server configuration
@@ -53,6 +57,7 @@ type HTTPSettings struct {
 type APISettings struct {
 	Enabled bool        `yaml:"enabled"`
 	Address string      `yaml:"address"`
+	Token   string      `yaml:"token"`
 	CORS    CORSSettings `yaml:"cors"`
 	WebSocket WSSettings `yaml:"websocket"`
 }
@@ -74,6 +79,7 @@ type WSSettings struct {
 // SelectionSettings holds proxy selection configuration
 type SelectionSettings struct {
 	Strategy string       `yaml:"strategy"`
+	HashKey  string       `yaml:"hash_key"` // Header identifying clients for consistent-hash (empty = client IP)
 	Smart    SmartSettings `yaml:"smart"`
 	Sticky   StickySettings `yaml:"sticky"`
 }
@@ -110,6 +116,7 @@ type HealthCheckSettings struct {
 	Timeout          time.Duration `yaml:"timeout"`
 	FailureThreshold int           `yaml:"failure_threshold"`
 	SuccessThreshold int           `yaml:"success_threshold"`
+	MaxBackoff       time.Duration `yaml:"max_backoff"`
 }
 
 // CacheSettings holds cache configuration
@@ -150,9 +157,13 @@ func LoadConfig(filename string) (*Config, error) {
 		SOCKS5Addr: serverConfig.Server.Proxy.SOCKS5.Address,
 		HTTPAddr:   serverConfig.Server.Proxy.HTTP.Address,
 		APIAddr:    serverConfig.Server.API.Address,
+		APIToken:   serverConfig.Server.API.Token,
 		Regions:    serverConfig.Regions,
 		
 		SelectionStrategy: SelectionStrategy(serverConfig.Selection.Strategy),
+		HashKey:           serverConfig.Selection.HashKey,
+		
+		AccessControl: serverConfig.AccessControl,
 		
 		RoundRobinDetection: RoundRobinConfig{
 			Enabled:             serverConfig.RoundRobin.Enabled,
@@ -167,6 +178,7 @@ func LoadConfig(filename string) (*Config, error) {
 			Timeout:          serverConfig.HealthCheck.Timeout,
 			FailureThreshold: serverConfig.HealthCheck.FailureThreshold,
 			SuccessThreshold: serverConfig.HealthCheck.SuccessThreshold,
+			MaxBackoff:       serverConfig.HealthCheck.MaxBackoff,
 		},
 		
 		CacheConfig: CacheConfig{
@@ -175,6 +187,8 @@ func LoadConfig(filename string) (*Config, error) {
 			MaxEntries: serverConfig.Cache.MaxEntries,
 		},
 		
+		ShutdownGracePeriod: serverConfig.Server.ShutdownGracePeriod,
+		
 		LogLevel:  serverConfig.Logging.Level,
 		LogFormat: serverConfig.Logging.Format,
 	}
@@ -200,6 +214,7 @@ func SaveConfig(config *Config, filename string) error {
 			API: APISettings{
 				Enabled: true,
 				Address: config.APIAddr,
+				Token:   config.APIToken,
 				CORS: CORSSettings{
 					Enabled: true,
 					Origins: []string{"http://localhost:*"},
@@ -211,12 +226,14 @@ func SaveConfig(config *Config, filename string) error {
 					PingInterval:   30 * time.Second,
 				},
 			},
+			ShutdownGracePeriod: config.ShutdownGracePeriod,
 		},
 		
 		Regions: config.Regions,
 		
 		Selection: SelectionSettings{
 			Strategy: string(config.SelectionStrategy),
+			HashKey:  config.HashKey,
 			Smart: SmartSettings{
 				CDNDetection: true,
 				GeoIPLookup:  true,
@@ -227,6 +244,8 @@ func SaveConfig(config *Config, filename string) error {
 			},
 		},
 		
+		AccessControl: config.AccessControl,
+		
 		RoundRobin: RoundRobinSettings{
 			Enabled:             config.RoundRobinDetection.Enabled,
 			MinSamples:          config.RoundRobinDetection.MinSamples,
@@ -240,6 +259,7 @@ func SaveConfig(config *Config, filename string) error {
 			Timeout:          config.HealthCheck.Timeout,
 			FailureThreshold: config.HealthCheck.FailureThreshold,
 			SuccessThreshold: config.HealthCheck.SuccessThreshold,
+			MaxBackoff:       config.HealthCheck.MaxBackoff,
 		},
 		
 		Cache: CacheSettings{
@@ -297,6 +317,14 @@ func ValidateConfig(config *Config) error {
 			return fmt.Errorf("region %s must have at least one proxy", name)
 		}
 		
+		if region.MaxConcurrent < 0 {
+			return fmt.Errorf("region %s max concurrent must not be negative", name)
+		}
+		
+		if region.QueueTimeout < 0 {
+			return fmt.Errorf("region %s queue timeout must not be negative", name)
+		}
+		
 		// Validate each proxy
 		for i, proxy := range region.Proxies {
 			if proxy.URL == "" {
@@ -311,12 +339,17 @@ func ValidateConfig(config *Config) error {
 	
 	// Validate selection strategy
 	switch config.SelectionStrategy {
-	case StrategyRoundRobin, StrategyRandom, StrategyWeighted, StrategySmart, StrategySticky:
+	case StrategyRoundRobin, StrategyRandom, StrategyWeighted, StrategySmart, StrategySticky, StrategyLeastConn, StrategyConsistentHash:
 		// Valid strategies
 	default:
 		return fmt.Errorf("invalid selection strategy: %s", config.SelectionStrategy)
 	}
 	
+	// Validate destination access control
+	if err := ValidateAccessControl(config.AccessControl); err != nil {
+		return fmt.Errorf("access control: %w", err)
+	}
+	
 	// Validate health check settings
 	if config.HealthCheck.Enabled {
 		if config.HealthCheck.Interval <= 0 {
@@ -334,6 +367,10 @@ func ValidateConfig(config *Config) error {
 		if config.HealthCheck.SuccessThreshold <= 0 {
 			return fmt.Errorf("health check success threshold must be positive")
 		}
+		
+		if config.HealthCheck.MaxBackoff < 0 {
+			return fmt.Errorf("health check max backoff must not be negative")
+		}
 	}
 	
 	// Validate cache settings
@@ -370,6 +407,9 @@ func GetDefaultConfigYAML() string {
 	return `# ProxyHawk Dual-Mode Server Configuration
 
 server:
+  # Wait this long for in-flight proxied connections on shutdown, then close them
+  shutdown_grace_period: "30s"
+  
   # Proxy server settings
   proxy:
     socks5:
@@ -388,7 +428,9 @@ server:
   # WebSocket/API server
   api:
     enabled: true
-    address: ":8888"
+    address: "127.0.0.1:8888"
+    # Bearer token for POST /api/check; without one only loopback clients may use it
+    token: ""
     cors:
       enabled: true
       origins: ["http://localhost:*"]
@@ -409,6 +451,8 @@ regions:
         health_check_url: "http://httpbin.org/ip"
       - url: "http://us-west-2.proxy.example.com:8080"
         weight: 5
+    max_concurrent: 0  # Cap on simultaneous connections to the region (0 = unlimited)
+    queue_timeout: 2s  # How long excess connections wait for a slot before being rejected
         
   us_east:
     name: "US East Coast"
@@ -432,7 +476,8 @@ regions:
 
 # Proxy selection strategies
 selection:
-  strategy: "smart"  # Options: smart, round-robin, random, sticky, weighted
+  strategy: "smart"  # Options: smart, round-robin, random, sticky, weighted, least-conn, consistent-hash
+  hash_key: ""       # consistent-hash: header identifying a client (empty = client IP)
   
   smart:
     # Automatically select region based on target
@@ -444,6 +489,12 @@ selection:
     session_duration: "5m"
     by_domain: true
 
+# Destinations proxy clients may reach (SSRF protection). Private (RFC 1918,
+# fc00::/7) and link-local ranges are denied unless listed in allow_cidrs.
+access_control:
+  allow_cidrs: []  # Always allowed, e.g. ["10.20.0.0/16"]
+  deny_cidrs: []   # Denied in addition to the default ranges, e.g. ["127.0.0.0/8"]
+
 # Round-robin detection
 round_robin_detection:
   enabled: true
@@ -462,8 +513,9 @@ health_check:
   enabled: true
   interval: "1m"
   timeout: "10s"
-  failure_threshold: 3
-  success_threshold: 2
+  failure_threshold: 3   # Consecutive failures before a proxy is taken out of rotation
+  success_threshold: 2   # Half-open probes in a row that must pass before it is put back
+  max_backoff: "5m"      # Unhealthy proxies are probed at a doubling interval, capped here
 
 # Global cache settings
 cache:
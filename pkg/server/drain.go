@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultShutdownGracePeriod is how long shutdown waits for active tunnels
+// to close before terminating them
+const DefaultShutdownGracePeriod = 30 * time.Second
+
+// DrainStats reports how the connections open at shutdown ended
+type DrainStats struct {
+	Drained int // Closed by their peers within the grace period
+	Killed  int // Still open at the end of the grace period and closed forcibly
+}
+
+// connTracker tracks the client connections accepted by the proxy servers
+// so shutdown can wait for them and close whatever is left. While draining it
+// counts connections as they close, so the metrics follow the drain live.
+type connTracker struct {
+	mutex    sync.Mutex
+	conns    map[*trackedClientConn]struct{}
+	draining bool
+	idle     chan struct{} // Closed when the last connection closes during a drain
+	active   int64         // Updated atomically
+	drained  int64         // Updated atomically
+	killed   int64         // Updated atomically
+}
+
+// newConnTracker creates an empty connTracker
+func newConnTracker() *connTracker {
+	return &connTracker{conns: make(map[*trackedClientConn]struct{})}
+}
+
+// listener wraps ln so every accepted connection is tracked until closed
+func (t *connTracker) listener(ln net.Listener) net.Listener {
+	return &trackingListener{Listener: ln, tracker: t}
+}
+
+// Active returns the number of open client connections
+func (t *connTracker) Active() int64 {
+	return atomic.LoadInt64(&t.active)
+}
+
+// Stats returns how many connections closed on their own and how many were
+// closed forcibly since draining started
+func (t *connTracker) Stats() DrainStats {
+	return DrainStats{
+		Drained: int(atomic.LoadInt64(&t.drained)),
+		Killed:  int(atomic.LoadInt64(&t.killed)),
+	}
+}
+
+// isDraining reports whether drain was called
+func (t *connTracker) isDraining() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.draining
+}
+
+// add starts tracking conn
+func (t *connTracker) add(conn net.Conn) net.Conn {
+	tracked := &trackedClientConn{Conn: conn, tracker: t}
+	atomic.AddInt64(&t.active, 1)
+	t.mutex.Lock()
+	t.conns[tracked] = struct{}{}
+	t.mutex.Unlock()
+	return tracked
+}
+
+// remove stops tracking conn
+func (t *connTracker) remove(conn *trackedClientConn) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.conns, conn)
+	atomic.AddInt64(&t.active, -1)
+	if !t.draining {
+		return
+	}
+	if conn.killed {
+		atomic.AddInt64(&t.killed, 1)
+	} else {
+		atomic.AddInt64(&t.drained, 1)
+	}
+	if len(t.conns) == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// drain waits until every tracked connection is closed or ctx is done, then
+// closes the connections still open. New connections must no longer be
+// accepted when it is called.
+func (t *connTracker) drain(ctx context.Context) DrainStats {
+	t.mutex.Lock()
+	t.draining = true
+	if len(t.conns) == 0 {
+		t.mutex.Unlock()
+		return t.Stats()
+	}
+	idle := make(chan struct{})
+	t.idle = idle
+	t.mutex.Unlock()
+
+	select {
+	case <-idle:
+		return t.Stats()
+	case <-ctx.Done():
+	}
+
+	t.mutex.Lock()
+	remaining := make([]*trackedClientConn, 0, len(t.conns))
+	for conn := range t.conns {
+		conn.killed = true
+		remaining = append(remaining, conn)
+	}
+	t.mutex.Unlock()
+
+	for _, conn := range remaining {
+		conn.Close()
+	}
+	return t.Stats()
+}
+
+// trackingListener tracks the connections it accepts
+type trackingListener struct {
+	net.Listener
+	tracker *connTracker
+}
+
+// Accept implements net.Listener
+func (l *trackingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return l.tracker.add(conn), nil
+}
+
+// trackedClientConn stops being tracked once it is closed
+type trackedClientConn struct {
+	net.Conn
+	tracker   *connTracker
+	closeOnce sync.Once
+	killed    bool // Set under the tracker's mutex when drain closes it
+}
+
+// Close implements net.Conn
+func (c *trackedClientConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(func() {
+		c.tracker.remove(c)
+	})
+	return err
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestConnTrackerDrain(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	tracker := newConnTracker()
+	listener := tracker.listener(ln)
+	defer listener.Close()
+
+	// Server side: connections close once their client hangs up
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+			go func() {
+				io.Copy(io.Discard, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	polite, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	stubborn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer stubborn.Close()
+	<-accepted
+	<-accepted
+
+	if got := tracker.Active(); got != 2 {
+		t.Fatalf("Active() = %d, want 2", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		polite.Close()
+	}()
+
+	stats := tracker.drain(ctx)
+	if stats.Drained != 1 || stats.Killed != 1 {
+		t.Errorf("drain() = %+v, want 1 drained and 1 killed", stats)
+	}
+	if got := tracker.Active(); got != 0 {
+		t.Errorf("Active() = %d after drain, want 0", got)
+	}
+
+	// The killed connection was closed on the server side
+	stubborn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := stubborn.Read(make([]byte, 1)); err == nil {
+		t.Error("killed connection is still open")
+	}
+}
+
+func TestConnTrackerDrainIdle(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if stats := newConnTracker().drain(ctx); stats != (DrainStats{}) {
+		t.Errorf("drain() = %+v, want nothing drained or killed", stats)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("drain() of an idle tracker took %v, want it to return immediately", elapsed)
+	}
+}
+
+func TestProxyRouterDrainStopsAccepting(t *testing.T) {
+	router := NewProxyRouter(NewProxyPoolManager(nil, StrategyRandom), StrategyRandom, nopLogger{})
+
+	listener, err := router.listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	router.Drain(ctx)
+
+	if _, err := listener.Accept(); err == nil {
+		t.Error("Accept() succeeded after Drain")
+	}
+	if _, err := router.listen("127.0.0.1:0"); err == nil {
+		t.Error("listen() succeeded after Drain")
+	}
+}
+
+func TestConnTrackerDrainCountsLive(t *testing.T) {
+	tracker := newConnTracker()
+	first := tracker.add(&nopConn{})
+	second := tracker.add(&nopConn{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	done := make(chan DrainStats)
+	go func() { done <- tracker.drain(ctx) }()
+
+	// Wait for the drain to start, then close one connection
+	for !tracker.isDraining() {
+		time.Sleep(time.Millisecond)
+	}
+	first.Close()
+	if got := tracker.Stats(); got.Drained != 1 || got.Killed != 0 {
+		t.Errorf("Stats() = %+v during the drain, want 1 drained", got)
+	}
+
+	// A connection accepted as the listeners close joins the drain
+	late := tracker.add(&nopConn{})
+	second.Close()
+	late.Close()
+	if stats := <-done; stats.Drained != 3 || stats.Killed != 0 {
+		t.Errorf("drain() = %+v, want 3 drained", stats)
+	}
+}
+
+// nopConn is a net.Conn whose Close does nothing
+type nopConn struct{ net.Conn }
+
+func (*nopConn) Close() error { return nil }
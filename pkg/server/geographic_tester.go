@@ -116,6 +116,18 @@ func (gt *GeographicTester) TestDomain(domain string, regions []string) *GeoTest
 	// Analyze results
 	gt.analyzeResults(result)
 	
+	// Remember the detection on the proxies that were used, for /api/proxies
+	for _, regionResult := range result.RegionResults {
+		if regionResult.ProxyUsed != "" {
+			gt.poolManager.RecordRoundRobinDetection(regionResult.ProxyUsed, RoundRobinStatus{
+				Domain:       result.Domain,
+				IsRoundRobin: result.IsRoundRobin,
+				Confidence:   result.Confidence,
+				TestedAt:     result.TestedAt,
+			})
+		}
+	}
+	
 	return result
 }
 
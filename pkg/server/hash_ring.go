@@ -0,0 +1,99 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// hashRingReplicas is how many points each proxy gets on a hash ring; more
+// points spread keys more evenly across the proxies
+const hashRingReplicas = 100
+
+// hashRing maps session keys to proxies by consistent hashing. A proxy's
+// points depend only on its own identity, so adding or removing a proxy only
+// moves the keys that hash next to its points; every other key keeps its proxy.
+type hashRing struct {
+	members string       // Identities of the proxies on the ring, to detect changes
+	points  []uint64     // Sorted point hashes
+	owners  []*ProxyInfo // owners[i] owns points[i]
+}
+
+// newHashRing builds a ring over proxies
+func newHashRing(proxies []*ProxyInfo) *hashRing {
+	ring := &hashRing{members: ringMembers(proxies)}
+
+	type point struct {
+		hash  uint64
+		owner *ProxyInfo
+	}
+	points := make([]point, 0, len(proxies)*hashRingReplicas)
+	for _, proxy := range proxies {
+		id := proxyIdentity(proxy)
+		for i := 0; i < hashRingReplicas; i++ {
+			points = append(points, point{ringHash(id + "#" + strconv.Itoa(i)), proxy})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].hash < points[j].hash })
+
+	ring.points = make([]uint64, len(points))
+	ring.owners = make([]*ProxyInfo, len(points))
+	for i, p := range points {
+		ring.points[i] = p.hash
+		ring.owners[i] = p.owner
+	}
+	return ring
+}
+
+// get returns the proxy owning the first point at or after the key's hash,
+// wrapping around the ring
+func (r *hashRing) get(key string) *ProxyInfo {
+	if len(r.points) == 0 {
+		return nil
+	}
+	hash := ringHash(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= hash })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[i]
+}
+
+// hashRingFor returns the pool's ring over proxies, rebuilding it when the
+// set of proxies changed since the last call, e.g. after a health check
+func (pool *RegionPool) hashRingFor(proxies []*ProxyInfo) *hashRing {
+	pool.ringMutex.Lock()
+	defer pool.ringMutex.Unlock()
+
+	if pool.ring == nil || pool.ring.members != ringMembers(proxies) {
+		pool.ring = newHashRing(proxies)
+	}
+	return pool.ring
+}
+
+// ringMembers identifies a set of proxies independent of their order
+func ringMembers(proxies []*ProxyInfo) string {
+	ids := make([]string, len(proxies))
+	for i, proxy := range proxies {
+		ids[i] = proxyIdentity(proxy)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, "\n")
+}
+
+// proxyIdentity names a proxy on the ring: its URL, or its chain for chained proxies
+func proxyIdentity(proxy *ProxyInfo) string {
+	if proxy.URL != "" {
+		return proxy.URL
+	}
+	return strings.Join(proxy.Chain, ",")
+}
+
+// ringHash hashes a key or point name onto the ring. A cryptographic hash
+// keeps the points of similar names, like "url#1" and "url#2", well spread.
+func ringHash(s string) uint64 {
+	sum := sha256.Sum256([]byte(s))
+	return binary.BigEndian.Uint64(sum[:8])
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func consistentHashManager(urls ...string) *ProxyPoolManager {
+	proxies := make([]ProxyConfig, len(urls))
+	for i, url := range urls {
+		proxies[i] = ProxyConfig{URL: url, Weight: 1}
+	}
+	return NewProxyPoolManager(map[string]*RegionConfig{
+		"us": {Name: "us", Proxies: proxies},
+	}, StrategyConsistentHash)
+}
+
+func TestConsistentHashAffinity(t *testing.T) {
+	manager := consistentHashManager("socks5://192.0.2.1:1080", "socks5://192.0.2.2:1080", "socks5://192.0.2.3:1080")
+
+	used := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("198.51.100.%d", i)
+		first := manager.GetHealthyProxyForKey("us", key)
+		for j := 0; j < 3; j++ {
+			if again := manager.GetHealthyProxyForKey("us", key); again != first {
+				t.Fatalf("key %s moved from %s to %s", key, first.URL, again.URL)
+			}
+		}
+		used[first.URL] = true
+	}
+	if len(used) != 3 {
+		t.Errorf("50 keys used %d of 3 proxies, want all", len(used))
+	}
+}
+
+func TestConsistentHashMinimalRebalance(t *testing.T) {
+	manager := consistentHashManager("http://192.0.2.1:8080", "http://192.0.2.2:8080", "http://192.0.2.3:8080", "http://192.0.2.4:8080")
+
+	before := map[string]*ProxyInfo{}
+	for i := 0; i < 200; i++ {
+		key := fmt.Sprintf("session-%d", i)
+		before[key] = manager.GetHealthyProxyForKey("us", key)
+	}
+
+	// A failed health check takes one proxy off the ring
+	failed := manager.regions["us"].Proxies[1]
+	failed.IsHealthy = false
+
+	for key, previous := range before {
+		current := manager.GetHealthyProxyForKey("us", key)
+		if current == failed {
+			t.Fatalf("key %s still maps to the unhealthy proxy", key)
+		}
+		if previous != failed && current != previous {
+			t.Errorf("key %s moved from healthy %s to %s", key, previous.URL, current.URL)
+		}
+	}
+
+	// Recovery moves the keys back
+	failed.IsHealthy = true
+	for key, previous := range before {
+		if current := manager.GetHealthyProxyForKey("us", key); current != previous {
+			t.Errorf("key %s maps to %s after recovery, want %s", key, current.URL, previous.URL)
+		}
+	}
+}
+
+func TestConsistentHashSharedRing(t *testing.T) {
+	manager := consistentHashManager("socks5://192.0.2.1:1080", "socks5://192.0.2.2:1080", "socks5://192.0.2.3:1080")
+	pool := manager.regions["us"]
+	pool.Proxies[2].IsHealthy = false
+
+	// The HTTP path and the dial path agree and keep using one ring
+	key := "198.51.100.7"
+	healthy := manager.GetHealthyProxyForKey("us", key)
+	ring := pool.ring
+	for i := 0; i < 3; i++ {
+		if got := manager.GetProxyForKey("us", key); got != healthy {
+			t.Fatalf("GetProxyForKey() = %s, want %s like GetHealthyProxyForKey", got.URL, healthy.URL)
+		}
+		manager.GetHealthyProxyForKey("us", key)
+	}
+	if pool.ring != ring {
+		t.Error("alternating lookups rebuilt the ring")
+	}
+}
+
+func TestRouterHashKey(t *testing.T) {
+	router := &ProxyRouter{config: &RouterConfig{}}
+	req, _ := http.NewRequest("GET", "http://example.com/", nil)
+	req.RemoteAddr = "203.0.113.9:51234"
+	req.Header.Set("X-Session-ID", "abc")
+
+	if got := router.hashKey(req); got != "203.0.113.9" {
+		t.Errorf("hashKey() = %q without a header configured, want the client IP", got)
+	}
+
+	router.SetHashKey("X-Session-ID")
+	if got := router.hashKey(req); got != "abc" {
+		t.Errorf("hashKey() = %q, want the header value", got)
+	}
+
+	req.Header.Del("X-Session-ID")
+	if got := router.hashKey(req); got != "203.0.113.9" {
+		t.Errorf("hashKey() = %q without the header, want the client IP", got)
+	}
+}
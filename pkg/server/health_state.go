@@ -0,0 +1,148 @@
+package server
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/proxy"
+)
+
+// DefaultHealthCheckMaxBackoff caps the backoff between checks of an
+// unhealthy proxy when HealthCheckConfig.MaxBackoff is unset
+const DefaultHealthCheckMaxBackoff = 5 * time.Minute
+
+// healthBackoffJitter scales each backoff by a random factor in [0.75, 1.25)
+// so proxies that failed together are not all probed at the same moment
+var healthBackoffJitter = func() float64 {
+	return 0.75 + 0.5*rand.Float64()
+}
+
+// HealthState is the circuit breaker state of a proxy
+type HealthState string
+
+const (
+	// HealthStateHealthy proxies receive traffic and are checked every interval
+	HealthStateHealthy HealthState = "healthy"
+	// HealthStateUnhealthy proxies receive no traffic and are checked with an
+	// exponential backoff while they keep failing
+	HealthStateUnhealthy HealthState = "unhealthy"
+	// HealthStateHalfOpen proxies passed a probe after being unhealthy; they
+	// receive no traffic until SuccessThreshold probes in a row succeed
+	HealthStateHalfOpen HealthState = "half-open"
+)
+
+// ProxyHealth reports the health state of a proxy
+type ProxyHealth struct {
+	URL                 string      `json:"url"`
+	State               HealthState `json:"state"`
+	ConsecutiveFailures int         `json:"consecutive_failures"`
+	LastCheck           time.Time   `json:"last_check,omitempty"`
+	NextCheck           time.Time   `json:"next_check,omitempty"`
+}
+
+// healthCheckDue reports whether the proxy should be checked at now. An
+// unhealthy proxy whose backoff elapsed moves to half-open, so the check
+// becomes the single probe deciding whether it recovered. The caller holds
+// the proxy's lock.
+func (p *ProxyInfo) healthCheckDue(now time.Time) bool {
+	if now.Before(p.NextHealthCheck) {
+		return false
+	}
+	if p.HealthState == HealthStateUnhealthy {
+		p.HealthState = HealthStateHalfOpen
+	}
+	return true
+}
+
+// recordHealthCheck moves the proxy through its health states after a check
+// and schedules the next one. The caller holds the proxy's lock.
+func (p *ProxyInfo) recordHealthCheck(success bool, now time.Time, config HealthCheckConfig) {
+	p.LastHealthCheck = now
+
+	if success {
+		p.SuccessCount++
+		p.ConsecutiveFailures = 0
+		p.ConsecutiveSuccesses++
+		if p.HealthState == HealthStateHalfOpen && p.ConsecutiveSuccesses >= config.SuccessThreshold {
+			p.HealthState = HealthStateHealthy
+		}
+		p.NextHealthCheck = now.Add(config.Interval)
+	} else {
+		p.FailureCount++
+		p.ConsecutiveSuccesses = 0
+		p.ConsecutiveFailures++
+		switch {
+		case p.HealthState == HealthStateHalfOpen:
+			// The probe failed: back off further instead of retrying at once
+			p.HealthState = HealthStateUnhealthy
+		case p.ConsecutiveFailures >= config.FailureThreshold:
+			p.HealthState = HealthStateUnhealthy
+		}
+		if p.HealthState == HealthStateUnhealthy {
+			p.NextHealthCheck = now.Add(healthBackoff(p.ConsecutiveFailures, config))
+		} else {
+			p.NextHealthCheck = now.Add(config.Interval)
+		}
+	}
+
+	p.IsHealthy = p.HealthState == HealthStateHealthy
+}
+
+// healthBackoff returns how long to wait before probing an unhealthy proxy:
+// the interval doubled for every failure since it became unhealthy, jittered
+// by up to 25% and capped at the maximum backoff
+func healthBackoff(failures int, config HealthCheckConfig) time.Duration {
+	maxBackoff := config.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultHealthCheckMaxBackoff
+	}
+
+	backoff := config.Interval
+	for i := config.FailureThreshold; i <= failures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = time.Duration(float64(backoff) * healthBackoffJitter())
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+// GetProxyHealth returns the health state of every proxy by region
+func (pm *ProxyPoolManager) GetProxyHealth() map[string][]ProxyHealth {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+
+	health := make(map[string][]ProxyHealth, len(pm.regions))
+	for name, pool := range pm.regions {
+		pool.mutex.RLock()
+		states := make([]ProxyHealth, 0, len(pool.Proxies))
+		for _, info := range pool.Proxies {
+			info.mutex.RLock()
+			states = append(states, ProxyHealth{
+				URL:                 maskedIdentity(info),
+				State:               info.HealthState,
+				ConsecutiveFailures: info.ConsecutiveFailures,
+				LastCheck:           info.LastHealthCheck,
+				NextCheck:           info.NextHealthCheck,
+			})
+			info.mutex.RUnlock()
+		}
+		pool.mutex.RUnlock()
+		health[name] = states
+	}
+	return health
+}
+
+// maskedIdentity is proxyIdentity with the credentials masked
+func maskedIdentity(info *ProxyInfo) string {
+	if info.URL != "" {
+		return proxy.RedactProxyURL(info.URL)
+	}
+	hops := make([]string, len(info.Chain))
+	for i, hop := range info.Chain {
+		hops[i] = proxy.RedactProxyURL(hop)
+	}
+	return strings.Join(hops, ",")
+}
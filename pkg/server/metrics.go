@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// serverMetrics holds the Prometheus metrics served by the metrics server
+type serverMetrics struct {
+	registry *prometheus.Registry
+}
+
+// newServerMetrics creates the server metrics; router may be nil in agent mode
+func newServerMetrics(router *ProxyRouter) *serverMetrics {
+	m := &serverMetrics{registry: prometheus.NewRegistry()}
+
+	if router != nil {
+		m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "proxyhawk_server_active_connections",
+			Help: "Open client connections to the SOCKS5 and HTTP proxies",
+		}, func() float64 {
+			return float64(router.ActiveConnections())
+		}))
+		m.registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "proxyhawk_server_blocked_connections_total",
+			Help: "Proxy connections rejected because their destination is in a denied IP range",
+		}, func() float64 {
+			return float64(router.BlockedConnections())
+		}))
+		// Counted as connections close during the drain, so a scrape during
+		// the grace period sees its progress
+		m.registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "proxyhawk_server_connections_drained_total",
+			Help: "Proxy connections that closed on their own during the shutdown grace period",
+		}, func() float64 {
+			return float64(router.conns.Stats().Drained)
+		}))
+		m.registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "proxyhawk_server_connections_killed_total",
+			Help: "Proxy connections closed forcibly when the shutdown grace period ran out",
+		}, func() float64 {
+			return float64(router.conns.Stats().Killed)
+		}))
+	}
+	return m
+}
+
+// handler serves the metrics in the Prometheus exposition format
+func (m *serverMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
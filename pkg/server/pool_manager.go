@@ -3,9 +3,11 @@ package server
 import (
 	"context"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,6 +25,9 @@ type ProxyPoolManager struct {
 	regionOrder     []string
 	currentIndex    int
 	
+	// Serializes selecting a proxy with reserving its connection slot
+	reserveMutex sync.Mutex
+	
 	mutex sync.RWMutex
 }
 
@@ -35,6 +40,13 @@ type RegionPool struct {
 	mutex         sync.RWMutex
 	currentIndex  int
 	lastUsed      time.Time
+	
+	// Consistent hash ring over the proxies last selected from, see hashRingFor
+	ringMutex sync.Mutex
+	ring      *hashRing
+	
+	// Concurrency limit on connections routed to the region
+	limiter *regionLimiter
 }
 
 // ProxyInfo represents a single proxy with health information
@@ -48,17 +60,27 @@ type ProxyInfo struct {
 	ChainTimeout   time.Duration // Timeout for chain establishment
 	RetryOnFailure bool          // Retry with different chain on failure
 	
-	// Health status
+	// Health status; IsHealthy is true only in HealthStateHealthy
 	IsHealthy      bool
+	HealthState    HealthState
 	LastHealthCheck time.Time
+	NextHealthCheck time.Time // Checks are skipped until then, see healthCheckDue
 	FailureCount   int
 	SuccessCount   int
+	ConsecutiveFailures  int
+	ConsecutiveSuccesses int
 	ResponseTime   time.Duration
 	
 	// Usage statistics
 	TotalRequests   int64
 	SuccessfulRequests int64
 	
+	// Tunnels currently open through this proxy, updated atomically
+	ActiveConnections int64
+	
+	// Round-robin detection of the latest geographic test through this proxy
+	RoundRobin *RoundRobinStatus
+	
 	mutex sync.RWMutex
 }
 
@@ -82,6 +104,7 @@ func NewProxyPoolManager(regions map[string]*RegionConfig, strategy SelectionStr
 		pool := &RegionPool{
 			Name:    name,
 			Proxies: make([]*ProxyInfo, 0, len(config.Proxies)),
+			limiter: newRegionLimiter(config),
 		}
 		
 		// Add proxies to pool
@@ -94,6 +117,7 @@ func NewProxyPoolManager(regions map[string]*RegionConfig, strategy SelectionStr
 				ChainTimeout:   proxyConfig.ChainTimeout,
 				RetryOnFailure: proxyConfig.RetryOnFailure,
 				IsHealthy:      true, // Assume healthy initially
+				HealthState:    HealthStateHealthy,
 			}
 			
 			// Set default chain timeout if not specified
@@ -113,6 +137,14 @@ func NewProxyPoolManager(regions map[string]*RegionConfig, strategy SelectionStr
 
 // GetProxy gets a proxy from the specified region
 func (pm *ProxyPoolManager) GetProxy(region string) *ProxyInfo {
+	return pm.GetProxyForKey(region, "")
+}
+
+// GetProxyForKey gets a proxy from the specified region for a session key,
+// such as a client IP. With the consistent-hash strategy a key keeps mapping
+// to the same healthy proxy, or to any proxy while none is healthy; other
+// strategies and empty keys ignore the key.
+func (pm *ProxyPoolManager) GetProxyForKey(region, key string) *ProxyInfo {
 	pm.mutex.RLock()
 	pool, exists := pm.regions[region]
 	pm.mutex.RUnlock()
@@ -122,11 +154,33 @@ func (pm *ProxyPoolManager) GetProxy(region string) *ProxyInfo {
 		return pm.getAnyProxy()
 	}
 	
+	if pm.strategy == StrategyConsistentHash && key != "" {
+		// Hash over the same healthy set as GetHealthyProxyForKey so the two
+		// share the pool's ring instead of rebuilding it for each other
+		proxies := pool.healthyProxies()
+		if len(proxies) == 0 {
+			pool.mutex.RLock()
+			proxies = append([]*ProxyInfo(nil), pool.Proxies...)
+			pool.mutex.RUnlock()
+		}
+		if len(proxies) == 0 {
+			return nil
+		}
+		return pool.hashRingFor(proxies).get(key)
+	}
+	
 	return pm.selectFromPool(pool)
 }
 
 // GetHealthyProxy gets a healthy proxy from the specified region
 func (pm *ProxyPoolManager) GetHealthyProxy(region string) *ProxyInfo {
+	return pm.GetHealthyProxyForKey(region, "")
+}
+
+// GetHealthyProxyForKey gets a healthy proxy from the specified region for a
+// session key. With the consistent-hash strategy the ring only holds healthy
+// proxies, so when a proxy fails or recovers only the keys it owns move.
+func (pm *ProxyPoolManager) GetHealthyProxyForKey(region, key string) *ProxyInfo {
 	pm.mutex.RLock()
 	pool, exists := pm.regions[region]
 	pm.mutex.RUnlock()
@@ -135,24 +189,48 @@ func (pm *ProxyPoolManager) GetHealthyProxy(region string) *ProxyInfo {
 		return pm.getAnyHealthyProxy()
 	}
 	
-	// Get healthy proxies from pool
+	healthyProxies := pool.healthyProxies()
+	if len(healthyProxies) == 0 {
+		return nil
+	}
+	
+	if pm.strategy == StrategyConsistentHash && key != "" {
+		return pool.hashRingFor(healthyProxies).get(key)
+	}
+	
+	// Select from healthy proxies
+	return pm.selectFromProxies(healthyProxies)
+}
+
+// reserveHealthyProxyForKey selects a healthy proxy like GetHealthyProxyForKey
+// and counts a connection against it before returning, so concurrent dials
+// see each other's picks under least-conn. The caller releases the slot with
+// releaseConn if the dial fails, or hands it to trackConn.
+func (pm *ProxyPoolManager) reserveHealthyProxyForKey(region, key string) *ProxyInfo {
+	pm.reserveMutex.Lock()
+	defer pm.reserveMutex.Unlock()
+	
+	proxy := pm.GetHealthyProxyForKey(region, key)
+	if proxy != nil {
+		proxy.reserveConn()
+	}
+	return proxy
+}
+
+// healthyProxies returns the pool's proxies that are currently healthy
+func (pool *RegionPool) healthyProxies() []*ProxyInfo {
 	pool.mutex.RLock()
-	healthyProxies := make([]*ProxyInfo, 0)
+	defer pool.mutex.RUnlock()
+	
+	healthy := make([]*ProxyInfo, 0, len(pool.Proxies))
 	for _, proxy := range pool.Proxies {
 		proxy.mutex.RLock()
 		if proxy.IsHealthy {
-			healthyProxies = append(healthyProxies, proxy)
+			healthy = append(healthy, proxy)
 		}
 		proxy.mutex.RUnlock()
 	}
-	pool.mutex.RUnlock()
-	
-	if len(healthyProxies) == 0 {
-		return nil
-	}
-	
-	// Select from healthy proxies
-	return pm.selectFromProxies(healthyProxies)
+	return healthy
 }
 
 // GetNextRegion gets the next region in round-robin order
@@ -197,6 +275,11 @@ func (pm *ProxyPoolManager) selectFromProxies(proxies []*ProxyInfo) *ProxyInfo {
 		return pm.selectWeighted(proxies)
 	case StrategySmart:
 		return pm.selectSmart(proxies)
+	case StrategyLeastConn:
+		return pm.selectLeastConn(proxies)
+	case StrategyConsistentHash:
+		// Without a session key there is nothing to hash
+		return pm.selectRandom(proxies)
 	default:
 		return proxies[0]
 	}
@@ -299,6 +382,63 @@ func (pm *ProxyPoolManager) selectSmart(proxies []*ProxyInfo) *ProxyInfo {
 	return bestProxy
 }
 
+// selectLeastConn selects the proxy with the fewest active connections.
+// Ties go to the proxy with the higher weight, then to the one listed first,
+// so an idle pool fills up in configuration order.
+func (pm *ProxyPoolManager) selectLeastConn(proxies []*ProxyInfo) *ProxyInfo {
+	best := proxies[0]
+	bestConns := best.ActiveConns()
+	best.mutex.RLock()
+	bestWeight := best.Weight
+	best.mutex.RUnlock()
+	
+	for _, proxy := range proxies[1:] {
+		conns := proxy.ActiveConns()
+		proxy.mutex.RLock()
+		weight := proxy.Weight
+		proxy.mutex.RUnlock()
+		
+		if conns < bestConns || (conns == bestConns && weight > bestWeight) {
+			best, bestConns, bestWeight = proxy, conns, weight
+		}
+	}
+	
+	return best
+}
+
+// ActiveConns returns the number of connections currently open through the proxy
+func (p *ProxyInfo) ActiveConns() int64 {
+	return atomic.LoadInt64(&p.ActiveConnections)
+}
+
+// reserveConn counts a connection that is being opened as active on the proxy
+func (p *ProxyInfo) reserveConn() {
+	atomic.AddInt64(&p.ActiveConnections, 1)
+}
+
+// releaseConn gives back a slot taken with reserveConn
+func (p *ProxyInfo) releaseConn() {
+	atomic.AddInt64(&p.ActiveConnections, -1)
+}
+
+// trackConn keeps the slot reserved for conn until conn is closed
+func (p *ProxyInfo) trackConn(conn net.Conn) net.Conn {
+	return &trackedConn{Conn: conn, proxy: p}
+}
+
+// trackedConn decrements its proxy's active connection count once on Close
+type trackedConn struct {
+	net.Conn
+	proxy     *ProxyInfo
+	closeOnce sync.Once
+}
+
+// Close implements net.Conn
+func (c *trackedConn) Close() error {
+	c.closeOnce.Do(c.proxy.releaseConn)
+	return c.Conn.Close()
+}
+
 // getAnyProxy gets any available proxy from any region
 func (pm *ProxyPoolManager) getAnyProxy() *ProxyInfo {
 	pm.mutex.RLock()
@@ -400,25 +540,40 @@ func (pm *ProxyPoolManager) performHealthChecks() {
 	wg.Wait()
 }
 
-// checkProxyHealth checks the health of a single proxy
+// checkProxyHealth checks the health of a single proxy once it is due
 func (pm *ProxyPoolManager) checkProxyHealth(proxy *ProxyInfo) {
 	proxy.mutex.Lock()
-	defer proxy.mutex.Unlock()
-	
-	start := time.Now()
+	if !proxy.healthCheckDue(time.Now()) {
+		proxy.mutex.Unlock()
+		return
+	}
+	proxyAddr := proxy.URL
 	
 	// Use health check URL or a default test URL
 	testURL := proxy.HealthCheckURL
 	if testURL == "" {
 		testURL = "http://httpbin.org/ip"
 	}
+	proxy.mutex.Unlock()
 	
-	// Create proxy client
-	proxyURL, err := url.Parse(proxy.URL)
+	// Send a single request without holding the lock, so selection and
+	// stats are not blocked while it is in flight
+	start := time.Now()
+	success := pm.probeProxy(proxyAddr, testURL)
+	
+	proxy.mutex.Lock()
+	defer proxy.mutex.Unlock()
+	if success {
+		proxy.ResponseTime = time.Since(start)
+	}
+	proxy.recordHealthCheck(success, time.Now(), pm.healthChecker.config)
+}
+
+// probeProxy reports whether a request through the proxy to testURL succeeds
+func (pm *ProxyPoolManager) probeProxy(proxyAddr, testURL string) bool {
+	proxyURL, err := url.Parse(proxyAddr)
 	if err != nil {
-		proxy.FailureCount++
-		proxy.IsHealthy = false
-		return
+		return false
 	}
 	
 	client := &http.Client{
@@ -428,28 +583,12 @@ func (pm *ProxyPoolManager) checkProxyHealth(proxy *ProxyInfo) {
 		},
 	}
 	
-	// Perform health check
 	resp, err := client.Get(testURL)
 	if err != nil {
-		proxy.FailureCount++
-	} else {
-		resp.Body.Close()
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			proxy.SuccessCount++
-			proxy.ResponseTime = time.Since(start)
-		} else {
-			proxy.FailureCount++
-		}
-	}
-	
-	proxy.LastHealthCheck = time.Now()
-	
-	// Update health status based on thresholds
-	if proxy.FailureCount >= pm.healthChecker.config.FailureThreshold {
-		proxy.IsHealthy = false
-	} else if proxy.SuccessCount >= pm.healthChecker.config.SuccessThreshold {
-		proxy.IsHealthy = true
+		return false
 	}
+	resp.Body.Close()
+	return resp.StatusCode >= 200 && resp.StatusCode < 300
 }
 
 // RecordProxyUsage records usage statistics for a proxy
@@ -510,8 +649,10 @@ func (pm *ProxyPoolManager) GetStats() map[string]interface{} {
 				"chain_timeout":       proxy.ChainTimeout.String(),
 				"retry_on_failure":    proxy.RetryOnFailure,
 				"healthy":             proxy.IsHealthy,
+				"health_state":        proxy.HealthState,
 				"total_requests":      proxy.TotalRequests,
 				"successful_requests": proxy.SuccessfulRequests,
+				"active_connections":  proxy.ActiveConns(),
 				"failure_count":       proxy.FailureCount,
 				"success_count":       proxy.SuccessCount,
 				"response_time":       proxy.ResponseTime.String(),
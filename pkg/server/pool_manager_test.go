@@ -0,0 +1,109 @@
+package server
+
+import (
+	"net"
+	"sync"
+	"testing"
+)
+
+func TestSelectLeastConn(t *testing.T) {
+	manager := NewProxyPoolManager(map[string]*RegionConfig{
+		"us": {
+			Name: "us",
+			Proxies: []ProxyConfig{
+				{URL: "socks5://192.0.2.1:1080", Weight: 1},
+				{URL: "socks5://192.0.2.2:1080", Weight: 1},
+				{URL: "socks5://192.0.2.3:1080", Weight: 5},
+			},
+		},
+	}, StrategyLeastConn)
+
+	// Idle pool: the heavier proxy wins the tie
+	first := manager.reserveHealthyProxyForKey("us", "")
+	if first.URL != "socks5://192.0.2.3:1080" {
+		t.Fatalf("idle pool selected %s, want the highest weight", first.URL)
+	}
+	if got := first.ActiveConns(); got != 1 {
+		t.Fatalf("ActiveConns() = %d after selection, want 1", got)
+	}
+
+	client, server := net.Pipe()
+	defer server.Close()
+	conn := first.trackConn(client)
+	if got := first.ActiveConns(); got != 1 {
+		t.Fatalf("ActiveConns() = %d after dial, want 1", got)
+	}
+
+	// Equal weights and counts: the proxy listed first wins
+	second := manager.GetHealthyProxy("us")
+	if second.URL != "socks5://192.0.2.1:1080" {
+		t.Errorf("selected %s with one busy proxy, want the first listed idle proxy", second.URL)
+	}
+
+	// Closing twice releases the connection only once
+	conn.Close()
+	conn.Close()
+	if got := first.ActiveConns(); got != 0 {
+		t.Errorf("ActiveConns() = %d after close, want 0", got)
+	}
+	if again := manager.GetHealthyProxy("us"); again != first {
+		t.Errorf("selected %s after close, want %s", again.URL, first.URL)
+	}
+}
+
+func TestReserveLeastConnConcurrently(t *testing.T) {
+	manager := NewProxyPoolManager(map[string]*RegionConfig{
+		"us": {
+			Name: "us",
+			Proxies: []ProxyConfig{
+				{URL: "socks5://192.0.2.1:1080", Weight: 1},
+				{URL: "socks5://192.0.2.2:1080", Weight: 1},
+				{URL: "socks5://192.0.2.3:1080", Weight: 1},
+				{URL: "socks5://192.0.2.4:1080", Weight: 1},
+			},
+		},
+	}, StrategyLeastConn)
+
+	// Dials that start together spread out before any of them connects
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			manager.reserveHealthyProxyForKey("us", "")
+		}()
+	}
+	wg.Wait()
+	for _, proxy := range manager.regions["us"].Proxies {
+		if got := proxy.ActiveConns(); got != 2 {
+			t.Errorf("%s has %d reserved connections, want 2", proxy.URL, got)
+		}
+	}
+
+	// A failed dial gives its slot back
+	proxy := manager.reserveHealthyProxyForKey("us", "")
+	proxy.releaseConn()
+	if got := proxy.ActiveConns(); got != 2 {
+		t.Errorf("ActiveConns() = %d after a failed dial, want 2", got)
+	}
+}
+
+func TestSelectLeastConnSkipsUnhealthy(t *testing.T) {
+	manager := NewProxyPoolManager(map[string]*RegionConfig{
+		"eu": {
+			Name: "eu",
+			Proxies: []ProxyConfig{
+				{URL: "http://192.0.2.10:8080", Weight: 1},
+				{URL: "http://192.0.2.11:8080", Weight: 1},
+			},
+		},
+	}, StrategyLeastConn)
+
+	pool := manager.regions["eu"]
+	pool.Proxies[0].IsHealthy = false
+	pool.Proxies[1].ActiveConnections = 10
+
+	if got := manager.GetHealthyProxy("eu"); got != pool.Proxies[1] {
+		t.Errorf("selected %s, want the only healthy proxy despite its load", got.URL)
+	}
+}
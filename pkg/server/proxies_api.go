@@ -0,0 +1,119 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ResistanceIsUseless/ProxyHawk/internal/proxy"
+)
+
+// ProxyStatus is the server's current view of one configured proxy, as
+// returned by GET /api/proxies
+type ProxyStatus struct {
+	Region              string      `json:"region"`
+	URL                 string      `json:"url,omitempty"`   // Credentials masked
+	Chain               []string    `json:"chain,omitempty"` // Credentials masked
+	State               HealthState `json:"state"`
+	LastCheck           *time.Time  `json:"last_check,omitempty"` // Unset until the first health check
+	ConsecutiveFailures int         `json:"consecutive_failures"`
+	ActiveConnections   int64       `json:"active_connections"`
+
+	// Outcome of the latest geographic test that went through this proxy
+	RoundRobin *RoundRobinStatus `json:"round_robin,omitempty"`
+}
+
+// RoundRobinStatus is the round-robin DNS detection of the latest geographic
+// test that used a proxy
+type RoundRobinStatus struct {
+	Domain       string    `json:"domain"`
+	IsRoundRobin bool      `json:"is_round_robin"`
+	Confidence   float64   `json:"confidence"`
+	TestedAt     time.Time `json:"tested_at"`
+}
+
+// handleProxies handles GET /api/proxies: it lists every configured proxy
+// with its health state and load, ordered by region
+func (s *WebSocketService) handleProxies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSONError(w, http.StatusMethodNotAllowed, "use GET")
+		return
+	}
+
+	statuses := []ProxyStatus{}
+	if s.geoTester != nil && s.geoTester.poolManager != nil {
+		statuses = s.geoTester.poolManager.GetProxyStatuses()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"proxies": statuses,
+	})
+}
+
+// GetProxyStatuses returns the state of every configured proxy, ordered by
+// region and then as configured
+func (pm *ProxyPoolManager) GetProxyStatuses() []ProxyStatus {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+
+	regions := make([]string, 0, len(pm.regions))
+	for name := range pm.regions {
+		regions = append(regions, name)
+	}
+	sort.Strings(regions)
+
+	statuses := make([]ProxyStatus, 0)
+	for _, name := range regions {
+		pool := pm.regions[name]
+		pool.mutex.RLock()
+		for _, info := range pool.Proxies {
+			info.mutex.RLock()
+			status := ProxyStatus{
+				Region:              name,
+				State:               info.HealthState,
+				ConsecutiveFailures: info.ConsecutiveFailures,
+				ActiveConnections:   info.ActiveConns(),
+			}
+			if info.URL != "" {
+				status.URL = proxy.RedactProxyURL(info.URL)
+			}
+			for _, hop := range info.Chain {
+				status.Chain = append(status.Chain, proxy.RedactProxyURL(hop))
+			}
+			if info.RoundRobin != nil {
+				roundRobin := *info.RoundRobin
+				status.RoundRobin = &roundRobin
+			}
+			if !info.LastHealthCheck.IsZero() {
+				lastCheck := info.LastHealthCheck
+				status.LastCheck = &lastCheck
+			}
+			info.mutex.RUnlock()
+			statuses = append(statuses, status)
+		}
+		pool.mutex.RUnlock()
+	}
+	return statuses
+}
+
+// RecordRoundRobinDetection keeps the round-robin detection of a geographic
+// test on the proxy it went through
+func (pm *ProxyPoolManager) RecordRoundRobinDetection(proxyURL string, status RoundRobinStatus) {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+
+	for _, pool := range pm.regions {
+		pool.mutex.RLock()
+		for _, proxy := range pool.Proxies {
+			if proxy.URL == proxyURL {
+				proxy.mutex.Lock()
+				proxy.RoundRobin = &status
+				proxy.mutex.Unlock()
+			}
+		}
+		pool.mutex.RUnlock()
+	}
+}
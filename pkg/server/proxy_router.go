@@ -11,6 +11,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	
 	"github.com/armon/go-socks5"
@@ -32,6 +33,17 @@ type ProxyRouter struct {
 	
 	// Proxy chaining
 	proxyChain *ProxyChain
+	
+	// Client connection tracking for graceful draining
+	conns         *connTracker
+	listenerMutex sync.Mutex
+	listeners     []net.Listener
+	httpServer    *http.Server
+	draining      bool
+	
+	// Destination access control
+	access  atomic.Pointer[accessPolicy]
+	blocked int64 // Connections rejected by the access policy, updated atomically
 }
 
 // RouterConfig contains proxy routing configuration  
@@ -44,6 +56,10 @@ type RouterConfig struct {
 	StickySessions  bool
 	SessionTTL      time.Duration
 	
+	// HashKey is the header identifying a client for the consistent-hash
+	// strategy (empty = client IP)
+	HashKey string
+	
 	// Advanced features
 	SmartRouting    bool
 	CDNDetection    bool
@@ -99,8 +115,13 @@ func NewProxyRouter(pools *ProxyPoolManager, strategy SelectionStrategy, logger
 		logger:   logger,
 		sessions: make(map[string]*SessionInfo),
 		config:   config,
+		conns:    newConnTracker(),
 	}
 	
+	// Deny the default private and link-local ranges until configured otherwise
+	policy, _ := newAccessPolicy(AccessControlConfig{})
+	router.access.Store(policy)
+	
 	// Initialize proxy chain handler
 	router.proxyChain = NewProxyChain(config, logger)
 	if router.proxyChain == nil {
@@ -117,7 +138,7 @@ func (r *ProxyRouter) StartSOCKS5Server(addr string) error {
 	conf := &socks5.Config{
 		Dial: r.dialWithRegion,
 		Resolver: &customResolver{router: r},
-		Rules: &permissiveRules{},
+		Rules: &accessRules{router: r},
 		Logger: log.New(os.Stderr, "[SOCKS5] ", log.LstdFlags),
 	}
 	
@@ -126,8 +147,16 @@ func (r *ProxyRouter) StartSOCKS5Server(addr string) error {
 		return fmt.Errorf("failed to create SOCKS5 server: %w", err)
 	}
 	
+	listener, err := r.listen(addr)
+	if err != nil {
+		return err
+	}
+	
 	r.logger.Info("Starting SOCKS5 proxy", "addr", addr)
-	return server.ListenAndServe("tcp", addr)
+	if err := server.Serve(listener); err != nil && !r.isDraining() {
+		return err
+	}
+	return nil
 }
 
 // StartHTTPProxy starts the HTTP proxy server
@@ -141,7 +170,7 @@ func (r *ProxyRouter) StartHTTPProxy(addr string) error {
 		region := r.selectRegion(req)
 		
 		// Get proxy for region
-		proxyInfo := r.pools.GetProxy(region)
+		proxyInfo := r.pools.GetProxyForKey(region, r.hashKey(req))
 		if proxyInfo == nil {
 			r.logger.Warn("No proxy available", "region", region)
 			return nil, nil
@@ -163,16 +192,26 @@ func (r *ProxyRouter) StartHTTPProxy(addr string) error {
 			r.updateSession(req.Host, proxyInfo.URL, region)
 		}
 		
+		if upstream, ok := req.Context().Value(upstreamContextKey).(*upstreamChoice); ok {
+			upstream.set(proxyURL)
+		}
 		return proxyURL, nil
 	}
 	
+	// Requests without an upstream proxy and CONNECT tunnels are dialed
+	// directly; check the address actually dialed against the access policy
+	proxy.Tr.DialContext = r.dialTransport
+	proxy.ConnectDialWithReq = func(req *http.Request, network, addr string) (net.Conn, error) {
+		return r.dialDestination(req.Context(), network, addr)
+	}
+	
 	// Intercept HTTPS CONNECT requests
 	proxy.OnRequest().HandleConnectFunc(func(host string, ctx *goproxy.ProxyCtx) (*goproxy.ConnectAction, string) {
 		// Select region for HTTPS
 		region := r.smartSelectRegion(host)
 		
 		// Get proxy for region
-		proxyInfo := r.pools.GetProxy(region)
+		proxyInfo := r.pools.GetProxyForKey(region, r.hashKey(ctx.Req))
 		if proxyInfo == nil {
 			r.logger.Warn("No proxy available for HTTPS", "region", region, "host", host)
 			return goproxy.OkConnect, host
@@ -187,8 +226,93 @@ func (r *ProxyRouter) StartHTTPProxy(addr string) error {
 		return goproxy.OkConnect, host
 	})
 	
+	listener, err := r.listen(addr)
+	if err != nil {
+		return err
+	}
+	
+	httpServer := &http.Server{Handler: r.restrictDestinations(r.limitRegions(proxy))}
+	r.listenerMutex.Lock()
+	r.httpServer = httpServer
+	r.listenerMutex.Unlock()
+	
 	r.logger.Info("Starting HTTP proxy", "addr", addr)
-	return http.ListenAndServe(addr, proxy)
+	if err := httpServer.Serve(listener); err != nil && err != http.ErrServerClosed && !r.isDraining() {
+		return err
+	}
+	return nil
+}
+
+// limitRegions holds one of the target region's concurrency slots while a
+// plain HTTP request is proxied, answering 503 when the region is at its limit
+func (r *ProxyRouter) limitRegions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodConnect {
+			// CONNECT tunnels are dialed directly, not through a region's proxies
+			next.ServeHTTP(w, req)
+			return
+		}
+		
+		region := r.selectRegion(req)
+		release, err := r.pools.AcquireRegion(req.Context(), region)
+		if err != nil {
+			r.logger.Warn("Rejecting request", "host", req.Host, "region", region, "error", err)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		defer release()
+		
+		next.ServeHTTP(w, req)
+	})
+}
+
+// listen opens a tracked listener on addr, unless the router is draining
+func (r *ProxyRouter) listen(addr string) (net.Listener, error) {
+	r.listenerMutex.Lock()
+	defer r.listenerMutex.Unlock()
+	
+	if r.draining {
+		return nil, fmt.Errorf("proxy router is shutting down")
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	r.listeners = append(r.listeners, listener)
+	return r.conns.listener(listener), nil
+}
+
+// isDraining reports whether Drain was called
+func (r *ProxyRouter) isDraining() bool {
+	r.listenerMutex.Lock()
+	defer r.listenerMutex.Unlock()
+	return r.draining
+}
+
+// ActiveConnections returns the number of open client connections
+func (r *ProxyRouter) ActiveConnections() int64 {
+	return r.conns.Active()
+}
+
+// Drain stops accepting new connections, then waits for the open ones to
+// close until ctx is done and closes whatever is still open
+func (r *ProxyRouter) Drain(ctx context.Context) DrainStats {
+	r.listenerMutex.Lock()
+	r.draining = true
+	for _, listener := range r.listeners {
+		listener.Close()
+	}
+	r.listeners = nil
+	httpServer := r.httpServer
+	r.listenerMutex.Unlock()
+	
+	if httpServer != nil {
+		// Closes idle keep-alive connections right away; CONNECT tunnels are
+		// hijacked and left to the tracker
+		go httpServer.Shutdown(ctx)
+	}
+	
+	return r.conns.drain(ctx)
 }
 
 // dialWithRegion creates a connection through a regional proxy
@@ -206,31 +330,70 @@ func (r *ProxyRouter) dialWithRegion(ctx context.Context, network, addr string)
 		}
 	}
 	
-	// Get proxy for selected region
-	proxyInfo := r.pools.GetHealthyProxy(region)
+	// Hold one of the region's concurrency slots while the tunnel is open
+	release, err := r.pools.AcquireRegion(ctx, region)
+	if err != nil {
+		r.logger.Warn("Rejecting connection", "target", addr, "region", region, "error", err)
+		return nil, err
+	}
+	
+	conn, err := r.dialRegionProxy(ctx, region, network, addr)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return releaseOnClose(conn, release), nil
+}
+
+// dialRegionProxy connects to addr through one of the region's healthy proxies
+func (r *ProxyRouter) dialRegionProxy(ctx context.Context, region, network, addr string) (net.Conn, error) {
+	// Get proxy for selected region; SOCKS5 clients are keyed by IP
+	clientIP, _ := ctx.Value(clientIPContextKey).(string)
+	proxyInfo := r.pools.reserveHealthyProxyForKey(region, clientIP)
 	if proxyInfo == nil {
 		return nil, fmt.Errorf("no healthy proxy for region %s", region)
 	}
 	
+	conn, err := r.dialProxyInfo(ctx, proxyInfo, region, network, addr)
+	if err != nil {
+		proxyInfo.releaseConn()
+		return nil, err
+	}
+	
+	// Keep the tunnel counted against the proxy until it is closed
+	return proxyInfo.trackConn(conn), nil
+}
+
+// dialProxyInfo connects to addr through proxyInfo or its chain
+func (r *ProxyRouter) dialProxyInfo(ctx context.Context, proxyInfo *ProxyInfo, region, network, addr string) (net.Conn, error) {
 	r.logger.Debug("Dialing through proxy", 
 		"target", addr,
 		"region", region,
 		"proxy", proxyInfo.URL)
 	
 	// Check if proxy chaining is enabled and chain is configured
+	var conn net.Conn
 	if r.config.EnableChaining && len(proxyInfo.Chain) > 0 {
 		// Use proxy chaining
-		return r.dialThroughChain(ctx, proxyInfo.Chain, addr)
+		chainedConn, err := r.dialThroughChain(ctx, proxyInfo.Chain, addr)
+		if err != nil {
+			return nil, err
+		}
+		conn = chainedConn
 	} else if proxyInfo.URL != "" {
 		// Use single proxy
 		proxyURL, err := url.Parse(proxyInfo.URL)
 		if err != nil {
 			return nil, fmt.Errorf("invalid proxy URL: %w", err)
 		}
-		return r.dialThroughProxy(proxyURL, network, addr)
+		conn, err = r.dialThroughProxy(proxyURL, network, addr)
+		if err != nil {
+			return nil, err
+		}
 	} else {
 		return nil, fmt.Errorf("no proxy configuration found for region %s", region)
 	}
+	return conn, nil
 }
 
 // dialThroughChain establishes connection through a proxy chain
@@ -504,6 +667,25 @@ func (r *ProxyRouter) geolocateTarget(host string) string {
 	return ""
 }
 
+// hashKey returns the key identifying the client of req for the
+// consistent-hash strategy: the configured header if the request carries it,
+// otherwise the client IP
+func (r *ProxyRouter) hashKey(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+	if r.config.HashKey != "" {
+		if value := req.Header.Get(r.config.HashKey); value != "" {
+			return value
+		}
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
 // extractRegionFromContext extracts region from context
 func (r *ProxyRouter) extractRegionFromContext(ctx context.Context) string {
 	if region, ok := ctx.Value("region").(string); ok {
@@ -565,6 +747,12 @@ func (r *ProxyRouter) cleanupSessions() {
 
 // Helper types for SOCKS5
 
+// routerContextKey keys values the SOCKS5 server passes to the dialer
+type routerContextKey string
+
+// clientIPContextKey holds the IP of the SOCKS5 client
+const clientIPContextKey routerContextKey = "client_ip"
+
 type customResolver struct {
 	router *ProxyRouter
 }
@@ -578,13 +766,6 @@ func (r *customResolver) Resolve(ctx context.Context, name string) (context.Cont
 	return ctx, addr.IP, nil
 }
 
-type permissiveRules struct{}
-
-func (r *permissiveRules) Allow(ctx context.Context, req *socks5.Request) (context.Context, bool) {
-	// Allow all connections
-	return ctx, true
-}
-
 type socks5Logger struct {
 	logger Logger
 }
@@ -608,6 +789,12 @@ func (r *ProxyRouter) SetChainConfig(config *RouterConfig) {
 	}
 }
 
+// SetHashKey sets the header identifying clients for the consistent-hash
+// strategy (empty = client IP)
+func (r *ProxyRouter) SetHashKey(header string) {
+	r.config.HashKey = header
+}
+
 // EnableChaining enables or disables proxy chaining
 func (r *ProxyRouter) EnableChaining(enabled bool) {
 	r.config.EnableChaining = enabled
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRegionAtCapacity is returned when a region already routes its
+// MaxConcurrent connections and no slot freed up within its QueueTimeout
+var ErrRegionAtCapacity = errors.New("region is at its concurrency limit")
+
+// regionLimiter caps the connections routed to a region's proxies at once
+type regionLimiter struct {
+	slots        chan struct{} // nil = unlimited
+	queueTimeout time.Duration
+	inFlight     int64 // Updated atomically
+}
+
+// newRegionLimiter creates a limiter for config; MaxConcurrent <= 0 means unlimited
+func newRegionLimiter(config *RegionConfig) *regionLimiter {
+	limiter := &regionLimiter{queueTimeout: config.QueueTimeout}
+	if config.MaxConcurrent > 0 {
+		limiter.slots = make(chan struct{}, config.MaxConcurrent)
+	}
+	return limiter
+}
+
+// acquire takes a slot, waiting up to the queue timeout for one to free up.
+// The returned function releases the slot and is safe to call more than once.
+func (l *regionLimiter) acquire(ctx context.Context) (func(), error) {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			if l.queueTimeout <= 0 {
+				return nil, ErrRegionAtCapacity
+			}
+			timer := time.NewTimer(l.queueTimeout)
+			defer timer.Stop()
+			select {
+			case l.slots <- struct{}{}:
+			case <-timer.C:
+				return nil, ErrRegionAtCapacity
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	atomic.AddInt64(&l.inFlight, 1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			atomic.AddInt64(&l.inFlight, -1)
+			if l.slots != nil {
+				<-l.slots
+			}
+		})
+	}, nil
+}
+
+// AcquireRegion takes one of the region's concurrency slots for a connection
+// routed to its proxies, queueing briefly when the region is at its limit.
+// Unknown regions are not limited. Call the returned function when done.
+func (pm *ProxyPoolManager) AcquireRegion(ctx context.Context, region string) (func(), error) {
+	pm.mutex.RLock()
+	pool, exists := pm.regions[region]
+	pm.mutex.RUnlock()
+
+	if !exists {
+		return func() {}, nil
+	}
+
+	release, err := pool.limiter.acquire(ctx)
+	if err != nil {
+		if errors.Is(err, ErrRegionAtCapacity) {
+			return nil, fmt.Errorf("region %s: %w (max %d concurrent connections)", region, err, cap(pool.limiter.slots))
+		}
+		return nil, err
+	}
+	return release, nil
+}
+
+// RegionLoad reports the in-flight connections of a region
+type RegionLoad struct {
+	InFlight      int64 `json:"in_flight"`
+	MaxConcurrent int   `json:"max_concurrent"` // 0 = unlimited
+}
+
+// GetRegionLoad returns the in-flight connections of every region
+func (pm *ProxyPoolManager) GetRegionLoad() map[string]RegionLoad {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+
+	load := make(map[string]RegionLoad, len(pm.regions))
+	for name, pool := range pm.regions {
+		load[name] = RegionLoad{
+			InFlight:      atomic.LoadInt64(&pool.limiter.inFlight),
+			MaxConcurrent: cap(pool.limiter.slots),
+		}
+	}
+	return load
+}
+
+// releaseOnClose calls release once when conn is closed
+func releaseOnClose(conn net.Conn, release func()) net.Conn {
+	return &releasingConn{Conn: conn, release: release}
+}
+
+// releasingConn releases its region slot when closed
+type releasingConn struct {
+	net.Conn
+	release func()
+}
+
+// Close implements net.Conn
+func (c *releasingConn) Close() error {
+	err := c.Conn.Close()
+	c.release()
+	return err
+}
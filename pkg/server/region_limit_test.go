@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newLimitedManager(maxConcurrent int, queueTimeout time.Duration) *ProxyPoolManager {
+	return NewProxyPoolManager(map[string]*RegionConfig{
+		"us": {
+			Name:          "us",
+			Proxies:       []ProxyConfig{{URL: "socks5://192.0.2.1:1080", Weight: 1}},
+			MaxConcurrent: maxConcurrent,
+			QueueTimeout:  queueTimeout,
+		},
+	}, StrategyRandom)
+}
+
+func TestAcquireRegionRejectsOverLimit(t *testing.T) {
+	manager := newLimitedManager(2, 0)
+	ctx := context.Background()
+
+	first, err := manager.AcquireRegion(ctx, "us")
+	if err != nil {
+		t.Fatalf("AcquireRegion() error = %v", err)
+	}
+	if _, err := manager.AcquireRegion(ctx, "us"); err != nil {
+		t.Fatalf("AcquireRegion() error = %v", err)
+	}
+	if _, err := manager.AcquireRegion(ctx, "us"); !errors.Is(err, ErrRegionAtCapacity) {
+		t.Fatalf("AcquireRegion() over the limit error = %v, want ErrRegionAtCapacity", err)
+	}
+
+	if load := manager.GetRegionLoad()["us"]; load != (RegionLoad{InFlight: 2, MaxConcurrent: 2}) {
+		t.Errorf("GetRegionLoad() = %+v, want 2 of 2 in flight", load)
+	}
+
+	// Releasing twice frees only one slot
+	first()
+	first()
+	if load := manager.GetRegionLoad()["us"]; load.InFlight != 1 {
+		t.Errorf("InFlight = %d after release, want 1", load.InFlight)
+	}
+	if _, err := manager.AcquireRegion(ctx, "us"); err != nil {
+		t.Errorf("AcquireRegion() after release error = %v", err)
+	}
+}
+
+func TestAcquireRegionQueues(t *testing.T) {
+	manager := newLimitedManager(1, time.Second)
+	ctx := context.Background()
+
+	release, err := manager.AcquireRegion(ctx, "us")
+	if err != nil {
+		t.Fatalf("AcquireRegion() error = %v", err)
+	}
+	time.AfterFunc(20*time.Millisecond, release)
+
+	// Waits for the slot to be released instead of failing
+	if _, err := manager.AcquireRegion(ctx, "us"); err != nil {
+		t.Errorf("queued AcquireRegion() error = %v", err)
+	}
+
+	// Times out when nothing is released
+	manager = newLimitedManager(1, 20*time.Millisecond)
+	manager.AcquireRegion(ctx, "us")
+	if _, err := manager.AcquireRegion(ctx, "us"); !errors.Is(err, ErrRegionAtCapacity) {
+		t.Errorf("AcquireRegion() after queue timeout error = %v, want ErrRegionAtCapacity", err)
+	}
+}
+
+func TestAcquireRegionUnlimited(t *testing.T) {
+	manager := newLimitedManager(0, 0)
+	for i := 0; i < 100; i++ {
+		if _, err := manager.AcquireRegion(context.Background(), "us"); err != nil {
+			t.Fatalf("AcquireRegion() #%d error = %v", i, err)
+		}
+	}
+	if load := manager.GetRegionLoad()["us"]; load != (RegionLoad{InFlight: 100}) {
+		t.Errorf("GetRegionLoad() = %+v, want 100 in flight and no limit", load)
+	}
+}
+
+func TestLimitRegionsRejectsWithServiceUnavailable(t *testing.T) {
+	manager := newLimitedManager(1, 0)
+	router := NewProxyRouter(manager, StrategyRandom, nopLogger{})
+	router.config.SmartRouting = false
+	router.config.DefaultRegion = "us"
+
+	manager.AcquireRegion(context.Background(), "us")
+
+	served := false
+	handler := router.limitRegions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = true
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if served {
+		t.Error("request was proxied although the region is at its limit")
+	}
+}
@@ -26,6 +26,7 @@ type ProxyHawkServer struct {
 	geoTester     *GeographicTester
 	dnsCache      *DNSCache
 	metricsServer *http.Server
+	metrics       *serverMetrics
 	
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -46,12 +47,25 @@ type Config struct {
 	// WebSocket/API settings
 	APIAddr string
 	
+	// APIToken must be sent as a bearer token to run checks through the
+	// API; when empty only loopback clients may run them
+	APIToken string
+	
 	// Regional proxy configuration
 	Regions map[string]*RegionConfig
 	
 	// Selection strategy
 	SelectionStrategy SelectionStrategy
 	
+	// HashKey is the HTTP header identifying a client for the consistent-hash
+	// strategy, e.g. X-Session-ID (empty = client IP). SOCKS5 clients are
+	// always identified by IP.
+	HashKey string
+	
+	// AccessControl restricts the destinations proxy clients may reach;
+	// private and link-local ranges are denied unless allowed here
+	AccessControl AccessControlConfig
+	
 	// Round-robin detection settings
 	RoundRobinDetection RoundRobinConfig
 	
@@ -65,6 +79,10 @@ type Config struct {
 	MetricsEnabled bool
 	MetricsAddr    string
 	
+	// ShutdownGracePeriod is how long shutdown waits for in-flight proxied
+	// connections before closing them (default 30s)
+	ShutdownGracePeriod time.Duration
+	
 	// Logging
 	LogLevel string
 	LogFormat string
@@ -74,6 +92,12 @@ type Config struct {
 type RegionConfig struct {
 	Name    string
 	Proxies []ProxyConfig
+	
+	// MaxConcurrent caps the connections routed to the region's proxies at
+	// once (0 = unlimited). Excess connections wait up to QueueTimeout for a
+	// slot and are rejected after that, or right away when QueueTimeout is 0.
+	MaxConcurrent int           `yaml:"max_concurrent"`
+	QueueTimeout  time.Duration `yaml:"queue_timeout"`
 }
 
 // ProxyConfig defines a single proxy or proxy chain
@@ -97,6 +121,12 @@ const (
 	StrategyWeighted   SelectionStrategy = "weighted"
 	StrategySmart      SelectionStrategy = "smart"
 	StrategySticky     SelectionStrategy = "sticky"
+	// StrategyLeastConn picks the healthy proxy with the fewest open tunnels;
+	// ties go to the higher weight, then to the proxy listed first
+	StrategyLeastConn SelectionStrategy = "least-conn"
+	// StrategyConsistentHash maps each client (its IP, or the HashKey header)
+	// to a proxy on a hash ring for session affinity
+	StrategyConsistentHash SelectionStrategy = "consistent-hash"
 )
 
 // RoundRobinConfig holds round-robin detection settings
@@ -114,6 +144,10 @@ type HealthCheckConfig struct {
 	Timeout           time.Duration
 	FailureThreshold  int
 	SuccessThreshold  int
+	
+	// MaxBackoff caps the exponential backoff between checks of an unhealthy
+	// proxy (default 5m)
+	MaxBackoff time.Duration
 }
 
 // CacheConfig holds cache settings
@@ -183,12 +217,21 @@ func (s *ProxyHawkServer) initializeComponents() {
 	// Initialize proxy router (used by proxy and dual modes)
 	if s.config.Mode == ModeProxy || s.config.Mode == ModeDual {
 		s.proxyRouter = NewProxyRouter(s.poolManager, s.config.SelectionStrategy, s.logger)
+		s.proxyRouter.SetHashKey(s.config.HashKey)
+		if err := s.proxyRouter.SetAccessControl(s.config.AccessControl); err != nil {
+			s.logger.Error("Invalid access control, keeping the default denied ranges", "error", err)
+		}
 		s.logger.Info("Proxy router initialized")
 	}
 	
+	if s.config.MetricsEnabled {
+		s.metrics = newServerMetrics(s.proxyRouter)
+	}
+	
 	// Initialize WebSocket service (used by agent and dual modes)
 	if s.config.Mode == ModeAgent || s.config.Mode == ModeDual {
 		s.wsService = NewWebSocketService(s.geoTester, s.dnsCache, s.logger)
+		s.wsService.apiToken = s.config.APIToken
 		s.logger.Info("WebSocket service initialized")
 	}
 }
@@ -302,9 +345,11 @@ func (s *ProxyHawkServer) StartDual(socksAddr, httpAddr, apiAddr string) error {
 
 // startMetricsServer starts the Prometheus metrics server
 func (s *ProxyHawkServer) startMetricsServer() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", s.metrics.handler())
 	s.metricsServer = &http.Server{
 		Addr:    s.config.MetricsAddr,
-		Handler: nil, // Will be set by metrics package
+		Handler: mux,
 	}
 	
 	s.wg.Add(1)
@@ -329,6 +374,33 @@ func (s *ProxyHawkServer) Shutdown() error {
 		s.poolManager.StopHealthChecking()
 	}
 	
+	gracePeriod := s.config.ShutdownGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultShutdownGracePeriod
+	}
+	
+	// Stop accepting proxy connections and let in-flight tunnels finish
+	if s.proxyRouter != nil {
+		s.logger.Info("Draining proxy connections",
+			"active", s.proxyRouter.ActiveConnections(),
+			"grace_period", gracePeriod)
+		ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+		drained := s.proxyRouter.Drain(ctx)
+		cancel()
+		s.logger.Info("Proxy connections drained",
+			"drained", drained.Drained,
+			"killed", drained.Killed)
+	}
+	
+	// Stop the WebSocket/API server
+	if s.wsService != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := s.wsService.Shutdown(ctx); err != nil {
+			s.logger.Warn("Error shutting down WebSocket service", "error", err)
+		}
+		cancel()
+	}
+	
 	// Shutdown metrics server
 	if s.metricsServer != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -363,6 +435,11 @@ func (s *ProxyHawkServer) GetStats() map[string]interface{} {
 		stats["pool"] = s.poolManager.GetStats()
 	}
 	
+	// Add proxy connection stats
+	if s.proxyRouter != nil {
+		stats["active_connections"] = s.proxyRouter.ActiveConnections()
+	}
+	
 	// Add cache stats
 	if s.dnsCache != nil {
 		stats["cache"] = s.dnsCache.GetStats()
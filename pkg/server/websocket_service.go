@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -41,6 +42,10 @@ type WebSocketService struct {
 	// Subscription management
 	subscriptions map[string]map[*WSClient]bool // domain -> clients
 	subsMux       sync.RWMutex
+	
+	// On-demand checks (POST /api/check)
+	apiToken   string        // Bearer token required by the check API (empty = loopback clients only)
+	checkSlots chan struct{} // Semaphore capping concurrent checks
 }
 
 // WSClient represents a WebSocket client
@@ -121,6 +126,7 @@ func NewWebSocketService(geoTester *GeographicTester, dnsCache *DNSCache, logger
 		dnsCache:      dnsCache,
 		logger:        logger,
 		subscriptions: make(map[string]map[*WSClient]bool),
+		checkSlots:    make(chan struct{}, maxConcurrentChecks),
 		upgrader: websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				// Configure origin checking for security
@@ -144,6 +150,8 @@ func (s *WebSocketService) Start(addr string) error {
 	mux.HandleFunc("/api/health", s.handleHealth)
 	mux.HandleFunc("/api/regions", s.handleRegions)
 	mux.HandleFunc("/api/stats", s.handleStats)
+	mux.HandleFunc("/api/check", s.handleCheck)
+	mux.HandleFunc("/api/proxies", s.handleProxies)
 	
 	s.server = &http.Server{
 		Addr:    addr,
@@ -154,7 +162,18 @@ func (s *WebSocketService) Start(addr string) error {
 	go s.run()
 	
 	s.logger.Info("Starting WebSocket service", "addr", addr)
-	return s.server.ListenAndServe()
+	if err := s.server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops the HTTP server, waiting for in-flight API requests until ctx is done
+func (s *WebSocketService) Shutdown(ctx context.Context) error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Shutdown(ctx)
 }
 
 // run manages the WebSocket hub
@@ -269,6 +288,13 @@ func (s *WebSocketService) handleHealth(w http.ResponseWriter, r *http.Request)
 		"uptime": time.Since(time.Now()).String(),
 	}
 	
+	// In-flight connections per region, against their concurrency limits,
+	// and the health state of every proxy
+	if s.geoTester != nil && s.geoTester.poolManager != nil {
+		health["regions"] = s.geoTester.poolManager.GetRegionLoad()
+		health["proxies"] = s.geoTester.poolManager.GetProxyHealth()
+	}
+	
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(health)
 }
@@ -3,11 +3,14 @@ package proxy
 import (
 	"bufio"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"syscall"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -116,6 +119,10 @@ func loadConfig(filename string) error {
 					},
 				},
 			},
+			MaxRetries:        3,
+			InitialRetryDelay: time.Second,
+			MaxRetryDelay:     30 * time.Second,
+			BackoffFactor:     2.0,
 		}
 		return nil // Return nil since we've set default values
 	}
@@ -175,7 +182,10 @@ func checkProxyWithInteractsh(proxy string, targetURL string, useInteractsh bool
 		return
 	}
 
-	resp, err := client.Do(req)
+	resp, attempts, err := doWithRetry(client, req, config.retryConfig())
+	if debug {
+		debugInfo += fmt.Sprintf("Attempts: %d\n", attempts)
+	}
 	if err != nil {
 		results <- ProxyResult{
 			Proxy:     proxy,
@@ -213,3 +223,45 @@ func checkProxyWithInteractsh(proxy string, targetURL string, useInteractsh bool
 		},
 	}
 }
+
+// doWithRetry sends req, retrying with exponential backoff while the error is a
+// transient network error. It returns the response and the number of attempts made.
+func doWithRetry(client *http.Client, req *http.Request, retry RetryConfig) (*http.Response, int, error) {
+	maxAttempts := retry.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	backoff := retry.BackoffFactor
+	if backoff < 1 {
+		backoff = 1
+	}
+	delay := retry.InitialDelay
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		resp, err := client.Do(req)
+		if err == nil {
+			return resp, attempt, nil
+		}
+		lastErr = err
+		if attempt == maxAttempts || !isRetryableError(err) {
+			return nil, attempt, lastErr
+		}
+		time.Sleep(delay)
+		delay = time.Duration(float64(delay) * backoff)
+		if retry.MaxDelay > 0 && delay > retry.MaxDelay {
+			delay = retry.MaxDelay
+		}
+	}
+	return nil, maxAttempts, lastErr
+}
+
+// isRetryableError reports whether err is a timeout or a refused connection.
+// Responses that arrive but fail validation are never retried.
+func isRetryableError(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
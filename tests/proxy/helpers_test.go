@@ -0,0 +1,65 @@
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDoWithRetry(t *testing.T) {
+	// Grab a free port and close it so connections are refused
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start listener: %v", err)
+	}
+	refusedURL := "http://" + listener.Addr().String()
+	listener.Close()
+
+	retry := RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond, BackoffFactor: 2}
+	req, _ := http.NewRequest("GET", refusedURL, nil)
+	if _, attempts, err := doWithRetry(&http.Client{}, req, retry); err == nil || attempts != 3 {
+		t.Errorf("Expected 3 attempts for a refused connection, got %d (err %v)", attempts, err)
+	}
+
+	// A response that arrives is returned as is, even when it is an error status
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	req, _ = http.NewRequest("GET", server.URL, nil)
+	resp, attempts, err := doWithRetry(&http.Client{}, req, retry)
+	if err != nil || attempts != 1 {
+		t.Fatalf("Expected a single attempt for a received response, got %d (err %v)", attempts, err)
+	}
+	resp.Body.Close()
+}
+
+func TestLoadConfigRetrySettings(t *testing.T) {
+	saved := config
+	defer func() { config = saved }()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "retry_enabled: true\nmax_retries: 2\ninitial_retry_delay: 10ms\nmax_retry_delay: 50ms\nbackoff_factor: 3\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := loadConfig(path); err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+
+	want := RetryConfig{MaxAttempts: 3, InitialDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond, BackoffFactor: 3}
+	if got := config.retryConfig(); got != want {
+		t.Errorf("Expected %+v from the retry settings, got %+v", want, got)
+	}
+
+	// Without retry_enabled, as in the client default, requests are tried once
+	config.RetryEnabled = false
+	if got := config.retryConfig(); got.MaxAttempts != 1 {
+		t.Errorf("Expected a single attempt with retries disabled, got %d", got.MaxAttempts)
+	}
+}
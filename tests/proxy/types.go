@@ -1,8 +1,6 @@
 package proxy
 
 import (
-	"net/http"
-	"net/url"
 	"time"
 )
 
@@ -108,6 +106,35 @@ type Config struct {
 			TestHostHeaderInjection bool     `yaml:"test_host_header_injection"`
 		} `yaml:"advanced_checks"`
 	} `yaml:"validation"`
+
+	// Retry settings, shared with the proxyhawk client configuration
+	RetryEnabled      bool          `yaml:"retry_enabled"`
+	MaxRetries        int           `yaml:"max_retries"`
+	InitialRetryDelay time.Duration `yaml:"initial_retry_delay"`
+	MaxRetryDelay     time.Duration `yaml:"max_retry_delay"`
+	BackoffFactor     float64       `yaml:"backoff_factor"`
+}
+
+// RetryConfig controls how transient network errors are retried
+type RetryConfig struct {
+	MaxAttempts   int
+	InitialDelay  time.Duration
+	MaxDelay      time.Duration
+	BackoffFactor float64
+}
+
+// retryConfig returns the retry policy described by the retry settings. With
+// retries disabled every request is tried once.
+func (c Config) retryConfig() RetryConfig {
+	if !c.RetryEnabled {
+		return RetryConfig{MaxAttempts: 1}
+	}
+	return RetryConfig{
+		MaxAttempts:   c.MaxRetries + 1,
+		InitialDelay:  c.InitialRetryDelay,
+		MaxDelay:      c.MaxRetryDelay,
+		BackoffFactor: c.BackoffFactor,
+	}
 }
 
 // TestURLConfig represents the test URL configuration
@@ -123,15 +150,3 @@ type TestURL struct {
 	Description string `yaml:"description"`
 	Required    bool   `yaml:"required"`
 }
-
-// Functions that need to be implemented in the main package
-var (
-	createProxyClient                  func(*url.URL, time.Duration) (*http.Client, error)
-	checkProtocolSmuggling             func(*http.Client, bool) (bool, string)
-	checkDNSRebinding                  func(*http.Client, bool) (bool, string)
-	checkCachePoisoning                func(*http.Client, bool) (bool, string)
-	checkHostHeaderInjection           func(*http.Client, bool) (bool, string)
-	writeTextOutput                    func(string, []ProxyResultOutput, SummaryOutput) error
-	writeWorkingProxiesOutput          func(string, []ProxyResultOutput) error
-	writeWorkingAnonymousProxiesOutput func(string, []ProxyResultOutput) error
-)